@@ -0,0 +1,196 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: keyprovider/key_provider.proto
+
+package keyprovider
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Request to unwrap the data key named in an encrypted source header.
+type GetDataKeyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ObjectKey     string                 `protobuf:"bytes,1,opt,name=object_key,json=objectKey,proto3" json:"object_key,omitempty"`
+	KeyId         string                 `protobuf:"bytes,2,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	WrappedKey    []byte                 `protobuf:"bytes,3,opt,name=wrapped_key,json=wrappedKey,proto3" json:"wrapped_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDataKeyRequest) Reset() {
+	*x = GetDataKeyRequest{}
+	mi := &file_keyprovider_key_provider_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDataKeyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataKeyRequest) ProtoMessage() {}
+
+func (x *GetDataKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_keyprovider_key_provider_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataKeyRequest.ProtoReflect.Descriptor instead.
+func (*GetDataKeyRequest) Descriptor() ([]byte, []int) {
+	return file_keyprovider_key_provider_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetDataKeyRequest) GetObjectKey() string {
+	if x != nil {
+		return x.ObjectKey
+	}
+	return ""
+}
+
+func (x *GetDataKeyRequest) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *GetDataKeyRequest) GetWrappedKey() []byte {
+	if x != nil {
+		return x.WrappedKey
+	}
+	return nil
+}
+
+// Response carrying the plaintext data key. Callers must zero it after use.
+type GetDataKeyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DataKey       []byte                 `protobuf:"bytes,1,opt,name=data_key,json=dataKey,proto3" json:"data_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDataKeyResponse) Reset() {
+	*x = GetDataKeyResponse{}
+	mi := &file_keyprovider_key_provider_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDataKeyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDataKeyResponse) ProtoMessage() {}
+
+func (x *GetDataKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_keyprovider_key_provider_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDataKeyResponse.ProtoReflect.Descriptor instead.
+func (*GetDataKeyResponse) Descriptor() ([]byte, []int) {
+	return file_keyprovider_key_provider_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetDataKeyResponse) GetDataKey() []byte {
+	if x != nil {
+		return x.DataKey
+	}
+	return nil
+}
+
+var File_keyprovider_key_provider_proto protoreflect.FileDescriptor
+
+const file_keyprovider_key_provider_proto_rawDesc = "" +
+	"\n" +
+	"\x1ekeyprovider/key_provider.proto\x12\x06upload\"j\n" +
+	"\x11GetDataKeyRequest\x12\x1d\n" +
+	"\n" +
+	"object_key\x18\x01 \x01(\tR\tobjectKey\x12\x15\n" +
+	"\x06key_id\x18\x02 \x01(\tR\x05keyId\x12\x1f\n" +
+	"\vwrapped_key\x18\x03 \x01(\fR\n" +
+	"wrappedKey\"/\n" +
+	"\x12GetDataKeyResponse\x12\x19\n" +
+	"\bdata_key\x18\x01 \x01(\fR\adataKey2R\n" +
+	"\vKeyProvider\x12C\n" +
+	"\n" +
+	"GetDataKey\x12\x19.upload.GetDataKeyRequest\x1a\x1a.upload.GetDataKeyResponseB#Z!transcode-service/api/keyproviderb\x06proto3"
+
+var (
+	file_keyprovider_key_provider_proto_rawDescOnce sync.Once
+	file_keyprovider_key_provider_proto_rawDescData []byte
+)
+
+func file_keyprovider_key_provider_proto_rawDescGZIP() []byte {
+	file_keyprovider_key_provider_proto_rawDescOnce.Do(func() {
+		file_keyprovider_key_provider_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_keyprovider_key_provider_proto_rawDesc), len(file_keyprovider_key_provider_proto_rawDesc)))
+	})
+	return file_keyprovider_key_provider_proto_rawDescData
+}
+
+var file_keyprovider_key_provider_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_keyprovider_key_provider_proto_goTypes = []any{
+	(*GetDataKeyRequest)(nil),  // 0: upload.GetDataKeyRequest
+	(*GetDataKeyResponse)(nil), // 1: upload.GetDataKeyResponse
+}
+var file_keyprovider_key_provider_proto_depIdxs = []int32{
+	0, // 0: upload.KeyProvider.GetDataKey:input_type -> upload.GetDataKeyRequest
+	1, // 1: upload.KeyProvider.GetDataKey:output_type -> upload.GetDataKeyResponse
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_keyprovider_key_provider_proto_init() }
+func file_keyprovider_key_provider_proto_init() {
+	if File_keyprovider_key_provider_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_keyprovider_key_provider_proto_rawDesc), len(file_keyprovider_key_provider_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_keyprovider_key_provider_proto_goTypes,
+		DependencyIndexes: file_keyprovider_key_provider_proto_depIdxs,
+		MessageInfos:      file_keyprovider_key_provider_proto_msgTypes,
+	}.Build()
+	File_keyprovider_key_provider_proto = out.File
+	file_keyprovider_key_provider_proto_goTypes = nil
+	file_keyprovider_key_provider_proto_depIdxs = nil
+}
@@ -0,0 +1,695 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: transcode/transcode_service.proto
+
+package transcode
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Request to create a new transcode task.
+type CreateTranscodeTaskRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	UserUuid         string                 `protobuf:"bytes,1,opt,name=user_uuid,json=userUuid,proto3" json:"user_uuid,omitempty"`
+	VideoUuid        string                 `protobuf:"bytes,2,opt,name=video_uuid,json=videoUuid,proto3" json:"video_uuid,omitempty"`
+	InputPath        string                 `protobuf:"bytes,3,opt,name=input_path,json=inputPath,proto3" json:"input_path,omitempty"`
+	TargetResolution string                 `protobuf:"bytes,4,opt,name=target_resolution,json=targetResolution,proto3" json:"target_resolution,omitempty"`
+	TargetBitrate    string                 `protobuf:"bytes,5,opt,name=target_bitrate,json=targetBitrate,proto3" json:"target_bitrate,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CreateTranscodeTaskRequest) Reset() {
+	*x = CreateTranscodeTaskRequest{}
+	mi := &file_transcode_transcode_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTranscodeTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTranscodeTaskRequest) ProtoMessage() {}
+
+func (x *CreateTranscodeTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transcode_transcode_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTranscodeTaskRequest.ProtoReflect.Descriptor instead.
+func (*CreateTranscodeTaskRequest) Descriptor() ([]byte, []int) {
+	return file_transcode_transcode_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateTranscodeTaskRequest) GetUserUuid() string {
+	if x != nil {
+		return x.UserUuid
+	}
+	return ""
+}
+
+func (x *CreateTranscodeTaskRequest) GetVideoUuid() string {
+	if x != nil {
+		return x.VideoUuid
+	}
+	return ""
+}
+
+func (x *CreateTranscodeTaskRequest) GetInputPath() string {
+	if x != nil {
+		return x.InputPath
+	}
+	return ""
+}
+
+func (x *CreateTranscodeTaskRequest) GetTargetResolution() string {
+	if x != nil {
+		return x.TargetResolution
+	}
+	return ""
+}
+
+func (x *CreateTranscodeTaskRequest) GetTargetBitrate() string {
+	if x != nil {
+		return x.TargetBitrate
+	}
+	return ""
+}
+
+// Response for CreateTranscodeTask.
+type CreateTranscodeTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TaskUuid      string                 `protobuf:"bytes,2,opt,name=task_uuid,json=taskUuid,proto3" json:"task_uuid,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTranscodeTaskResponse) Reset() {
+	*x = CreateTranscodeTaskResponse{}
+	mi := &file_transcode_transcode_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTranscodeTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTranscodeTaskResponse) ProtoMessage() {}
+
+func (x *CreateTranscodeTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transcode_transcode_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTranscodeTaskResponse.ProtoReflect.Descriptor instead.
+func (*CreateTranscodeTaskResponse) Descriptor() ([]byte, []int) {
+	return file_transcode_transcode_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateTranscodeTaskResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CreateTranscodeTaskResponse) GetTaskUuid() string {
+	if x != nil {
+		return x.TaskUuid
+	}
+	return ""
+}
+
+func (x *CreateTranscodeTaskResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Request to query a transcode task.
+type GetTranscodeTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskUuid      string                 `protobuf:"bytes,1,opt,name=task_uuid,json=taskUuid,proto3" json:"task_uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTranscodeTaskRequest) Reset() {
+	*x = GetTranscodeTaskRequest{}
+	mi := &file_transcode_transcode_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTranscodeTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTranscodeTaskRequest) ProtoMessage() {}
+
+func (x *GetTranscodeTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transcode_transcode_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTranscodeTaskRequest.ProtoReflect.Descriptor instead.
+func (*GetTranscodeTaskRequest) Descriptor() ([]byte, []int) {
+	return file_transcode_transcode_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetTranscodeTaskRequest) GetTaskUuid() string {
+	if x != nil {
+		return x.TaskUuid
+	}
+	return ""
+}
+
+// Response containing task status.
+type GetTranscodeTaskResponse struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Success      bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TaskUuid     string                 `protobuf:"bytes,2,opt,name=task_uuid,json=taskUuid,proto3" json:"task_uuid,omitempty"`
+	VideoUuid    string                 `protobuf:"bytes,3,opt,name=video_uuid,json=videoUuid,proto3" json:"video_uuid,omitempty"`
+	Status       string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Progress     int32                  `protobuf:"varint,5,opt,name=progress,proto3" json:"progress,omitempty"`
+	OutputPath   string                 `protobuf:"bytes,6,opt,name=output_path,json=outputPath,proto3" json:"output_path,omitempty"`
+	ErrorMessage string                 `protobuf:"bytes,7,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	// Every output produced for the task: MP4 rendition, audio-only outputs and HLS playlists.
+	Outputs       []*TranscodeOutput `protobuf:"bytes,8,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTranscodeTaskResponse) Reset() {
+	*x = GetTranscodeTaskResponse{}
+	mi := &file_transcode_transcode_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTranscodeTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTranscodeTaskResponse) ProtoMessage() {}
+
+func (x *GetTranscodeTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transcode_transcode_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTranscodeTaskResponse.ProtoReflect.Descriptor instead.
+func (*GetTranscodeTaskResponse) Descriptor() ([]byte, []int) {
+	return file_transcode_transcode_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetTranscodeTaskResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetTranscodeTaskResponse) GetTaskUuid() string {
+	if x != nil {
+		return x.TaskUuid
+	}
+	return ""
+}
+
+func (x *GetTranscodeTaskResponse) GetVideoUuid() string {
+	if x != nil {
+		return x.VideoUuid
+	}
+	return ""
+}
+
+func (x *GetTranscodeTaskResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GetTranscodeTaskResponse) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *GetTranscodeTaskResponse) GetOutputPath() string {
+	if x != nil {
+		return x.OutputPath
+	}
+	return ""
+}
+
+func (x *GetTranscodeTaskResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *GetTranscodeTaskResponse) GetOutputs() []*TranscodeOutput {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+// A single output produced for a transcode task.
+type TranscodeOutput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// video | audio | hls_master | hls_rendition
+	Type       string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Resolution string `protobuf:"bytes,2,opt,name=resolution,proto3" json:"resolution,omitempty"`
+	// Public URL, or a signed URL for private outputs.
+	Url string `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	// Size in bytes, 0 when unknown.
+	Size          int64 `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TranscodeOutput) Reset() {
+	*x = TranscodeOutput{}
+	mi := &file_transcode_transcode_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TranscodeOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscodeOutput) ProtoMessage() {}
+
+func (x *TranscodeOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_transcode_transcode_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscodeOutput.ProtoReflect.Descriptor instead.
+func (*TranscodeOutput) Descriptor() ([]byte, []int) {
+	return file_transcode_transcode_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TranscodeOutput) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TranscodeOutput) GetResolution() string {
+	if x != nil {
+		return x.Resolution
+	}
+	return ""
+}
+
+func (x *TranscodeOutput) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *TranscodeOutput) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// Request to query the latest task of several videos.
+type GetTranscodeTasksByVideoUUIDsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VideoUuids    []string               `protobuf:"bytes,1,rep,name=video_uuids,json=videoUuids,proto3" json:"video_uuids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTranscodeTasksByVideoUUIDsRequest) Reset() {
+	*x = GetTranscodeTasksByVideoUUIDsRequest{}
+	mi := &file_transcode_transcode_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTranscodeTasksByVideoUUIDsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTranscodeTasksByVideoUUIDsRequest) ProtoMessage() {}
+
+func (x *GetTranscodeTasksByVideoUUIDsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_transcode_transcode_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTranscodeTasksByVideoUUIDsRequest.ProtoReflect.Descriptor instead.
+func (*GetTranscodeTasksByVideoUUIDsRequest) Descriptor() ([]byte, []int) {
+	return file_transcode_transcode_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetTranscodeTasksByVideoUUIDsRequest) GetVideoUuids() []string {
+	if x != nil {
+		return x.VideoUuids
+	}
+	return nil
+}
+
+// Response with one record per requested video, in request order.
+type GetTranscodeTasksByVideoUUIDsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*TranscodeTaskStatus `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTranscodeTasksByVideoUUIDsResponse) Reset() {
+	*x = GetTranscodeTasksByVideoUUIDsResponse{}
+	mi := &file_transcode_transcode_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTranscodeTasksByVideoUUIDsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTranscodeTasksByVideoUUIDsResponse) ProtoMessage() {}
+
+func (x *GetTranscodeTasksByVideoUUIDsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_transcode_transcode_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTranscodeTasksByVideoUUIDsResponse.ProtoReflect.Descriptor instead.
+func (*GetTranscodeTasksByVideoUUIDsResponse) Descriptor() ([]byte, []int) {
+	return file_transcode_transcode_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetTranscodeTasksByVideoUUIDsResponse) GetRecords() []*TranscodeTaskStatus {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+// Compact status of the latest task of a video.
+type TranscodeTaskStatus struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	VideoUuid string                 `protobuf:"bytes,1,opt,name=video_uuid,json=videoUuid,proto3" json:"video_uuid,omitempty"`
+	// false when the video has no task; the remaining fields are then empty.
+	Found        bool   `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	TaskUuid     string `protobuf:"bytes,3,opt,name=task_uuid,json=taskUuid,proto3" json:"task_uuid,omitempty"`
+	Status       string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Progress     int32  `protobuf:"varint,5,opt,name=progress,proto3" json:"progress,omitempty"`
+	OutputPath   string `protobuf:"bytes,6,opt,name=output_path,json=outputPath,proto3" json:"output_path,omitempty"`
+	ErrorMessage string `protobuf:"bytes,7,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	// RFC 3339 timestamp of the last task update.
+	UpdatedAt     string `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TranscodeTaskStatus) Reset() {
+	*x = TranscodeTaskStatus{}
+	mi := &file_transcode_transcode_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TranscodeTaskStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscodeTaskStatus) ProtoMessage() {}
+
+func (x *TranscodeTaskStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_transcode_transcode_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscodeTaskStatus.ProtoReflect.Descriptor instead.
+func (*TranscodeTaskStatus) Descriptor() ([]byte, []int) {
+	return file_transcode_transcode_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TranscodeTaskStatus) GetVideoUuid() string {
+	if x != nil {
+		return x.VideoUuid
+	}
+	return ""
+}
+
+func (x *TranscodeTaskStatus) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *TranscodeTaskStatus) GetTaskUuid() string {
+	if x != nil {
+		return x.TaskUuid
+	}
+	return ""
+}
+
+func (x *TranscodeTaskStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TranscodeTaskStatus) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *TranscodeTaskStatus) GetOutputPath() string {
+	if x != nil {
+		return x.OutputPath
+	}
+	return ""
+}
+
+func (x *TranscodeTaskStatus) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *TranscodeTaskStatus) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+var File_transcode_transcode_service_proto protoreflect.FileDescriptor
+
+const file_transcode_transcode_service_proto_rawDesc = "" +
+	"\n" +
+	"!transcode/transcode_service.proto\x12\ttranscode\"\xcb\x01\n" +
+	"\x1aCreateTranscodeTaskRequest\x12\x1b\n" +
+	"\tuser_uuid\x18\x01 \x01(\tR\buserUuid\x12\x1d\n" +
+	"\n" +
+	"video_uuid\x18\x02 \x01(\tR\tvideoUuid\x12\x1d\n" +
+	"\n" +
+	"input_path\x18\x03 \x01(\tR\tinputPath\x12+\n" +
+	"\x11target_resolution\x18\x04 \x01(\tR\x10targetResolution\x12%\n" +
+	"\x0etarget_bitrate\x18\x05 \x01(\tR\rtargetBitrate\"n\n" +
+	"\x1bCreateTranscodeTaskResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1b\n" +
+	"\ttask_uuid\x18\x02 \x01(\tR\btaskUuid\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"6\n" +
+	"\x17GetTranscodeTaskRequest\x12\x1b\n" +
+	"\ttask_uuid\x18\x01 \x01(\tR\btaskUuid\"\xa0\x02\n" +
+	"\x18GetTranscodeTaskResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1b\n" +
+	"\ttask_uuid\x18\x02 \x01(\tR\btaskUuid\x12\x1d\n" +
+	"\n" +
+	"video_uuid\x18\x03 \x01(\tR\tvideoUuid\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1a\n" +
+	"\bprogress\x18\x05 \x01(\x05R\bprogress\x12\x1f\n" +
+	"\voutput_path\x18\x06 \x01(\tR\n" +
+	"outputPath\x12#\n" +
+	"\rerror_message\x18\a \x01(\tR\ferrorMessage\x124\n" +
+	"\aoutputs\x18\b \x03(\v2\x1a.transcode.TranscodeOutputR\aoutputs\"k\n" +
+	"\x0fTranscodeOutput\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1e\n" +
+	"\n" +
+	"resolution\x18\x02 \x01(\tR\n" +
+	"resolution\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\"G\n" +
+	"$GetTranscodeTasksByVideoUUIDsRequest\x12\x1f\n" +
+	"\vvideo_uuids\x18\x01 \x03(\tR\n" +
+	"videoUuids\"a\n" +
+	"%GetTranscodeTasksByVideoUUIDsResponse\x128\n" +
+	"\arecords\x18\x01 \x03(\v2\x1e.transcode.TranscodeTaskStatusR\arecords\"\x80\x02\n" +
+	"\x13TranscodeTaskStatus\x12\x1d\n" +
+	"\n" +
+	"video_uuid\x18\x01 \x01(\tR\tvideoUuid\x12\x14\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\x12\x1b\n" +
+	"\ttask_uuid\x18\x03 \x01(\tR\btaskUuid\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1a\n" +
+	"\bprogress\x18\x05 \x01(\x05R\bprogress\x12\x1f\n" +
+	"\voutput_path\x18\x06 \x01(\tR\n" +
+	"outputPath\x12#\n" +
+	"\rerror_message\x18\a \x01(\tR\ferrorMessage\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\tR\tupdatedAt2\xd5\x01\n" +
+	"\x10TranscodeService\x12d\n" +
+	"\x13CreateTranscodeTask\x12%.transcode.CreateTranscodeTaskRequest\x1a&.transcode.CreateTranscodeTaskResponse\x12[\n" +
+	"\x10GetTranscodeTask\x12\".transcode.GetTranscodeTaskRequest\x1a#.transcode.GetTranscodeTaskResponse2\x9c\x01\n" +
+	"\x15TranscodeBatchService\x12\x82\x01\n" +
+	"\x1dGetTranscodeTasksByVideoUUIDs\x12/.transcode.GetTranscodeTasksByVideoUUIDsRequest\x1a0.transcode.GetTranscodeTasksByVideoUUIDsResponseB!Z\x1ftranscode-service/api/transcodeb\x06proto3"
+
+var (
+	file_transcode_transcode_service_proto_rawDescOnce sync.Once
+	file_transcode_transcode_service_proto_rawDescData []byte
+)
+
+func file_transcode_transcode_service_proto_rawDescGZIP() []byte {
+	file_transcode_transcode_service_proto_rawDescOnce.Do(func() {
+		file_transcode_transcode_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_transcode_transcode_service_proto_rawDesc), len(file_transcode_transcode_service_proto_rawDesc)))
+	})
+	return file_transcode_transcode_service_proto_rawDescData
+}
+
+var file_transcode_transcode_service_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_transcode_transcode_service_proto_goTypes = []any{
+	(*CreateTranscodeTaskRequest)(nil),            // 0: transcode.CreateTranscodeTaskRequest
+	(*CreateTranscodeTaskResponse)(nil),           // 1: transcode.CreateTranscodeTaskResponse
+	(*GetTranscodeTaskRequest)(nil),               // 2: transcode.GetTranscodeTaskRequest
+	(*GetTranscodeTaskResponse)(nil),              // 3: transcode.GetTranscodeTaskResponse
+	(*TranscodeOutput)(nil),                       // 4: transcode.TranscodeOutput
+	(*GetTranscodeTasksByVideoUUIDsRequest)(nil),  // 5: transcode.GetTranscodeTasksByVideoUUIDsRequest
+	(*GetTranscodeTasksByVideoUUIDsResponse)(nil), // 6: transcode.GetTranscodeTasksByVideoUUIDsResponse
+	(*TranscodeTaskStatus)(nil),                   // 7: transcode.TranscodeTaskStatus
+}
+var file_transcode_transcode_service_proto_depIdxs = []int32{
+	4, // 0: transcode.GetTranscodeTaskResponse.outputs:type_name -> transcode.TranscodeOutput
+	7, // 1: transcode.GetTranscodeTasksByVideoUUIDsResponse.records:type_name -> transcode.TranscodeTaskStatus
+	0, // 2: transcode.TranscodeService.CreateTranscodeTask:input_type -> transcode.CreateTranscodeTaskRequest
+	2, // 3: transcode.TranscodeService.GetTranscodeTask:input_type -> transcode.GetTranscodeTaskRequest
+	5, // 4: transcode.TranscodeBatchService.GetTranscodeTasksByVideoUUIDs:input_type -> transcode.GetTranscodeTasksByVideoUUIDsRequest
+	1, // 5: transcode.TranscodeService.CreateTranscodeTask:output_type -> transcode.CreateTranscodeTaskResponse
+	3, // 6: transcode.TranscodeService.GetTranscodeTask:output_type -> transcode.GetTranscodeTaskResponse
+	6, // 7: transcode.TranscodeBatchService.GetTranscodeTasksByVideoUUIDs:output_type -> transcode.GetTranscodeTasksByVideoUUIDsResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_transcode_transcode_service_proto_init() }
+func file_transcode_transcode_service_proto_init() {
+	if File_transcode_transcode_service_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_transcode_transcode_service_proto_rawDesc), len(file_transcode_transcode_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_transcode_transcode_service_proto_goTypes,
+		DependencyIndexes: file_transcode_transcode_service_proto_depIdxs,
+		MessageInfos:      file_transcode_transcode_service_proto_msgTypes,
+	}.Build()
+	File_transcode_transcode_service_proto = out.File
+	file_transcode_transcode_service_proto_goTypes = nil
+	file_transcode_transcode_service_proto_depIdxs = nil
+}
@@ -0,0 +1,275 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: transcode/transcode_service.proto
+
+package transcode
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TranscodeService_CreateTranscodeTask_FullMethodName = "/transcode.TranscodeService/CreateTranscodeTask"
+	TranscodeService_GetTranscodeTask_FullMethodName    = "/transcode.TranscodeService/GetTranscodeTask"
+)
+
+// TranscodeServiceClient is the client API for TranscodeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TranscodeService exposes RPCs for managing video transcode tasks.
+type TranscodeServiceClient interface {
+	// CreateTranscodeTask enqueue a new transcode job.
+	CreateTranscodeTask(ctx context.Context, in *CreateTranscodeTaskRequest, opts ...grpc.CallOption) (*CreateTranscodeTaskResponse, error)
+	// GetTranscodeTask returns current task status details.
+	GetTranscodeTask(ctx context.Context, in *GetTranscodeTaskRequest, opts ...grpc.CallOption) (*GetTranscodeTaskResponse, error)
+}
+
+type transcodeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTranscodeServiceClient(cc grpc.ClientConnInterface) TranscodeServiceClient {
+	return &transcodeServiceClient{cc}
+}
+
+func (c *transcodeServiceClient) CreateTranscodeTask(ctx context.Context, in *CreateTranscodeTaskRequest, opts ...grpc.CallOption) (*CreateTranscodeTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateTranscodeTaskResponse)
+	err := c.cc.Invoke(ctx, TranscodeService_CreateTranscodeTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transcodeServiceClient) GetTranscodeTask(ctx context.Context, in *GetTranscodeTaskRequest, opts ...grpc.CallOption) (*GetTranscodeTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTranscodeTaskResponse)
+	err := c.cc.Invoke(ctx, TranscodeService_GetTranscodeTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TranscodeServiceServer is the server API for TranscodeService service.
+// All implementations must embed UnimplementedTranscodeServiceServer
+// for forward compatibility.
+//
+// TranscodeService exposes RPCs for managing video transcode tasks.
+type TranscodeServiceServer interface {
+	// CreateTranscodeTask enqueue a new transcode job.
+	CreateTranscodeTask(context.Context, *CreateTranscodeTaskRequest) (*CreateTranscodeTaskResponse, error)
+	// GetTranscodeTask returns current task status details.
+	GetTranscodeTask(context.Context, *GetTranscodeTaskRequest) (*GetTranscodeTaskResponse, error)
+	mustEmbedUnimplementedTranscodeServiceServer()
+}
+
+// UnimplementedTranscodeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTranscodeServiceServer struct{}
+
+func (UnimplementedTranscodeServiceServer) CreateTranscodeTask(context.Context, *CreateTranscodeTaskRequest) (*CreateTranscodeTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTranscodeTask not implemented")
+}
+func (UnimplementedTranscodeServiceServer) GetTranscodeTask(context.Context, *GetTranscodeTaskRequest) (*GetTranscodeTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTranscodeTask not implemented")
+}
+func (UnimplementedTranscodeServiceServer) mustEmbedUnimplementedTranscodeServiceServer() {}
+func (UnimplementedTranscodeServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeTranscodeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TranscodeServiceServer will
+// result in compilation errors.
+type UnsafeTranscodeServiceServer interface {
+	mustEmbedUnimplementedTranscodeServiceServer()
+}
+
+func RegisterTranscodeServiceServer(s grpc.ServiceRegistrar, srv TranscodeServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTranscodeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TranscodeService_ServiceDesc, srv)
+}
+
+func _TranscodeService_CreateTranscodeTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTranscodeTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranscodeServiceServer).CreateTranscodeTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TranscodeService_CreateTranscodeTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranscodeServiceServer).CreateTranscodeTask(ctx, req.(*CreateTranscodeTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TranscodeService_GetTranscodeTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTranscodeTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranscodeServiceServer).GetTranscodeTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TranscodeService_GetTranscodeTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranscodeServiceServer).GetTranscodeTask(ctx, req.(*GetTranscodeTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TranscodeService_ServiceDesc is the grpc.ServiceDesc for TranscodeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TranscodeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "transcode.TranscodeService",
+	HandlerType: (*TranscodeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateTranscodeTask",
+			Handler:    _TranscodeService_CreateTranscodeTask_Handler,
+		},
+		{
+			MethodName: "GetTranscodeTask",
+			Handler:    _TranscodeService_GetTranscodeTask_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "transcode/transcode_service.proto",
+}
+
+const (
+	TranscodeBatchService_GetTranscodeTasksByVideoUUIDs_FullMethodName = "/transcode.TranscodeBatchService/GetTranscodeTasksByVideoUUIDs"
+)
+
+// TranscodeBatchServiceClient is the client API for TranscodeBatchService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TranscodeBatchService serves bulk status reconciliation for callers tracking many videos.
+type TranscodeBatchServiceClient interface {
+	// GetTranscodeTasksByVideoUUIDs returns the latest task of each requested video, at most 500 per call.
+	GetTranscodeTasksByVideoUUIDs(ctx context.Context, in *GetTranscodeTasksByVideoUUIDsRequest, opts ...grpc.CallOption) (*GetTranscodeTasksByVideoUUIDsResponse, error)
+}
+
+type transcodeBatchServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTranscodeBatchServiceClient(cc grpc.ClientConnInterface) TranscodeBatchServiceClient {
+	return &transcodeBatchServiceClient{cc}
+}
+
+func (c *transcodeBatchServiceClient) GetTranscodeTasksByVideoUUIDs(ctx context.Context, in *GetTranscodeTasksByVideoUUIDsRequest, opts ...grpc.CallOption) (*GetTranscodeTasksByVideoUUIDsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTranscodeTasksByVideoUUIDsResponse)
+	err := c.cc.Invoke(ctx, TranscodeBatchService_GetTranscodeTasksByVideoUUIDs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TranscodeBatchServiceServer is the server API for TranscodeBatchService service.
+// All implementations must embed UnimplementedTranscodeBatchServiceServer
+// for forward compatibility.
+//
+// TranscodeBatchService serves bulk status reconciliation for callers tracking many videos.
+type TranscodeBatchServiceServer interface {
+	// GetTranscodeTasksByVideoUUIDs returns the latest task of each requested video, at most 500 per call.
+	GetTranscodeTasksByVideoUUIDs(context.Context, *GetTranscodeTasksByVideoUUIDsRequest) (*GetTranscodeTasksByVideoUUIDsResponse, error)
+	mustEmbedUnimplementedTranscodeBatchServiceServer()
+}
+
+// UnimplementedTranscodeBatchServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTranscodeBatchServiceServer struct{}
+
+func (UnimplementedTranscodeBatchServiceServer) GetTranscodeTasksByVideoUUIDs(context.Context, *GetTranscodeTasksByVideoUUIDsRequest) (*GetTranscodeTasksByVideoUUIDsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTranscodeTasksByVideoUUIDs not implemented")
+}
+func (UnimplementedTranscodeBatchServiceServer) mustEmbedUnimplementedTranscodeBatchServiceServer() {}
+func (UnimplementedTranscodeBatchServiceServer) testEmbeddedByValue()                               {}
+
+// UnsafeTranscodeBatchServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TranscodeBatchServiceServer will
+// result in compilation errors.
+type UnsafeTranscodeBatchServiceServer interface {
+	mustEmbedUnimplementedTranscodeBatchServiceServer()
+}
+
+func RegisterTranscodeBatchServiceServer(s grpc.ServiceRegistrar, srv TranscodeBatchServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTranscodeBatchServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TranscodeBatchService_ServiceDesc, srv)
+}
+
+func _TranscodeBatchService_GetTranscodeTasksByVideoUUIDs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTranscodeTasksByVideoUUIDsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranscodeBatchServiceServer).GetTranscodeTasksByVideoUUIDs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TranscodeBatchService_GetTranscodeTasksByVideoUUIDs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranscodeBatchServiceServer).GetTranscodeTasksByVideoUUIDs(ctx, req.(*GetTranscodeTasksByVideoUUIDsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TranscodeBatchService_ServiceDesc is the grpc.ServiceDesc for TranscodeBatchService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TranscodeBatchService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "transcode.TranscodeBatchService",
+	HandlerType: (*TranscodeBatchServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTranscodeTasksByVideoUUIDs",
+			Handler:    _TranscodeBatchService_GetTranscodeTasksByVideoUUIDs_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "transcode/transcode_service.proto",
+}
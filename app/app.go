@@ -2,25 +2,37 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
+	"runtime"
 	"strings"
 	"syscall"
 	"time"
 
 	transcodeGrpc "transcode-service/ddd/adapter/grpc"
 	app "transcode-service/ddd/application/app"
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/infrastructure/failstats"
+	"transcode-service/ddd/infrastructure/storage"
+	"transcode-service/pkg/clock"
 	"transcode-service/pkg/config"
+	"transcode-service/pkg/ffruntime"
 	"transcode-service/pkg/grpcutil"
 	"transcode-service/pkg/logger"
 	"transcode-service/pkg/manager"
+	"transcode-service/pkg/metrics"
 	"transcode-service/pkg/middleware"
+	"transcode-service/pkg/observability"
+	"transcode-service/pkg/openapi"
 	"transcode-service/pkg/repository"
+	"transcode-service/pkg/sysinfo"
 	"transcode-service/pkg/task"
 
 	"github.com/gin-gonic/gin"
@@ -31,11 +43,15 @@ import (
 	_ "transcode-service/ddd/infrastructure/worker"
 
 	// 导入资源和模块包以触发init函数
-	transcodepb "github.com/jiangqiao2/go-video-proto/proto/transcode/transcode"
-	_ "transcode-service/internal/resource"
+	transcodepb "transcode-service/api/transcode"
+	"transcode-service/internal/resource"
 )
 
+var preflight = flag.Bool("preflight", false, "run the end-to-end self-test (encode, upload, download) and exit")
+
 func Run() {
+	flag.Parse()
+
 	// 先使用标准输出确保能看到日志
 	fmt.Println("[STARTUP] Starting transcode service...")
 
@@ -66,28 +82,68 @@ func Run() {
 
 	logger.Infof("Transcode service starting version=%s env=%s", "1.0.0", "development")
 
-	// 检查 FFmpeg 是否可用，直接在启动阶段失败
-	ffmpegBin := cfg.Transcode.FFmpeg.BinaryPath
-	if strings.TrimSpace(ffmpegBin) == "" {
-		ffmpegBin = "ffmpeg"
+	// 按容器资源限制推导 ffmpeg 线程数与 worker 并发
+	tuning := cfg.AutoTune(sysinfo.Detect())
+	logger.Infof("resource tuning source=%s host_cpus=%d cpu_limit=%.2f memory_limit=%d threads=%d max_concurrent_tasks=%d hls_max_concurrent_tasks=%d adjusted=%v",
+		tuning.Resources.Source, tuning.Resources.HostCPUs, tuning.Resources.CPULimit, tuning.Resources.MemoryLimit,
+		tuning.Threads, tuning.MaxConcurrentTasks, tuning.HLSMaxConcurrentTasks, tuning.Adjusted)
+	metrics.SetFloat("cpu_limit", tuning.Resources.CPULimit)
+	metrics.Set("memory_limit_bytes", tuning.Resources.MemoryLimit)
+	metrics.Set("ffmpeg_threads", int64(tuning.Threads))
+	metrics.Set("worker_max_concurrent_tasks", int64(tuning.MaxConcurrentTasks))
+	metrics.Set("worker_hls_max_concurrent_tasks", int64(tuning.HLSMaxConcurrentTasks))
+	// 按容器内存上限设置 Go 软内存上限，GC 调优在组件初始化前生效
+	rt := observability.ApplyRuntimeTuning(cfg.Diagnostics.GCPercent, cfg.Diagnostics.MemoryLimitRatio, tuning.Resources.MemoryLimit)
+	logger.Infof("runtime tuning gc_percent=%d go_memory_limit=%d", rt.GCPercent, rt.MemoryLimit)
+	if cfg.Server.LegacyTimeFormat {
+		// 迁移期间保留旧的时间戳格式，客户端改为解析 RFC3339 UTC 后关闭
+		types.SetLegacyTimeFormat(true)
+		logger.Warnf("server.legacy_time_format enabled, api timestamps use server local time zone")
 	}
+	// 产物 URL 无法被下游解析时启动即提示，按 server.mode 区分环境
+	for _, w := range cfg.PublicURLWarnings() {
+		metrics.Add("public_url_config_warnings", 1)
+		logger.Warnf("%s env=%s", w, cfg.Server.Mode)
+	}
+
+	// 检查 FFmpeg 是否可用，直接在启动阶段失败
+	ffmpegBin := cfg.Transcode.FFmpeg.Binary()
 	if _, err := exec.LookPath(ffmpegBin); err != nil {
-		logger.Fatal(fmt.Sprintf("FFmpeg binary not found, please install or set transcode.ffmpeg.binary_path binary=%s error=%s", ffmpegBin, err.Error()))
-	}
-	if strings.Contains(strings.ToLower(cfg.Transcode.FFmpeg.VideoCodec), "nvenc") {
-		cmd := exec.Command(ffmpegBin, "-hide_banner", "-encoders")
-		if out, err := cmd.Output(); err == nil {
-			if !strings.Contains(strings.ToLower(string(out)), "nvenc") {
-				logger.Warnf("NVENC encoder not detected in FFmpeg, codec=%s", cfg.Transcode.FFmpeg.VideoCodec)
-			}
-		}
+		logger.Fatal(fmt.Sprintf("FFmpeg binary not found, please install or set transcode.ffmpeg.binary_path / arch_binary_paths binary=%s arch=%s error=%s", ffmpegBin, runtime.GOARCH, err.Error()))
+	}
+	// 检测本机 ffmpeg 能力，剔除无法执行的编码配置，避免到编码阶段才失败
+	caps, err := ffruntime.Detect(context.Background(), ffmpegBin)
+	if err != nil {
+		logger.Fatal(fmt.Sprintf("FFmpeg capability detection failed binary=%s error=%v", ffmpegBin, err))
 	}
+	excluded, ok := cfg.ExcludeUnsupported(caps)
+	if len(excluded) > 0 {
+		logger.Warnf("FFmpeg profiles excluded binary=%s arch=%s excluded=%s", ffmpegBin, caps.Arch, strings.Join(excluded, "; "))
+	}
+	if !ok {
+		logger.Fatal(fmt.Sprintf("FFmpeg binary cannot run any configured profile binary=%s excluded=%s", ffmpegBin, strings.Join(excluded, "; ")))
+	}
+	logger.Infof("FFmpeg runtime binary=%s arch=%s version=%q video_codec=%s hardware_accel=%s",
+		ffmpegBin, caps.Arch, caps.Version, cfg.Transcode.FFmpeg.VideoCodec, cfg.Transcode.FFmpeg.HardwareAccel)
+	metrics.SetString("ffmpeg_binary", ffmpegBin)
+	metrics.SetString("ffmpeg_video_codec", cfg.Transcode.FFmpeg.VideoCodec)
 
-	// 资源管理器初始化
+	// 资源管理器初始化：按依赖分层并行打开，仅可选资源失败时降级启动
 	logger.Infof("Initializing resource manager...")
 	manager.MustInitResources()
 	defer manager.CloseResources()
-	logger.Infof("Resource manager initialized")
+	if degraded := manager.DegradedResources(); len(degraded) > 0 {
+		metrics.Set("startup_degraded_resources", int64(len(degraded)))
+		logger.Warnf("Resource manager initialized in degraded mode unavailable=%+v", degraded)
+	} else {
+		logger.Infof("Resource manager initialized")
+	}
+
+	// --preflight：仅执行端到端自检，按结果退出
+	if *preflight {
+		runPreflight()
+		return
+	}
 
 	// 初始化数据库（用于依赖注入）
 	logger.Infof("Initializing database connection...")
@@ -110,6 +166,8 @@ func Run() {
 		DB:                  db.Self,
 		Config:              cfg,
 		TranscodeAppService: transcodeAppService,
+		Clock:               clock.System(),
+		IDs:                 clock.UUIDs(),
 	}
 
 	// 初始化所有服务
@@ -121,6 +179,12 @@ func Run() {
 	logger.Infof("Initializing components...")
 	manager.MustInitComponents(deps)
 	logger.Infof("All components initialized")
+	if exporter := observability.NewRuntimeExporter(cfg.Diagnostics.RuntimeMetricsInterval); exporter != nil {
+		task.Register(exporter)
+	}
+	if collector := observability.NewGPUCollector(cfg.Diagnostics.GPU); collector != nil {
+		task.Register(collector)
+	}
 
 	// 启动所有后台任务（消费者/定时任务/worker 等）
 	if err := task.StartAll(context.Background()); err != nil {
@@ -135,11 +199,10 @@ func Run() {
 		logger.Fatal(fmt.Sprintf("Failed to listen on gRPC port address=%s error=%v", grpcAddr, err))
 	}
 
-	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(grpcutil.UnaryServerRequestIDInterceptor))
-	transcodepb.RegisterTranscodeServiceServer(
-		grpcServer,
-		transcodeGrpc.NewTranscodeGrpcServer(transcodeAppService),
-	)
+	grpcServer := grpc.NewServer(grpcutil.ServerOptions(cfg.GRPCServer)...)
+	transcodeGrpcServer := transcodeGrpc.NewTranscodeGrpcServer(transcodeAppService)
+	transcodepb.RegisterTranscodeServiceServer(grpcServer, transcodeGrpcServer)
+	transcodepb.RegisterTranscodeBatchServiceServer(grpcServer, transcodeGrpc.NewTranscodeBatchGrpcServer(transcodeAppService))
 
 	go func() {
 		logger.Infof("gRPC server started address=%s service=%s", grpcAddr, "transcode-service")
@@ -155,16 +218,53 @@ func Run() {
 
 	// 添加健康检查端点
 	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
+		body := gin.H{
 			"status":    "ok",
 			"service":   "transcode-service",
 			"timestamp": time.Now().Unix(),
-		})
+		}
+		// 降级启动时仍返回 200，列出不可用的可选依赖
+		degraded := manager.DegradedResources()
+		// Redis 运行中断开时同样视为降级，恢复后自动回到 ok
+		if redis := resource.DefaultRedisResource(); !manager.IsDegraded("redis") && !redis.Since().IsZero() && !redis.Available() {
+			degraded = append(degraded, manager.DegradedResource{Name: "redis", Error: "unavailable since " + redis.Since().Format(time.RFC3339)})
+		}
+		if len(degraded) > 0 {
+			body["status"] = "degraded"
+			body["degraded"] = degraded
+		}
+		c.JSON(http.StatusOK, body)
+	})
+
+	// 就绪检查：存储探测目标 down 或出队闸门关闭时返回 503
+	router.GET("/readyz", func(c *gin.Context) {
+		ready := storage.DefaultHealthGate().Healthy()
+		body := gin.H{"service": "transcode-service", "storage_gate": ready, "timestamp": time.Now().Unix()}
+		if prober := storage.DefaultHealthProber(); prober != nil {
+			ready = prober.Ready() && ready
+			body["storage"] = prober.Results()
+		}
+		// 最近 24 小时失败按类别分布与上升趋势，仅供展示，不影响就绪判断
+		body["failures_24h"] = failstats.Default().Snapshot()
+		status := http.StatusOK
+		body["status"] = "ready"
+		if !ready {
+			status = http.StatusServiceUnavailable
+			body["status"] = "not_ready"
+		}
+		c.JSON(status, body)
 	})
 
+	// 运行时指标（expvar）含启动命令行与内部计数，与 /debug/pprof 共用访问令牌，未配置令牌时只允许本机访问
+	router.GET("/debug/vars", middleware.DebugGuard(true, cfg.Diagnostics.ResolvedToken()), gin.WrapH(metrics.Handler()))
+	// Prometheus 文本格式，含按 GPU 区分的样本
+	router.GET("/metrics", gin.WrapH(metrics.PrometheusHandler()))
+
 	// 注册所有路由
 	logger.Infof("Registering routes...")
 	manager.RegisterAllRoutes(router)
+	// OpenAPI 文档由已注册的路由与 handler 注解生成，须在全部路由注册之后挂载
+	router.GET("/api/openapi.json", openapi.Handler(router, openapi.Info{Title: "transcode-service", Version: "1.0.0"}))
 	logger.Infof("Routes registered")
 
 	// 启动HTTP服务器
@@ -190,22 +290,23 @@ func Run() {
 
 	logger.Infof("Received shutdown signal, shutting down server...")
 
-	logger.Infof("Stopping gRPC server... address=%s", grpcAddr)
-	grpcServer.GracefulStop()
-
-	// 关闭所有组件
-	logger.Infof("Shutting down components...")
-	task.StopAll()
-	manager.Shutdown()
-	logger.Infof("Components closed")
-
-	// 设置5秒超时
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := server.Shutdown(ctx); err != nil {
-		logger.Fatal(fmt.Sprintf("Server forced to close error=%v", err))
-	}
+	// 有序停机：先停止接入（HTTP/gRPC/Kafka），再排空编码、写出缓冲，最后关闭资源
+	manager.RegisterShutdownStep(manager.PhaseIngest, "http-server", server.Shutdown)
+	manager.RegisterShutdownStep(manager.PhaseIngest, "grpc-server", func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			grpcServer.Stop()
+			return fmt.Errorf("graceful stop timed out, in-flight RPCs aborted address=%s", grpcAddr)
+		}
+	})
+	manager.Shutdown(cfg.Shutdown)
 
 	logger.Infof("Server exited safely")
 
@@ -218,6 +319,19 @@ func Run() {
 	fmt.Println("[SHUTDOWN] Transcode service exited safely")
 }
 
+// runPreflight 执行自检并输出报告，失败时以非零状态退出
+func runPreflight() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	report := app.DefaultOpsApp().SelfTest(ctx)
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+	if !report.Passed {
+		manager.CloseResources()
+		os.Exit(1)
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,281 @@
+// Package client 转码服务的 Go 客户端，封装 HTTP（/api/v2、/inner）与 gRPC 接口，
+// 提供按错误类别的自动重试、类型化错误与任务进度监听，兄弟服务不必再直接拼装 pb 与 HTTP 请求。
+//
+//	c, err := client.New(client.Options{BaseURL: "http://transcode:8083", GRPCAddr: "transcode:9092"})
+//	task, err := c.CreateTask(ctx, &client.CreateTaskRequest{UserUUID: u, VideoUUID: v, OriginalPath: p})
+//	final, err := c.WaitTask(ctx, task.TaskUUID, client.WatchOptions{})
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	transcodepb "transcode-service/api/transcode"
+)
+
+// RetryPolicy 可重试错误（连接失败、503、429、5xx）的指数退避重试
+type RetryPolicy struct {
+	MaxAttempts    int           // 含首次调用，默认 3；1 表示不重试
+	InitialBackoff time.Duration // 默认 200ms
+	MaxBackoff     time.Duration // 默认 5s
+}
+
+// Options 客户端配置，BaseURL 与 GRPCAddr 至少配置一个
+type Options struct {
+	BaseURL    string        // HTTP 地址，如 http://transcode-service:8083
+	GRPCAddr   string        // gRPC 地址，如 transcode-service:9092
+	Token      string        // 可选的 Bearer Token，HTTP 走 Authorization 头，gRPC 走 authorization metadata
+	Timeout    time.Duration // 单次请求超时，默认 10s
+	Retry      RetryPolicy
+	HTTPClient *http.Client
+	// DialOptions 额外的 gRPC 拨号参数，未指定传输凭证时使用明文
+	DialOptions []grpc.DialOption
+}
+
+// Client 转码服务客户端，可并发使用
+type Client struct {
+	opts    Options
+	http    *http.Client
+	conn    *grpc.ClientConn
+	pb      transcodepb.TranscodeServiceClient
+	batchPB transcodepb.TranscodeBatchServiceClient
+}
+
+// New 创建客户端；配置 GRPCAddr 时建立 gRPC 连接（惰性拨号，不等待连通）
+func New(opts Options) (*Client, error) {
+	if opts.BaseURL == "" && opts.GRPCAddr == "" {
+		return nil, fmt.Errorf("transcode client: BaseURL or GRPCAddr is required")
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Retry.MaxAttempts <= 0 {
+		opts.Retry.MaxAttempts = 3
+	}
+	if opts.Retry.InitialBackoff <= 0 {
+		opts.Retry.InitialBackoff = 200 * time.Millisecond
+	}
+	if opts.Retry.MaxBackoff <= 0 {
+		opts.Retry.MaxBackoff = 5 * time.Second
+	}
+	opts.BaseURL = strings.TrimRight(opts.BaseURL, "/")
+	c := &Client{opts: opts, http: opts.HTTPClient}
+	if c.http == nil {
+		c.http = &http.Client{Timeout: opts.Timeout}
+	}
+	if opts.GRPCAddr != "" {
+		dial := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, opts.DialOptions...)
+		conn, err := grpc.NewClient(opts.GRPCAddr, dial...)
+		if err != nil {
+			return nil, fmt.Errorf("transcode client: dial %s: %w", opts.GRPCAddr, err)
+		}
+		c.conn = conn
+		c.pb = transcodepb.NewTranscodeServiceClient(conn)
+		c.batchPB = transcodepb.NewTranscodeBatchServiceClient(conn)
+	}
+	return c, nil
+}
+
+// Close 关闭 gRPC 连接
+func (c *Client) Close() error {
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// retry 按 RetryPolicy 执行 fn，只重试 Retryable 错误，ctx 结束时立即返回
+func (c *Client) retry(ctx context.Context, fn func(ctx context.Context) error) error {
+	p := c.opts.Retry
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil || attempt >= p.MaxAttempts || !IsRetryable(err) {
+			return err
+		}
+		backoff := time.Duration(float64(p.InitialBackoff) * math.Pow(2, float64(attempt-1)))
+		if backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// envelope 服务端统一响应信封
+type envelope struct {
+	Code      int             `json:"code"`
+	Message   string          `json:"message"`
+	Data      json.RawMessage `json:"data"`
+	RequestID string          `json:"request_id"`
+}
+
+// doJSON 发送 HTTP 请求并把 data 解码到 out；非 200 或业务码非 200 时返回 *Error
+func (c *Client) doJSON(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	if c.opts.BaseURL == "" {
+		return fmt.Errorf("transcode client: BaseURL is not configured")
+	}
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	u := c.opts.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return c.retry(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.opts.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.opts.Token)
+		}
+		resp, err := c.http.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return &Error{Class: ClassUnavailable, Message: err.Error()}
+		}
+		defer resp.Body.Close()
+		raw, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+		if err != nil {
+			return &Error{Class: ClassUnavailable, Message: err.Error(), HTTPStatus: resp.StatusCode}
+		}
+		var env envelope
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return &Error{Class: classForHTTP(resp.StatusCode, 0), Message: fmt.Sprintf("unexpected response: %s", truncate(raw)), HTTPStatus: resp.StatusCode}
+		}
+		if resp.StatusCode != http.StatusOK || (env.Code != 0 && env.Code != http.StatusOK) {
+			return &Error{
+				Class:      classForHTTP(resp.StatusCode, env.Code),
+				Code:       env.Code,
+				Message:    env.Message,
+				HTTPStatus: resp.StatusCode,
+				RequestID:  env.RequestID,
+			}
+		}
+		if out == nil || len(env.Data) == 0 {
+			return nil
+		}
+		return json.Unmarshal(env.Data, out)
+	})
+}
+
+func truncate(b []byte) string {
+	if len(b) > 256 {
+		return string(b[:256]) + "..."
+	}
+	return string(b)
+}
+
+// newIdempotencyKey 客户端生成的幂等键
+func newIdempotencyKey() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return "sdk-" + hex.EncodeToString(b[:])
+}
+
+// CreateTask 创建任务（POST /api/v2/tasks）。未指定幂等键时自动生成，重试不会重复创建
+func (c *Client) CreateTask(ctx context.Context, req *CreateTaskRequest) (*Task, error) {
+	r := *req
+	if r.IdempotencyKey == "" {
+		r.IdempotencyKey = newIdempotencyKey()
+	}
+	var task Task
+	if err := c.doJSON(ctx, http.MethodPost, "/api/v2/tasks", nil, &r, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// GetTask 查询任务（GET /api/v2/tasks/:task_uuid）
+func (c *Client) GetTask(ctx context.Context, taskUUID string) (*Task, error) {
+	var task Task
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v2/tasks/"+url.PathEscape(taskUUID), nil, nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ListTasks 分页查询任务（GET /api/v2/tasks）
+func (c *Client) ListTasks(ctx context.Context, opts ListTasksOptions) (*TaskPage, error) {
+	q := url.Values{}
+	if opts.UserUUID != "" {
+		q.Set("user_uuid", opts.UserUUID)
+	}
+	if opts.Labels != "" {
+		q.Set("labels", opts.Labels)
+	}
+	if opts.Page > 0 {
+		q.Set("page_num", strconv.Itoa(opts.Page))
+	}
+	if opts.Size > 0 {
+		q.Set("page_size", strconv.Itoa(opts.Size))
+	}
+	var page struct {
+		PageInfo struct {
+			TotalNum    int64 `json:"total_num"`
+			CurrentPage int   `json:"current_page"`
+			PageSize    int   `json:"page_size"`
+		} `json:"page_info"`
+		Rows []*Task `json:"rows"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v2/tasks", q, nil, &page); err != nil {
+		return nil, err
+	}
+	return &TaskPage{Total: page.PageInfo.TotalNum, Page: page.PageInfo.CurrentPage, Size: page.PageInfo.PageSize, Tasks: page.Rows}, nil
+}
+
+// CancelTask 取消任务（POST /api/v2/tasks/:task_uuid/cancel），返回取消后的任务
+func (c *Client) CancelTask(ctx context.Context, taskUUID string) (*Task, error) {
+	var task Task
+	if err := c.doJSON(ctx, http.MethodPost, "/api/v2/tasks/"+url.PathEscape(taskUUID)+"/cancel", nil, struct{}{}, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// BoostPriority 提升排队任务优先级（1-10），只对 pending 任务生效
+func (c *Client) BoostPriority(ctx context.Context, taskUUID string, priority int, reason string) (*Task, error) {
+	body := map[string]interface{}{"priority": priority, "reason": reason}
+	var task Task
+	if err := c.doJSON(ctx, http.MethodPost, "/api/v2/tasks/"+url.PathEscape(taskUUID)+"/priority", nil, body, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// BatchStatus 按视频批量查询最新任务的精简状态（POST /inner/v1/tasks/batch-status，单次最多 500 个）
+func (c *Client) BatchStatus(ctx context.Context, videoUUIDs []string) ([]TaskStatus, error) {
+	var records []TaskStatus
+	body := map[string]interface{}{"video_uuids": videoUUIDs}
+	if err := c.doJSON(ctx, http.MethodPost, "/inner/v1/tasks/batch-status", nil, body, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
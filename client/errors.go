@@ -0,0 +1,138 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorClass 错误类别，与服务端 /api/v2 的 HTTP 状态映射一致
+type ErrorClass string
+
+const (
+	ClassValidation   ErrorClass = "validation"   // 400，参数错误，重试无意义
+	ClassUnauthorized ErrorClass = "unauthorized" // 401
+	ClassNotFound     ErrorClass = "not_found"    // 404
+	ClassConflict     ErrorClass = "conflict"     // 409，任务已存在或状态不允许
+	ClassRateLimited  ErrorClass = "rate_limited" // 429
+	ClassUnavailable  ErrorClass = "unavailable"  // 503 或连接失败，可重试
+	ClassInternal     ErrorClass = "internal"     // 其他 5xx
+)
+
+// 常用业务错误码（pkg/errno），用于 Error.Code 的精确判断
+const (
+	CodeTaskNotFound      = 20008
+	CodeTaskExists        = 20010
+	CodeWorkerUnavailable = 20011
+	CodeQueueFull         = 20012
+	CodeTaskNotPending    = 20031
+	CodeReplayRateLimited = 20043
+)
+
+// Error 服务端返回的错误。HTTP 接口带 errno 业务码，gRPC 接口只有状态码与消息
+type Error struct {
+	Class      ErrorClass
+	Code       int    // errno 业务码，gRPC 错误为 0
+	Message    string // 服务端错误消息
+	HTTPStatus int    // HTTP 状态码，gRPC 错误为 0
+	GRPCCode   codes.Code
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("transcode: %s (code=%d): %s", e.Class, e.Code, e.Message)
+	}
+	return fmt.Sprintf("transcode: %s: %s", e.Class, e.Message)
+}
+
+// Retryable 服务繁忙、限流或内部错误时可重试
+func (e *Error) Retryable() bool {
+	switch e.Class {
+	case ClassUnavailable, ClassRateLimited, ClassInternal:
+		return true
+	}
+	return e.Code == CodeQueueFull || e.Code == CodeWorkerUnavailable
+}
+
+// classForHTTP 按 HTTP 状态分类；v1 与 inner 接口错误统一为 500，再按业务码细分
+func classForHTTP(status, code int) ErrorClass {
+	switch status {
+	case http.StatusBadRequest:
+		return ClassValidation
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ClassUnauthorized
+	case http.StatusNotFound:
+		return ClassNotFound
+	case http.StatusConflict:
+		return ClassConflict
+	case http.StatusTooManyRequests:
+		return ClassRateLimited
+	case http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return ClassUnavailable
+	}
+	switch code {
+	case CodeTaskNotFound:
+		return ClassNotFound
+	case CodeTaskExists, CodeTaskNotPending:
+		return ClassConflict
+	case CodeReplayRateLimited:
+		return ClassRateLimited
+	case CodeQueueFull, CodeWorkerUnavailable:
+		return ClassUnavailable
+	}
+	if code >= 400 && code < 500 {
+		return ClassValidation
+	}
+	return ClassInternal
+}
+
+// fromGRPC 把 gRPC 状态转为 *Error，非 gRPC 错误（如 ctx 取消）原样返回
+func fromGRPC(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.OK {
+		return err
+	}
+	e := &Error{Message: st.Message(), GRPCCode: st.Code()}
+	switch st.Code() {
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		e.Class = ClassValidation
+	case codes.Unauthenticated, codes.PermissionDenied:
+		e.Class = ClassUnauthorized
+	case codes.NotFound:
+		e.Class = ClassNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		e.Class = ClassConflict
+	case codes.ResourceExhausted:
+		e.Class = ClassRateLimited
+	case codes.Unavailable, codes.DeadlineExceeded:
+		e.Class = ClassUnavailable
+	case codes.Canceled:
+		return err
+	default:
+		e.Class = ClassInternal
+	}
+	return e
+}
+
+// ClassOf 返回错误类别，非 *Error 返回空串
+func ClassOf(err error) ErrorClass {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Class
+	}
+	return ""
+}
+
+func IsNotFound(err error) bool   { return ClassOf(err) == ClassNotFound }
+func IsValidation(err error) bool { return ClassOf(err) == ClassValidation }
+func IsConflict(err error) bool   { return ClassOf(err) == ClassConflict }
+
+// IsRetryable 调用方自行重试时使用；客户端内部已按 RetryPolicy 重试过
+func IsRetryable(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Retryable()
+}
@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	transcodepb "transcode-service/api/transcode"
+)
+
+// gRPC 响应 header 与请求 metadata，与 ddd/adapter/grpc 保持一致
+const (
+	headerQueuePosition    = "x-queue-position"
+	headerEstimatedStartAt = "x-estimated-start-at"
+	headerVideoProgress    = "x-video-progress"
+	headerTaskLabels       = "x-task-labels"
+)
+
+// GRPCTask gRPC GetTranscodeTask 的结果，排队信息与视频进度来自响应 header，产物列表来自 outputs 字段
+type GRPCTask struct {
+	TaskUUID         string
+	VideoUUID        string
+	Status           string
+	Progress         int
+	VideoProgress    int
+	OutputPath       string
+	ErrorMessage     string
+	QueuePosition    int
+	EstimatedStartAt *time.Time
+	Outputs          []GRPCOutput // 仅 completed 返回
+}
+
+// GRPCOutput GetTranscodeTaskResponse.outputs 中的单个产物
+type GRPCOutput struct {
+	Type       string // video | audio | hls_master | hls_rendition
+	Resolution string
+	URL        string // 私有产物为签名 URL
+	Size       int64  // 字节数，未知时为 0
+}
+
+func (c *Client) grpcContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if c.pb == nil {
+		return nil, nil, fmt.Errorf("transcode client: GRPCAddr is not configured")
+	}
+	if c.opts.Token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.opts.Token)
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	return ctx, cancel, nil
+}
+
+// CreateTaskGRPC 经 gRPC 创建任务，返回 task_uuid。proto 只有基础字段，标签经 x-task-labels 传递。
+// 服务端以 success=false + message 表示业务失败，这里按消息归类为 *Error
+func (c *Client) CreateTaskGRPC(ctx context.Context, req *CreateTaskRequest) (string, error) {
+	var taskUUID string
+	err := c.retry(ctx, func(ctx context.Context) error {
+		ctx, cancel, err := c.grpcContext(ctx)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+		if len(req.Labels) > 0 {
+			pairs := make([]string, 0, len(req.Labels))
+			for k, v := range req.Labels {
+				pairs = append(pairs, k+"="+v)
+			}
+			ctx = metadata.AppendToOutgoingContext(ctx, headerTaskLabels, strings.Join(pairs, ","))
+		}
+		resp, err := c.pb.CreateTranscodeTask(ctx, &transcodepb.CreateTranscodeTaskRequest{
+			UserUuid:         req.UserUUID,
+			VideoUuid:        req.VideoUUID,
+			InputPath:        req.OriginalPath,
+			TargetResolution: req.Resolution,
+			TargetBitrate:    req.Bitrate,
+		})
+		if err != nil {
+			return fromGRPC(err)
+		}
+		if !resp.GetSuccess() {
+			return classifyMessage(resp.GetMessage())
+		}
+		taskUUID = resp.GetTaskUuid()
+		return nil
+	})
+	return taskUUID, err
+}
+
+// GetTaskGRPC 经 gRPC 查询任务
+func (c *Client) GetTaskGRPC(ctx context.Context, taskUUID string) (*GRPCTask, error) {
+	var task *GRPCTask
+	err := c.retry(ctx, func(ctx context.Context) error {
+		ctx, cancel, err := c.grpcContext(ctx)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+		var header metadata.MD
+		resp, err := c.pb.GetTranscodeTask(ctx, &transcodepb.GetTranscodeTaskRequest{TaskUuid: taskUUID}, grpc.Header(&header))
+		if err != nil {
+			return fromGRPC(err)
+		}
+		if !resp.GetSuccess() {
+			return classifyMessage(resp.GetErrorMessage())
+		}
+		task = &GRPCTask{
+			TaskUUID:     resp.GetTaskUuid(),
+			VideoUUID:    resp.GetVideoUuid(),
+			Status:       resp.GetStatus(),
+			Progress:     int(resp.GetProgress()),
+			OutputPath:   resp.GetOutputPath(),
+			ErrorMessage: resp.GetErrorMessage(),
+			Outputs:      toGRPCOutputs(resp.GetOutputs()),
+		}
+		task.VideoProgress, _ = strconv.Atoi(first(header, headerVideoProgress))
+		task.QueuePosition, _ = strconv.Atoi(first(header, headerQueuePosition))
+		if v := first(header, headerEstimatedStartAt); v != "" {
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				task.EstimatedStartAt = &t
+			}
+		}
+		return nil
+	})
+	return task, err
+}
+
+// BatchStatusGRPC 经 gRPC 批量查询（TranscodeBatchService）
+func (c *Client) BatchStatusGRPC(ctx context.Context, videoUUIDs []string) ([]TaskStatus, error) {
+	in := &transcodepb.GetTranscodeTasksByVideoUUIDsRequest{VideoUuids: videoUUIDs}
+	var records []TaskStatus
+	err := c.retry(ctx, func(ctx context.Context) error {
+		ctx, cancel, err := c.grpcContext(ctx)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+		out, err := c.batchPB.GetTranscodeTasksByVideoUUIDs(ctx, in)
+		if err != nil {
+			return fromGRPC(err)
+		}
+		records = records[:0]
+		for _, r := range out.GetRecords() {
+			rec := TaskStatus{
+				VideoUUID:    r.GetVideoUuid(),
+				Found:        r.GetFound(),
+				TaskUUID:     r.GetTaskUuid(),
+				Status:       r.GetStatus(),
+				Progress:     int(r.GetProgress()),
+				OutputPath:   r.GetOutputPath(),
+				ErrorMessage: r.GetErrorMessage(),
+			}
+			if t, err := time.Parse(time.RFC3339Nano, r.GetUpdatedAt()); err == nil {
+				rec.UpdatedAt = &t
+			}
+			records = append(records, rec)
+		}
+		return nil
+	})
+	return records, err
+}
+
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// classifyMessage 按 TranscodeService 的失败消息归类（proto 未携带错误码）
+func classifyMessage(msg string) error {
+	lower := strings.ToLower(msg)
+	e := &Error{Class: ClassInternal, Message: msg}
+	switch {
+	case strings.Contains(lower, "already exists"):
+		e.Class, e.Code = ClassConflict, CodeTaskExists
+	case strings.Contains(lower, "not found"):
+		e.Class, e.Code = ClassNotFound, CodeTaskNotFound
+	case strings.Contains(lower, "busy"), strings.Contains(lower, "unavailable"):
+		e.Class = ClassUnavailable
+	case strings.Contains(lower, "required"), strings.Contains(lower, "invalid"):
+		e.Class = ClassValidation
+	}
+	return e
+}
+
+func toGRPCOutputs(outputs []*transcodepb.TranscodeOutput) []GRPCOutput {
+	if len(outputs) == 0 {
+		return nil
+	}
+	out := make([]GRPCOutput, 0, len(outputs))
+	for _, o := range outputs {
+		out = append(out, GRPCOutput{Type: o.GetType(), Resolution: o.GetResolution(), URL: o.GetUrl(), Size: o.GetSize()})
+	}
+	return out
+}
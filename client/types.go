@@ -0,0 +1,157 @@
+package client
+
+import "time"
+
+// 任务状态
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusRetrying   = "retrying"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+	StatusCancelled  = "cancelled"
+	StatusExpired    = "expired"
+	StatusRejected   = "rejected" // 输入超出时长、大小或分辨率上限，重新提交同一输入无意义
+)
+
+// IsTerminal 任务是否已结束，不会再变化
+func IsTerminal(status string) bool {
+	switch status {
+	case StatusCompleted, StatusFailed, StatusCancelled, StatusExpired, StatusRejected:
+		return true
+	}
+	return false
+}
+
+// CreateTaskRequest POST /api/v2/tasks 请求体，常用字段；完整字段见 /api/openapi.json
+type CreateTaskRequest struct {
+	UserUUID      string            `json:"user_uuid"`
+	VideoUUID     string            `json:"video_uuid"`
+	VideoPushUUID string            `json:"video_push_uuid,omitempty"`
+	OriginalPath  string            `json:"original_path"`
+	Resolution    string            `json:"resolution,omitempty"`
+	Bitrate       string            `json:"bitrate,omitempty"`
+	Container     string            `json:"container,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Profile       string            `json:"profile,omitempty"`
+	FormatSet     string            `json:"format_set,omitempty"`
+	MaxRenditions int               `json:"max_renditions,omitempty"`
+	// IdempotencyKey 为空时客户端自动生成，保证重试不会重复创建任务
+	IdempotencyKey  string               `json:"idempotency_key,omitempty"`
+	EnableHLS       bool                 `json:"enable_hls,omitempty"`
+	HLSResolutions  []HLSResolution      `json:"hls_resolutions,omitempty"`
+	SegmentDuration int                  `json:"segment_duration,omitempty"`
+	AudioOutputs    []AudioOutputRequest `json:"audio_outputs,omitempty"`
+	AudioTags       map[string]string    `json:"audio_tags,omitempty"`
+	Preview         bool                 `json:"preview,omitempty"`
+	PreviewSeconds  int                  `json:"preview_seconds,omitempty"`
+}
+
+// HLSResolution HLS 档位
+type HLSResolution struct {
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Bitrate string `json:"bitrate"`
+}
+
+// AudioOutputRequest 纯音频产物
+type AudioOutputRequest struct {
+	Format  string `json:"format"`
+	Bitrate string `json:"bitrate,omitempty"`
+}
+
+// Task /api/v2 任务资源
+type Task struct {
+	TaskUUID       string            `json:"task_uuid"`
+	UserUUID       string            `json:"user_uuid"`
+	VideoUUID      string            `json:"video_uuid"`
+	ParentTaskUUID string            `json:"parent_task_uuid,omitempty"`
+	Status         string            `json:"status"`
+	Progress       int               `json:"progress"`
+	VideoProgress  int               `json:"video_progress"`
+	Stages         []StageProgress   `json:"stages"`
+	Timings        *TaskTimings      `json:"timings,omitempty"`
+	Source         TaskSource        `json:"source"`
+	Output         TaskOutput        `json:"output"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	Queue          *TaskQueue        `json:"queue,omitempty"`
+	Error          *TaskError        `json:"error,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// StageProgress 阶段进度
+type StageProgress struct {
+	Stage    string `json:"stage"`
+	Weight   int    `json:"weight"`
+	Progress int    `json:"progress"`
+}
+
+// TaskTimings 耗时拆分（毫秒）
+type TaskTimings struct {
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	QueueWaitMs  *int64     `json:"queue_wait_ms,omitempty"`
+	SourceWaitMs *int64     `json:"source_wait_ms,omitempty"`
+	DownloadMs   *int64     `json:"download_ms,omitempty"`
+	EncodeMs     *int64     `json:"encode_ms,omitempty"`
+	UploadMs     *int64     `json:"upload_ms,omitempty"`
+	TotalMs      *int64     `json:"total_ms,omitempty"`
+}
+
+// TaskSource 任务输入
+type TaskSource struct {
+	Path       string `json:"path"`
+	Generation int64  `json:"generation,omitempty"`
+}
+
+// TaskOutput 任务产物
+type TaskOutput struct {
+	Path          string `json:"path"`
+	Resolution    string `json:"resolution"`
+	Bitrate       string `json:"bitrate"`
+	Container     string `json:"container"`
+	FormatSet     string `json:"format_set,omitempty"`
+	MaxRenditions int    `json:"max_renditions,omitempty"`
+}
+
+// TaskQueue 排队信息，仅 pending 返回
+type TaskQueue struct {
+	Position         int        `json:"position"`
+	Priority         int        `json:"priority"`
+	EstimatedStartAt *time.Time `json:"estimated_start_at,omitempty"`
+}
+
+// TaskError 任务失败原因
+type TaskError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// TaskPage 任务列表的一页
+type TaskPage struct {
+	Total int64   `json:"total"`
+	Page  int     `json:"page"`
+	Size  int     `json:"size"`
+	Tasks []*Task `json:"tasks"`
+}
+
+// ListTasksOptions 任务列表筛选，Labels 为标签选择器，如 campaign=summer,source=mobile-app
+type ListTasksOptions struct {
+	UserUUID string
+	Labels   string
+	Page     int
+	Size     int
+}
+
+// TaskStatus 批量对账使用的精简状态，每个请求的视频一条
+type TaskStatus struct {
+	VideoUUID    string     `json:"video_uuid"`
+	Found        bool       `json:"found"`
+	TaskUUID     string     `json:"task_uuid,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	Progress     int        `json:"progress"`
+	OutputPath   string     `json:"output_path,omitempty"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
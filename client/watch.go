@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// WatchOptions 任务进度轮询参数
+type WatchOptions struct {
+	Interval    time.Duration // 轮询间隔，默认 2s
+	MaxInterval time.Duration // 任务长时间无变化时逐步放宽到该间隔，默认 15s
+}
+
+// TaskUpdate 进度变化事件；Err 非空时为最后一个事件
+type TaskUpdate struct {
+	Task *Task
+	Err  error
+}
+
+func (o *WatchOptions) normalize() {
+	if o.Interval <= 0 {
+		o.Interval = 2 * time.Second
+	}
+	if o.MaxInterval < o.Interval {
+		o.MaxInterval = 15 * time.Second
+		if o.MaxInterval < o.Interval {
+			o.MaxInterval = o.Interval
+		}
+	}
+}
+
+// WatchTask 轮询任务，状态、进度或整体进度变化时推送；任务结束、出错或 ctx 结束后关闭通道。
+// 可重试错误已在单次查询内重试，仍失败时以 Err 推送并结束
+func (c *Client) WatchTask(ctx context.Context, taskUUID string, opts WatchOptions) <-chan TaskUpdate {
+	opts.normalize()
+	ch := make(chan TaskUpdate, 1)
+	go func() {
+		defer close(ch)
+		var last *Task
+		interval := opts.Interval
+		for {
+			task, err := c.GetTask(ctx, taskUUID)
+			if err != nil {
+				if ctx.Err() == nil {
+					send(ctx, ch, TaskUpdate{Err: err})
+				}
+				return
+			}
+			if last == nil || changed(last, task) {
+				if !send(ctx, ch, TaskUpdate{Task: task}) {
+					return
+				}
+				interval = opts.Interval
+			} else if interval *= 2; interval > opts.MaxInterval {
+				interval = opts.MaxInterval
+			}
+			if IsTerminal(task.Status) {
+				return
+			}
+			last = task
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+	return ch
+}
+
+// WaitTask 阻塞到任务结束并返回最终状态；失败、取消、过期仍返回任务本身，由调用方检查 Status 与 Error
+func (c *Client) WaitTask(ctx context.Context, taskUUID string, opts WatchOptions) (*Task, error) {
+	var last *Task
+	for u := range c.WatchTask(ctx, taskUUID, opts) {
+		if u.Err != nil {
+			return last, u.Err
+		}
+		last = u.Task
+	}
+	if last == nil || !IsTerminal(last.Status) {
+		return last, ctx.Err()
+	}
+	return last, nil
+}
+
+func changed(a, b *Task) bool {
+	return a.Status != b.Status || a.Progress != b.Progress || a.VideoProgress != b.VideoProgress
+}
+
+func send(ctx context.Context, ch chan<- TaskUpdate, u TaskUpdate) bool {
+	select {
+	case ch <- u:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
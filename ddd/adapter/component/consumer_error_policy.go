@@ -0,0 +1,150 @@
+package component
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	pkgkafka "transcode-service/pkg/kafka"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// validationCodes 请求参数类错误，重试无意义
+var validationCodes = map[int]struct{}{
+	errno.ErrInvalidParam.Code:           {},
+	errno.ErrMissingParam.Code:           {},
+	errno.ErrUserUUIDRequired.Code:       {},
+	errno.ErrVideoUUIDRequired.Code:      {},
+	errno.ErrOriginalPathRequired.Code:   {},
+	errno.ErrResolutionRequired.Code:     {},
+	errno.ErrBitrateRequired.Code:        {},
+	errno.ErrHLSResolutionsRequired.Code: {},
+	errno.ErrInvalidHLSResolution.Code:   {},
+	errno.ErrHLSBitrateRequired.Code:     {},
+	errno.ErrInvalidLabels.Code:          {},
+	errno.ErrInvalidStreamSelection.Code: {},
+	errno.ErrInvalidProfile.Code:         {},
+	errno.ErrInvalidAudioOutput.Code:     {},
+	errno.ErrInvalidFormatSet.Code:       {},
+	errno.ErrUnsafeInputPath.Code:        {},
+	errno.ErrTaskGroupNotFound.Code:      {},
+	errno.ErrTaskGroupClosed.Code:        {},
+}
+
+// classifyProcessError 把创建任务的错误归类为 validation/duplicate/storage/internal
+func classifyProcessError(err error) string {
+	code := 0
+	var no *errno.Errno
+	if errors.As(err, &no) {
+		code = no.Code
+	} else {
+		code = errno.AssertBizError(err).Code()
+	}
+	if _, ok := validationCodes[code]; ok {
+		return config.KafkaErrorValidation
+	}
+	if code == errno.ErrTranscodeTaskExists.Code || strings.Contains(err.Error(), "Duplicate entry") {
+		return config.KafkaErrorDuplicate
+	}
+	if code == errno.ErrDatabase.Code {
+		return config.KafkaErrorStorage
+	}
+	return config.KafkaErrorInternal
+}
+
+// recordDecision 按错误类别与处理动作计数：kafka_consumer_<class>_<decision>_total
+func recordDecision(class, decision string) {
+	metrics.Add("kafka_consumer_"+class+"_"+decision+"_total", 1)
+}
+
+// handleFailure 按错误类别执行处理动作；retry 时 attempt 为原地重试函数，返回 nil 表示重试成功。
+// 返回后消息已提交（commit/dlq）或因死信写入失败、停机、排空超时保持未提交
+func (c *transcodeTaskConsumer) handleFailure(d dispatched, class string, err error, attempt func() error, workerID int) {
+	ctx, msg := d.ctx, d.msg
+	if ctx.Err() != nil {
+		// 停机或排空超时打断的失败不代表消息本身有问题，不提交也不写死信，由重启后或新的分区持有者重新消费
+		logger.Warnf("Kafka message interrupted by shutdown or rebalance, left uncommitted partition=%d offset=%d worker=%d error=%v", msg.Partition, msg.Offset, workerID, err)
+		return
+	}
+	action := c.policy.Action(class)
+	if action.Action == config.KafkaActionRetry {
+		if attempt == nil {
+			action.Action = action.OnExhausted
+		} else {
+			for i := 1; i <= action.Retries; i++ {
+				recordDecision(class, "retry")
+				logger.Warnf("Kafka message failed, retrying class=%s attempt=%d/%d partition=%d offset=%d worker=%d error=%v", class, i, action.Retries, msg.Partition, msg.Offset, workerID, err)
+				select {
+				case <-ctx.Done():
+					// 停机时不提交，重启后重新消费
+					return
+				case <-time.After(time.Duration(i) * action.Backoff):
+				}
+				if err = attempt(); err == nil {
+					recordDecision(class, "retry_succeeded")
+					c.commit(d, workerID)
+					return
+				}
+				if ctx.Err() != nil {
+					return
+				}
+			}
+			recordDecision(class, "retry_exhausted")
+			action.Action = action.OnExhausted
+		}
+	}
+	switch action.Action {
+	case config.KafkaActionDLQ:
+		if dlqErr := c.sendToDLQ(ctx, msg, class, err); dlqErr != nil {
+			recordDecision(class, "dlq_failed")
+			logger.Errorf("Kafka DLQ write failed, message left uncommitted class=%s partition=%d offset=%d worker=%d error=%v", class, msg.Partition, msg.Offset, workerID, dlqErr)
+			return
+		}
+		recordDecision(class, "dlq")
+		metrics.Add("kafka_consumer_dlq_total", 1)
+		logger.Warnf("Kafka message sent to DLQ class=%s topic=%s partition=%d offset=%d worker=%d error=%v", class, c.policy.DLQTopic, msg.Partition, msg.Offset, workerID, err)
+	default:
+		recordDecision(class, "commit")
+		logger.Warnf("Kafka message dropped class=%s partition=%d offset=%d worker=%d error=%v", class, msg.Partition, msg.Offset, workerID, err)
+	}
+	c.commit(d, workerID)
+}
+
+// sendToDLQ 原样写入死信主题，附带来源位置与错误信息头
+func (c *transcodeTaskConsumer) sendToDLQ(ctx context.Context, msg kafka.Message, class string, cause error) error {
+	if c.policy.DLQTopic == "" {
+		return errors.New("kafka.error_policy.dlq_topic is not configured")
+	}
+	headers := append([]kafka.Header{}, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: "dlq-source-topic", Value: []byte(msg.Topic)},
+		kafka.Header{Key: "dlq-source-partition", Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: "dlq-source-offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: "dlq-error-class", Value: []byte(class)},
+		kafka.Header{Key: "dlq-error", Value: []byte(cause.Error())},
+	)
+	return pkgkafka.DefaultClient().Writer(c.policy.DLQTopic).WriteMessages(ctx, kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+		Time:    time.Now(),
+	})
+}
+
+// commit 通过消息所属代次提交位点。代次结束后、排空完成前消费组尚未重新加入，
+// 已处理完成的消息仍可在原代次内提交，避免被新的分区持有者重复消费
+func (c *transcodeTaskConsumer) commit(d dispatched, workerID int) {
+	msg := d.msg
+	if err := d.gen.CommitOffsets(map[string]map[int]int64{msg.Topic: {msg.Partition: msg.Offset + 1}}); err != nil {
+		logger.Warnf("Kafka commit error error=%s partition=%d offset=%d worker=%d", err.Error(), msg.Partition, msg.Offset, workerID)
+	} else {
+		logger.Infof("Kafka commit done partition=%d offset=%d worker=%d", msg.Partition, msg.Offset, workerID)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"sync"
@@ -19,6 +20,7 @@ import (
 	pkgkafka "transcode-service/pkg/kafka"
 	"transcode-service/pkg/logger"
 	"transcode-service/pkg/manager"
+	"transcode-service/pkg/metrics"
 	"transcode-service/pkg/task"
 
 	kafka "github.com/segmentio/kafka-go"
@@ -42,24 +44,41 @@ func (p *TranscodeTaskConsumerPlugin) MustCreateComponent(deps *manager.Dependen
 }
 
 type transcodeTaskConsumer struct {
-	app                  appsvc.TranscodeApp
-	ctx                  context.Context
-	cancel               context.CancelFunc
-	repo                 repo.TranscodeJobRepository
-	reader               *kafka.Reader
-	msgCh                chan kafka.Message
-	wgRead               sync.WaitGroup
-	wgProc               sync.WaitGroup
-	max                  int
-	interval             time.Duration
-	topic                string
-	group                string
-	commitOnDecodeError  bool
-	commitOnProcessError bool
+	app          appsvc.TranscodeApp
+	ctx          context.Context
+	cancel       context.CancelFunc
+	repo         repo.TranscodeJobRepository
+	group        *kafka.ConsumerGroup
+	msgCh        chan dispatched
+	wgRead       sync.WaitGroup
+	wgProc       sync.WaitGroup
+	max          int
+	interval     time.Duration
+	topic        string
+	groupID      string
+	policy       config.KafkaErrorPolicyConfig
+	drainTimeout time.Duration
+	stopOnce     sync.Once
+}
+
+// dispatched 已派发给处理协程的消息，位点通过所属代次提交
+type dispatched struct {
+	msg kafka.Message
+	gen *kafka.Generation
+	// genCtx 代次结束（再均衡）后尚未开始处理的消息直接跳过
+	genCtx context.Context
+	// ctx 排空超时或停机时取消，中止进行中的创建
+	ctx  context.Context
+	done func()
 }
 
 func (c *transcodeTaskConsumer) Start() error {
-	task.Register(&backgroundTaskAdapter{name: "kafka-consumer", startFunc: c.startInternal, stopFunc: c.Stop})
+	if manager.IsDegraded("kafka") {
+		logger.Warnf("Kafka unavailable at startup, consumer disabled; tasks can still be created via gRPC/HTTP")
+		return nil
+	}
+	task.Register(&backgroundTaskAdapter{name: "kafka-consumer", startFunc: c.startInternal, stopFunc: c.Stop, phase: manager.PhaseIngest, inFlight: c.InFlight})
+	task.Register(pkgkafka.DefaultLagMonitor())
 	// 由 TaskManager 统一启动
 	return nil
 }
@@ -75,13 +94,13 @@ func (c *transcodeTaskConsumer) startInternal(ctx context.Context) error {
 			c.interval = cfg.Worker.TaskPollInterval
 		}
 		if cfg.Kafka.GroupID != "" {
-			c.group = cfg.Kafka.GroupID
+			c.groupID = cfg.Kafka.GroupID
 		}
 		if cfg.Kafka.Topics.TranscodeTasks != "" {
 			c.topic = cfg.Kafka.Topics.TranscodeTasks
 		}
-		c.commitOnDecodeError = cfg.Kafka.CommitOnDecodeError
-		c.commitOnProcessError = cfg.Kafka.CommitOnProcessError
+		c.policy = cfg.Kafka.ErrorPolicy
+		c.drainTimeout = cfg.Kafka.RebalanceDrainTimeout
 	}
 	if c.max <= 0 {
 		c.max = 1
@@ -89,9 +108,17 @@ func (c *transcodeTaskConsumer) startInternal(ctx context.Context) error {
 	if c.interval <= 0 {
 		c.interval = time.Second
 	}
-	c.reader = pkgkafka.DefaultClient().Reader(c.topic, c.group)
-	c.msgCh = make(chan kafka.Message, c.max)
-	logger.Infof("Kafka consumer started topic=%s group=%s", c.topic, c.group)
+	if c.drainTimeout <= 0 {
+		c.drainTimeout = 20 * time.Second
+	}
+	group, err := pkgkafka.DefaultClient().ConsumerGroup(c.topic, c.groupID)
+	if err != nil {
+		c.cancel()
+		return err
+	}
+	c.group = group
+	c.msgCh = make(chan dispatched, c.max)
+	logger.Infof("Kafka consumer started topic=%s group=%s drain_timeout=%s", c.topic, c.groupID, c.drainTimeout)
 	c.wgRead.Add(1)
 	go c.consumeLoop()
 	for i := 0; i < c.max; i++ {
@@ -101,22 +128,37 @@ func (c *transcodeTaskConsumer) startInternal(ctx context.Context) error {
 	return nil
 }
 
+// Stop 先停止拉取并排空各分区已派发的消息（位点在代次内提交），再离开消费组。
+// 后台任务与组件两条停止路径都会调用，只执行一次
 func (c *transcodeTaskConsumer) Stop() error {
-	if c.cancel != nil {
-		c.cancel()
-	}
-	c.wgRead.Wait()
-	if c.msgCh != nil {
-		close(c.msgCh)
-	}
-	c.wgProc.Wait()
-	if c.reader != nil {
-		_ = c.reader.Close()
-	}
+	c.stopOnce.Do(func() {
+		if c.cancel != nil {
+			c.cancel()
+		}
+		c.wgRead.Wait()
+		if c.group != nil {
+			_ = c.group.Close()
+		}
+		if c.msgCh != nil {
+			close(c.msgCh)
+		}
+		c.wgProc.Wait()
+	})
 	return nil
 }
 func (c *transcodeTaskConsumer) GetName() string { return "transcodeTaskConsumer" }
 
+// ShutdownPhase 消费者属于停止接入阶段，离开消费组后才关闭本地队列，避免入队到已关闭的队列
+func (c *transcodeTaskConsumer) ShutdownPhase() manager.Phase { return manager.PhaseIngest }
+
+// InFlight 已派发但尚未处理完的消息数
+func (c *transcodeTaskConsumer) InFlight() []string {
+	if c.msgCh == nil || len(c.msgCh) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("buffered_messages=%d", len(c.msgCh))}
+}
+
 func (c *transcodeTaskConsumer) shouldPause(max int) bool {
 	if max <= 0 {
 		max = 1
@@ -133,7 +175,7 @@ func (c *transcodeTaskConsumer) shouldPause(max int) bool {
 	}
 	now := time.Now()
 	if c.repo != nil {
-		if list, err := c.repo.QueryTranscodeJobsByStatus(context.Background(), vo.TaskStatusProcessing, max*2); err == nil {
+		if list, err := c.repo.QueryTranscodeJobsByStatus(c.ctx, vo.TaskStatusProcessing, max*2); err == nil {
 			for _, t := range list {
 				if t == nil {
 					continue
@@ -158,107 +200,202 @@ func (c *transcodeTaskConsumer) shouldPause(max int) bool {
 	return false
 }
 
+// consumeLoop 每次再均衡加入新的代次，为分配到的每个分区启动读取协程；
+// 代次结束时各协程停止派发并排空，全部退出后消费组才会重新加入
 func (c *transcodeTaskConsumer) consumeLoop() {
 	defer c.wgRead.Done()
 	for {
-		if c.ctx.Err() != nil {
-			return
+		gen, err := c.group.Next(c.ctx)
+		if err != nil {
+			if c.ctx.Err() != nil || errors.Is(err, kafka.ErrGroupClosed) {
+				return
+			}
+			logger.Warnf("Kafka consumer group join error error=%s", err.Error())
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-time.After(c.interval):
+			}
+			continue
+		}
+		metrics.Add("kafka_consumer_rebalances_total", 1)
+		assignments := gen.Assignments[c.topic]
+		logger.Infof("Kafka consumer group generation joined generation=%d member=%s partitions=%d", gen.ID, gen.MemberID, len(assignments))
+		for _, a := range assignments {
+			c.wgRead.Add(1)
+			gen.Start(func(genCtx context.Context) {
+				defer c.wgRead.Done()
+				c.consumePartition(genCtx, gen, a)
+			})
 		}
+	}
+}
+
+// consumePartition 从代次分配的位点读取单个分区，代次结束或停机时停止派发并排空
+func (c *transcodeTaskConsumer) consumePartition(genCtx context.Context, gen *kafka.Generation, a kafka.PartitionAssignment) {
+	fetchCtx, cancelFetch := context.WithCancel(genCtx)
+	defer cancelFetch()
+	stop := context.AfterFunc(c.ctx, cancelFetch)
+	defer stop()
+	abortCtx, abort := context.WithCancel(c.ctx)
+	defer abort()
+
+	reader := pkgkafka.DefaultClient().PartitionReader(c.topic, a.ID)
+	defer reader.Close()
+	if err := reader.SetOffset(a.Offset); err != nil {
+		logger.Warnf("Kafka set offset error error=%s partition=%d offset=%d", err.Error(), a.ID, a.Offset)
+	}
+
+	var inflight sync.WaitGroup
+	for fetchCtx.Err() == nil {
 		if c.shouldPause(c.max) {
-			logger.Debug("Kafka consumer paused", map[string]interface{}{"max": c.max, "size": queue.DefaultTaskQueue().Size()})
-			time.Sleep(c.interval)
+			logger.Debug("Kafka consumer paused", map[string]interface{}{"max": c.max, "size": queue.DefaultTaskQueue().Size(), "partition": a.ID})
+			select {
+			case <-fetchCtx.Done():
+			case <-time.After(c.interval):
+			}
 			continue
 		}
-		msg, err := c.reader.FetchMessage(c.ctx)
+		msg, err := reader.FetchMessage(fetchCtx)
 		if err != nil {
-			if c.ctx.Err() != nil {
-				return
+			if fetchCtx.Err() != nil {
+				break
 			}
 			if errors.Is(err, io.EOF) || strings.Contains(err.Error(), "EOF") {
 				logger.Debug("Kafka reader EOF")
 			} else {
-				logger.Warnf("Kafka read error error=%s", err.Error())
+				logger.Warnf("Kafka read error error=%s partition=%d", err.Error(), a.ID)
 			}
 			continue
 		}
+		inflight.Add(1)
 		select {
-		case c.msgCh <- msg:
-		case <-c.ctx.Done():
-			return
+		case c.msgCh <- dispatched{msg: msg, gen: gen, genCtx: genCtx, ctx: abortCtx, done: inflight.Done}:
+		case <-fetchCtx.Done():
+			inflight.Done()
 		}
 	}
+	c.drain(gen.ID, a.ID, &inflight, abort)
+}
+
+// drain 等待分区已派发的消息处理完成；超过 rebalance_drain_timeout 时中止进行中的创建，
+// 被中止的消息不提交，由新的分区持有者重新消费并按幂等键去重
+func (c *transcodeTaskConsumer) drain(generation int32, partition int, inflight *sync.WaitGroup, abort context.CancelFunc) {
+	done := make(chan struct{})
+	go func() {
+		inflight.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(c.drainTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		metrics.Add("kafka_consumer_drain_aborted_total", 1)
+		logger.Warnf("Kafka partition drain timed out, aborting in-flight messages generation=%d partition=%d timeout=%s", generation, partition, c.drainTimeout)
+		abort()
+		<-done
+	}
+	logger.Infof("Kafka partition drained generation=%d partition=%d", generation, partition)
 }
 
 func (c *transcodeTaskConsumer) processLoop(workerID int) {
 	defer c.wgProc.Done()
-	for {
-		select {
-		case <-c.ctx.Done():
-			return
-		case msg, ok := <-c.msgCh:
-			if !ok {
-				return
-			}
-			msgCtx := c.ctx
-			if rid := headerValue(msg.Headers, "request-id"); rid != "" {
-				if ctxWithReq, _ := grpcutil.ContextWithRequestID(msgCtx, rid); ctxWithReq != nil {
-					msgCtx = ctxWithReq
-				}
-			}
-			req, err := c.decodeKafkaMessage(&msg)
-			if err != nil {
-				if c.commitOnDecodeError {
-					if e := c.reader.CommitMessages(c.ctx, msg); e != nil {
-						logger.Warnf("Kafka commit error error=%s partition=%d offset=%d worker=%d", e.Error(), msg.Partition, msg.Offset, workerID)
-					} else {
-						logger.Infof("Kafka commit done partition=%d offset=%d worker=%d", msg.Partition, msg.Offset, workerID)
-					}
-				}
-				continue
-			}
-			if _, err := c.app.CreateTranscodeTask(msgCtx, req); err != nil {
-				if c.commitOnProcessError {
-					if e := c.reader.CommitMessages(c.ctx, msg); e != nil {
-						logger.Warnf("Kafka commit error error=%s partition=%d offset=%d worker=%d", e.Error(), msg.Partition, msg.Offset, workerID)
-					} else {
-						logger.Infof("Kafka commit done partition=%d offset=%d worker=%d", msg.Partition, msg.Offset, workerID)
-					}
-				}
-				time.Sleep(c.interval)
-				continue
-			}
-			if err := c.reader.CommitMessages(c.ctx, msg); err != nil {
-				logger.Warnf("Kafka commit error error=%s partition=%d offset=%d worker=%d", err.Error(), msg.Partition, msg.Offset, workerID)
-			} else {
-				logger.Infof("Kafka commit done partition=%d offset=%d worker=%d", msg.Partition, msg.Offset, workerID)
-			}
+	for d := range c.msgCh {
+		c.process(d, workerID)
+		d.done()
+	}
+}
+
+func (c *transcodeTaskConsumer) process(d dispatched, workerID int) {
+	msg := d.msg
+	if d.genCtx.Err() != nil || d.ctx.Err() != nil {
+		// 再均衡或停机开始后不再处理新消息，位点不提交
+		metrics.Add("kafka_consumer_skipped_on_rebalance_total", 1)
+		logger.Infof("Kafka message skipped after generation ended partition=%d offset=%d worker=%d", msg.Partition, msg.Offset, workerID)
+		return
+	}
+	msgCtx := d.ctx
+	if rid := headerValue(msg.Headers, "request-id"); rid != "" {
+		if ctxWithReq, _ := grpcutil.ContextWithRequestID(msgCtx, rid); ctxWithReq != nil {
+			msgCtx = ctxWithReq
 		}
 	}
+	req, err := c.decodeKafkaMessage(&msg)
+	if err != nil {
+		c.handleFailure(d, config.KafkaErrorDecode, err, nil, workerID)
+		return
+	}
+	create := func() error {
+		_, err := c.app.CreateTranscodeTask(msgCtx, req)
+		return err
+	}
+	if err := create(); err != nil {
+		c.handleFailure(d, classifyProcessError(err), err, create, workerID)
+		return
+	}
+	c.commit(d, workerID)
 }
 
 func (c *transcodeTaskConsumer) decodeKafkaMessage(msg *kafka.Message) (*cqe.CreateTranscodeTaskReq, error) {
 	var m struct {
-		UserUUID         string `json:"user_uuid"`
-		VideoUUID        string `json:"video_uuid"`
-		VideoPushUUID    string `json:"video_push_uuid"`
-		InputPath        string `json:"input_path"`
-		TargetResolution string `json:"target_resolution"`
-		TargetBitrate    string `json:"target_bitrate"`
+		UserUUID         string               `json:"user_uuid"`
+		VideoUUID        string               `json:"video_uuid"`
+		VideoPushUUID    string               `json:"video_push_uuid"`
+		InputPath        string               `json:"input_path"`
+		TargetResolution string               `json:"target_resolution"`
+		TargetBitrate    string               `json:"target_bitrate"`
+		Labels           map[string]string    `json:"labels"`
+		SourceGeneration int64                `json:"source_generation"`
+		VideoStreamIndex *int                 `json:"video_stream_index"`
+		AudioStreamIndex *int                 `json:"audio_stream_index"`
+		Profile          string               `json:"profile"`
+		Source           *vo.SourceHints      `json:"source"`
+		IdempotencyKey   string               `json:"idempotency_key"`
+		AudioOutputs     []cqe.AudioOutputReq `json:"audio_outputs"`
+		AudioTags        map[string]string    `json:"audio_tags"`
+		FormatSet        string               `json:"format_set"`
+		MaxRenditions    int                  `json:"max_renditions"`
+		GroupUUID        string               `json:"group_uuid"`
 	}
 	if err := json.Unmarshal(msg.Value, &m); err != nil {
 		return nil, err
 	}
 	req := &cqe.CreateTranscodeTaskReq{
-		UserUUID:      m.UserUUID,
-		VideoUUID:     m.VideoUUID,
-		VideoPushUUID: m.VideoPushUUID,
-		OriginalPath:  m.InputPath,
-		Resolution:    m.TargetResolution,
-		Bitrate:       m.TargetBitrate,
+		UserUUID:         m.UserUUID,
+		VideoUUID:        m.VideoUUID,
+		VideoPushUUID:    m.VideoPushUUID,
+		OriginalPath:     m.InputPath,
+		Resolution:       m.TargetResolution,
+		Bitrate:          m.TargetBitrate,
+		Labels:           m.Labels,
+		SourceGeneration: m.SourceGeneration,
+		VideoStreamIndex: m.VideoStreamIndex,
+		AudioStreamIndex: m.AudioStreamIndex,
+		Profile:          m.Profile,
+		Source:           m.Source,
+		IdempotencyKey:   messageIdempotencyKey(msg, m.IdempotencyKey),
+		AudioOutputs:     m.AudioOutputs,
+		AudioTags:        m.AudioTags,
+		FormatSet:        m.FormatSet,
+		MaxRenditions:    m.MaxRenditions,
+		GroupUUID:        m.GroupUUID,
 	}
 	return req, nil
 }
 
+// messageIdempotencyKey 依次取消息体、idempotency-key 头，缺省为消息位置：
+// 同一条消息被再均衡后的新持有者重新消费时仍得到相同的键
+func messageIdempotencyKey(msg *kafka.Message, bodyKey string) string {
+	if bodyKey != "" {
+		return bodyKey
+	}
+	if key := headerValue(msg.Headers, "idempotency-key"); key != "" {
+		return key
+	}
+	return fmt.Sprintf("%s/%d/%d", msg.Topic, msg.Partition, msg.Offset)
+}
+
 func headerValue(headers []kafka.Header, key string) string {
 	for _, h := range headers {
 		if h.Key == key {
@@ -286,8 +423,18 @@ type backgroundTaskAdapter struct {
 	name      string
 	startFunc func(ctx context.Context) error
 	stopFunc  func() error
+	phase     manager.Phase
+	inFlight  func() []string
 }
 
 func (b *backgroundTaskAdapter) Name() string                    { return b.name }
 func (b *backgroundTaskAdapter) Start(ctx context.Context) error { return b.startFunc(ctx) }
 func (b *backgroundTaskAdapter) Stop() error                     { return b.stopFunc() }
+func (b *backgroundTaskAdapter) ShutdownPhase() manager.Phase    { return b.phase }
+
+func (b *backgroundTaskAdapter) InFlight() []string {
+	if b.inFlight == nil {
+		return nil
+	}
+	return b.inFlight()
+}
@@ -0,0 +1,18 @@
+package grpc
+
+import (
+	transcodepb "transcode-service/api/transcode"
+	"transcode-service/ddd/application/dto"
+)
+
+// toPBOutputs 把产物列表转换为 GetTranscodeTaskResponse.outputs
+func toPBOutputs(outputs []dto.TaskOutputDto) []*transcodepb.TranscodeOutput {
+	if len(outputs) == 0 {
+		return nil
+	}
+	out := make([]*transcodepb.TranscodeOutput, 0, len(outputs))
+	for _, o := range outputs {
+		out = append(out, &transcodepb.TranscodeOutput{Type: o.Type, Resolution: o.Resolution, Url: o.URL, Size: o.Size})
+	}
+	return out
+}
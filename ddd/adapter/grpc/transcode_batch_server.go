@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	transcodepb "transcode-service/api/transcode"
+	"transcode-service/ddd/application/app"
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+)
+
+// TranscodeBatchGrpcServer implements the gRPC TranscodeBatchService，
+// 字段与 HTTP POST /inner/v1/tasks/batch-status 一致
+type TranscodeBatchGrpcServer struct {
+	transcodepb.UnimplementedTranscodeBatchServiceServer
+	app app.TranscodeApp
+}
+
+// NewTranscodeBatchGrpcServer creates a new gRPC batch server implementation.
+func NewTranscodeBatchGrpcServer(transcodeApp app.TranscodeApp) *TranscodeBatchGrpcServer {
+	return &TranscodeBatchGrpcServer{
+		app: transcodeApp,
+	}
+}
+
+// GetTranscodeTasksByVideoUUIDs 批量返回每个视频最新任务的精简状态（单次最多 500 个）
+func (s *TranscodeBatchGrpcServer) GetTranscodeTasksByVideoUUIDs(ctx context.Context, req *transcodepb.GetTranscodeTasksByVideoUUIDsRequest) (*transcodepb.GetTranscodeTasksByVideoUUIDsResponse, error) {
+	if s.app == nil {
+		return nil, status.Error(codes.Unavailable, "service unavailable")
+	}
+	batch := &cqe.BatchTaskStatusReq{VideoUUIDs: req.GetVideoUuids()}
+	records, err := s.app.GetTaskStatusesByVideoUUIDs(ctx, batch)
+	if err != nil {
+		if errors.Is(err, errno.ErrVideoUUIDRequired) || errors.Is(err, errno.ErrBatchTooLarge) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		logger.WithContext(ctx).Errorf("GetTranscodeTasksByVideoUUIDs failed count=%d error=%v", len(batch.VideoUUIDs), err)
+		return nil, status.Error(codes.Internal, "query task status failed")
+	}
+	resp := &transcodepb.GetTranscodeTasksByVideoUUIDsResponse{Records: make([]*transcodepb.TranscodeTaskStatus, 0, len(records))}
+	for _, r := range records {
+		resp.Records = append(resp.Records, toPBTaskStatus(r))
+	}
+	logger.WithContext(ctx).Infof("GetTranscodeTasksByVideoUUIDs served count=%d", len(records))
+	return resp, nil
+}
+
+func toPBTaskStatus(r dto.TaskStatusRecord) *transcodepb.TranscodeTaskStatus {
+	out := &transcodepb.TranscodeTaskStatus{
+		VideoUuid:    r.VideoUUID,
+		Found:        r.Found,
+		TaskUuid:     r.TaskUUID,
+		Status:       r.Status,
+		Progress:     int32(r.Progress),
+		OutputPath:   r.OutputPath,
+		ErrorMessage: r.ErrorMessage,
+	}
+	if r.UpdatedAt != nil && !r.UpdatedAt.IsZero() {
+		out.UpdatedAt = r.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return out
+}
@@ -4,15 +4,30 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
-	transcodepb "github.com/jiangqiao2/go-video-proto/proto/transcode/transcode"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
+	transcodepb "transcode-service/api/transcode"
 	"transcode-service/ddd/application/app"
 	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/domain/vo"
 	"transcode-service/pkg/errno"
 	"transcode-service/pkg/logger"
 )
 
+// 排队信息与视频整体进度通过响应 header 返回（proto 暂无对应字段）
+const (
+	headerQueuePosition    = "x-queue-position"
+	headerEstimatedStartAt = "x-estimated-start-at"
+	headerVideoProgress    = "x-video-progress"
+)
+
+// headerTaskLabels 创建任务时通过请求 metadata 传入标签（proto 暂无对应字段），格式 "k1=v1,k2=v2"
+const headerTaskLabels = "x-task-labels"
+
 // TranscodeGrpcServer implements the gRPC TranscodeService.
 type TranscodeGrpcServer struct {
 	transcodepb.UnimplementedTranscodeServiceServer
@@ -67,6 +82,15 @@ func (s *TranscodeGrpcServer) CreateTranscodeTask(ctx context.Context, req *tran
 
 	logger.WithContext(ctx).Infof("CreateTranscodeTask called user_uuid=%s video_uuid=%s input_path=%s target_resolution=%s target_bitrate=%s", userUUID, videoUUID, inputPath, req.GetTargetResolution(), req.GetTargetBitrate())
 
+	labels, err := labelsFromMetadata(ctx)
+	if err != nil {
+		logger.WithContext(ctx).Warnf("CreateTranscodeTask called with invalid labels user_uuid=%s video_uuid=%s error=%v", userUUID, videoUUID, err)
+		return &transcodepb.CreateTranscodeTaskResponse{
+			Success: false,
+			Message: "invalid " + headerTaskLabels + ": " + err.Error(),
+		}, nil
+	}
+
 	// 构建应用层请求
 	createReq := &cqe.CreateTranscodeTaskReq{
 		UserUUID:     userUUID,
@@ -74,6 +98,7 @@ func (s *TranscodeGrpcServer) CreateTranscodeTask(ctx context.Context, req *tran
 		OriginalPath: inputPath,
 		Resolution:   req.GetTargetResolution(),
 		Bitrate:      req.GetTargetBitrate(),
+		Labels:       labels,
 	}
 
 	// 调用应用层服务
@@ -167,9 +192,20 @@ func (s *TranscodeGrpcServer) GetTranscodeTask(ctx context.Context, req *transco
 		errorMessage = "transcode task failed"
 	}
 
-	logger.WithContext(ctx).Infof("transcode task retrieved successfully task_uuid=%s video_uuid=%s status=%s progress=%d", taskDto.TaskUUID, taskDto.VideoUUID, taskDto.Status, progress)
+	md := metadata.Pairs(headerVideoProgress, strconv.Itoa(int(taskDto.VideoProgress)))
+	if taskDto.QueuePosition > 0 {
+		md.Set(headerQueuePosition, strconv.Itoa(taskDto.QueuePosition))
+		if taskDto.EstimatedStartAt != nil {
+			md.Set(headerEstimatedStartAt, taskDto.EstimatedStartAt.UTC().Format(time.RFC3339))
+		}
+	}
+	if err := grpc.SetHeader(ctx, md); err != nil {
+		logger.WithContext(ctx).Warnf("set task header failed task_uuid=%s error=%v", taskDto.TaskUUID, err)
+	}
 
-	return &transcodepb.GetTranscodeTaskResponse{
+	logger.WithContext(ctx).Infof("transcode task retrieved successfully task_uuid=%s video_uuid=%s status=%s progress=%d queue_position=%d", taskDto.TaskUUID, taskDto.VideoUUID, taskDto.Status, progress, taskDto.QueuePosition)
+
+	resp := &transcodepb.GetTranscodeTaskResponse{
 		Success:      true,
 		TaskUuid:     taskDto.TaskUUID,
 		VideoUuid:    taskDto.VideoUUID,
@@ -177,5 +213,36 @@ func (s *TranscodeGrpcServer) GetTranscodeTask(ctx context.Context, req *transco
 		Progress:     progress,
 		OutputPath:   outputPath,
 		ErrorMessage: errorMessage,
-	}, nil
+	}
+	// 多码流/HLS 产物随响应返回，省去额外的地址查询；查询失败不影响任务信息
+	if taskDto.Status == "completed" {
+		outputs, err := s.app.GetTaskOutputs(ctx, taskUUID)
+		if err != nil {
+			logger.WithContext(ctx).Warnf("get task outputs failed task_uuid=%s error=%v", taskUUID, err)
+		}
+		resp.Outputs = toPBOutputs(outputs)
+	}
+	return resp, nil
+}
+
+// labelsFromMetadata 读取 x-task-labels，多个值合并
+func labelsFromMetadata(ctx context.Context) (map[string]string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	var labels map[string]string
+	for _, v := range md.Get(headerTaskLabels) {
+		parsed, err := vo.ParseLabelSelector(v)
+		if err != nil {
+			return nil, err
+		}
+		for k, val := range parsed {
+			if labels == nil {
+				labels = make(map[string]string)
+			}
+			labels[k] = val
+		}
+	}
+	return labels, nil
 }
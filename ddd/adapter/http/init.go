@@ -4,4 +4,7 @@ import "transcode-service/pkg/manager"
 
 func init() {
 	manager.RegisterControllerPlugin(&TranscodeControllerPlugin{})
+	manager.RegisterControllerPlugin(&OpsControllerPlugin{})
+	manager.RegisterControllerPlugin(&UserPreferenceControllerPlugin{})
+	manager.RegisterControllerPlugin(&StatisticsControllerPlugin{})
 }
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/benchmark"
+	"transcode-service/ddd/infrastructure/budget"
+	"transcode-service/ddd/infrastructure/database/persistence"
+	"transcode-service/ddd/infrastructure/diagnostics"
+	"transcode-service/ddd/infrastructure/hlsinspect"
+	"transcode-service/ddd/infrastructure/ladder"
+	"transcode-service/ddd/infrastructure/notify"
+	"transcode-service/ddd/infrastructure/selftest"
+	"transcode-service/ddd/infrastructure/storage"
+	pkgkafka "transcode-service/pkg/kafka"
+	"transcode-service/pkg/openapi"
+)
+
+// includeQuery 任务详情的 include 参数，commands 附带 ffmpeg 命令
+type includeQuery struct {
+	Include string `form:"include"`
+}
+
+// hlsInspectQuery 抽查的切片数
+type hlsInspectQuery struct {
+	Samples int `form:"samples"`
+}
+
+// sourceCacheQuery 要删除缓存的对象键
+type sourceCacheQuery struct {
+	ObjectKey string `form:"object_key" binding:"required"`
+}
+
+// 各 handler 的 OpenAPI 注解，新增路由时在此补充；未注解的路由仍会出现在 /api/openapi.json 中
+func init() {
+	// 任务 v1
+	openapi.Annotate((*transcodeControllerImpl).CreateTranscodeTask, openapi.Operation{
+		Summary: "创建转码任务", Tags: []string{"tasks-v1"}, Request: cqe.CreateTranscodeTaskReq{}, Response: dto.TranscodeTaskDto{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).ValidateTranscodeTask, openapi.Operation{
+		Summary: "校验任务参数并返回解析后的阶梯（不创建任务）", Tags: []string{"tasks-v1"}, Request: cqe.ValidateTranscodeTaskReq{}, Response: dto.TranscodeDryRunDto{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).GetTranscodeTask, openapi.Operation{
+		Summary: "查询任务", Tags: []string{"tasks-v1"}, Query: includeQuery{}, Response: dto.TranscodeTaskDto{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).GetTaskStatusesByVideoUUIDs, openapi.Operation{
+		Summary: "按视频批量查询任务状态", Tags: []string{"tasks-v1"}, Request: cqe.BatchTaskStatusReq{}, Response: []dto.TaskStatusRecord{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).BoostTaskPriority, openapi.Operation{
+		Summary: "提升排队任务优先级", Tags: []string{"tasks-v1"}, Request: cqe.BoostTaskPriorityReq{}, Response: dto.TranscodeTaskDto{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).ReplayTask, openapi.Operation{
+		Summary: "按覆盖参数重放已结束任务", Tags: []string{"tasks-v1"}, Request: cqe.ReplayTaskReq{}, Response: dto.TranscodeTaskDto{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).GetVideoProcessing, openapi.Operation{
+		Summary: "视频的转码与 HLS 处理进度", Tags: []string{"videos"}, Response: dto.VideoProcessingDto{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).CreateThumbnail, openapi.Operation{
+		Summary: "按时间戳截取封面", Tags: []string{"videos"}, Request: cqe.CreateThumbnailReq{}, Response: dto.ThumbnailDto{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).SubmitJob, openapi.Operation{
+		Summary: "提交通用作业，负载格式由作业类型决定", Tags: []string{"jobs"}, Request: map[string]interface{}{}, Response: dto.JobSubmissionDto{},
+	})
+
+	// 任务 v2
+	openapi.Annotate((*transcodeControllerImpl).CreateTaskV2, openapi.Operation{
+		Summary: "创建转码任务", Tags: []string{"tasks"}, Request: cqe.CreateTranscodeTaskReq{}, Response: dto.TaskResource{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).ListTasksV2, openapi.Operation{
+		Summary: "分页查询任务，可按标签筛选", Tags: []string{"tasks"}, Query: ListTasksQuery{}, Response: dto.TaskResource{}, Paged: true,
+	})
+	openapi.Annotate((*transcodeControllerImpl).GetTaskV2, openapi.Operation{
+		Summary: "查询任务", Tags: []string{"tasks"}, Query: includeQuery{}, Response: dto.TaskResource{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).CancelTaskV2, openapi.Operation{
+		Summary: "取消任务", Tags: []string{"tasks"}, Response: dto.TaskResource{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).BoostTaskPriorityV2, openapi.Operation{
+		Summary: "提升排队任务优先级", Tags: []string{"tasks"}, Request: cqe.BoostTaskPriorityReq{}, Response: dto.TaskResource{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).ReplayTaskV2, openapi.Operation{
+		Summary: "按覆盖参数重放已结束任务", Tags: []string{"tasks"}, Request: cqe.ReplayTaskReq{}, Response: dto.TaskResource{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).RestoreTaskOutputV2, openapi.Operation{
+		Summary: "恢复已归档的产物", Tags: []string{"tasks"}, Response: dto.TaskResource{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).GetTaskSamplesV2, openapi.Operation{
+		Summary: "编码期间 ffmpeg 进程生命周期与资源采样时间序列", Tags: []string{"tasks"}, Response: vo.ResourceTrace{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).GetTaskOutputURLsV2, openapi.Operation{
+		Summary: "产物访问地址（私有产物为签名 URL）", Tags: []string{"tasks"}, Response: dto.OutputURLsDto{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).ListTaskNotesV2, openapi.Operation{
+		Summary: "任务备注列表", Tags: []string{"tasks"}, Response: []dto.TaskNoteDto{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).AddTaskNoteV2, openapi.Operation{
+		Summary: "添加任务备注", Tags: []string{"tasks"}, Request: cqe.AddTaskNoteReq{}, Response: dto.TaskNoteDto{},
+	})
+
+	// 任务组
+	openapi.Annotate((*transcodeControllerImpl).CreateTaskGroupV2, openapi.Operation{
+		Summary: "创建任务组", Tags: []string{"task-groups"}, Request: cqe.CreateTaskGroupReq{}, Response: dto.TaskGroupDto{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).GetTaskGroupV2, openapi.Operation{
+		Summary: "任务组聚合进度与组内任务", Tags: []string{"task-groups"}, Response: dto.TaskGroupDto{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).AttachTaskGroupTasksV2, openapi.Operation{
+		Summary: "把已有任务加入任务组", Tags: []string{"task-groups"}, Request: cqe.AttachTaskGroupTasksReq{}, Response: dto.TaskGroupDto{},
+	})
+	openapi.Annotate((*transcodeControllerImpl).CreateTaskGroupTasksV2, openapi.Operation{
+		Summary: "在任务组下批量创建任务", Tags: []string{"task-groups"}, Request: cqe.CreateTaskGroupTasksReq{}, Response: dto.TaskGroupTasksDto{},
+	})
+
+	// 用户偏好
+	openapi.Annotate((*userPreferenceControllerImpl).GetUserPreference, openapi.Operation{
+		Summary: "查询用户转码偏好", Tags: []string{"preferences"}, Response: dto.UserPreferenceDto{},
+	})
+	openapi.Annotate((*userPreferenceControllerImpl).SaveUserPreference, openapi.Operation{
+		Summary: "保存用户转码偏好", Tags: []string{"preferences"}, Request: cqe.SaveUserPreferenceReq{}, Response: dto.UserPreferenceDto{},
+	})
+	openapi.Annotate((*userPreferenceControllerImpl).DeleteUserPreference, openapi.Operation{
+		Summary: "删除用户转码偏好", Tags: []string{"preferences"},
+	})
+
+	// 统计
+	openapi.Annotate((*statisticsControllerImpl).GetThroughput, openapi.Operation{
+		Summary: "每日处理量：下载/上传字节与编码耗时", Tags: []string{"statistics"}, Query: cqe.ThroughputQuery{}, Response: dto.ThroughputReportDto{},
+	})
+
+	// HLS 作业与 worker
+	openapi.Annotate((*opsControllerImpl).GetHLSJob, openapi.Operation{
+		Summary: "HLS 作业详情与各路码流状态", Tags: []string{"hls-jobs"}, Response: dto.HLSJobDto{},
+	})
+	openapi.Annotate((*opsControllerImpl).RetryHLSJob, openapi.Operation{
+		Summary: "只重试失败的码流", Tags: []string{"hls-jobs"}, Response: dto.HLSJobDto{},
+	})
+	openapi.Annotate((*opsControllerImpl).RegenerateHLSMaster, openapi.Operation{
+		Summary: "按已完成码流重新生成 master playlist", Tags: []string{"hls-jobs"}, Response: dto.HLSMasterDto{},
+	})
+	openapi.Annotate((*opsControllerImpl).RegenerateVideoHLSMaster, openapi.Operation{
+		Summary: "重新生成视频最近完成作业的 master playlist", Tags: []string{"hls-jobs"}, Response: dto.HLSMasterDto{},
+	})
+	openapi.Annotate((*opsControllerImpl).InspectHLSJob, openapi.Operation{
+		Summary: "抽查切片时长与关键帧对齐", Tags: []string{"hls-jobs"}, Query: hlsInspectQuery{}, Response: hlsinspect.Report{},
+	})
+	openapi.Annotate((*opsControllerImpl).Workers, openapi.Operation{
+		Summary: "worker 列表：Redis 存活状态合并 MySQL 执行记录", Tags: []string{"workers"}, Response: dto.WorkerListDto{},
+	})
+	openapi.Annotate((*opsControllerImpl).WorkerUtilization, openapi.Operation{
+		Summary: "worker 编码槽位利用率", Tags: []string{"workers"}, Query: cqe.WorkerUtilizationQuery{}, Response: dto.WorkerUtilizationReportDto{},
+	})
+
+	// 运维
+	openapi.Annotate((*opsControllerImpl).EffectiveConfig, openapi.Operation{
+		Summary: "生效配置（敏感字段脱敏）", Tags: []string{"admin"}, Response: map[string]interface{}{}, Auth: true,
+	})
+	openapi.Annotate((*opsControllerImpl).SelfTest, openapi.Operation{
+		Summary: "端到端自检", Tags: []string{"admin"}, Response: selftest.Report{},
+	})
+	openapi.Annotate((*opsControllerImpl).KafkaLag, openapi.Operation{
+		Summary: "Kafka 消费组积压", Tags: []string{"admin"}, Response: pkgkafka.GroupLag{},
+	})
+	openapi.Annotate((*opsControllerImpl).EncodeBudget, openapi.Operation{
+		Summary: "共享编码槽位占用", Tags: []string{"admin"}, Response: budget.Stats{},
+	})
+	openapi.Annotate((*opsControllerImpl).UpdateEncodeBudget, openapi.Operation{
+		Summary: "调整共享编码槽位容量与权重", Tags: []string{"admin"}, Request: cqe.UpdateEncodeBudgetReq{}, Response: budget.Stats{},
+	})
+	openapi.Annotate((*opsControllerImpl).SourceCache, openapi.Operation{
+		Summary: "源文件缓存统计", Tags: []string{"admin"}, Response: storage.SourceCacheStats{},
+	})
+	openapi.Annotate((*opsControllerImpl).InvalidateSourceCache, openapi.Operation{
+		Summary: "删除对象的源文件缓存", Tags: []string{"admin"}, Query: sourceCacheQuery{}, Response: map[string]int{},
+	})
+	openapi.Annotate((*opsControllerImpl).Notifications, openapi.Operation{
+		Summary: "告警通道与事件阈值", Tags: []string{"admin"}, Response: notify.Status{},
+	})
+	openapi.Annotate((*opsControllerImpl).TestNotification, openapi.Operation{
+		Summary: "发送测试告警", Tags: []string{"admin"}, Request: cqe.TestNotificationReq{}, Response: []notify.SendResult{},
+	})
+	openapi.Annotate((*opsControllerImpl).RotateEncryption, openapi.Operation{
+		Summary: "密钥轮换后重加密历史数据", Tags: []string{"admin"}, Response: persistence.RotationReport{},
+	})
+	openapi.Annotate((*opsControllerImpl).LadderCache, openapi.Operation{
+		Summary: "档位缓存状态", Tags: []string{"admin"}, Response: ladder.Stats{},
+	})
+	openapi.Annotate((*opsControllerImpl).InvalidateLadderCache, openapi.Operation{
+		Summary: "失效档位缓存并通知其他实例", Tags: []string{"admin"}, Response: ladder.Invalidation{},
+	})
+	openapi.Annotate((*opsControllerImpl).StartBenchmark, openapi.Operation{
+		Summary: "开始压测（合成源文件按速率经完整流水线转码）", Tags: []string{"admin"}, Request: cqe.StartBenchmarkReq{}, Response: benchmark.Report{},
+	})
+	openapi.Annotate((*opsControllerImpl).Benchmark, openapi.Operation{
+		Summary: "当前或最近一次压测的吞吐与耗时报告", Tags: []string{"admin"}, Response: benchmark.Report{},
+	})
+	openapi.Annotate((*opsControllerImpl).StopBenchmark, openapi.Operation{
+		Summary: "停止压测", Tags: []string{"admin"}, Response: benchmark.Report{},
+	})
+	openapi.Annotate((*opsControllerImpl).GetWorker, openapi.Operation{
+		Summary: "worker 详情（存活状态、能力标签、正在执行的任务、最近一次执行与健康状态）", Tags: []string{"admin"}, Response: dto.WorkerDetailDto{},
+	})
+	openapi.Annotate((*opsControllerImpl).CheckWorkerHealth, openapi.Operation{
+		Summary: "worker 健康检查（心跳间隔与近期执行结果）", Tags: []string{"admin"}, Response: dto.WorkerHealthDto{},
+	})
+	openapi.Annotate((*opsControllerImpl).DeleteWorker, openapi.Operation{
+		Summary: "删除 worker 并重新派发其被打断的任务（存活实例须 force，任务被取消）", Tags: []string{"admin"}, Query: cqe.DeleteWorkerReq{}, Response: dto.WorkerDeletionDto{},
+	})
+	openapi.Annotate((*opsControllerImpl).StartProfileCapture, openapi.Operation{
+		Summary: "采集本实例的 trace/CPU/heap 剖析文件并上传到对象存储", Tags: []string{"admin"}, Request: cqe.StartProfileCaptureReq{}, Response: diagnostics.Capture{},
+	})
+	openapi.Annotate((*opsControllerImpl).ProfileCaptures, openapi.Operation{
+		Summary: "本实例最近的剖析采集记录", Tags: []string{"admin"}, Response: []diagnostics.Capture{},
+	})
+	openapi.Annotate((*opsControllerImpl).StaleOutputs, openapi.Operation{
+		Summary: "按编码设置版本或指纹选出待回填的产物", Tags: []string{"admin"}, Query: cqe.StaleOutputQuery{}, Response: dto.StaleOutputListDto{},
+	})
+	openapi.Annotate((*opsControllerImpl).RecordOutputAccess, openapi.Operation{
+		Summary: "回写 CDN 访问日志中的产物最近访问时间", Tags: []string{"admin"}, Request: cqe.RecordOutputAccessReq{}, Response: dto.OutputAccessResultDto{},
+	})
+}
@@ -0,0 +1,439 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http/pprof"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"transcode-service/ddd/application/app"
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/pkg/assert"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/manager"
+	"transcode-service/pkg/middleware"
+	"transcode-service/pkg/restapi"
+)
+
+var (
+	opsControllerOnce      sync.Once
+	singletonOpsController OpsController
+)
+
+type OpsControllerPlugin struct {
+}
+
+func (p *OpsControllerPlugin) Name() string {
+	return "opsControllerPlugin"
+}
+
+func (p *OpsControllerPlugin) MustCreateController() manager.Controller {
+	assert.NotCircular()
+	opsControllerOnce.Do(func() {
+		singletonOpsController = &opsControllerImpl{
+			opsApp: app.DefaultOpsApp(),
+		}
+	})
+	assert.NotNil(singletonOpsController)
+	return singletonOpsController
+}
+
+type OpsController interface {
+	manager.Controller
+}
+
+type opsControllerImpl struct {
+	manager.Controller
+	opsApp app.OpsApp
+}
+
+// RegisterOpenApi 注册开放API
+func (o *opsControllerImpl) RegisterOpenApi(router *gin.RouterGroup) {
+	admin := router.Group("v1/admin", middleware.DefaultAuthComponent().Required())
+	{
+		admin.GET("/config", o.EffectiveConfig)
+	}
+}
+
+// RegisterInnerApi 注册内部API
+func (o *opsControllerImpl) RegisterInnerApi(router *gin.RouterGroup) {
+}
+
+// RegisterDebugApi 注册调试API：/debug/pprof 按需剖析，受 diagnostics.pprof_enabled 与访问令牌保护
+func (o *opsControllerImpl) RegisterDebugApi(router *gin.RouterGroup) {
+	enabled, token := false, ""
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		enabled, token = cfg.Diagnostics.PprofEnabled, cfg.Diagnostics.ResolvedToken()
+	}
+	pp := router.Group("/pprof", middleware.DebugGuard(enabled, token))
+	{
+		pp.GET("/", gin.WrapF(pprof.Index))
+		pp.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+		pp.GET("/profile", gin.WrapF(pprof.Profile))
+		pp.GET("/symbol", gin.WrapF(pprof.Symbol))
+		pp.POST("/symbol", gin.WrapF(pprof.Symbol))
+		pp.GET("/trace", gin.WrapF(pprof.Trace))
+		// heap、goroutine、allocs、block、mutex、threadcreate 由 Index 按路径分发
+		pp.GET("/:profile", gin.WrapF(pprof.Index))
+	}
+}
+
+// RegisterOpsApi 注册运维API
+func (o *opsControllerImpl) RegisterOpsApi(router *gin.RouterGroup) {
+	admin := router.Group("v1/admin")
+	{
+		admin.POST("/selftest", o.SelfTest)
+		admin.GET("/kafka/lag", o.KafkaLag)
+		admin.GET("/encode-budget", o.EncodeBudget)
+		admin.PUT("/encode-budget", o.UpdateEncodeBudget)
+		admin.GET("/source-cache", o.SourceCache)
+		admin.DELETE("/source-cache", o.InvalidateSourceCache)
+		admin.GET("/hls-jobs/:job_uuid", o.GetHLSJob)
+		admin.POST("/hls-jobs/:job_uuid/retry", o.RetryHLSJob)
+		admin.GET("/hls-jobs/:job_uuid/inspect", o.InspectHLSJob)
+		admin.POST("/hls-jobs/:job_uuid/master", o.RegenerateHLSMaster)
+		admin.POST("/videos/:video_uuid/hls-master", o.RegenerateVideoHLSMaster)
+		admin.GET("/workers", o.Workers)
+		admin.GET("/workers/utilization", o.WorkerUtilization)
+		admin.GET("/workers/:worker_id", o.GetWorker)
+		admin.GET("/workers/:worker_id/health", o.CheckWorkerHealth)
+		admin.DELETE("/workers/:worker_id", o.DeleteWorker)
+		admin.GET("/notifications", o.Notifications)
+		admin.POST("/notifications/test", o.TestNotification)
+		admin.POST("/encryption/rotate", o.RotateEncryption)
+		admin.GET("/ladders", o.LadderCache)
+		admin.POST("/ladders/invalidate", o.InvalidateLadderCache)
+		admin.POST("/benchmark", o.StartBenchmark)
+		admin.GET("/benchmark", o.Benchmark)
+		admin.POST("/benchmark/stop", o.StopBenchmark)
+		admin.POST("/diagnostics/captures", o.StartProfileCapture)
+		admin.GET("/diagnostics/captures", o.ProfileCaptures)
+		admin.GET("/outputs/stale", o.StaleOutputs)
+		admin.POST("/outputs/access", o.RecordOutputAccess)
+	}
+}
+
+// SelfTest 运行端到端自检并返回逐组件结果
+func (o *opsControllerImpl) SelfTest(c *gin.Context) {
+	restapi.Success(c, o.opsApp.SelfTest(c.Request.Context()))
+}
+
+// EffectiveConfig 返回当前实例生效的配置（密钥已脱敏）
+func (o *opsControllerImpl) EffectiveConfig(c *gin.Context) {
+	res, err := o.opsApp.EffectiveConfig(c.Request.Context())
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// KafkaLag 返回消费组各分区积压
+func (o *opsControllerImpl) KafkaLag(c *gin.Context) {
+	res, err := o.opsApp.KafkaLag(c.Request.Context())
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// EncodeBudget 返回共享编码槽位的容量、占用与等待数
+func (o *opsControllerImpl) EncodeBudget(c *gin.Context) {
+	restapi.Success(c, o.opsApp.EncodeBudget(c.Request.Context()))
+}
+
+// UpdateEncodeBudget 运行时调整共享编码槽位，无需重启
+func (o *opsControllerImpl) UpdateEncodeBudget(c *gin.Context) {
+	var req cqe.UpdateEncodeBudgetReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	res, err := o.opsApp.UpdateEncodeBudget(c.Request.Context(), &req)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// SourceCache 返回源文件缓存占用与命中统计
+func (o *opsControllerImpl) SourceCache(c *gin.Context) {
+	res, err := o.opsApp.SourceCache(c.Request.Context())
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// InvalidateSourceCache 源文件被替换时删除缓存，?object_key=
+func (o *opsControllerImpl) InvalidateSourceCache(c *gin.Context) {
+	removed, err := o.opsApp.InvalidateSourceCache(c.Request.Context(), c.Query("object_key"))
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, map[string]int{"removed": removed})
+}
+
+// RotateEncryption 密钥轮换后重加密历史数据，同步执行并返回逐列统计
+func (o *opsControllerImpl) RotateEncryption(c *gin.Context) {
+	res, err := o.opsApp.RotateEncryption(c.Request.Context())
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// LadderCache 返回档位缓存的版本、加载时间与失效统计
+func (o *opsControllerImpl) LadderCache(c *gin.Context) {
+	res, err := o.opsApp.LadderCache(c.Request.Context())
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// InvalidateLadderCache 档位或自动档位规则变更后失效缓存，并经 Redis 通知其他实例
+func (o *opsControllerImpl) InvalidateLadderCache(c *gin.Context) {
+	res, err := o.opsApp.InvalidateLadderCache(c.Request.Context())
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// StartBenchmark 开始压测，立即返回初始报告，进度通过 GET /benchmark 查询
+func (o *opsControllerImpl) StartBenchmark(c *gin.Context) {
+	var req cqe.StartBenchmarkReq
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		restapi.Failed(c, err)
+		return
+	}
+	res, err := o.opsApp.StartBenchmark(c.Request.Context(), &req)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// Benchmark 返回当前或最近一次压测的报告
+func (o *opsControllerImpl) Benchmark(c *gin.Context) {
+	res, err := o.opsApp.Benchmark(c.Request.Context())
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// StopBenchmark 停止提交，已提交的任务照常执行
+func (o *opsControllerImpl) StopBenchmark(c *gin.Context) {
+	res, err := o.opsApp.StopBenchmark(c.Request.Context())
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// StartProfileCapture 开始采集剖析文件，立即返回，完成后的对象 key 通过 GET /diagnostics/captures 查询
+func (o *opsControllerImpl) StartProfileCapture(c *gin.Context) {
+	var req cqe.StartProfileCaptureReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	res, err := o.opsApp.StartProfileCapture(c.Request.Context(), &req)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// ProfileCaptures 返回本实例最近的剖析采集记录
+func (o *opsControllerImpl) ProfileCaptures(c *gin.Context) {
+	res, err := o.opsApp.ProfileCaptures(c.Request.Context())
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// GetHLSJob 返回 HLS 作业详情与各路码流状态
+func (o *opsControllerImpl) GetHLSJob(c *gin.Context) {
+	res, err := o.opsApp.GetHLSJob(c.Request.Context(), c.Param("job_uuid"))
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// RetryHLSJob 只重试失败 HLS 作业中失败的码流
+func (o *opsControllerImpl) RetryHLSJob(c *gin.Context) {
+	res, err := o.opsApp.RetryHLSJob(c.Request.Context(), c.Param("job_uuid"))
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// RegenerateHLSMaster 按已完成的码流重新生成并上传 master playlist
+func (o *opsControllerImpl) RegenerateHLSMaster(c *gin.Context) {
+	res, err := o.opsApp.RegenerateHLSMaster(c.Request.Context(), c.Param("job_uuid"))
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// RegenerateVideoHLSMaster 对视频最近一次完成的 HLS 作业重新生成 master playlist
+func (o *opsControllerImpl) RegenerateVideoHLSMaster(c *gin.Context) {
+	res, err := o.opsApp.RegenerateVideoHLSMaster(c.Request.Context(), c.Param("video_uuid"))
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// InspectHLSJob 下载 HLS 作业的播放列表并抽查切片，?samples= 每路码流抽查的切片数
+func (o *opsControllerImpl) InspectHLSJob(c *gin.Context) {
+	samples, _ := strconv.Atoi(c.Query("samples"))
+	res, err := o.opsApp.InspectHLSJob(c.Request.Context(), c.Param("job_uuid"), samples)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// StaleOutputs 选出旧编码设置的已完成产物，?kind=&before_version=&exclude_hash=&after_id=&limit=
+func (o *opsControllerImpl) StaleOutputs(c *gin.Context) {
+	var q cqe.StaleOutputQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	res, err := o.opsApp.StaleOutputs(c.Request.Context(), &q)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// RecordOutputAccess 回写 CDN 访问日志中的产物最近访问时间
+func (o *opsControllerImpl) RecordOutputAccess(c *gin.Context) {
+	var req cqe.RecordOutputAccessReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	res, err := o.opsApp.RecordOutputAccess(c.Request.Context(), &req)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// Workers 存活与近期有执行记录的 worker
+func (o *opsControllerImpl) Workers(c *gin.Context) {
+	res, err := o.opsApp.Workers(c.Request.Context())
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// GetWorker 返回单个 worker 详情，worker_id 为 worker_id@hostname；未知实例返回 404
+func (o *opsControllerImpl) GetWorker(c *gin.Context) {
+	res, err := o.opsApp.GetWorker(c.Request.Context(), c.Param("worker_id"))
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// CheckWorkerHealth 返回 worker 健康状态；未知实例返回 404
+func (o *opsControllerImpl) CheckWorkerHealth(c *gin.Context) {
+	res, err := o.opsApp.CheckWorkerHealth(c.Request.Context(), c.Param("worker_id"))
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// DeleteWorker 删除 worker，?force=true 时取消存活实例正在执行的任务，?operator= 写入任务备注
+func (o *opsControllerImpl) DeleteWorker(c *gin.Context) {
+	var req cqe.DeleteWorkerReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	res, err := o.opsApp.DeleteWorker(c.Request.Context(), c.Param("worker_id"), &req)
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// WorkerUtilization 按 worker 统计编码槽位利用率，?worker_id=&from=&to=&bucket=
+func (o *opsControllerImpl) WorkerUtilization(c *gin.Context) {
+	var q cqe.WorkerUtilizationQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	res, err := o.opsApp.WorkerUtilization(c.Request.Context(), &q)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// Notifications 返回告警通道路由与事件阈值
+func (o *opsControllerImpl) Notifications(c *gin.Context) {
+	res, err := o.opsApp.Notifications(c.Request.Context())
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// TestNotification 向指定通道（缺省全部）发送测试告警，忽略路由与冷却
+func (o *opsControllerImpl) TestNotification(c *gin.Context) {
+	var req cqe.TestNotificationReq
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			restapi.Failed(c, err)
+			return
+		}
+	}
+	res, err := o.opsApp.TestNotification(c.Request.Context(), &req)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
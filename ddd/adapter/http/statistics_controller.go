@@ -0,0 +1,82 @@
+package http
+
+import (
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"transcode-service/ddd/application/app"
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/pkg/assert"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/manager"
+	"transcode-service/pkg/restapi"
+)
+
+var (
+	statisticsControllerOnce      sync.Once
+	singletonStatisticsController StatisticsController
+)
+
+type StatisticsControllerPlugin struct {
+}
+
+func (p *StatisticsControllerPlugin) Name() string {
+	return "statisticsControllerPlugin"
+}
+
+func (p *StatisticsControllerPlugin) MustCreateController() manager.Controller {
+	assert.NotCircular()
+	statisticsControllerOnce.Do(func() {
+		singletonStatisticsController = &statisticsControllerImpl{
+			statsApp: app.DefaultStatisticsApp(),
+		}
+	})
+	assert.NotNil(singletonStatisticsController)
+	return singletonStatisticsController
+}
+
+type StatisticsController interface {
+	manager.Controller
+}
+
+type statisticsControllerImpl struct {
+	manager.Controller
+	statsApp app.StatisticsApp
+}
+
+// RegisterOpenApi 注册开放API
+func (s *statisticsControllerImpl) RegisterOpenApi(router *gin.RouterGroup) {
+	s.registerRoutes(router.Group("v1/statistics"))
+}
+
+// RegisterInnerApi 注册内部API，供计费与容量规划服务拉取
+func (s *statisticsControllerImpl) RegisterInnerApi(router *gin.RouterGroup) {
+	s.registerRoutes(router.Group("v1/statistics"))
+}
+
+// RegisterDebugApi 注册调试API
+func (s *statisticsControllerImpl) RegisterDebugApi(router *gin.RouterGroup) {
+}
+
+// RegisterOpsApi 注册运维API
+func (s *statisticsControllerImpl) RegisterOpsApi(router *gin.RouterGroup) {
+}
+
+func (s *statisticsControllerImpl) registerRoutes(group *gin.RouterGroup) {
+	group.GET("/throughput", s.GetThroughput)
+}
+
+// GetThroughput 每日处理量，from/to 为 UTC 日期（含两端），缺省最近 30 天
+func (s *statisticsControllerImpl) GetThroughput(c *gin.Context) {
+	var q cqe.ThroughputQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		restapi.Failed(c, errno.NewSimpleBizError(errno.ErrInvalidParam, err))
+		return
+	}
+	res, err := s.statsApp.GetThroughput(c.Request.Context(), &q)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
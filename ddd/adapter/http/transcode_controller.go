@@ -2,6 +2,7 @@ package http
 
 import (
 	"github.com/gin-gonic/gin"
+	"strings"
 	"sync"
 	"transcode-service/ddd/application/app"
 	"transcode-service/ddd/application/cqe"
@@ -47,12 +48,26 @@ func (t *transcodeControllerImpl) RegisterOpenApi(router *gin.RouterGroup) {
 	v1 := router.Group("v1/transcode")
 	{
 		v1.POST("/tasks", t.CreateTranscodeTask)
+		v1.GET("/videos/:video_uuid", t.GetVideoProcessing)
 	}
+	router.POST("v1/tasks/validate", t.ValidateTranscodeTask)
+	router.GET("v1/tasks/:task_uuid", t.GetTranscodeTask)
+	router.POST("v1/tasks/batch-status", t.GetTaskStatusesByVideoUUIDs)
+	router.POST("v1/tasks/:task_uuid/priority", t.BoostTaskPriority)
+	router.POST("v1/tasks/:task_uuid/replay", t.ReplayTask)
+	// 编辑器自定义封面：按时间戳同步截帧
+	router.POST("v1/videos/:video_uuid/thumbnail", t.CreateThumbnail)
+	t.registerOpenApiV2(router)
 }
 
 // RegisterInnerApi 注册内部API
 func (t *transcodeControllerImpl) RegisterInnerApi(router *gin.RouterGroup) {
-
+	// upload-service 对账使用
+	router.POST("v1/tasks/batch-status", t.GetTaskStatusesByVideoUUIDs)
+	// 上游在用户等待时提升优先级
+	router.POST("v1/tasks/:task_uuid/priority", t.BoostTaskPriority)
+	// 通用作业提交，负载格式由作业类型的 Decode 决定
+	router.POST("v1/jobs/:job_type", t.SubmitJob)
 }
 
 // RegisterDebugApi 注册调试API
@@ -62,7 +77,8 @@ func (t *transcodeControllerImpl) RegisterDebugApi(router *gin.RouterGroup) {
 
 // RegisterOpsApi 注册运维API
 func (t *transcodeControllerImpl) RegisterOpsApi(router *gin.RouterGroup) {
-	// 运维API实现
+	router.POST("v1/tasks/:task_uuid/priority", t.BoostTaskPriority)
+	router.POST("v1/tasks/:task_uuid/replay", t.ReplayTask)
 }
 
 func (t *transcodeControllerImpl) CreateTranscodeTask(c *gin.Context) {
@@ -79,3 +95,133 @@ func (t *transcodeControllerImpl) CreateTranscodeTask(c *gin.Context) {
 	}
 	restapi.Success(c, res)
 }
+
+// GetTranscodeTask 获取任务详情，include=commands 时附带实际执行的 ffmpeg 命令
+func (t *transcodeControllerImpl) GetTranscodeTask(c *gin.Context) {
+	ctx := c.Request.Context()
+	taskUUID := c.Param("task_uuid")
+	res, err := t.transcodeApp.GetTranscodeTask(ctx, taskUUID)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	if includes(c.Query("include"), "commands") {
+		cmds, err := t.transcodeApp.GetTranscodeTaskCommands(ctx, taskUUID)
+		if err != nil {
+			restapi.Failed(c, err)
+			return
+		}
+		res.Commands = cmds
+	}
+	restapi.SuccessWithETag(c, res)
+}
+
+// includes 解析逗号分隔的 include 参数
+func includes(raw, name string) bool {
+	for _, part := range strings.Split(raw, ",") {
+		if strings.EqualFold(strings.TrimSpace(part), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTranscodeTask 预演建任务，返回将要使用的产物与 ffmpeg 参数，不创建任何数据
+func (t *transcodeControllerImpl) ValidateTranscodeTask(c *gin.Context) {
+	var req cqe.ValidateTranscodeTaskReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	res, err := t.transcodeApp.ValidateTranscodeTask(c.Request.Context(), &req)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// GetTaskStatusesByVideoUUIDs 按视频批量查询最新任务状态（单次最多 500 个）
+func (t *transcodeControllerImpl) GetTaskStatusesByVideoUUIDs(c *gin.Context) {
+	var req cqe.BatchTaskStatusReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	res, err := t.transcodeApp.GetTaskStatusesByVideoUUIDs(c.Request.Context(), &req)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// BoostTaskPriority 调整排队任务优先级，body: {"priority": 9, "reason": "user_waiting"}
+func (t *transcodeControllerImpl) BoostTaskPriority(c *gin.Context) {
+	var req cqe.BoostTaskPriorityReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	req.TaskUUID = c.Param("task_uuid")
+	res, err := t.transcodeApp.BoostTaskPriority(c.Request.Context(), &req)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res.ToV1())
+}
+
+// ReplayTask 以覆盖参数重放已结束任务，body: {"codec": "libx264", "preset": "slow", "resolution": "720p", "reason": "quality_complaint"}
+func (t *transcodeControllerImpl) ReplayTask(c *gin.Context) {
+	var req cqe.ReplayTaskReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	req.TaskUUID = c.Param("task_uuid")
+	res, err := t.transcodeApp.ReplayTask(c.Request.Context(), &req)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res.ToV1())
+}
+
+func (t *transcodeControllerImpl) SubmitJob(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	res, err := t.transcodeApp.SubmitJob(c.Request.Context(), c.Param("job_type"), payload)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+func (t *transcodeControllerImpl) CreateThumbnail(c *gin.Context) {
+	var req cqe.CreateThumbnailReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	req.VideoUUID = c.Param("video_uuid")
+	res, err := t.transcodeApp.CreateThumbnail(c.Request.Context(), &req)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+func (t *transcodeControllerImpl) GetVideoProcessing(c *gin.Context) {
+	res, err := t.transcodeApp.GetVideoProcessing(c.Request.Context(), c.Param("video_uuid"))
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
@@ -0,0 +1,284 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/restapi"
+)
+
+// registerOpenApiV2 /api/v2 任务资源：稳定字段名、整数进度、错误码与 HTTP 状态一致、分页信封
+func (t *transcodeControllerImpl) registerOpenApiV2(router *gin.RouterGroup) {
+	v2 := router.Group("v2/tasks")
+	{
+		v2.POST("", t.CreateTaskV2)
+		v2.GET("", t.ListTasksV2)
+		v2.GET("/:task_uuid", t.GetTaskV2)
+		v2.POST("/:task_uuid/cancel", t.CancelTaskV2)
+		v2.POST("/:task_uuid/priority", t.BoostTaskPriorityV2)
+		v2.POST("/:task_uuid/replay", t.ReplayTaskV2)
+		v2.POST("/:task_uuid/restore", t.RestoreTaskOutputV2)
+		v2.GET("/:task_uuid/samples", t.GetTaskSamplesV2)
+		v2.GET("/:task_uuid/urls", t.GetTaskOutputURLsV2)
+		v2.GET("/:task_uuid/notes", t.ListTaskNotesV2)
+		v2.POST("/:task_uuid/notes", t.AddTaskNoteV2)
+	}
+	groups := router.Group("v2/task-groups")
+	{
+		groups.POST("", t.CreateTaskGroupV2)
+		groups.GET("/:group_uuid", t.GetTaskGroupV2)
+		groups.POST("/:group_uuid/attach", t.AttachTaskGroupTasksV2)
+		groups.POST("/:group_uuid/tasks", t.CreateTaskGroupTasksV2)
+	}
+}
+
+// ListTasksQuery v2 任务列表查询参数
+type ListTasksQuery struct {
+	restapi.PageQuery
+	UserUUID string `form:"user_uuid"`
+	// Labels 标签选择器，如 labels=campaign=summer,source=mobile-app，需全部匹配
+	Labels string `form:"labels"`
+}
+
+func (t *transcodeControllerImpl) CreateTaskV2(c *gin.Context) {
+	var req cqe.CreateTranscodeTaskReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		failedV2(c, errno.NewSimpleBizError(errno.ErrInvalidParam, err))
+		return
+	}
+	res, err := t.transcodeApp.CreateTask(c.Request.Context(), &req)
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+func (t *transcodeControllerImpl) ListTasksV2(c *gin.Context) {
+	var q ListTasksQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		failedV2(c, errno.NewSimpleBizError(errno.ErrInvalidParam, err))
+		return
+	}
+	list, total, err := t.transcodeApp.ListTasks(c.Request.Context(), q.UserUUID, q.Labels, q.PageNum, q.PageSize)
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.SuccessWithPage(c, q.PageQuery, list, total)
+}
+
+// GetTaskV2 include=commands 时附带实际执行的 ffmpeg 命令
+func (t *transcodeControllerImpl) GetTaskV2(c *gin.Context) {
+	ctx := c.Request.Context()
+	taskUUID := c.Param("task_uuid")
+	res, err := t.transcodeApp.GetTask(ctx, taskUUID)
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	if includes(c.Query("include"), "commands") {
+		cmds, err := t.transcodeApp.GetTranscodeTaskCommands(ctx, taskUUID)
+		if err != nil {
+			failedV2(c, err)
+			return
+		}
+		res.Commands = cmds
+	}
+	restapi.SuccessWithETag(c, res)
+}
+
+func (t *transcodeControllerImpl) CancelTaskV2(c *gin.Context) {
+	ctx := c.Request.Context()
+	taskUUID := c.Param("task_uuid")
+	if err := t.transcodeApp.CancelTranscodeTask(ctx, taskUUID); err != nil {
+		failedV2(c, err)
+		return
+	}
+	res, err := t.transcodeApp.GetTask(ctx, taskUUID)
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+func (t *transcodeControllerImpl) BoostTaskPriorityV2(c *gin.Context) {
+	var req cqe.BoostTaskPriorityReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		failedV2(c, errno.NewSimpleBizError(errno.ErrInvalidParam, err))
+		return
+	}
+	req.TaskUUID = c.Param("task_uuid")
+	res, err := t.transcodeApp.BoostTaskPriority(c.Request.Context(), &req)
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+func (t *transcodeControllerImpl) ReplayTaskV2(c *gin.Context) {
+	var req cqe.ReplayTaskReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		failedV2(c, errno.NewSimpleBizError(errno.ErrInvalidParam, err))
+		return
+	}
+	req.TaskUUID = c.Param("task_uuid")
+	res, err := t.transcodeApp.ReplayTask(c.Request.Context(), &req)
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// RestoreTaskOutputV2 请求恢复已归档的产物，任务进入 restoring，恢复完成后回到 standard
+func (t *transcodeControllerImpl) RestoreTaskOutputV2(c *gin.Context) {
+	res, err := t.transcodeApp.RestoreTaskOutput(c.Request.Context(), c.Param("task_uuid"))
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// GetTaskSamplesV2 编码期间 ffmpeg 进程的生命周期与 CPU/RSS/GPU/fps/speed 时间序列
+func (t *transcodeControllerImpl) GetTaskSamplesV2(c *gin.Context) {
+	res, err := t.transcodeApp.GetTaskResourceTrace(c.Request.Context(), c.Param("task_uuid"))
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// GetTaskOutputURLsV2 产物访问地址，私有产物为签名网关 URL
+func (t *transcodeControllerImpl) GetTaskOutputURLsV2(c *gin.Context) {
+	res, err := t.transcodeApp.GetTaskOutputURLs(c.Request.Context(), c.Param("task_uuid"))
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+func (t *transcodeControllerImpl) ListTaskNotesV2(c *gin.Context) {
+	notes, err := t.transcodeApp.ListTaskNotes(c.Request.Context(), c.Param("task_uuid"))
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, notes)
+}
+
+func (t *transcodeControllerImpl) AddTaskNoteV2(c *gin.Context) {
+	var req cqe.AddTaskNoteReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		failedV2(c, errno.NewSimpleBizError(errno.ErrInvalidParam, err))
+		return
+	}
+	req.TaskUUID = c.Param("task_uuid")
+	res, err := t.transcodeApp.AddTaskNote(c.Request.Context(), &req)
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// CreateTaskGroupV2 创建任务组，返回 group_uuid 供后续加入任务与查询进度
+func (t *transcodeControllerImpl) CreateTaskGroupV2(c *gin.Context) {
+	var req cqe.CreateTaskGroupReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		failedV2(c, errno.NewSimpleBizError(errno.ErrInvalidParam, err))
+		return
+	}
+	res, err := t.transcodeApp.CreateTaskGroup(c.Request.Context(), &req)
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+func (t *transcodeControllerImpl) GetTaskGroupV2(c *gin.Context) {
+	res, err := t.transcodeApp.GetTaskGroup(c.Request.Context(), c.Param("group_uuid"))
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+func (t *transcodeControllerImpl) AttachTaskGroupTasksV2(c *gin.Context) {
+	var req cqe.AttachTaskGroupTasksReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		failedV2(c, errno.NewSimpleBizError(errno.ErrInvalidParam, err))
+		return
+	}
+	req.GroupUUID = c.Param("group_uuid")
+	res, err := t.transcodeApp.AttachTaskGroupTasks(c.Request.Context(), &req)
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// CreateTaskGroupTasksV2 批量创建组内任务，部分失败时仍返回 200，失败项见 errors
+func (t *transcodeControllerImpl) CreateTaskGroupTasksV2(c *gin.Context) {
+	var req cqe.CreateTaskGroupTasksReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		failedV2(c, errno.NewSimpleBizError(errno.ErrInvalidParam, err))
+		return
+	}
+	req.GroupUUID = c.Param("group_uuid")
+	res, err := t.transcodeApp.CreateTaskGroupTasks(c.Request.Context(), &req)
+	if err != nil {
+		failedV2(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+// failedV2 返回业务错误码对应的 HTTP 状态；v1 沿用统一 500
+func failedV2(c *gin.Context, err error) {
+	var no *errno.Errno
+	if errors.As(err, &no) {
+		err = errno.NewSimpleBizError(no, nil)
+	}
+	restapi.FailedWithStatus(c, err, httpStatusForCode(errno.AssertBizError(err).Code()))
+}
+
+func httpStatusForCode(code int) int {
+	switch code {
+	case errno.ErrInvalidParam.Code, errno.ErrMissingParam.Code, errno.ErrInvalidTaskStatus.Code,
+		errno.ErrUserUUIDRequired.Code, errno.ErrVideoUUIDRequired.Code, errno.ErrTaskUUIDRequired.Code,
+		errno.ErrOriginalPathRequired.Code, errno.ErrResolutionRequired.Code, errno.ErrBitrateRequired.Code,
+		errno.ErrStatusRequired.Code, errno.ErrBatchTooLarge.Code, errno.ErrInvalidPriority.Code,
+		errno.ErrInvalidLabels.Code, errno.ErrInvalidPreview.Code, errno.ErrInvalidStreamSelection.Code, errno.ErrInvalidProfile.Code,
+		errno.ErrInvalidTaskNote.Code, errno.ErrTaskNotReplayable.Code, errno.ErrInvalidAudioOutput.Code,
+		errno.ErrInvalidThumbnail.Code, errno.ErrInvalidFormatSet.Code, errno.ErrInvalidVisibility.Code,
+		errno.ErrPrivateOutputsDisabled.Code, errno.ErrInvalidOutputTarget.Code, errno.ErrUnsafeInputPath.Code, errno.ErrInvalidTaskGroup.Code:
+		return http.StatusBadRequest
+	case errno.ErrUnauthorized.Code:
+		return http.StatusUnauthorized
+	case errno.ErrNotFound.Code, errno.ErrTranscodeTaskNotFound.Code, errno.ErrResourceTraceNotFound.Code,
+		errno.ErrWorkerNotFound.Code, errno.ErrTaskGroupNotFound.Code:
+		return http.StatusNotFound
+	case errno.ErrTranscodeTaskExists.Code, errno.ErrTaskNotPending.Code, errno.ErrOutputNotArchived.Code,
+		errno.ErrWorkerStillLive.Code, errno.ErrTaskGroupClosed.Code:
+		return http.StatusConflict
+	case errno.ErrReplayRateLimited.Code:
+		return http.StatusTooManyRequests
+	case errno.ErrQueueFull.Code, errno.ErrWorkerNotAvailable.Code, errno.ErrPresenceUnavailable.Code:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"transcode-service/ddd/application/app"
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/pkg/assert"
+	"transcode-service/pkg/manager"
+	"transcode-service/pkg/restapi"
+)
+
+var (
+	userPreferenceControllerOnce      sync.Once
+	singletonUserPreferenceController UserPreferenceController
+)
+
+type UserPreferenceControllerPlugin struct {
+}
+
+func (p *UserPreferenceControllerPlugin) Name() string {
+	return "userPreferenceControllerPlugin"
+}
+
+func (p *UserPreferenceControllerPlugin) MustCreateController() manager.Controller {
+	assert.NotCircular()
+	userPreferenceControllerOnce.Do(func() {
+		singletonUserPreferenceController = &userPreferenceControllerImpl{
+			prefApp: app.DefaultUserPreferenceApp(),
+		}
+	})
+	assert.NotNil(singletonUserPreferenceController)
+	return singletonUserPreferenceController
+}
+
+type UserPreferenceController interface {
+	manager.Controller
+}
+
+type userPreferenceControllerImpl struct {
+	manager.Controller
+	prefApp app.UserPreferenceApp
+}
+
+// RegisterOpenApi 注册开放API
+func (u *userPreferenceControllerImpl) RegisterOpenApi(router *gin.RouterGroup) {
+	u.registerRoutes(router.Group("v1/transcode/preferences"))
+}
+
+// RegisterInnerApi 注册内部API，供上游服务代用户维护偏好
+func (u *userPreferenceControllerImpl) RegisterInnerApi(router *gin.RouterGroup) {
+	u.registerRoutes(router.Group("v1/transcode/preferences"))
+}
+
+// RegisterDebugApi 注册调试API
+func (u *userPreferenceControllerImpl) RegisterDebugApi(router *gin.RouterGroup) {
+}
+
+// RegisterOpsApi 注册运维API
+func (u *userPreferenceControllerImpl) RegisterOpsApi(router *gin.RouterGroup) {
+}
+
+func (u *userPreferenceControllerImpl) registerRoutes(group *gin.RouterGroup) {
+	group.GET("/:user_uuid", u.GetUserPreference)
+	group.PUT("/:user_uuid", u.SaveUserPreference)
+	group.DELETE("/:user_uuid", u.DeleteUserPreference)
+}
+
+func (u *userPreferenceControllerImpl) GetUserPreference(c *gin.Context) {
+	var req cqe.UserPreferenceReq
+	if err := c.ShouldBindUri(&req); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	res, err := u.prefApp.GetUserPreference(c.Request.Context(), req.UserUUID)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+func (u *userPreferenceControllerImpl) SaveUserPreference(c *gin.Context) {
+	var req cqe.SaveUserPreferenceReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	req.UserUUID = c.Param("user_uuid")
+	res, err := u.prefApp.SaveUserPreference(c.Request.Context(), &req)
+	if err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, res)
+}
+
+func (u *userPreferenceControllerImpl) DeleteUserPreference(c *gin.Context) {
+	var req cqe.UserPreferenceReq
+	if err := c.ShouldBindUri(&req); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	if err := u.prefApp.DeleteUserPreference(c.Request.Context(), req.UserUUID); err != nil {
+		restapi.Failed(c, err)
+		return
+	}
+	restapi.Success(c, nil)
+}
@@ -0,0 +1,403 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/service"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/benchmark"
+	"transcode-service/ddd/infrastructure/budget"
+	"transcode-service/ddd/infrastructure/database/persistence"
+	"transcode-service/ddd/infrastructure/diagnostics"
+	"transcode-service/ddd/infrastructure/hlsinspect"
+	"transcode-service/ddd/infrastructure/ladder"
+	"transcode-service/ddd/infrastructure/notify"
+	"transcode-service/ddd/infrastructure/publicurl"
+	"transcode-service/ddd/infrastructure/queue"
+	"transcode-service/ddd/infrastructure/selftest"
+	"transcode-service/ddd/infrastructure/storage"
+	"transcode-service/pkg/assert"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/fieldcrypt"
+	pkgkafka "transcode-service/pkg/kafka"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+var (
+	singleOpsApp OpsApp
+	onceOpsApp   sync.Once
+)
+
+// OpsApp 运维相关应用服务
+type OpsApp interface {
+	// SelfTest 端到端自检：编码测试图案、上传并回读，逐组件返回结果
+	SelfTest(ctx context.Context) *selftest.Report
+	// KafkaLag 最近一次采集的消费组积压
+	KafkaLag(ctx context.Context) (*pkgkafka.GroupLag, error)
+	// EffectiveConfig 合并默认值后的生效配置，敏感字段脱敏
+	EffectiveConfig(ctx context.Context) (map[string]interface{}, error)
+	// EncodeBudget MP4/HLS 共享编码槽位的当前占用
+	EncodeBudget(ctx context.Context) budget.Stats
+	// UpdateEncodeBudget 运行时调整共享编码槽位容量与权重
+	UpdateEncodeBudget(ctx context.Context, req *cqe.UpdateEncodeBudgetReq) (budget.Stats, error)
+	// SourceCache 源文件磁盘缓存统计
+	SourceCache(ctx context.Context) (*storage.SourceCacheStats, error)
+	// InvalidateSourceCache 删除对象的全部缓存版本，返回删除数量
+	InvalidateSourceCache(ctx context.Context, objectKey string) (int, error)
+	// InspectHLSJob 抽查已完成 HLS 作业的切片时长、不连续标记与关键帧对齐
+	InspectHLSJob(ctx context.Context, jobUUID string, samples int) (*hlsinspect.Report, error)
+	// GetHLSJob HLS 作业详情，含各路码流状态、错误与统计
+	GetHLSJob(ctx context.Context, jobUUID string) (*dto.HLSJobDto, error)
+	// RetryHLSJob 失败的 HLS 作业只重试失败（及未执行）的码流，已成功码流的产物保留
+	RetryHLSJob(ctx context.Context, jobUUID string) (*dto.HLSJobDto, error)
+	// RegenerateHLSMaster 按已完成的码流重新生成并上传 master playlist，单路码流重新编码后使用
+	RegenerateHLSMaster(ctx context.Context, jobUUID string) (*dto.HLSMasterDto, error)
+	// RegenerateVideoHLSMaster 对视频最近一次完成的 HLS 作业重新生成 master playlist
+	RegenerateVideoHLSMaster(ctx context.Context, videoUUID string) (*dto.HLSMasterDto, error)
+	// Workers 合并 Redis 存活状态与 MySQL 执行记录的 worker 列表
+	Workers(ctx context.Context) (*dto.WorkerListDto, error)
+	// GetWorker 单个 worker 详情：存活状态、能力标签、正在执行的任务、最近一次执行与健康状态
+	GetWorker(ctx context.Context, workerID string) (*dto.WorkerDetailDto, error)
+	// CheckWorkerHealth 按心跳间隔与近期执行结果判断 worker 健康状态
+	CheckWorkerHealth(ctx context.Context, workerID string) (*dto.WorkerHealthDto, error)
+	// DeleteWorker 删除 worker：重新派发已停止实例被打断的任务，或 force 取消存活实例的任务，并删除存活键与流水线快照
+	DeleteWorker(ctx context.Context, workerID string, req *cqe.DeleteWorkerReq) (*dto.WorkerDeletionDto, error)
+	// WorkerUtilization 按 worker 汇总作业分配历史，给出时间窗口内的编码槽位利用率
+	WorkerUtilization(ctx context.Context, q *cqe.WorkerUtilizationQuery) (*dto.WorkerUtilizationReportDto, error)
+	// Notifications 告警通道路由与事件阈值（不含密钥）
+	Notifications(ctx context.Context) (*notify.Status, error)
+	// TestNotification 向通道发送测试告警，返回逐通道结果
+	TestNotification(ctx context.Context, req *cqe.TestNotificationReq) ([]notify.SendResult, error)
+	// RotateEncryption 把加密列中的明文与旧密钥密文重加密为当前 primary 密钥
+	RotateEncryption(ctx context.Context) (*persistence.RotationReport, error)
+	// LadderCache 档位缓存状态
+	LadderCache(ctx context.Context) (*ladder.Stats, error)
+	// InvalidateLadderCache 失效档位缓存并通知其他实例，档位或规则变更后调用
+	InvalidateLadderCache(ctx context.Context) (*ladder.Invalidation, error)
+	// StartBenchmark 开始压测：生成合成源文件，按速率经完整流水线提交任务，结束后输出吞吐与耗时报告
+	StartBenchmark(ctx context.Context, req *cqe.StartBenchmarkReq) (*benchmark.Report, error)
+	// Benchmark 当前或最近一次压测的报告
+	Benchmark(ctx context.Context) (*benchmark.Report, error)
+	// StopBenchmark 停止提交并结束压测
+	StopBenchmark(ctx context.Context) (*benchmark.Report, error)
+	// StartProfileCapture 在本实例采集 trace/heap 等剖析文件并上传到对象存储
+	StartProfileCapture(ctx context.Context, req *cqe.StartProfileCaptureReq) (*diagnostics.Capture, error)
+	// ProfileCaptures 本实例最近的剖析采集记录
+	ProfileCaptures(ctx context.Context) ([]diagnostics.Capture, error)
+	// RecordOutputAccess 回写 CDN 访问日志中的产物最近访问时间，供分层存储判断冷产物
+	RecordOutputAccess(ctx context.Context, req *cqe.RecordOutputAccessReq) (*dto.OutputAccessResultDto, error)
+	// StaleOutputs 按设置版本或指纹哈希分页选出需要重新编码的已完成产物，供回填使用
+	StaleOutputs(ctx context.Context, q *cqe.StaleOutputQuery) (*dto.StaleOutputListDto, error)
+}
+
+type opsAppImpl struct {
+	selftest  *selftest.Runner
+	hlsRepo   repo.HLSJobRepository
+	inspector *hlsinspect.Inspector
+	master    *service.HLSMasterPublisher
+
+	transcodeRepo  repo.TranscodeJobRepository
+	assignmentRepo repo.TaskAssignmentRepository
+	snapshotRepo   repo.PipelineSnapshotRepository
+	noteRepo       repo.TaskNoteRepository
+	taskQueue      queue.TaskQueue
+	rotator        *persistence.FieldRotator
+	benchmark      *benchmark.Runner
+	capturer       *diagnostics.Capturer
+}
+
+func DefaultOpsApp() OpsApp {
+	assert.NotCircular()
+	onceOpsApp.Do(func() {
+		singleOpsApp = &opsAppImpl{
+			selftest:  selftest.NewRunner(config.GetGlobalConfig(), storage.DefaultStorageGateway()),
+			hlsRepo:   persistence.NewHLSRepository(),
+			inspector: hlsinspect.NewInspector(config.GetGlobalConfig(), storage.DefaultStorageGateway()),
+			master:    service.NewHLSMasterPublisher(config.GetGlobalConfig(), storage.DefaultStorageGateway()),
+
+			transcodeRepo:  persistence.NewTranscodeRepository(),
+			assignmentRepo: persistence.NewTaskAssignmentRepository(),
+			snapshotRepo:   persistence.NewPipelineSnapshotRepository(),
+			noteRepo:       persistence.NewTaskNoteRepository(),
+			taskQueue:      queue.DefaultTaskQueue(),
+			rotator:        persistence.NewFieldRotator(),
+			benchmark: benchmark.NewRunner(config.GetGlobalConfig(), storage.DefaultStorageGateway(),
+				persistence.NewTranscodeRepository(), submitBenchmarkTask),
+			capturer: diagnostics.NewCapturer(config.GetGlobalConfig(), storage.DefaultStorageGateway()),
+		}
+	})
+	assert.NotNil(singleOpsApp)
+	return singleOpsApp
+}
+
+func (o *opsAppImpl) EffectiveConfig(ctx context.Context) (map[string]interface{}, error) {
+	cfg := config.GetGlobalConfig()
+	if cfg == nil {
+		return nil, errno.ErrInternalServer
+	}
+	return cfg.Redacted(), nil
+}
+
+func (o *opsAppImpl) KafkaLag(ctx context.Context) (*pkgkafka.GroupLag, error) {
+	if lag := pkgkafka.DefaultLagMonitor().Snapshot(); lag != nil {
+		return lag, nil
+	}
+	return nil, errno.ErrKafkaLagUnavailable
+}
+
+func (o *opsAppImpl) EncodeBudget(ctx context.Context) budget.Stats {
+	return budget.DefaultEncodeBudget().Stats()
+}
+
+func (o *opsAppImpl) UpdateEncodeBudget(ctx context.Context, req *cqe.UpdateEncodeBudgetReq) (budget.Stats, error) {
+	if err := req.Validate(); err != nil {
+		return budget.Stats{}, err
+	}
+	return budget.DefaultEncodeBudget().Reconfigure(req.Enabled, req.Slots, req.JobWeights, req.ResolutionWeights), nil
+}
+
+func (o *opsAppImpl) SourceCache(ctx context.Context) (*storage.SourceCacheStats, error) {
+	cache := storage.DefaultSourceCache()
+	if cache == nil {
+		return nil, errno.ErrSourceCacheDisabled
+	}
+	stats := cache.Stats()
+	return &stats, nil
+}
+
+func (o *opsAppImpl) InvalidateSourceCache(ctx context.Context, objectKey string) (int, error) {
+	if objectKey == "" {
+		return 0, errno.ErrMissingParam
+	}
+	cache := storage.DefaultSourceCache()
+	if cache == nil {
+		return 0, errno.ErrSourceCacheDisabled
+	}
+	return cache.Invalidate(objectKey), nil
+}
+
+func (o *opsAppImpl) RotateEncryption(ctx context.Context) (*persistence.RotationReport, error) {
+	keyring := fieldcrypt.Writer()
+	if keyring == nil {
+		return nil, errno.ErrEncryptionDisabled
+	}
+	report, err := o.rotator.Rotate(ctx, keyring, config.GetGlobalConfig().Encryption.RotateBatchSize)
+	if err != nil {
+		logger.Errorf("field encryption rotation aborted primary_key_id=%s error=%v", keyring.PrimaryKeyID(), err)
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	return report, nil
+}
+
+func (o *opsAppImpl) LadderCache(ctx context.Context) (*ladder.Stats, error) {
+	stats := ladder.Default().Stats()
+	return &stats, nil
+}
+
+func (o *opsAppImpl) InvalidateLadderCache(ctx context.Context) (*ladder.Invalidation, error) {
+	res := &ladder.Invalidation{Broadcast: ladder.Broadcast(ctx, "admin")}
+	// 立即重新加载，返回新版本
+	ladder.Default().LadderCatalog(ctx)
+	res.Stats = ladder.Default().Stats()
+	logger.Infof("ladder cache invalidated by admin broadcast=%t version=%s", res.Broadcast, res.Stats.Version)
+	return res, nil
+}
+
+func (o *opsAppImpl) InspectHLSJob(ctx context.Context, jobUUID string, samples int) (*hlsinspect.Report, error) {
+	if jobUUID == "" {
+		return nil, errno.ErrMissingParam
+	}
+	job, err := o.hlsRepo.GetHLSJob(ctx, jobUUID)
+	if err != nil || job == nil {
+		return nil, errno.ErrHLSJobNotFound
+	}
+	if job.Status() != vo.HLSStatusCompleted.String() {
+		return nil, errno.ErrHLSJobNotReady
+	}
+	return o.inspector.Inspect(ctx, job, samples)
+}
+
+func (o *opsAppImpl) GetHLSJob(ctx context.Context, jobUUID string) (*dto.HLSJobDto, error) {
+	if jobUUID == "" {
+		return nil, errno.ErrMissingParam
+	}
+	job, err := o.hlsRepo.GetHLSJob(ctx, jobUUID)
+	if err != nil || job == nil {
+		return nil, errno.ErrHLSJobNotFound
+	}
+	return dto.NewHLSJobDto(job), nil
+}
+
+func (o *opsAppImpl) RetryHLSJob(ctx context.Context, jobUUID string) (*dto.HLSJobDto, error) {
+	if jobUUID == "" {
+		return nil, errno.ErrMissingParam
+	}
+	job, err := o.hlsRepo.GetHLSJob(ctx, jobUUID)
+	if err != nil || job == nil {
+		return nil, errno.ErrHLSJobNotFound
+	}
+	if job.Status() != vo.HLSStatusFailed.String() {
+		return nil, errno.ErrHLSJobNotRetryable
+	}
+	reset := job.ResetFailedRenditions()
+	ok, err := o.hlsRepo.ResetHLSJobForRetry(ctx, jobUUID, job.Renditions())
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if !ok {
+		// 并发重试已将作业置回 pending
+		return nil, errno.ErrHLSJobNotRetryable
+	}
+	job.SetStatus(vo.HLSStatusPending)
+	job.SetError("")
+	job.SetProgress(0)
+	if err := queue.DefaultHLSJobQueue().Enqueue(ctx, job); err != nil {
+		logger.Warnf("enqueue retried hls job failed, left pending for pickup job_uuid=%s error=%v", jobUUID, err)
+	}
+	metrics.Add("hls_job_retries_total", 1)
+	logger.Infof("hls job retry requested job_uuid=%s reset_renditions=%d kept_renditions=%d", jobUUID, reset, job.Renditions().CountByStatus(vo.RenditionCompleted))
+	return dto.NewHLSJobDto(job), nil
+}
+
+func (o *opsAppImpl) RegenerateHLSMaster(ctx context.Context, jobUUID string) (*dto.HLSMasterDto, error) {
+	if jobUUID == "" {
+		return nil, errno.ErrMissingParam
+	}
+	job, err := o.hlsRepo.GetHLSJob(ctx, jobUUID)
+	if err != nil || job == nil {
+		return nil, errno.ErrHLSJobNotFound
+	}
+	return o.regenerateHLSMaster(ctx, job)
+}
+
+func (o *opsAppImpl) RegenerateVideoHLSMaster(ctx context.Context, videoUUID string) (*dto.HLSMasterDto, error) {
+	if videoUUID == "" {
+		return nil, errno.ErrVideoUUIDRequired
+	}
+	jobs, err := o.hlsRepo.QueryHLSJobsByVideo(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	var latest *entity.HLSJobEntity
+	for _, job := range jobs {
+		if job.Status() != vo.HLSStatusCompleted.String() {
+			continue
+		}
+		if latest == nil || job.CreatedAt().After(latest.CreatedAt()) {
+			latest = job
+		}
+	}
+	if latest == nil {
+		return nil, errno.ErrHLSJobNotFound
+	}
+	return o.regenerateHLSMaster(ctx, latest)
+}
+
+// regenerateHLSMaster 只处理已完成作业，处理中的作业完成时会自行上传 master
+func (o *opsAppImpl) regenerateHLSMaster(ctx context.Context, job *entity.HLSJobEntity) (*dto.HLSMasterDto, error) {
+	if job.Status() != vo.HLSStatusCompleted.String() {
+		return nil, errno.ErrHLSMasterNotRegenerable
+	}
+	key, resolutions, err := o.master.Publish(ctx, job)
+	if errors.Is(err, service.ErrNoCompletedRenditions) {
+		return nil, errno.ErrHLSMasterNotRegenerable
+	}
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrUploadError, err)
+	}
+	res := &dto.HLSMasterDto{JobUUID: job.JobUUID(), VideoUUID: job.VideoUUID(), ObjectKey: key, Resolutions: resolutions}
+	if !job.IsPrivate() {
+		res.MasterPlaylist = publicurl.DefaultBuilder().Build(key)
+	} else if u, _, err := publicurl.BuildPrivate(key); err == nil {
+		res.MasterPlaylist = u
+	}
+	if res.MasterPlaylist != "" {
+		if err := o.hlsRepo.UpdateHLSJobOutput(ctx, job.JobUUID(), res.MasterPlaylist); err != nil {
+			return nil, errno.NewBizError(errno.ErrDatabase, err)
+		}
+	}
+	return res, nil
+}
+
+func (o *opsAppImpl) StartBenchmark(ctx context.Context, req *cqe.StartBenchmarkReq) (*benchmark.Report, error) {
+	return o.benchmark.Start(benchmark.Params{
+		Resolutions:   req.Resolutions,
+		SourceSeconds: req.SourceSeconds,
+		RatePerMinute: req.RatePerMinute,
+		Minutes:       req.Minutes,
+	})
+}
+
+func (o *opsAppImpl) Benchmark(ctx context.Context) (*benchmark.Report, error) {
+	return o.benchmark.Status()
+}
+
+func (o *opsAppImpl) StopBenchmark(ctx context.Context) (*benchmark.Report, error) {
+	return o.benchmark.Stop()
+}
+
+func (o *opsAppImpl) StartProfileCapture(ctx context.Context, req *cqe.StartProfileCaptureReq) (*diagnostics.Capture, error) {
+	return o.capturer.Start(req.Kind, req.Seconds)
+}
+
+func (o *opsAppImpl) ProfileCaptures(ctx context.Context) ([]diagnostics.Capture, error) {
+	return o.capturer.List(), nil
+}
+
+// submitBenchmarkTask 压测任务与普通任务走同一创建路径，码率取档位目录中同名档位
+func submitBenchmarkTask(ctx context.Context, videoUUID, sourceKey, resolution string) (string, error) {
+	bitrate := "2000k"
+	for _, of := range ladder.Default().LadderCatalog(ctx).OutputFormats {
+		if of.Name == resolution && of.Bitrate != "" {
+			bitrate = of.Bitrate
+			break
+		}
+	}
+	res, err := DefaultTranscodeApp().CreateTask(ctx, &cqe.CreateTranscodeTaskReq{
+		UserUUID:     config.GetGlobalConfig().Benchmark.UserUUID,
+		VideoUUID:    videoUUID,
+		OriginalPath: sourceKey,
+		Resolution:   resolution,
+		Bitrate:      bitrate,
+		Labels:       map[string]string{"source": "benchmark"},
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.TaskUUID, nil
+}
+
+func (o *opsAppImpl) SelfTest(ctx context.Context) *selftest.Report {
+	return o.selftest.Run(ctx)
+}
+
+func (o *opsAppImpl) Notifications(ctx context.Context) (*notify.Status, error) {
+	n := notify.DefaultNotifier()
+	if !n.Enabled() {
+		return nil, errno.ErrNotificationsDisabled
+	}
+	status := n.Status()
+	return &status, nil
+}
+
+func (o *opsAppImpl) TestNotification(ctx context.Context, req *cqe.TestNotificationReq) ([]notify.SendResult, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	n := notify.DefaultNotifier()
+	if !n.Enabled() {
+		return nil, errno.ErrNotificationsDisabled
+	}
+	if req.Channel != "" && !n.HasChannel(req.Channel) {
+		return nil, errno.ErrNotificationChannelNotFound
+	}
+	return n.TestFire(ctx, req.Channel, req.Severity), nil
+}
@@ -0,0 +1,23 @@
+package app
+
+import (
+	"context"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/metrics"
+)
+
+func (o *opsAppImpl) RecordOutputAccess(ctx context.Context, req *cqe.RecordOutputAccessReq) (*dto.OutputAccessResultDto, error) {
+	if err := req.Validate(clock.Now()); err != nil {
+		return nil, err
+	}
+	n, err := o.transcodeRepo.TouchTranscodeOutputAccess(ctx, req.ObjectKeys, *req.AccessedAt)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	metrics.Add("output_access_updates_total", int64(n))
+	return &dto.OutputAccessResultDto{Received: len(req.ObjectKeys), Updated: n}, nil
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"path"
+
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/service"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/publicurl"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+)
+
+// applyVisibility 按请求（缺省取 private_outputs.default_visibility）决定产物可见性，私有任务的产物 key 改写到租户令牌目录
+func applyVisibility(task *entity.TranscodeTaskEntity, visibility string) error {
+	var pc config.PrivateOutputsConfig
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		pc = cfg.PrivateOutputs
+	}
+	fallback, err := vo.ParseVisibility(pc.DefaultVisibility, vo.VisibilityPublic)
+	if err != nil {
+		fallback = vo.VisibilityPublic
+	}
+	v, err := vo.ParseVisibility(visibility, fallback)
+	if err != nil {
+		return errno.ErrInvalidVisibility
+	}
+	if v != vo.VisibilityPrivate {
+		return nil
+	}
+	secret := pc.ResolvedKeySecret()
+	if secret == "" || pc.ResolvedSignSecret() == "" {
+		return errno.ErrPrivateOutputsDisabled
+	}
+	task.MakePrivate(vo.PrivateKeyToken(secret, task.UserUUID(), task.VideoUUID()))
+	return nil
+}
+
+// applyOutputDestination 请求指定目标桶时按 output_targets 白名单校验，产物 key 改写到该桶；
+// 私有产物依赖默认私有桶的签名网关，不能同时指定
+func applyOutputDestination(task *entity.TranscodeTaskEntity, bucket, prefix string) error {
+	dest, err := vo.NewOutputDestination(bucket, prefix)
+	if err != nil {
+		return errno.ErrInvalidOutputTarget
+	}
+	if dest.IsZero() {
+		return nil
+	}
+	cfg := config.GetGlobalConfig()
+	if cfg == nil || task.Visibility() == vo.VisibilityPrivate {
+		return errno.ErrInvalidOutputTarget
+	}
+	target, ok := cfg.FindOutputTarget(dest.Bucket)
+	if !ok || !target.AllowsPrefix(dest.Prefix) {
+		return errno.ErrInvalidOutputTarget
+	}
+	task.RouteOutputs(dest)
+	return nil
+}
+
+func (t *transcodeAppImpl) GetTaskOutputURLs(ctx context.Context, taskUUID string) (*dto.OutputURLsDto, error) {
+	if taskUUID == "" {
+		return nil, errno.ErrTaskUUIDRequired
+	}
+	task, err := t.taskReader.GetTranscodeJob(ctx, taskUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if task == nil {
+		return nil, errno.ErrTranscodeTaskNotFound
+	}
+	res := &dto.OutputURLsDto{TaskUUID: task.TaskUUID(), Visibility: string(task.Visibility())}
+	if !task.IsCompleted() {
+		return res, nil
+	}
+	var hlsKey string
+	if t.hlsRepo != nil {
+		hlsJob, err := t.hlsRepo.GetHLSJobBySource(ctx, task.TaskUUID())
+		if err != nil {
+			logger.Warnf("get hls job by source failed task_uuid=%s error=%v", task.TaskUUID(), err)
+		} else if hlsJob != nil && hlsJob.Status() == vo.HLSStatusCompleted.String() {
+			hlsKey = path.Join(service.HLSObjectKeyPrefix(config.GetGlobalConfig(), hlsJob), service.HLSMasterPlaylistName)
+		}
+	}
+	if task.Visibility() == vo.VisibilityPublic {
+		res.URL = publicurl.DefaultBuilder().Build(task.OutputPath())
+		res.HLSURL = publicurl.DefaultBuilder().Build(hlsKey)
+		return res, nil
+	}
+	sign := func(key string) (string, error) {
+		if key == "" {
+			return "", nil
+		}
+		u, exp, err := publicurl.BuildPrivate(key)
+		if err != nil {
+			return "", err
+		}
+		res.ExpiresAt = types.NewTimeOrNil(exp)
+		return u, nil
+	}
+	if res.URL, err = sign(task.OutputPath()); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrPrivateOutputsDisabled, err)
+	}
+	if res.HLSURL, err = sign(hlsKey); err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrPrivateOutputsDisabled, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+)
+
+func (o *opsAppImpl) StaleOutputs(ctx context.Context, q *cqe.StaleOutputQuery) (*dto.StaleOutputListDto, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	filter := vo.StaleOutputFilter{BeforeVersion: q.BeforeVersion, ExcludeHash: q.ExcludeHash}
+	res := &dto.StaleOutputListDto{Kind: q.Kind, Items: []dto.StaleOutputDto{}}
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		res.CurrentSettingsVersion = cfg.Transcode.SettingsVersion
+	}
+	if q.Kind == cqe.OutputKindHLS {
+		jobs, err := o.hlsRepo.QueryStaleHLSJobs(ctx, filter, q.AfterID, q.Limit)
+		if err != nil {
+			return nil, errno.ErrDatabase
+		}
+		for _, j := range jobs {
+			item := dto.StaleOutputDto{ID: j.ID(), UUID: j.JobUUID(), UserUUID: j.UserUUID(), VideoUUID: j.VideoUUID(),
+				Fingerprint: j.Fingerprint(), UpdatedAt: types.NewTime(j.UpdatedAt())}
+			if m := j.MasterPlaylist(); m != nil {
+				item.Output = *m
+			}
+			res.Items = append(res.Items, item)
+		}
+	} else {
+		tasks, err := o.transcodeRepo.QueryStaleTranscodeJobs(ctx, filter, q.AfterID, q.Limit)
+		if err != nil {
+			return nil, errno.ErrDatabase
+		}
+		for _, t := range tasks {
+			res.Items = append(res.Items, dto.StaleOutputDto{ID: t.ID(), UUID: t.TaskUUID(), UserUUID: t.UserUUID(),
+				VideoUUID: t.VideoUUID(), Output: t.OutputPath(), Fingerprint: t.Fingerprint(), UpdatedAt: types.NewTime(t.UpdatedAt())})
+		}
+	}
+	if len(res.Items) == q.Limit {
+		res.NextAfterID = res.Items[len(res.Items)-1].ID
+	}
+	return res, nil
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"sync"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/persistence"
+	"transcode-service/pkg/assert"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/errno"
+)
+
+var (
+	singleStatisticsApp StatisticsApp
+	onceStatisticsApp   sync.Once
+)
+
+type StatisticsApp interface {
+	// GetThroughput 按 UTC 日期返回源文件下载字节、产物上传字节与编码耗时
+	GetThroughput(ctx context.Context, q *cqe.ThroughputQuery) (*dto.ThroughputReportDto, error)
+}
+
+type statisticsAppImpl struct {
+	throughputRepo repo.ThroughputRepository
+}
+
+func DefaultStatisticsApp() StatisticsApp {
+	assert.NotCircular()
+	onceStatisticsApp.Do(func() {
+		singleStatisticsApp = &statisticsAppImpl{throughputRepo: persistence.NewThroughputRepository()}
+	})
+	assert.NotNil(singleStatisticsApp)
+	return singleStatisticsApp
+}
+
+func (a *statisticsAppImpl) GetThroughput(ctx context.Context, q *cqe.ThroughputQuery) (*dto.ThroughputReportDto, error) {
+	if err := q.Validate(clock.Now()); err != nil {
+		return nil, err
+	}
+	from, to := q.FromDate.Format(vo.ThroughputDateLayout), q.ToDate.Format(vo.ThroughputDateLayout)
+	rows, err := a.throughputRepo.ListDailyThroughput(ctx, from, to)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	byDate := make(map[string]vo.DailyThroughput, len(rows))
+	for _, r := range rows {
+		byDate[r.Date] = r
+	}
+	var total vo.DailyThroughput
+	report := &dto.ThroughputReportDto{From: from, To: to, Days: make([]dto.ThroughputDto, 0, q.Days())}
+	for i := 0; i < q.Days(); i++ {
+		date := q.FromDate.AddDate(0, 0, i).Format(vo.ThroughputDateLayout)
+		day, ok := byDate[date]
+		if !ok {
+			day = vo.DailyThroughput{Date: date}
+		}
+		total.Add(day)
+		report.Days = append(report.Days, dto.NewThroughputDto(day))
+	}
+	report.Total = dto.NewThroughputDto(total)
+	return report, nil
+}
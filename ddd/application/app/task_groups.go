@@ -0,0 +1,195 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// taskGroupBatchHold 批量创建任务时的完成保护期，实例在创建中途崩溃时保护期过后任务组照常完成
+const taskGroupBatchHold = 10 * time.Minute
+
+func taskGroupsConfig() config.TaskGroupsConfig {
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		return cfg.TaskGroups
+	}
+	return config.TaskGroupsConfig{MaxTasks: 500}
+}
+
+func (t *transcodeAppImpl) CreateTaskGroup(ctx context.Context, req *cqe.CreateTaskGroupReq) (*dto.TaskGroupDto, error) {
+	cfg := taskGroupsConfig()
+	if err := req.Validate(cfg.MaxTasks, cfg.AllowsHost); err != nil {
+		return nil, err
+	}
+	if t.groupRepo == nil {
+		return nil, errno.ErrInternalServer
+	}
+	group := entity.NewTaskGroupEntity(clock.NewID(), req.UserUUID, req.Name, req.CallbackURL, req.ExpectedTasks)
+	if err := t.groupRepo.CreateTaskGroup(ctx, group); err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	metrics.Add("task_group_created_total", 1)
+	logger.Infof("task group created group_uuid=%s user_uuid=%s expected_tasks=%d", group.GroupUUID(), group.UserUUID(), group.ExpectedTasks())
+	return t.taskGroupDto(ctx, group, false)
+}
+
+func (t *transcodeAppImpl) GetTaskGroup(ctx context.Context, groupUUID string) (*dto.TaskGroupDto, error) {
+	group, err := t.loadTaskGroup(ctx, groupUUID)
+	if err != nil {
+		return nil, err
+	}
+	return t.taskGroupDto(ctx, group, true)
+}
+
+func (t *transcodeAppImpl) AttachTaskGroupTasks(ctx context.Context, req *cqe.AttachTaskGroupTasksReq) (*dto.TaskGroupDto, error) {
+	cfg := taskGroupsConfig()
+	if err := req.Validate(cfg.MaxTasks); err != nil {
+		return nil, err
+	}
+	group, err := t.openTaskGroup(ctx, req.GroupUUID, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkGroupCapacity(ctx, group, len(req.TaskUUIDs), cfg.MaxTasks); err != nil {
+		return nil, err
+	}
+	n, err := t.groupRepo.AttachTasks(ctx, group.GroupUUID(), group.UserUUID(), req.TaskUUIDs)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	logger.Infof("tasks attached to group group_uuid=%s requested=%d attached=%d", group.GroupUUID(), len(req.TaskUUIDs), n)
+	return t.taskGroupDto(ctx, group, true)
+}
+
+func (t *transcodeAppImpl) CreateTaskGroupTasks(ctx context.Context, req *cqe.CreateTaskGroupTasksReq) (*dto.TaskGroupTasksDto, error) {
+	cfg := taskGroupsConfig()
+	if err := req.Validate(cfg.MaxTasks); err != nil {
+		return nil, err
+	}
+	group, err := t.openTaskGroup(ctx, req.GroupUUID, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := t.checkGroupCapacity(ctx, group, len(req.Tasks), cfg.MaxTasks); err != nil {
+		return nil, err
+	}
+	// 逐个创建期间先到的任务可能已结束，设置保护期避免任务组在只创建了部分任务时提前完成
+	holdUntil := clock.Now().Add(taskGroupBatchHold)
+	if err := t.groupRepo.HoldTaskGroup(ctx, group.GroupUUID(), holdUntil); err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	defer func() {
+		if err := t.groupRepo.ReleaseTaskGroup(context.WithoutCancel(ctx), group.GroupUUID(), holdUntil); err != nil {
+			logger.Warnf("release task group hold failed group_uuid=%s error=%v", group.GroupUUID(), err)
+		}
+	}()
+	res := &dto.TaskGroupTasksDto{Tasks: make([]*dto.TaskResource, 0, len(req.Tasks))}
+	for i := range req.Tasks {
+		item := &req.Tasks[i]
+		if item.UserUUID == "" {
+			item.UserUUID = group.UserUUID()
+		}
+		item.GroupUUID = group.GroupUUID()
+		task, err := t.CreateTask(ctx, item)
+		if err != nil {
+			res.Errors = append(res.Errors, taskGroupItemError(i, err))
+			continue
+		}
+		if task.GroupUUID == "" {
+			// 幂等命中或同一视频已有未完成任务时返回的是已有任务，补加入任务组
+			if n, err := t.groupRepo.AttachTasks(ctx, group.GroupUUID(), group.UserUUID(), []string{task.TaskUUID}); err == nil && n > 0 {
+				task.GroupUUID = group.GroupUUID()
+			}
+		}
+		res.Tasks = append(res.Tasks, task)
+	}
+	if res.Group, err = t.taskGroupDto(ctx, group, false); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// taskGroupItemError 错误码与消息，与单个创建接口的响应一致
+func taskGroupItemError(index int, err error) dto.TaskGroupItemErrorDto {
+	var no *errno.Errno
+	if errors.As(err, &no) {
+		err = errno.NewSimpleBizError(no, nil)
+	}
+	biz := errno.AssertBizError(err)
+	return dto.TaskGroupItemErrorDto{Index: index, Code: biz.Code(), Message: biz.Message()}
+}
+
+// resolveTaskGroup 创建任务时校验请求中的任务组：须存在、未完成且属于同一用户
+func (t *transcodeAppImpl) resolveTaskGroup(ctx context.Context, req *cqe.TranscodeTaskCqe) (string, error) {
+	if req.GroupUUID == "" {
+		return "", nil
+	}
+	group, err := t.openTaskGroup(ctx, req.GroupUUID, req.UserUUID)
+	if err != nil {
+		return "", err
+	}
+	return group.GroupUUID(), nil
+}
+
+func (t *transcodeAppImpl) loadTaskGroup(ctx context.Context, groupUUID string) (*entity.TaskGroupEntity, error) {
+	if t.groupRepo == nil || groupUUID == "" {
+		return nil, errno.ErrTaskGroupNotFound
+	}
+	group, err := t.groupRepo.GetTaskGroup(ctx, groupUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if group == nil {
+		return nil, errno.ErrTaskGroupNotFound
+	}
+	return group, nil
+}
+
+// openTaskGroup userUUID 非空时还要求任务组属于该用户
+func (t *transcodeAppImpl) openTaskGroup(ctx context.Context, groupUUID, userUUID string) (*entity.TaskGroupEntity, error) {
+	group, err := t.loadTaskGroup(ctx, groupUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !group.IsOpen() || (userUUID != "" && group.UserUUID() != userUUID) {
+		return nil, errno.ErrTaskGroupClosed
+	}
+	return group, nil
+}
+
+// checkGroupCapacity 加入后组内任务数不得超过 task_groups.max_tasks
+func (t *transcodeAppImpl) checkGroupCapacity(ctx context.Context, group *entity.TaskGroupEntity, adding, maxTasks int) error {
+	progress, err := t.groupRepo.CountGroupTasks(ctx, group.GroupUUID())
+	if err != nil {
+		return errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if progress.Total+adding > maxTasks {
+		return errno.ErrInvalidTaskGroup
+	}
+	return nil
+}
+
+func (t *transcodeAppImpl) taskGroupDto(ctx context.Context, group *entity.TaskGroupEntity, withTasks bool) (*dto.TaskGroupDto, error) {
+	progress, err := t.groupRepo.CountGroupTasks(ctx, group.GroupUUID())
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	res := dto.NewTaskGroupDto(group, progress)
+	if withTasks {
+		tasks, err := t.groupRepo.ListGroupTasks(ctx, group.GroupUUID(), taskGroupsConfig().MaxTasks)
+		if err != nil {
+			return nil, errno.NewBizError(errno.ErrDatabase, err)
+		}
+		res.Tasks = dto.NewTaskGroupTaskDtos(tasks)
+	}
+	return res, nil
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+)
+
+// maxTaskNotes 单个任务详情最多返回的备注条数
+const maxTaskNotes = 200
+
+func (t *transcodeAppImpl) AddTaskNote(ctx context.Context, req *cqe.AddTaskNoteReq) (*dto.TaskNoteDto, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if t.noteRepo == nil {
+		return nil, errno.ErrInternalServer
+	}
+	task, err := t.taskReader.GetTranscodeJob(ctx, req.TaskUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if task == nil {
+		return nil, errno.ErrTranscodeTaskNotFound
+	}
+	note := &vo.TaskNote{
+		NoteUUID:  clock.NewID(),
+		TaskUUID:  req.TaskUUID,
+		Author:    req.Author,
+		Body:      req.Body,
+		CreatedAt: clock.Now(),
+	}
+	if err := t.noteRepo.CreateTaskNote(ctx, note); err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	logger.Infof("task note added task_uuid=%s author=%s", req.TaskUUID, req.Author)
+	res := dto.NewTaskNoteDto(note)
+	return &res, nil
+}
+
+func (t *transcodeAppImpl) ListTaskNotes(ctx context.Context, taskUUID string) ([]dto.TaskNoteDto, error) {
+	if taskUUID == "" {
+		return nil, errno.ErrTaskUUIDRequired
+	}
+	task, err := t.taskReader.GetTranscodeJob(ctx, taskUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if task == nil {
+		return nil, errno.ErrTranscodeTaskNotFound
+	}
+	if t.noteRepo == nil {
+		return []dto.TaskNoteDto{}, nil
+	}
+	notes, err := t.noteRepo.ListTaskNotes(ctx, taskUUID, maxTaskNotes)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	return dto.NewTaskNoteDtos(notes), nil
+}
+
+// fillNotes 详情附带备注；查询失败只记日志，不影响任务详情
+func (t *transcodeAppImpl) fillNotes(ctx context.Context, res *dto.TaskResource) {
+	if t.noteRepo == nil {
+		return
+	}
+	notes, err := t.noteRepo.ListTaskNotes(ctx, res.TaskUUID, maxTaskNotes)
+	if err != nil {
+		logger.Warnf("list task notes failed task_uuid=%s error=%v", res.TaskUUID, err)
+		return
+	}
+	res.Notes = dto.NewTaskNoteDtos(notes)
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"path"
+
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/domain/service"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/publicurl"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+)
+
+func (t *transcodeAppImpl) GetTaskOutputs(ctx context.Context, taskUUID string) ([]dto.TaskOutputDto, error) {
+	if taskUUID == "" {
+		return nil, errno.ErrTaskUUIDRequired
+	}
+	task, err := t.taskReader.GetTranscodeJob(ctx, taskUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if task == nil {
+		return nil, errno.ErrTranscodeTaskNotFound
+	}
+	if !task.IsCompleted() {
+		return nil, nil
+	}
+
+	// 产物地址与 GetTaskOutputURLs 一致：公开产物走公共地址，私有产物每次重新签名
+	private := task.Visibility() == vo.VisibilityPrivate
+	outputs := make([]dto.TaskOutputDto, 0, 4)
+	add := func(typ, resolution, key string, size int64) error {
+		if key == "" {
+			return nil
+		}
+		url := publicurl.DefaultBuilder().Build(key)
+		if private {
+			u, _, err := publicurl.BuildPrivate(key)
+			if err != nil {
+				return errno.NewSimpleBizError(errno.ErrPrivateOutputsDisabled, err)
+			}
+			url = u
+		}
+		outputs = append(outputs, dto.TaskOutputDto{Type: typ, Resolution: resolution, URL: url, Size: size})
+		return nil
+	}
+
+	params := task.GetParams()
+	if err := add(dto.TaskOutputVideo, params.Resolution, task.OutputPath(), t.objectSize(ctx, task.OutputPath())); err != nil {
+		return nil, err
+	}
+	if params.HasAudioOutputs() {
+		for _, r := range params.Audio.Renditions {
+			if err := add(dto.TaskOutputAudio, string(r.Format)+"@"+r.Bitrate, r.ObjectKey, t.objectSize(ctx, r.ObjectKey)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if t.hlsRepo == nil {
+		return outputs, nil
+	}
+	hlsJob, err := t.hlsRepo.GetHLSJobBySource(ctx, task.TaskUUID())
+	if err != nil {
+		logger.Warnf("get hls job by source failed task_uuid=%s error=%v", task.TaskUUID(), err)
+		return outputs, nil
+	}
+	if hlsJob == nil || hlsJob.Status() != vo.HLSStatusCompleted.String() {
+		return outputs, nil
+	}
+	prefix := service.HLSObjectKeyPrefix(config.GetGlobalConfig(), hlsJob)
+	masterKey := path.Join(prefix, service.HLSMasterPlaylistName)
+	if err := add(dto.TaskOutputHLSMaster, "", masterKey, t.objectSize(ctx, masterKey)); err != nil {
+		return nil, err
+	}
+	renditions := hlsJob.Renditions()
+	for _, res := range service.HLSMasterResolutions(hlsJob) {
+		var size int64
+		if r := renditions.Find(res.Resolution); r != nil {
+			size = r.Bytes
+		}
+		key := path.Join(prefix, service.HLSRenditionPlaylist(hlsJob, res.Resolution))
+		if err := add(dto.TaskOutputHLSRendition, res.Resolution, key, size); err != nil {
+			return nil, err
+		}
+	}
+	return outputs, nil
+}
+
+// objectSize 查询对象大小，失败时返回 0（大小仅供参考，不影响地址返回）
+func (t *transcodeAppImpl) objectSize(ctx context.Context, key string) int64 {
+	if t.storage == nil || key == "" {
+		return 0
+	}
+	info, err := t.storage.StatObject(ctx, key)
+	if err != nil {
+		logger.Warnf("stat output object failed key=%s error=%v", key, err)
+		return 0
+	}
+	return info.Size
+}
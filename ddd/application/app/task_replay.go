@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// replayLimiter 按原任务用户的每分钟固定窗口计数，仅限本实例
+type replayLimiter struct {
+	mu      sync.Mutex
+	windows map[string]replayWindow
+}
+
+type replayWindow struct {
+	start time.Time
+	count int
+}
+
+var defaultReplayLimiter = &replayLimiter{windows: make(map[string]replayWindow)}
+
+func (l *replayLimiter) allow(key string, limit int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	w := l.windows[key]
+	if now.Sub(w.start) >= time.Minute {
+		w = replayWindow{start: now}
+		// 顺带清理过期窗口，避免 map 无限增长
+		for k, old := range l.windows {
+			if now.Sub(old.start) >= time.Minute {
+				delete(l.windows, k)
+			}
+		}
+	}
+	if w.count >= limit {
+		return false
+	}
+	w.count++
+	l.windows[key] = w
+	return true
+}
+
+// ReplayTask 克隆已结束任务（同一源文件），按请求覆盖编码器/预设/分辨率/码率后重新排队；
+// 新任务通过 parent_task_uuid 关联原任务，产物发布到 replay 前缀且不生成 HLS
+func (t *transcodeAppImpl) ReplayTask(ctx context.Context, req *cqe.ReplayTaskReq) (*dto.TaskResource, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	parent, err := t.transcodeRepo.GetTranscodeJob(ctx, req.TaskUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if parent == nil {
+		return nil, errno.ErrTranscodeTaskNotFound
+	}
+	if !parent.IsCompleted() && !parent.IsFailed() {
+		return nil, errno.ErrTaskNotReplayable
+	}
+	limit := 5
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		limit = cfg.Transcode.Replay.MaxPerMinute
+	}
+	if !defaultReplayLimiter.allow(parent.UserUUID(), limit, clock.Now()) {
+		metrics.Add("task_replays_rate_limited_total", 1)
+		return nil, errno.ErrReplayRateLimited
+	}
+
+	params, err := replayParams(parent.GetParams(), req)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrInvalidParam, err)
+	}
+	task := entity.DefaultTranscodeTaskEntity(parent.UserUUID(), parent.VideoUUID(), parent.VideoPushUUID(), parent.OriginalPath(), params)
+	task.SetLabels(parent.Labels())
+	task.SetSourceGeneration(parent.SourceGeneration())
+	task.MarkReplayOf(parent.TaskUUID())
+	if parent.PrivateToken() != "" {
+		// 私有视频的重放产物同样只能签名访问
+		task.MakePrivate(parent.PrivateToken())
+	}
+	task.RouteOutputs(parent.OutputDestination())
+	task.SetLane(parent.Lane())
+
+	if err := t.transcodeRepo.CreateTranscodeJob(ctx, task); err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if err := t.taskQueue.Enqueue(ctx, task); err != nil {
+		logger.Errorf("重放任务入队失败 task_uuid=%s error=%v", task.TaskUUID(), err)
+		_ = task.TransitionTo(vo.TaskStatusFailed)
+		task.SetErrorMessage(fmt.Errorf("enqueue task failed: %w", err).Error())
+		_ = t.transcodeRepo.SaveTranscodeJobStatus(ctx, task)
+		return nil, errno.ErrQueueFull
+	}
+	metrics.Add("task_replays_total", 1)
+	logger.Infof("task replayed task_uuid=%s parent_task_uuid=%s codec=%s preset=%s resolution=%s bitrate=%s reason=%s",
+		task.TaskUUID(), parent.TaskUUID(), req.VideoCodec, req.Preset, params.Resolution, params.Bitrate, req.Reason)
+	return dto.NewTaskResource(task), nil
+}
+
+// replayParams 在原任务参数上应用覆盖；编码器/预设写入任务的编码覆盖，原任务的 profile=auto 决策保留
+func replayParams(base vo.TranscodeParams, req *cqe.ReplayTaskReq) (vo.TranscodeParams, error) {
+	resolution, bitrate := base.Resolution, base.Bitrate
+	if req.Resolution != "" {
+		resolution = req.Resolution
+	}
+	if req.Bitrate != "" {
+		bitrate = req.Bitrate
+	}
+	p, err := vo.NewTranscodeParams(resolution, bitrate)
+	if err != nil {
+		return vo.TranscodeParams{}, err
+	}
+	params := base
+	params.Resolution, params.Bitrate = p.Resolution, p.Bitrate
+	// 重放只用于排查视频画质，不重新生成纯音频产物
+	params.Audio = nil
+	if req.VideoCodec != "" || req.Preset != "" {
+		profile := vo.AutoProfile{Rule: vo.ReplayProfileRule}
+		if base.Profile != nil {
+			profile = *base.Profile
+		}
+		if req.VideoCodec != "" {
+			profile.VideoCodec = req.VideoCodec
+		}
+		if req.Preset != "" {
+			profile.Preset = req.Preset
+		}
+		params.Profile = &profile
+	}
+	return params, nil
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/publicurl"
+	"transcode-service/ddd/infrastructure/worker"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+)
+
+func (t *transcodeAppImpl) CreateThumbnail(ctx context.Context, req *cqe.CreateThumbnailReq) (*dto.ThumbnailDto, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	tasks, err := t.taskReader.QueryTranscodeJobsByVideo(ctx, req.VideoUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	task, source := thumbnailSource(tasks, req.Source)
+	if task == nil {
+		return nil, errno.ErrTranscodeTaskNotFound
+	}
+	sourceKey := task.OriginalPath()
+	if source == vo.ThumbnailSourceRendition {
+		sourceKey = task.OutputPath()
+	}
+	format, _ := vo.ParseImageFormat(req.Format)
+	at := time.Duration(req.TimestampMs) * time.Millisecond
+	spec := vo.ThumbnailSpec{
+		UserUUID:  task.UserUUID(),
+		VideoUUID: req.VideoUUID,
+		SourceKey: sourceKey,
+		At:        at,
+		Width:     req.Width,
+		Height:    req.Height,
+		Format:    format,
+		ObjectKey: vo.ThumbnailObjectKey(task.UserUUID(), req.VideoUUID, at, req.Width, req.Height, format),
+	}
+	if err := worker.ExtractThumbnail(ctx, spec); err != nil {
+		logger.Warnf("create thumbnail failed video_uuid=%s source=%s at=%s error=%v", req.VideoUUID, source, at, err)
+		return nil, errno.NewSimpleBizError(errno.ErrThumbnailFailed, err)
+	}
+	return dto.NewThumbnailDto(spec, publicurl.DefaultBuilder().Build(spec.ObjectKey), source), nil
+}
+
+// thumbnailSource 选出截帧使用的任务与来源：rendition 取最近完成的正式产物，source 取最新任务的源文件；
+// 未指定时优先产物，尚无完成产物时回退到源文件。tasks 按创建时间升序
+func thumbnailSource(tasks []*entity.TranscodeTaskEntity, want string) (*entity.TranscodeTaskEntity, string) {
+	var latest, rendition *entity.TranscodeTaskEntity
+	for i := len(tasks) - 1; i >= 0; i-- {
+		task := tasks[i]
+		if task == nil {
+			continue
+		}
+		if latest == nil {
+			latest = task
+		}
+		if rendition == nil && task.Status() == vo.TaskStatusCompleted && !task.GetParams().IsPreview() && task.OutputPath() != "" {
+			rendition = task
+		}
+	}
+	switch want {
+	case vo.ThumbnailSourceOriginal:
+		return latest, vo.ThumbnailSourceOriginal
+	case vo.ThumbnailSourceRendition:
+		return rendition, vo.ThumbnailSourceRendition
+	}
+	if rendition != nil {
+		return rendition, vo.ThumbnailSourceRendition
+	}
+	return latest, vo.ThumbnailSourceOriginal
+}
@@ -3,18 +3,30 @@ package app
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 	"transcode-service/ddd/application/cqe"
 	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/application/dto/types"
 	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/event"
+	"transcode-service/ddd/domain/gateway"
 	"transcode-service/ddd/domain/port"
 	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/service"
 	"transcode-service/ddd/domain/vo"
 	"transcode-service/ddd/infrastructure/database/persistence"
+	"transcode-service/ddd/infrastructure/ladder"
 	"transcode-service/ddd/infrastructure/queue"
+	"transcode-service/ddd/infrastructure/storage"
+	"transcode-service/ddd/infrastructure/worker"
 	"transcode-service/pkg/assert"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
 	"transcode-service/pkg/errno"
 	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
 )
 
 var (
@@ -25,40 +37,99 @@ var (
 type TranscodeApp interface {
 	// CreateTranscodeTask 创建转码任务
 	CreateTranscodeTask(ctx context.Context, req *cqe.TranscodeTaskCqe) (*dto.TranscodeTaskDTO, error)
+	// ValidateTranscodeTask 预演建任务：校验、解析编码配置与阶梯并估算，不落库不入队
+	ValidateTranscodeTask(ctx context.Context, req *cqe.ValidateTranscodeTaskReq) (*dto.TranscodeDryRunDto, error)
 	// GetTranscodeTask 获取转码任务详情
 	GetTranscodeTask(ctx context.Context, taskUUID string) (*dto.TranscodeTaskDTO, error)
+	// CreateTask 创建转码任务，返回 v2 任务资源
+	CreateTask(ctx context.Context, req *cqe.TranscodeTaskCqe) (*dto.TaskResource, error)
+	// GetTask 获取 v2 任务资源（含阶段进度与排队信息）
+	GetTask(ctx context.Context, taskUUID string) (*dto.TaskResource, error)
+	// ListTasks 分页获取 v2 任务资源，labelSelector 形如 "campaign=summer,source=mobile-app"，为空时不按标签筛选
+	ListTasks(ctx context.Context, userUUID, labelSelector string, page, size int) ([]*dto.TaskResource, int64, error)
+	// GetTranscodeTaskCommands 获取任务及其 HLS 作业实际执行的 ffmpeg 命令
+	GetTranscodeTaskCommands(ctx context.Context, taskUUID string) ([]dto.FFmpegCommandDto, error)
+	// RestoreTaskOutput 请求恢复已归档的产物，返回最新任务资源（output.storage.tier=restoring）
+	RestoreTaskOutput(ctx context.Context, taskUUID string) (*dto.TaskResource, error)
+	// GetTaskResourceTrace 获取任务编码期间的 ffmpeg 进程生命周期与资源采样
+	GetTaskResourceTrace(ctx context.Context, taskUUID string) (*vo.ResourceTrace, error)
+	// GetTaskOutputURLs 获取产物访问地址，私有产物每次返回新签名
+	GetTaskOutputURLs(ctx context.Context, taskUUID string) (*dto.OutputURLsDto, error)
+	// GetTaskOutputs 列出已完成任务的全部产物（MP4、纯音频、HLS master 与各码流播放列表）
+	GetTaskOutputs(ctx context.Context, taskUUID string) ([]dto.TaskOutputDto, error)
 	// ListTranscodeTasks 获取转码任务列表
 	ListTranscodeTasks(ctx context.Context, userUUID string, page, size int) ([]*dto.TranscodeTaskDTO, int64, error)
 	// UpdateTranscodeTaskStatus 更新转码任务状态
 	UpdateTranscodeTaskStatus(ctx context.Context, taskUUID, status, errorMessage string) error
 	// CancelTranscodeTask 取消转码任务
 	CancelTranscodeTask(ctx context.Context, taskUUID string) error
+	// BoostTaskPriority 调整排队中任务的优先级，返回最新任务资源
+	BoostTaskPriority(ctx context.Context, req *cqe.BoostTaskPriorityReq) (*dto.TaskResource, error)
+	// SubmitJob 按作业类型解码原始负载并提交到对应队列（内置 transcode/hls 或插件类型）
+	SubmitJob(ctx context.Context, jobType string, payload []byte) (*dto.JobSubmissionDto, error)
 	// GetTranscodeProgress 获取转码进度
 	GetTranscodeProgress(ctx context.Context, taskUUID string) (float64, error)
+	// GetVideoProcessing 获取视频维度的聚合处理状态
+	GetVideoProcessing(ctx context.Context, videoUUID string) (*dto.VideoProcessingDto, error)
+	// GetTaskStatusesByVideoUUIDs 批量返回每个视频最新任务的精简状态，按请求顺序
+	GetTaskStatusesByVideoUUIDs(ctx context.Context, req *cqe.BatchTaskStatusReq) ([]dto.TaskStatusRecord, error)
+	// ReplayTask 以覆盖参数克隆已结束任务并重新排队，按原任务用户限流
+	ReplayTask(ctx context.Context, req *cqe.ReplayTaskReq) (*dto.TaskResource, error)
+	// AddTaskNote 给任务追加一条运维备注（作者+时间）
+	AddTaskNote(ctx context.Context, req *cqe.AddTaskNoteReq) (*dto.TaskNoteDto, error)
+	// ListTaskNotes 按时间升序返回任务备注
+	ListTaskNotes(ctx context.Context, taskUUID string) ([]dto.TaskNoteDto, error)
+	// CreateThumbnail 按时间戳从源文件或最近产物截取一帧并上传，返回图片地址
+	CreateThumbnail(ctx context.Context, req *cqe.CreateThumbnailReq) (*dto.ThumbnailDto, error)
+	// CreateTaskGroup 创建任务组，组内任务全部结束后投递一次完成回调
+	CreateTaskGroup(ctx context.Context, req *cqe.CreateTaskGroupReq) (*dto.TaskGroupDto, error)
+	// GetTaskGroup 任务组聚合进度与组内任务状态
+	GetTaskGroup(ctx context.Context, groupUUID string) (*dto.TaskGroupDto, error)
+	// AttachTaskGroupTasks 把已有任务加入任务组
+	AttachTaskGroupTasks(ctx context.Context, req *cqe.AttachTaskGroupTasksReq) (*dto.TaskGroupDto, error)
+	// CreateTaskGroupTasks 在任务组下批量创建任务，单项失败不影响其他项
+	CreateTaskGroupTasks(ctx context.Context, req *cqe.CreateTaskGroupTasksReq) (*dto.TaskGroupTasksDto, error)
 }
 
 type transcodeAppImpl struct {
 	transcodeRepo repo.TranscodeJobRepository
+	taskReader    repo.TranscodeJobRepository // 只读查询使用，可能带短 TTL 缓存
+	hlsRepo       repo.HLSJobRepository
+	prefRepo      repo.UserPreferenceRepository
+	noteRepo      repo.TaskNoteRepository  // 为空时不支持任务备注
+	groupRepo     repo.TaskGroupRepository // 为空时不支持任务组
+	videoSvc      service.VideoProcessingService
 	taskQueue     queue.TaskQueue
 	progressSink  port.ProgressSink
+	storage       gateway.StorageGateway // 为空时产物大小返回 0
 	maxRetries    int
 }
 
 func DefaultTranscodeApp() TranscodeApp {
 	assert.NotCircular()
 	onceTranscodeApp.Do(func() {
-		singleTranscodeApp = NewTranscodeAppWith(persistence.NewTranscodeRepository(), queue.DefaultTaskQueue(), nil, 3)
+		impl := NewTranscodeAppWith(persistence.NewTranscodeRepository(), persistence.NewHLSRepository(), persistence.NewUserPreferenceRepository(), queue.DefaultTaskQueue(), nil, 3).(*transcodeAppImpl)
+		// 详情/进度轮询走读缓存，状态变更等写路径仍读取最新数据
+		impl.taskReader = persistence.NewCachedTranscodeRepository()
+		impl.noteRepo = persistence.NewTaskNoteRepository()
+		impl.groupRepo = persistence.NewTaskGroupRepository()
+		impl.storage = storage.DefaultStorageGateway()
+		singleTranscodeApp = impl
 	})
 	assert.NotNil(singleTranscodeApp)
 	return singleTranscodeApp
 }
 
-func NewTranscodeAppWith(repo repo.TranscodeJobRepository, q queue.TaskQueue, sink port.ProgressSink, maxRetries int) TranscodeApp {
+func NewTranscodeAppWith(repo repo.TranscodeJobRepository, hlsRepo repo.HLSJobRepository, prefRepo repo.UserPreferenceRepository, q queue.TaskQueue, sink port.ProgressSink, maxRetries int) TranscodeApp {
 	if maxRetries <= 0 {
 		maxRetries = 3
 	}
 	return &transcodeAppImpl{
 		transcodeRepo: repo,
+		taskReader:    repo,
+		hlsRepo:       hlsRepo,
+		prefRepo:      prefRepo,
+		videoSvc:      service.NewVideoProcessingService(repo, hlsRepo),
 		taskQueue:     q,
 		progressSink:  sink,
 		maxRetries:    maxRetries,
@@ -66,14 +137,48 @@ func NewTranscodeAppWith(repo repo.TranscodeJobRepository, q queue.TaskQueue, si
 }
 
 func (t *transcodeAppImpl) CreateTranscodeTask(ctx context.Context, req *cqe.TranscodeTaskCqe) (*dto.TranscodeTaskDTO, error) {
+	res, err := t.CreateTask(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return res.ToV1(), nil
+}
+
+func (t *transcodeAppImpl) CreateTask(ctx context.Context, req *cqe.TranscodeTaskCqe) (*dto.TaskResource, error) {
+	// profile=auto 时先按规则补齐，仍未指定的分辨率/码率再按用户偏好补齐
+	catalog := ladder.Default().LadderCatalog(ctx)
+	profile := applyAutoProfile(catalog, req)
+	_ = t.applyUserPreference(ctx, req)
+
 	// 验证请求参数
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
 
-	// 幂等：检查同一视频是否已有未完成任务
-	if existing, err := t.findActiveByVideo(ctx, req.VideoUUID); err == nil && existing != nil {
-		return dto.NewTranscodeTaskDto(existing), nil
+	// 同一幂等键重复提交（如再均衡后被其他实例重新消费）直接返回首次创建的任务
+	if existing := t.findByIdempotencyKey(ctx, req.IdempotencyKey); existing != nil {
+		metrics.Add("task_create_idempotent_hits_total", 1)
+		return dto.NewTaskResource(existing), nil
+	}
+
+	previewSeconds, err := resolvePreviewSeconds(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 幂等：检查同一视频是否已有未完成任务；预览任务与正式任务互不影响。
+	// 源文件已被替换（代数更新）时取消旧任务，按新源文件重新转码
+	generation := req.SourceGeneration
+	if existing, err := t.findActiveByVideo(ctx, req.VideoUUID, previewSeconds > 0); err == nil && existing != nil {
+		if !isNewerSource(req, existing) {
+			return dto.NewTaskResource(existing), nil
+		}
+		if generation == 0 {
+			generation = existing.SourceGeneration() + 1
+		}
+		t.supersedeTask(ctx, existing, generation)
+	} else if generation == 0 {
+		generation = t.inferSourceGeneration(ctx, req)
 	}
 
 	// 创建转码参数
@@ -81,52 +186,406 @@ func (t *transcodeAppImpl) CreateTranscodeTask(ctx context.Context, req *cqe.Tra
 	if err != nil {
 		return nil, errno.NewBizError(errno.ErrInvalidParam, err)
 	}
+	if err := applyFormatSet(catalog, req, params); err != nil {
+		return nil, err
+	}
+	params.PreviewSeconds = previewSeconds
+	params.VideoStream, params.AudioStream = req.VideoStreamIndex, req.AudioStreamIndex
+	params.Profile = profile
+	// 已在 Validate 中校验
+	params.Audio, _ = req.AudioOutputSpec()
 
 	// 创建转码任务实体（不再与 HLS 耦合）
 	task := entity.DefaultTranscodeTaskEntity(req.UserUUID, req.VideoUUID, req.VideoPushUUID, req.OriginalPath, *params)
+	// 标签已在 Validate 中校验
+	labels, _ := vo.NewTaskLabels(req.Labels)
+	task.SetLabels(labels)
+	task.AssignSourceGeneration(generation)
+	task.SetIdempotencyKey(req.IdempotencyKey)
+	if err := applyVisibility(task, req.Visibility); err != nil {
+		return nil, err
+	}
+	if err := applyOutputDestination(task, req.OutputBucket, req.OutputPrefix); err != nil {
+		return nil, err
+	}
+	task.SetLane(classifyLane(req, previewSeconds))
+	groupUUID, err := t.resolveTaskGroup(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	task.SetGroupUUID(groupUUID)
 
 	// 保存到仓储
 	err = t.transcodeRepo.CreateTranscodeJob(ctx, task)
 	if err != nil {
+		// 并发提交同一幂等键时唯一索引冲突，返回先写入的任务
+		if existing := t.findByIdempotencyKey(ctx, req.IdempotencyKey); existing != nil {
+			metrics.Add("task_create_idempotent_hits_total", 1)
+			return dto.NewTaskResource(existing), nil
+		}
 		return nil, errno.NewBizError(errno.ErrDatabase, err)
 	}
+	event.RecordLabelMetrics("task_created_total", labels)
 
 	// 将任务加入队列，触发异步处理
 	if err := t.taskQueue.Enqueue(ctx, task); err != nil {
 		logger.Errorf("任务入队失败 task_uuid=%s error=%v", task.TaskUUID(), err)
 		failErr := fmt.Errorf("enqueue task failed: %w", err)
-		task.SetStatus(vo.TaskStatusFailed)
+		_ = task.TransitionTo(vo.TaskStatusFailed)
 		task.SetErrorMessage(failErr.Error())
-		_ = t.transcodeRepo.UpdateTranscodeJobStatus(ctx, task.TaskUUID(), vo.TaskStatusFailed, failErr.Error(), task.OutputPath(), task.Progress())
+		_ = t.transcodeRepo.SaveTranscodeJobStatus(ctx, task)
 		return nil, errno.ErrQueueFull
 	}
 
 	// 转换为DTO返回
-	return dto.NewTranscodeTaskDto(task), nil
+	return dto.NewTaskResource(task), nil
+}
+
+// findByIdempotencyKey 未携带幂等键或未找到时返回 nil
+func (t *transcodeAppImpl) findByIdempotencyKey(ctx context.Context, key string) *entity.TranscodeTaskEntity {
+	if key == "" {
+		return nil
+	}
+	existing, err := t.transcodeRepo.GetTranscodeJobByIdempotencyKey(ctx, key)
+	if err != nil {
+		return nil
+	}
+	logger.Infof("duplicate create request, returning existing task idempotency_key=%s task_uuid=%s", key, existing.TaskUUID())
+	return existing
+}
+
+// applyFormatSet 解析任务的输出档位集合并记录在参数上：集合名缺省为当前环境的 format_set，
+// 未指定封装时按集合中同名档位的 container 配置
+func applyFormatSet(catalog *gateway.LadderCatalog, req *cqe.TranscodeTaskCqe, params *vo.TranscodeParams) error {
+	var set config.FormatSetConfig
+	if catalog != nil {
+		var ok bool
+		if set, params.FormatSet, ok = catalog.ResolveFormatSet(req.FormatSet); !ok {
+			return errno.NewSimpleBizError(errno.ErrInvalidFormatSet, fmt.Errorf("format set %q is not configured", req.FormatSet))
+		}
+	}
+	params.MaxRenditions = req.MaxRenditions
+	container := req.Container
+	if container == "" {
+		for _, of := range set.OutputFormats {
+			if strings.EqualFold(strings.TrimSpace(of.Name), req.Resolution) {
+				container = of.Container
+				break
+			}
+		}
+	}
+	if err := params.WithContainer(container); err != nil {
+		return errno.NewBizError(errno.ErrInvalidParam, err)
+	}
+	gop, err := service.ProfileGOP(set, req.Resolution)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrInvalidFormatSet, err)
+	}
+	params.GOP = gop
+	return nil
+}
+
+// classifyLane 开启快车道时按上游探测的源时长分道，未携带 source.duration_seconds 的任务走 standard
+func classifyLane(req *cqe.TranscodeTaskCqe, previewSeconds int) vo.TaskLane {
+	cfg := config.GetGlobalConfig()
+	if cfg == nil || !cfg.Worker.ExpressLane.Enabled {
+		return vo.LaneStandard
+	}
+	var duration float64
+	if req.Source != nil {
+		duration = req.Source.DurationSeconds
+	}
+	lane := vo.ClassifyLane(duration, previewSeconds, cfg.Worker.ExpressLane.MaxDuration)
+	metrics.Add("task_lane_"+string(lane)+"_created_total", 1)
+	return lane
+}
+
+// resolvePreviewSeconds 非预览请求返回 0；未指定时长时使用配置默认值，超过上限报错
+func resolvePreviewSeconds(req *cqe.TranscodeTaskCqe) (int, error) {
+	if !req.Preview {
+		return 0, nil
+	}
+	defaultSeconds, maxSeconds := 10, 60
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		defaultSeconds, maxSeconds = cfg.Transcode.Preview.DefaultSeconds, cfg.Transcode.Preview.MaxSeconds
+	}
+	if req.PreviewSeconds == 0 {
+		return defaultSeconds, nil
+	}
+	if req.PreviewSeconds > maxSeconds {
+		return 0, errno.ErrInvalidPreview
+	}
+	return req.PreviewSeconds, nil
+}
+
+// applyAutoProfile profile=auto 时按源文件特征匹配规则，补齐缺省的分辨率/码率；返回记录在任务上的规则决策，
+// 未命中任何规则时记录为 none 并回退到用户偏好
+func applyAutoProfile(catalog *gateway.LadderCatalog, req *cqe.TranscodeTaskCqe) *vo.AutoProfile {
+	if req.Profile != service.ProfileAuto {
+		return nil
+	}
+	var hints vo.SourceHints
+	if req.Source != nil {
+		hints = *req.Source
+	}
+	decision, ok := service.SelectAutoProfile(catalog, hints)
+	if !ok {
+		logger.Warnf("no auto profile rule matched video_uuid=%s height=%d fps=%.2f duration=%.0fs popularity=%s", req.VideoUUID, hints.Height, hints.FPS, hints.DurationSeconds, hints.Popularity)
+		return &vo.AutoProfile{Rule: vo.AutoProfileNoMatch}
+	}
+	if req.Resolution == "" {
+		req.Resolution = decision.Resolution
+	}
+	if req.Bitrate == "" {
+		req.Bitrate = decision.Bitrate
+	}
+	logger.Infof("auto profile selected video_uuid=%s rule=%s resolution=%s bitrate=%s codec=%s renditions=%v", req.VideoUUID, decision.Profile.Rule, req.Resolution, req.Bitrate, decision.Profile.VideoCodec, decision.Profile.Renditions)
+	return &decision.Profile
+}
+
+// applyUserPreference 用偏好阶梯首档补齐缺省的分辨率/码率，返回是否应用；查询失败不影响建任务
+func (t *transcodeAppImpl) applyUserPreference(ctx context.Context, req *cqe.TranscodeTaskCqe) bool {
+	if t.prefRepo == nil || req.UserUUID == "" || (req.Resolution != "" && req.Bitrate != "") {
+		return false
+	}
+	pref, err := t.prefRepo.GetUserPreference(ctx, req.UserUUID)
+	if err != nil {
+		logger.Warnf("load user preference failed user_uuid=%s error=%v", req.UserUUID, err)
+		return false
+	}
+	if pref == nil {
+		return false
+	}
+	primary, ok := pref.PrimaryRendition()
+	if !ok {
+		return false
+	}
+	if req.Resolution == "" {
+		req.Resolution = primary.Resolution
+	}
+	if req.Bitrate == "" {
+		req.Bitrate = primary.Bitrate
+	}
+	logger.Infof("applied user preference user_uuid=%s video_uuid=%s resolution=%s bitrate=%s", req.UserUUID, req.VideoUUID, req.Resolution, req.Bitrate)
+	return true
 }
 
 func (t *transcodeAppImpl) GetTranscodeTask(ctx context.Context, taskUUID string) (*dto.TranscodeTaskDTO, error) {
+	res, err := t.GetTask(ctx, taskUUID)
+	if err != nil {
+		return nil, err
+	}
+	return res.ToV1(), nil
+}
+
+func (t *transcodeAppImpl) GetTask(ctx context.Context, taskUUID string) (*dto.TaskResource, error) {
+	if taskUUID == "" {
+		return nil, errno.ErrTaskUUIDRequired
+	}
+	taskEntity, err := t.taskReader.GetTranscodeJob(ctx, taskUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if taskEntity == nil {
+		return nil, errno.ErrTranscodeTaskNotFound
+	}
+	res := dto.NewTaskResource(taskEntity)
+	t.fillStageProgress(ctx, taskEntity, res)
+	if taskEntity.IsPending() {
+		t.fillQueueInfo(ctx, taskEntity, res)
+	}
+	t.fillNotes(ctx, res)
+	return res, nil
+}
+
+func (t *transcodeAppImpl) RestoreTaskOutput(ctx context.Context, taskUUID string) (*dto.TaskResource, error) {
+	if taskUUID == "" {
+		return nil, errno.ErrTaskUUIDRequired
+	}
+	task, err := t.transcodeRepo.GetTranscodeJob(ctx, taskUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if task == nil {
+		return nil, errno.ErrTranscodeTaskNotFound
+	}
+	storage := task.OutputStorage()
+	switch storage.Tier {
+	case vo.StorageTierRestoring:
+		// 重复请求直接返回当前状态
+		return dto.NewTaskResource(task), nil
+	case vo.StorageTierArchived:
+	default:
+		return nil, errno.ErrOutputNotArchived
+	}
+	now := clock.Now()
+	storage.Tier, storage.RestoreRequestedAt = vo.StorageTierRestoring, &now
+	ok, err := t.transcodeRepo.SwapTranscodeOutputStorage(ctx, taskUUID, []vo.StorageTier{vo.StorageTierArchived}, storage)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if !ok {
+		return nil, errno.ErrOutputNotArchived
+	}
+	metrics.Add("outputs_restore_requested_total", 1)
+	logger.Infof("output restore requested task_uuid=%s archive_path=%s", taskUUID, storage.ArchivePath)
+	task.SetOutputStorage(storage)
+	return dto.NewTaskResource(task), nil
+}
+
+func (t *transcodeAppImpl) GetTaskResourceTrace(ctx context.Context, taskUUID string) (*vo.ResourceTrace, error) {
 	if taskUUID == "" {
 		return nil, errno.ErrTaskUUIDRequired
 	}
-	taskEntity, err := t.transcodeRepo.GetTranscodeJob(ctx, taskUUID)
+	taskEntity, err := t.taskReader.GetTranscodeJob(ctx, taskUUID)
 	if err != nil {
 		return nil, errno.NewBizError(errno.ErrDatabase, err)
 	}
 	if taskEntity == nil {
 		return nil, errno.ErrTranscodeTaskNotFound
 	}
-	return dto.NewTranscodeTaskDto(taskEntity), nil
+	if taskEntity.ResourceTrace() == nil {
+		return nil, errno.ErrResourceTraceNotFound
+	}
+	return taskEntity.ResourceTrace(), nil
+}
+
+func (t *transcodeAppImpl) GetTranscodeTaskCommands(ctx context.Context, taskUUID string) ([]dto.FFmpegCommandDto, error) {
+	if taskUUID == "" {
+		return nil, errno.ErrTaskUUIDRequired
+	}
+	taskEntity, err := t.taskReader.GetTranscodeJob(ctx, taskUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if taskEntity == nil {
+		return nil, errno.ErrTranscodeTaskNotFound
+	}
+	cmds := dto.NewFFmpegCommandDtos("transcode", taskEntity.TaskUUID(), taskEntity.Commands())
+	if t.hlsRepo != nil {
+		hlsJob, err := t.hlsRepo.GetHLSJobBySource(ctx, taskEntity.TaskUUID())
+		if err != nil {
+			logger.Warnf("get hls job by source failed task_uuid=%s error=%v", taskEntity.TaskUUID(), err)
+		} else if hlsJob != nil {
+			cmds = append(cmds, dto.NewFFmpegCommandDtos("hls", hlsJob.JobUUID(), hlsJob.Commands())...)
+		}
+	}
+	return cmds, nil
+}
+
+// fillStageProgress 合成视频整体进度：转码任务的三个阶段 + 关联 HLS 作业
+func (t *transcodeAppImpl) fillStageProgress(ctx context.Context, task *entity.TranscodeTaskEntity, res *dto.TaskResource) {
+	var hlsJob *entity.HLSJobEntity
+	if t.hlsRepo != nil && task.IsCompleted() {
+		job, err := t.hlsRepo.GetHLSJobBySource(ctx, task.TaskUUID())
+		if err != nil {
+			logger.Warnf("get hls job by source failed task_uuid=%s error=%v", task.TaskUUID(), err)
+		}
+		hlsJob = job
+	}
+	applyVideoState(task, hlsJob, res)
+}
+
+// fillListVideoState 列表按页批量查询关联 HLS 作业，逐项合成整体进度与端到端状态
+func (t *transcodeAppImpl) fillListVideoState(ctx context.Context, tasks []*entity.TranscodeTaskEntity, list []*dto.TaskResource) {
+	var hlsJobs map[string]*entity.HLSJobEntity
+	if t.hlsRepo != nil {
+		sources := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			if task.IsCompleted() {
+				sources = append(sources, task.TaskUUID())
+			}
+		}
+		if len(sources) > 0 {
+			jobs, err := t.hlsRepo.GetHLSJobsBySources(ctx, sources)
+			if err != nil {
+				logger.Warnf("get hls jobs by sources failed count=%d error=%v", len(sources), err)
+			}
+			hlsJobs = jobs
+		}
+	}
+	for i, task := range tasks {
+		var hlsJob *entity.HLSJobEntity
+		if task.IsCompleted() {
+			hlsJob = hlsJobs[task.TaskUUID()]
+		}
+		applyVideoState(task, hlsJob, list[i])
+	}
+}
+
+// applyVideoState 按转码阶段与 HLS 作业（可为空）填充阶段进度、整体进度与端到端状态
+func applyVideoState(task *entity.TranscodeTaskEntity, hlsJob *entity.HLSJobEntity, res *dto.TaskResource) {
+	stages := task.StageProgress()
+	if task.IsCompleted() {
+		// 兼容没有阶段记录的历史任务
+		for _, stage := range vo.TranscodeStages {
+			stages.Set(stage, 100)
+		}
+	}
+	if hlsJob != nil {
+		hlsProgress := hlsJob.Progress()
+		if hlsJob.Status() == vo.HLSStatusCompleted.String() {
+			hlsProgress = 100
+		}
+		stages.Set(vo.StageHLS, hlsProgress)
+		res.ApplyHLSJob(hlsJob)
+	}
+	res.Stages = dto.NewStageProgressDtos(stages)
+	res.VideoProgress = stages.Composite()
+}
+
+// fillQueueInfo 根据 DB 中排在前面的 pending 任务数计算排队位置和预计开始时间
+func (t *transcodeAppImpl) fillQueueInfo(ctx context.Context, task *entity.TranscodeTaskEntity, res *dto.TaskResource) {
+	ahead, err := t.transcodeRepo.CountPendingTranscodeJobsAhead(ctx, task.Lane(), task.Priority(), task.CreatedAt())
+	if err != nil {
+		logger.Warnf("count pending tasks failed task_uuid=%s error=%v", task.TaskUUID(), err)
+		return
+	}
+	workers := 1
+	avgDuration := 5 * time.Minute
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		if cfg.Worker.MaxConcurrentTasks > 0 {
+			workers = cfg.Worker.MaxConcurrentTasks
+		}
+		if task.Lane() == vo.LaneExpress && cfg.Worker.ExpressLane.Enabled {
+			// 快车道任务还可由专用协程执行
+			workers += cfg.Worker.ExpressLane.ReservedSlots
+		}
+		if cfg.Worker.AvgTaskDuration > 0 {
+			avgDuration = cfg.Worker.AvgTaskDuration
+		}
+	}
+	// 前面的任务按 worker 数分批执行，每批耗时按平均时长估算
+	rounds := int(ahead) / workers
+	// 截断到分钟，避免轮询时 ETag 因估算时间每次变化而失效
+	startAt := clock.Now().Add(time.Duration(rounds) * avgDuration).Truncate(time.Minute)
+	res.Queue = &dto.TaskQueueResource{Position: int(ahead) + 1, Priority: task.Priority(), EstimatedStartAt: types.NewTimeOrNil(startAt)}
 }
 
 func (t *transcodeAppImpl) ListTranscodeTasks(ctx context.Context, userUUID string, page, size int) ([]*dto.TranscodeTaskDTO, int64, error) {
+	list, total, err := t.ListTasks(ctx, userUUID, "", page, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	dtos := make([]*dto.TranscodeTaskDTO, 0, len(list))
+	for _, r := range list {
+		dtos = append(dtos, r.ToV1())
+	}
+	return dtos, total, nil
+}
+
+func (t *transcodeAppImpl) ListTasks(ctx context.Context, userUUID, labelSelector string, page, size int) ([]*dto.TaskResource, int64, error) {
+	selector, err := vo.ParseLabelSelector(labelSelector)
+	if err != nil {
+		return nil, 0, errno.NewSimpleBizError(errno.ErrInvalidLabels, err)
+	}
 	if page <= 0 {
 		page = 1
 	}
 	if size <= 0 || size > 100 {
 		size = 10
 	}
-	statuses := []vo.TaskStatus{vo.TaskStatusProcessing, vo.TaskStatusPending, vo.TaskStatusCompleted, vo.TaskStatusFailed, vo.TaskStatusCancelled}
+	statuses := []vo.TaskStatus{vo.TaskStatusProcessing, vo.TaskStatusPending, vo.TaskStatusCompleted, vo.TaskStatusFailed, vo.TaskStatusCancelled, vo.TaskStatusExpired, vo.TaskStatusRetrying, vo.TaskStatusRejected}
 	var all []*entity.TranscodeTaskEntity
 	for _, st := range statuses {
 		list, err := t.transcodeRepo.QueryTranscodeJobsByStatus(ctx, st, size*page)
@@ -140,24 +599,29 @@ func (t *transcodeAppImpl) ListTranscodeTasks(ctx context.Context, userUUID stri
 			if userUUID != "" && job.UserUUID() != userUUID {
 				continue
 			}
+			if !job.Labels().Matches(selector) {
+				continue
+			}
 			all = append(all, job)
 		}
 	}
 	total := int64(len(all))
 	start := (page - 1) * size
 	if start > len(all) {
-		return []*dto.TranscodeTaskDTO{}, total, nil
+		return []*dto.TaskResource{}, total, nil
 	}
 	end := start + size
 	if end > len(all) {
 		end = len(all)
 	}
 	slice := all[start:end]
-	dtos := make([]*dto.TranscodeTaskDTO, 0, len(slice))
+	list := make([]*dto.TaskResource, 0, len(slice))
 	for _, e := range slice {
-		dtos = append(dtos, dto.NewTranscodeTaskDto(e))
+		list = append(list, dto.NewTaskResource(e))
 	}
-	return dtos, total, nil
+	// 每项附带关联 HLS 作业摘要，转码完成但 HLS 仍在打包时 video_status 为 packaging
+	t.fillListVideoState(ctx, slice, list)
+	return list, total, nil
 }
 
 func (t *transcodeAppImpl) UpdateTranscodeTaskStatus(ctx context.Context, taskUUID, status, errorMessage string) error {
@@ -175,18 +639,82 @@ func (t *transcodeAppImpl) UpdateTranscodeTaskStatus(ctx context.Context, taskUU
 	if task == nil {
 		return errno.ErrTranscodeTaskNotFound
 	}
-	if !task.Status().CanTransitionTo(target) {
+	if err := task.TransitionTo(target); err != nil {
 		return errno.ErrInvalidTaskStatus
 	}
-	return t.transcodeRepo.UpdateTranscodeJobStatus(ctx, taskUUID, target, errorMessage, task.OutputPath(), task.Progress())
+	task.SetErrorMessage(errorMessage)
+	return t.transcodeRepo.SaveTranscodeJobStatus(ctx, task)
 }
 
 func (t *transcodeAppImpl) CancelTranscodeTask(ctx context.Context, taskUUID string) error {
 	return t.UpdateTranscodeTaskStatus(ctx, taskUUID, vo.TaskStatusCancelled.String(), "cancelled by user")
 }
 
+func (t *transcodeAppImpl) BoostTaskPriority(ctx context.Context, req *cqe.BoostTaskPriorityReq) (*dto.TaskResource, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	task, err := t.transcodeRepo.GetTranscodeJob(ctx, req.TaskUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if task == nil {
+		return nil, errno.ErrTranscodeTaskNotFound
+	}
+	if task.Status() != vo.TaskStatusPending {
+		return nil, errno.ErrTaskNotPending
+	}
+	if task.Priority() != req.Priority {
+		ok, err := t.transcodeRepo.UpdateTranscodeJobPriority(ctx, req.TaskUUID, req.Priority)
+		if err != nil {
+			return nil, errno.NewBizError(errno.ErrDatabase, err)
+		}
+		if !ok {
+			// 查询与更新之间任务已被领取
+			return nil, errno.ErrTaskNotPending
+		}
+		// 任务可能排在其他实例的内存队列中，此时仅 DB 生效，重新入队（快照恢复/重试）时按新优先级排序
+		requeued := false
+		if r, ok := t.taskQueue.(queue.Reprioritizer); ok {
+			requeued = r.Reprioritize(req.TaskUUID, req.Priority)
+		}
+		logger.Infof("task priority changed task_uuid=%s from=%d to=%d reason=%s local_queue=%t", req.TaskUUID, task.Priority(), req.Priority, req.Reason, requeued)
+		task.SetPriority(req.Priority)
+	}
+	res := dto.NewTaskResource(task)
+	t.fillQueueInfo(ctx, task, res)
+	return res, nil
+}
+
+func (t *transcodeAppImpl) SubmitJob(ctx context.Context, jobType string, payload []byte) (*dto.JobSubmissionDto, error) {
+	if _, ok := worker.LookupJobHandler(jobType); !ok {
+		return nil, errno.NewSimpleBizError(errno.ErrInvalidParam, fmt.Errorf("unknown job type %q, registered: %v", jobType, worker.RegisteredJobTypes()))
+	}
+	job, err := worker.SubmitJob(ctx, jobType, payload)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInvalidParam, err)
+	}
+	logger.Infof("job submitted type=%s job_id=%s", job.Type, job.ID)
+	return &dto.JobSubmissionDto{JobType: job.Type, JobID: job.ID}, nil
+}
+
+func (t *transcodeAppImpl) GetTaskStatusesByVideoUUIDs(ctx context.Context, req *cqe.BatchTaskStatusReq) ([]dto.TaskStatusRecord, error) {
+	if err := req.Normalize(); err != nil {
+		return nil, err
+	}
+	latest, err := t.transcodeRepo.QueryLatestTranscodeJobsByVideos(ctx, req.VideoUUIDs)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	records := make([]dto.TaskStatusRecord, 0, len(req.VideoUUIDs))
+	for _, videoUUID := range req.VideoUUIDs {
+		records = append(records, dto.NewTaskStatusRecord(videoUUID, latest[videoUUID]))
+	}
+	return records, nil
+}
+
 func (t *transcodeAppImpl) GetTranscodeProgress(ctx context.Context, taskUUID string) (float64, error) {
-	task, err := t.transcodeRepo.GetTranscodeJob(ctx, taskUUID)
+	task, err := t.taskReader.GetTranscodeJob(ctx, taskUUID)
 	if err != nil {
 		return 0, errno.NewBizError(errno.ErrDatabase, err)
 	}
@@ -196,19 +724,81 @@ func (t *transcodeAppImpl) GetTranscodeProgress(ctx context.Context, taskUUID st
 	return float64(task.Progress()), nil
 }
 
-// findActiveByVideo returns a pending/processing task for the same video if exists.
-func (t *transcodeAppImpl) findActiveByVideo(ctx context.Context, videoUUID string) (*entity.TranscodeTaskEntity, error) {
+func (t *transcodeAppImpl) GetVideoProcessing(ctx context.Context, videoUUID string) (*dto.VideoProcessingDto, error) {
+	if videoUUID == "" {
+		return nil, errno.ErrVideoUUIDRequired
+	}
+	agg, err := t.videoSvc.Load(ctx, videoUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if len(agg.Children()) == 0 {
+		return nil, errno.ErrVideoProcessingNotFound
+	}
+	return dto.NewVideoProcessingDto(agg), nil
+}
+
+// findActiveByVideo returns a pending/processing task for the same video and preview mode if exists.
+// isNewerSource 请求是否对应比在途任务更新的源文件：显式代数更大，或未带代数但源路径已变化
+func isNewerSource(req *cqe.TranscodeTaskCqe, existing *entity.TranscodeTaskEntity) bool {
+	if req.SourceGeneration > 0 {
+		return req.SourceGeneration > existing.SourceGeneration()
+	}
+	return req.OriginalPath != existing.OriginalPath()
+}
+
+// inferSourceGeneration 未带代数时按该视频最近一次任务推断：源路径相同沿用，变化则递增，首个任务为 1
+func (t *transcodeAppImpl) inferSourceGeneration(ctx context.Context, req *cqe.TranscodeTaskCqe) int64 {
+	latest, err := t.transcodeRepo.QueryLatestTranscodeJobsByVideos(ctx, []string{req.VideoUUID})
+	if err != nil {
+		logger.Warnf("query latest task for source generation failed video_uuid=%s error=%v", req.VideoUUID, err)
+		return 1
+	}
+	prev := latest[req.VideoUUID]
+	if prev == nil {
+		return 1
+	}
+	gen := prev.SourceGeneration()
+	if gen <= 0 {
+		gen = 1
+	}
+	if prev.OriginalPath() != req.OriginalPath {
+		gen++
+	}
+	return gen
+}
+
+// supersedeTask 取消被新代数源文件取代的在途任务；处理中的任务由执行侧轮询到取消状态后中止 ffmpeg
+func (t *transcodeAppImpl) supersedeTask(ctx context.Context, existing *entity.TranscodeTaskEntity, generation int64) {
+	if err := existing.TransitionTo(vo.TaskStatusCancelled); err != nil {
+		logger.Warnf("supersede task skipped task_uuid=%s status=%s error=%v", existing.TaskUUID(), existing.Status().String(), err)
+		return
+	}
+	existing.SetErrorMessage(fmt.Sprintf("superseded by source generation %d", generation))
+	if err := t.transcodeRepo.SaveTranscodeJobStatus(ctx, existing); err != nil {
+		logger.Errorf("supersede task failed task_uuid=%s error=%v", existing.TaskUUID(), err)
+		return
+	}
+	// 同一对象 key 被覆盖上传时，丢弃旧内容的本地缓存
+	if cache := storage.DefaultSourceCache(); cache != nil {
+		cache.Invalidate(existing.OriginalPath())
+	}
+	metrics.Add("transcode_tasks_superseded_total", 1)
+	logger.Infof("task superseded task_uuid=%s video_uuid=%s old_generation=%d new_generation=%d", existing.TaskUUID(), existing.VideoUUID(), existing.SourceGeneration(), generation)
+}
+
+func (t *transcodeAppImpl) findActiveByVideo(ctx context.Context, videoUUID string, preview bool) (*entity.TranscodeTaskEntity, error) {
 	if videoUUID == "" {
 		return nil, nil
 	}
-	statuses := []vo.TaskStatus{vo.TaskStatusPending, vo.TaskStatusProcessing}
+	statuses := []vo.TaskStatus{vo.TaskStatusPending, vo.TaskStatusProcessing, vo.TaskStatusRetrying}
 	for _, st := range statuses {
 		jobs, err := t.transcodeRepo.QueryTranscodeJobsByStatus(ctx, st, 100)
 		if err != nil {
 			continue
 		}
 		for _, job := range jobs {
-			if job != nil && job.VideoUUID() == videoUUID {
+			if job != nil && job.VideoUUID() == videoUUID && job.GetParams().IsPreview() == preview && !job.IsReplay() {
 				return job, nil
 			}
 		}
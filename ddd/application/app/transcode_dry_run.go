@@ -0,0 +1,138 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/service"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/executor"
+	"transcode-service/ddd/infrastructure/ladder"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/hlsname"
+	"transcode-service/pkg/utils"
+)
+
+// dryRunDefaultDuration 未提供源时长时的估算基准（秒）
+const dryRunDefaultDuration = 60
+
+func (t *transcodeAppImpl) ValidateTranscodeTask(ctx context.Context, req *cqe.ValidateTranscodeTaskReq) (*dto.TranscodeDryRunDto, error) {
+	createReq := &req.CreateTranscodeTaskReq
+	catalog := ladder.Default().LadderCatalog(ctx)
+	profile := applyAutoProfile(catalog, createReq)
+	applied := t.applyUserPreference(ctx, createReq)
+	if err := createReq.Validate(); err != nil {
+		return nil, err
+	}
+	params, err := vo.NewTranscodeParams(createReq.Resolution, createReq.Bitrate)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrInvalidParam, err)
+	}
+	if err := applyFormatSet(catalog, createReq, params); err != nil {
+		return nil, err
+	}
+	params.VideoStream, params.AudioStream = createReq.VideoStreamIndex, createReq.AudioStreamIndex
+	params.Profile = profile
+	if params.PreviewSeconds, err = resolvePreviewSeconds(createReq); err != nil {
+		return nil, err
+	}
+	cfg := config.GetGlobalConfig()
+	if cfg == nil {
+		return nil, errno.ErrInternalServer
+	}
+
+	res := &dto.TranscodeDryRunDto{
+		UserUUID:          createReq.UserUUID,
+		VideoUUID:         createReq.VideoUUID,
+		Resolution:        params.Resolution,
+		Bitrate:           params.Bitrate,
+		PreferenceApplied: applied,
+	}
+	if existing, err := t.findActiveByVideo(ctx, createReq.VideoUUID, params.IsPreview()); err == nil && existing != nil {
+		res.ExistingTaskUUID = existing.TaskUUID()
+		res.Notes = append(res.Notes, "video already has an unfinished task; a real request would return it instead of creating a new one")
+	}
+
+	ff := cfg.Transcode.FFmpeg
+	ffExec := executor.NewFFmpegExecutor(cfg, nil)
+	res.Profile = dto.DryRunProfileDto{
+		Binary:            ffExec.Binary(),
+		VideoCodec:        firstNonEmpty(ff.VideoCodec, "libx264"),
+		VideoPreset:       firstNonEmpty(ff.VideoPreset, "medium"),
+		HardwareAccel:     ff.HardwareAccel,
+		UseHardwareDecode: ff.UseHardwareDecode,
+		Threads:           ff.Threads,
+	}
+	if profile != nil {
+		res.Profile.AutoRule = profile.Rule
+		res.Profile.VideoCodec = firstNonEmpty(profile.VideoCodec, res.Profile.VideoCodec)
+		res.Profile.VideoPreset = firstNonEmpty(profile.Preset, res.Profile.VideoPreset)
+	}
+
+	// 与执行器一致的工作目录布局，输入编码未知时不指定硬件解码器
+	task := entity.DefaultTranscodeTaskEntity(createReq.UserUUID, createReq.VideoUUID, createReq.VideoPushUUID, createReq.OriginalPath, *params)
+	inputPath := path.Join("input", utils.LocalFileName(task.OriginalPath()))
+	outputPath := path.Join("output", utils.LocalFileName(task.OutputPath()))
+	res.Output = dto.DryRunOutputDto{ObjectKey: task.OutputPath(), ContentType: params.OutputContainer().ContentType(), SkipUpload: cfg.Transcode.SkipFullUpload && !params.IsPreview()}
+	res.Renditions = append(res.Renditions, dto.DryRunRenditionDto{
+		Kind:       "mp4",
+		Resolution: params.Resolution,
+		Bitrate:    params.Bitrate,
+		FFmpegArgs: ffExec.BuildArgs(*params, "", inputPath, outputPath),
+	})
+	if ff.UseHardwareDecode {
+		res.Notes = append(res.Notes, "input codec is probed at encode time; hardware decoder selection is omitted here")
+	}
+
+	hlsLadder, source := service.ResolveTaskLadder(ctx, catalog, t.prefRepo, createReq.UserUUID, *params)
+	res.LadderSource = source
+	hlsInput := task.OutputPath()
+	if cfg.Transcode.SkipFullUpload {
+		hlsInput = task.OriginalPath()
+	}
+	if params.IsPreview() {
+		// 预览任务不生成 HLS
+		hlsLadder = nil
+		res.Notes = append(res.Notes, fmt.Sprintf("preview: only the first %ds are encoded; HLS is skipped", params.PreviewSeconds))
+	} else if hlsCfg, err := vo.NewHLSConfig(true, hlsLadder); err == nil {
+		for _, r := range hlsLadder {
+			// 作业尚未创建，文件名中的 {job} 原样保留
+			args, playlist, _ := service.BuildHLSRenditionArgs(cfg, *hlsCfg, hlsInput, "hls", r, hlsname.TokenJob)
+			res.Renditions = append(res.Renditions, dto.DryRunRenditionDto{
+				Kind:       "hls",
+				Resolution: r.Resolution,
+				Bitrate:    r.Bitrate,
+				Playlist:   playlist,
+				FFmpegArgs: args,
+			})
+		}
+	} else {
+		res.Notes = append(res.Notes, "HLS ladder invalid: "+err.Error())
+	}
+
+	duration := req.SourceDurationSeconds
+	if duration <= 0 {
+		duration = dryRunDefaultDuration
+		res.Notes = append(res.Notes, "source_duration_seconds not provided; estimate assumes 60s of source")
+	}
+	if params.IsPreview() && duration > float64(params.PreviewSeconds) {
+		duration = float64(params.PreviewSeconds)
+	}
+	renditions := append([]vo.ResolutionConfig{{Resolution: params.Resolution, Bitrate: params.Bitrate}}, hlsLadder...)
+	res.Estimate = service.EstimateEncode(cfg, renditions, duration)
+	return res, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/persistence"
+	"transcode-service/pkg/assert"
+	"transcode-service/pkg/errno"
+)
+
+var (
+	singleUserPreferenceApp UserPreferenceApp
+	onceUserPreferenceApp   sync.Once
+)
+
+type UserPreferenceApp interface {
+	// GetUserPreference 获取用户默认转码偏好
+	GetUserPreference(ctx context.Context, userUUID string) (*dto.UserPreferenceDto, error)
+	// SaveUserPreference 新建或覆盖用户默认转码偏好
+	SaveUserPreference(ctx context.Context, req *cqe.SaveUserPreferenceReq) (*dto.UserPreferenceDto, error)
+	// DeleteUserPreference 删除用户默认转码偏好
+	DeleteUserPreference(ctx context.Context, userUUID string) error
+}
+
+type userPreferenceAppImpl struct {
+	prefRepo repo.UserPreferenceRepository
+}
+
+func DefaultUserPreferenceApp() UserPreferenceApp {
+	assert.NotCircular()
+	onceUserPreferenceApp.Do(func() {
+		singleUserPreferenceApp = &userPreferenceAppImpl{prefRepo: persistence.NewUserPreferenceRepository()}
+	})
+	assert.NotNil(singleUserPreferenceApp)
+	return singleUserPreferenceApp
+}
+
+func (a *userPreferenceAppImpl) GetUserPreference(ctx context.Context, userUUID string) (*dto.UserPreferenceDto, error) {
+	if strings.TrimSpace(userUUID) == "" {
+		return nil, errno.ErrUserUUIDRequired
+	}
+	pref, err := a.prefRepo.GetUserPreference(ctx, userUUID)
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if pref == nil {
+		return nil, errno.ErrPreferenceNotFound
+	}
+	return dto.NewUserPreferenceDto(pref), nil
+}
+
+func (a *userPreferenceAppImpl) SaveUserPreference(ctx context.Context, req *cqe.SaveUserPreferenceReq) (*dto.UserPreferenceDto, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	ladder := make([]vo.ResolutionConfig, 0, len(req.Ladder))
+	for _, r := range req.Ladder {
+		ladder = append(ladder, vo.ResolutionConfig{Resolution: strings.TrimSpace(r.Resolution), Bitrate: strings.TrimSpace(r.Bitrate)})
+	}
+	var watermark *vo.Watermark
+	if req.Watermark != nil {
+		watermark = &vo.Watermark{ObjectKey: strings.TrimSpace(req.Watermark.ObjectKey), Position: strings.TrimSpace(req.Watermark.Position)}
+	}
+	pref, err := entity.NewUserPreferenceEntity(req.UserUUID, ladder, watermark, strings.TrimSpace(req.SubtitleLanguage))
+	if err != nil {
+		return nil, errno.NewBizError(errno.ErrInvalidParam, err)
+	}
+	if err := a.prefRepo.SaveUserPreference(ctx, pref); err != nil {
+		return nil, errno.NewBizError(errno.ErrDatabase, err)
+	}
+	return dto.NewUserPreferenceDto(pref), nil
+}
+
+func (a *userPreferenceAppImpl) DeleteUserPreference(ctx context.Context, userUUID string) error {
+	if strings.TrimSpace(userUUID) == "" {
+		return errno.ErrUserUUIDRequired
+	}
+	deleted, err := a.prefRepo.DeleteUserPreference(ctx, userUUID)
+	if err != nil {
+		return errno.NewBizError(errno.ErrDatabase, err)
+	}
+	if !deleted {
+		return errno.ErrPreferenceNotFound
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/presence"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// DeleteWorker 下线并删除 worker：已停止的实例按流水线快照把被打断的任务置回 pending 并在本实例重新排队；
+// 仍存活的实例须 force，其正在执行的任务被取消而不是重新派发，避免同一任务在两个实例上重复编码。
+// 之后删除存活键与流水线快照，执行记录保留用于容量统计；每个受影响的任务追加一条备注作为审计记录
+func (o *opsAppImpl) DeleteWorker(ctx context.Context, workerID string, req *cqe.DeleteWorkerReq) (*dto.WorkerDeletionDto, error) {
+	rec, _, presenceOK, err := o.loadWorker(ctx, workerID)
+	notFound := err == errno.ErrWorkerNotFound
+	if err != nil && !notFound {
+		return nil, err
+	}
+	// 无法确认实例已停止时不重新派发，否则任务可能在两个实例上同时编码
+	if !presenceOK {
+		return nil, errno.ErrPresenceUnavailable
+	}
+	snap, err := o.workerSnapshot(ctx, workerID)
+	if err != nil {
+		return nil, errno.ErrDatabase
+	}
+	if notFound && snap == nil {
+		return nil, errno.ErrWorkerNotFound
+	}
+	if rec != nil && !req.Force {
+		return nil, errno.ErrWorkerStillLive
+	}
+	operator := req.Operator
+	if operator == "" {
+		operator = "ops"
+	}
+
+	res := &dto.WorkerDeletionDto{WorkerID: workerID, WasLive: rec != nil, Reassigned: []string{}, Cancelled: []string{}, Skipped: []string{}, Failed: []string{}}
+	// 先删除快照取得接管权，避免其他实例启动对账时重复恢复同一批任务
+	if snap != nil {
+		owned, err := o.snapshotRepo.DeletePipelineSnapshot(ctx, workerID)
+		if err != nil {
+			return nil, errno.ErrDatabase
+		}
+		res.SnapshotRemoved = owned
+		if !owned {
+			snap = nil
+		}
+	}
+	var taskUUIDs []string
+	seen := map[string]bool{}
+	if rec != nil {
+		for _, id := range rec.ActiveTasks {
+			if !seen[id] {
+				seen[id] = true
+				taskUUIDs = append(taskUUIDs, id)
+			}
+		}
+	}
+	if snap != nil {
+		for _, cl := range snap.Claims {
+			if !seen[cl.TaskUUID] {
+				seen[cl.TaskUUID] = true
+				taskUUIDs = append(taskUUIDs, cl.TaskUUID)
+			}
+		}
+	}
+
+	for _, id := range taskUUIDs {
+		outcome, err := o.releaseWorkerTask(ctx, id, workerID, rec != nil)
+		switch {
+		case err != nil:
+			res.Failed = append(res.Failed, id)
+			logger.Warnf("release task of deleted worker failed worker_id=%s task_uuid=%s error=%v", workerID, id, err)
+			continue
+		case outcome == "":
+			res.Skipped = append(res.Skipped, id)
+			continue
+		case outcome == vo.TaskStatusCancelled.String():
+			res.Cancelled = append(res.Cancelled, id)
+		default:
+			res.Reassigned = append(res.Reassigned, id)
+		}
+		o.auditWorkerTask(ctx, id, operator, fmt.Sprintf("worker %s deleted by %s: task %s", workerID, operator, outcome))
+	}
+
+	if removed, err := presence.Remove(ctx, workerID); err != nil {
+		logger.Warnf("remove worker presence failed worker_id=%s error=%v", workerID, err)
+	} else {
+		res.PresenceRemoved = removed
+	}
+	res.DeletedAt = types.NewTime(clock.Now())
+	metrics.Add("workers_deleted_total", 1)
+	metrics.Add("worker_delete_reassigned_total", int64(len(res.Reassigned)))
+	metrics.Add("worker_delete_cancelled_total", int64(len(res.Cancelled)))
+	logger.Infof("audit worker deleted worker_id=%s operator=%s was_live=%v force=%v reassigned=%d cancelled=%d skipped=%d failed=%d snapshot_removed=%v presence_removed=%v",
+		workerID, operator, res.WasLive, req.Force, len(res.Reassigned), len(res.Cancelled), len(res.Skipped), len(res.Failed), res.SnapshotRemoved, res.PresenceRemoved)
+	return res, nil
+}
+
+// workerSnapshot 实例最近一次写入的流水线快照，不存在时返回 nil
+func (o *opsAppImpl) workerSnapshot(ctx context.Context, workerID string) (*vo.PipelineSnapshot, error) {
+	snaps, err := o.snapshotRepo.ListPipelineSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range snaps {
+		if s.InstanceID == workerID {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+// releaseWorkerTask 按库中最新状态处理单个任务，返回处理后的状态；已结束或等待重试的任务返回空串
+func (o *opsAppImpl) releaseWorkerTask(ctx context.Context, taskUUID, workerID string, live bool) (string, error) {
+	task, err := o.transcodeRepo.GetTranscodeJob(ctx, taskUUID)
+	if err != nil {
+		return "", err
+	}
+	if task == nil {
+		return "", nil
+	}
+	status := task.Status()
+	if status != vo.TaskStatusPending && status != vo.TaskStatusProcessing {
+		return "", nil
+	}
+	if live {
+		if err := task.TransitionTo(vo.TaskStatusCancelled); err != nil {
+			return "", err
+		}
+		task.SetErrorMessage("cancelled: worker " + workerID + " deleted")
+		if err := o.transcodeRepo.SaveTranscodeJobStatus(ctx, task); err != nil {
+			return "", err
+		}
+		return vo.TaskStatusCancelled.String(), nil
+	}
+	if err := o.requeueTask(ctx, task); err != nil {
+		return "", err
+	}
+	return "reassigned", nil
+}
+
+// requeueTask 被打断的 processing 任务置回 pending，与排队中的任务一起在本实例重新入队
+func (o *opsAppImpl) requeueTask(ctx context.Context, task *entity.TranscodeTaskEntity) error {
+	if task.Status() == vo.TaskStatusProcessing {
+		if err := task.TransitionTo(vo.TaskStatusPending); err != nil {
+			return err
+		}
+		task.SetProgress(0)
+		task.SetErrorMessage("")
+		if err := o.transcodeRepo.SaveTranscodeJobStatus(ctx, task); err != nil {
+			return err
+		}
+	}
+	return o.taskQueue.Enqueue(ctx, task)
+}
+
+// auditWorkerTask 在任务上追加备注，失败只记录日志
+func (o *opsAppImpl) auditWorkerTask(ctx context.Context, taskUUID, operator, body string) {
+	note := &vo.TaskNote{NoteUUID: clock.NewID(), TaskUUID: taskUUID, Author: operator, Body: body, CreatedAt: clock.Now()}
+	if err := o.noteRepo.CreateTaskNote(ctx, note); err != nil {
+		logger.Warnf("write worker deletion note failed task_uuid=%s error=%v", taskUUID, err)
+	}
+}
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/presence"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+)
+
+// workerHistoryLimit 合并执行记录时读取的上限
+const workerHistoryLimit = 10000
+
+func (o *opsAppImpl) Workers(ctx context.Context) (*dto.WorkerListDto, error) {
+	res := &dto.WorkerListDto{PresenceAvailable: true}
+	byID := map[string]*dto.WorkerDto{}
+	records, err := presence.List(ctx)
+	if err != nil {
+		// Redis 不可用时退化为只看执行记录，不影响排障
+		res.PresenceAvailable = false
+		logger.Warnf("list worker presence failed error=%v", err)
+	}
+	for _, r := range records {
+		byID[r.ClaimID] = &dto.WorkerDto{
+			WorkerID: r.ClaimID, Live: true, Hostname: r.Hostname, StartedAt: types.NewTimeOrNil(r.StartedAt), LastSeenAt: types.NewTimeOrNil(r.UpdatedAt),
+			Slots: r.Slots, HLSSlots: r.HLSSlots, Running: r.Running, QueueDepth: r.QueueDepth, GPUs: dto.NewWorkerGPUDtos(r.GPUs),
+		}
+		res.Live++
+		res.Slots += r.Slots
+		res.Running += r.Running
+	}
+
+	history := 24 * time.Hour
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		history = cfg.Worker.Presence.History
+	}
+	now := clock.Now()
+	rows, err := o.assignmentRepo.QueryTaskAssignments(ctx, "", now.Add(-history), now, workerHistoryLimit)
+	if err != nil {
+		return nil, errno.ErrDatabase
+	}
+	for _, a := range rows {
+		w, ok := byID[a.WorkerID]
+		if !ok {
+			w = &dto.WorkerDto{WorkerID: a.WorkerID, Slots: a.SlotCapacity}
+			byID[a.WorkerID] = w
+		}
+		w.RecentTasks++
+		if w.LastAssignmentAt == nil || a.FinishedAt.After(w.LastAssignmentAt.Time) {
+			w.LastAssignmentAt = types.NewTimeOrNil(a.FinishedAt)
+		}
+	}
+
+	res.Workers = make([]dto.WorkerDto, 0, len(byID))
+	for _, w := range byID {
+		res.Workers = append(res.Workers, *w)
+	}
+	sort.Slice(res.Workers, func(i, j int) bool {
+		if res.Workers[i].Live != res.Workers[j].Live {
+			return res.Workers[i].Live
+		}
+		return res.Workers[i].WorkerID < res.Workers[j].WorkerID
+	})
+	return res, nil
+}
+
+const (
+	// staleHeartbeats 心跳滞后超过该倍数的写入间隔视为 unhealthy
+	staleHeartbeats = 2
+	// degradedFailures 最近连续失败达到该次数视为 degraded
+	degradedFailures = 3
+)
+
+func (o *opsAppImpl) GetWorker(ctx context.Context, workerID string) (*dto.WorkerDetailDto, error) {
+	rec, history, presenceOK, err := o.loadWorker(ctx, workerID)
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.WorkerDetailDto{WorkerDto: dto.WorkerDto{WorkerID: workerID}}
+	if rec != nil {
+		res.WorkerDto = dto.WorkerDto{
+			WorkerID: workerID, Live: true, Hostname: rec.Hostname, StartedAt: types.NewTimeOrNil(rec.StartedAt), LastSeenAt: types.NewTimeOrNil(rec.UpdatedAt),
+			Slots: rec.Slots, HLSSlots: rec.HLSSlots, Running: rec.Running, QueueDepth: rec.QueueDepth, GPUs: dto.NewWorkerGPUDtos(rec.GPUs),
+		}
+		res.Capabilities, res.ActiveTasks = rec.Capabilities, rec.ActiveTasks
+	}
+	for _, a := range history {
+		res.RecentTasks++
+		if a.Outcome == vo.AssignmentFailed {
+			res.RecentFailures++
+		}
+		if res.Slots == 0 {
+			res.Slots = a.SlotCapacity
+		}
+		if res.LastTask == nil || a.FinishedAt.After(res.LastTask.FinishedAt.Time) {
+			res.LastTask = &dto.WorkerTaskDto{
+				TaskUUID: a.TaskUUID, JobType: a.JobType, Attempt: a.Attempt, Outcome: string(a.Outcome),
+				StartedAt: types.NewTime(a.StartedAt), FinishedAt: types.NewTime(a.FinishedAt),
+			}
+		}
+	}
+	if res.LastTask != nil {
+		finished := res.LastTask.FinishedAt
+		res.LastAssignmentAt = &finished
+	}
+	res.Health = workerHealth(workerID, rec, history, presenceOK, clock.Now())
+	return res, nil
+}
+
+func (o *opsAppImpl) CheckWorkerHealth(ctx context.Context, workerID string) (*dto.WorkerHealthDto, error) {
+	rec, history, presenceOK, err := o.loadWorker(ctx, workerID)
+	if err != nil {
+		return nil, err
+	}
+	return workerHealth(workerID, rec, history, presenceOK, clock.Now()), nil
+}
+
+// loadWorker 读取实例的存活状态与 history 窗口内的执行记录；两者都没有时返回 ErrWorkerNotFound
+func (o *opsAppImpl) loadWorker(ctx context.Context, workerID string) (*presence.Record, []*vo.TaskAssignment, bool, error) {
+	if workerID == "" {
+		return nil, nil, false, errno.ErrInvalidParam
+	}
+	presenceOK := true
+	rec, err := presence.Get(ctx, workerID)
+	if err != nil {
+		presenceOK = false
+		logger.Warnf("get worker presence failed worker_id=%s error=%v", workerID, err)
+	}
+	history := 24 * time.Hour
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		history = cfg.Worker.Presence.History
+	}
+	now := clock.Now()
+	rows, err := o.assignmentRepo.QueryTaskAssignments(ctx, workerID, now.Add(-history), now, workerHistoryLimit)
+	if err != nil {
+		return nil, nil, presenceOK, errno.ErrDatabase
+	}
+	if rec == nil && len(rows) == 0 {
+		return nil, nil, presenceOK, errno.ErrWorkerNotFound
+	}
+	return rec, rows, presenceOK, nil
+}
+
+// workerHealth 没有存活键为 offline；存活键仍在但心跳滞后超过 staleHeartbeats 个写入间隔为 unhealthy；
+// 最近连续失败达到 degradedFailures 次为 degraded
+func workerHealth(workerID string, rec *presence.Record, history []*vo.TaskAssignment, presenceOK bool, now time.Time) *dto.WorkerHealthDto {
+	h := &dto.WorkerHealthDto{WorkerID: workerID, Status: dto.WorkerHealthy, PresenceAvailable: presenceOK, CheckedAt: types.NewTime(now)}
+	sorted := make([]*vo.TaskAssignment, len(history))
+	copy(sorted, history)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].FinishedAt.Before(sorted[j].FinishedAt) })
+	for i := len(sorted) - 1; i >= 0 && sorted[i].Outcome == vo.AssignmentFailed; i-- {
+		h.ConsecutiveFailures++
+	}
+
+	if rec == nil {
+		h.Status = dto.WorkerOffline
+		if presenceOK {
+			h.Reasons = append(h.Reasons, "no presence heartbeat")
+		} else {
+			h.Reasons = append(h.Reasons, "presence store unavailable")
+		}
+		return h
+	}
+	age := now.Sub(rec.UpdatedAt).Seconds()
+	h.HeartbeatAgeSeconds = &age
+	interval := presence.HeartbeatInterval(config.GetGlobalConfig())
+	if now.Sub(rec.UpdatedAt) > staleHeartbeats*interval {
+		h.Status = dto.WorkerUnhealthy
+		h.Reasons = append(h.Reasons, fmt.Sprintf("heartbeat stale: last write %.0fs ago, interval %s", age, interval))
+	}
+	if h.ConsecutiveFailures >= degradedFailures {
+		if h.Status == dto.WorkerHealthy {
+			h.Status = dto.WorkerDegraded
+		}
+		h.Reasons = append(h.Reasons, fmt.Sprintf("last %d tasks failed", h.ConsecutiveFailures))
+	}
+	return h
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"transcode-service/ddd/application/cqe"
+	"transcode-service/ddd/application/dto"
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/errno"
+)
+
+// utilizationQueryLimit 单次报表读取的执行记录上限
+const utilizationQueryLimit = 50000
+
+func (o *opsAppImpl) WorkerUtilization(ctx context.Context, q *cqe.WorkerUtilizationQuery) (*dto.WorkerUtilizationReportDto, error) {
+	if err := q.Validate(clock.Now()); err != nil {
+		return nil, err
+	}
+	rows, err := o.assignmentRepo.QueryTaskAssignments(ctx, q.WorkerID, q.FromTime, q.ToTime, utilizationQueryLimit)
+	if err != nil {
+		return nil, errno.ErrDatabase
+	}
+	report := &dto.WorkerUtilizationReportDto{
+		From:      types.NewTime(q.FromTime),
+		To:        types.NewTime(q.ToTime),
+		Bucket:    q.Interval.String(),
+		Truncated: len(rows) >= utilizationQueryLimit,
+		Workers:   []dto.WorkerUtilizationDto{},
+	}
+	nBuckets := int((q.ToTime.Sub(q.FromTime) + q.Interval - 1) / q.Interval)
+	workers := make(map[string]*dto.WorkerUtilizationDto)
+	waits := make(map[string]float64)
+	for _, a := range rows {
+		w, ok := workers[a.WorkerID]
+		if !ok {
+			w = &dto.WorkerUtilizationDto{WorkerID: a.WorkerID, JobTypes: map[string]int{}, Buckets: make([]dto.UtilizationBucketDto, nBuckets)}
+			for i := range w.Buckets {
+				w.Buckets[i].Start = types.NewTime(q.FromTime.Add(q.Interval * time.Duration(i)))
+			}
+			workers[a.WorkerID] = w
+		}
+		if a.SlotCapacity > w.SlotCapacity {
+			w.SlotCapacity = a.SlotCapacity
+		}
+		// 跨窗口起点的执行只计入占用时长，不计入任务数
+		if !a.StartedAt.Before(q.FromTime) {
+			w.Tasks++
+			w.JobTypes[a.JobType]++
+			waits[a.WorkerID] += a.Wait.Seconds()
+			switch a.Outcome {
+			case vo.AssignmentSucceeded:
+				w.Succeeded++
+			case vo.AssignmentFailed:
+				w.Failed++
+			case vo.AssignmentCancelled:
+				w.Cancelled++
+			}
+			if i := int(a.StartedAt.Sub(q.FromTime) / q.Interval); i < nBuckets {
+				w.Buckets[i].Tasks++
+			}
+		}
+		for i := range w.Buckets {
+			start := w.Buckets[i].Start.Time
+			if busy := a.Overlap(start, start.Add(q.Interval)); busy > 0 {
+				w.Buckets[i].BusySlotSeconds += busy.Seconds() * float64(a.SlotWeight)
+			}
+		}
+	}
+	for _, w := range workers {
+		for i := range w.Buckets {
+			b := &w.Buckets[i]
+			w.BusySlotSeconds += b.BusySlotSeconds
+			b.Utilization = utilizationRatio(b.BusySlotSeconds, b.Start.Time, minTime(b.Start.Add(q.Interval), q.ToTime), w.SlotCapacity)
+		}
+		w.Utilization = utilizationRatio(w.BusySlotSeconds, q.FromTime, q.ToTime, w.SlotCapacity)
+		if w.Tasks > 0 {
+			w.AvgWaitSeconds = waits[w.WorkerID] / float64(w.Tasks)
+		}
+		report.Workers = append(report.Workers, *w)
+	}
+	sort.Slice(report.Workers, func(i, j int) bool { return report.Workers[i].WorkerID < report.Workers[j].WorkerID })
+	return report, nil
+}
+
+func utilizationRatio(busySlotSeconds float64, from, to time.Time, capacity int) float64 {
+	total := to.Sub(from).Seconds() * float64(capacity)
+	if total <= 0 {
+		return 0
+	}
+	return busySlotSeconds / total
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
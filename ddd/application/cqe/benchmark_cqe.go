@@ -0,0 +1,9 @@
+package cqe
+
+// StartBenchmarkReq 开始压测；省略的字段取 benchmark 配置，校验由压测 Runner 按配置上限完成
+type StartBenchmarkReq struct {
+	Resolutions   []string `json:"resolutions"`      // 如 ["720p","1080p"]，轮流提交
+	SourceSeconds int      `json:"source_seconds"`   // 合成源文件时长
+	RatePerMinute float64  `json:"rate_per_minute"`  // 每分钟提交任务数
+	Minutes       int      `json:"duration_minutes"` // 提交持续分钟数
+}
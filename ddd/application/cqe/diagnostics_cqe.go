@@ -0,0 +1,7 @@
+package cqe
+
+// StartProfileCaptureReq 采集剖析文件；校验由采集器按 diagnostics 配置完成
+type StartProfileCaptureReq struct {
+	Kind    string `json:"kind"`    // trace/cpu/heap/allocs/goroutine
+	Seconds int    `json:"seconds"` // trace/cpu 采集时长，缺省为 diagnostics.max_capture_seconds
+}
@@ -0,0 +1,25 @@
+package cqe
+
+import "transcode-service/pkg/errno"
+
+// UpdateEncodeBudgetReq 运行时调整共享编码槽位；省略的字段保持不变
+type UpdateEncodeBudgetReq struct {
+	Enabled           *bool          `json:"enabled"`
+	Slots             int            `json:"slots"`
+	JobWeights        map[string]int `json:"job_weights"`
+	ResolutionWeights map[string]int `json:"resolution_weights"`
+}
+
+func (req *UpdateEncodeBudgetReq) Validate() error {
+	if req.Slots < 0 {
+		return errno.ErrInvalidParam
+	}
+	for _, m := range []map[string]int{req.JobWeights, req.ResolutionWeights} {
+		for _, w := range m {
+			if w <= 0 {
+				return errno.ErrInvalidParam
+			}
+		}
+	}
+	return nil
+}
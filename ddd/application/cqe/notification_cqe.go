@@ -0,0 +1,18 @@
+package cqe
+
+import "transcode-service/pkg/errno"
+
+// TestNotificationReq 测试发送告警；channel 为空时发送到全部通道
+type TestNotificationReq struct {
+	Channel  string `json:"channel"`
+	Severity string `json:"severity"` // info | warning | critical，缺省 info
+}
+
+func (req *TestNotificationReq) Validate() error {
+	switch req.Severity {
+	case "", "info", "warning", "critical":
+		return nil
+	default:
+		return errno.ErrInvalidParam
+	}
+}
@@ -0,0 +1,38 @@
+package cqe
+
+import (
+	"strings"
+	"time"
+
+	"transcode-service/pkg/errno"
+)
+
+// maxOutputAccessKeys 单次回写的产物 key 上限
+const maxOutputAccessKeys = 1000
+
+// RecordOutputAccessReq CDN 访问日志汇总后回写产物最近访问时间，accessed_at 缺省为当前时间
+type RecordOutputAccessReq struct {
+	ObjectKeys []string   `json:"object_keys"`
+	AccessedAt *time.Time `json:"accessed_at,omitempty"`
+}
+
+// Validate 去掉空 key 并校验数量与时间
+func (r *RecordOutputAccessReq) Validate(now time.Time) error {
+	keys := r.ObjectKeys[:0]
+	for _, k := range r.ObjectKeys {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	r.ObjectKeys = keys
+	if len(keys) == 0 || len(keys) > maxOutputAccessKeys {
+		return errno.ErrInvalidParam
+	}
+	if r.AccessedAt == nil {
+		r.AccessedAt = &now
+	}
+	if r.AccessedAt.After(now.Add(time.Minute)) {
+		return errno.ErrInvalidParam
+	}
+	return nil
+}
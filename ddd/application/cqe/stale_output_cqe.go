@@ -0,0 +1,50 @@
+package cqe
+
+import (
+	"strings"
+
+	"transcode-service/pkg/errno"
+)
+
+const (
+	defaultStaleOutputLimit = 100
+	maxStaleOutputLimit     = 1000
+)
+
+// 回填产物类型
+const (
+	OutputKindTranscode = "transcode"
+	OutputKindHLS       = "hls"
+)
+
+// StaleOutputQuery 选择需要回填的产物：?kind=transcode|hls&before_version=&exclude_hash=&after_id=&limit=；
+// before_version 与 exclude_hash 至少一个，都设置时满足其一即可
+type StaleOutputQuery struct {
+	Kind          string `form:"kind"`
+	BeforeVersion int    `form:"before_version"`
+	ExcludeHash   string `form:"exclude_hash"`
+	AfterID       uint64 `form:"after_id"`
+	Limit         int    `form:"limit"`
+}
+
+// Validate 补齐缺省值并校验筛选条件
+func (q *StaleOutputQuery) Validate() error {
+	q.Kind = strings.ToLower(strings.TrimSpace(q.Kind))
+	if q.Kind == "" {
+		q.Kind = OutputKindTranscode
+	}
+	if q.Kind != OutputKindTranscode && q.Kind != OutputKindHLS {
+		return errno.ErrInvalidParam
+	}
+	q.ExcludeHash = strings.TrimSpace(q.ExcludeHash)
+	if q.BeforeVersion < 0 || (q.BeforeVersion == 0 && q.ExcludeHash == "") {
+		return errno.ErrInvalidParam
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultStaleOutputLimit
+	}
+	if q.Limit > maxStaleOutputLimit {
+		q.Limit = maxStaleOutputLimit
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package cqe
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"transcode-service/pkg/errno"
+)
+
+// MaxTaskGroupNameLength 任务组名称最大字符数
+const MaxTaskGroupNameLength = 128
+
+// CreateTaskGroupReq 创建任务组，如一季剧集；expected_tasks 大于 0 时组内任务数达到该值且全部结束才算完成
+type CreateTaskGroupReq struct {
+	UserUUID      string `json:"user_uuid"`
+	Name          string `json:"name"`
+	ExpectedTasks int    `json:"expected_tasks"`
+	// CallbackURL 组内任务全部结束后 POST 一次完成回调（task_group.completed），缺省不回调
+	CallbackURL string `json:"callback_url"`
+}
+
+// Validate maxTasks 为 task_groups.max_tasks，allowHost 校验回调地址的主机
+func (req *CreateTaskGroupReq) Validate(maxTasks int, allowHost func(string) bool) error {
+	req.Name = strings.TrimSpace(req.Name)
+	req.CallbackURL = strings.TrimSpace(req.CallbackURL)
+	if req.UserUUID == "" {
+		return errno.ErrUserUUIDRequired
+	}
+	if utf8.RuneCountInString(req.Name) > MaxTaskGroupNameLength || req.ExpectedTasks < 0 || req.ExpectedTasks > maxTasks {
+		return errno.ErrInvalidTaskGroup
+	}
+	if req.CallbackURL != "" {
+		u, err := url.Parse(req.CallbackURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || !allowHost(u.Hostname()) {
+			return errno.ErrInvalidTaskGroup
+		}
+	}
+	return nil
+}
+
+// AttachTaskGroupTasksReq 把已有任务加入任务组；已属于其他任务组或其他用户的任务被跳过
+type AttachTaskGroupTasksReq struct {
+	GroupUUID string   `json:"-"`
+	TaskUUIDs []string `json:"task_uuids"`
+}
+
+func (req *AttachTaskGroupTasksReq) Validate(maxTasks int) error {
+	if req.GroupUUID == "" || len(req.TaskUUIDs) == 0 || len(req.TaskUUIDs) > maxTasks {
+		return errno.ErrInvalidTaskGroup
+	}
+	for _, id := range req.TaskUUIDs {
+		if id == "" {
+			return errno.ErrTaskUUIDRequired
+		}
+	}
+	return nil
+}
+
+// CreateTaskGroupTasksReq 在任务组下批量创建任务；各项缺省的 user_uuid 取任务组的用户
+type CreateTaskGroupTasksReq struct {
+	GroupUUID string                   `json:"-"`
+	Tasks     []CreateTranscodeTaskReq `json:"tasks"`
+}
+
+func (req *CreateTaskGroupTasksReq) Validate(maxTasks int) error {
+	if req.GroupUUID == "" || len(req.Tasks) == 0 || len(req.Tasks) > maxTasks {
+		return errno.ErrInvalidTaskGroup
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package cqe
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"transcode-service/pkg/errno"
+)
+
+// MaxTaskNoteLength 单条备注的最大字符数
+const MaxTaskNoteLength = 2000
+
+// AddTaskNoteReq 给任务追加备注，如 "customer escalation, retried with x264"
+type AddTaskNoteReq struct {
+	TaskUUID string `json:"-"`
+	Author   string `json:"author"`
+	Body     string `json:"body"`
+}
+
+func (req *AddTaskNoteReq) Validate() error {
+	req.Author = strings.TrimSpace(req.Author)
+	req.Body = strings.TrimSpace(req.Body)
+	if req.TaskUUID == "" {
+		return errno.ErrTaskUUIDRequired
+	}
+	if req.Author == "" || utf8.RuneCountInString(req.Author) > 128 {
+		return errno.ErrInvalidTaskNote
+	}
+	if req.Body == "" || utf8.RuneCountInString(req.Body) > MaxTaskNoteLength {
+		return errno.ErrInvalidTaskNote
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cqe
+
+import (
+	"fmt"
+	"time"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/errno"
+)
+
+const (
+	defaultThroughputDays = 30
+	maxThroughputDays     = 366
+)
+
+// ThroughputQuery 每日处理量查询；from/to 为 UTC 日期 2006-01-02（含两端），缺省最近 30 天
+type ThroughputQuery struct {
+	From string `form:"from"`
+	To   string `form:"to"`
+
+	FromDate time.Time `form:"-"`
+	ToDate   time.Time `form:"-"`
+}
+
+// Validate 解析日期区间，区间不超过 366 天
+func (q *ThroughputQuery) Validate(now time.Time) error {
+	today, _ := time.Parse(vo.ThroughputDateLayout, vo.ThroughputDate(now))
+	q.ToDate = today
+	if q.To != "" {
+		t, err := time.Parse(vo.ThroughputDateLayout, q.To)
+		if err != nil {
+			return errno.NewSimpleBizError(errno.ErrInvalidParam, fmt.Errorf("to must be a date like 2006-01-02: %w", err))
+		}
+		q.ToDate = t
+	}
+	q.FromDate = q.ToDate.AddDate(0, 0, -(defaultThroughputDays - 1))
+	if q.From != "" {
+		t, err := time.Parse(vo.ThroughputDateLayout, q.From)
+		if err != nil {
+			return errno.NewSimpleBizError(errno.ErrInvalidParam, fmt.Errorf("from must be a date like 2006-01-02: %w", err))
+		}
+		q.FromDate = t
+	}
+	if q.ToDate.Before(q.FromDate) || q.Days() > maxThroughputDays {
+		return errno.NewSimpleBizError(errno.ErrInvalidParam, fmt.Errorf("date range must be from <= to and at most %d days", maxThroughputDays))
+	}
+	return nil
+}
+
+// Days 区间内的天数
+func (q *ThroughputQuery) Days() int {
+	return int(q.ToDate.Sub(q.FromDate)/(24*time.Hour)) + 1
+}
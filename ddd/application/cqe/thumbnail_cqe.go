@@ -0,0 +1,39 @@
+package cqe
+
+import (
+	"strings"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/errno"
+)
+
+// CreateThumbnailReq 按时间戳截取一帧作为自定义封面，宽高均为 0 时保持原尺寸
+type CreateThumbnailReq struct {
+	VideoUUID   string `json:"-"`
+	TimestampMs int64  `json:"timestamp_ms"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Format      string `json:"format"` // jpeg|webp，默认 jpeg
+	Source      string `json:"source"` // rendition|source，为空时优先最近完成的产物，尚无产物时用源文件
+}
+
+func (req *CreateThumbnailReq) Validate() error {
+	if req.VideoUUID == "" {
+		return errno.ErrVideoUUIDRequired
+	}
+	if req.TimestampMs < 0 {
+		return errno.ErrInvalidThumbnail
+	}
+	if req.Width < 0 || req.Width > vo.MaxThumbnailDimension || req.Height < 0 || req.Height > vo.MaxThumbnailDimension {
+		return errno.ErrInvalidThumbnail
+	}
+	if _, err := vo.ParseImageFormat(req.Format); err != nil {
+		return errno.ErrInvalidThumbnail
+	}
+	switch req.Source = strings.ToLower(strings.TrimSpace(req.Source)); req.Source {
+	case "", vo.ThumbnailSourceRendition, vo.ThumbnailSourceOriginal:
+	default:
+		return errno.ErrInvalidThumbnail
+	}
+	return nil
+}
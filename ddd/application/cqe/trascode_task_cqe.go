@@ -1,6 +1,19 @@
 package cqe
 
-import "transcode-service/pkg/errno"
+import (
+	"regexp"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/utils"
+)
+
+// MaxIdempotencyKeyLength 幂等键最大长度
+const MaxIdempotencyKeyLength = 128
+
+// MaxRenditionsLimit max_renditions 允许的最大值
+const MaxRenditionsLimit = 16
 
 // TranscodeTaskCqe 转码任务CQE（别名）
 type TranscodeTaskCqe = CreateTranscodeTaskReq
@@ -11,8 +24,41 @@ type CreateTranscodeTaskReq struct {
 	VideoUUID     string `json:"video_uuid" binding:"required"`    // 视频UUID
 	VideoPushUUID string `json:"video_push_uuid"`                  // 上传服务侧UUID
 	OriginalPath  string `json:"original_path" binding:"required"` // 原始视频路径
-	Resolution    string `json:"resolution" binding:"required"`    // 转码分辨率
-	Bitrate       string `json:"bitrate" binding:"required"`       // 转码码率
+	Resolution    string `json:"resolution"`                       // 转码分辨率，缺省时使用用户偏好
+	Bitrate       string `json:"bitrate"`                          // 转码码率，缺省时使用用户偏好
+	Container     string `json:"container"`                        // 输出封装 mp4|mkv|webm|mov，缺省按 output_formats 配置
+	// Labels 任务标签，如 {"campaign":"summer","source":"mobile-app"}，最多 16 个
+	Labels map[string]string `json:"labels"`
+	// Preview 预览模式：只转码前 PreviewSeconds 秒（缺省按 transcode.preview.default_seconds），发布到预览 key，不生成 HLS
+	Preview        bool `json:"preview"`
+	PreviewSeconds int  `json:"preview_seconds"`
+	// SourceGeneration 源文件代数，重新上传源文件时由上传服务递增；缺省按源路径是否变化推断
+	SourceGeneration int64 `json:"source_generation"`
+	// VideoStreamIndex/AudioStreamIndex 显式指定输入流序号（ffprobe stream index），
+	// 用于多节目 TS 或带封面图的文件；缺省时自动选择主视频流与最佳音频流
+	VideoStreamIndex *int `json:"video_stream_index"`
+	AudioStreamIndex *int `json:"audio_stream_index"`
+	// Profile 为 auto 时按 Source 中的源文件特征由 transcode.auto_profile 规则选择分辨率、码率、编码器与 HLS 阶梯，
+	// 显式传入的 resolution/bitrate 仍优先
+	Profile string          `json:"profile"`
+	Source  *vo.SourceHints `json:"source"`
+	// AudioOutputs 随视频一起生成的纯音频产物（播客重发布），如 [{"format":"m4a","bitrate":"128k"}]；
+	// AudioTags 写入音频产物的元数据标签（title/artist/album/album_artist/genre/date/comment/composer/copyright）
+	AudioOutputs []AudioOutputReq  `json:"audio_outputs"`
+	AudioTags    map[string]string `json:"audio_tags"`
+	// FormatSet 使用的输出档位集合（transcode.format_sets），缺省为当前环境的 transcode.format_set；
+	// MaxRenditions HLS 阶梯最多档位数，按分辨率从高到低保留，0 表示不限制
+	FormatSet     string `json:"format_set"`
+	MaxRenditions int    `json:"max_renditions"`
+	// IdempotencyKey 调用方提供的幂等键，同一键重复提交返回首次创建的任务；Kafka 消费缺省为 topic/partition/offset
+	IdempotencyKey string `json:"idempotency_key"`
+	// Visibility public|private，缺省按 private_outputs.default_visibility；private 产物只返回签名 URL
+	Visibility string `json:"visibility"`
+	// OutputBucket/OutputPrefix 产物写入的目标桶与前缀，须在 output_targets 白名单内，缺省为默认桶
+	OutputBucket string `json:"output_bucket"`
+	OutputPrefix string `json:"output_prefix"`
+	// GroupUUID 加入已有的任务组（同一用户、未完成），缺省不加入
+	GroupUUID string `json:"group_uuid"`
 
 	// HLS相关配置（可选）
 	EnableHLS       bool                  `json:"enable_hls"`       // 是否启用HLS切片
@@ -22,6 +68,31 @@ type CreateTranscodeTaskReq struct {
 	HLSFormat       string                `json:"hls_format"`       // HLS格式
 }
 
+// AudioOutputReq 纯音频产物请求，bitrate 缺省 128k
+type AudioOutputReq struct {
+	Format  string `json:"format"` // m4a | mp3
+	Bitrate string `json:"bitrate"`
+}
+
+// AudioOutputSpec 转换为值对象并校验，未请求纯音频产物时返回 nil
+func (req *CreateTranscodeTaskReq) AudioOutputSpec() (*vo.AudioOutputs, error) {
+	if len(req.AudioOutputs) == 0 {
+		if len(req.AudioTags) > 0 {
+			return nil, errno.ErrInvalidAudioOutput
+		}
+		return nil, nil
+	}
+	renditions := make([]vo.AudioRendition, 0, len(req.AudioOutputs))
+	for _, o := range req.AudioOutputs {
+		renditions = append(renditions, vo.AudioRendition{Format: vo.AudioFormat(o.Format), Bitrate: o.Bitrate})
+	}
+	spec, err := vo.NewAudioOutputs(renditions, req.AudioTags)
+	if err != nil {
+		return nil, errno.NewSimpleBizError(errno.ErrInvalidAudioOutput, err)
+	}
+	return spec, nil
+}
+
 // HLSResolutionConfig HLS分辨率配置
 type HLSResolutionConfig struct {
 	Width   int    `json:"width" binding:"required"`   // 宽度
@@ -45,7 +116,50 @@ func (req *CreateTranscodeTaskReq) Validate() error {
 	if req.Bitrate == "" {
 		return errno.ErrBitrateRequired
 	}
+	// 标识与源路径会拼入本地工作目录与对象 key，拒绝目录穿越；源路径按规范形式保存
+	if utils.ValidatePathSegment(req.UserUUID) != nil || utils.ValidatePathSegment(req.VideoUUID) != nil {
+		return errno.ErrUnsafeInputPath
+	}
+	originalPath, err := utils.NormalizeObjectKey(req.OriginalPath)
+	if err != nil {
+		return errno.NewSimpleBizError(errno.ErrUnsafeInputPath, err)
+	}
+	req.OriginalPath = originalPath
 	// VideoPushUUID 可选，不强制校验
+	if _, err := vo.NewTaskLabels(req.Labels); err != nil {
+		return errno.NewSimpleBizError(errno.ErrInvalidLabels, err)
+	}
+	if req.PreviewSeconds < 0 || (req.PreviewSeconds > 0 && !req.Preview) {
+		return errno.ErrInvalidPreview
+	}
+	if req.SourceGeneration < 0 {
+		return errno.ErrInvalidParam
+	}
+	if (req.VideoStreamIndex != nil && *req.VideoStreamIndex < 0) || (req.AudioStreamIndex != nil && *req.AudioStreamIndex < 0) {
+		return errno.ErrInvalidStreamSelection
+	}
+	if req.Profile != "" && req.Profile != "auto" {
+		return errno.ErrInvalidProfile
+	}
+	if _, err := req.AudioOutputSpec(); err != nil {
+		return err
+	}
+	if req.Preview && len(req.AudioOutputs) > 0 {
+		// 预览只用于确认画质
+		return errno.ErrInvalidAudioOutput
+	}
+	if len(req.IdempotencyKey) > MaxIdempotencyKeyLength {
+		return errno.ErrInvalidParam
+	}
+	if _, err := vo.ParseVisibility(req.Visibility, vo.VisibilityPublic); err != nil {
+		return errno.ErrInvalidVisibility
+	}
+	if _, err := vo.NewOutputDestination(req.OutputBucket, req.OutputPrefix); err != nil {
+		return errno.ErrInvalidOutputTarget
+	}
+	if req.MaxRenditions < 0 || req.MaxRenditions > MaxRenditionsLimit {
+		return errno.ErrInvalidFormatSet
+	}
 
 	// 验证HLS配置
 	if req.EnableHLS {
@@ -165,3 +279,84 @@ func (req *GetTranscodeProgressReq) Validate() error {
 	}
 	return nil
 }
+
+// ValidateTranscodeTaskReq 预演（dry-run）建任务请求，不落库不入队
+type ValidateTranscodeTaskReq struct {
+	CreateTranscodeTaskReq
+	SourceDurationSeconds float64 `json:"source_duration_seconds"` // 源视频时长，用于耗时/体积估算，缺省按 60 秒
+}
+
+// MaxBatchVideoUUIDs 批量状态查询单次最多视频数
+const MaxBatchVideoUUIDs = 500
+
+// BatchTaskStatusReq 按视频批量查询任务状态
+type BatchTaskStatusReq struct {
+	VideoUUIDs []string `json:"video_uuids" binding:"required"`
+}
+
+// Normalize 去空、去重并校验数量
+func (req *BatchTaskStatusReq) Normalize() error {
+	seen := make(map[string]struct{}, len(req.VideoUUIDs))
+	out := make([]string, 0, len(req.VideoUUIDs))
+	for _, id := range req.VideoUUIDs {
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	if len(out) == 0 {
+		return errno.ErrVideoUUIDRequired
+	}
+	if len(out) > MaxBatchVideoUUIDs {
+		return errno.ErrBatchTooLarge
+	}
+	req.VideoUUIDs = out
+	return nil
+}
+
+// BoostTaskPriorityReq 调整排队任务优先级请求
+type BoostTaskPriorityReq struct {
+	TaskUUID string `json:"-"`
+	Priority int    `json:"priority" binding:"required"` // 1-10，越大越先执行
+	Reason   string `json:"reason"`                      // 例如 user_waiting，仅记录日志
+}
+
+func (req *BoostTaskPriorityReq) Validate() error {
+	if req.TaskUUID == "" {
+		return errno.ErrTaskUUIDRequired
+	}
+	if !entity.ValidTaskPriority(req.Priority) {
+		return errno.ErrInvalidPriority
+	}
+	return nil
+}
+
+// encoderNamePattern 编码器/预设名只允许字母数字与 _-，如 libx264、h264_nvenc、veryslow、p5
+var encoderNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// ReplayTaskReq 以覆盖参数重放已结束任务，未指定的参数沿用原任务
+type ReplayTaskReq struct {
+	TaskUUID   string `json:"-"`
+	VideoCodec string `json:"codec"`      // 如 libx264、libx265、h264_nvenc
+	Preset     string `json:"preset"`     // 如 veryslow、medium、p5
+	Resolution string `json:"resolution"` // 如 720p
+	Bitrate    string `json:"bitrate"`    // 如 2500k
+	Reason     string `json:"reason"`     // 例如 quality_complaint，仅记录日志
+}
+
+func (req *ReplayTaskReq) Validate() error {
+	if req.TaskUUID == "" {
+		return errno.ErrTaskUUIDRequired
+	}
+	if req.VideoCodec != "" && !encoderNamePattern.MatchString(req.VideoCodec) {
+		return errno.ErrInvalidParam
+	}
+	if req.Preset != "" && !encoderNamePattern.MatchString(req.Preset) {
+		return errno.ErrInvalidParam
+	}
+	return nil
+}
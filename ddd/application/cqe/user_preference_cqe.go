@@ -0,0 +1,49 @@
+package cqe
+
+import (
+	"strings"
+
+	"transcode-service/pkg/errno"
+)
+
+// SaveUserPreferenceReq 保存用户默认转码偏好请求
+type SaveUserPreferenceReq struct {
+	UserUUID         string                `uri:"user_uuid" json:"-"`
+	Ladder           []PreferenceRendition `json:"ladder"`            // 码率阶梯，首档为默认转码目标
+	Watermark        *WatermarkReq         `json:"watermark"`         // 水印
+	SubtitleLanguage string                `json:"subtitle_language"` // 字幕语言，如 zh-CN
+}
+
+// PreferenceRendition 偏好阶梯中的单档
+type PreferenceRendition struct {
+	Resolution string `json:"resolution" binding:"required"` // 分辨率，如 720p
+	Bitrate    string `json:"bitrate" binding:"required"`    // 码率，如 2000k
+}
+
+// WatermarkReq 水印配置
+type WatermarkReq struct {
+	ObjectKey string `json:"object_key" binding:"required"` // 水印图片对象键
+	Position  string `json:"position"`                      // 位置，默认 bottom_right
+}
+
+func (req *SaveUserPreferenceReq) Validate() error {
+	if strings.TrimSpace(req.UserUUID) == "" {
+		return errno.ErrUserUUIDRequired
+	}
+	if len(req.Ladder) == 0 && req.Watermark == nil && strings.TrimSpace(req.SubtitleLanguage) == "" {
+		return errno.ErrPreferenceEmpty
+	}
+	return nil
+}
+
+// UserPreferenceReq 查询/删除用户偏好请求
+type UserPreferenceReq struct {
+	UserUUID string `uri:"user_uuid" binding:"required"`
+}
+
+func (req *UserPreferenceReq) Validate() error {
+	if strings.TrimSpace(req.UserUUID) == "" {
+		return errno.ErrUserUUIDRequired
+	}
+	return nil
+}
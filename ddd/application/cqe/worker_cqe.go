@@ -0,0 +1,9 @@
+package cqe
+
+// DeleteWorkerReq 删除 worker 的查询参数
+type DeleteWorkerReq struct {
+	// Force 实例仍存活时强制删除：取消其正在执行的任务（执行侧轮询到取消状态后中止 ffmpeg）
+	Force bool `form:"force"`
+	// Operator 操作人，写入受影响任务的备注，缺省为 ops
+	Operator string `form:"operator"`
+}
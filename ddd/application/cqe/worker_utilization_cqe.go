@@ -0,0 +1,59 @@
+package cqe
+
+import (
+	"time"
+
+	"transcode-service/pkg/errno"
+)
+
+const (
+	defaultUtilizationWindow = 24 * time.Hour
+	defaultUtilizationBucket = time.Hour
+	maxUtilizationWindow     = 31 * 24 * time.Hour
+	maxUtilizationBuckets    = 1000
+)
+
+// WorkerUtilizationQuery 按 worker 统计利用率；from/to 为 RFC3339，缺省最近 24 小时，bucket 缺省 1h
+type WorkerUtilizationQuery struct {
+	WorkerID string `form:"worker_id"`
+	From     string `form:"from"`
+	To       string `form:"to"`
+	Bucket   string `form:"bucket"`
+
+	FromTime time.Time     `form:"-"`
+	ToTime   time.Time     `form:"-"`
+	Interval time.Duration `form:"-"`
+}
+
+// Validate 解析时间窗口与分桶，窗口不超过 31 天、分桶数不超过 1000
+func (q *WorkerUtilizationQuery) Validate(now time.Time) error {
+	q.ToTime = now
+	if q.To != "" {
+		t, err := time.Parse(time.RFC3339, q.To)
+		if err != nil {
+			return errno.ErrInvalidParam
+		}
+		q.ToTime = t
+	}
+	q.FromTime = q.ToTime.Add(-defaultUtilizationWindow)
+	if q.From != "" {
+		t, err := time.Parse(time.RFC3339, q.From)
+		if err != nil {
+			return errno.ErrInvalidParam
+		}
+		q.FromTime = t
+	}
+	q.Interval = defaultUtilizationBucket
+	if q.Bucket != "" {
+		d, err := time.ParseDuration(q.Bucket)
+		if err != nil || d < time.Minute {
+			return errno.ErrInvalidParam
+		}
+		q.Interval = d
+	}
+	window := q.ToTime.Sub(q.FromTime)
+	if window <= 0 || window > maxUtilizationWindow || window/q.Interval > maxUtilizationBuckets {
+		return errno.ErrInvalidParam
+	}
+	return nil
+}
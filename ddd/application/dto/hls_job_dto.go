@@ -0,0 +1,109 @@
+package dto
+
+import (
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+)
+
+// HLSJobDto HLS 作业详情，含各路码流状态与统计
+type HLSJobDto struct {
+	JobUUID        string            `json:"job_uuid"`
+	UserUUID       string            `json:"user_uuid"`
+	VideoUUID      string            `json:"video_uuid"`
+	SourceJobUUID  string            `json:"source_job_uuid,omitempty"`
+	Status         string            `json:"status"`
+	Progress       int               `json:"progress"`
+	MasterPlaylist string            `json:"master_playlist,omitempty"`
+	ErrorMessage   string            `json:"error_message,omitempty"`
+	Renditions     []HLSRenditionDto `json:"renditions"`
+	Stats          HLSJobStatsDto    `json:"stats"`
+	// Fingerprint 产物的编码设置指纹，完成前或历史作业省略
+	Fingerprint *vo.EncoderFingerprint `json:"encoder_fingerprint,omitempty"`
+	CreatedAt   types.Time             `json:"created_at"`
+	UpdatedAt   types.Time             `json:"updated_at"`
+}
+
+// HLSJobStatsDto 按码流汇总的作业统计
+type HLSJobStatsDto struct {
+	Renditions int   `json:"renditions"`
+	Completed  int   `json:"completed"`
+	Failed     int   `json:"failed"`
+	Pending    int   `json:"pending"`
+	Segments   int   `json:"segments"`
+	Bytes      int64 `json:"bytes"`
+	SliceMs    int64 `json:"slice_ms"` // 各码流最近一次切片耗时之和
+}
+
+// HLSRenditionDto 单路码流的切片状态
+type HLSRenditionDto struct {
+	Resolution string             `json:"resolution"`
+	Bitrate    string             `json:"bitrate"`
+	Status     vo.RenditionStatus `json:"status"`
+	Error      string             `json:"error,omitempty"`
+	Attempts   int                `json:"attempts"`
+	DurationMs int64              `json:"duration_ms,omitempty"` // 最近一次切片耗时
+	Segments   int                `json:"segments,omitempty"`
+	Bytes      int64              `json:"bytes,omitempty"` // 播放列表与切片总大小
+	UpdatedAt  types.Time         `json:"updated_at"`
+}
+
+func NewHLSRenditionDtos(renditions vo.HLSRenditions) []HLSRenditionDto {
+	if renditions == nil {
+		return nil
+	}
+	out := make([]HLSRenditionDto, 0, len(renditions))
+	for _, r := range renditions {
+		out = append(out, HLSRenditionDto{
+			Resolution: r.Resolution, Bitrate: r.Bitrate, Status: r.Status, Error: r.Error, Attempts: r.Attempts,
+			DurationMs: r.DurationMs, Segments: r.Segments, Bytes: r.Bytes, UpdatedAt: types.NewTime(r.UpdatedAt),
+		})
+	}
+	return out
+}
+
+func NewHLSJobDto(job *entity.HLSJobEntity) *HLSJobDto {
+	if job == nil {
+		return nil
+	}
+	renditions := job.Renditions()
+	d := &HLSJobDto{
+		JobUUID:      job.JobUUID(),
+		UserUUID:     job.UserUUID(),
+		VideoUUID:    job.VideoUUID(),
+		Status:       job.Status(),
+		Progress:     job.Progress(),
+		ErrorMessage: job.ErrorMessage(),
+		Renditions:   NewHLSRenditionDtos(renditions),
+		Stats: HLSJobStatsDto{
+			Renditions: len(renditions),
+			Completed:  renditions.CountByStatus(vo.RenditionCompleted),
+			Failed:     renditions.CountByStatus(vo.RenditionFailed),
+			Pending:    renditions.CountByStatus(vo.RenditionPending),
+		},
+		Fingerprint: job.Fingerprint(),
+		CreatedAt:   types.NewTime(job.CreatedAt()),
+		UpdatedAt:   types.NewTime(job.UpdatedAt()),
+	}
+	if src := job.SourceJobUUID(); src != nil {
+		d.SourceJobUUID = *src
+	}
+	if m := job.MasterPlaylist(); m != nil {
+		d.MasterPlaylist = *m
+	}
+	for _, r := range renditions {
+		d.Stats.Segments += r.Segments
+		d.Stats.Bytes += r.Bytes
+		d.Stats.SliceMs += r.DurationMs
+	}
+	return d
+}
+
+// HLSMasterDto 重新生成的 master playlist
+type HLSMasterDto struct {
+	JobUUID        string   `json:"job_uuid"`
+	VideoUUID      string   `json:"video_uuid"`
+	ObjectKey      string   `json:"object_key"`
+	MasterPlaylist string   `json:"master_playlist,omitempty"` // 公开 URL 或私有签名 URL
+	Resolutions    []string `json:"resolutions"`               // 收录的已完成码流
+}
@@ -0,0 +1,7 @@
+package dto
+
+// JobSubmissionDto 通用作业提交结果
+type JobSubmissionDto struct {
+	JobType string `json:"job_type"`
+	JobID   string `json:"job_id"`
+}
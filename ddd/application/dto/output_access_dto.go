@@ -0,0 +1,7 @@
+package dto
+
+// OutputAccessResultDto 访问时间回写结果；key 不对应任何产物或已有更晚的访问时间时不计入 updated
+type OutputAccessResultDto struct {
+	Received int `json:"received"`
+	Updated  int `json:"updated"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "transcode-service/ddd/application/dto/types"
+
+// OutputURLsDto 任务产物的访问地址；私有产物为签名 URL，到期前需重新获取
+type OutputURLsDto struct {
+	TaskUUID   string      `json:"task_uuid"`
+	Visibility string      `json:"visibility"`
+	URL        string      `json:"url,omitempty"`     // MP4 产物
+	HLSURL     string      `json:"hls_url,omitempty"` // HLS master playlist，切片与子播放列表按相对地址继承签名
+	ExpiresAt  *types.Time `json:"expires_at,omitempty"`
+}
+
+// 任务产物类型
+const (
+	TaskOutputVideo        = "video"
+	TaskOutputAudio        = "audio"
+	TaskOutputHLSMaster    = "hls_master"
+	TaskOutputHLSRendition = "hls_rendition"
+)
+
+// TaskOutputDto 单个产物：MP4、纯音频或 HLS 播放列表
+type TaskOutputDto struct {
+	Type       string `json:"type"`
+	Resolution string `json:"resolution,omitempty"` // 音频产物为格式@码率
+	URL        string `json:"url"`
+	Size       int64  `json:"size"` // 字节数，未知时为 0
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/vo"
+)
+
+// StaleOutputDto 需要回填的产物；fingerprint 为空表示指纹功能上线前的历史产物
+type StaleOutputDto struct {
+	ID          uint64                 `json:"id"`
+	UUID        string                 `json:"uuid"` // transcode 为 task_uuid，hls 为 job_uuid
+	UserUUID    string                 `json:"user_uuid"`
+	VideoUUID   string                 `json:"video_uuid"`
+	Output      string                 `json:"output"` // MP4 路径或 master playlist
+	Fingerprint *vo.EncoderFingerprint `json:"fingerprint,omitempty"`
+	UpdatedAt   types.Time             `json:"updated_at"`
+}
+
+// StaleOutputListDto 一页回填候选，NextAfterID 为 0 表示已到末尾
+type StaleOutputListDto struct {
+	Kind                   string           `json:"kind"`
+	CurrentSettingsVersion int              `json:"current_settings_version"`
+	Items                  []StaleOutputDto `json:"items"`
+	NextAfterID            uint64           `json:"next_after_id"`
+}
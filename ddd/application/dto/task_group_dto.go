@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+)
+
+// TaskGroupDto 任务组及组内任务的聚合进度
+type TaskGroupDto struct {
+	GroupUUID     string `json:"group_uuid"`
+	UserUUID      string `json:"user_uuid"`
+	Name          string `json:"name,omitempty"`
+	Status        string `json:"status"` // open | completed
+	ExpectedTasks int    `json:"expected_tasks,omitempty"`
+	// Progress 按状态归类的任务数，percent 为整体进度 0-100
+	Progress TaskGroupProgressDto  `json:"progress"`
+	Callback *TaskGroupCallbackDto `json:"callback,omitempty"`
+	// Tasks 组内任务的精简状态，按创建时间升序，仅详情接口返回
+	Tasks       []TaskGroupTaskDto `json:"tasks,omitempty"`
+	CreatedAt   types.Time         `json:"created_at"`
+	CompletedAt *types.Time        `json:"completed_at,omitempty"`
+}
+
+// TaskGroupProgressDto 组内任务计数；expired/rejected 计入 failed，retrying 计入 pending
+type TaskGroupProgressDto struct {
+	Total      int `json:"total"`
+	Pending    int `json:"pending"`
+	Processing int `json:"processing"`
+	Completed  int `json:"completed"`
+	Failed     int `json:"failed"`
+	Cancelled  int `json:"cancelled"`
+	Percent    int `json:"percent"`
+}
+
+// TaskGroupCallbackDto 完成回调的投递状态
+type TaskGroupCallbackDto struct {
+	URL       string `json:"url"`
+	State     string `json:"state"` // none | pending | delivered | failed
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// TaskGroupTaskDto 组内单个任务
+type TaskGroupTaskDto struct {
+	TaskUUID  string     `json:"task_uuid"`
+	VideoUUID string     `json:"video_uuid"`
+	Status    string     `json:"status"`
+	Progress  int        `json:"progress"`
+	Error     string     `json:"error,omitempty"`
+	UpdatedAt types.Time `json:"updated_at"`
+}
+
+// TaskGroupTasksDto 批量创建结果：成功的任务与按请求下标对应的失败项
+type TaskGroupTasksDto struct {
+	Group  *TaskGroupDto           `json:"group"`
+	Tasks  []*TaskResource         `json:"tasks"`
+	Errors []TaskGroupItemErrorDto `json:"errors,omitempty"`
+}
+
+// TaskGroupItemErrorDto 批量创建中失败的一项
+type TaskGroupItemErrorDto struct {
+	Index   int    `json:"index"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func NewTaskGroupDto(g *entity.TaskGroupEntity, p vo.TaskGroupProgress) *TaskGroupDto {
+	res := &TaskGroupDto{
+		GroupUUID:     g.GroupUUID(),
+		UserUUID:      g.UserUUID(),
+		Name:          g.Name(),
+		Status:        string(g.Status()),
+		ExpectedTasks: g.ExpectedTasks(),
+		Progress: TaskGroupProgressDto{
+			Total: p.Total, Pending: p.Pending, Processing: p.Processing,
+			Completed: p.Completed, Failed: p.Failed, Cancelled: p.Cancelled, Percent: p.Percent(),
+		},
+		CreatedAt:   types.NewTime(g.CreatedAt()),
+		CompletedAt: types.NewTimePtr(g.CompletedAt()),
+	}
+	if g.CallbackURL() != "" {
+		res.Callback = &TaskGroupCallbackDto{
+			URL: g.CallbackURL(), State: string(g.CallbackState()), Attempts: g.CallbackAttempts(), LastError: g.CallbackError(),
+		}
+	}
+	return res
+}
+
+func NewTaskGroupTaskDtos(tasks []*entity.TranscodeTaskEntity) []TaskGroupTaskDto {
+	out := make([]TaskGroupTaskDto, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, TaskGroupTaskDto{
+			TaskUUID:  t.TaskUUID(),
+			VideoUUID: t.VideoUUID(),
+			Status:    t.Status().String(),
+			Progress:  t.Progress(),
+			Error:     t.ErrorMessage(),
+			UpdatedAt: types.NewTime(t.UpdatedAt()),
+		})
+	}
+	return out
+}
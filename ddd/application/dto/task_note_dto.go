@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/vo"
+)
+
+// TaskNoteDto 任务备注
+type TaskNoteDto struct {
+	NoteUUID  string     `json:"note_uuid"`
+	Author    string     `json:"author"`
+	Body      string     `json:"body"`
+	CreatedAt types.Time `json:"created_at"`
+}
+
+func NewTaskNoteDtos(notes []*vo.TaskNote) []TaskNoteDto {
+	out := make([]TaskNoteDto, 0, len(notes))
+	for _, n := range notes {
+		out = append(out, NewTaskNoteDto(n))
+	}
+	return out
+}
+
+func NewTaskNoteDto(n *vo.TaskNote) TaskNoteDto {
+	return TaskNoteDto{NoteUUID: n.NoteUUID, Author: n.Author, Body: n.Body, CreatedAt: types.NewTime(n.CreatedAt)}
+}
@@ -0,0 +1,361 @@
+package dto
+
+import (
+	"time"
+
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+)
+
+// TaskResource /api/v2 转码任务资源，也是 v1 DTO 的唯一数据来源。
+// 字段名稳定；进度统一为 0-100 的整数；错误以 error.code + error.message 表达。
+type TaskResource struct {
+	TaskUUID      string `json:"task_uuid"`
+	UserUUID      string `json:"user_uuid"`
+	VideoUUID     string `json:"video_uuid"`
+	VideoPushUUID string `json:"video_push_uuid,omitempty"`
+	// ParentTaskUUID 重放任务对应的原任务，普通任务省略
+	ParentTaskUUID string `json:"parent_task_uuid,omitempty"`
+	// Status pending | processing | retrying | completed | failed | cancelled | expired | rejected
+	Status string `json:"status"`
+	// Progress 本任务（下载/编码/上传）进度
+	Progress int `json:"progress"`
+	// Lane 调度通道 standard | express（短视频快车道）
+	Lane string `json:"lane"`
+	// GroupUUID 所属任务组，未加入任务组时省略
+	GroupUUID string `json:"group_uuid,omitempty"`
+	// VideoProgress 视频整体进度（含关联 HLS 作业），按阶段权重合成
+	VideoProgress int                `json:"video_progress"`
+	Stages        []StageProgressDto `json:"stages"`
+	// VideoStatus 视频端到端状态：转码未完成时同 status；转码完成后 HLS 打包中为 packaging、
+	// HLS 失败为 hls_failed，HLS 完成或无需 HLS 时为 completed
+	VideoStatus string `json:"video_status"`
+	// HLS 关联 HLS 作业摘要，没有 HLS 作业（未完成转码、预览、重放或纯音频）时省略
+	HLS *TaskHLSResource `json:"hls,omitempty"`
+	// Timings 最近一次执行的耗时拆分，尚未开始执行时省略
+	Timings *TaskTimingResource `json:"timings,omitempty"`
+	Source  TaskSourceResource  `json:"source"`
+	Output  TaskOutputResource  `json:"output"`
+	// Labels 创建时设置的任务标签
+	Labels map[string]string `json:"labels,omitempty"`
+	// Queue 排队信息，仅 pending 状态返回
+	Queue *TaskQueueResource `json:"queue,omitempty"`
+	// Error 仅 failed/cancelled/expired/rejected 状态返回
+	Error *TaskErrorResource `json:"error,omitempty"`
+	// Commands 已执行的 ffmpeg 命令，仅 include=commands 时返回
+	Commands []FFmpegCommandDto `json:"commands,omitempty"`
+	// Notes 运维备注，按时间升序，仅详情接口返回
+	Notes     []TaskNoteDto `json:"notes,omitempty"`
+	CreatedAt types.Time    `json:"created_at"`
+	UpdatedAt types.Time    `json:"updated_at"`
+}
+
+// 视频端到端状态中 HLS 阶段的取值，其余取值同任务状态
+const (
+	VideoStatusPackaging = "packaging"
+	VideoStatusHLSFailed = "hls_failed"
+)
+
+// TaskHLSResource 关联 HLS 作业摘要，码流明细见 GET /ops/v1/admin/hls-jobs/:job_uuid
+type TaskHLSResource struct {
+	JobUUID      string `json:"job_uuid"`
+	Status       string `json:"status"`
+	Progress     int    `json:"progress"`
+	Renditions   int    `json:"renditions"`
+	Completed    int    `json:"completed"`
+	Failed       int    `json:"failed"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// ApplyHLSJob 填充 HLS 作业摘要并按作业状态修正端到端状态
+func (r *TaskResource) ApplyHLSJob(job *entity.HLSJobEntity) {
+	if job == nil {
+		return
+	}
+	renditions := job.Renditions()
+	r.HLS = &TaskHLSResource{
+		JobUUID:      job.JobUUID(),
+		Status:       job.Status(),
+		Progress:     job.Progress(),
+		Renditions:   len(renditions),
+		Completed:    renditions.CountByStatus(vo.RenditionCompleted),
+		Failed:       renditions.CountByStatus(vo.RenditionFailed),
+		ErrorMessage: job.ErrorMessage(),
+	}
+	if r.Status != vo.TaskStatusCompleted.String() {
+		return
+	}
+	switch job.Status() {
+	case vo.HLSStatusCompleted.String():
+		r.HLS.Progress = 100
+		r.VideoStatus = r.Status
+	case vo.HLSStatusFailed.String():
+		r.VideoStatus = VideoStatusHLSFailed
+	default:
+		r.VideoStatus = VideoStatusPackaging
+	}
+}
+
+// TaskSourceResource 任务输入
+type TaskSourceResource struct {
+	Path string `json:"path"`
+	// Generation 产物对应的源文件代数，重新上传源文件后递增
+	Generation int64 `json:"generation,omitempty"`
+	// VideoStreamIndex/AudioStreamIndex 创建时显式指定的输入流序号，自动选择时省略
+	VideoStreamIndex *int `json:"video_stream_index,omitempty"`
+	AudioStreamIndex *int `json:"audio_stream_index,omitempty"`
+	// Streams 探测到的源文件内容（audio_video/video_only/audio_only）及缺失流的处理方式，执行前省略
+	Streams *vo.SourceStreams `json:"streams,omitempty"`
+}
+
+// TaskOutputResource 任务产物及编码参数
+type TaskOutputResource struct {
+	Path string `json:"path"`
+	// Visibility public|private，私有产物经 GET /api/v2/tasks/:task_uuid/urls 获取签名 URL
+	Visibility string `json:"visibility"`
+	// Destination 请求指定的目标桶与前缀，使用默认桶时省略
+	Destination *vo.OutputDestination `json:"destination,omitempty"`
+	Resolution  string                `json:"resolution"`
+	Bitrate     string                `json:"bitrate"`
+	Container   string                `json:"container"`
+	// PreviewSeconds 预览任务只转码前 N 秒，完整转码时省略
+	PreviewSeconds int `json:"preview_seconds,omitempty"`
+	// Profile profile=auto 时命中的规则及其编码覆盖
+	Profile *vo.AutoProfile `json:"profile,omitempty"`
+	// Audio 纯音频产物，未请求时省略
+	Audio []AudioOutputResource `json:"audio,omitempty"`
+	// GOP 档位配置的 GOP 结构（closed_gop/forced_idr/scenecut），未配置时省略
+	GOP *vo.GOPPolicy `json:"gop,omitempty"`
+	// FormatSet/MaxRenditions 输出档位集合与 HLS 档位数上限，未使用时省略
+	FormatSet     string `json:"format_set,omitempty"`
+	MaxRenditions int    `json:"max_renditions,omitempty"`
+	// BitrateCap 按源码率封顶后的输出与 HLS 阶梯，未启用或尚未执行时省略
+	BitrateCap *vo.BitrateAdjustment `json:"bitrate_cap,omitempty"`
+	// EncoderFingerprint 产物的编码设置指纹，完成前或历史任务省略
+	EncoderFingerprint *vo.EncoderFingerprint `json:"encoder_fingerprint,omitempty"`
+	// Storage 存储层级（standard/archiving/archived/restoring）与归档位置，从未归档且无访问记录时省略
+	Storage *OutputStorageResource `json:"storage,omitempty"`
+}
+
+// OutputStorageResource 产物的存储层级与归档位置
+type OutputStorageResource struct {
+	Tier               vo.StorageTier `json:"tier"`
+	ArchivePath        string         `json:"archive_path,omitempty"`
+	StorageClass       string         `json:"storage_class,omitempty"`
+	ArchivedAt         *types.Time    `json:"archived_at,omitempty"`
+	RestoreRequestedAt *types.Time    `json:"restore_requested_at,omitempty"`
+	RestoredAt         *types.Time    `json:"restored_at,omitempty"`
+	// LastAccessedAt 由 CDN 访问日志回写，未回写时省略
+	LastAccessedAt *types.Time `json:"last_accessed_at,omitempty"`
+}
+
+func newOutputStorage(s vo.OutputStorage) *OutputStorageResource {
+	if s.IsZero() {
+		return nil
+	}
+	return &OutputStorageResource{
+		Tier:               s.Tier,
+		ArchivePath:        s.ArchivePath,
+		StorageClass:       s.StorageClass,
+		ArchivedAt:         types.NewTimePtr(s.ArchivedAt),
+		RestoreRequestedAt: types.NewTimePtr(s.RestoreRequestedAt),
+		RestoredAt:         types.NewTimePtr(s.RestoredAt),
+		LastAccessedAt:     types.NewTimePtr(s.LastAccessedAt),
+	}
+}
+
+// AudioOutputResource 纯音频产物
+type AudioOutputResource struct {
+	Format  string `json:"format"`
+	Bitrate string `json:"bitrate"`
+	Path    string `json:"path"`
+}
+
+func newAudioOutputResources(a *vo.AudioOutputs) []AudioOutputResource {
+	if a == nil {
+		return nil
+	}
+	out := make([]AudioOutputResource, 0, len(a.Renditions))
+	for _, r := range a.Renditions {
+		out = append(out, AudioOutputResource{Format: string(r.Format), Bitrate: r.Bitrate, Path: r.ObjectKey})
+	}
+	return out
+}
+
+// TaskTimingResource 耗时拆分（毫秒）：排队为创建到开始执行，阶段未结束时省略
+type TaskTimingResource struct {
+	StartedAt   *types.Time `json:"started_at,omitempty"`
+	FinishedAt  *types.Time `json:"finished_at,omitempty"`
+	QueueWaitMs *int64      `json:"queue_wait_ms,omitempty"`
+	// SourceWaitMs 等待源文件复制到位的时长，未等待时省略
+	SourceWaitMs *int64 `json:"source_wait_ms,omitempty"`
+	DownloadMs   *int64 `json:"download_ms,omitempty"`
+	EncodeMs     *int64 `json:"encode_ms,omitempty"`
+	UploadMs     *int64 `json:"upload_ms,omitempty"`
+	TotalMs      *int64 `json:"total_ms,omitempty"`
+}
+
+// NewTaskTimingResource 尚未开始执行时返回 nil
+func NewTaskTimingResource(timings vo.TaskTimings, createdAt time.Time) *TaskTimingResource {
+	if timings.IsZero() {
+		return nil
+	}
+	ms := func(d time.Duration, ok bool) *int64 {
+		if !ok {
+			return nil
+		}
+		v := d.Milliseconds()
+		return &v
+	}
+	return &TaskTimingResource{
+		StartedAt:    types.NewTimePtr(timings.StartedAt),
+		FinishedAt:   types.NewTimePtr(timings.FinishedAt),
+		QueueWaitMs:  ms(timings.QueueWait(createdAt)),
+		SourceWaitMs: ms(time.Duration(timings.SourceWaitMs)*time.Millisecond, timings.SourceWaitMs > 0),
+		DownloadMs:   ms(timings.StageDuration(vo.StageDownload)),
+		EncodeMs:     ms(timings.StageDuration(vo.StageEncode)),
+		UploadMs:     ms(timings.StageDuration(vo.StageUpload)),
+		TotalMs:      ms(timings.Total()),
+	}
+}
+
+// TaskQueueResource 排队位置与预计开始时间
+type TaskQueueResource struct {
+	Position         int         `json:"position"`
+	Priority         int         `json:"priority"`
+	EstimatedStartAt *types.Time `json:"estimated_start_at,omitempty"`
+}
+
+// TaskErrorResource 任务错误
+type TaskErrorResource struct {
+	// Code transcode_failed | task_cancelled | task_expired | input_rejected
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// 任务错误码
+const (
+	TaskErrorTranscodeFailed = "transcode_failed"
+	TaskErrorCancelled       = "task_cancelled"
+	TaskErrorExpired         = "task_expired"
+	TaskErrorInputRejected   = "input_rejected"
+)
+
+// NewTaskResource 从实体创建任务资源；阶段进度与排队信息由应用层补充
+func NewTaskResource(e *entity.TranscodeTaskEntity) *TaskResource {
+	if e == nil {
+		return nil
+	}
+	params := e.GetParams()
+	r := &TaskResource{
+		TaskUUID:       e.TaskUUID(),
+		UserUUID:       e.UserUUID(),
+		VideoUUID:      e.VideoUUID(),
+		VideoPushUUID:  e.VideoPushUUID(),
+		ParentTaskUUID: e.ParentTaskUUID(),
+		Status:         e.Status().String(),
+		Progress:       e.Progress(),
+		VideoStatus:    e.Status().String(),
+		Source: TaskSourceResource{
+			Path:             e.OriginalPath(),
+			Generation:       e.SourceGeneration(),
+			VideoStreamIndex: params.VideoStream,
+			AudioStreamIndex: params.AudioStream,
+			Streams:          e.SourceStreams(),
+		},
+		Output: TaskOutputResource{
+			Path:               e.OutputPath(),
+			Visibility:         string(e.Visibility()),
+			Destination:        outputDestination(e.OutputDestination()),
+			Resolution:         params.Resolution,
+			Bitrate:            params.Bitrate,
+			Container:          params.OutputContainer().String(),
+			PreviewSeconds:     params.PreviewSeconds,
+			Profile:            params.Profile,
+			Audio:              newAudioOutputResources(params.Audio),
+			GOP:                params.GOP,
+			FormatSet:          params.FormatSet,
+			MaxRenditions:      params.MaxRenditions,
+			BitrateCap:         e.BitrateAdjustment(),
+			EncoderFingerprint: e.Fingerprint(),
+			Storage:            newOutputStorage(e.OutputStorage()),
+		},
+		Lane:      string(e.Lane()),
+		GroupUUID: e.GroupUUID(),
+		Labels:    e.Labels(),
+		Timings:   NewTaskTimingResource(e.Timings(), e.CreatedAt()),
+		CreatedAt: types.NewTime(e.CreatedAt()),
+		UpdatedAt: types.NewTime(e.UpdatedAt()),
+	}
+	if code := taskErrorCode(e.Status()); code != "" {
+		r.Error = &TaskErrorResource{Code: code, Message: e.ErrorMessage()}
+	}
+	return r
+}
+
+func taskErrorCode(status vo.TaskStatus) string {
+	switch status {
+	case vo.TaskStatusFailed:
+		return TaskErrorTranscodeFailed
+	case vo.TaskStatusCancelled:
+		return TaskErrorCancelled
+	case vo.TaskStatusExpired:
+		return TaskErrorExpired
+	case vo.TaskStatusRejected:
+		return TaskErrorInputRejected
+	default:
+		return ""
+	}
+}
+
+// ToV1 转换为 /api/v1 兼容结构
+func (r *TaskResource) ToV1() *TranscodeTaskDto {
+	if r == nil {
+		return nil
+	}
+	d := &TranscodeTaskDto{
+		TaskUUID:      r.TaskUUID,
+		UserUUID:      r.UserUUID,
+		VideoUUID:     r.VideoUUID,
+		VideoPushUUID: r.VideoPushUUID,
+		OriginalPath:  r.Source.Path,
+		OutputPath:    r.Output.Path,
+		Status:        r.Status,
+		Progress:      float64(r.Progress),
+		CreatedAt:     r.CreatedAt,
+		UpdatedAt:     r.UpdatedAt,
+		Params: TranscodeParamsDto{
+			Resolution:     r.Output.Resolution,
+			Bitrate:        r.Output.Bitrate,
+			Container:      r.Output.Container,
+			PreviewSeconds: r.Output.PreviewSeconds,
+			AudioOutputs:   r.Output.Audio,
+		},
+		VideoProgress:    float64(r.VideoProgress),
+		Stages:           r.Stages,
+		VideoStatus:      r.VideoStatus,
+		HLS:              r.HLS,
+		Timings:          r.Timings,
+		Commands:         r.Commands,
+		Labels:           r.Labels,
+		Lane:             r.Lane,
+		SourceGeneration: r.Source.Generation,
+		ParentTaskUUID:   r.ParentTaskUUID,
+	}
+	if r.Error != nil {
+		d.ErrorMessage = r.Error.Message
+	}
+	if r.Queue != nil {
+		d.QueuePosition = r.Queue.Position
+		d.EstimatedStartAt = r.Queue.EstimatedStartAt
+	}
+	return d
+}
+
+func outputDestination(d vo.OutputDestination) *vo.OutputDestination {
+	if d.IsZero() {
+		return nil
+	}
+	return &d
+}
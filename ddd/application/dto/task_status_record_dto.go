@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/entity"
+)
+
+// TaskStatusRecord 批量对账使用的精简任务状态，每个请求的视频一条
+type TaskStatusRecord struct {
+	VideoUUID    string      `json:"video_uuid"`
+	Found        bool        `json:"found"`
+	TaskUUID     string      `json:"task_uuid,omitempty"`
+	Status       string      `json:"status,omitempty"`
+	Progress     int         `json:"progress"`
+	OutputPath   string      `json:"output_path,omitempty"`
+	ErrorMessage string      `json:"error_message,omitempty"`
+	UpdatedAt    *types.Time `json:"updated_at,omitempty"`
+}
+
+// NewTaskStatusRecord task 为 nil 时返回 found=false 的记录
+func NewTaskStatusRecord(videoUUID string, task *entity.TranscodeTaskEntity) TaskStatusRecord {
+	if task == nil {
+		return TaskStatusRecord{VideoUUID: videoUUID}
+	}
+	return TaskStatusRecord{
+		VideoUUID:    videoUUID,
+		Found:        true,
+		TaskUUID:     task.TaskUUID(),
+		Status:       task.Status().String(),
+		Progress:     task.Progress(),
+		OutputPath:   task.OutputPath(),
+		ErrorMessage: task.ErrorMessage(),
+		UpdatedAt:    types.NewTimeOrNil(task.UpdatedAt()),
+	}
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "transcode-service/ddd/domain/vo"
+
+// ThroughputReportDto 日期区间内的每日处理量，无记录的日期补 0，便于看板直接绘制
+type ThroughputReportDto struct {
+	From  string          `json:"from"`
+	To    string          `json:"to"`
+	Total ThroughputDto   `json:"total"`
+	Days  []ThroughputDto `json:"days"`
+}
+
+// ThroughputDto 单日（或区间合计）的处理量
+type ThroughputDto struct {
+	Date          string  `json:"date,omitempty"`
+	InputBytes    int64   `json:"input_bytes"`    // 源文件下载字节（源缓存命中不计）
+	OutputBytes   int64   `json:"output_bytes"`   // 产物上传字节
+	EncodeSeconds float64 `json:"encode_seconds"` // ffmpeg 编码耗时，并发作业累加
+}
+
+func NewThroughputDto(d vo.DailyThroughput) ThroughputDto {
+	return ThroughputDto{
+		Date:          d.Date,
+		InputBytes:    d.InputBytes,
+		OutputBytes:   d.OutputBytes,
+		EncodeSeconds: d.Encode.Seconds(),
+	}
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "transcode-service/ddd/domain/vo"
+
+// ThumbnailDto 截帧结果
+type ThumbnailDto struct {
+	VideoUUID   string `json:"video_uuid"`
+	ObjectKey   string `json:"object_key"`
+	URL         string `json:"url"`
+	TimestampMs int64  `json:"timestamp_ms"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Format      string `json:"format"`
+	Source      string `json:"source"`
+}
+
+func NewThumbnailDto(spec vo.ThumbnailSpec, url, source string) *ThumbnailDto {
+	return &ThumbnailDto{
+		VideoUUID:   spec.VideoUUID,
+		ObjectKey:   spec.ObjectKey,
+		URL:         url,
+		TimestampMs: spec.At.Milliseconds(),
+		Width:       spec.Width,
+		Height:      spec.Height,
+		Format:      string(spec.Format),
+		Source:      source,
+	}
+}
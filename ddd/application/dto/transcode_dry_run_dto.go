@@ -0,0 +1,46 @@
+package dto
+
+import "transcode-service/ddd/domain/service"
+
+// TranscodeDryRunDto 预演结果：将要使用的参数、产物与估算
+type TranscodeDryRunDto struct {
+	UserUUID          string                 `json:"user_uuid"`
+	VideoUUID         string                 `json:"video_uuid"`
+	Resolution        string                 `json:"resolution"`
+	Bitrate           string                 `json:"bitrate"`
+	PreferenceApplied bool                   `json:"preference_applied"`
+	ExistingTaskUUID  string                 `json:"existing_task_uuid,omitempty"` // 已有未完成任务时，真实请求会直接返回该任务
+	Profile           DryRunProfileDto       `json:"profile"`
+	Output            DryRunOutputDto        `json:"output"`
+	LadderSource      string                 `json:"ladder_source"`
+	Renditions        []DryRunRenditionDto   `json:"renditions"`
+	Estimate          service.EncodeEstimate `json:"estimate"`
+	Notes             []string               `json:"notes,omitempty"`
+}
+
+// DryRunProfileDto 生效的编码配置
+type DryRunProfileDto struct {
+	Binary            string `json:"binary"`
+	VideoCodec        string `json:"video_codec"`
+	VideoPreset       string `json:"video_preset"`
+	HardwareAccel     string `json:"hardware_accel,omitempty"`
+	UseHardwareDecode bool   `json:"use_hardware_decode"`
+	Threads           int    `json:"threads"`
+	AutoRule          string `json:"auto_rule,omitempty"` // profile=auto 命中的规则
+}
+
+// DryRunOutputDto MP4 产物
+type DryRunOutputDto struct {
+	ObjectKey   string `json:"object_key"`
+	ContentType string `json:"content_type"`
+	SkipUpload  bool   `json:"skip_upload"`
+}
+
+// DryRunRenditionDto 单个产物及其 ffmpeg 参数
+type DryRunRenditionDto struct {
+	Kind       string   `json:"kind"` // mp4 | hls
+	Resolution string   `json:"resolution"`
+	Bitrate    string   `json:"bitrate"`
+	Playlist   string   `json:"playlist,omitempty"`
+	FFmpegArgs []string `json:"ffmpeg_args"`
+}
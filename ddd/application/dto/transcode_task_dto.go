@@ -1,11 +1,12 @@
 package dto
 
 import (
-	"time"
+	"transcode-service/ddd/application/dto/types"
 	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
 )
 
-// TranscodeTaskDto 转码任务数据传输对象
+// TranscodeTaskDto /api/v1 转码任务数据传输对象，兼容保留，新接口使用 TaskResource
 type TranscodeTaskDto struct {
 	TaskUUID      string             `json:"task_uuid"`
 	UserUUID      string             `json:"user_uuid"`
@@ -16,9 +17,30 @@ type TranscodeTaskDto struct {
 	Status        string             `json:"status"`
 	Progress      float64            `json:"progress"`
 	ErrorMessage  string             `json:"error_message,omitempty"`
-	CreatedAt     time.Time          `json:"created_at"`
-	UpdatedAt     time.Time          `json:"updated_at"`
+	CreatedAt     types.Time         `json:"created_at"`
+	UpdatedAt     types.Time         `json:"updated_at"`
 	Params        TranscodeParamsDto `json:"params"`
+	// 视频整体进度（下载/编码/上传/HLS 按权重合成）及各阶段明细
+	VideoProgress float64            `json:"video_progress"`
+	Stages        []StageProgressDto `json:"stages,omitempty"`
+	// 视频端到端状态与关联 HLS 作业摘要，含义同 v2 任务资源
+	VideoStatus string           `json:"video_status,omitempty"`
+	HLS         *TaskHLSResource `json:"hls,omitempty"`
+	// 最近一次执行的耗时拆分（排队/下载/编码/上传）
+	Timings *TaskTimingResource `json:"timings,omitempty"`
+	// 排队信息，仅 pending 状态下有值
+	QueuePosition    int         `json:"queue_position,omitempty"`
+	EstimatedStartAt *types.Time `json:"estimated_start_at,omitempty"`
+	// 已执行的 ffmpeg 命令，仅 include=commands 时返回
+	Commands []FFmpegCommandDto `json:"commands,omitempty"`
+	// 任务标签
+	Labels map[string]string `json:"labels,omitempty"`
+	// 产物对应的源文件代数
+	SourceGeneration int64 `json:"source_generation,omitempty"`
+	// 重放任务对应的原任务
+	ParentTaskUUID string `json:"parent_task_uuid,omitempty"`
+	// 调度通道 standard/express
+	Lane string `json:"lane,omitempty"`
 	// 已拆分，HLS配置不再包含在转码任务DTO中
 }
 
@@ -28,6 +50,59 @@ type TranscodeTaskDto struct {
 type TranscodeParamsDto struct {
 	Resolution string `json:"resolution"`
 	Bitrate    string `json:"bitrate"`
+	Container  string `json:"container"`
+	// PreviewSeconds 预览任务只转码前 N 秒
+	PreviewSeconds int `json:"preview_seconds,omitempty"`
+	// AudioOutputs 纯音频产物
+	AudioOutputs []AudioOutputResource `json:"audio_outputs,omitempty"`
+}
+
+// StageProgressDto 流水线阶段进度
+type StageProgressDto struct {
+	Stage    string `json:"stage"`
+	Weight   int    `json:"weight"`
+	Progress int    `json:"progress"`
+}
+
+// NewStageProgressDtos 按阶段顺序生成阶段进度列表
+func NewStageProgressDtos(stages vo.StageProgress) []StageProgressDto {
+	out := make([]StageProgressDto, 0, len(vo.PipelineStages))
+	for _, stage := range vo.PipelineStages {
+		out = append(out, StageProgressDto{
+			Stage:    stage.String(),
+			Weight:   stage.Weight(),
+			Progress: stages.Get(stage),
+		})
+	}
+	return out
+}
+
+// FFmpegCommandDto 已执行的 ffmpeg 命令
+type FFmpegCommandDto struct {
+	Source      string     `json:"source"` // transcode | hls
+	JobUUID     string     `json:"job_uuid"`
+	Label       string     `json:"label"`
+	Binary      string     `json:"binary"`
+	Args        []string   `json:"args"`
+	CommandLine string     `json:"command_line"`
+	RecordedAt  types.Time `json:"recorded_at"`
+}
+
+// NewFFmpegCommandDtos 转换命令列表
+func NewFFmpegCommandDtos(source, jobUUID string, cmds vo.FFmpegCommands) []FFmpegCommandDto {
+	out := make([]FFmpegCommandDto, 0, len(cmds))
+	for _, c := range cmds {
+		out = append(out, FFmpegCommandDto{
+			Source:      source,
+			JobUUID:     jobUUID,
+			Label:       c.Label,
+			Binary:      c.Binary,
+			Args:        c.Args,
+			CommandLine: c.CommandLine(),
+			RecordedAt:  types.NewTime(c.RecordedAt),
+		})
+	}
+	return out
 }
 
 // TranscodeTaskListDto 转码任务列表数据传输对象
@@ -56,33 +131,9 @@ type UpdateTranscodeTaskStatusDTO struct {
 // TranscodeTaskDTO 转码任务DTO（别名）
 type TranscodeTaskDTO = TranscodeTaskDto
 
-// NewTranscodeTaskDto 从实体创建DTO
+// NewTranscodeTaskDto 从实体创建 v1 DTO，字段由 v2 任务资源转换而来
 func NewTranscodeTaskDto(entity *entity.TranscodeTaskEntity) *TranscodeTaskDto {
-	if entity == nil {
-		return nil
-	}
-
-	dto := &TranscodeTaskDto{
-		TaskUUID:      entity.TaskUUID(),
-		UserUUID:      entity.UserUUID(),
-		VideoUUID:     entity.VideoUUID(),
-		VideoPushUUID: entity.VideoPushUUID(),
-		OriginalPath:  entity.OriginalPath(),
-		OutputPath:    entity.OutputPath(),
-		Status:        entity.Status().String(),
-		Progress:      float64(entity.Progress()),
-		ErrorMessage:  entity.ErrorMessage(),
-		CreatedAt:     entity.CreatedAt(),
-		UpdatedAt:     entity.UpdatedAt(),
-		Params: TranscodeParamsDto{
-			Resolution: entity.GetParams().Resolution,
-			Bitrate:    entity.GetParams().Bitrate,
-		},
-	}
-
-	// 已拆分：不在转码任务DTO中携带HLS配置
-
-	return dto
+	return NewTaskResource(entity).ToV1()
 }
 
 // NewTranscodeTaskListDto 创建任务列表DTO
@@ -0,0 +1,65 @@
+// Package types API 响应中共用的值类型，统一对外的 JSON 表示
+package types
+
+import (
+	"bytes"
+	"sync/atomic"
+	"time"
+)
+
+// TimeLayout API 时间戳格式：RFC3339，UTC，毫秒精度
+const TimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+var legacyTimeFormat atomic.Bool
+
+// SetLegacyTimeFormat 开启后按旧格式输出（time.Time 默认编码，带服务器本地时区偏移与纳秒），供客户端迁移期间使用
+func SetLegacyTimeFormat(on bool) {
+	legacyTimeFormat.Store(on)
+}
+
+// Time API 时间戳，JSON 统一输出为 UTC 的 RFC3339（如 2026-01-02T03:04:05.678Z），零值输出 null；
+// 解析时接受任意时区偏移的 RFC3339
+type Time struct {
+	time.Time
+}
+
+// NewTime 包装 time.Time
+func NewTime(t time.Time) Time {
+	return Time{Time: t}
+}
+
+// NewTimePtr nil 或零值返回 nil，配合 omitempty 省略字段
+func NewTimePtr(t *time.Time) *Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return &Time{Time: *t}
+}
+
+// NewTimeOrNil 零值返回 nil
+func NewTimeOrNil(t time.Time) *Time {
+	return NewTimePtr(&t)
+}
+
+func (t Time) MarshalJSON() ([]byte, error) {
+	if legacyTimeFormat.Load() {
+		return t.Time.MarshalJSON()
+	}
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + t.UTC().Format(TimeLayout) + `"`), nil
+}
+
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		t.Time = time.Time{}
+		return nil
+	}
+	return t.Time.UnmarshalJSON(data)
+}
+
+// OpenAPIStringFormat 文档中按 string/date-time 描述
+func (Time) OpenAPIStringFormat() string {
+	return "date-time"
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+)
+
+// UserPreferenceDto 用户默认转码偏好
+type UserPreferenceDto struct {
+	UserUUID         string                `json:"user_uuid"`
+	Ladder           []vo.ResolutionConfig `json:"ladder"`
+	Watermark        *vo.Watermark         `json:"watermark,omitempty"`
+	SubtitleLanguage string                `json:"subtitle_language,omitempty"`
+	UpdatedAt        types.Time            `json:"updated_at"`
+}
+
+func NewUserPreferenceDto(e *entity.UserPreferenceEntity) *UserPreferenceDto {
+	if e == nil {
+		return nil
+	}
+	ladder := e.Ladder()
+	if ladder == nil {
+		ladder = []vo.ResolutionConfig{}
+	}
+	return &UserPreferenceDto{
+		UserUUID:         e.UserUUID(),
+		Ladder:           ladder,
+		Watermark:        e.Watermark(),
+		SubtitleLanguage: e.SubtitleLanguage(),
+		UpdatedAt:        types.NewTime(e.UpdatedAt()),
+	}
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/ddd/domain/entity"
+)
+
+// VideoProcessingDto 视频维度聚合状态
+type VideoProcessingDto struct {
+	VideoUUID    string             `json:"video_uuid"`
+	UserUUID     string             `json:"user_uuid"`
+	State        string             `json:"state"`
+	PlaybackURL  string             `json:"playback_url,omitempty"`
+	ErrorMessage string             `json:"error_message,omitempty"`
+	Jobs         []VideoChildJobDto `json:"jobs"`
+}
+
+// VideoChildJobDto 视频下的子作业
+type VideoChildJobDto struct {
+	Kind         string     `json:"kind"`
+	JobUUID      string     `json:"job_uuid"`
+	Status       string     `json:"status"`
+	Progress     int        `json:"progress"`
+	OutputPath   string     `json:"output_path,omitempty"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	UpdatedAt    types.Time `json:"updated_at"`
+	// Renditions HLS 作业各路码流状态与失败原因
+	Renditions []HLSRenditionDto `json:"renditions,omitempty"`
+}
+
+// NewVideoProcessingDto 从聚合创建DTO
+func NewVideoProcessingDto(agg *entity.VideoProcessing) *VideoProcessingDto {
+	if agg == nil {
+		return nil
+	}
+	jobs := make([]VideoChildJobDto, 0, len(agg.Children()))
+	for _, c := range agg.Children() {
+		jobs = append(jobs, VideoChildJobDto{
+			Kind:         c.Kind,
+			JobUUID:      c.JobUUID,
+			Status:       c.Status,
+			Progress:     c.Progress,
+			OutputPath:   c.OutputPath,
+			ErrorMessage: c.ErrorMessage,
+			UpdatedAt:    types.NewTime(c.UpdatedAt),
+			Renditions:   NewHLSRenditionDtos(c.Renditions),
+		})
+	}
+	return &VideoProcessingDto{
+		VideoUUID:    agg.VideoUUID(),
+		UserUUID:     agg.UserUUID(),
+		State:        agg.State().String(),
+		PlaybackURL:  agg.PlaybackURL(),
+		ErrorMessage: agg.FirstError(),
+		Jobs:         jobs,
+	}
+}
@@ -0,0 +1,112 @@
+package dto
+
+import (
+	"transcode-service/ddd/application/dto/types"
+	"transcode-service/pkg/sysinfo"
+)
+
+// WorkerListDto 存活实例（Redis）与近期有执行记录的实例（MySQL）合并后的 worker 列表
+type WorkerListDto struct {
+	PresenceAvailable bool        `json:"presence_available"` // Redis 不可用时为 false，只能给出执行记录
+	Live              int         `json:"live"`
+	Slots             int         `json:"slots"`   // 存活实例的转码并发合计
+	Running           int         `json:"running"` // 存活实例正在执行的转码任务合计
+	Workers           []WorkerDto `json:"workers"`
+}
+
+// WorkerDto 单个实例；worker_id 为 worker_id@hostname，与执行记录一致
+type WorkerDto struct {
+	WorkerID         string         `json:"worker_id"`
+	Live             bool           `json:"live"`
+	Hostname         string         `json:"hostname,omitempty"`
+	StartedAt        *types.Time    `json:"started_at,omitempty"`
+	LastSeenAt       *types.Time    `json:"last_seen_at,omitempty"` // 最近一次存活写入
+	Slots            int            `json:"slots"`
+	HLSSlots         int            `json:"hls_slots"`
+	Running          int            `json:"running"`
+	QueueDepth       int            `json:"queue_depth"`
+	GPUs             []WorkerGPUDto `json:"gpus,omitempty"`
+	RecentTasks      int            `json:"recent_tasks"`                 // history 窗口内的执行记录数
+	LastAssignmentAt *types.Time    `json:"last_assignment_at,omitempty"` // 最近一次执行结束时间
+}
+
+// worker 健康状态
+const (
+	WorkerHealthy   = "healthy"
+	WorkerDegraded  = "degraded"  // 心跳正常但近期任务连续失败
+	WorkerUnhealthy = "unhealthy" // 存活键仍在但心跳滞后，进程可能卡住
+	WorkerOffline   = "offline"   // 没有存活键，只有执行记录
+)
+
+// WorkerDetailDto 单个实例详情
+type WorkerDetailDto struct {
+	WorkerDto
+	Capabilities   []string         `json:"capabilities,omitempty"`
+	ActiveTasks    []string         `json:"active_tasks,omitempty"`
+	LastTask       *WorkerTaskDto   `json:"last_task,omitempty"`
+	RecentFailures int              `json:"recent_failures"` // history 窗口内失败的执行记录数
+	Health         *WorkerHealthDto `json:"health"`
+}
+
+// WorkerTaskDto 最近一次执行记录
+type WorkerTaskDto struct {
+	TaskUUID   string     `json:"task_uuid"`
+	JobType    string     `json:"job_type"`
+	Attempt    int        `json:"attempt"`
+	Outcome    string     `json:"outcome"`
+	StartedAt  types.Time `json:"started_at"`
+	FinishedAt types.Time `json:"finished_at"`
+}
+
+// WorkerHealthDto 由心跳间隔与近期执行结果推导的健康状态
+type WorkerHealthDto struct {
+	WorkerID            string     `json:"worker_id"`
+	Status              string     `json:"status"`
+	PresenceAvailable   bool       `json:"presence_available"`
+	HeartbeatAgeSeconds *float64   `json:"heartbeat_age_seconds,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Reasons             []string   `json:"reasons,omitempty"`
+	CheckedAt           types.Time `json:"checked_at"`
+}
+
+// WorkerDeletionDto 删除 worker 的结果
+type WorkerDeletionDto struct {
+	WorkerID        string     `json:"worker_id"`
+	WasLive         bool       `json:"was_live"`
+	Reassigned      []string   `json:"reassigned"` // 置回 pending 并在本实例重新排队的任务
+	Cancelled       []string   `json:"cancelled"`  // force 时取消的任务
+	Skipped         []string   `json:"skipped"`    // 已结束或等待重试，无需处理的任务
+	Failed          []string   `json:"failed"`     // 处理失败的任务，需人工跟进
+	PresenceRemoved bool       `json:"presence_removed"`
+	SnapshotRemoved bool       `json:"snapshot_removed"`
+	DeletedAt       types.Time `json:"deleted_at"`
+}
+
+// WorkerGPUDto 实例心跳中携带的单块 GPU 计数器
+type WorkerGPUDto struct {
+	Index              int      `json:"index"`
+	UUID               string   `json:"uuid"`
+	Name               string   `json:"name"`
+	UtilizationPercent float64  `json:"utilization_percent"`
+	MemoryUsedBytes    int64    `json:"memory_used_bytes"`
+	MemoryTotalBytes   int64    `json:"memory_total_bytes"`
+	EncoderPercent     *float64 `json:"encoder_percent,omitempty"`
+	DecoderPercent     *float64 `json:"decoder_percent,omitempty"`
+	TemperatureC       *float64 `json:"temperature_c,omitempty"`
+}
+
+// NewWorkerGPUDtos 转换心跳中的 GPU 计数器
+func NewWorkerGPUDtos(stats []sysinfo.GPUStat) []WorkerGPUDto {
+	if len(stats) == 0 {
+		return nil
+	}
+	res := make([]WorkerGPUDto, 0, len(stats))
+	for _, g := range stats {
+		res = append(res, WorkerGPUDto{
+			Index: g.Index, UUID: g.UUID, Name: g.Name, UtilizationPercent: g.UtilizationPercent,
+			MemoryUsedBytes: g.MemoryUsedBytes, MemoryTotalBytes: g.MemoryTotalBytes,
+			EncoderPercent: g.EncoderPercent, DecoderPercent: g.DecoderPercent, TemperatureC: g.TemperatureC,
+		})
+	}
+	return res
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "transcode-service/ddd/application/dto/types"
+
+// WorkerUtilizationReportDto 各 worker 在时间窗口内的编码槽位利用率
+type WorkerUtilizationReportDto struct {
+	From      types.Time             `json:"from"`
+	To        types.Time             `json:"to"`
+	Bucket    string                 `json:"bucket"`
+	Truncated bool                   `json:"truncated"` // 记录数超过查询上限，结果只覆盖部分执行
+	Workers   []WorkerUtilizationDto `json:"workers"`
+}
+
+// WorkerUtilizationDto 单个 worker 的汇总与分桶明细
+type WorkerUtilizationDto struct {
+	WorkerID        string                 `json:"worker_id"`
+	SlotCapacity    int                    `json:"slot_capacity"`
+	Tasks           int                    `json:"tasks"`
+	Succeeded       int                    `json:"succeeded"`
+	Failed          int                    `json:"failed"`
+	Cancelled       int                    `json:"cancelled"`
+	BusySlotSeconds float64                `json:"busy_slot_seconds"` // 占用槽位数 × 执行秒数
+	Utilization     float64                `json:"utilization"`       // busy_slot_seconds / (窗口秒数 × slot_capacity)
+	AvgWaitSeconds  float64                `json:"avg_wait_seconds"`  // 平均等待编码槽位时长
+	JobTypes        map[string]int         `json:"job_types"`
+	Buckets         []UtilizationBucketDto `json:"buckets"`
+}
+
+// UtilizationBucketDto 单个时间桶内的利用率，tasks 按开始时间归入
+type UtilizationBucketDto struct {
+	Start           types.Time `json:"start"`
+	Tasks           int        `json:"tasks"`
+	BusySlotSeconds float64    `json:"busy_slot_seconds"`
+	Utilization     float64    `json:"utilization"`
+}
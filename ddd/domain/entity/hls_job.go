@@ -3,6 +3,7 @@ package entity
 import (
 	"time"
 	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
 )
 
 type HLSJobEntity struct {
@@ -22,10 +23,15 @@ type HLSJobEntity struct {
 	createdAt      time.Time
 	updatedAt      time.Time
 	requestID      string
+	commands       vo.FFmpegCommands
+	renditions     vo.HLSRenditions
+	fingerprint    *vo.EncoderFingerprint
+	privateToken   string
+	destination    vo.OutputDestination
 }
 
 func NewHLSJobEntity(jobUUID, userUUID, videoUUID, inputPath, outputDir string, cfg vo.HLSConfig) *HLSJobEntity {
-	now := time.Now()
+	now := clock.Now()
 	return &HLSJobEntity{jobUUID: jobUUID, userUUID: userUUID, videoUUID: videoUUID, inputPath: inputPath, outputDir: outputDir, config: cfg, status: vo.HLSStatusPending.String(), progress: 0, createdAt: now, updatedAt: now}
 }
 
@@ -44,10 +50,11 @@ func (e *HLSJobEntity) CreatedAt() time.Time     { return e.createdAt }
 func (e *HLSJobEntity) UpdatedAt() time.Time     { return e.updatedAt }
 func (e *HLSJobEntity) GetConfig() *vo.HLSConfig { return &e.config }
 func (e *HLSJobEntity) RequestID() string        { return e.requestID }
+func (e *HLSJobEntity) ErrorMessage() string     { return e.errorMessage }
 
 func (e *HLSJobEntity) SetStatus(status vo.HLSStatus) {
 	e.status = status.String()
-	e.updatedAt = time.Now()
+	e.updatedAt = clock.Now()
 }
 func (e *HLSJobEntity) SetProgress(p int) {
 	if p < 0 {
@@ -57,24 +64,127 @@ func (e *HLSJobEntity) SetProgress(p int) {
 		p = 100
 	}
 	e.progress = p
-	e.updatedAt = time.Now()
+	e.updatedAt = clock.Now()
 }
 func (e *HLSJobEntity) SetInputPath(path string) {
 	e.inputPath = path
-	e.updatedAt = time.Now()
+	e.updatedAt = clock.Now()
 }
 func (e *HLSJobEntity) SetMasterPlaylist(path string) {
 	e.masterPlaylist = &path
-	e.updatedAt = time.Now()
+	e.updatedAt = clock.Now()
 }
-func (e *HLSJobEntity) SetOutputDir(dir string) { e.outputDir = dir; e.updatedAt = time.Now() }
-func (e *HLSJobEntity) SetError(msg string)     { e.errorMessage = msg; e.updatedAt = time.Now() }
+func (e *HLSJobEntity) SetOutputDir(dir string) { e.outputDir = dir; e.updatedAt = clock.Now() }
+func (e *HLSJobEntity) SetError(msg string)     { e.errorMessage = msg; e.updatedAt = clock.Now() }
 func (e *HLSJobEntity) SetSource(jobUUID *string, sourceType string) {
 	e.sourceJobUUID = jobUUID
 	e.sourceType = sourceType
-	e.updatedAt = time.Now()
+	e.updatedAt = clock.Now()
+}
+
+// Restore 用于持久化还原主键与时间戳
+func (e *HLSJobEntity) Restore(id uint64, status string, createdAt, updatedAt time.Time) {
+	e.id = id
+	e.status = status
+	e.createdAt = createdAt
+	e.updatedAt = updatedAt
 }
 
 func (e *HLSJobEntity) SetRequestID(requestID string) {
 	e.requestID = requestID
 }
+
+// Commands 获取已执行的 ffmpeg 命令
+func (e *HLSJobEntity) Commands() vo.FFmpegCommands { return e.commands }
+
+// RecordCommand 记录一次 ffmpeg 命令，同 label 覆盖
+func (e *HLSJobEntity) RecordCommand(cmd vo.FFmpegCommand) {
+	e.commands = e.commands.Upsert(cmd)
+}
+
+// SetCommands 设置全部命令（用于持久化还原）
+func (e *HLSJobEntity) SetCommands(commands vo.FFmpegCommands) { e.commands = commands }
+
+// Fingerprint 产物的编码设置指纹，未完成或历史作业为 nil
+func (e *HLSJobEntity) Fingerprint() *vo.EncoderFingerprint { return e.fingerprint }
+
+// SetFingerprint 设置编码设置指纹
+func (e *HLSJobEntity) SetFingerprint(fp *vo.EncoderFingerprint) { e.fingerprint = fp }
+
+// PrivateToken 私有产物的令牌目录，随源任务；公开作业为空
+func (e *HLSJobEntity) PrivateToken() string { return e.privateToken }
+
+// SetPrivateToken 设置令牌目录
+func (e *HLSJobEntity) SetPrivateToken(token string) { e.privateToken = token }
+
+// IsPrivate 切片是否发布到私有前缀，只能签名访问
+func (e *HLSJobEntity) IsPrivate() bool { return e.privateToken != "" }
+
+// OutputDestination 切片的目标桶与前缀，随源任务；零值为默认桶
+func (e *HLSJobEntity) OutputDestination() vo.OutputDestination { return e.destination }
+
+// SetOutputDestination 设置目标桶与前缀
+func (e *HLSJobEntity) SetOutputDestination(d vo.OutputDestination) { e.destination = d }
+
+// Renditions 各路码流状态；历史作业未记录时按配置阶梯视为 pending
+func (e *HLSJobEntity) Renditions() vo.HLSRenditions {
+	if len(e.renditions) == 0 {
+		e.renditions = vo.NewHLSRenditions(e.config.Resolutions)
+	}
+	return e.renditions
+}
+
+// SetRenditions 设置全部码流状态（用于持久化还原）
+func (e *HLSJobEntity) SetRenditions(renditions vo.HLSRenditions) { e.renditions = renditions }
+
+// MarkRendition 记录单路码流一次切片的结果，失败时 err 非空
+func (e *HLSJobEntity) MarkRendition(resolution string, took time.Duration, segments int, bytes int64, err error) {
+	r := e.Renditions().Find(resolution)
+	if r == nil {
+		return
+	}
+	r.Attempts++
+	r.DurationMs = took.Milliseconds()
+	r.UpdatedAt = clock.Now()
+	if err != nil {
+		r.Status = vo.RenditionFailed
+		r.Error = err.Error()
+		return
+	}
+	r.Status, r.Error, r.Segments, r.Bytes = vo.RenditionCompleted, "", segments, bytes
+}
+
+// SetRenditionPlaylist 记录码流实际生成的播放列表文件名，命名模板变更后 master 仍引用已上传的文件
+func (e *HLSJobEntity) SetRenditionPlaylist(resolution, playlist string) {
+	if r := e.Renditions().Find(resolution); r != nil {
+		r.Playlist = playlist
+	}
+}
+
+// ResetRenditions 全部码流重置为 pending（作业中断时本地产物未上传，需整体重切）
+func (e *HLSJobEntity) ResetRenditions() {
+	rs := e.Renditions()
+	for i := range rs {
+		rs[i].Status, rs[i].Error = vo.RenditionPending, ""
+		rs[i].UpdatedAt = clock.Now()
+	}
+}
+
+// ResetFailedRenditions 失败码流重置为 pending 以便只重试失败部分，返回重置数量
+func (e *HLSJobEntity) ResetFailedRenditions() int {
+	n := 0
+	rs := e.Renditions()
+	for i := range rs {
+		if rs[i].Status == vo.RenditionFailed {
+			rs[i].Status = vo.RenditionPending
+			rs[i].UpdatedAt = clock.Now()
+			n++
+		}
+	}
+	if n > 0 {
+		e.status = vo.HLSStatusPending.String()
+		e.errorMessage = ""
+		e.updatedAt = clock.Now()
+	}
+	return n
+}
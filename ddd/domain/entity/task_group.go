@@ -0,0 +1,130 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+)
+
+// maxCallbackErrorLen 保存的回调错误最大字节数
+const maxCallbackErrorLen = 512
+
+// TaskGroupEntity 任务组：一季多集等批量上传共用一个句柄，聚合查询进度，组内任务全部结束后触发一次完成回调
+type TaskGroupEntity struct {
+	groupUUID        string
+	userUUID         string
+	name             string
+	expectedTasks    int // 预期任务数，大于 0 时组内任务数达到该值才会完成，避免陆续加入期间提前完成
+	callbackURL      string
+	status           vo.TaskGroupStatus
+	callbackState    vo.GroupCallbackState
+	callbackAttempts int
+	callbackError    string
+	completedAt      *time.Time
+	batchUntil       *time.Time // 批量创建进行中，该时间前不会完成，避免只创建了部分任务时提前完成
+	createdAt        time.Time
+	updatedAt        time.Time
+}
+
+// NewTaskGroupEntity 创建 open 状态的任务组
+func NewTaskGroupEntity(groupUUID, userUUID, name, callbackURL string, expectedTasks int) *TaskGroupEntity {
+	now := clock.Now()
+	return &TaskGroupEntity{
+		groupUUID:     groupUUID,
+		userUUID:      userUUID,
+		name:          name,
+		expectedTasks: expectedTasks,
+		callbackURL:   callbackURL,
+		status:        vo.TaskGroupOpen,
+		callbackState: vo.GroupCallbackNone,
+		createdAt:     now,
+		updatedAt:     now,
+	}
+}
+
+// RestoreTaskGroup 从持久化数据恢复
+func RestoreTaskGroup(groupUUID, userUUID, name, callbackURL string, expectedTasks int, status vo.TaskGroupStatus,
+	callbackState vo.GroupCallbackState, callbackAttempts int, callbackError string, completedAt, batchUntil *time.Time, createdAt, updatedAt time.Time) *TaskGroupEntity {
+	return &TaskGroupEntity{
+		groupUUID:        groupUUID,
+		userUUID:         userUUID,
+		name:             name,
+		expectedTasks:    expectedTasks,
+		callbackURL:      callbackURL,
+		status:           status,
+		callbackState:    callbackState,
+		callbackAttempts: callbackAttempts,
+		callbackError:    callbackError,
+		completedAt:      completedAt,
+		batchUntil:       batchUntil,
+		createdAt:        createdAt,
+		updatedAt:        updatedAt,
+	}
+}
+
+func (g *TaskGroupEntity) GroupUUID() string                    { return g.groupUUID }
+func (g *TaskGroupEntity) UserUUID() string                     { return g.userUUID }
+func (g *TaskGroupEntity) Name() string                         { return g.name }
+func (g *TaskGroupEntity) ExpectedTasks() int                   { return g.expectedTasks }
+func (g *TaskGroupEntity) CallbackURL() string                  { return g.callbackURL }
+func (g *TaskGroupEntity) Status() vo.TaskGroupStatus           { return g.status }
+func (g *TaskGroupEntity) CallbackState() vo.GroupCallbackState { return g.callbackState }
+func (g *TaskGroupEntity) CallbackAttempts() int                { return g.callbackAttempts }
+func (g *TaskGroupEntity) CallbackError() string                { return g.callbackError }
+func (g *TaskGroupEntity) CompletedAt() *time.Time              { return g.completedAt }
+func (g *TaskGroupEntity) BatchUntil() *time.Time               { return g.batchUntil }
+func (g *TaskGroupEntity) CreatedAt() time.Time                 { return g.createdAt }
+func (g *TaskGroupEntity) UpdatedAt() time.Time                 { return g.updatedAt }
+func (g *TaskGroupEntity) IsOpen() bool                         { return g.status == vo.TaskGroupOpen }
+
+// ReadyToComplete 组仍为 open、没有进行中的批量创建、组内任务全部结束且达到预期任务数
+func (g *TaskGroupEntity) ReadyToComplete(p vo.TaskGroupProgress) bool {
+	if g.batchUntil != nil && clock.Now().Before(*g.batchUntil) {
+		return false
+	}
+	return g.IsOpen() && p.AllFinished() && (g.expectedTasks <= 0 || p.Total >= g.expectedTasks)
+}
+
+// Complete 标记完成；配置了回调地址时回调进入 pending
+func (g *TaskGroupEntity) Complete(at time.Time) {
+	g.status = vo.TaskGroupCompleted
+	g.completedAt = &at
+	g.updatedAt = at
+	if g.callbackURL != "" {
+		g.callbackState = vo.GroupCallbackPending
+	}
+}
+
+// ClaimCallback 认领回调投递，pending 变为 delivering
+func (g *TaskGroupEntity) ClaimCallback() {
+	g.callbackState = vo.GroupCallbackDelivering
+	g.updatedAt = clock.Now()
+}
+
+// ReleaseCallback 认领后未投递，退回 pending
+func (g *TaskGroupEntity) ReleaseCallback() {
+	g.callbackState = vo.GroupCallbackPending
+	g.updatedAt = clock.Now()
+}
+
+// RecordCallbackAttempt 记录一次回调投递结果，失败未达到 maxAttempts 次时退回 pending 等待重试
+func (g *TaskGroupEntity) RecordCallbackAttempt(err error, maxAttempts int) {
+	g.callbackAttempts++
+	g.updatedAt = clock.Now()
+	if err == nil {
+		g.callbackState = vo.GroupCallbackDelivered
+		g.callbackError = ""
+		return
+	}
+	g.callbackError = err.Error()
+	if len(g.callbackError) > maxCallbackErrorLen {
+		g.callbackError = strings.ToValidUTF8(g.callbackError[:maxCallbackErrorLen], "")
+	}
+	if maxAttempts > 0 && g.callbackAttempts >= maxAttempts {
+		g.callbackState = vo.GroupCallbackFailed
+		return
+	}
+	g.callbackState = vo.GroupCallbackPending
+}
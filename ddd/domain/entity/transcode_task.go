@@ -4,11 +4,25 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/google/uuid"
-
+	"transcode-service/ddd/domain/event"
 	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
 )
 
+// DefaultTaskPriority 任务默认优先级
+const DefaultTaskPriority = 5
+
+// 优先级取值范围，数值越大越先执行
+const (
+	MinTaskPriority = 1
+	MaxTaskPriority = 10
+)
+
+// ValidTaskPriority 优先级是否在允许范围内
+func ValidTaskPriority(priority int) bool {
+	return priority >= MinTaskPriority && priority <= MaxTaskPriority
+}
+
 // TranscodeTaskEntity 转码任务实体
 type TranscodeTaskEntity struct {
 	id            uint64 // 数据库主键ID
@@ -22,15 +36,47 @@ type TranscodeTaskEntity struct {
 	progress      int
 	errorMessage  string
 	params        vo.TranscodeParams
-	createdAt     time.Time
-	updatedAt     time.Time
+	stages        vo.StageProgress
+	timings       vo.TaskTimings
+	commands      vo.FFmpegCommands
+	labels        vo.TaskLabels
+	// bitrateAdjustment 按源码率封顶后的输出阶梯，未封顶时为 nil
+	bitrateAdjustment *vo.BitrateAdjustment
+	// fingerprint 产物的编码设置指纹，完成时记录
+	fingerprint *vo.EncoderFingerprint
+	// sourceStreams 执行阶段探测到的源文件内容，未探测时为 nil
+	sourceStreams *vo.SourceStreams
+	// resourceTrace 编码期间的进程资源时间序列，只随查询还原，执行中由采样回调直接持久化
+	resourceTrace *vo.ResourceTrace
+	// outputStorage 产物的存储层级与归档位置
+	outputStorage vo.OutputStorage
+	// sourceGeneration 源文件代数，用户为同一视频重新上传源文件时递增
+	sourceGeneration int64
+	// parentTaskUUID 重放任务对应的原任务，普通任务为空
+	parentTaskUUID string
+	// idempotencyKey 创建请求的幂等键（如 Kafka 消息位置），重复投递时据此返回已有任务
+	idempotencyKey string
+	// privateToken 私有产物的令牌目录，公开任务为空
+	privateToken string
+	// destination 请求指定的产物目标桶与前缀，零值为默认桶
+	destination vo.OutputDestination
+	// lane 调度通道，按源时长在创建时分道
+	lane vo.TaskLane
+	// groupUUID 所属任务组，未加入任务组时为空
+	groupUUID   string
+	priority    int
+	retryCount  int
+	nextRetryAt *time.Time
+	createdAt   time.Time
+	updatedAt   time.Time
+	events      []event.TaskStatusChanged // 尚未持久化的状态变更事件
 }
 
 // NewTranscodeTaskEntity 创建转码任务实体
 func NewTranscodeTaskEntity(
 	taskUUID, userUUID, videoUUID, originalPath, outputPath string,
 ) *TranscodeTaskEntity {
-	now := time.Now()
+	now := clock.Now()
 	return &TranscodeTaskEntity{
 		taskUUID:     taskUUID,
 		userUUID:     userUUID,
@@ -40,6 +86,7 @@ func NewTranscodeTaskEntity(
 		status:       vo.TaskStatusPending,
 		progress:     0,
 		errorMessage: "",
+		priority:     DefaultTaskPriority,
 		createdAt:    now,
 		updatedAt:    now,
 	}
@@ -47,11 +94,12 @@ func NewTranscodeTaskEntity(
 
 // DefaultTranscodeTaskEntity 创建默认转码任务实体（自动生成UUID）
 func DefaultTranscodeTaskEntity(userUUID, videoUUID, videoPushUUID, originalPath string, params vo.TranscodeParams) *TranscodeTaskEntity {
-	taskUUID := uuid.New().String()
-	now := time.Now()
+	taskUUID := clock.NewID()
+	now := clock.Now()
 
 	// 生成输出路径
-	outputPath := generateOutputPath(userUUID, videoUUID, params)
+	outputPath := generateOutputPath(userUUID, videoUUID, params, 0)
+	assignAudioKeys(userUUID, videoUUID, &params, 0)
 
 	return &TranscodeTaskEntity{
 		taskUUID:      taskUUID,
@@ -64,6 +112,7 @@ func DefaultTranscodeTaskEntity(userUUID, videoUUID, videoPushUUID, originalPath
 		progress:      0,
 		errorMessage:  "",
 		params:        params,
+		priority:      DefaultTaskPriority,
 		createdAt:     now,
 		updatedAt:     now,
 	}
@@ -88,14 +137,39 @@ func NewTranscodeTaskEntityWithDetails(
 		progress:      progress,
 		errorMessage:  errorMessage,
 		params:        params,
+		priority:      DefaultTaskPriority,
 		createdAt:     createdAt,
 		updatedAt:     updatedAt,
 	}
 }
 
-// generateOutputPath 生成输出路径
-func generateOutputPath(userUUID, videoUUID string, params vo.TranscodeParams) string {
-	return "/transcoded/" + userUUID + "/" + videoUUID + "_" + params.Resolution + "_" + params.Bitrate + ".mp4"
+// generateOutputPath 生成输出路径；源文件代数大于 1 时带 _g<N> 后缀，新旧源的产物互不覆盖
+func generateOutputPath(userUUID, videoUUID string, params vo.TranscodeParams, generation int64) string {
+	suffix := ""
+	if generation > 1 {
+		suffix = fmt.Sprintf("_g%d", generation)
+	}
+	if params.IsPreview() {
+		// 预览产物单独发布，不覆盖正式产物
+		return fmt.Sprintf("/transcoded/preview/%s/%s_%s_%s_%ds%s%s", userUUID, videoUUID, params.Resolution, params.Bitrate, params.PreviewSeconds, suffix, params.OutputContainer().Extension())
+	}
+	return "/transcoded/" + userUUID + "/" + videoUUID + "_" + params.Resolution + "_" + params.Bitrate + suffix + params.OutputContainer().Extension()
+}
+
+// assignAudioKeys 纯音频产物发布到独立的 audio 前缀，与视频产物一样按源文件代数区分
+func assignAudioKeys(userUUID, videoUUID string, params *vo.TranscodeParams, generation int64) {
+	if !params.HasAudioOutputs() {
+		return
+	}
+	suffix := ""
+	if generation > 1 {
+		suffix = fmt.Sprintf("_g%d", generation)
+	}
+	params.Audio = params.Audio.Clone()
+	for i := range params.Audio.Renditions {
+		r := &params.Audio.Renditions[i]
+		r.ObjectKey = "/transcoded/audio/" + userUUID + "/" + videoUUID + "_" + r.Bitrate + suffix + r.Format.Extension()
+	}
 }
 
 // ID 获取数据库主键ID
@@ -151,7 +225,7 @@ func (t *TranscodeTaskEntity) OutputPath() string {
 // SetOutputPath 设置输出路径
 func (t *TranscodeTaskEntity) SetOutputPath(path string) {
 	t.outputPath = path
-	t.updatedAt = time.Now()
+	t.updatedAt = clock.Now()
 }
 
 // Status 获取状态
@@ -184,32 +258,349 @@ func (t *TranscodeTaskEntity) UpdatedAt() time.Time {
 	return t.updatedAt
 }
 
-// SetStatus 设置状态
-func (t *TranscodeTaskEntity) SetStatus(status vo.TaskStatus) {
-	t.status = status
-	t.updatedAt = time.Now()
-}
-
-// TransitionTo 执行带校验的状态转换。
+// TransitionTo 执行带校验的状态转换，成功时记录状态变更事件
 func (t *TranscodeTaskEntity) TransitionTo(target vo.TaskStatus) error {
 	if !t.status.CanTransitionTo(target) {
 		return fmt.Errorf("invalid status transition from %s to %s", t.status.String(), target.String())
 	}
+	now := clock.Now()
+	t.events = append(t.events, event.TaskStatusChanged{
+		TaskUUID:   t.taskUUID,
+		VideoUUID:  t.videoUUID,
+		Labels:     t.labels,
+		Generation: t.sourceGeneration,
+		From:       t.status,
+		To:         target,
+		OccurredAt: now,
+	})
 	t.status = target
-	t.updatedAt = time.Now()
+	t.updatedAt = now
+	switch {
+	case target == vo.TaskStatusProcessing:
+		t.timings.Restart(now)
+	case t.IsTerminal():
+		t.timings.Finish(now)
+	}
 	return nil
 }
 
+// PullEvents 取出并清空尚未持久化的状态变更事件，由仓储在持久化成功后调用
+func (t *TranscodeTaskEntity) PullEvents() []event.TaskStatusChanged {
+	events := t.events
+	t.events = nil
+	return events
+}
+
 // SetProgress 设置进度
 func (t *TranscodeTaskEntity) SetProgress(progress int) {
 	t.progress = progress
-	t.updatedAt = time.Now()
+	t.updatedAt = clock.Now()
 }
 
 // SetErrorMessage 设置错误信息
 func (t *TranscodeTaskEntity) SetErrorMessage(message string) {
 	t.errorMessage = message
-	t.updatedAt = time.Now()
+	t.updatedAt = clock.Now()
+}
+
+// StageProgress 获取各阶段进度（副本）
+func (t *TranscodeTaskEntity) StageProgress() vo.StageProgress {
+	if t.stages == nil {
+		return vo.StageProgress{}
+	}
+	return t.stages.Clone()
+}
+
+// SetStageProgress 设置单个阶段进度
+func (t *TranscodeTaskEntity) SetStageProgress(stage vo.PipelineStage, progress int) {
+	if t.stages == nil {
+		t.stages = vo.StageProgress{}
+	}
+	t.stages.Set(stage, progress)
+	t.updatedAt = clock.Now()
+	t.timings.MarkStage(stage, progress, t.updatedAt)
+}
+
+// SetStages 设置全部阶段进度（用于持久化还原）
+func (t *TranscodeTaskEntity) SetStages(stages vo.StageProgress) {
+	t.stages = stages
+}
+
+// Timings 最近一次执行的计时（副本）
+func (t *TranscodeTaskEntity) Timings() vo.TaskTimings {
+	return t.timings.Clone()
+}
+
+// RecordSourceWait 记录开始下载前等待源文件就绪的时长，随下一次阶段进度一并落库
+func (t *TranscodeTaskEntity) RecordSourceWait(d time.Duration) {
+	t.timings.SourceWaitMs = d.Milliseconds()
+}
+
+// SetTimings 设置计时（用于持久化还原）
+func (t *TranscodeTaskEntity) SetTimings(timings vo.TaskTimings) {
+	t.timings = timings
+}
+
+// Commands 获取已执行的 ffmpeg 命令
+func (t *TranscodeTaskEntity) Commands() vo.FFmpegCommands {
+	return t.commands
+}
+
+// RecordCommand 记录一次 ffmpeg 命令，同 label 覆盖
+func (t *TranscodeTaskEntity) RecordCommand(cmd vo.FFmpegCommand) {
+	t.commands = t.commands.Upsert(cmd)
+}
+
+// SetCommands 设置全部命令（用于持久化还原）
+func (t *TranscodeTaskEntity) SetCommands(commands vo.FFmpegCommands) {
+	t.commands = commands
+}
+
+// BitrateAdjustment 按源码率封顶后的输出阶梯
+func (t *TranscodeTaskEntity) BitrateAdjustment() *vo.BitrateAdjustment {
+	return t.bitrateAdjustment
+}
+
+// SetBitrateAdjustment 设置码率封顶记录
+func (t *TranscodeTaskEntity) SetBitrateAdjustment(adj *vo.BitrateAdjustment) {
+	t.bitrateAdjustment = adj
+}
+
+// Fingerprint 产物的编码设置指纹，未完成或历史任务为 nil
+func (t *TranscodeTaskEntity) Fingerprint() *vo.EncoderFingerprint {
+	return t.fingerprint
+}
+
+// SetFingerprint 设置编码设置指纹
+func (t *TranscodeTaskEntity) SetFingerprint(fp *vo.EncoderFingerprint) {
+	t.fingerprint = fp
+}
+
+// SourceStreams 源文件内容及缺失流的处理方式
+func (t *TranscodeTaskEntity) SourceStreams() *vo.SourceStreams {
+	return t.sourceStreams
+}
+
+// SetSourceStreams 设置源文件内容
+func (t *TranscodeTaskEntity) SetSourceStreams(s *vo.SourceStreams) {
+	t.sourceStreams = s
+}
+
+// ResourceTrace 编码期间的进程资源时间序列，未采样时为 nil
+func (t *TranscodeTaskEntity) ResourceTrace() *vo.ResourceTrace {
+	return t.resourceTrace
+}
+
+// SetResourceTrace 设置资源时间序列（用于持久化还原）
+func (t *TranscodeTaskEntity) SetResourceTrace(trace *vo.ResourceTrace) {
+	t.resourceTrace = trace
+}
+
+// OutputStorage 产物的存储层级与归档位置
+func (t *TranscodeTaskEntity) OutputStorage() vo.OutputStorage {
+	return t.outputStorage
+}
+
+// SetOutputStorage 设置存储层级（用于持久化还原）
+func (t *TranscodeTaskEntity) SetOutputStorage(s vo.OutputStorage) {
+	t.outputStorage = s
+}
+
+// Labels 获取任务标签
+func (t *TranscodeTaskEntity) Labels() vo.TaskLabels {
+	return t.labels
+}
+
+// SetLabels 设置任务标签（创建时或从存储恢复）
+func (t *TranscodeTaskEntity) SetLabels(labels vo.TaskLabels) {
+	t.labels = labels
+}
+
+// SourceGeneration 获取源文件代数，产物对应该代源文件
+func (t *TranscodeTaskEntity) SourceGeneration() int64 {
+	return t.sourceGeneration
+}
+
+// SetSourceGeneration 设置源文件代数（从存储恢复）
+func (t *TranscodeTaskEntity) SetSourceGeneration(generation int64) {
+	t.sourceGeneration = generation
+}
+
+// AssignSourceGeneration 创建时设置源文件代数并重新生成输出路径
+func (t *TranscodeTaskEntity) AssignSourceGeneration(generation int64) {
+	t.sourceGeneration = generation
+	t.outputPath = generateOutputPath(t.userUUID, t.videoUUID, t.params, generation)
+	assignAudioKeys(t.userUUID, t.videoUUID, &t.params, generation)
+	t.applyPrivateKeys()
+	t.applyDestinationKeys()
+}
+
+// ParentTaskUUID 重放任务对应的原任务UUID
+func (t *TranscodeTaskEntity) ParentTaskUUID() string {
+	return t.parentTaskUUID
+}
+
+// SetParentTaskUUID 设置原任务UUID（从存储恢复）
+func (t *TranscodeTaskEntity) SetParentTaskUUID(parentUUID string) {
+	t.parentTaskUUID = parentUUID
+}
+
+// IdempotencyKey 创建请求的幂等键，未携带时为空
+func (t *TranscodeTaskEntity) IdempotencyKey() string {
+	return t.idempotencyKey
+}
+
+// SetIdempotencyKey 设置幂等键
+func (t *TranscodeTaskEntity) SetIdempotencyKey(key string) {
+	t.idempotencyKey = key
+}
+
+// IsReplay 是否为重放任务
+func (t *TranscodeTaskEntity) IsReplay() bool {
+	return t.parentTaskUUID != ""
+}
+
+// MarkReplayOf 创建时标记为 parent 的重放任务，产物单独发布到 replay 前缀，不覆盖正式产物
+func (t *TranscodeTaskEntity) MarkReplayOf(parentUUID string) {
+	t.parentTaskUUID = parentUUID
+	t.outputPath = fmt.Sprintf("/transcoded/replay/%s/%s_%s_%s_%s%s", t.userUUID, t.videoUUID, t.taskUUID, t.params.Resolution, t.params.Bitrate, t.params.OutputContainer().Extension())
+	t.applyPrivateKeys()
+	t.applyDestinationKeys()
+}
+
+// Visibility 产物可见性
+func (t *TranscodeTaskEntity) Visibility() vo.Visibility {
+	if t.privateToken != "" {
+		return vo.VisibilityPrivate
+	}
+	return vo.VisibilityPublic
+}
+
+// PrivateToken 私有产物的令牌目录，公开任务为空
+func (t *TranscodeTaskEntity) PrivateToken() string {
+	return t.privateToken
+}
+
+// SetPrivateToken 设置令牌（从存储恢复），不改写产物 key
+func (t *TranscodeTaskEntity) SetPrivateToken(token string) {
+	t.privateToken = token
+}
+
+// MakePrivate 创建时标记为私有产物，视频与音频产物改写到令牌目录；之后重新生成 key 时同样改写
+func (t *TranscodeTaskEntity) MakePrivate(token string) {
+	t.privateToken = token
+	t.applyPrivateKeys()
+}
+
+func (t *TranscodeTaskEntity) applyPrivateKeys() {
+	if t.privateToken == "" {
+		return
+	}
+	t.outputPath = vo.PrivateObjectKey(t.outputPath, t.privateToken, t.userUUID, t.videoUUID)
+	if !t.params.HasAudioOutputs() {
+		return
+	}
+	t.params.Audio = t.params.Audio.Clone()
+	for i := range t.params.Audio.Renditions {
+		r := &t.params.Audio.Renditions[i]
+		r.ObjectKey = vo.PrivateObjectKey(r.ObjectKey, t.privateToken, t.userUUID, t.videoUUID)
+	}
+}
+
+// OutputDestination 产物的目标桶与前缀，零值为默认桶
+func (t *TranscodeTaskEntity) OutputDestination() vo.OutputDestination {
+	return t.destination
+}
+
+// SetOutputDestination 设置目标桶与前缀（从存储恢复），不改写产物 key
+func (t *TranscodeTaskEntity) SetOutputDestination(d vo.OutputDestination) {
+	t.destination = d
+}
+
+// RouteOutputs 创建时指定目标桶与前缀，视频与音频产物改写到目标桶；之后重新生成 key 时同样改写
+func (t *TranscodeTaskEntity) RouteOutputs(d vo.OutputDestination) {
+	t.destination = d
+	t.applyDestinationKeys()
+}
+
+func (t *TranscodeTaskEntity) applyDestinationKeys() {
+	if t.destination.IsZero() {
+		return
+	}
+	t.outputPath = vo.DestinationObjectKey(t.outputPath, t.destination)
+	if !t.params.HasAudioOutputs() {
+		return
+	}
+	t.params.Audio = t.params.Audio.Clone()
+	for i := range t.params.Audio.Renditions {
+		r := &t.params.Audio.Renditions[i]
+		r.ObjectKey = vo.DestinationObjectKey(r.ObjectKey, t.destination)
+	}
+}
+
+// Priority 获取优先级
+func (t *TranscodeTaskEntity) Priority() int {
+	return t.priority
+}
+
+// SetPriority 设置优先级
+func (t *TranscodeTaskEntity) SetPriority(priority int) {
+	t.priority = priority
+}
+
+// Lane 调度通道，未分道的历史任务为 standard
+func (t *TranscodeTaskEntity) Lane() vo.TaskLane {
+	if t.lane == "" {
+		return vo.LaneStandard
+	}
+	return t.lane
+}
+
+// SetLane 设置调度通道
+func (t *TranscodeTaskEntity) SetLane(lane vo.TaskLane) {
+	t.lane = lane
+}
+
+// GroupUUID 所属任务组，未加入任务组时为空
+func (t *TranscodeTaskEntity) GroupUUID() string {
+	return t.groupUUID
+}
+
+// SetGroupUUID 设置所属任务组
+func (t *TranscodeTaskEntity) SetGroupUUID(groupUUID string) {
+	t.groupUUID = groupUUID
+}
+
+// RetryCount 已因瞬时故障重试的次数
+func (t *TranscodeTaskEntity) RetryCount() int {
+	return t.retryCount
+}
+
+// NextRetryAt 下次重试时间，未安排时为 nil
+func (t *TranscodeTaskEntity) NextRetryAt() *time.Time {
+	return t.nextRetryAt
+}
+
+// SetRetryState 恢复重试次数与下次重试时间（用于持久化重建）
+func (t *TranscodeTaskEntity) SetRetryState(retryCount int, nextRetryAt *time.Time) {
+	t.retryCount = retryCount
+	t.nextRetryAt = nextRetryAt
+}
+
+// ScheduleRetry 进入 retrying 状态并安排下次重试
+func (t *TranscodeTaskEntity) ScheduleRetry(nextRetryAt time.Time, reason string) error {
+	if err := t.TransitionTo(vo.TaskStatusRetrying); err != nil {
+		return err
+	}
+	t.retryCount++
+	t.nextRetryAt = &nextRetryAt
+	t.errorMessage = reason
+	return nil
+}
+
+// IsRetrying 检查是否等待重试
+func (t *TranscodeTaskEntity) IsRetrying() bool {
+	return t.status == vo.TaskStatusRetrying
 }
 
 // SetTimestamps 设置创建和更新时间（用于持久化还原）
@@ -221,7 +612,7 @@ func (t *TranscodeTaskEntity) SetTimestamps(createdAt, updatedAt time.Time) {
 // SetParams 设置转码参数
 func (t *TranscodeTaskEntity) SetParams(params vo.TranscodeParams) {
 	t.params = params
-	t.updatedAt = time.Now()
+	t.updatedAt = clock.Now()
 }
 
 // IsCompleted 检查是否已完成
@@ -239,6 +630,21 @@ func (t *TranscodeTaskEntity) IsCancelled() bool {
 	return t.status == vo.TaskStatusCancelled
 }
 
+// IsExpired 检查是否已过期
+func (t *TranscodeTaskEntity) IsExpired() bool {
+	return t.status == vo.TaskStatusExpired
+}
+
+// IsRejected 检查是否因输入超限被拒绝
+func (t *TranscodeTaskEntity) IsRejected() bool {
+	return t.status == vo.TaskStatusRejected
+}
+
+// IsTerminal 是否处于终态
+func (t *TranscodeTaskEntity) IsTerminal() bool {
+	return t.IsCompleted() || t.IsFailed() || t.IsCancelled() || t.IsExpired() || t.IsRejected()
+}
+
 // IsProcessing 检查是否正在处理
 func (t *TranscodeTaskEntity) IsProcessing() bool {
 	return t.status == vo.TaskStatusProcessing
@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+)
+
+// UserPreferenceEntity 用户默认转码偏好，任务未显式指定参数时使用
+type UserPreferenceEntity struct {
+	id               uint64
+	userUUID         string
+	ladder           []vo.ResolutionConfig
+	watermark        *vo.Watermark
+	subtitleLanguage string
+	createdAt        time.Time
+	updatedAt        time.Time
+}
+
+// NewUserPreferenceEntity 创建用户偏好并校验
+func NewUserPreferenceEntity(userUUID string, ladder []vo.ResolutionConfig, watermark *vo.Watermark, subtitleLanguage string) (*UserPreferenceEntity, error) {
+	for i := range ladder {
+		if err := ladder[i].Validate(); err != nil {
+			return nil, fmt.Errorf("码率阶梯[%d]无效: %w", i, err)
+		}
+	}
+	if watermark != nil {
+		if err := watermark.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	now := clock.Now()
+	return &UserPreferenceEntity{
+		userUUID:         userUUID,
+		ladder:           ladder,
+		watermark:        watermark,
+		subtitleLanguage: subtitleLanguage,
+		createdAt:        now,
+		updatedAt:        now,
+	}, nil
+}
+
+// RestoreUserPreference 从持久化数据恢复
+func RestoreUserPreference(id uint64, userUUID string, ladder []vo.ResolutionConfig, watermark *vo.Watermark, subtitleLanguage string, createdAt, updatedAt time.Time) *UserPreferenceEntity {
+	return &UserPreferenceEntity{
+		id:               id,
+		userUUID:         userUUID,
+		ladder:           ladder,
+		watermark:        watermark,
+		subtitleLanguage: subtitleLanguage,
+		createdAt:        createdAt,
+		updatedAt:        updatedAt,
+	}
+}
+
+func (p *UserPreferenceEntity) ID() uint64                    { return p.id }
+func (p *UserPreferenceEntity) UserUUID() string              { return p.userUUID }
+func (p *UserPreferenceEntity) Ladder() []vo.ResolutionConfig { return p.ladder }
+func (p *UserPreferenceEntity) Watermark() *vo.Watermark      { return p.watermark }
+func (p *UserPreferenceEntity) SubtitleLanguage() string      { return p.subtitleLanguage }
+func (p *UserPreferenceEntity) CreatedAt() time.Time          { return p.createdAt }
+func (p *UserPreferenceEntity) UpdatedAt() time.Time          { return p.updatedAt }
+
+// PrimaryRendition 阶梯首档作为单路转码的默认目标
+func (p *UserPreferenceEntity) PrimaryRendition() (vo.ResolutionConfig, bool) {
+	if len(p.ladder) == 0 {
+		return vo.ResolutionConfig{}, false
+	}
+	return p.ladder[0], true
+}
@@ -0,0 +1,161 @@
+package entity
+
+import (
+	"time"
+
+	"transcode-service/ddd/domain/vo"
+)
+
+// 子作业类型
+const (
+	VideoJobKindMP4 = "mp4"
+	VideoJobKindHLS = "hls"
+)
+
+// VideoChildJob 视频下的子作业快照
+type VideoChildJob struct {
+	Kind         string
+	JobUUID      string
+	Status       string
+	Progress     int
+	ErrorMessage string
+	OutputPath   string
+	UpdatedAt    time.Time
+	// Renditions HLS 作业各路码流状态，MP4 作业为空
+	Renditions vo.HLSRenditions
+}
+
+// IsSucceeded 子作业是否成功
+func (j VideoChildJob) IsSucceeded() bool {
+	return j.Status == vo.TaskStatusCompleted.String()
+}
+
+// IsFailed 子作业是否失败（取消、过期、拒绝也视为失败）
+func (j VideoChildJob) IsFailed() bool {
+	return j.Status == vo.TaskStatusFailed.String() || j.Status == vo.TaskStatusCancelled.String() || j.Status == vo.TaskStatusExpired.String() ||
+		j.Status == vo.TaskStatusRejected.String()
+}
+
+// VideoProcessing 以 video_uuid 聚合的视频处理聚合根，汇总 MP4 转码与 HLS 等子作业
+type VideoProcessing struct {
+	videoUUID string
+	userUUID  string
+	children  []VideoChildJob
+}
+
+// NewVideoProcessing 根据子作业构建聚合
+func NewVideoProcessing(videoUUID string, tasks []*TranscodeTaskEntity, hlsJobs []*HLSJobEntity) *VideoProcessing {
+	vp := &VideoProcessing{videoUUID: videoUUID, children: make([]VideoChildJob, 0, len(tasks)+len(hlsJobs))}
+	for _, t := range tasks {
+		if t == nil {
+			continue
+		}
+		if vp.userUUID == "" {
+			vp.userUUID = t.UserUUID()
+		}
+		vp.children = append(vp.children, VideoChildJob{
+			Kind:         VideoJobKindMP4,
+			JobUUID:      t.TaskUUID(),
+			Status:       t.Status().String(),
+			Progress:     t.Progress(),
+			ErrorMessage: t.ErrorMessage(),
+			OutputPath:   t.OutputPath(),
+			UpdatedAt:    t.UpdatedAt(),
+		})
+	}
+	for _, h := range hlsJobs {
+		if h == nil {
+			continue
+		}
+		if vp.userUUID == "" {
+			vp.userUUID = h.UserUUID()
+		}
+		output := ""
+		if h.MasterPlaylist() != nil {
+			output = *h.MasterPlaylist()
+		}
+		vp.children = append(vp.children, VideoChildJob{
+			Kind:         VideoJobKindHLS,
+			JobUUID:      h.JobUUID(),
+			Status:       h.Status(),
+			Progress:     h.Progress(),
+			ErrorMessage: h.ErrorMessage(),
+			OutputPath:   output,
+			UpdatedAt:    h.UpdatedAt(),
+			Renditions:   h.Renditions(),
+		})
+	}
+	return vp
+}
+
+func (v *VideoProcessing) VideoUUID() string         { return v.videoUUID }
+func (v *VideoProcessing) UserUUID() string          { return v.userUUID }
+func (v *VideoProcessing) Children() []VideoChildJob { return v.children }
+
+// State 推导整体状态（每类子作业只看最近一次，避免重试前的失败记录干扰）：
+// 有子作业未结束 -> processing；全部成功 -> published；
+// 有失败但存在可播放的 HLS 产物 -> partially_failed；否则 failed。
+func (v *VideoProcessing) State() vo.VideoProcessingState {
+	latest := v.latestByKind()
+	if len(latest) == 0 {
+		return vo.VideoStatePending
+	}
+	failed, playable := 0, false
+	for kind, c := range latest {
+		switch {
+		case c.IsSucceeded():
+			if kind == VideoJobKindHLS {
+				playable = true
+			}
+		case c.IsFailed():
+			failed++
+		default:
+			return vo.VideoStateProcessing
+		}
+	}
+	// MP4 完成后 HLS 作业尚未创建，仍视为处理中
+	if _, ok := latest[VideoJobKindHLS]; !ok && failed == 0 {
+		return vo.VideoStateProcessing
+	}
+	switch {
+	case failed == 0:
+		return vo.VideoStatePublished
+	case playable:
+		return vo.VideoStatePartiallyFailed
+	default:
+		return vo.VideoStateFailed
+	}
+}
+
+// PlaybackURL 返回最近一次成功 HLS 作业的 master playlist
+func (v *VideoProcessing) PlaybackURL() string {
+	url := ""
+	var latest time.Time
+	for _, c := range v.children {
+		if c.Kind == VideoJobKindHLS && c.IsSucceeded() && c.OutputPath != "" && !c.UpdatedAt.Before(latest) {
+			url = c.OutputPath
+			latest = c.UpdatedAt
+		}
+	}
+	return url
+}
+
+// FirstError 返回最近一次失败子作业的错误信息
+func (v *VideoProcessing) FirstError() string {
+	for _, c := range v.latestByKind() {
+		if c.IsFailed() && c.ErrorMessage != "" {
+			return c.ErrorMessage
+		}
+	}
+	return ""
+}
+
+func (v *VideoProcessing) latestByKind() map[string]VideoChildJob {
+	latest := make(map[string]VideoChildJob, 2)
+	for _, c := range v.children {
+		if cur, ok := latest[c.Kind]; !ok || c.UpdatedAt.After(cur.UpdatedAt) {
+			latest[c.Kind] = c
+		}
+	}
+	return latest
+}
@@ -0,0 +1,69 @@
+package event
+
+import (
+	"strings"
+	"sync"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/metrics"
+)
+
+const labelMetricOverflow = "other"
+
+var (
+	labelValuesMu sync.Mutex
+	labelValues   = make(map[string]map[string]struct{})
+)
+
+// RecordLabelMetrics 按标签分组累加指标 <name>_by_<key>_<value>。
+// 只统计 transcode.labels.metric_keys 中的 key，每个 key 最多 max_metric_values 个取值，超出归入 other，
+// 避免任意标签导致指标基数膨胀
+func RecordLabelMetrics(name string, labels vo.TaskLabels) {
+	if len(labels) == 0 {
+		return
+	}
+	cfg := config.GetGlobalConfig()
+	if cfg == nil {
+		return
+	}
+	lc := cfg.Transcode.Labels
+	for _, key := range lc.MetricKeys {
+		value, ok := labels[key]
+		if !ok {
+			continue
+		}
+		metrics.Add(name+"_by_"+key+"_"+boundedLabelValue(key, sanitizeMetricPart(value), lc.MaxMetricValues), 1)
+	}
+}
+
+func boundedLabelValue(key, value string, max int) string {
+	labelValuesMu.Lock()
+	defer labelValuesMu.Unlock()
+	seen, ok := labelValues[key]
+	if !ok {
+		seen = make(map[string]struct{})
+		labelValues[key] = seen
+	}
+	if _, ok := seen[value]; ok {
+		return value
+	}
+	if len(seen) >= max {
+		return labelMetricOverflow
+	}
+	seen[value] = struct{}{}
+	return value
+}
+
+func sanitizeMetricPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '_'
+		}
+	}, s)
+}
@@ -0,0 +1,85 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// TaskStatusChanged 转码任务状态变更事件，仅由实体在通过校验的状态转换时产生
+type TaskStatusChanged struct {
+	TaskUUID   string
+	VideoUUID  string
+	Labels     vo.TaskLabels
+	Generation int64 // 源文件代数
+	From       vo.TaskStatus
+	To         vo.TaskStatus
+	OccurredAt time.Time
+}
+
+// Handler 事件处理函数
+type Handler func(ctx context.Context, evt TaskStatusChanged)
+
+// Bus 进程内事件总线，仓储持久化成功后发布事件
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+var (
+	busOnce      sync.Once
+	singletonBus *Bus
+)
+
+// DefaultBus 默认事件总线，内置状态转换指标订阅
+func DefaultBus() *Bus {
+	busOnce.Do(func() {
+		singletonBus = &Bus{}
+		singletonBus.Subscribe(recordTransitionMetrics)
+	})
+	return singletonBus
+}
+
+// Subscribe 注册事件处理函数
+func (b *Bus) Subscribe(h Handler) {
+	if h == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, h)
+}
+
+// Publish 按顺序分发事件，单个处理函数 panic 不影响其他订阅者
+func (b *Bus) Publish(ctx context.Context, events ...TaskStatusChanged) {
+	if len(events) == 0 {
+		return
+	}
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers...)
+	b.mu.RUnlock()
+	for _, evt := range events {
+		for _, h := range handlers {
+			dispatch(ctx, h, evt)
+		}
+	}
+}
+
+func dispatch(ctx context.Context, h Handler, evt TaskStatusChanged) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("task event handler panic task_uuid=%s from=%s to=%s panic=%v", evt.TaskUUID, evt.From.String(), evt.To.String(), r)
+		}
+	}()
+	h(ctx, evt)
+}
+
+func recordTransitionMetrics(_ context.Context, evt TaskStatusChanged) {
+	metrics.Add("task_status_transitions_total", 1)
+	metrics.Add("task_status_to_"+evt.To.String()+"_total", 1)
+	RecordLabelMetrics("task_status_to_"+evt.To.String()+"_total", evt.Labels)
+}
@@ -0,0 +1,15 @@
+package gateway
+
+import "context"
+
+// DataKeyRequest 解封源文件数据密钥的请求，字段取自加密源文件头部
+type DataKeyRequest struct {
+	ObjectKey  string
+	KeyID      string
+	WrappedKey []byte
+}
+
+// KeyProvider upload-service 侧的数据密钥提供方；返回的明文密钥只在内存中使用，用后清零
+type KeyProvider interface {
+	DataKey(ctx context.Context, req DataKeyRequest) ([]byte, error)
+}
@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"transcode-service/pkg/config"
+)
+
+// LadderCatalog 某一时刻生效的输出档位、档位集合与自动档位规则
+type LadderCatalog struct {
+	OutputFormats    []config.OutputFormat
+	FormatSet        string // 当前环境缺省的档位集合
+	FormatSets       map[string]config.FormatSetConfig
+	AutoProfileRules []config.AutoProfileRule
+	Version          string // 来源给出的版本，用于日志与缓存状态展示
+	LoadedAt         time.Time
+}
+
+// LadderCatalogFromConfig 由配置文件构造；cfg 为 nil 时返回 nil
+func LadderCatalogFromConfig(cfg *config.Config) *LadderCatalog {
+	if cfg == nil {
+		return nil
+	}
+	return &LadderCatalog{
+		OutputFormats:    cfg.Transcode.OutputFormats,
+		FormatSet:        cfg.Transcode.FormatSet,
+		FormatSets:       cfg.Transcode.FormatSets,
+		AutoProfileRules: cfg.Transcode.AutoProfile.Rules,
+		Version:          "config",
+	}
+}
+
+// ResolveFormatSet 与 config.TranscodeConfig.ResolveFormatSet 语义一致
+func (c *LadderCatalog) ResolveFormatSet(name string) (set config.FormatSetConfig, resolved string, ok bool) {
+	t := config.TranscodeConfig{OutputFormats: c.OutputFormats, FormatSet: c.FormatSet, FormatSets: c.FormatSets}
+	return t.ResolveFormatSet(name)
+}
+
+// LadderSource 档位目录的来源。当前由配置文件提供，profiles API 落库后换成数据库实现
+type LadderSource interface {
+	LoadLadderCatalog(ctx context.Context) (*LadderCatalog, error)
+}
+
+// LadderCatalogProvider 按任务取档位目录，实现负责缓存与失效，调用开销应可忽略
+type LadderCatalogProvider interface {
+	LadderCatalog(ctx context.Context) *LadderCatalog
+}
@@ -1,6 +1,26 @@
 package gateway
 
-import "context"
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrStorageUnavailable 存储不可达（网络错误、5xx 等瞬时故障），调用方应退避重试而非直接失败
+var ErrStorageUnavailable = errors.New("storage unavailable")
+
+// IsStorageUnavailable 判断错误是否为存储连通性故障
+func IsStorageUnavailable(err error) bool {
+	return errors.Is(err, ErrStorageUnavailable)
+}
+
+// ErrObjectNotFound 对象不存在（404），源文件尚未复制到位时也会出现
+var ErrObjectNotFound = errors.New("object not found")
+
+// IsObjectNotFound 判断错误是否为对象不存在
+func IsObjectNotFound(err error) bool {
+	return errors.Is(err, ErrObjectNotFound)
+}
 
 // UploadObject 表示要上传的对象
 type UploadObject struct {
@@ -9,6 +29,12 @@ type UploadObject struct {
 	ContentType string
 }
 
+// ObjectInfo 对象元数据
+type ObjectInfo struct {
+	Size int64
+	ETag string
+}
+
 // StorageGateway 存储网关
 type StorageGateway interface {
 	// UploadTranscodedFile 上传转码后的文件，返回可访问的对象路径
@@ -19,4 +45,44 @@ type StorageGateway interface {
 
 	// DownloadFile 从存储中下载文件到本地路径
 	DownloadFile(ctx context.Context, objectKey, localPath string) error
+
+	// OpenObject 流式读取对象内容，调用方负责关闭；对象不存在时返回 ErrObjectNotFound
+	OpenObject(ctx context.Context, objectKey string) (io.ReadCloser, error)
+
+	// StatObject 获取对象大小与 ETag，不下载内容
+	StatObject(ctx context.Context, objectKey string) (ObjectInfo, error)
+
+	// Ping 检查存储是否可达
+	Ping(ctx context.Context) error
+
+	MultipartUploader
+}
+
+// CompletedPart 已上传的分段
+type CompletedPart struct {
+	PartNumber int
+	ETag       string
+	Size       int64
+}
+
+// MultipartUploader 分段上传：对象只在 CompleteMultipart 后出现，未完成的会话可列出已上传分段后续传
+type MultipartUploader interface {
+	// InitiateMultipart 创建上传会话，返回 uploadID
+	InitiateMultipart(ctx context.Context, objectKey, contentType string) (string, error)
+	// UploadPart 上传单个分段（partNumber 从 1 开始），data 可重复读取以便计算校验和
+	UploadPart(ctx context.Context, objectKey, uploadID string, partNumber int, data io.ReadSeeker, size int64) (CompletedPart, error)
+	// ListParts 列出会话中已上传的分段，会话不存在时返回 ErrObjectNotFound
+	ListParts(ctx context.Context, objectKey, uploadID string) ([]CompletedPart, error)
+	// CompleteMultipart 按分段号顺序合并分段
+	CompleteMultipart(ctx context.Context, objectKey, uploadID string, parts []CompletedPart) error
+	// AbortMultipart 放弃会话并释放已上传分段，会话不存在时视为成功
+	AbortMultipart(ctx context.Context, objectKey, uploadID string) error
+}
+
+// ObjectMover 服务端复制与删除，用于产物分层存储；存储实现不支持时不迁移
+type ObjectMover interface {
+	// CopyObject 服务端复制，storageClass 非空时为目标对象设置存储类别
+	CopyObject(ctx context.Context, srcKey, dstKey, storageClass string) error
+	// RemoveObject 删除对象，对象不存在时视为成功
+	RemoveObject(ctx context.Context, objectKey string) error
 }
@@ -1,9 +1,29 @@
 package gateway
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 // TranscodeResultReporter notifies downstream services about task outcomes.
 type TranscodeResultReporter interface {
 	ReportSuccess(ctx context.Context, videoUUID, taskUUID, videoURL string) error
 	ReportFailure(ctx context.Context, videoUUID, taskUUID, errorMessage string) error
+	// ReportExpired 任务超过截止时间未完成，以独立的 expired 状态通知下游
+	ReportExpired(ctx context.Context, videoUUID, taskUUID, reason string) error
+	// ReportRejected 输入超出时长、大小或分辨率上限，以独立的 rejected 状态通知下游，上游不应重试
+	ReportRejected(ctx context.Context, videoUUID, taskUUID, reason string) error
+}
+
+// MilestoneHLSStarted HLS 开始切片
+const MilestoneHLSStarted = "hls_started"
+
+// MilestoneProgress 转码进度里程碑名称，如 progress_25
+func MilestoneProgress(pct int) string {
+	return fmt.Sprintf("progress_%d", pct)
+}
+
+// TranscodeMilestoneReporter 可选能力：处理中推送非终态里程碑，供下游展示进度
+type TranscodeMilestoneReporter interface {
+	ReportMilestone(ctx context.Context, videoUUID, taskUUID, milestone string) error
 }
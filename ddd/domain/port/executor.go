@@ -2,13 +2,36 @@ package port
 
 import (
 	"context"
+	"errors"
 
 	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
 )
 
+// ErrUploadDeferred is returned by Execute when encoding finished and the upload was handed
+// to a background upload pool; the result is delivered later through TranscodeOptions.OnUploaded.
+var ErrUploadDeferred = errors.New("upload deferred to upload pool")
+
+// ErrUploadAborted is passed to OnUploaded when the upload pool shut down before the upload
+// finished; the output was discarded and the task should be encoded again.
+var ErrUploadAborted = errors.New("upload aborted by shutdown")
+
+// UploadCallback receives the uploaded object key, or the error after retries are exhausted.
+type UploadCallback func(objectKey string, err error)
+
 // ProgressCallback is invoked by executors to report percentage progress (0-100).
 type ProgressCallback func(progress int)
 
+// StageProgressCallback is invoked by executors when a pipeline stage advances (0-100).
+type StageProgressCallback func(stage vo.PipelineStage, progress int)
+
+// CommandCallback is invoked by executors right before an ffmpeg command runs.
+type CommandCallback func(cmd vo.FFmpegCommand)
+
+// ResourceTraceCallback receives a snapshot of the ffmpeg resource trace, periodically while
+// the process runs and once after it exits. It is called from a sampling goroutine.
+type ResourceTraceCallback func(trace *vo.ResourceTrace)
+
 // TranscodeExecutor executes a full transcode job (typically MP4 output) and returns
 // the object key and public URL of the generated asset. Implementations may choose
 // to skip uploading based on the provided options.
@@ -25,10 +48,19 @@ type HLSExecutor interface {
 type TranscodeOptions struct {
 	SkipUpload  bool
 	ProgressCb  ProgressCallback
+	StageCb     StageProgressCallback
+	CommandCb   CommandCallback
+	TraceCb     ResourceTraceCallback
 	RequestID   string
 	TraceID     string
 	TempDir     string
 	TimeoutSecs int
+	// BitrateCap, when set, caps the output bitrate by the probed source bitrate and
+	// records the adjustment on the task.
+	BitrateCap *vo.BitrateCapPolicy
+	// OnUploaded, when set, lets the executor defer the upload to a pool: Execute returns
+	// ErrUploadDeferred and OnUploaded is called exactly once when the upload finishes.
+	OnUploaded UploadCallback
 }
 
 // HLSOptions controls HLS slicing behaviour.
@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+
+	"transcode-service/ddd/domain/vo"
+)
+
+// TaskGroupCallbackSender 向任务组创建时指定的地址投递完成回调，非 2xx 视为失败
+type TaskGroupCallbackSender interface {
+	SendGroupCompletion(ctx context.Context, url string, completion vo.TaskGroupCompletion) error
+}
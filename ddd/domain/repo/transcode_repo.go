@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"transcode-service/ddd/domain/entity"
 	"transcode-service/ddd/domain/vo"
@@ -10,18 +11,156 @@ import (
 type TranscodeJobRepository interface {
 	CreateTranscodeJob(ctx context.Context, job *entity.TranscodeTaskEntity) error
 	UpdateTranscodeJobProgress(ctx context.Context, jobUUID string, progress int) error
+	// UpdateTranscodeJobStageProgress 持久化整体进度、阶段进度与阶段计时
+	UpdateTranscodeJobStageProgress(ctx context.Context, jobUUID string, progress int, stages vo.StageProgress, timings vo.TaskTimings) error
+	// UpdateTranscodeJob 持久化整个实体，成功后发布实体上的状态变更事件
 	UpdateTranscodeJob(ctx context.Context, job *entity.TranscodeTaskEntity) error
 	GetTranscodeJob(ctx context.Context, jobUUID string) (*entity.TranscodeTaskEntity, error)
-	UpdateTranscodeJobStatus(ctx context.Context, jobUUID string, status vo.TaskStatus, message, outputPath string, progress int) error
+	// GetTranscodeJobByIdempotencyKey 按创建请求的幂等键查询任务，不存在时返回错误
+	GetTranscodeJobByIdempotencyKey(ctx context.Context, key string) (*entity.TranscodeTaskEntity, error)
+	// SaveTranscodeJobStatus 持久化实体当前的状态、消息、输出路径、进度与计时，成功后发布状态变更事件
+	SaveTranscodeJobStatus(ctx context.Context, job *entity.TranscodeTaskEntity) error
 	QueryTranscodeJobsByStatus(ctx context.Context, status vo.TaskStatus, limit int) ([]*entity.TranscodeTaskEntity, error)
+	QueryTranscodeJobsByVideo(ctx context.Context, videoUUID string) ([]*entity.TranscodeTaskEntity, error)
+	// QueryLatestTranscodeJobsByVideos 批量查询每个视频最新创建的任务，按 video_uuid 索引，无任务的视频不在结果中
+	QueryLatestTranscodeJobsByVideos(ctx context.Context, videoUUIDs []string) (map[string]*entity.TranscodeTaskEntity, error)
+	// CountPendingTranscodeJobsAhead 统计排在指定任务之前的排队任务数（优先级更高或同优先级更早创建）；
+	// express 任务只与快车道任务比较，standard 任务之前还有全部排队的快车道任务
+	CountPendingTranscodeJobsAhead(ctx context.Context, lane vo.TaskLane, priority int, createdAt time.Time) (int64, error)
+	// QueryActiveTranscodeJobsCreatedBefore 查询创建时间早于指定时间且未结束的任务
+	QueryActiveTranscodeJobsCreatedBefore(ctx context.Context, createdBefore time.Time, limit int) ([]*entity.TranscodeTaskEntity, error)
+	// ExpireTranscodeJob 持久化已转换为 expired 的实体，任务已在其他路径结束时返回 false
+	ExpireTranscodeJob(ctx context.Context, job *entity.TranscodeTaskEntity) (bool, error)
+	// UpdateTranscodeJobPriority 修改排队中任务的优先级，任务已出队或已结束时返回 false
+	UpdateTranscodeJobPriority(ctx context.Context, taskUUID string, priority int) (bool, error)
+	// ScheduleTranscodeJobRetry 持久化 retrying 状态、重试次数与下次重试时间
+	ScheduleTranscodeJobRetry(ctx context.Context, job *entity.TranscodeTaskEntity) error
+	// QueryDueRetryTranscodeJobs 查询已到重试时间的任务
+	QueryDueRetryTranscodeJobs(ctx context.Context, now time.Time, limit int) ([]*entity.TranscodeTaskEntity, error)
+	// ResumeRetryTranscodeJob 持久化已从 retrying 转换为 pending 的实体，已被其他实例恢复时返回 false
+	ResumeRetryTranscodeJob(ctx context.Context, job *entity.TranscodeTaskEntity) (bool, error)
+	// UpdateTranscodeJobCommands 持久化已执行的 ffmpeg 命令
+	UpdateTranscodeJobCommands(ctx context.Context, jobUUID string, commands vo.FFmpegCommands) error
+	// UpdateTranscodeJobResourceTrace 持久化编码期间的进程资源时间序列
+	UpdateTranscodeJobResourceTrace(ctx context.Context, jobUUID string, trace *vo.ResourceTrace) error
+	// QueryStaleTranscodeJobs 按 id 升序查询满足条件的已完成任务，用于回填
+	QueryStaleTranscodeJobs(ctx context.Context, filter vo.StaleOutputFilter, afterID uint64, limit int) ([]*entity.TranscodeTaskEntity, error)
+	// QueryArchiveCandidates 查询最近访问（无访问记录时取完成时间）早于 idleBefore 且仍在标准层的已完成任务
+	QueryArchiveCandidates(ctx context.Context, idleBefore time.Time, limit int) ([]*entity.TranscodeTaskEntity, error)
+	// QueryTranscodeJobsByStorageTier 查询产物处于指定存储层级的任务
+	QueryTranscodeJobsByStorageTier(ctx context.Context, tier vo.StorageTier, limit int) ([]*entity.TranscodeTaskEntity, error)
+	// SwapTranscodeOutputStorage 仅当当前层级属于 from 时切换为 storage.Tier 并记录归档位置，多副本下只有一个实例成功
+	SwapTranscodeOutputStorage(ctx context.Context, jobUUID string, from []vo.StorageTier, storage vo.OutputStorage) (bool, error)
+	// TouchTranscodeOutputAccess 按产物 key 回写最近访问时间（只前移），返回更新的任务数
+	TouchTranscodeOutputAccess(ctx context.Context, outputPaths []string, at time.Time) (int, error)
+	// MarkTranscodeJobForRedispatch 将本实例排队未执行的 pending 任务交还给其他实例，任务已出队或已结束时返回 false
+	MarkTranscodeJobForRedispatch(ctx context.Context, taskUUID string) (bool, error)
+	// QueryRedispatchTranscodeJobs 查询等待重新派发的任务
+	QueryRedispatchTranscodeJobs(ctx context.Context, limit int) ([]*entity.TranscodeTaskEntity, error)
+	// ClaimRedispatchTranscodeJob 认领等待重新派发的任务，已被其他实例认领时返回 false
+	ClaimRedispatchTranscodeJob(ctx context.Context, taskUUID string) (bool, error)
 }
 
 type HLSJobRepository interface {
 	CreateHLSJob(ctx context.Context, job *entity.HLSJobEntity) error
+	// GetOrCreateHLSJob 来源转码任务已有 HLS 作业时返回已有作业与 false，否则创建 job 并返回 true；
+	// source_job_uuid 唯一，并发创建时只有一个成功
+	GetOrCreateHLSJob(ctx context.Context, job *entity.HLSJobEntity) (*entity.HLSJobEntity, bool, error)
 	UpdateHLSJobProgress(ctx context.Context, jobUUID string, progress int) error
 	UpdateHLSJobStatus(ctx context.Context, jobUUID string, status string) error
 	UpdateHLSJobOutput(ctx context.Context, jobUUID string, masterPlaylist string) error
 	UpdateHLSJobError(ctx context.Context, jobUUID string, errorMessage string) error
 	GetHLSJob(ctx context.Context, jobUUID string) (*entity.HLSJobEntity, error)
 	QueryHLSJobsByStatus(ctx context.Context, status string, limit int) ([]*entity.HLSJobEntity, error)
+	QueryHLSJobsByVideo(ctx context.Context, videoUUID string) ([]*entity.HLSJobEntity, error)
+	// ClaimHLSJob 原子认领 pending 作业（pending -> claimed），多副本下只有一个 worker 会成功
+	ClaimHLSJob(ctx context.Context, jobUUID, workerID string) (bool, error)
+	// ReleaseStaleHLSClaims 释放超时未处理的认领，以及 updated_at 早于 updatedBefore 的 processing 作业
+	ReleaseStaleHLSClaims(ctx context.Context, claimedBefore, updatedBefore time.Time) (int64, error)
+	// TouchHLSJob 刷新 processing 作业的 updated_at，处理期间周期调用，避免被当作崩溃遗留回收
+	TouchHLSJob(ctx context.Context, jobUUID string) error
+	// GetHLSJobBySource 根据来源转码任务获取最新的 HLS 作业，不存在时返回 nil
+	GetHLSJobBySource(ctx context.Context, sourceJobUUID string) (*entity.HLSJobEntity, error)
+	// GetHLSJobsBySources 批量获取各来源转码任务最新的 HLS 作业，按来源任务索引，没有作业的任务不在结果中
+	GetHLSJobsBySources(ctx context.Context, sourceJobUUIDs []string) (map[string]*entity.HLSJobEntity, error)
+	// UpdateHLSJobCommands 持久化已执行的 ffmpeg 命令
+	UpdateHLSJobCommands(ctx context.Context, jobUUID string, commands vo.FFmpegCommands) error
+	// UpdateHLSJobFingerprint 持久化产物的编码设置指纹
+	UpdateHLSJobFingerprint(ctx context.Context, jobUUID string, fp *vo.EncoderFingerprint) error
+	// QueryStaleHLSJobs 按 id 升序查询满足条件的已完成作业，用于回填
+	QueryStaleHLSJobs(ctx context.Context, filter vo.StaleOutputFilter, afterID uint64, limit int) ([]*entity.HLSJobEntity, error)
+	// UpdateHLSJobRenditions 持久化各路码流状态
+	UpdateHLSJobRenditions(ctx context.Context, jobUUID string, renditions vo.HLSRenditions) error
+	// ResetHLSJobForRetry 失败作业置回 pending 并写入重置后的码流状态，作业已不是 failed 时返回 false
+	ResetHLSJobForRetry(ctx context.Context, jobUUID string, renditions vo.HLSRenditions) (bool, error)
+	// ReleaseInterruptedHLSJob 停机中断的 processing 作业置回 pending，作业已不是 processing 时返回 false
+	ReleaseInterruptedHLSJob(ctx context.Context, jobUUID string, renditions vo.HLSRenditions) (bool, error)
+}
+
+type UserPreferenceRepository interface {
+	// GetUserPreference 获取用户偏好，不存在时返回 nil
+	GetUserPreference(ctx context.Context, userUUID string) (*entity.UserPreferenceEntity, error)
+	// SaveUserPreference 按 user_uuid 新建或覆盖
+	SaveUserPreference(ctx context.Context, pref *entity.UserPreferenceEntity) error
+	// DeleteUserPreference 删除用户偏好，不存在时返回 false
+	DeleteUserPreference(ctx context.Context, userUUID string) (bool, error)
+}
+
+type PipelineSnapshotRepository interface {
+	// SavePipelineSnapshot 按 instance_id 新建或覆盖快照
+	SavePipelineSnapshot(ctx context.Context, snapshot *vo.PipelineSnapshot) error
+	// ListPipelineSnapshots 列出全部实例的快照
+	ListPipelineSnapshots(ctx context.Context) ([]*vo.PipelineSnapshot, error)
+	// DeletePipelineSnapshot 删除快照，已被其他实例删除时返回 false；对账前先删除以保证只有一个实例接管
+	DeletePipelineSnapshot(ctx context.Context, instanceID string) (bool, error)
+}
+
+type TaskAssignmentRepository interface {
+	// SaveTaskAssignment 追加一条作业执行记录
+	SaveTaskAssignment(ctx context.Context, assignment *vo.TaskAssignment) error
+	// QueryTaskAssignments 查询执行区间与 [from, to) 有重叠的记录，workerID 为空时查询全部实例
+	QueryTaskAssignments(ctx context.Context, workerID string, from, to time.Time, limit int) ([]*vo.TaskAssignment, error)
+	// PruneTaskAssignments 删除 before 之前结束的记录
+	PruneTaskAssignments(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TaskNoteRepository interface {
+	// CreateTaskNote 追加一条任务备注
+	CreateTaskNote(ctx context.Context, note *vo.TaskNote) error
+	// ListTaskNotes 按时间升序返回任务备注，最多 limit 条
+	ListTaskNotes(ctx context.Context, taskUUID string, limit int) ([]*vo.TaskNote, error)
+}
+
+type TaskGroupRepository interface {
+	// CreateTaskGroup 新建任务组
+	CreateTaskGroup(ctx context.Context, group *entity.TaskGroupEntity) error
+	// GetTaskGroup 不存在时返回 nil
+	GetTaskGroup(ctx context.Context, groupUUID string) (*entity.TaskGroupEntity, error)
+	// AttachTasks 把任务加入任务组，只更新同一用户且尚未加入其他任务组的任务，返回加入的任务数
+	AttachTasks(ctx context.Context, groupUUID, userUUID string, taskUUIDs []string) (int64, error)
+	// CountGroupTasks 按状态统计组内任务
+	CountGroupTasks(ctx context.Context, groupUUID string) (vo.TaskGroupProgress, error)
+	// ListGroupTasks 按创建时间升序返回组内任务，最多 limit 条
+	ListGroupTasks(ctx context.Context, groupUUID string, limit int) ([]*entity.TranscodeTaskEntity, error)
+	// QueryOpenTaskGroups 查询 open 状态的任务组
+	QueryOpenTaskGroups(ctx context.Context, limit int) ([]*entity.TaskGroupEntity, error)
+	// CompleteTaskGroup 仅当任务组仍为 open 时持久化完成状态，多副本下只有一个实例成功
+	CompleteTaskGroup(ctx context.Context, group *entity.TaskGroupEntity) (bool, error)
+	// QueryPendingGroupCallbacks 查询完成回调待投递的任务组，包括认领时间早于 staleBefore 仍未写回结果的
+	QueryPendingGroupCallbacks(ctx context.Context, staleBefore time.Time, limit int) ([]*entity.TaskGroupEntity, error)
+	// ClaimGroupCallback 投递前条件认领回调（pending -> delivering），返回 false 表示已被其他实例认领
+	ClaimGroupCallback(ctx context.Context, group *entity.TaskGroupEntity, staleBefore time.Time) (bool, error)
+	// HoldTaskGroup 批量创建任务前设置完成保护期，until 前任务组不会完成
+	HoldTaskGroup(ctx context.Context, groupUUID string, until time.Time) error
+	// ReleaseTaskGroup 批量创建结束后清除本次设置的保护期
+	ReleaseTaskGroup(ctx context.Context, groupUUID string, until time.Time) error
+	// SaveGroupCallbackState 持久化回调投递状态、尝试次数与最近错误
+	SaveGroupCallbackState(ctx context.Context, group *entity.TaskGroupEntity) error
+}
+
+type ThroughputRepository interface {
+	// AddDailyThroughput 把增量累加到对应日期的统计行，不存在时新建；多实例并发累加互不覆盖
+	AddDailyThroughput(ctx context.Context, delta vo.DailyThroughput) error
+	// ListDailyThroughput 按日期升序返回 [from, to] 内有记录的日统计，日期格式 2006-01-02
+	ListDailyThroughput(ctx context.Context, from, to string) ([]vo.DailyThroughput, error)
 }
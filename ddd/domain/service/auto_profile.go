@@ -0,0 +1,140 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/metrics"
+)
+
+// ProfileAuto 请求 profile 取值：按源文件特征由规则引擎选择编码配置
+const ProfileAuto = "auto"
+
+// AutoProfileDecision 命中规则给出的转码分辨率/码率与编码覆盖
+type AutoProfileDecision struct {
+	Resolution string
+	Bitrate    string
+	Profile    vo.AutoProfile
+}
+
+// SelectAutoProfile 按配置顺序匹配第一条规则；HLS 阶梯中高于源文件的分辨率被剔除，避免放大
+func SelectAutoProfile(catalog *gateway.LadderCatalog, hints vo.SourceHints) (*AutoProfileDecision, bool) {
+	if catalog == nil {
+		return nil, false
+	}
+	for _, rule := range catalog.AutoProfileRules {
+		if rule.Name == "" || rule.Resolution == "" || rule.Bitrate == "" || !matchAutoProfileRule(rule, hints) {
+			continue
+		}
+		metrics.Add("auto_profile_rule_"+metricName(rule.Name)+"_total", 1)
+		return &AutoProfileDecision{
+			Resolution: rule.Resolution,
+			Bitrate:    rule.Bitrate,
+			Profile: vo.AutoProfile{
+				Rule:       rule.Name,
+				VideoCodec: rule.VideoCodec,
+				Preset:     rule.Preset,
+				Renditions: capRenditions(rule.Renditions, hints.Height),
+			},
+		}, true
+	}
+	metrics.Add("auto_profile_unmatched_total", 1)
+	return nil, false
+}
+
+func matchAutoProfileRule(rule config.AutoProfileRule, hints vo.SourceHints) bool {
+	if (rule.MinHeight > 0 || rule.MaxHeight > 0) && hints.Height <= 0 {
+		return false
+	}
+	if rule.MinHeight > 0 && hints.Height < rule.MinHeight || rule.MaxHeight > 0 && hints.Height > rule.MaxHeight {
+		return false
+	}
+	if (rule.MinFPS > 0 || rule.MaxFPS > 0) && hints.FPS <= 0 {
+		return false
+	}
+	if rule.MinFPS > 0 && hints.FPS < rule.MinFPS || rule.MaxFPS > 0 && hints.FPS > rule.MaxFPS {
+		return false
+	}
+	duration := time.Duration(hints.DurationSeconds * float64(time.Second))
+	if (rule.MinDuration > 0 || rule.MaxDuration > 0) && duration <= 0 {
+		return false
+	}
+	if rule.MinDuration > 0 && duration < rule.MinDuration || rule.MaxDuration > 0 && duration > rule.MaxDuration {
+		return false
+	}
+	if len(rule.Popularity) > 0 {
+		for _, p := range rule.Popularity {
+			if strings.EqualFold(p, hints.Popularity) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// capRenditions 剔除高于源文件高度的档位，全部高于源时保留最低一档
+func capRenditions(renditions []string, sourceHeight int) []string {
+	if len(renditions) == 0 || sourceHeight <= 0 {
+		return renditions
+	}
+	out := make([]string, 0, len(renditions))
+	lowest := ""
+	for _, r := range renditions {
+		h := vo.ResolutionHeight(r)
+		if h <= sourceHeight {
+			out = append(out, r)
+		}
+		if lowest == "" || h < vo.ResolutionHeight(lowest) {
+			lowest = r
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, lowest)
+	}
+	return out
+}
+
+// FilterLadder 按 profile=auto 选定的分辨率裁剪 HLS 阶梯，阶梯中缺少的分辨率使用默认码率补齐
+func FilterLadder(ladder []vo.ResolutionConfig, renditions []string) []vo.ResolutionConfig {
+	if len(renditions) == 0 {
+		return ladder
+	}
+	byRes := make(map[string]vo.ResolutionConfig, len(ladder))
+	for _, rc := range ladder {
+		byRes[strings.ToLower(rc.Resolution)] = rc
+	}
+	out := make([]vo.ResolutionConfig, 0, len(renditions))
+	for _, r := range renditions {
+		if rc, ok := byRes[strings.ToLower(r)]; ok {
+			out = append(out, rc)
+			continue
+		}
+		if br, ok := defaultLadderBitrates[r]; ok {
+			if rc, err := vo.NewResolutionConfig(r, br); err == nil {
+				out = append(out, *rc)
+			}
+		}
+	}
+	if len(out) == 0 {
+		return ladder
+	}
+	return out
+}
+
+// metricName 规则名转为指标名片段；规则来自配置，取值有限
+func metricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/ffruntime"
+)
+
+// encoderFingerprint 由已执行的命令计算产物指纹，附带当前设置版本与 ffmpeg 版本
+func encoderFingerprint(ctx context.Context, cfg *config.Config, cmds vo.FFmpegCommands) *vo.EncoderFingerprint {
+	version, binary := 1, "ffmpeg"
+	if cfg != nil {
+		version, binary = cfg.Transcode.SettingsVersion, cfg.Transcode.FFmpeg.Binary()
+	}
+	return vo.NewEncoderFingerprint(version, ffruntime.Version(ctx, binary), cmds)
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"strings"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/config"
+)
+
+const (
+	// referencePixels 1080p 像素数，作为速度估算基准
+	referencePixels = 1920 * 1080
+	// nvencRealtimeFactor NVENC 编码 1080p 约为实时的倍数
+	nvencRealtimeFactor = 8.0
+	// cpuRealtimePerThread libx264 每线程编码 1080p 约为实时的倍数
+	cpuRealtimePerThread = 0.25
+	// defaultCPUThreads threads 未配置时按 4 线程估算
+	defaultCPUThreads = 4
+	// audioBitrateBps 各产物的 AAC 码率
+	audioBitrateBps = 128000
+)
+
+// EncodeEstimate 编码耗时与资源消耗估算（经验值，仅用于排查与容量规划）
+type EncodeEstimate struct {
+	SourceDurationSeconds float64 `json:"source_duration_seconds"`
+	EncodeSeconds         float64 `json:"encode_seconds"`  // 顺序执行的墙钟耗时
+	ComputeSeconds        float64 `json:"compute_seconds"` // CPU 核秒或 GPU 秒
+	ComputeUnit           string  `json:"compute_unit"`    // cpu_core_seconds | gpu_seconds
+	OutputBytes           int64   `json:"output_bytes"`
+}
+
+// EstimateEncode 按分辨率像素数与编码器经验速度估算各产物的编码耗时与输出大小
+func EstimateEncode(cfg *config.Config, renditions []vo.ResolutionConfig, durationSec float64) EncodeEstimate {
+	est := EncodeEstimate{SourceDurationSeconds: durationSec, ComputeUnit: "cpu_core_seconds"}
+	if durationSec <= 0 {
+		return est
+	}
+	codec, threads := "libx264", 0
+	if cfg != nil {
+		if c := strings.TrimSpace(cfg.Transcode.FFmpeg.VideoCodec); c != "" {
+			codec = c
+		}
+		threads = cfg.Transcode.FFmpeg.Threads
+	}
+	if threads <= 0 {
+		threads = defaultCPUThreads
+	}
+	gpu := strings.Contains(strings.ToLower(codec), "nvenc")
+	speed := cpuRealtimePerThread * float64(threads)
+	if gpu {
+		speed = nvencRealtimeFactor
+		est.ComputeUnit = "gpu_seconds"
+	}
+
+	for _, r := range renditions {
+		ratio := 1.0
+		if h, err := parseResolutionHeight(r.Resolution); err == nil {
+			ratio = float64(h*h*16/9) / referencePixels
+		}
+		seconds := durationSec * ratio / speed
+		est.EncodeSeconds += seconds
+		if gpu {
+			est.ComputeSeconds += seconds
+		} else {
+			est.ComputeSeconds += seconds * float64(threads)
+		}
+		if bps, err := parseBitrateToBps(r.Bitrate); err == nil {
+			est.OutputBytes += int64(float64(bps+audioBitrateBps) * durationSec / 8)
+		}
+	}
+	return est
+}
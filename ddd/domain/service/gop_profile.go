@@ -0,0 +1,54 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/config"
+)
+
+// OutputFormatGOP 档位配置的 GOP 结构，未配置时返回 nil
+func OutputFormatGOP(of config.OutputFormat) (*vo.GOPPolicy, error) {
+	g := of.GOP
+	policy, err := vo.NewGOPPolicy(g.ClosedGOP, g.ForcedIDR, g.Scenecut, g.IDRInterval)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil && strings.TrimSpace(of.Codec) != "" && !policy.SupportsCodec(of.Codec) {
+		return nil, fmt.Errorf("gop options are not supported by codec %s", of.Codec)
+	}
+	return policy, nil
+}
+
+// ProfileGOP 档位集合中与分辨率同名档位的 GOP 结构
+func ProfileGOP(set config.FormatSetConfig, resolution string) (*vo.GOPPolicy, error) {
+	for _, of := range set.OutputFormats {
+		if strings.EqualFold(strings.TrimSpace(of.Name), resolution) {
+			return OutputFormatGOP(of)
+		}
+	}
+	return nil, nil
+}
+
+// ValidateCatalogGOP 校验目录中全部档位的 GOP 配置，目录加载时调用，错误指明档位位置
+func ValidateCatalogGOP(catalog *gateway.LadderCatalog) error {
+	check := func(scope string, formats []config.OutputFormat) error {
+		for _, of := range formats {
+			if _, err := OutputFormatGOP(of); err != nil {
+				return fmt.Errorf("%s %s gop: %w", scope, of.Name, err)
+			}
+		}
+		return nil
+	}
+	if err := check("output_formats", catalog.OutputFormats); err != nil {
+		return err
+	}
+	for name, set := range catalog.FormatSets {
+		if err := check("format_sets."+name, set.OutputFormats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
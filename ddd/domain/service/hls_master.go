@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// HLSMasterPlaylistName 作业目录与对象存储中 master playlist 的文件名
+const HLSMasterPlaylistName = "master.m3u8"
+
+// ErrNoCompletedRenditions 作业没有已完成的码流，无法生成 master
+var ErrNoCompletedRenditions = errors.New("hls job has no completed renditions")
+
+// HLSMasterResolutions 参与 master 的码流：配置阶梯中已完成的码流，按阶梯顺序；
+// 已完成的历史作业未记录码流状态时视为全部完成
+func HLSMasterResolutions(job *entity.HLSJobEntity) []vo.ResolutionConfig {
+	resolutions := job.GetConfig().Resolutions
+	renditions := job.Renditions()
+	if job.Status() == vo.HLSStatusCompleted.String() && renditions.CountByStatus(vo.RenditionCompleted) == 0 {
+		return resolutions
+	}
+	out := make([]vo.ResolutionConfig, 0, len(resolutions))
+	for _, res := range resolutions {
+		if r := renditions.Find(res.Resolution); r != nil && r.Status == vo.RenditionCompleted {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// BuildHLSMasterPlaylist 按码流阶梯生成 master playlist 内容，条目引用各码流实际生成的播放列表
+func BuildHLSMasterPlaylist(job *entity.HLSJobEntity, resolutions []vo.ResolutionConfig) []byte {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n\n")
+	for _, res := range resolutions {
+		b.WriteString(hlsMasterEntry(res, HLSRenditionPlaylist(job, res.Resolution)))
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+// hlsMasterEntry 单路码流的 master playlist 条目
+func hlsMasterEntry(resolution vo.ResolutionConfig, playlistPath string) string {
+	bitrate, err := parseBitrateToBps(resolution.Bitrate)
+	if err != nil {
+		logger.Warnf("invalid HLS bitrate bitrate=%s err=%v", resolution.Bitrate, err)
+		bitrate = 0
+	}
+
+	height, err := parseResolutionHeight(resolution.Resolution)
+	if err != nil {
+		logger.Warnf("invalid HLS resolution resolution=%s err=%v", resolution.Resolution, err)
+		height = 0
+	}
+	width := 0
+	if height > 0 {
+		width = height * 16 / 9 // 假设16:9比例
+	}
+
+	return fmt.Sprintf("#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d\n%s",
+		bitrate, width, height, playlistPath)
+}
+
+// HLSMasterPublisher 按作业当前已完成的码流重新生成 master 并覆盖上传，
+// 单路码流重新编码后 master 与实际码流保持一致
+type HLSMasterPublisher struct {
+	cfg     *config.Config
+	storage gateway.StorageGateway
+}
+
+func NewHLSMasterPublisher(cfg *config.Config, storage gateway.StorageGateway) *HLSMasterPublisher {
+	return &HLSMasterPublisher{cfg: cfg, storage: storage}
+}
+
+// Publish 上传重新生成的 master，返回对象 key 与收录的分辨率；没有已完成码流时返回 ErrNoCompletedRenditions
+func (p *HLSMasterPublisher) Publish(ctx context.Context, job *entity.HLSJobEntity) (string, []string, error) {
+	resolutions := HLSMasterResolutions(job)
+	if len(resolutions) == 0 {
+		return "", nil, ErrNoCompletedRenditions
+	}
+	f, err := os.CreateTemp("", "hls-master-*.m3u8")
+	if err != nil {
+		return "", nil, fmt.Errorf("create master playlist: %w", err)
+	}
+	local := f.Name()
+	defer os.Remove(local)
+	_, err = f.Write(BuildHLSMasterPlaylist(job, resolutions))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("write master playlist: %w", err)
+	}
+
+	key := path.Join(HLSObjectKeyPrefix(p.cfg, job), HLSMasterPlaylistName)
+	obj := gateway.UploadObject{LocalPath: local, ObjectKey: key, ContentType: vo.ContentTypeForExtension(filepath.Ext(local))}
+	if err := p.storage.UploadObjects(ctx, []gateway.UploadObject{obj}); err != nil {
+		metrics.Add("hls_master_regenerate_failures_total", 1)
+		return "", nil, fmt.Errorf("upload master playlist: %w", err)
+	}
+	names := make([]string, 0, len(resolutions))
+	for _, res := range resolutions {
+		names = append(names, res.Resolution)
+	}
+	metrics.Add("hls_master_regenerated_total", 1)
+	logger.WithContext(ctx).Infof("hls master playlist regenerated job_uuid=%s key=%s resolutions=%s", job.JobUUID(), key, strings.Join(names, ","))
+	return key, names, nil
+}
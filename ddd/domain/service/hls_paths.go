@@ -0,0 +1,64 @@
+package service
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/hlsname"
+	"transcode-service/pkg/utils"
+)
+
+const (
+	defaultHLSWorkDir      = "storage/hls"
+	defaultHLSObjectPrefix = "hls"
+)
+
+// HLSWorkDir 返回 HLS 作业的本地工作目录：<work_dir>/<user>/<video>/<job>；
+// 各级标识经 LocalFileName 处理，含 "/"、".." 等字符时替换为哈希名，不会越出 work_dir
+func HLSWorkDir(cfg *config.Config, userUUID, videoUUID, jobUUID string) string {
+	base := defaultHLSWorkDir
+	if cfg != nil && strings.TrimSpace(cfg.Transcode.HLS.WorkDir) != "" {
+		base = cfg.Transcode.HLS.WorkDir
+	}
+	return filepath.Join(base, utils.LocalFileName(userUUID), utils.LocalFileName(videoUUID), utils.LocalFileName(jobUUID))
+}
+
+// HLSObjectKeyPrefix 返回 HLS 作业在对象存储中的 key 前缀：<object_prefix>/<user>/<video>/<job>，
+// 私有作业为 private/<token>/hls/<job>，与同一视频的 MP4 产物共用签名授权目录。
+// 指定目标桶的作业为 buckets/<bucket>/<prefix>/hls/<user>/<video>/<job>。
+// 与本地工作目录相互独立，上传时按文件相对作业目录的路径拼接。
+func HLSObjectKeyPrefix(cfg *config.Config, job *entity.HLSJobEntity) string {
+	if job.IsPrivate() {
+		return path.Join(vo.PrivateKeyPrefix, job.PrivateToken(), defaultHLSObjectPrefix, job.JobUUID())
+	}
+	prefix := defaultHLSObjectPrefix
+	if cfg != nil && strings.TrimSpace(cfg.Transcode.HLS.ObjectPrefix) != "" {
+		prefix = strings.Trim(cfg.Transcode.HLS.ObjectPrefix, "/")
+	}
+	return vo.DestinationObjectKey(path.Join(prefix, job.UserUUID(), job.VideoUUID(), job.JobUUID()), job.OutputDestination())
+}
+
+// HLSNaming 返回码流播放列表与切片的文件名模板；模板已在加载配置时校验，无配置或模板无效时使用默认命名
+func HLSNaming(cfg *config.Config) *hlsname.Naming {
+	if cfg == nil {
+		return hlsname.Default()
+	}
+	naming, err := cfg.Transcode.HLS.Naming()
+	if err != nil {
+		return hlsname.Default()
+	}
+	return naming
+}
+
+// HLSObjectKey 根据作业目录内的本地文件计算对象 key
+func HLSObjectKey(cfg *config.Config, job *entity.HLSJobEntity, localPath string) (string, error) {
+	rel, err := filepath.Rel(filepath.Clean(job.OutputDir()), filepath.Clean(localPath))
+	if err != nil {
+		return "", err
+	}
+	return path.Join(HLSObjectKeyPrefix(cfg, job), filepath.ToSlash(rel)), nil
+}
@@ -2,22 +2,31 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"transcode-service/ddd/domain/entity"
 	"transcode-service/ddd/domain/port"
 	"transcode-service/ddd/domain/repo"
 	"transcode-service/ddd/domain/vo"
 	"transcode-service/ddd/infrastructure/database/persistence"
+	"transcode-service/pkg/clock"
 	"transcode-service/pkg/config"
+	"transcode-service/pkg/hlsname"
+	"transcode-service/pkg/hwaccel"
 	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
 )
 
+// ErrRenditionsFailed 部分码流切片失败；已成功码流的产物仍可上传，之后只重试失败的码流
+var ErrRenditionsFailed = errors.New("hls renditions failed")
+
 // HLSService HLS切片服务接口
 type HLSService interface {
 	GenerateHLSSlices(ctx context.Context, job *entity.HLSJobEntity, inputPath string) error
@@ -76,30 +85,55 @@ func (h *hlsServiceImpl) GenerateHLSSlices(ctx context.Context, job *entity.HLSJ
 	hlsConfig.SetStatus(vo.HLSStatusProcessing)
 	h.updateProgress(ctx, job, 0)
 
-	// 生成各分辨率的HLS切片
-	var masterPlaylistEntries []string
+	// 生成各分辨率的HLS切片；单路失败时记录原因并继续其余码流，便于之后只重试失败的码流
+	var masterResolutions []vo.ResolutionConfig
 	resolutions := hlsConfig.Resolutions
+	renditions := job.Renditions()
 
 	for i, resolution := range resolutions {
+		if r := renditions.Find(resolution.Resolution); r != nil && r.Status == vo.RenditionCompleted {
+			// 重试时已成功码流的产物已在存储中，只补写 master 条目
+			log.Infof("跳过已完成的分辨率 job_uuid=%s resolution=%s", job.JobUUID(), resolution.Resolution)
+			masterResolutions = append(masterResolutions, resolution)
+			continue
+		}
 		log.Infof("生成分辨率切片 job_uuid=%s resolution=%s bitrate=%s", job.JobUUID(), resolution.Resolution, resolution.Bitrate)
 
 		// 生成单个分辨率的HLS切片
-		playlistPath, err := h.generateResolutionHLS(ctx, job, inputPath, outputDir, resolution, i)
+		start := time.Now()
+		playlist, err := h.generateResolutionHLS(ctx, job, inputPath, outputDir, resolution, i)
 		if err != nil {
-			job.SetError(fmt.Sprintf("生成%s分辨率切片失败: %v", resolution.Resolution, err))
-			return err
+			if ctx.Err() != nil {
+				// 停机或取消：未完成的码流保持 pending
+				job.SetError(fmt.Sprintf("生成%s分辨率切片被中断: %v", resolution.Resolution, err))
+				return err
+			}
+			job.MarkRendition(resolution.Resolution, time.Since(start), 0, 0, err)
+			h.persistRenditions(ctx, job)
+			metrics.Add("hls_rendition_failed_total", 1)
+			metrics.Add("hls_rendition_"+strings.ToLower(resolution.Resolution)+"_failed_total", 1)
+			log.Warnf("分辨率切片失败，继续其余码流 job_uuid=%s resolution=%s error=%v", job.JobUUID(), resolution.Resolution, err)
+			continue
 		}
+		segments, bytes := renditionOutputStats(HLSNaming(h.cfg), outputDir, resolution.Resolution, job.JobUUID())
+		job.MarkRendition(resolution.Resolution, time.Since(start), segments, bytes, nil)
+		job.SetRenditionPlaylist(resolution.Resolution, playlist)
+		h.persistRenditions(ctx, job)
 
 		// 添加到master playlist
-		masterPlaylistEntries = append(masterPlaylistEntries, h.createMasterPlaylistEntry(resolution, playlistPath))
+		masterResolutions = append(masterResolutions, resolution)
 
 		progress := (i + 1) * 100 / len(resolutions) // 以分辨率维度粗粒度进度
 		h.updateProgress(ctx, job, progress)
 	}
+	if summary := job.Renditions().FailureSummary(200); summary != "" {
+		job.SetError("部分码流切片失败: " + summary)
+		return fmt.Errorf("%w: %s", ErrRenditionsFailed, summary)
+	}
 
 	// 生成master playlist
-	masterPlaylistPath := filepath.Join(outputDir, "master.m3u8")
-	if err := h.generateMasterPlaylist(masterPlaylistPath, masterPlaylistEntries); err != nil {
+	masterPlaylistPath := filepath.Join(outputDir, HLSMasterPlaylistName)
+	if err := os.WriteFile(masterPlaylistPath, BuildHLSMasterPlaylist(job, masterResolutions), 0644); err != nil {
 		job.SetError(fmt.Sprintf("生成master playlist失败: %v", err))
 		return err
 	}
@@ -108,6 +142,12 @@ func (h *hlsServiceImpl) GenerateHLSSlices(ctx context.Context, job *entity.HLSJ
 	job.SetMasterPlaylist(masterPlaylistPath)
 	job.SetOutputDir(outputDir)
 	job.SetStatus(vo.HLSStatusCompleted)
+	job.SetFingerprint(encoderFingerprint(ctx, h.cfg, job.Commands()))
+	if h.hlsRepo != nil {
+		if err := h.hlsRepo.UpdateHLSJobFingerprint(ctx, job.JobUUID(), job.Fingerprint()); err != nil {
+			h.logger.Warnf("persist encoder fingerprint failed job_uuid=%s error=%v", job.JobUUID(), err)
+		}
+	}
 
 	log.Infof("HLS切片生成完成 job_uuid=%s output_dir=%s master_path=%s", job.JobUUID(), outputDir, masterPlaylistPath)
 
@@ -116,44 +156,87 @@ func (h *hlsServiceImpl) GenerateHLSSlices(ctx context.Context, job *entity.HLSJ
 
 // generateResolutionHLS 生成单个分辨率的HLS切片
 func (h *hlsServiceImpl) generateResolutionHLS(ctx context.Context, job *entity.HLSJobEntity, inputPath, outputDir string, resolution vo.ResolutionConfig, index int) (string, error) {
-	hlsConfig := job.GetConfig()
-	ffcfg := h.cfg.Transcode.FFmpeg
+	args, playlistName, err := BuildHLSRenditionArgs(h.cfg, *job.GetConfig(), inputPath, outputDir, resolution, job.JobUUID())
+	if err != nil {
+		h.logger.Warnf("invalid HLS resolution; use source height job_uuid=%s resolution=%s err=%v",
+			job.JobUUID(), resolution.Resolution, err)
+	}
+
+	binary := "ffmpeg"
+	if h.cfg != nil {
+		binary = h.cfg.Transcode.FFmpeg.Binary()
+	}
+	window := hlsStallWindow(h.cfg)
+	args = withProgressArgs(args, window)
+	logger.WithContext(ctx).Debug(fmt.Sprintf("执行FFmpeg命令 job_uuid=%s command=%s", job.JobUUID(), binary+" "+strings.Join(args, " ")))
+	job.RecordCommand(vo.FFmpegCommand{Label: "hls_" + resolution.Resolution, Binary: binary, Args: args, RecordedAt: clock.Now()})
+	if h.hlsRepo != nil {
+		if err := h.hlsRepo.UpdateHLSJobCommands(ctx, job.JobUUID(), job.Commands()); err != nil {
+			h.logger.Warnf("persist ffmpeg command failed job_uuid=%s error=%v", job.JobUUID(), err)
+		}
+	}
+
+	// 执行FFmpeg命令；开启卡死检测时进度停滞超过窗口即终止，该码流按失败处理，可通过码流级重试重新切片
+	output, err := runFFmpegWatched(ctx, binary, args, window, "hls")
+	if err != nil {
+		logger.WithContext(ctx).Errorf("FFmpeg执行失败 job_uuid=%s error=%v output=%s", job.JobUUID(), err, string(output))
+		return "", fmt.Errorf("FFmpeg执行失败: %w, output: %s", err, string(output))
+	}
+
+	return playlistName, nil
+}
+
+// BuildHLSRenditionArgs 生成单个分辨率的 HLS 切片参数，返回参数与播放列表文件名；
+// 文件名按 transcode.hls 的命名模板生成，分辨率无法解析时保留源尺寸并返回解析错误
+func BuildHLSRenditionArgs(cfg *config.Config, hlsConfig vo.HLSConfig, inputPath, outputDir string, resolution vo.ResolutionConfig, jobUUID string) ([]string, string, error) {
+	var ffcfg config.FFmpegConfig
+	if cfg != nil {
+		ffcfg = cfg.Transcode.FFmpeg
+	}
 
 	videoCodec := "libx264"
 	if strings.TrimSpace(ffcfg.VideoCodec) != "" {
 		videoCodec = ffcfg.VideoCodec
 	}
-	lowerCodec := strings.ToLower(videoCodec)
 	hardwareAccel := strings.TrimSpace(ffcfg.HardwareAccel)
-	useHwDecode := ffcfg.UseHardwareDecode && strings.EqualFold(hardwareAccel, "cuda")
+	backend := hwaccel.Lookup(hardwareAccel)
+	if backend != nil && !backend.Encodes(videoCodec) {
+		backend = nil
+		hardwareAccel = ""
+	}
+	useHwDecode := ffcfg.UseHardwareDecode && backend != nil && backend.Decodes()
 	threads := ffcfg.Threads
 	if threads < 0 {
 		threads = 0
 	}
 
 	// 构建输出文件名
-	resolutionName := resolution.Resolution
-	playlistName := fmt.Sprintf("playlist_%s.m3u8", resolutionName)
-	segmentPattern := fmt.Sprintf("segment_%s_%%03d.ts", resolutionName)
+	naming := HLSNaming(cfg)
+	playlistName := naming.Playlist(resolution.Resolution, jobUUID)
+	segmentPattern := naming.SegmentPattern(resolution.Resolution, jobUUID)
 
 	playlistPath := filepath.Join(outputDir, playlistName)
 	segmentPath := filepath.Join(outputDir, segmentPattern)
 
 	// 根据配置解析目标高度，无法解析时回退为源尺寸
 	height, err := parseResolutionHeight(resolution.Resolution)
-	scaleFilter := ""
-	if err != nil {
-		h.logger.Warnf("invalid HLS resolution; use source height job_uuid=%s resolution=%s err=%v",
-			job.JobUUID(), resolution.Resolution, err)
-	} else {
+	scaleFilter, deviceScale := "", false
+	if err == nil {
 		scaleFilter = fmt.Sprintf("scale=-2:%d", height)
+		if backend != nil {
+			if f := backend.ScaleFilter(-2, height, useHwDecode); f != "" {
+				scaleFilter, deviceScale = f, true
+			}
+		}
 	}
 
-	// 构建FFmpeg命令
+	// 构建FFmpeg命令；输入编码未知，由 -hwaccel 选择解码器
 	args := make([]string, 0, 32)
 	if useHwDecode {
-		args = append(args, "-hwaccel", "cuda", "-hwaccel_output_format", "cuda", "-c:v", "h264_cuvid")
-	} else if hardwareAccel != "" && !strings.EqualFold(hardwareAccel, "cuda") {
+		args = append(args, backend.DecodeArgs("", hwaccel.DecodeOptions{Device: ffcfg.HardwareDevice, Threads: ffcfg.DecoderThreads})...)
+	} else if backend != nil {
+		args = append(args, backend.UploadArgs(ffcfg.HardwareDevice)...)
+	} else if hardwareAccel != "" {
 		args = append(args, "-hwaccel", hardwareAccel)
 	}
 	args = append(args,
@@ -161,18 +244,15 @@ func (h *hlsServiceImpl) generateResolutionHLS(ctx context.Context, job *entity.
 		"-analyzeduration", "5M",
 		"-i", inputPath,
 		"-c:v", videoCodec,
-		"-c:a", "aac",
 	)
-	if strings.Contains(lowerCodec, "nvenc") {
-		// NVENC: 用 scale_npp，目标格式使用 nv12 以避免 auto_scale 插入。
-		if scaleFilter != "" {
-			nppFilter := strings.ReplaceAll(scaleFilter, "scale=", "scale_npp=")
-			if !strings.Contains(nppFilter, "format=") {
-				nppFilter += ":format=nv12"
-			}
-			args = append(args, "-vf", nppFilter)
-		}
-		// 保持 GPU 链路，避免强制指定 nv12 触发 auto_scale 将帧拉回 CPU
+	if hlsConfig.NoAudio {
+		args = append(args, "-an")
+	} else {
+		args = append(args, "-c:a", "aac", "-b:a", "128k")
+	}
+	if deviceScale {
+		// 设备链路：帧留在设备上缩放，不再指定像素格式，避免 auto_scale 将帧拉回内存
+		args = append(args, "-vf", scaleFilter)
 	} else if scaleFilter != "" {
 		// CPU 路径：加上 format=yuv420p，防止自动插入不兼容的 auto_scale
 		cpuFilter := scaleFilter
@@ -180,15 +260,21 @@ func (h *hlsServiceImpl) generateResolutionHLS(ctx context.Context, job *entity.
 			cpuFilter = fmt.Sprintf("%s,format=yuv420p", cpuFilter)
 		}
 		args = append(args, "-vf", cpuFilter)
-	} else if !strings.Contains(lowerCodec, "nvenc") {
-		// 非 GPU 且无缩放时仍指定兼容像素格式
+	} else if !useHwDecode {
+		// 帧在内存中且无缩放时仍指定兼容像素格式
 		args = append(args, "-pix_fmt", "yuv420p")
 	}
 	args = append(args,
 		"-b:v", resolution.Bitrate,
-		"-b:a", "128k",
 		"-threads", strconv.Itoa(max(1, threads)),
-		"-sc_threshold", "0",
+	)
+	// 切片已按切片时长强制关键帧，档位的 GOP 结构只追加编码器参数；档位指定 scenecut 时替代默认的 -sc_threshold 0
+	gopArgs := resolution.GOP.EncoderArgs(videoCodec)
+	args = append(args, gopArgs...)
+	if !slices.Contains(gopArgs, "-sc_threshold") {
+		args = append(args, "-sc_threshold", "0")
+	}
+	args = append(args,
 		"-keyint_min", "48",
 		"-g", "48",
 		"-force_key_frames", fmt.Sprintf("expr:gte(t,n*%d)", hlsConfig.SegmentDuration),
@@ -199,32 +285,47 @@ func (h *hlsServiceImpl) generateResolutionHLS(ctx context.Context, job *entity.
 		"-f", "hls",
 		playlistPath,
 	)
+	return args, playlistName, err
+}
 
-	binary := "ffmpeg"
-	if h.cfg != nil && strings.TrimSpace(h.cfg.Transcode.FFmpeg.BinaryPath) != "" {
-		binary = h.cfg.Transcode.FFmpeg.BinaryPath
+// HLSRenditionPlaylist 作业中单路码流的播放列表文件名：优先取切片时记录的文件名，
+// 未记录时（历史作业或尚未切片）按默认命名
+func HLSRenditionPlaylist(job *entity.HLSJobEntity, resolution string) string {
+	if r := job.Renditions().Find(resolution); r != nil && r.Playlist != "" {
+		return r.Playlist
 	}
-	logger.WithContext(ctx).Debug(fmt.Sprintf("执行FFmpeg命令 job_uuid=%s command=%s", job.JobUUID(), binary+" "+strings.Join(args, " ")))
+	return hlsname.Default().Playlist(resolution, job.JobUUID())
+}
 
-	// 执行FFmpeg命令
-	cmd := exec.CommandContext(ctx, binary, args...)
-	output, err := cmd.CombinedOutput()
+// renditionOutputStats 统计单路码流的切片数与产物大小
+func renditionOutputStats(naming *hlsname.Naming, outputDir, resolution, jobUUID string) (int, int64) {
+	entries, err := os.ReadDir(outputDir)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("FFmpeg执行失败 job_uuid=%s error=%v output=%s", job.JobUUID(), err, string(output))
-		return "", fmt.Errorf("FFmpeg执行失败: %w, output: %s", err, string(output))
+		return 0, 0
 	}
-
-	return playlistName, nil
+	segments, bytes := 0, int64(0)
+	for _, e := range entries {
+		if e.IsDir() || !naming.IsRenditionFile(e.Name(), resolution, jobUUID) {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			bytes += info.Size()
+		}
+		if naming.IsSegment(e.Name(), resolution, jobUUID) {
+			segments++
+		}
+	}
+	return segments, bytes
 }
 
-// generateMasterPlaylist 生成master playlist
-func (h *hlsServiceImpl) generateMasterPlaylist(masterPath string, entries []string) error {
-	content := "#EXTM3U\n#EXT-X-VERSION:3\n\n"
-	for _, entry := range entries {
-		content += entry + "\n"
+// persistRenditions 码流状态写库失败只记录日志，作业结束时仍会随错误信息落库
+func (h *hlsServiceImpl) persistRenditions(ctx context.Context, job *entity.HLSJobEntity) {
+	if h.hlsRepo == nil {
+		return
+	}
+	if err := h.hlsRepo.UpdateHLSJobRenditions(ctx, job.JobUUID(), job.Renditions()); err != nil {
+		h.logger.Warnf("persist hls renditions failed job_uuid=%s error=%v", job.JobUUID(), err)
 	}
-
-	return os.WriteFile(masterPath, []byte(content), 0644)
 }
 
 // parseBitrateToBps 将 "2000k"/"2M"/"2000kbps"/"2mbps" 等解析为 bps
@@ -287,32 +388,12 @@ func max(a, b int) int {
 	return b
 }
 
-// createMasterPlaylistEntry 创建master playlist条目
-func (h *hlsServiceImpl) createMasterPlaylistEntry(resolution vo.ResolutionConfig, playlistPath string) string {
-	bitrate, err := parseBitrateToBps(resolution.Bitrate)
-	if err != nil {
-		h.logger.Warnf("invalid HLS bitrate bitrate=%s err=%v", resolution.Bitrate, err)
-		bitrate = 0
-	}
-
-	height, err := parseResolutionHeight(resolution.Resolution)
-	if err != nil {
-		h.logger.Warnf("invalid HLS resolution resolution=%s err=%v", resolution.Resolution, err)
-		height = 0
-	}
-	width := 0
-	if height > 0 {
-		width = height * 16 / 9 // 假设16:9比例
-	}
-
-	return fmt.Sprintf("#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d\n%s",
-		bitrate, width, height, playlistPath)
-}
-
-// generateOutputDir 生成输出目录路径
+// generateOutputDir 生成输出目录路径，优先使用作业创建时记录的目录
 func (h *hlsServiceImpl) generateOutputDir(job *entity.HLSJobEntity) string {
-	baseDir := "storage/hls"
-	return filepath.Join(baseDir, job.UserUUID(), job.VideoUUID(), job.JobUUID())
+	if dir := strings.TrimSpace(job.OutputDir()); dir != "" {
+		return filepath.Clean(dir)
+	}
+	return HLSWorkDir(h.cfg, job.UserUUID(), job.VideoUUID(), job.JobUUID())
 }
 
 func (h *hlsServiceImpl) updateProgress(ctx context.Context, job *entity.HLSJobEntity, progress int) {
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/metrics"
+)
+
+// hlsStallWindow HLS 切片的卡死判定窗口，worker.stall_watchdog 未启用时返回 0
+func hlsStallWindow(cfg *config.Config) time.Duration {
+	if cfg == nil || !cfg.Worker.StallWatchdog.Enabled {
+		return 0
+	}
+	return cfg.Worker.StallWatchdog.Window
+}
+
+// withProgressArgs 开启卡死检测时让 ffmpeg 把进度写到 stdout（-progress 为全局选项，放在最前）
+func withProgressArgs(args []string, window time.Duration) []string {
+	if window <= 0 {
+		return args
+	}
+	return append([]string{"-progress", "pipe:1", "-nostats"}, args...)
+}
+
+// runFFmpegWatched 执行 ffmpeg 并返回 stderr；window>0 时 out_time 超过 window（从进程启动起算）未推进即终止进程，
+// 返回 errno.ErrEncodeStalled。args 需已由 withProgressArgs 处理
+func runFFmpegWatched(ctx context.Context, binary string, args []string, window time.Duration, label string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, binary, args...)
+	if window <= 0 {
+		return cmd.CombinedOutput()
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// 进程被终止后最多再等 1s 读完输出，避免残留子进程占住管道
+	cmd.WaitDelay = time.Second
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("创建FFmpeg stdout管道失败: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动FFmpeg命令失败: %w", err)
+	}
+
+	advanced := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		last := ""
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "out_time_us=") || line == last {
+				continue
+			}
+			last = line
+			select {
+			case advanced <- struct{}{}:
+			default:
+			}
+		}
+		// 读完管道后才能 Wait
+		done <- cmd.Wait()
+	}()
+
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+	for {
+		select {
+		case <-advanced:
+			timer.Reset(window)
+		case <-timer.C:
+			if cmd.Process != nil {
+				_ = cmd.Process.Kill()
+			}
+			_ = stdout.Close()
+			<-done
+			metrics.Add("ffmpeg_stalls_total", 1)
+			metrics.Add("ffmpeg_stalls_"+label+"_total", 1)
+			return stderr.Bytes(), fmt.Errorf("%w: no progress for %s", errno.ErrEncodeStalled, window)
+		case err := <-done:
+			return stderr.Bytes(), err
+		}
+	}
+}
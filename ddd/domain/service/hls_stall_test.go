@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transcode-service/pkg/errno"
+)
+
+func TestRunFFmpegWatchedKillsStalledProcess(t *testing.T) {
+	start := time.Now()
+	_, err := runFFmpegWatched(context.Background(), "/bin/sh", []string{"-c", "echo out_time_us=1; exec sleep 10"}, 200*time.Millisecond, "hls")
+	if !errors.Is(err, errno.ErrEncodeStalled) {
+		t.Fatalf("err = %v, want ErrEncodeStalled", err)
+	}
+	if took := time.Since(start); took > 5*time.Second {
+		t.Fatalf("stalled process killed after %s", took)
+	}
+}
+
+func TestRunFFmpegWatchedAllowsAdvancingProcess(t *testing.T) {
+	script := "for i in 1 2 3 4 5 6; do echo out_time_us=$i; sleep 0.1; done"
+	if _, err := runFFmpegWatched(context.Background(), "/bin/sh", []string{"-c", script}, 300*time.Millisecond, "hls"); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+}
+
+func TestWithProgressArgs(t *testing.T) {
+	if got := withProgressArgs([]string{"-i", "in"}, 0); len(got) != 2 {
+		t.Fatalf("watchdog disabled should keep args, got %v", got)
+	}
+	got := withProgressArgs([]string{"-i", "in"}, time.Minute)
+	if len(got) != 5 || got[0] != "-progress" || got[1] != "pipe:1" {
+		t.Fatalf("got %v", got)
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+)
+
+// 阶梯来源
+const (
+	LadderSourcePreference = "preference"
+	LadderSourceConfig     = "config"
+)
+
+// defaultLadderBitrates 配置未覆盖时补齐的默认档位
+var defaultLadderBitrates = map[string]string{"1080p": "4000k", "720p": "2000k", "480p": "1000k"}
+
+// ResolveTaskLadder 任务的 HLS 阶梯：按任务的档位集合解析，profile=auto 时按规则裁剪，
+// 再按任务与档位集合中较严的 max_renditions 保留最高的若干档
+func ResolveTaskLadder(ctx context.Context, catalog *gateway.LadderCatalog, prefRepo repo.UserPreferenceRepository, userUUID string, params vo.TranscodeParams) ([]vo.ResolutionConfig, string) {
+	ladder, source := ResolveHLSLadder(ctx, catalog, prefRepo, userUUID, params.FormatSet)
+	if params.Profile != nil {
+		ladder = FilterLadder(ladder, params.Profile.Renditions)
+	}
+	limit := params.MaxRenditions
+	if catalog != nil {
+		if set, _, ok := catalog.ResolveFormatSet(params.FormatSet); ok && set.MaxRenditions > 0 && (limit <= 0 || set.MaxRenditions < limit) {
+			limit = set.MaxRenditions
+		}
+	}
+	return LimitLadder(ladder, limit), source
+}
+
+// LimitLadder 档位数超过 max 时按分辨率从高到低保留 max 档，保持原有顺序；max<=0 不限制
+func LimitLadder(ladder []vo.ResolutionConfig, max int) []vo.ResolutionConfig {
+	if max <= 0 || len(ladder) <= max {
+		return ladder
+	}
+	heights := make([]int, 0, len(ladder))
+	for _, rc := range ladder {
+		heights = append(heights, vo.ResolutionHeight(rc.Resolution))
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(heights)))
+	cutoff := heights[max-1]
+	out := make([]vo.ResolutionConfig, 0, max)
+	for _, rc := range ladder {
+		if len(out) < max && vo.ResolutionHeight(rc.Resolution) >= cutoff {
+			out = append(out, rc)
+		}
+	}
+	return out
+}
+
+// ResolveHLSLadder 计算 HLS 码率阶梯：用户偏好优先，未设置时使用档位集合的档位；
+// 未使用档位集合（全局 output_formats）时补齐默认档位，档位集合则以配置为准
+func ResolveHLSLadder(ctx context.Context, catalog *gateway.LadderCatalog, prefRepo repo.UserPreferenceRepository, userUUID, formatSet string) ([]vo.ResolutionConfig, string) {
+	if prefRepo != nil && userUUID != "" {
+		pref, err := prefRepo.GetUserPreference(ctx, userUUID)
+		if err != nil {
+			logger.Warnf("load user preference failed user_uuid=%s error=%v", userUUID, err)
+		} else if pref != nil && len(pref.Ladder()) > 0 {
+			return pref.Ladder(), LadderSourcePreference
+		}
+	}
+
+	variants := make([]vo.ResolutionConfig, 0, 4)
+	existed := map[string]struct{}{}
+	scoped := false
+	if catalog != nil {
+		set, name, ok := catalog.ResolveFormatSet(formatSet)
+		if !ok {
+			// 任务记录的档位集合已从配置中移除，退回全局档位
+			logger.Warnf("format set not configured, falling back to output_formats format_set=%s", name)
+			set = config.FormatSetConfig{OutputFormats: catalog.OutputFormats}
+		}
+		scoped = ok && name != ""
+		for _, of := range set.OutputFormats {
+			name := strings.TrimSpace(of.Name)
+			br := strings.TrimSpace(of.Bitrate)
+			if name == "" || br == "" {
+				continue
+			}
+			if rc, err := vo.NewResolutionConfig(name, br); err == nil {
+				// 目录加载时已校验
+				rc.GOP, _ = OutputFormatGOP(of)
+				if _, ok := existed[rc.Resolution]; !ok {
+					variants = append(variants, *rc)
+					existed[rc.Resolution] = struct{}{}
+				}
+			}
+		}
+	}
+	if scoped && len(variants) > 0 {
+		return variants, LadderSourceConfig
+	}
+	for _, res := range []string{"1080p", "720p", "480p"} {
+		if _, ok := existed[res]; !ok {
+			if rc, err := vo.NewResolutionConfig(res, defaultLadderBitrates[res]); err == nil {
+				variants = append(variants, *rc)
+			}
+		}
+	}
+	return variants, LadderSourceConfig
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// MilestoneNotifier 处理中向上游推送里程碑；同一任务的进度里程碑只推送一次，推送失败不影响处理
+type MilestoneNotifier struct {
+	reporter   gateway.TranscodeMilestoneReporter
+	progress   []int
+	hlsStarted bool
+	timeout    time.Duration
+	mu         sync.Mutex
+	sent       map[string]int // task_uuid -> 已推送的最高进度里程碑
+}
+
+// NewMilestoneNotifier 未启用或上报器不支持里程碑时返回 nil，nil 上的方法均为空操作
+func NewMilestoneNotifier(cfg *config.Config, reporter gateway.TranscodeResultReporter) *MilestoneNotifier {
+	if cfg == nil {
+		cfg = config.GetGlobalConfig()
+	}
+	if cfg == nil || !cfg.Dependencies.UploadService.Milestones.Enabled {
+		return nil
+	}
+	mr, ok := reporter.(gateway.TranscodeMilestoneReporter)
+	if !ok {
+		return nil
+	}
+	ms := cfg.Dependencies.UploadService.Milestones
+	return &MilestoneNotifier{
+		reporter:   mr,
+		progress:   ms.Progress,
+		hlsStarted: ms.HLSStarted,
+		timeout:    ms.Timeout,
+		sent:       make(map[string]int),
+	}
+}
+
+// Progress 进度越过里程碑时推送；一次越过多个时只推送最高的一个
+func (n *MilestoneNotifier) Progress(task *entity.TranscodeTaskEntity, pct int) {
+	if n == nil || task.GetParams().IsPreview() || task.IsReplay() {
+		return
+	}
+	reached := 0
+	for _, m := range n.progress {
+		if pct >= m {
+			reached = m
+		}
+	}
+	if reached == 0 {
+		return
+	}
+	n.mu.Lock()
+	if n.sent[task.TaskUUID()] >= reached {
+		n.mu.Unlock()
+		return
+	}
+	n.sent[task.TaskUUID()] = reached
+	n.mu.Unlock()
+	go n.send(task.VideoUUID(), task.TaskUUID(), gateway.MilestoneProgress(reached))
+}
+
+// HLSStarted HLS 作业开始切片时推送
+func (n *MilestoneNotifier) HLSStarted(videoUUID, taskUUID string) {
+	if n == nil || !n.hlsStarted {
+		return
+	}
+	go n.send(videoUUID, taskUUID, gateway.MilestoneHLSStarted)
+}
+
+// Forget 任务执行结束后清理已推送记录
+func (n *MilestoneNotifier) Forget(taskUUID string) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	delete(n.sent, taskUUID)
+	n.mu.Unlock()
+}
+
+func (n *MilestoneNotifier) send(videoUUID, taskUUID, milestone string) {
+	ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+	defer cancel()
+	if err := n.reporter.ReportMilestone(ctx, videoUUID, taskUUID, milestone); err != nil {
+		metrics.Add("milestone_notify_failures_total", 1)
+		logger.Warnf("milestone notify failed video_uuid=%s task_uuid=%s milestone=%s error=%v", videoUUID, taskUUID, milestone, err)
+		return
+	}
+	metrics.Add("milestone_notify_sent_total", 1)
+}
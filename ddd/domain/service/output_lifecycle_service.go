@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// restoreBatchSize 单轮最多处理的恢复请求数
+const restoreBatchSize = 50
+
+// OutputLifecycleService 产物分层存储：长期未访问的 MP4 产物复制到归档前缀后删除原对象，已请求恢复的复制回原位置
+type OutputLifecycleService interface {
+	// ArchiveIdle 归档一批冷产物，并收尾上一轮中断的归档，返回归档数量
+	ArchiveIdle(ctx context.Context) (int, error)
+	// RestoreRequested 处理 restoring 状态的任务，返回恢复完成的数量
+	RestoreRequested(ctx context.Context) (int, error)
+}
+
+type outputLifecycleServiceImpl struct {
+	transcodeRepo repo.TranscodeJobRepository
+	mover         gateway.ObjectMover
+	storage       gateway.StorageGateway
+	cfg           config.LifecycleConfig
+}
+
+// NewOutputLifecycleService 存储实现不支持服务端复制/删除时返回 nil
+func NewOutputLifecycleService(transcodeRepo repo.TranscodeJobRepository, storage gateway.StorageGateway, cfg config.LifecycleConfig) OutputLifecycleService {
+	mover, ok := storage.(gateway.ObjectMover)
+	if !ok {
+		logger.Warnf("storage lifecycle disabled: storage gateway does not support server-side copy")
+		return nil
+	}
+	return &outputLifecycleServiceImpl{transcodeRepo: transcodeRepo, mover: mover, storage: storage, cfg: cfg}
+}
+
+func (s *outputLifecycleServiceImpl) ArchiveIdle(ctx context.Context) (int, error) {
+	s.recoverInterrupted(ctx)
+	candidates, err := s.transcodeRepo.QueryArchiveCandidates(ctx, clock.Now().Add(-s.cfg.ColdAfter), s.cfg.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("query archive candidates: %w", err)
+	}
+	archived := 0
+	for _, task := range candidates {
+		if ctx.Err() != nil {
+			break
+		}
+		if s.archive(ctx, task) {
+			archived++
+		}
+	}
+	return archived, nil
+}
+
+// archive 先占位 archiving（多副本下只有一个实例处理），复制成功后再删除原对象；任一步失败回到 standard
+func (s *outputLifecycleServiceImpl) archive(ctx context.Context, task *entity.TranscodeTaskEntity) bool {
+	storage := task.OutputStorage()
+	storage.Tier = vo.StorageTierArchiving
+	ok, err := s.transcodeRepo.SwapTranscodeOutputStorage(ctx, task.TaskUUID(), []vo.StorageTier{vo.StorageTierStandard}, storage)
+	if err != nil || !ok {
+		return false
+	}
+	src := task.OutputPath()
+	dst := vo.ArchiveKey(src, s.cfg.ArchiveDir)
+	if err := s.mover.CopyObject(ctx, src, dst, s.cfg.StorageClass); err != nil {
+		s.archiveFailed(ctx, task, "copy", err)
+		return false
+	}
+	if err := s.mover.RemoveObject(ctx, src); err != nil {
+		_ = s.mover.RemoveObject(ctx, dst)
+		s.archiveFailed(ctx, task, "remove", err)
+		return false
+	}
+	now := clock.Now()
+	storage.Tier, storage.ArchivePath, storage.StorageClass, storage.ArchivedAt = vo.StorageTierArchived, dst, s.cfg.StorageClass, &now
+	storage.RestoreRequestedAt, storage.RestoredAt = nil, nil
+	if _, err := s.transcodeRepo.SwapTranscodeOutputStorage(ctx, task.TaskUUID(), []vo.StorageTier{vo.StorageTierArchiving}, storage); err != nil {
+		// 对象已迁移，状态留在 archiving，由下一轮 recoverInterrupted 按对象是否存在收尾
+		logger.Warnf("record archived output failed task_uuid=%s archive_path=%s error=%v", task.TaskUUID(), dst, err)
+		return false
+	}
+	metrics.Add("outputs_archived_total", 1)
+	logger.Infof("output archived task_uuid=%s output_path=%s archive_path=%s storage_class=%s",
+		task.TaskUUID(), src, dst, s.cfg.StorageClass)
+	return true
+}
+
+func (s *outputLifecycleServiceImpl) archiveFailed(ctx context.Context, task *entity.TranscodeTaskEntity, step string, err error) {
+	metrics.Add("outputs_archive_failed_total", 1)
+	logger.Warnf("archive output failed task_uuid=%s step=%s error=%v", task.TaskUUID(), step, err)
+	if _, err := s.transcodeRepo.SwapTranscodeOutputStorage(ctx, task.TaskUUID(), []vo.StorageTier{vo.StorageTierArchiving}, task.OutputStorage()); err != nil {
+		logger.Warnf("revert archiving output failed task_uuid=%s error=%v", task.TaskUUID(), err)
+	}
+}
+
+// recoverInterrupted 处理停在 archiving 超过两个周期的任务（实例在归档中途退出）：原对象仍在则回到 standard，否则记为 archived
+func (s *outputLifecycleServiceImpl) recoverInterrupted(ctx context.Context) {
+	tasks, err := s.transcodeRepo.QueryTranscodeJobsByStorageTier(ctx, vo.StorageTierArchiving, s.cfg.BatchSize)
+	if err != nil {
+		logger.Warnf("query interrupted archives failed error=%v", err)
+		return
+	}
+	staleBefore := clock.Now().Add(-2 * s.cfg.Interval)
+	for _, task := range tasks {
+		if task.UpdatedAt().After(staleBefore) {
+			continue
+		}
+		storage := task.OutputStorage()
+		_, statErr := s.storage.StatObject(ctx, task.OutputPath())
+		switch {
+		case statErr == nil:
+			storage.Tier = vo.StorageTierStandard
+		case gateway.IsObjectNotFound(statErr):
+			now := clock.Now()
+			storage.Tier, storage.ArchivePath, storage.StorageClass, storage.ArchivedAt = vo.StorageTierArchived, vo.ArchiveKey(task.OutputPath(), s.cfg.ArchiveDir), s.cfg.StorageClass, &now
+		default:
+			continue
+		}
+		if _, err := s.transcodeRepo.SwapTranscodeOutputStorage(ctx, task.TaskUUID(), []vo.StorageTier{vo.StorageTierArchiving}, storage); err == nil {
+			logger.Warnf("interrupted archive recovered task_uuid=%s tier=%s", task.TaskUUID(), storage.Tier)
+		}
+	}
+}
+
+func (s *outputLifecycleServiceImpl) RestoreRequested(ctx context.Context) (int, error) {
+	tasks, err := s.transcodeRepo.QueryTranscodeJobsByStorageTier(ctx, vo.StorageTierRestoring, restoreBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("query restoring outputs: %w", err)
+	}
+	restored := 0
+	for _, task := range tasks {
+		if ctx.Err() != nil {
+			break
+		}
+		storage := task.OutputStorage()
+		// 需要先解冻的存储类别（如 GLACIER）复制会失败，保持 restoring 下一轮重试
+		if err := s.mover.CopyObject(ctx, storage.ArchivePath, task.OutputPath(), ""); err != nil {
+			metrics.Add("outputs_restore_failed_total", 1)
+			logger.Warnf("restore output failed, will retry task_uuid=%s archive_path=%s error=%v", task.TaskUUID(), storage.ArchivePath, err)
+			continue
+		}
+		archivePath := storage.ArchivePath
+		now := clock.Now()
+		storage.Tier, storage.ArchivePath, storage.StorageClass, storage.ArchivedAt, storage.RestoredAt = vo.StorageTierStandard, "", "", nil, &now
+		ok, err := s.transcodeRepo.SwapTranscodeOutputStorage(ctx, task.TaskUUID(), []vo.StorageTier{vo.StorageTierRestoring}, storage)
+		if err != nil || !ok {
+			continue
+		}
+		if err := s.mover.RemoveObject(ctx, archivePath); err != nil {
+			logger.Warnf("remove archived copy failed task_uuid=%s archive_path=%s error=%v", task.TaskUUID(), archivePath, err)
+		}
+		restored++
+		metrics.Add("outputs_restored_total", 1)
+		var waited time.Duration
+		if storage.RestoreRequestedAt != nil {
+			waited = now.Sub(*storage.RestoreRequestedAt).Round(time.Second)
+		}
+		logger.Infof("output restored task_uuid=%s output_path=%s waited=%s", task.TaskUUID(), task.OutputPath(), waited)
+	}
+	return restored, nil
+}
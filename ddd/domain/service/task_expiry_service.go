@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+const (
+	// expiryBatchSize 单轮最多处理的任务数
+	expiryBatchSize = 200
+	// stalledProcessingGrace processing 任务需超过该时长无进度更新才会被判定过期，避免打断正在编码的任务
+	stalledProcessingGrace = 15 * time.Minute
+)
+
+// TaskExpiryService 截止时间检查：超时未完成的任务标记为 expired 并通知上游
+type TaskExpiryService interface {
+	ExpireOverdue(ctx context.Context) (int, error)
+}
+
+type taskExpiryServiceImpl struct {
+	transcodeRepo repo.TranscodeJobRepository
+	reporter      gateway.TranscodeResultReporter
+	cfg           config.ExpiryConfig
+}
+
+// NewTaskExpiryService 创建任务过期服务
+func NewTaskExpiryService(transcodeRepo repo.TranscodeJobRepository, reporter gateway.TranscodeResultReporter, cfg config.ExpiryConfig) TaskExpiryService {
+	return &taskExpiryServiceImpl{transcodeRepo: transcodeRepo, reporter: reporter, cfg: cfg}
+}
+
+func (s *taskExpiryServiceImpl) ExpireOverdue(ctx context.Context) (int, error) {
+	minTTL := s.cfg.MinTTL()
+	if minTTL <= 0 {
+		return 0, nil
+	}
+	now := clock.Now()
+	candidates, err := s.transcodeRepo.QueryActiveTranscodeJobsCreatedBefore(ctx, now.Add(-minTTL), expiryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("query overdue tasks: %w", err)
+	}
+	expired := 0
+	for _, task := range candidates {
+		if task == nil || !s.overdue(task, now) {
+			continue
+		}
+		ttl := s.cfg.TTLFor(task.Priority(), task.GetParams().Resolution)
+		reason := fmt.Sprintf("task expired: not finished within %s", ttl)
+		prev := task.Status()
+		if err := task.TransitionTo(vo.TaskStatusExpired); err != nil {
+			continue
+		}
+		task.SetErrorMessage(reason)
+		ok, err := s.transcodeRepo.ExpireTranscodeJob(ctx, task)
+		if err != nil {
+			logger.Warnf("expire task failed task_uuid=%s error=%v", task.TaskUUID(), err)
+			continue
+		}
+		if !ok {
+			// 任务已在其他路径结束
+			continue
+		}
+		expired++
+		metrics.Add("tasks_expired_total", 1)
+		metrics.Add("tasks_expired_"+prev.String(), 1)
+		logger.Warnf("task expired task_uuid=%s video_uuid=%s status=%s priority=%d ttl=%s age=%s",
+			task.TaskUUID(), task.VideoUUID(), prev.String(), task.Priority(), ttl, now.Sub(task.CreatedAt()).Truncate(time.Second))
+		if s.reporter != nil {
+			if err := s.reporter.ReportExpired(ctx, task.VideoUUID(), task.TaskUUID(), reason); err != nil {
+				logger.Warnf("report expired task failed task_uuid=%s error=%v", task.TaskUUID(), err)
+			}
+		}
+	}
+	return expired, nil
+}
+
+func (s *taskExpiryServiceImpl) overdue(task *entity.TranscodeTaskEntity, now time.Time) bool {
+	ttl := s.cfg.TTLFor(task.Priority(), task.GetParams().Resolution)
+	if ttl <= 0 || now.Sub(task.CreatedAt()) < ttl {
+		return false
+	}
+	if task.IsProcessing() && now.Sub(task.UpdatedAt()) < stalledProcessingGrace {
+		return false
+	}
+	return task.IsPending() || task.IsProcessing()
+}
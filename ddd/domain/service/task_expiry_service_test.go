@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+)
+
+// expiryRepo 只实现过期检查用到的方法，其余方法调用会 panic
+type expiryRepo struct {
+	repo.TranscodeJobRepository
+	tasks []*entity.TranscodeTaskEntity
+}
+
+func (r *expiryRepo) QueryActiveTranscodeJobsCreatedBefore(_ context.Context, before time.Time, _ int) ([]*entity.TranscodeTaskEntity, error) {
+	var out []*entity.TranscodeTaskEntity
+	for _, t := range r.tasks {
+		if (t.IsPending() || t.IsProcessing()) && t.CreatedAt().Before(before) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (r *expiryRepo) ExpireTranscodeJob(context.Context, *entity.TranscodeTaskEntity) (bool, error) {
+	return true, nil
+}
+
+// TestExpireOverdueWithManualClock 用手动时钟推进时间，验证截止时长与 processing 任务的无进度宽限期
+func TestExpireOverdueWithManualClock(t *testing.T) {
+	m := clock.NewManual(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock.Install(m, clock.NewSequence("task"))
+	t.Cleanup(func() { clock.Install(clock.System(), clock.UUIDs()) })
+
+	pending := entity.DefaultTranscodeTaskEntity("user", "v1", "", "raw/v1.mp4", vo.TranscodeParams{Resolution: "720p"})
+	encoding := entity.DefaultTranscodeTaskEntity("user", "v2", "", "raw/v2.mp4", vo.TranscodeParams{Resolution: "720p"})
+	if pending.TaskUUID() != "task-1" || encoding.TaskUUID() != "task-2" {
+		t.Fatalf("task ids = %s, %s, want task-1, task-2", pending.TaskUUID(), encoding.TaskUUID())
+	}
+	if err := encoding.TransitionTo(vo.TaskStatusProcessing); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewTaskExpiryService(&expiryRepo{tasks: []*entity.TranscodeTaskEntity{pending, encoding}}, nil, config.ExpiryConfig{DefaultTTL: time.Hour})
+
+	m.Advance(55 * time.Minute)
+	encoding.SetProgress(80)
+	if n, err := svc.ExpireOverdue(context.Background()); err != nil || n != 0 {
+		t.Fatalf("before ttl expired=%d err=%v, want 0", n, err)
+	}
+
+	m.Advance(6 * time.Minute)
+	if n, err := svc.ExpireOverdue(context.Background()); err != nil || n != 1 {
+		t.Fatalf("after ttl expired=%d err=%v, want 1", n, err)
+	}
+	if pending.Status() != vo.TaskStatusExpired {
+		t.Fatalf("pending task status = %s, want expired", pending.Status())
+	}
+	if encoding.Status() != vo.TaskStatusProcessing {
+		t.Fatalf("encoding task with recent progress status = %s, want processing", encoding.Status())
+	}
+
+	m.Advance(stalledProcessingGrace)
+	if n, err := svc.ExpireOverdue(context.Background()); err != nil || n != 1 {
+		t.Fatalf("after stall grace expired=%d err=%v, want 1", n, err)
+	}
+	if encoding.Status() != vo.TaskStatusExpired {
+		t.Fatalf("stalled task status = %s, want expired", encoding.Status())
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/port"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// taskGroupBatchSize 单轮最多检查的任务组数
+const taskGroupBatchSize = 200
+
+// callbackClaimLease 认领回调后超过该时长仍未写回结果（实例崩溃等）时，其他实例可重新认领
+const callbackClaimLease = 5 * time.Minute
+
+// TaskGroupCompletedEvent 任务组完成回调的事件名
+const TaskGroupCompletedEvent = "task_group.completed"
+
+// TaskGroupService 任务组完成检查：组内任务全部结束的 open 组标记为 completed，并投递（或重试）完成回调
+type TaskGroupService interface {
+	CheckCompletion(ctx context.Context) (completed, delivered int, err error)
+}
+
+type taskGroupServiceImpl struct {
+	repo        repo.TaskGroupRepository
+	sender      port.TaskGroupCallbackSender
+	maxAttempts int
+}
+
+// NewTaskGroupService 创建任务组完成检查服务
+func NewTaskGroupService(repo repo.TaskGroupRepository, sender port.TaskGroupCallbackSender, maxAttempts int) TaskGroupService {
+	return &taskGroupServiceImpl{repo: repo, sender: sender, maxAttempts: maxAttempts}
+}
+
+func (s *taskGroupServiceImpl) CheckCompletion(ctx context.Context) (int, int, error) {
+	groups, err := s.repo.QueryOpenTaskGroups(ctx, taskGroupBatchSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("query open task groups: %w", err)
+	}
+	completed := 0
+	for _, g := range groups {
+		progress, err := s.repo.CountGroupTasks(ctx, g.GroupUUID())
+		if err != nil {
+			logger.Warnf("count task group tasks failed group_uuid=%s error=%v", g.GroupUUID(), err)
+			continue
+		}
+		if !g.ReadyToComplete(progress) {
+			continue
+		}
+		g.Complete(clock.Now())
+		ok, err := s.repo.CompleteTaskGroup(ctx, g)
+		if err != nil {
+			logger.Warnf("complete task group failed group_uuid=%s error=%v", g.GroupUUID(), err)
+			continue
+		}
+		if ok {
+			completed++
+			metrics.Add("task_group_completed_total", 1)
+			logger.Infof("task group completed group_uuid=%s total=%d completed=%d failed=%d cancelled=%d",
+				g.GroupUUID(), progress.Total, progress.Completed, progress.Failed, progress.Cancelled)
+		}
+	}
+
+	pending, err := s.repo.QueryPendingGroupCallbacks(ctx, clock.Now().Add(-callbackClaimLease), taskGroupBatchSize)
+	if err != nil {
+		return completed, 0, fmt.Errorf("query pending task group callbacks: %w", err)
+	}
+	delivered := 0
+	for _, g := range pending {
+		if s.deliver(ctx, g) {
+			delivered++
+		}
+	}
+	return completed, delivered, nil
+}
+
+// deliver 认领后投递一次完成回调并持久化结果，未认领到（其他实例正在投递）时跳过
+func (s *taskGroupServiceImpl) deliver(ctx context.Context, g *entity.TaskGroupEntity) bool {
+	claimed, err := s.repo.ClaimGroupCallback(ctx, g, clock.Now().Add(-callbackClaimLease))
+	if err != nil {
+		logger.Warnf("claim task group callback failed group_uuid=%s error=%v", g.GroupUUID(), err)
+		return false
+	}
+	if !claimed {
+		metrics.Add("task_group_callback_claim_conflicts_total", 1)
+		return false
+	}
+	g.ClaimCallback()
+	progress, err := s.repo.CountGroupTasks(ctx, g.GroupUUID())
+	if err != nil {
+		logger.Warnf("count task group tasks failed group_uuid=%s error=%v", g.GroupUUID(), err)
+		s.releaseClaim(ctx, g)
+		return false
+	}
+	completion := vo.TaskGroupCompletion{
+		Event:     TaskGroupCompletedEvent,
+		GroupUUID: g.GroupUUID(),
+		UserUUID:  g.UserUUID(),
+		Name:      g.Name(),
+		Progress:  progress,
+	}
+	if at := g.CompletedAt(); at != nil {
+		completion.CompletedAt = at.UTC()
+	}
+	sendErr := s.sender.SendGroupCompletion(ctx, g.CallbackURL(), completion)
+	g.RecordCallbackAttempt(sendErr, s.maxAttempts)
+	if err := s.repo.SaveGroupCallbackState(ctx, g); err != nil {
+		logger.Warnf("save task group callback state failed group_uuid=%s error=%v", g.GroupUUID(), err)
+	}
+	if sendErr != nil {
+		metrics.Add("task_group_callback_failures_total", 1)
+		logger.Warnf("task group callback failed group_uuid=%s attempts=%d state=%s error=%v", g.GroupUUID(), g.CallbackAttempts(), g.CallbackState(), sendErr)
+		return false
+	}
+	metrics.Add("task_group_callback_delivered_total", 1)
+	return true
+}
+
+// releaseClaim 未投递时退回 pending，下一轮重试
+func (s *taskGroupServiceImpl) releaseClaim(ctx context.Context, g *entity.TaskGroupEntity) {
+	g.ReleaseCallback()
+	if err := s.repo.SaveGroupCallbackState(ctx, g); err != nil {
+		logger.Warnf("release task group callback claim failed group_uuid=%s error=%v", g.GroupUUID(), err)
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+)
+
+// groupRepo 只实现完成检查与回调投递用到的方法，认领按内存中的状态做条件更新
+type groupRepo struct {
+	repo.TaskGroupRepository
+	groups []*entity.TaskGroupEntity
+	states map[string]vo.GroupCallbackState
+}
+
+func (r *groupRepo) QueryOpenTaskGroups(context.Context, int) ([]*entity.TaskGroupEntity, error) {
+	return nil, nil
+}
+
+func (r *groupRepo) CountGroupTasks(context.Context, string) (vo.TaskGroupProgress, error) {
+	var p vo.TaskGroupProgress
+	p.Add(vo.TaskStatusCompleted, 2, 200)
+	return p, nil
+}
+
+func (r *groupRepo) QueryPendingGroupCallbacks(context.Context, time.Time, int) ([]*entity.TaskGroupEntity, error) {
+	return r.groups, nil
+}
+
+func (r *groupRepo) ClaimGroupCallback(_ context.Context, g *entity.TaskGroupEntity, _ time.Time) (bool, error) {
+	if r.states[g.GroupUUID()] != vo.GroupCallbackPending {
+		return false, nil
+	}
+	r.states[g.GroupUUID()] = vo.GroupCallbackDelivering
+	return true, nil
+}
+
+func (r *groupRepo) SaveGroupCallbackState(_ context.Context, g *entity.TaskGroupEntity) error {
+	r.states[g.GroupUUID()] = g.CallbackState()
+	return nil
+}
+
+type countingSender struct {
+	sent int
+	err  error
+}
+
+func (s *countingSender) SendGroupCompletion(context.Context, string, vo.TaskGroupCompletion) error {
+	s.sent++
+	return s.err
+}
+
+func pendingGroup(groupUUID string) *entity.TaskGroupEntity {
+	at := clock.Now()
+	return entity.RestoreTaskGroup(groupUUID, "user", "S01", "https://cms.example.com/hook", 0, vo.TaskGroupCompleted,
+		vo.GroupCallbackPending, 0, "", &at, nil, at, at)
+}
+
+// TestCheckCompletionDeliversOnceAcrossInstances 两个实例查到同一个待投递回调，只有认领成功的实例发送
+func TestCheckCompletionDeliversOnceAcrossInstances(t *testing.T) {
+	r := &groupRepo{
+		groups: []*entity.TaskGroupEntity{pendingGroup("g1")},
+		states: map[string]vo.GroupCallbackState{"g1": vo.GroupCallbackPending},
+	}
+	a, b := &countingSender{}, &countingSender{}
+	// 第二个实例拿到的是认领前查询出的同一行
+	stale := []*entity.TaskGroupEntity{pendingGroup("g1")}
+
+	if _, delivered, err := NewTaskGroupService(r, a, 3).CheckCompletion(context.Background()); err != nil || delivered != 1 {
+		t.Fatalf("first instance delivered=%d err=%v, want 1", delivered, err)
+	}
+	r.states["g1"] = vo.GroupCallbackDelivering
+	r.groups = stale
+	if _, delivered, err := NewTaskGroupService(r, b, 3).CheckCompletion(context.Background()); err != nil || delivered != 0 {
+		t.Fatalf("second instance delivered=%d err=%v, want 0", delivered, err)
+	}
+	if a.sent != 1 || b.sent != 0 {
+		t.Fatalf("sent a=%d b=%d, want exactly one delivery", a.sent, b.sent)
+	}
+}
+
+func TestCheckCompletionReturnsFailedCallbackToPending(t *testing.T) {
+	r := &groupRepo{
+		groups: []*entity.TaskGroupEntity{pendingGroup("g1")},
+		states: map[string]vo.GroupCallbackState{"g1": vo.GroupCallbackPending},
+	}
+	s := &countingSender{err: errors.New("503")}
+	svc := NewTaskGroupService(r, s, 2)
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := svc.CheckCompletion(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.sent != 2 {
+		t.Fatalf("sent = %d, want a retry after the first failure", s.sent)
+	}
+	if got := r.states["g1"]; got != vo.GroupCallbackFailed {
+		t.Fatalf("state = %s, want failed after max attempts", got)
+	}
+}
+
+func TestReadyToCompleteWaitsForBatch(t *testing.T) {
+	now := clock.Now()
+	until := now.Add(time.Minute)
+	g := entity.RestoreTaskGroup("g1", "user", "S01", "", 0, vo.TaskGroupOpen, vo.GroupCallbackNone, 0, "", nil, &until, now, now)
+	var p vo.TaskGroupProgress
+	p.Add(vo.TaskStatusCompleted, 1, 100)
+	if g.ReadyToComplete(p) {
+		t.Fatal("group completed while a batch creation is in progress")
+	}
+	past := now.Add(-time.Second)
+	g = entity.RestoreTaskGroup("g1", "user", "S01", "", 0, vo.TaskGroupOpen, vo.GroupCallbackNone, 0, "", nil, &past, now, now)
+	if !g.ReadyToComplete(p) {
+		t.Fatal("group should complete once the batch hold has expired")
+	}
+}
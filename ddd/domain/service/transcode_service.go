@@ -9,8 +9,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/google/uuid"
-
 	"transcode-service/ddd/domain/entity"
 	"transcode-service/ddd/domain/gateway"
 	"transcode-service/ddd/domain/port"
@@ -18,45 +16,64 @@ import (
 	"transcode-service/ddd/domain/vo"
 	"transcode-service/ddd/infrastructure/executor"
 	"transcode-service/ddd/infrastructure/queue"
+	"transcode-service/pkg/clock"
 	"transcode-service/pkg/config"
+	"transcode-service/pkg/ctxutil"
+	"transcode-service/pkg/errno"
 	"transcode-service/pkg/grpcutil"
 	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
 )
 
 // TranscodeService 转码领域服务
 type TranscodeService interface {
-	// ExecuteTranscode 执行转码任务
-	ExecuteTranscode(ctx context.Context, task *entity.TranscodeTaskEntity) error
+	// ExecuteTranscode 执行转码任务；产物交给上传池时返回 port.ErrUploadDeferred，
+	// 上传结束且任务状态落库后以最终结果调用 onUploaded（可为 nil）
+	ExecuteTranscode(ctx context.Context, task *entity.TranscodeTaskEntity, onUploaded func(err error)) error
 }
 
 type transcodeServiceImpl struct {
 	transcodeRepo  repo.TranscodeJobRepository
 	hlsRepo        repo.HLSJobRepository
+	prefRepo       repo.UserPreferenceRepository
 	storageGateway gateway.StorageGateway
 	cfg            *config.Config
+	ladders        gateway.LadderCatalogProvider
 	resultReporter gateway.TranscodeResultReporter
 	executor       port.TranscodeExecutor
 	progressSink   port.ProgressSink
+	milestones     *MilestoneNotifier
 	progressMu     sync.Mutex
 	lastPersist    map[string]time.Time
 }
 
 // NewTranscodeService 创建转码领域服务
-func NewTranscodeService(transcodeRepo repo.TranscodeJobRepository, hlsRepo repo.HLSJobRepository, storage gateway.StorageGateway, cfg *config.Config, reporter gateway.TranscodeResultReporter, executor port.TranscodeExecutor, sink port.ProgressSink) TranscodeService {
+func NewTranscodeService(transcodeRepo repo.TranscodeJobRepository, hlsRepo repo.HLSJobRepository, prefRepo repo.UserPreferenceRepository, storage gateway.StorageGateway, cfg *config.Config, ladders gateway.LadderCatalogProvider, reporter gateway.TranscodeResultReporter, executor port.TranscodeExecutor, sink port.ProgressSink) TranscodeService {
 	return &transcodeServiceImpl{
 		transcodeRepo:  transcodeRepo,
 		hlsRepo:        hlsRepo,
+		prefRepo:       prefRepo,
 		storageGateway: storage,
 		cfg:            cfg,
+		ladders:        ladders,
 		resultReporter: reporter,
 		executor:       executor,
 		progressSink:   sink,
+		milestones:     NewMilestoneNotifier(cfg, reporter),
 		lastPersist:    make(map[string]time.Time),
 	}
 }
 
+// ladderCatalog 未注入缓存时直接取配置文件中的档位
+func (s *transcodeServiceImpl) ladderCatalog(ctx context.Context) *gateway.LadderCatalog {
+	if s.ladders != nil {
+		return s.ladders.LadderCatalog(ctx)
+	}
+	return gateway.LadderCatalogFromConfig(s.cfg)
+}
+
 // ExecuteTranscode 执行转码任务
-func (s *transcodeServiceImpl) ExecuteTranscode(ctx context.Context, task *entity.TranscodeTaskEntity) error {
+func (s *transcodeServiceImpl) ExecuteTranscode(ctx context.Context, task *entity.TranscodeTaskEntity, onUploaded func(err error)) error {
 	logger.Infof("start transcode task task_uuid=%s video_uuid=%s resolution=%s bitrate=%s",
 		task.TaskUUID(), task.VideoUUID(), task.GetParams().Resolution, task.GetParams().Bitrate)
 
@@ -73,67 +90,145 @@ func (s *transcodeServiceImpl) ExecuteTranscode(ctx context.Context, task *entit
 		return err
 	}
 	task.SetProgress(0)
+	task.SetStages(vo.StageProgress{})
 	task.SetErrorMessage("")
-	if err := s.updateJobStatus(ctx, task, vo.TaskStatusProcessing, ""); err != nil {
+	if err := s.updateJobStatus(ctx, task); err != nil {
 		return fmt.Errorf("更新任务状态失败: %w", err)
 	}
 	defer s.clearProgressThrottle(task.TaskUUID())
 
+	// 任务在处理中被取消（如源文件被新代数取代）时中止执行
+	execCtx, cancelExec := context.WithCancel(ctx)
+	defer cancelExec()
+	go s.watchCancellation(execCtx, cancelExec, task.TaskUUID())
+	ctx = execCtx
+
+	// 上传交给上传池时编码协程立即返回，上传结束后在上传池协程内完成收尾
+	asyncCtx := context.WithoutCancel(ctx)
 	opt := port.TranscodeOptions{
-		SkipUpload: s.cfg != nil && s.cfg.Transcode.SkipFullUpload,
+		// 预览/重放产物始终上传，供人工确认画质
+		SkipUpload: s.cfg != nil && s.cfg.Transcode.SkipFullUpload && !task.GetParams().IsPreview() && !task.IsReplay(),
+		BitrateCap: s.bitrateCapPolicy(),
+		RequestID:  grpcutil.RequestIDFromContext(ctx),
 		ProgressCb: func(p int) {
-			s.setProgress(task, float64(p), 100)
+			s.setStageProgress(ctx, task, vo.StageEncode, p)
+		},
+		StageCb: func(stage vo.PipelineStage, p int) {
+			// 上传阶段可能在上传池协程内回调，此时执行上下文已结束
+			stageCtx := ctx
+			if stage == vo.StageUpload {
+				stageCtx = asyncCtx
+			}
+			s.setStageProgress(stageCtx, task, stage, p)
+		},
+		CommandCb: func(cmd vo.FFmpegCommand) {
+			task.RecordCommand(cmd)
+			if err := s.transcodeRepo.UpdateTranscodeJobCommands(ctx, task.TaskUUID(), task.Commands()); err != nil {
+				logger.Warnf("persist ffmpeg command failed task_uuid=%s error=%v", task.TaskUUID(), err)
+			}
 		},
 	}
+	opt.TraceCb = func(trace *vo.ResourceTrace) {
+		// 采样协程回调，只持久化不修改实体；执行结束时上下文可能已取消
+		if err := s.transcodeRepo.UpdateTranscodeJobResourceTrace(asyncCtx, task.TaskUUID(), trace); err != nil {
+			logger.Warnf("persist resource trace failed task_uuid=%s error=%v", task.TaskUUID(), err)
+		}
+	}
+	opt.OnUploaded = func(objectKey string, err error) {
+		defer s.clearProgressThrottle(task.TaskUUID())
+		err = s.completeTranscode(asyncCtx, task, opt, objectKey, err)
+		if onUploaded != nil {
+			onUploaded(err)
+		}
+	}
 	uploadedKey, _, err := s.executor.Execute(ctx, task, opt)
+	if errors.Is(err, port.ErrUploadDeferred) {
+		logger.Infof("transcode encode finished, upload handed to upload pool task_uuid=%s", task.TaskUUID())
+		return err
+	}
+	return s.completeTranscode(ctx, task, opt, uploadedKey, err)
+}
+
+// completeTranscode 按执行（或上传池上传）结果落库任务终态，成功时触发 HLS
+func (s *transcodeServiceImpl) completeTranscode(ctx context.Context, task *entity.TranscodeTaskEntity, opt port.TranscodeOptions, uploadedKey string, err error) error {
+	// 执行上下文可能已被停机或外部取消结束，终态仍须在宽限内落库
+	interrupted := ctx.Err() != nil
+	ctx, cancel := s.finalWriteContext(ctx)
+	defer cancel()
 	if err != nil {
+		if s.cancelledExternally(ctx, task.TaskUUID()) {
+			// 库中已是 cancelled，不再覆盖为 failed
+			logger.Infof("transcode task aborted after cancellation task_uuid=%s generation=%d", task.TaskUUID(), task.SourceGeneration())
+			return nil
+		}
+		if errors.Is(err, errno.ErrInputRejected) {
+			s.rejectTask(ctx, task, err)
+			return nil
+		}
+		if errors.Is(err, errno.ErrProbeFailed) {
+			logger.Warnf("transcode input probe failed task_uuid=%s error_class=probe error=%v", task.TaskUUID(), err)
+		}
+		if errors.Is(err, port.ErrUploadAborted) && s.scheduleAbortedUploadRetry(ctx, task, err) {
+			return fmt.Errorf("上传被停机中止，已安排重试: %w", err)
+		}
+		if errors.Is(err, errno.ErrEncodeStalled) && s.scheduleStallRetry(ctx, task, err) {
+			return fmt.Errorf("编码卡死，已安排重试: %w", err)
+		}
+		if gateway.IsStorageUnavailable(err) && s.scheduleStorageRetry(ctx, task, err) {
+			return fmt.Errorf("存储不可用，已安排重试: %w", err)
+		}
+		if interrupted && s.cfg != nil && s.cfg.Worker.Snapshot.Enabled {
+			// 实例停止导致中断，保持 processing，由流水线快照对账后重新排队
+			return fmt.Errorf("转码被中断: %w", err)
+		}
 		_ = task.TransitionTo(vo.TaskStatusFailed)
 		task.SetErrorMessage(err.Error())
-		_ = s.updateJobStatus(ctx, task, vo.TaskStatusFailed, task.ErrorMessage())
+		_ = s.updateJobStatus(ctx, task)
 		return fmt.Errorf("转码执行失败: %w", err)
 	}
 
+	if s.cancelledExternally(ctx, task.TaskUUID()) {
+		// 编码结束前被取代，不覆盖取消状态，也不为旧源文件生成 HLS
+		logger.Infof("transcode task finished after cancellation, result discarded task_uuid=%s generation=%d", task.TaskUUID(), task.SourceGeneration())
+		return nil
+	}
 	if !opt.SkipUpload {
 		task.SetOutputPath(uploadedKey)
 	} else {
 		task.SetOutputPath("")
 	}
 	_ = task.TransitionTo(vo.TaskStatusCompleted)
+	for _, stage := range vo.TranscodeStages {
+		task.SetStageProgress(stage, 100)
+	}
 	task.SetProgress(100)
 	task.SetErrorMessage("")
 
+	// 预览、重放任务与纯音频源不生成 HLS；阶梯在落库前解析，按源码率封顶的结果随任务一起保存
+	var variants []vo.ResolutionConfig
+	if task.SourceStreams().AudioOnly() {
+		logger.Infof("audio-only source, HLS skipped task_uuid=%s", task.TaskUUID())
+	} else if !task.GetParams().IsPreview() && !task.IsReplay() {
+		variants, _ = ResolveTaskLadder(ctx, s.ladderCatalog(ctx), s.prefRepo, task.UserUUID(), task.GetParams())
+		variants = s.capLadder(task, variants)
+	}
+	task.SetFingerprint(encoderFingerprint(ctx, s.cfg, task.Commands()))
 	if err := s.transcodeRepo.UpdateTranscodeJob(ctx, task); err != nil {
-		errorMsg := fmt.Sprintf("更新任务完成状态失败: %v", err)
-		_ = task.TransitionTo(vo.TaskStatusFailed)
-		task.SetErrorMessage(errorMsg)
-		_ = s.updateJobStatus(ctx, task, vo.TaskStatusFailed, task.ErrorMessage())
+		// completed 未落库，丢弃未发布的事件；库中仍为 processing，由卡住任务回收重新排队
+		task.PullEvents()
 		return fmt.Errorf("更新任务完成状态失败: %w", err)
 	}
+	recordTimingMetrics(task)
 
-	variants := make([]vo.ResolutionConfig, 0, 4)
-	existed := map[string]struct{}{}
-	if s.cfg != nil && len(s.cfg.Transcode.OutputFormats) > 0 {
-		for _, of := range s.cfg.Transcode.OutputFormats {
-			name := strings.TrimSpace(of.Name)
-			br := strings.TrimSpace(of.Bitrate)
-			if name == "" || br == "" {
-				continue
-			}
-			if rc, err := vo.NewResolutionConfig(name, br); err == nil {
-				if _, ok := existed[rc.Resolution]; !ok {
-					variants = append(variants, *rc)
-					existed[rc.Resolution] = struct{}{}
-				}
-			}
-		}
+	if task.GetParams().IsPreview() {
+		// 预览任务不生成 HLS，也不触发上游发布
+		logger.Infof("preview task finished task_uuid=%s output_path=%s preview_seconds=%d", task.TaskUUID(), uploadedKey, task.GetParams().PreviewSeconds)
+		return nil
 	}
-	defaults := map[string]string{"1080p": "4000k", "720p": "2000k", "480p": "1000k"}
-	for res, br := range defaults {
-		if _, ok := existed[res]; !ok {
-			if rc, err := vo.NewResolutionConfig(res, br); err == nil {
-				variants = append(variants, *rc)
-			}
-		}
+	if task.IsReplay() {
+		// 重放任务只用于排查，同样不生成 HLS
+		logger.Infof("replay task finished task_uuid=%s parent_task_uuid=%s output_path=%s", task.TaskUUID(), task.ParentTaskUUID(), uploadedKey)
+		return nil
 	}
 
 	inputForHLS := uploadedKey
@@ -142,14 +237,17 @@ func (s *transcodeServiceImpl) ExecuteTranscode(ctx context.Context, task *entit
 	}
 	if len(variants) > 0 && s.hlsRepo != nil {
 		if hcfg, err2 := vo.NewHLSConfig(true, variants); err2 == nil {
-			hJobUUID := uuid.New().String()
-			outputDir := filepath.ToSlash(filepath.Join("storage/hls", task.UserUUID(), task.VideoUUID(), hJobUUID))
+			// 无音频源：MP4 已补静音音轨时沿用，否则切片不输出音轨
+			hcfg.NoAudio = task.SourceStreams().NoAudio() || (task.SourceStreams().SilentAudio() && inputForHLS == task.OriginalPath())
+			hJobUUID := clock.NewID()
+			outputDir := filepath.ToSlash(HLSWorkDir(s.cfg, task.UserUUID(), task.VideoUUID(), hJobUUID))
 			hJob := entity.NewHLSJobEntity(hJobUUID, task.UserUUID(), task.VideoUUID(), inputForHLS, outputDir, *hcfg)
 			src := task.TaskUUID()
 			hJob.SetSource(&src, "transcoded")
+			hJob.SetPrivateToken(task.PrivateToken())
+			hJob.SetOutputDestination(task.OutputDestination())
 			hJob.SetRequestID(grpcutil.RequestIDFromContext(ctx))
-			_ = s.hlsRepo.CreateHLSJob(ctx, hJob)
-			_ = queue.DefaultHLSJobQueue().Enqueue(ctx, hJob)
+			s.ensureHLSJob(ctx, task, hJob)
 		}
 	}
 
@@ -159,41 +257,224 @@ func (s *transcodeServiceImpl) ExecuteTranscode(ctx context.Context, task *entit
 	return nil
 }
 
+// ensureHLSJob 每个转码任务只保留一个 HLS 作业：任务重试后再次完成时复用已有作业，
+// 已完成或排队/处理中的作业直接跳过，失败的作业重置失败码流后重新排队
+func (s *transcodeServiceImpl) ensureHLSJob(ctx context.Context, task *entity.TranscodeTaskEntity, hJob *entity.HLSJobEntity) {
+	job, created, err := s.hlsRepo.GetOrCreateHLSJob(ctx, hJob)
+	if err != nil || job == nil {
+		logger.Errorf("create hls job failed task_uuid=%s error=%v", task.TaskUUID(), err)
+		return
+	}
+	if created {
+		_ = queue.DefaultHLSJobQueue().Enqueue(ctx, job)
+		return
+	}
+	if job.Status() != vo.HLSStatusFailed.String() {
+		metrics.Add("hls_job_duplicates_skipped_total", 1)
+		logger.Infof("hls job already exists, creation skipped task_uuid=%s job_uuid=%s status=%s", task.TaskUUID(), job.JobUUID(), job.Status())
+		return
+	}
+	job.ResetFailedRenditions()
+	ok, err := s.hlsRepo.ResetHLSJobForRetry(ctx, job.JobUUID(), job.Renditions())
+	if err != nil || !ok {
+		// 并发重试已将作业置回 pending
+		logger.Warnf("reset existing hls job failed task_uuid=%s job_uuid=%s reset=%t error=%v", task.TaskUUID(), job.JobUUID(), ok, err)
+		return
+	}
+	job.SetStatus(vo.HLSStatusPending)
+	job.SetError("")
+	job.SetProgress(0)
+	_ = queue.DefaultHLSJobQueue().Enqueue(ctx, job)
+	metrics.Add("hls_job_retries_total", 1)
+	logger.Infof("existing failed hls job re-queued task_uuid=%s job_uuid=%s", task.TaskUUID(), job.JobUUID())
+}
+
+// recordTimingMetrics 已完成任务的排队与各阶段耗时计入直方图，区分编码慢与存储慢
+func recordTimingMetrics(task *entity.TranscodeTaskEntity) {
+	timings := task.Timings()
+	if wait, ok := timings.QueueWait(task.CreatedAt()); ok {
+		metrics.ObserveDuration("task_queue_wait_seconds", wait)
+	}
+	for _, stage := range vo.TranscodeStages {
+		if d, ok := timings.StageDuration(stage); ok {
+			metrics.ObserveDuration("task_stage_"+stage.String()+"_seconds", d)
+		}
+	}
+}
+
+// watchCancellation 周期性回读任务状态，发现已取消时取消执行上下文
+func (s *transcodeServiceImpl) watchCancellation(ctx context.Context, cancel context.CancelFunc, taskUUID string) {
+	interval := 10 * time.Second
+	if s.cfg != nil && s.cfg.Transcode.CancelCheckInterval > 0 {
+		interval = s.cfg.Transcode.CancelCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.cancelledExternally(ctx, taskUUID) {
+				metrics.Add("transcode_inflight_cancelled_total", 1)
+				logger.Infof("task cancelled while processing, aborting ffmpeg task_uuid=%s", taskUUID)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// cancelledExternally 任务在库中是否已被置为 cancelled
+func (s *transcodeServiceImpl) cancelledExternally(ctx context.Context, taskUUID string) bool {
+	cur, err := s.transcodeRepo.GetTranscodeJob(ctx, taskUUID)
+	return err == nil && cur != nil && cur.Status() == vo.TaskStatusCancelled
+}
+
+// finalWriteContext 收尾写入使用：脱离执行上下文的取消，最长 worker.final_write_timeout
+func (s *transcodeServiceImpl) finalWriteContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	var grace time.Duration
+	if s.cfg != nil {
+		grace = s.cfg.Worker.FinalWriteTimeout
+	}
+	return ctxutil.Detached(ctx, grace)
+}
+
 // updateJobStatus 封装状态更新，统一使用任务当前的输出路径与进度。
-func (s *transcodeServiceImpl) updateJobStatus(ctx context.Context, task *entity.TranscodeTaskEntity, status vo.TaskStatus, message string) error {
+// scheduleStorageRetry 存储瞬时故障时将任务置为 retrying 并按指数退避安排重试，超过最大次数返回 false
+func (s *transcodeServiceImpl) scheduleStorageRetry(ctx context.Context, task *entity.TranscodeTaskEntity, cause error) bool {
+	if s.cfg == nil || task.RetryCount() >= s.cfg.Worker.StorageRetry.MaxAttempts {
+		return false
+	}
+	backoff := s.cfg.Worker.StorageRetry.Backoff(task.RetryCount() + 1)
+	if err := task.ScheduleRetry(clock.Now().Add(backoff), cause.Error()); err != nil {
+		return false
+	}
+	if err := s.transcodeRepo.ScheduleTranscodeJobRetry(ctx, task); err != nil {
+		logger.Errorf("schedule storage retry failed task_uuid=%s error=%v", task.TaskUUID(), err)
+		return false
+	}
+	logger.Warnf("storage unavailable, task scheduled for retry task_uuid=%s attempt=%d backoff=%s error=%v", task.TaskUUID(), task.RetryCount(), backoff, cause)
+	return true
+}
+
+// scheduleAbortedUploadRetry 上传池停机中止上传后将任务置为立即到期的 retrying，不受存储重试次数上限限制，
+// 由任一实例的重试恢复重新编码
+func (s *transcodeServiceImpl) scheduleAbortedUploadRetry(ctx context.Context, task *entity.TranscodeTaskEntity, cause error) bool {
+	if err := task.ScheduleRetry(clock.Now(), cause.Error()); err != nil {
+		return false
+	}
+	if err := s.transcodeRepo.ScheduleTranscodeJobRetry(ctx, task); err != nil {
+		logger.Errorf("schedule aborted upload retry failed task_uuid=%s error=%v", task.TaskUUID(), err)
+		return false
+	}
+	metrics.Add("transcode_upload_abort_retries_total", 1)
+	logger.Warnf("upload aborted by shutdown, task scheduled for retry task_uuid=%s error=%v", task.TaskUUID(), cause)
+	return true
+}
+
+// scheduleStallRetry ffmpeg 卡死被终止后将任务置为 retrying，任务重试次数达到 stall_watchdog.max_retries 时返回 false
+func (s *transcodeServiceImpl) scheduleStallRetry(ctx context.Context, task *entity.TranscodeTaskEntity, cause error) bool {
+	if s.cfg == nil || task.RetryCount() >= s.cfg.Worker.StallWatchdog.MaxRetries {
+		metrics.Add("transcode_stall_retries_exhausted_total", 1)
+		return false
+	}
+	backoff := s.cfg.Worker.StallWatchdog.Backoff
+	if err := task.ScheduleRetry(clock.Now().Add(backoff), cause.Error()); err != nil {
+		return false
+	}
+	if err := s.transcodeRepo.ScheduleTranscodeJobRetry(ctx, task); err != nil {
+		logger.Errorf("schedule stall retry failed task_uuid=%s error=%v", task.TaskUUID(), err)
+		return false
+	}
+	metrics.Add("transcode_stall_retries_total", 1)
+	logger.Warnf("ffmpeg stalled, task scheduled for retry task_uuid=%s attempt=%d backoff=%s error=%v", task.TaskUUID(), task.RetryCount(), backoff, cause)
+	return true
+}
+
+// bitrateCapPolicy 码率封顶策略，未启用或下限无效时返回 nil
+func (s *transcodeServiceImpl) bitrateCapPolicy() *vo.BitrateCapPolicy {
+	if s.cfg == nil || !s.cfg.Transcode.BitrateCap.Enabled {
+		return nil
+	}
+	c := s.cfg.Transcode.BitrateCap
+	floor, err := parseBitrateToBps(c.Floor)
+	if err != nil {
+		logger.Warnf("invalid transcode.bitrate_cap.floor, bitrate cap disabled floor=%s error=%v", c.Floor, err)
+		return nil
+	}
+	return &vo.BitrateCapPolicy{Factor: c.Factor, Factors: c.Factors, FloorKbps: floor / 1000}
+}
+
+// capLadder 按执行阶段记录的源码率封顶 HLS 阶梯，逐档结果记录到任务
+func (s *transcodeServiceImpl) capLadder(task *entity.TranscodeTaskEntity, ladder []vo.ResolutionConfig) []vo.ResolutionConfig {
+	policy, adj := s.bitrateCapPolicy(), task.BitrateAdjustment()
+	if policy == nil || adj == nil || adj.SourceKbps <= 0 || len(ladder) == 0 {
+		return ladder
+	}
+	capped, records := policy.Apply(ladder, adj.SourceKbps)
+	adj.Renditions = records
+	for _, r := range records {
+		if r.Dropped {
+			metrics.Add("bitrate_cap_dropped_renditions_total", 1)
+			logger.Infof("hls rendition dropped by bitrate cap task_uuid=%s resolution=%s requested=%s source_kbps=%d",
+				task.TaskUUID(), r.Resolution, r.Requested, adj.SourceKbps)
+		} else if r.Adjusted() {
+			metrics.Add("bitrate_cap_capped_renditions_total", 1)
+		}
+	}
+	return capped
+}
+
+// rejectTask 输入超限以 rejected 终态结束，不重试、不计入失败，并通知上游
+func (s *transcodeServiceImpl) rejectTask(ctx context.Context, task *entity.TranscodeTaskEntity, cause error) {
+	_ = task.TransitionTo(vo.TaskStatusRejected)
+	task.SetErrorMessage(cause.Error())
+	if err := s.updateJobStatus(ctx, task); err != nil {
+		logger.Errorf("persist rejected task failed task_uuid=%s error=%v", task.TaskUUID(), err)
+	}
+	metrics.Add("tasks_rejected_total", 1)
+	if s.resultReporter != nil {
+		if err := s.resultReporter.ReportRejected(ctx, task.VideoUUID(), task.TaskUUID(), cause.Error()); err != nil {
+			logger.Warnf("report rejected task failed task_uuid=%s error=%v", task.TaskUUID(), err)
+		}
+	}
+}
+
+func (s *transcodeServiceImpl) updateJobStatus(ctx context.Context, task *entity.TranscodeTaskEntity) error {
 	if s.transcodeRepo == nil {
 		return errors.New("transcodeRepo is nil")
 	}
-	return s.transcodeRepo.UpdateTranscodeJobStatus(ctx, task.TaskUUID(), status, message, task.OutputPath(), task.Progress())
+	return s.transcodeRepo.SaveTranscodeJobStatus(ctx, task)
 }
 
-func (s *transcodeServiceImpl) setProgress(task *entity.TranscodeTaskEntity, currentSec, totalSec float64) {
-	if totalSec <= 0 {
-		return
-	}
-	pct := int((currentSec / totalSec) * 100)
+// setStageProgress 更新阶段进度，任务进度为下载/编码/上传三阶段按权重合成的结果
+func (s *transcodeServiceImpl) setStageProgress(ctx context.Context, task *entity.TranscodeTaskEntity, stage vo.PipelineStage, stagePct int) {
+	task.SetStageProgress(stage, stagePct)
+	pct := task.StageProgress().Composite(vo.TranscodeStages...)
 	if pct > 99 {
 		pct = 99
 	}
-	if pct < 0 {
-		pct = 0
-	}
 	task.SetProgress(pct)
+	s.milestones.Progress(task, pct)
+	// 阶段开始/结束时立即落库，阶段内按分钟节流
+	boundary := stage != vo.StageEncode || stagePct >= 100
 	shouldPersist := false
-	now := time.Now()
+	now := clock.Now()
 	s.progressMu.Lock()
 	last := s.lastPersist[task.TaskUUID()]
-	if last.IsZero() || now.Sub(last) >= time.Minute {
+	if boundary || last.IsZero() || now.Sub(last) >= time.Minute {
 		s.lastPersist[task.TaskUUID()] = now
 		shouldPersist = true
 	}
 	s.progressMu.Unlock()
-	if shouldPersist {
+	// 执行已取消（停机/外部取消）时不再写中间进度，终态由 completeTranscode 落库
+	if shouldPersist && ctx.Err() == nil {
 		if sink := s.progressWriter(); sink != nil {
-			if err := sink.SaveProgress(context.Background(), task, pct); err != nil {
+			if err := sink.SaveProgress(ctx, task, pct); err != nil {
 				logger.Errorf("update transcode progress failed task_uuid=%s progress=%d error=%s", task.TaskUUID(), pct, err.Error())
 			}
-		} else if err := s.transcodeRepo.UpdateTranscodeJobProgress(context.Background(), task.TaskUUID(), pct); err != nil {
+		} else if err := s.transcodeRepo.UpdateTranscodeJobProgress(ctx, task.TaskUUID(), pct); err != nil {
 			logger.Errorf("update transcode progress failed task_uuid=%s progress=%d error=%s", task.TaskUUID(), pct, err.Error())
 		}
 	}
@@ -211,20 +492,12 @@ func (s *transcodeServiceImpl) progressWriter() port.ProgressSink {
 }
 
 func detectHLSContentType(path string) string {
-	switch strings.ToLower(filepath.Ext(path)) {
-	case ".m3u8":
-		return "application/vnd.apple.mpegurl"
-	case ".ts":
-		return "video/mp2t"
-	case ".mp4":
-		return "video/mp4"
-	default:
-		return "application/octet-stream"
-	}
+	return vo.ContentTypeForExtension(filepath.Ext(path))
 }
 
 func (s *transcodeServiceImpl) clearProgressThrottle(taskUUID string) {
 	s.progressMu.Lock()
 	delete(s.lastPersist, taskUUID)
 	s.progressMu.Unlock()
+	s.milestones.Forget(taskUUID)
 }
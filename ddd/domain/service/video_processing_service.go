@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/repo"
+)
+
+// VideoProcessingService 加载视频维度的处理聚合
+type VideoProcessingService interface {
+	Load(ctx context.Context, videoUUID string) (*entity.VideoProcessing, error)
+}
+
+type videoProcessingServiceImpl struct {
+	transcodeRepo repo.TranscodeJobRepository
+	hlsRepo       repo.HLSJobRepository
+}
+
+// NewVideoProcessingService 创建视频聚合服务
+func NewVideoProcessingService(transcodeRepo repo.TranscodeJobRepository, hlsRepo repo.HLSJobRepository) VideoProcessingService {
+	return &videoProcessingServiceImpl{transcodeRepo: transcodeRepo, hlsRepo: hlsRepo}
+}
+
+func (s *videoProcessingServiceImpl) Load(ctx context.Context, videoUUID string) (*entity.VideoProcessing, error) {
+	var tasks []*entity.TranscodeTaskEntity
+	if s.transcodeRepo != nil {
+		list, err := s.transcodeRepo.QueryTranscodeJobsByVideo(ctx, videoUUID)
+		if err != nil {
+			return nil, fmt.Errorf("query transcode jobs: %w", err)
+		}
+		tasks = list
+	}
+	var hlsJobs []*entity.HLSJobEntity
+	if s.hlsRepo != nil {
+		list, err := s.hlsRepo.QueryHLSJobsByVideo(ctx, videoUUID)
+		if err != nil {
+			return nil, fmt.Errorf("query hls jobs: %w", err)
+		}
+		hlsJobs = list
+	}
+	return entity.NewVideoProcessing(videoUUID, tasks, hlsJobs), nil
+}
@@ -0,0 +1,176 @@
+package vo
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AudioFormat 纯音频产物格式
+type AudioFormat string
+
+const (
+	AudioFormatM4A AudioFormat = "m4a"
+	AudioFormatMP3 AudioFormat = "mp3"
+)
+
+// 纯音频产物的数量与标签限制
+const (
+	MaxAudioRenditions = 4
+	maxAudioTagValue   = 256
+	// DefaultAudioBitrate 未指定码率时使用
+	DefaultAudioBitrate = "128k"
+)
+
+// audioTagKeys 允许写入产物的元数据标签（ffmpeg -metadata 键名）
+var audioTagKeys = map[string]struct{}{
+	"title": {}, "artist": {}, "album": {}, "album_artist": {}, "genre": {},
+	"date": {}, "comment": {}, "composer": {}, "copyright": {},
+}
+
+// ParseAudioFormat 解析纯音频格式
+func ParseAudioFormat(s string) (AudioFormat, error) {
+	switch f := AudioFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case AudioFormatM4A, AudioFormatMP3:
+		return f, nil
+	default:
+		return "", fmt.Errorf("不支持的音频格式: %s", s)
+	}
+}
+
+// Extension 文件扩展名（含点）
+func (f AudioFormat) Extension() string {
+	return "." + string(f)
+}
+
+// ContentType 上传对象存储使用的 MIME 类型
+func (f AudioFormat) ContentType() string {
+	return ContentTypeForExtension(f.Extension())
+}
+
+// Codec ffmpeg 音频编码器
+func (f AudioFormat) Codec() string {
+	if f == AudioFormatMP3 {
+		return "libmp3lame"
+	}
+	return "aac"
+}
+
+// Muxer ffmpeg -f 使用的封装器名称，m4a 使用 ipod 封装以兼容播客客户端
+func (f AudioFormat) Muxer() string {
+	if f == AudioFormatMP3 {
+		return "mp3"
+	}
+	return "ipod"
+}
+
+// AudioRendition 单个纯音频产物，ObjectKey 在建任务时按视频与码率生成
+type AudioRendition struct {
+	Format    AudioFormat `json:"format"`
+	Bitrate   string      `json:"bitrate"`
+	ObjectKey string      `json:"object_key,omitempty"`
+}
+
+// AudioOutputs 随视频一起生成的纯音频产物（如播客重发布）及写入的元数据标签
+type AudioOutputs struct {
+	Renditions []AudioRendition  `json:"renditions"`
+	Tags       map[string]string `json:"tags,omitempty"`
+}
+
+// NewAudioOutputs 校验格式、码率与标签；格式与码率相同的产物只保留一个
+func NewAudioOutputs(renditions []AudioRendition, tags map[string]string) (*AudioOutputs, error) {
+	if len(renditions) == 0 {
+		return nil, fmt.Errorf("至少需要一个音频产物")
+	}
+	if len(renditions) > MaxAudioRenditions {
+		return nil, fmt.Errorf("音频产物最多 %d 个", MaxAudioRenditions)
+	}
+	out := &AudioOutputs{}
+	seen := make(map[string]bool, len(renditions))
+	for _, r := range renditions {
+		format, err := ParseAudioFormat(string(r.Format))
+		if err != nil {
+			return nil, err
+		}
+		bitrate := strings.ToLower(strings.TrimSpace(r.Bitrate))
+		if bitrate == "" {
+			bitrate = DefaultAudioBitrate
+		}
+		if err := validateBitrate(bitrate); err != nil {
+			return nil, err
+		}
+		if key := string(format) + "/" + bitrate; !seen[key] {
+			seen[key] = true
+			out.Renditions = append(out.Renditions, AudioRendition{Format: format, Bitrate: bitrate})
+		}
+	}
+	for k, v := range tags {
+		if _, ok := audioTagKeys[k]; !ok {
+			return nil, fmt.Errorf("不支持的音频标签: %s", k)
+		}
+		if len(v) > maxAudioTagValue {
+			return nil, fmt.Errorf("音频标签 %s 超过 %d 字符", k, maxAudioTagValue)
+		}
+	}
+	if len(tags) > 0 {
+		out.Tags = make(map[string]string, len(tags))
+		for k, v := range tags {
+			out.Tags[k] = v
+		}
+	}
+	return out, nil
+}
+
+// Clone 深拷贝，避免任务之间共享产物 key
+func (a *AudioOutputs) Clone() *AudioOutputs {
+	if a == nil {
+		return nil
+	}
+	out := &AudioOutputs{Renditions: append([]AudioRendition(nil), a.Renditions...)}
+	if a.Tags != nil {
+		out.Tags = make(map[string]string, len(a.Tags))
+		for k, v := range a.Tags {
+			out.Tags[k] = v
+		}
+	}
+	return out
+}
+
+// MetadataArgs ffmpeg -metadata 参数，按键排序保证命令稳定
+func (a *AudioOutputs) MetadataArgs() []string {
+	if a == nil || len(a.Tags) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(a.Tags))
+	for k := range a.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, len(keys)*2)
+	for _, k := range keys {
+		args = append(args, "-metadata", k+"="+a.Tags[k])
+	}
+	return args
+}
+
+// ToJSON 序列化为 JSON
+func (a *AudioOutputs) ToJSON() (string, error) {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// AudioOutputsFromJSON 从 JSON 反序列化，空串或解析失败返回 nil
+func AudioOutputsFromJSON(data string) *AudioOutputs {
+	if data == "" {
+		return nil
+	}
+	var a AudioOutputs
+	if err := json.Unmarshal([]byte(data), &a); err != nil || len(a.Renditions) == 0 {
+		return nil
+	}
+	return &a
+}
@@ -0,0 +1,67 @@
+package vo
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// SourceHints 调用方随请求提供的源文件特征与热度预测，用于 profile=auto 选择编码配置
+type SourceHints struct {
+	Width           int     `json:"width"`
+	Height          int     `json:"height"`
+	FPS             float64 `json:"fps"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Popularity      string  `json:"popularity"` // 预测热度，如 high/normal/low
+}
+
+// AutoProfileNoMatch profile=auto 未命中任何规则时记录的规则名
+const AutoProfileNoMatch = "none"
+
+// ReplayProfileRule 重放任务覆盖编码器/预设、原任务没有编码覆盖时记录的规则名
+const ReplayProfileRule = "replay"
+
+// AutoProfile profile=auto 命中的规则及其编码决策，随任务持久化
+type AutoProfile struct {
+	Rule       string   `json:"rule"`
+	VideoCodec string   `json:"video_codec,omitempty"` // 覆盖 ffmpeg.video_codec
+	Preset     string   `json:"preset,omitempty"`      // 覆盖 ffmpeg.video_preset
+	Renditions []string `json:"renditions,omitempty"`  // HLS 阶梯中保留的分辨率，空表示使用完整阶梯
+}
+
+// ToJSON 序列化为 JSON
+func (p *AutoProfile) ToJSON() (string, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// AutoProfileFromJSON 从 JSON 反序列化，空串或解析失败返回 nil
+func AutoProfileFromJSON(data string) *AutoProfile {
+	if data == "" {
+		return nil
+	}
+	var p AutoProfile
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		return nil
+	}
+	return &p
+}
+
+// ResolutionHeight 分辨率名称对应的高度（720p/720 -> 720，4K -> 2160，2K -> 1440），无法识别时返回 0
+func ResolutionHeight(resolution string) int {
+	r := strings.ToLower(strings.TrimSpace(resolution))
+	switch r {
+	case "4k":
+		return 2160
+	case "2k":
+		return 1440
+	}
+	h, err := strconv.Atoi(strings.TrimSuffix(r, "p"))
+	if err != nil {
+		return 0
+	}
+	return h
+}
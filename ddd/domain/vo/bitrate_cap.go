@@ -0,0 +1,148 @@
+package vo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BitrateCapPolicy 按源码率封顶各档码率：档位码率不超过 源码率×系数，
+// 封顶后低于下限的 HLS 档位被丢弃，但至少保留分辨率最低的一档（按下限码率）
+type BitrateCapPolicy struct {
+	Factor    float64            // 默认系数
+	Factors   map[string]float64 // 按分辨率（如 1080p）覆盖系数
+	FloorKbps int
+}
+
+// factorFor 分辨率对应的系数
+func (p BitrateCapPolicy) factorFor(resolution string) float64 {
+	if f, ok := p.Factors[strings.ToLower(resolution)]; ok && f > 0 {
+		return f
+	}
+	return p.Factor
+}
+
+// CapKbps 分辨率的封顶码率，源码率未知或系数无效时返回 0（不封顶）
+func (p BitrateCapPolicy) CapKbps(resolution string, sourceKbps int) int {
+	f := p.factorFor(resolution)
+	if sourceKbps <= 0 || f <= 0 {
+		return 0
+	}
+	return int(float64(sourceKbps) * f)
+}
+
+// CapOutput 封顶单路输出（MP4）的码率；输出不可丢弃，封顶值低于下限时取下限
+func (p BitrateCapPolicy) CapOutput(resolution, bitrate string, sourceKbps int) BitrateAdjustedRendition {
+	r := BitrateAdjustedRendition{Resolution: resolution, Requested: bitrate}
+	limit := p.CapKbps(resolution, sourceKbps)
+	requested := BitrateKbps(bitrate)
+	if limit <= 0 || requested <= 0 || requested <= limit {
+		return r
+	}
+	r.Bitrate = FormatKbps(max(limit, p.FloorKbps))
+	return r
+}
+
+// Apply 封顶 HLS 阶梯，返回调整后的阶梯与逐档记录
+func (p BitrateCapPolicy) Apply(ladder []ResolutionConfig, sourceKbps int) ([]ResolutionConfig, []BitrateAdjustedRendition) {
+	out := make([]ResolutionConfig, 0, len(ladder))
+	records := make([]BitrateAdjustedRendition, 0, len(ladder))
+	lowest := -1
+	for i, rc := range ladder {
+		if lowest < 0 || ResolutionHeight(rc.Resolution) < ResolutionHeight(ladder[lowest].Resolution) {
+			lowest = i
+		}
+	}
+	for i, rc := range ladder {
+		rec := BitrateAdjustedRendition{Resolution: rc.Resolution, Requested: rc.Bitrate}
+		limit := p.CapKbps(rc.Resolution, sourceKbps)
+		requested := BitrateKbps(rc.Bitrate)
+		if limit > 0 && requested > limit {
+			switch {
+			case limit >= p.FloorKbps:
+				rec.Bitrate = FormatKbps(limit)
+			case i == lowest:
+				rec.Bitrate = FormatKbps(min(p.FloorKbps, requested))
+			default:
+				rec.Dropped = true
+			}
+		}
+		records = append(records, rec)
+		if rec.Dropped {
+			continue
+		}
+		if rec.Bitrate != "" {
+			rc.Bitrate = rec.Bitrate
+		}
+		out = append(out, rc)
+	}
+	return out, records
+}
+
+// BitrateAdjustedRendition 单档封顶记录；Bitrate 为空表示未调整
+type BitrateAdjustedRendition struct {
+	Resolution string `json:"resolution"`
+	Requested  string `json:"requested_bitrate"`
+	Bitrate    string `json:"bitrate,omitempty"`
+	Dropped    bool   `json:"dropped,omitempty"`
+}
+
+// Adjusted 是否被封顶或丢弃
+func (r BitrateAdjustedRendition) Adjusted() bool {
+	return r.Bitrate != "" || r.Dropped
+}
+
+// BitrateAdjustment 按源码率调整后的输出阶梯，记录在任务上
+type BitrateAdjustment struct {
+	SourceKbps int                        `json:"source_kbps"`
+	Output     *BitrateAdjustedRendition  `json:"output,omitempty"`
+	Renditions []BitrateAdjustedRendition `json:"renditions,omitempty"`
+}
+
+// ToJSON 序列化为 JSON
+func (a *BitrateAdjustment) ToJSON() (string, error) {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// BitrateAdjustmentFromJSON 反序列化，失败返回 nil
+func BitrateAdjustmentFromJSON(s string) *BitrateAdjustment {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var a BitrateAdjustment
+	if err := json.Unmarshal([]byte(s), &a); err != nil {
+		return nil
+	}
+	return &a
+}
+
+// BitrateKbps 将 2000k/2M/2000kbps/2mbps 解析为 kbps，无法解析返回 0
+func BitrateKbps(bitrate string) int {
+	s := strings.TrimSpace(strings.ToLower(bitrate))
+	factor := 0.001
+	switch {
+	case strings.HasSuffix(s, "kbps"):
+		factor, s = 1, strings.TrimSuffix(s, "kbps")
+	case strings.HasSuffix(s, "mbps"):
+		factor, s = 1000, strings.TrimSuffix(s, "mbps")
+	case strings.HasSuffix(s, "k"):
+		factor, s = 1, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "m"):
+		factor, s = 1000, strings.TrimSuffix(s, "m")
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v <= 0 {
+		return 0
+	}
+	return int(v * factor)
+}
+
+// FormatKbps 格式化为 ffmpeg 码率参数，如 800k
+func FormatKbps(kbps int) string {
+	return fmt.Sprintf("%dk", kbps)
+}
@@ -0,0 +1,116 @@
+package vo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Container 输出封装格式
+type Container string
+
+const (
+	ContainerMP4  Container = "mp4"
+	ContainerMKV  Container = "mkv"
+	ContainerWebM Container = "webm"
+	ContainerMOV  Container = "mov"
+)
+
+// ParseContainer 解析封装格式，空值默认 mp4
+func ParseContainer(s string) (Container, error) {
+	switch c := Container(strings.ToLower(strings.TrimSpace(s))); c {
+	case "":
+		return ContainerMP4, nil
+	case ContainerMP4, ContainerMKV, ContainerWebM, ContainerMOV:
+		return c, nil
+	default:
+		return "", fmt.Errorf("不支持的封装格式: %s", s)
+	}
+}
+
+// String 返回封装格式名称
+func (c Container) String() string {
+	return string(c)
+}
+
+// Extension 文件扩展名（含点）
+func (c Container) Extension() string {
+	return "." + string(c)
+}
+
+// ContentType 上传对象存储使用的 MIME 类型
+func (c Container) ContentType() string {
+	return ContentTypeForExtension(c.Extension())
+}
+
+// Muxer ffmpeg -f 使用的封装器名称
+func (c Container) Muxer() string {
+	switch c {
+	case ContainerMKV:
+		return "matroska"
+	default:
+		return string(c)
+	}
+}
+
+// MuxFlags 封装器参数：mp4/mov 将 moov 前置以便边下边播
+func (c Container) MuxFlags() []string {
+	switch c {
+	case ContainerMP4, ContainerMOV:
+		return []string{"-movflags", "+faststart"}
+	default:
+		return nil
+	}
+}
+
+// AudioCodec 该封装使用的音频编码器
+func (c Container) AudioCodec() string {
+	if c == ContainerWebM {
+		return "libopus"
+	}
+	return "aac"
+}
+
+// SupportsVideoCodec webm 仅能封装 VP8/VP9/AV1，其余封装不限制
+func (c Container) SupportsVideoCodec(codec string) bool {
+	if c != ContainerWebM {
+		return true
+	}
+	codec = strings.ToLower(codec)
+	return strings.Contains(codec, "vp8") || strings.Contains(codec, "vp9") || strings.Contains(codec, "libvpx") || strings.Contains(codec, "av1")
+}
+
+// DefaultVideoCodec 配置的编码器不兼容时使用的软件编码器
+func (c Container) DefaultVideoCodec() string {
+	if c == ContainerWebM {
+		return "libvpx-vp9"
+	}
+	return "libx264"
+}
+
+// ContentTypeForExtension 根据扩展名返回音视频相关 MIME 类型
+func ContentTypeForExtension(ext string) string {
+	switch strings.ToLower(ext) {
+	case ".mp4":
+		return "video/mp4"
+	case ".mkv":
+		return "video/x-matroska"
+	case ".webm":
+		return "video/webm"
+	case ".mov":
+		return "video/quicktime"
+	case ".m3u8":
+		return "application/vnd.apple.mpegurl"
+	case ".ts":
+		return "video/mp2t"
+	case ".m4a":
+		return "audio/mp4"
+	case ".mp3":
+		return "audio/mpeg"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".webp":
+		return "image/webp"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,146 @@
+package vo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// fingerprintFlags 参与指纹的编码参数（输入输出路径、线程数等不影响产物画质的参数不参与）
+var fingerprintFlags = []string{
+	"-c:v", "-preset", "-tune", "-profile:v", "-level", "-pix_fmt",
+	"-crf", "-cq", "-qp", "-rc", "-b:v", "-maxrate", "-bufsize",
+	"-g", "-keyint_min", "-sc_threshold", "-bf", "-x264-params", "-x265-params",
+	"-flags", "-forced-idr", "-no-scenecut",
+	"-c:a", "-b:a", "-ar", "-ac",
+}
+
+// filterFlags 滤镜链参数，只记录哈希
+var filterFlags = []string{"-vf", "-filter:v", "-af", "-filter:a", "-filter_complex"}
+
+// EncoderFingerprint 产物的编码设置指纹；SettingsVersion 为运维手动递增的设置版本，
+// Hash 由 ffmpeg 版本与各条命令的编码参数计算，设置相同的产物哈希相同
+type EncoderFingerprint struct {
+	SettingsVersion int    `json:"settings_version"`
+	FFmpegVersion   string `json:"ffmpeg_version"`
+	Codec           string `json:"codec"`
+	Preset          string `json:"preset,omitempty"`
+	// RateControl 按命令 label 记录码控参数，如 {"mp4":"crf=23"}、{"hls_720p":"b:v=2800k"}
+	RateControl map[string]string `json:"rate_control,omitempty"`
+	FilterHash  string            `json:"filter_hash,omitempty"`
+	Hash        string            `json:"hash"`
+}
+
+// NewEncoderFingerprint 由实际执行的 ffmpeg 命令计算指纹，没有命令时返回 nil
+func NewEncoderFingerprint(settingsVersion int, ffmpegVersion string, cmds FFmpegCommands) *EncoderFingerprint {
+	if len(cmds) == 0 {
+		return nil
+	}
+	sorted := append(FFmpegCommands(nil), cmds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Label < sorted[j].Label })
+
+	fp := &EncoderFingerprint{SettingsVersion: settingsVersion, FFmpegVersion: ffmpegVersion, RateControl: map[string]string{}}
+	settings := sha256.New()
+	filters := sha256.New()
+	settings.Write([]byte("ffmpeg=" + ffmpegVersion + "\n"))
+	hasFilter := false
+	for _, cmd := range sorted {
+		values := commandFlagValues(cmd.Args)
+		if fp.Codec == "" {
+			fp.Codec, fp.Preset = values["-c:v"], values["-preset"]
+		}
+		if rc := rateControl(values); rc != "" {
+			fp.RateControl[cmd.Label] = rc
+		}
+		settings.Write([]byte(cmd.Label))
+		for _, f := range fingerprintFlags {
+			if v, ok := values[f]; ok {
+				settings.Write([]byte(" " + f + "=" + v))
+			}
+		}
+		for _, f := range filterFlags {
+			if v, ok := values[f]; ok {
+				hasFilter = true
+				filters.Write([]byte(cmd.Label + " " + f + "=" + v + "\n"))
+				settings.Write([]byte(" " + f + "=" + v))
+			}
+		}
+		settings.Write([]byte("\n"))
+	}
+	if hasFilter {
+		fp.FilterHash = shortHash(filters.Sum(nil))
+	}
+	if len(fp.RateControl) == 0 {
+		fp.RateControl = nil
+	}
+	fp.Hash = shortHash(settings.Sum(nil))
+	return fp
+}
+
+// commandFlagValues 提取参数中关注的选项值，同一选项出现多次时取最后一次（与 ffmpeg 一致）
+func commandFlagValues(args []string) map[string]string {
+	watched := make(map[string]struct{}, len(fingerprintFlags)+len(filterFlags))
+	for _, f := range fingerprintFlags {
+		watched[f] = struct{}{}
+	}
+	for _, f := range filterFlags {
+		watched[f] = struct{}{}
+	}
+	values := make(map[string]string)
+	for i := 0; i+1 < len(args); i++ {
+		flag := args[i]
+		if flag == "-vcodec" {
+			flag = "-c:v"
+		}
+		if _, ok := watched[flag]; ok {
+			values[flag] = args[i+1]
+			i++
+		}
+	}
+	return values
+}
+
+// rateControl 码控参数的紧凑描述
+func rateControl(values map[string]string) string {
+	parts := make([]string, 0, 4)
+	for _, f := range []string{"-crf", "-cq", "-qp", "-rc", "-b:v", "-maxrate", "-bufsize"} {
+		if v, ok := values[f]; ok {
+			parts = append(parts, strings.TrimPrefix(f, "-")+"="+v)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func shortHash(sum []byte) string {
+	return hex.EncodeToString(sum)[:16]
+}
+
+// ToJSON 序列化为 JSON
+func (f *EncoderFingerprint) ToJSON() (string, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// EncoderFingerprintFromJSON 反序列化，失败返回 nil
+func EncoderFingerprintFromJSON(s string) *EncoderFingerprint {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var f EncoderFingerprint
+	if err := json.Unmarshal([]byte(s), &f); err != nil {
+		return nil
+	}
+	return &f
+}
+
+// StaleOutputFilter 选择需要重新编码的产物：设置版本低于 BeforeVersion，或指纹哈希不等于 ExcludeHash；
+// 两个条件都设置时满足其一即可，未记录指纹的历史产物视为版本 0
+type StaleOutputFilter struct {
+	BeforeVersion int
+	ExcludeHash   string
+}
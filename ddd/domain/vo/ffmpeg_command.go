@@ -0,0 +1,54 @@
+package vo
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// FFmpegCommand 一次实际执行的 ffmpeg 命令，便于在本地复现编码
+type FFmpegCommand struct {
+	Label      string    `json:"label"` // 如 mp4、hls_720p
+	Binary     string    `json:"binary"`
+	Args       []string  `json:"args"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// CommandLine 拼接为可读命令行（仅用于展示，参数未做 shell 转义）
+func (c FFmpegCommand) CommandLine() string {
+	return c.Binary + " " + strings.Join(c.Args, " ")
+}
+
+// FFmpegCommands 任务执行过的全部 ffmpeg 命令
+type FFmpegCommands []FFmpegCommand
+
+// Upsert 按 label 覆盖旧记录（重试时只保留最近一次），否则追加
+func (cs FFmpegCommands) Upsert(cmd FFmpegCommand) FFmpegCommands {
+	for i := range cs {
+		if cs[i].Label == cmd.Label {
+			out := append(FFmpegCommands(nil), cs...)
+			out[i] = cmd
+			return out
+		}
+	}
+	return append(append(FFmpegCommands(nil), cs...), cmd)
+}
+
+// ToJSON 序列化为 JSON
+func (cs FFmpegCommands) ToJSON() (string, error) {
+	data, err := json.Marshal(cs)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// FFmpegCommandsFromJSON 从 JSON 反序列化，解析失败返回空列表
+func FFmpegCommandsFromJSON(data string) FFmpegCommands {
+	var cs FFmpegCommands
+	if data == "" {
+		return cs
+	}
+	_ = json.Unmarshal([]byte(data), &cs)
+	return cs
+}
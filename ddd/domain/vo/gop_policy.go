@@ -0,0 +1,141 @@
+package vo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultIDRIntervalSeconds forced_idr 未指定间隔时 MP4 产物的关键帧间隔；HLS 切片按切片时长对齐
+const DefaultIDRIntervalSeconds = 2
+
+// GOPPolicy 档位的 GOP 结构，供要求闭合 GOP、IDR 对齐切片的下游打包器使用；零值表示按编码器默认
+type GOPPolicy struct {
+	ClosedGOP bool `json:"closed_gop,omitempty"`
+	// ForcedIDR 按 IDRIntervalSeconds 强制关键帧并输出为 IDR，隐含闭合 GOP
+	ForcedIDR          bool `json:"forced_idr,omitempty"`
+	IDRIntervalSeconds int  `json:"idr_interval_seconds,omitempty"`
+	// Scenecut 场景切换检测阈值 0-100，0 关闭；nil 表示编码器默认
+	Scenecut *int `json:"scenecut,omitempty"`
+}
+
+// NewGOPPolicy 校验互斥选项：forced_idr 不能与 closed_gop=false 或开启的 scenecut 同时使用
+// （场景切换插入的额外关键帧会打乱 IDR 对齐），idr_interval 只在 forced_idr 时有效
+func NewGOPPolicy(closedGOP *bool, forcedIDR bool, scenecut *int, idrIntervalSeconds int) (*GOPPolicy, error) {
+	if scenecut != nil && (*scenecut < 0 || *scenecut > 100) {
+		return nil, fmt.Errorf("scenecut must be within 0-100")
+	}
+	if idrIntervalSeconds < 0 {
+		return nil, fmt.Errorf("idr_interval must be positive")
+	}
+	if forcedIDR {
+		if closedGOP != nil && !*closedGOP {
+			return nil, fmt.Errorf("forced_idr requires closed GOPs, closed_gop=false is not allowed")
+		}
+		if scenecut != nil && *scenecut > 0 {
+			return nil, fmt.Errorf("forced_idr cannot be combined with scenecut > 0")
+		}
+	} else if idrIntervalSeconds > 0 {
+		return nil, fmt.Errorf("idr_interval is only valid with forced_idr")
+	}
+	p := &GOPPolicy{ForcedIDR: forcedIDR, Scenecut: scenecut}
+	p.ClosedGOP = forcedIDR || (closedGOP != nil && *closedGOP)
+	if forcedIDR {
+		p.IDRIntervalSeconds = idrIntervalSeconds
+		if p.IDRIntervalSeconds == 0 {
+			p.IDRIntervalSeconds = DefaultIDRIntervalSeconds
+		}
+	}
+	if p.IsZero() {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// IsZero 是否未设置任何选项
+func (p *GOPPolicy) IsZero() bool {
+	return p == nil || (!p.ClosedGOP && !p.ForcedIDR && p.Scenecut == nil)
+}
+
+// SupportsCodec 编码器是否支持这些选项；libvpx/libaom 等不支持，由调用方忽略并告警
+func (p *GOPPolicy) SupportsCodec(codec string) bool {
+	c := strings.ToLower(codec)
+	return strings.Contains(c, "264") || strings.Contains(c, "265") || strings.Contains(c, "hevc") || strings.Contains(c, "nvenc")
+}
+
+// EncoderArgs 映射为编码器参数，不含关键帧位置（MP4 用 ForceKeyFramesArgs，HLS 已按切片时长强制关键帧）：
+// libx264 -flags +cgop / -forced-idr 1 / -sc_threshold N；
+// libx265 -x265-params open-gop=0:scenecut=N / -forced-idr 1；
+// nvenc -flags +cgop / -forced-idr 1 / -no-scenecut 0|1（nvenc 只能开关场景切换检测）
+func (p *GOPPolicy) EncoderArgs(codec string) []string {
+	if p.IsZero() || !p.SupportsCodec(codec) {
+		return nil
+	}
+	c := strings.ToLower(codec)
+	var args []string
+	switch {
+	case strings.Contains(c, "nvenc"):
+		if p.ClosedGOP {
+			args = append(args, "-flags", "+cgop")
+		}
+		if p.Scenecut != nil {
+			noScenecut := "0"
+			if *p.Scenecut == 0 {
+				noScenecut = "1"
+			}
+			args = append(args, "-no-scenecut", noScenecut)
+		}
+	case strings.Contains(c, "265") || strings.Contains(c, "hevc"):
+		var params []string
+		if p.ClosedGOP {
+			params = append(params, "open-gop=0")
+		}
+		if p.Scenecut != nil {
+			params = append(params, "scenecut="+strconv.Itoa(*p.Scenecut))
+		}
+		if len(params) > 0 {
+			args = append(args, "-x265-params", strings.Join(params, ":"))
+		}
+	default:
+		if p.ClosedGOP {
+			args = append(args, "-flags", "+cgop")
+		}
+		if p.Scenecut != nil {
+			args = append(args, "-sc_threshold", strconv.Itoa(*p.Scenecut))
+		}
+	}
+	if p.ForcedIDR {
+		args = append(args, "-forced-idr", "1")
+	}
+	return args
+}
+
+// ForceKeyFramesArgs forced_idr 时按间隔强制关键帧
+func (p *GOPPolicy) ForceKeyFramesArgs() []string {
+	if p == nil || !p.ForcedIDR {
+		return nil
+	}
+	return []string{"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", p.IDRIntervalSeconds)}
+}
+
+// ToJSON 序列化为 JSON
+func (p *GOPPolicy) ToJSON() (string, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// GOPPolicyFromJSON 反序列化，空串或失败返回 nil
+func GOPPolicyFromJSON(s string) *GOPPolicy {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var p GOPPolicy
+	if err := json.Unmarshal([]byte(s), &p); err != nil || p.IsZero() {
+		return nil
+	}
+	return &p
+}
@@ -13,6 +13,7 @@ type HLSStatus string
 const (
 	HLSStatusDisabled   HLSStatus = "disabled"   // 未启用
 	HLSStatusPending    HLSStatus = "pending"    // 待处理
+	HLSStatusClaimed    HLSStatus = "claimed"    // 已被某个 worker 认领
 	HLSStatusProcessing HLSStatus = "processing" // 处理中
 	HLSStatusCompleted  HLSStatus = "completed"  // 已完成
 	HLSStatusFailed     HLSStatus = "failed"     // 失败
@@ -26,7 +27,7 @@ func (s HLSStatus) String() string {
 // IsValid 检查状态是否有效
 func (s HLSStatus) IsValid() bool {
 	switch s {
-	case HLSStatusDisabled, HLSStatusPending, HLSStatusProcessing, HLSStatusCompleted, HLSStatusFailed:
+	case HLSStatusDisabled, HLSStatusPending, HLSStatusClaimed, HLSStatusProcessing, HLSStatusCompleted, HLSStatusFailed:
 		return true
 	default:
 		return false
@@ -35,8 +36,9 @@ func (s HLSStatus) IsValid() bool {
 
 // ResolutionConfig 分辨率配置
 type ResolutionConfig struct {
-	Resolution string `json:"resolution"` // 分辨率，如 "720p", "480p", "360p"
-	Bitrate    string `json:"bitrate"`    // 码率，如 "2000k", "1000k", "500k"
+	Resolution string     `json:"resolution"`    // 分辨率，如 "720p", "480p", "360p"
+	Bitrate    string     `json:"bitrate"`       // 码率，如 "2000k", "1000k", "500k"
+	GOP        *GOPPolicy `json:"gop,omitempty"` // 档位配置的 GOP 结构
 }
 
 // NewResolutionConfig 创建分辨率配置
@@ -66,15 +68,16 @@ func (rc *ResolutionConfig) Validate() error {
 
 // HLSConfig HLS配置值对象
 type HLSConfig struct {
-	EnableHLS       bool               `json:"enable_hls"`       // 是否启用HLS切片
-	Resolutions     []ResolutionConfig `json:"resolutions"`      // 多分辨率配置
-	SegmentDuration int                `json:"segment_duration"` // 切片时长(秒)
-	ListSize        int                `json:"list_size"`        // 播放列表大小(0表示无限制)
-	Format          string             `json:"format"`           // HLS格式(mpegts/fmp4)
-	Status          HLSStatus          `json:"status"`           // HLS状态
-	Progress        int                `json:"progress"`         // 进度(0-100)
-	OutputPath      string             `json:"output_path"`      // 输出路径
-	ErrorMessage    string             `json:"error_message"`    // 错误信息
+	EnableHLS       bool               `json:"enable_hls"`         // 是否启用HLS切片
+	Resolutions     []ResolutionConfig `json:"resolutions"`        // 多分辨率配置
+	SegmentDuration int                `json:"segment_duration"`   // 切片时长(秒)
+	ListSize        int                `json:"list_size"`          // 播放列表大小(0表示无限制)
+	Format          string             `json:"format"`             // HLS格式(mpegts/fmp4)
+	Status          HLSStatus          `json:"status"`             // HLS状态
+	Progress        int                `json:"progress"`           // 进度(0-100)
+	OutputPath      string             `json:"output_path"`        // 输出路径
+	ErrorMessage    string             `json:"error_message"`      // 错误信息
+	NoAudio         bool               `json:"no_audio,omitempty"` // 输入没有音频，切片不输出音轨
 }
 
 // NewHLSConfig 创建HLS配置
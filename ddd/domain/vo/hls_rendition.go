@@ -0,0 +1,109 @@
+package vo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RenditionStatus HLS 单路码流状态
+type RenditionStatus string
+
+const (
+	RenditionPending   RenditionStatus = "pending"
+	RenditionCompleted RenditionStatus = "completed"
+	RenditionFailed    RenditionStatus = "failed"
+)
+
+// HLSRendition HLS 作业中单路码流的状态、错误与产物统计，随作业持久化
+type HLSRendition struct {
+	Resolution string          `json:"resolution"`
+	Bitrate    string          `json:"bitrate"`
+	Status     RenditionStatus `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	Attempts   int             `json:"attempts"`
+	DurationMs int64           `json:"duration_ms,omitempty"` // 最近一次切片耗时
+	Segments   int             `json:"segments,omitempty"`
+	Bytes      int64           `json:"bytes,omitempty"`    // 播放列表与切片总大小
+	Playlist   string          `json:"playlist,omitempty"` // 切片时按命名模板生成的播放列表文件名，master 按此引用
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// HLSRenditions 按阶梯顺序排列的码流状态
+type HLSRenditions []HLSRendition
+
+// NewHLSRenditions 按 HLS 配置的阶梯初始化为 pending
+func NewHLSRenditions(resolutions []ResolutionConfig) HLSRenditions {
+	out := make(HLSRenditions, 0, len(resolutions))
+	for _, r := range resolutions {
+		out = append(out, HLSRendition{Resolution: r.Resolution, Bitrate: r.Bitrate, Status: RenditionPending})
+	}
+	return out
+}
+
+// Find 按分辨率查找，不存在时返回 nil
+func (rs HLSRenditions) Find(resolution string) *HLSRendition {
+	for i := range rs {
+		if rs[i].Resolution == resolution {
+			return &rs[i]
+		}
+	}
+	return nil
+}
+
+// Failed 失败的码流
+func (rs HLSRenditions) Failed() []HLSRendition {
+	var out []HLSRendition
+	for _, r := range rs {
+		if r.Status == RenditionFailed {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// CountByStatus 指定状态的码流数量
+func (rs HLSRenditions) CountByStatus(status RenditionStatus) int {
+	n := 0
+	for _, r := range rs {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// FailureSummary 失败码流及原因，如 "1080p: ffmpeg exit status 1; 4K: ..."，无失败时为空
+func (rs HLSRenditions) FailureSummary(maxErrLen int) string {
+	parts := make([]string, 0, len(rs))
+	for _, r := range rs.Failed() {
+		msg := r.Error
+		if maxErrLen > 0 && len([]rune(msg)) > maxErrLen {
+			msg = string([]rune(msg)[:maxErrLen]) + "..."
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", r.Resolution, msg))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ToJSON 序列化为 JSON
+func (rs HLSRenditions) ToJSON() (string, error) {
+	data, err := json.Marshal(rs)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// HLSRenditionsFromJSON 从 JSON 反序列化，空串或解析失败返回 nil
+func HLSRenditionsFromJSON(data string) HLSRenditions {
+	if data == "" {
+		return nil
+	}
+	var rs HLSRenditions
+	if err := json.Unmarshal([]byte(data), &rs); err != nil {
+		return nil
+	}
+	return rs
+}
@@ -0,0 +1,88 @@
+package vo
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// BucketKeyPrefix 指定目标桶的对象 key 前缀：/buckets/<bucket>/<key>，存储侧据此路由到该桶，
+// 桶内实际的对象 key 为去掉 buckets/<bucket>/ 之后的部分
+const BucketKeyPrefix = "buckets/"
+
+var (
+	bucketNamePattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+	prefixSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+)
+
+// maxOutputPrefixLength 产物前缀的最大长度
+const maxOutputPrefixLength = 256
+
+// OutputDestination 请求指定的产物目标桶与前缀，零值表示默认桶
+type OutputDestination struct {
+	Bucket string `json:"bucket,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// NewOutputDestination 校验桶名（S3 命名规则）与前缀（/ 分隔，不含 . 或 .. 段），前缀两端的 / 会被去掉
+func NewOutputDestination(bucket, prefix string) (OutputDestination, error) {
+	bucket = strings.TrimSpace(bucket)
+	prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+	if bucket == "" {
+		if prefix != "" {
+			return OutputDestination{}, fmt.Errorf("output_prefix requires output_bucket")
+		}
+		return OutputDestination{}, nil
+	}
+	if !bucketNamePattern.MatchString(bucket) {
+		return OutputDestination{}, fmt.Errorf("invalid output_bucket %q", bucket)
+	}
+	if len(prefix) > maxOutputPrefixLength {
+		return OutputDestination{}, fmt.Errorf("output_prefix exceeds %d characters", maxOutputPrefixLength)
+	}
+	if prefix != "" {
+		for _, seg := range strings.Split(prefix, "/") {
+			if seg == "." || seg == ".." || !prefixSegmentPattern.MatchString(seg) {
+				return OutputDestination{}, fmt.Errorf("invalid output_prefix %q", prefix)
+			}
+		}
+	}
+	return OutputDestination{Bucket: bucket, Prefix: prefix}, nil
+}
+
+// IsZero 是否使用默认桶
+func (d OutputDestination) IsZero() bool {
+	return d.Bucket == ""
+}
+
+// DestinationObjectKey 将默认桶的产物 key 改写到目标桶：
+// /transcoded/<user>/<video>_720p_2000k.mp4 -> /buckets/<bucket>/<prefix>/<user>/<video>_720p_2000k.mp4，
+// hls/<user>/<video>/<job> -> buckets/<bucket>/<prefix>/hls/<user>/<video>/<job>；零值或已定位的 key 原样返回
+func DestinationObjectKey(key string, d OutputDestination) string {
+	if d.IsZero() {
+		return key
+	}
+	if _, _, ok := SplitBucketKey(key); ok {
+		return key
+	}
+	lead := ""
+	if strings.HasPrefix(key, "/") {
+		lead = "/"
+	}
+	rest := strings.TrimPrefix(strings.TrimLeft(key, "/"), "transcoded/")
+	return lead + path.Join(BucketKeyPrefix, d.Bucket, d.Prefix, rest)
+}
+
+// SplitBucketKey 拆分 /buckets/<bucket>/<key> 形式的 key，不是该形式时返回 false
+func SplitBucketKey(key string) (string, string, bool) {
+	k := strings.TrimLeft(key, "/")
+	if !strings.HasPrefix(k, BucketKeyPrefix) {
+		return "", "", false
+	}
+	bucket, objectKey, ok := strings.Cut(strings.TrimPrefix(k, BucketKeyPrefix), "/")
+	if !ok || bucket == "" || objectKey == "" {
+		return "", "", false
+	}
+	return bucket, objectKey, true
+}
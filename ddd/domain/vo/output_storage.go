@@ -0,0 +1,77 @@
+package vo
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// StorageTier 产物所在的存储层级
+type StorageTier string
+
+const (
+	StorageTierStandard  StorageTier = "standard"
+	StorageTierArchiving StorageTier = "archiving" // 正在复制到归档前缀
+	StorageTierArchived  StorageTier = "archived"
+	StorageTierRestoring StorageTier = "restoring" // 已请求恢复，等待复制回原位置
+)
+
+// NewStorageTier 空值视为 standard（历史任务）
+func NewStorageTier(s string) StorageTier {
+	if s == "" {
+		return StorageTierStandard
+	}
+	return StorageTier(s)
+}
+
+// OutputStorage 产物的存储层级与归档位置
+type OutputStorage struct {
+	Tier               StorageTier `json:"tier"`
+	ArchivePath        string      `json:"archive_path,omitempty"`
+	StorageClass       string      `json:"storage_class,omitempty"`
+	ArchivedAt         *time.Time  `json:"archived_at,omitempty"`
+	RestoreRequestedAt *time.Time  `json:"restore_requested_at,omitempty"`
+	RestoredAt         *time.Time  `json:"restored_at,omitempty"`
+	// LastAccessedAt 由 CDN 访问日志回写，未回写时省略
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+}
+
+// IsZero 从未归档且无访问记录
+func (s OutputStorage) IsZero() bool {
+	return (s.Tier == "" || s.Tier == StorageTierStandard) && s.ArchivePath == "" && s.ArchivedAt == nil &&
+		s.RestoredAt == nil && s.LastAccessedAt == nil
+}
+
+// ToJSON 序列化归档位置信息；层级与访问时间单独成列，不写入 JSON
+func (s OutputStorage) ToJSON() (string, error) {
+	s.Tier, s.LastAccessedAt = "", nil
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// OutputStorageFromJSON 反序列化，失败返回零值
+func OutputStorageFromJSON(s string) OutputStorage {
+	var out OutputStorage
+	if strings.TrimSpace(s) == "" {
+		return out
+	}
+	_ = json.Unmarshal([]byte(s), &out)
+	return out
+}
+
+// ArchiveKey 归档对象 key：<首级目录>/<dir>/<其余路径>，与原对象同桶，桶生命周期规则可按前缀转换存储类别；
+// 指定目标桶的 key 在桶内的对象 key 上插入
+func ArchiveKey(objectKey, dir string) string {
+	if bucket, inner, ok := SplitBucketKey(objectKey); ok {
+		return BucketKeyPrefix + bucket + "/" + ArchiveKey(inner, dir)
+	}
+	k := strings.TrimLeft(objectKey, "/")
+	root, rest, ok := strings.Cut(k, "/")
+	if !ok {
+		return dir + "/" + k
+	}
+	return root + "/" + dir + "/" + rest
+}
@@ -0,0 +1,34 @@
+package vo
+
+import "time"
+
+// ClaimState 快照中任务所处的位置
+type ClaimState string
+
+const (
+	ClaimRunning ClaimState = "running" // 正在由某个 worker 协程执行
+	ClaimQueued  ClaimState = "queued"  // 已在内存队列中等待执行
+)
+
+// TaskClaim 快照中的单个任务认领
+type TaskClaim struct {
+	TaskUUID  string     `json:"task_uuid"`
+	VideoUUID string     `json:"video_uuid"`
+	State     ClaimState `json:"state"`
+	Slot      int        `json:"slot"` // 执行该任务的 worker 协程序号，queued 时为 -1
+	Progress  int        `json:"progress"`
+	StartedAt time.Time  `json:"started_at,omitempty"`
+}
+
+// PipelineSnapshot 单个实例运行中流水线状态的快照，用于重启后精确恢复或重新排队
+type PipelineSnapshot struct {
+	InstanceID string      `json:"instance_id"`
+	TakenAt    time.Time   `json:"taken_at"`
+	Released   bool        `json:"released"` // 实例正常停止时写入的最终快照
+	Claims     []TaskClaim `json:"claims"`
+}
+
+// Orphaned 实例已释放，或超过 staleAfter 未刷新快照（视为已宕机）
+func (s *PipelineSnapshot) Orphaned(now time.Time, staleAfter time.Duration) bool {
+	return s.Released || (staleAfter > 0 && now.Sub(s.TakenAt) > staleAfter)
+}
@@ -0,0 +1,101 @@
+package vo
+
+import "encoding/json"
+
+// PipelineStage 视频处理流水线阶段
+type PipelineStage string
+
+const (
+	StageDownload PipelineStage = "download" // 下载源文件
+	StageEncode   PipelineStage = "encode"   // ffmpeg 编码
+	StageUpload   PipelineStage = "upload"   // 上传转码产物
+	StageHLS      PipelineStage = "hls"      // HLS 切片打包
+)
+
+// PipelineStages 按执行顺序排列的全部阶段
+var PipelineStages = []PipelineStage{StageDownload, StageEncode, StageUpload, StageHLS}
+
+// TranscodeStages 转码任务本身覆盖的阶段（HLS 由独立作业完成）
+var TranscodeStages = []PipelineStage{StageDownload, StageEncode, StageUpload}
+
+// stageWeights 各阶段在整体进度中的权重（百分比，合计 100）
+var stageWeights = map[PipelineStage]int{
+	StageDownload: 10,
+	StageEncode:   60,
+	StageUpload:   10,
+	StageHLS:      20,
+}
+
+// String 返回阶段名称
+func (s PipelineStage) String() string {
+	return string(s)
+}
+
+// Weight 返回阶段权重
+func (s PipelineStage) Weight() int {
+	return stageWeights[s]
+}
+
+// StageProgress 各阶段进度（0-100）
+type StageProgress map[PipelineStage]int
+
+// Set 设置阶段进度，超出范围时截断
+func (sp StageProgress) Set(stage PipelineStage, progress int) {
+	if progress < 0 {
+		progress = 0
+	}
+	if progress > 100 {
+		progress = 100
+	}
+	sp[stage] = progress
+}
+
+// Get 获取阶段进度，未记录时返回 0
+func (sp StageProgress) Get(stage PipelineStage) int {
+	return sp[stage]
+}
+
+// Clone 复制一份阶段进度
+func (sp StageProgress) Clone() StageProgress {
+	out := make(StageProgress, len(sp))
+	for k, v := range sp {
+		out[k] = v
+	}
+	return out
+}
+
+// Composite 按权重计算指定阶段的综合进度（0-100），未指定阶段时计算全部阶段
+func (sp StageProgress) Composite(stages ...PipelineStage) int {
+	if len(stages) == 0 {
+		stages = PipelineStages
+	}
+	total, done := 0, 0
+	for _, stage := range stages {
+		w := stage.Weight()
+		total += w
+		done += w * sp.Get(stage)
+	}
+	if total == 0 {
+		return 0
+	}
+	return done / total
+}
+
+// ToJSON 序列化为 JSON
+func (sp StageProgress) ToJSON() (string, error) {
+	data, err := json.Marshal(sp)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// StageProgressFromJSON 从 JSON 反序列化，解析失败返回空进度
+func StageProgressFromJSON(data string) StageProgress {
+	sp := StageProgress{}
+	if data == "" {
+		return sp
+	}
+	_ = json.Unmarshal([]byte(data), &sp)
+	return sp
+}
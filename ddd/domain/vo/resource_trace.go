@@ -0,0 +1,81 @@
+package vo
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// ResourceSample ffmpeg 进程的一次资源采样
+type ResourceSample struct {
+	ElapsedMs  int64   `json:"elapsed_ms"`  // 相对进程启动
+	CPUPercent float64 `json:"cpu_percent"` // 多核时可超过 100
+	RSSBytes   int64   `json:"rss_bytes"`
+	// GPUUtil 整卡利用率（%），只在 NVENC 编码时采集
+	GPUUtil    *float64 `json:"gpu_util,omitempty"`
+	FPS        float64  `json:"fps"`
+	Speed      float64  `json:"speed"` // ffmpeg -progress 的 speed=Nx
+	OutTimeSec float64  `json:"out_time_sec"`
+}
+
+// ResourceTrace 一次 ffmpeg 编码的生命周期与资源时间序列，用于排查单个任务为何变慢
+type ResourceTrace struct {
+	Label      string    `json:"label"` // 与 ffmpeg 命令的 label 一致，如 mp4
+	RequestID  string    `json:"request_id,omitempty"`
+	Encoder    string    `json:"encoder"` // nvenc 或 software
+	StartedAt  time.Time `json:"started_at"`
+	IntervalMs int64     `json:"interval_ms"`
+	// FirstFrameMs 进程启动到首帧（解码/编码器初始化），未出帧时省略
+	FirstFrameMs int64      `json:"first_frame_ms,omitempty"`
+	ExitedAt     *time.Time `json:"exited_at,omitempty"`
+	ExitCode     *int       `json:"exit_code,omitempty"`
+	UserCPUMs    int64      `json:"user_cpu_ms,omitempty"`
+	SysCPUMs     int64      `json:"sys_cpu_ms,omitempty"`
+	MaxRSSBytes  int64      `json:"max_rss_bytes,omitempty"`
+	// Downsampled 样本数超过上限后相邻样本被合并、间隔加倍的次数
+	Downsampled int              `json:"downsampled,omitempty"`
+	Samples     []ResourceSample `json:"samples"`
+}
+
+// Append 追加样本；超过 maxSamples 时隔一个保留一个，采样间隔随之加倍，长任务的序列仍覆盖全程
+func (t *ResourceTrace) Append(s ResourceSample, maxSamples int) {
+	t.Samples = append(t.Samples, s)
+	if maxSamples <= 1 || len(t.Samples) <= maxSamples {
+		return
+	}
+	kept := t.Samples[:0]
+	for i := 0; i < len(t.Samples); i += 2 {
+		kept = append(kept, t.Samples[i])
+	}
+	t.Samples = kept
+	t.IntervalMs *= 2
+	t.Downsampled++
+}
+
+// Clone 深拷贝，供采样协程之外持久化
+func (t *ResourceTrace) Clone() *ResourceTrace {
+	c := *t
+	c.Samples = append([]ResourceSample(nil), t.Samples...)
+	return &c
+}
+
+// ToJSON 序列化为 JSON
+func (t *ResourceTrace) ToJSON() (string, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ResourceTraceFromJSON 反序列化，失败返回 nil
+func ResourceTraceFromJSON(data string) *ResourceTrace {
+	if strings.TrimSpace(data) == "" {
+		return nil
+	}
+	var t ResourceTrace
+	if err := json.Unmarshal([]byte(data), &t); err != nil {
+		return nil
+	}
+	return &t
+}
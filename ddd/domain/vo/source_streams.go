@@ -0,0 +1,79 @@
+package vo
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// SourceContent 源文件包含的流类型
+type SourceContent string
+
+const (
+	SourceAudioVideo SourceContent = "audio_video"
+	SourceVideoOnly  SourceContent = "video_only"
+	SourceAudioOnly  SourceContent = "audio_only"
+	SourceNoStreams  SourceContent = "none"
+)
+
+// NewSourceContent 由是否存在视频流（不含封面）与音频流得到源文件内容
+func NewSourceContent(hasVideo, hasAudio bool) SourceContent {
+	switch {
+	case hasVideo && hasAudio:
+		return SourceAudioVideo
+	case hasVideo:
+		return SourceVideoOnly
+	case hasAudio:
+		return SourceAudioOnly
+	}
+	return SourceNoStreams
+}
+
+// 缺失流的处理方式
+const (
+	StreamHandlingSilentAudio = "silent_audio" // 无音频源补静音音轨
+	StreamHandlingNoAudio     = "no_audio"     // 无音频源输出无音轨视频
+	StreamHandlingAudioOnly   = "audio_only"   // 纯音频源只输出音频
+)
+
+// SourceStreams 执行阶段探测到的源文件内容及缺失流的处理方式，记录在任务上
+type SourceStreams struct {
+	Content SourceContent `json:"content"`
+	// Handling 音视频俱全时为空
+	Handling string `json:"handling,omitempty"`
+}
+
+// SilentAudio 是否需要补静音音轨
+func (s *SourceStreams) SilentAudio() bool {
+	return s != nil && s.Handling == StreamHandlingSilentAudio
+}
+
+// NoAudio 是否输出无音轨视频
+func (s *SourceStreams) NoAudio() bool {
+	return s != nil && s.Handling == StreamHandlingNoAudio
+}
+
+// AudioOnly 是否只输出音频
+func (s *SourceStreams) AudioOnly() bool {
+	return s != nil && s.Handling == StreamHandlingAudioOnly
+}
+
+// ToJSON 序列化为 JSON
+func (s *SourceStreams) ToJSON() (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// SourceStreamsFromJSON 反序列化，失败返回 nil
+func SourceStreamsFromJSON(data string) *SourceStreams {
+	if strings.TrimSpace(data) == "" {
+		return nil
+	}
+	var s SourceStreams
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,41 @@
+package vo
+
+import "time"
+
+// AssignmentOutcome 一次作业执行的结果
+type AssignmentOutcome string
+
+const (
+	AssignmentSucceeded AssignmentOutcome = "succeeded"
+	AssignmentFailed    AssignmentOutcome = "failed"
+	AssignmentCancelled AssignmentOutcome = "cancelled" // 停机或任务被取消
+)
+
+// TaskAssignment 作业在某个 worker 上的一次执行记录，用于容量规划
+type TaskAssignment struct {
+	WorkerID     string            `json:"worker_id"`
+	JobType      string            `json:"job_type"`
+	TaskUUID     string            `json:"task_uuid"`
+	Attempt      int               `json:"attempt"`
+	StartedAt    time.Time         `json:"started_at"`
+	FinishedAt   time.Time         `json:"finished_at"`
+	Outcome      AssignmentOutcome `json:"outcome"`
+	SlotWeight   int               `json:"slot_weight"`   // 占用的编码槽位数
+	SlotCapacity int               `json:"slot_capacity"` // 执行时实例的编码槽位总数
+	Wait         time.Duration     `json:"wait"`          // 等待编码槽位的时长
+}
+
+// Overlap 执行区间与 [from, to) 的重叠时长
+func (a *TaskAssignment) Overlap(from, to time.Time) time.Duration {
+	start, end := a.StartedAt, a.FinishedAt
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
@@ -0,0 +1,81 @@
+package vo
+
+import "time"
+
+// TaskGroupStatus 任务组状态：open 可继续加入任务，completed 组内任务已全部结束
+type TaskGroupStatus string
+
+const (
+	TaskGroupOpen      TaskGroupStatus = "open"
+	TaskGroupCompleted TaskGroupStatus = "completed"
+)
+
+// GroupCallbackState 任务组完成回调的投递状态
+type GroupCallbackState string
+
+const (
+	GroupCallbackNone       GroupCallbackState = "none"       // 未配置回调地址
+	GroupCallbackPending    GroupCallbackState = "pending"    // 组已完成，等待投递或重试
+	GroupCallbackDelivering GroupCallbackState = "delivering" // 已被某个实例认领，正在投递
+	GroupCallbackDelivered  GroupCallbackState = "delivered"  // 回调方返回 2xx
+	GroupCallbackFailed     GroupCallbackState = "failed"     // 超过最大尝试次数
+)
+
+// TaskGroupProgress 组内任务按状态归类的计数；expired/rejected 计入 failed，retrying 计入 pending
+type TaskGroupProgress struct {
+	Total      int `json:"total"`
+	Pending    int `json:"pending"`
+	Processing int `json:"processing"`
+	Completed  int `json:"completed"`
+	Failed     int `json:"failed"`
+	Cancelled  int `json:"cancelled"`
+	// activeProgress 未结束任务的进度之和，用于计算整体百分比
+	activeProgress int
+}
+
+// Add 累加一种状态的任务数与进度之和
+func (p *TaskGroupProgress) Add(status TaskStatus, count, progressSum int) {
+	p.Total += count
+	switch status {
+	case TaskStatusCompleted:
+		p.Completed += count
+	case TaskStatusFailed, TaskStatusExpired, TaskStatusRejected:
+		p.Failed += count
+	case TaskStatusCancelled:
+		p.Cancelled += count
+	case TaskStatusProcessing:
+		p.Processing += count
+		p.activeProgress += progressSum
+	default:
+		p.Pending += count
+		p.activeProgress += progressSum
+	}
+}
+
+// Finished 已结束（成功、失败或取消）的任务数
+func (p TaskGroupProgress) Finished() int {
+	return p.Completed + p.Failed + p.Cancelled
+}
+
+// AllFinished 组内至少有一个任务且全部结束
+func (p TaskGroupProgress) AllFinished() bool {
+	return p.Total > 0 && p.Finished() == p.Total
+}
+
+// Percent 整体进度 0-100：已结束的任务按 100 计，其余按各自进度
+func (p TaskGroupProgress) Percent() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return (p.Finished()*100 + p.activeProgress) / p.Total
+}
+
+// TaskGroupCompletion 任务组完成回调的请求体；至少投递一次，接收方按 group_uuid 去重
+type TaskGroupCompletion struct {
+	Event       string            `json:"event"` // task_group.completed
+	GroupUUID   string            `json:"group_uuid"`
+	UserUUID    string            `json:"user_uuid"`
+	Name        string            `json:"name,omitempty"`
+	Progress    TaskGroupProgress `json:"progress"`
+	CompletedAt time.Time         `json:"completed_at"`
+}
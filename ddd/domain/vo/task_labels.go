@@ -0,0 +1,101 @@
+package vo
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 标签限制：避免任意大小的 JSON 进入任务表
+const (
+	MaxTaskLabels        = 16
+	MaxTaskLabelValueLen = 128
+)
+
+var labelKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,62}$`)
+
+// TaskLabels 任务标签（如 campaign=summer、source=mobile-app），创建时设置，用于筛选与指标分组
+type TaskLabels map[string]string
+
+// NewTaskLabels 校验并复制标签：key 为小写字母/数字/._-，value 非空且不超过 128 字符
+func NewTaskLabels(labels map[string]string) (TaskLabels, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	if len(labels) > MaxTaskLabels {
+		return nil, fmt.Errorf("too many labels: %d > %d", len(labels), MaxTaskLabels)
+	}
+	out := make(TaskLabels, len(labels))
+	for k, v := range labels {
+		if !labelKeyPattern.MatchString(k) {
+			return nil, fmt.Errorf("invalid label key %q", k)
+		}
+		if v == "" || len(v) > MaxTaskLabelValueLen || strings.ContainsAny(v, ",=") {
+			return nil, fmt.Errorf("invalid label value for %q", k)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// ParseLabelSelector 解析 "k1=v1,k2=v2" 形式的标签选择器（列表筛选、gRPC metadata 使用）
+func ParseLabelSelector(s string) (TaskLabels, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	raw := make(map[string]string)
+	for _, part := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid label selector %q", part)
+		}
+		raw[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return NewTaskLabels(raw)
+}
+
+// Matches 是否包含选择器中的全部标签
+func (l TaskLabels) Matches(selector TaskLabels) bool {
+	for k, v := range selector {
+		if l[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// String 按 key 排序输出 "k1=v1,k2=v2"
+func (l TaskLabels) String() string {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+l[k])
+	}
+	return strings.Join(parts, ",")
+}
+
+// ToJSON 序列化为 JSON
+func (l TaskLabels) ToJSON() (string, error) {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// TaskLabelsFromJSON 从 JSON 反序列化，解析失败返回空标签
+func TaskLabelsFromJSON(data string) TaskLabels {
+	var l TaskLabels
+	if data == "" {
+		return l
+	}
+	_ = json.Unmarshal([]byte(data), &l)
+	return l
+}
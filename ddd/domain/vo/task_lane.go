@@ -0,0 +1,34 @@
+package vo
+
+import "time"
+
+// TaskLane 任务所在的调度通道：短视频走 express，不必排在长视频编码之后
+type TaskLane string
+
+const (
+	LaneStandard TaskLane = "standard"
+	LaneExpress  TaskLane = "express"
+)
+
+// ClassifyLane 按探测到的源时长分道：时长已知且短于 maxDuration 时为 express；
+// 预览任务只转码前 previewSeconds 秒，按实际转码时长计算。时长未知时走 standard
+func ClassifyLane(durationSeconds float64, previewSeconds int, maxDuration time.Duration) TaskLane {
+	if maxDuration <= 0 {
+		return LaneStandard
+	}
+	if previewSeconds > 0 && (durationSeconds <= 0 || float64(previewSeconds) < durationSeconds) {
+		durationSeconds = float64(previewSeconds)
+	}
+	if durationSeconds <= 0 || durationSeconds >= maxDuration.Seconds() {
+		return LaneStandard
+	}
+	return LaneExpress
+}
+
+// ParseTaskLane 存储中的取值，空值或未知取值视为 standard
+func ParseTaskLane(s string) TaskLane {
+	if TaskLane(s) == LaneExpress {
+		return LaneExpress
+	}
+	return LaneStandard
+}
@@ -0,0 +1,12 @@
+package vo
+
+import "time"
+
+// TaskNote 运维/客服附加在任务上的备注，保留事件上下文
+type TaskNote struct {
+	NoteUUID  string    `json:"note_uuid"`
+	TaskUUID  string    `json:"task_uuid"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
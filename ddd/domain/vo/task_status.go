@@ -13,6 +13,9 @@ var (
 	TaskStatusCompleted  = TaskStatus{value: "completed"}
 	TaskStatusFailed     = TaskStatus{value: "failed"}
 	TaskStatusCancelled  = TaskStatus{value: "cancelled"}
+	TaskStatusExpired    = TaskStatus{value: "expired"}
+	TaskStatusRetrying   = TaskStatus{value: "retrying"} // 存储等瞬时故障后等待退避重试
+	TaskStatusRejected   = TaskStatus{value: "rejected"} // 输入超出时长、大小或分辨率上限
 )
 
 var taskStatusSet = []TaskStatus{
@@ -21,6 +24,9 @@ var taskStatusSet = []TaskStatus{
 	TaskStatusCompleted,
 	TaskStatusFailed,
 	TaskStatusCancelled,
+	TaskStatusExpired,
+	TaskStatusRetrying,
+	TaskStatusRejected,
 }
 
 // NewTaskStatus 尝试从原始值构造，未知值回退为 pending。
@@ -66,10 +72,13 @@ func (ts TaskStatus) IsValid() bool {
 func (ts TaskStatus) CanTransitionTo(target TaskStatus) bool {
 	switch ts {
 	case TaskStatusPending:
-		return target == TaskStatusProcessing || target == TaskStatusFailed || target == TaskStatusCancelled
+		return target == TaskStatusProcessing || target == TaskStatusFailed || target == TaskStatusCancelled || target == TaskStatusExpired
 	case TaskStatusProcessing:
-		return target == TaskStatusCompleted || target == TaskStatusFailed || target == TaskStatusCancelled
-	case TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled:
+		// pending: 卡住任务回收后重新排队
+		return target == TaskStatusPending || target == TaskStatusCompleted || target == TaskStatusFailed || target == TaskStatusCancelled || target == TaskStatusExpired || target == TaskStatusRetrying || target == TaskStatusRejected
+	case TaskStatusRetrying:
+		return target == TaskStatusPending || target == TaskStatusProcessing || target == TaskStatusFailed || target == TaskStatusCancelled || target == TaskStatusExpired
+	case TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled, TaskStatusExpired, TaskStatusRejected:
 		return false // 终态不能再转换
 	default:
 		return false
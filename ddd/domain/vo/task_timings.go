@@ -0,0 +1,121 @@
+package vo
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StageTiming 单个阶段的起止时间，未结束时 FinishedAt 为空
+type StageTiming struct {
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Duration 已结束阶段的耗时，未结束返回 0
+func (st StageTiming) Duration() time.Duration {
+	if st.FinishedAt == nil {
+		return 0
+	}
+	return st.FinishedAt.Sub(st.StartedAt)
+}
+
+// TaskTimings 最近一次执行的计时：开始执行、结束时间与各阶段起止，用于区分排队、下载、编码、上传耗时
+type TaskTimings struct {
+	StartedAt  *time.Time                    `json:"started_at,omitempty"`
+	FinishedAt *time.Time                    `json:"finished_at,omitempty"`
+	Stages     map[PipelineStage]StageTiming `json:"stages,omitempty"`
+	// SourceWaitMs 开始下载前等待源文件就绪的时长（wait-for-source）
+	SourceWaitMs int64 `json:"source_wait_ms,omitempty"`
+}
+
+// IsZero 是否尚未开始执行
+func (tt TaskTimings) IsZero() bool {
+	return tt.StartedAt == nil && tt.FinishedAt == nil && len(tt.Stages) == 0 && tt.SourceWaitMs == 0
+}
+
+// Restart 开始一次新的执行，清除上一次执行（如重试前）的记录
+func (tt *TaskTimings) Restart(at time.Time) {
+	tt.StartedAt = &at
+	tt.FinishedAt = nil
+	tt.Stages = nil
+	tt.SourceWaitMs = 0
+}
+
+// Finish 记录执行结束时间
+func (tt *TaskTimings) Finish(at time.Time) {
+	tt.FinishedAt = &at
+}
+
+// MarkStage 按阶段进度记录起止：首次上报时记为开始，达到 100 时记为结束，已结束的阶段不再改动
+func (tt *TaskTimings) MarkStage(stage PipelineStage, progress int, at time.Time) {
+	if tt.Stages == nil {
+		tt.Stages = make(map[PipelineStage]StageTiming)
+	}
+	st, ok := tt.Stages[stage]
+	if !ok {
+		st = StageTiming{StartedAt: at}
+	}
+	if st.FinishedAt != nil {
+		return
+	}
+	if progress >= 100 {
+		st.FinishedAt = &at
+	}
+	tt.Stages[stage] = st
+}
+
+// StageDuration 已结束阶段的耗时，未记录或未结束时 ok 为 false
+func (tt TaskTimings) StageDuration(stage PipelineStage) (time.Duration, bool) {
+	st, ok := tt.Stages[stage]
+	if !ok || st.FinishedAt == nil {
+		return 0, false
+	}
+	return st.Duration(), true
+}
+
+// QueueWait 创建到最近一次开始执行的等待时间，尚未开始时 ok 为 false
+func (tt TaskTimings) QueueWait(createdAt time.Time) (time.Duration, bool) {
+	if tt.StartedAt == nil || createdAt.IsZero() {
+		return 0, false
+	}
+	return tt.StartedAt.Sub(createdAt), true
+}
+
+// Total 最近一次执行的总耗时，未结束时 ok 为 false
+func (tt TaskTimings) Total() (time.Duration, bool) {
+	if tt.StartedAt == nil || tt.FinishedAt == nil {
+		return 0, false
+	}
+	return tt.FinishedAt.Sub(*tt.StartedAt), true
+}
+
+// Clone 深拷贝
+func (tt TaskTimings) Clone() TaskTimings {
+	out := TaskTimings{StartedAt: tt.StartedAt, FinishedAt: tt.FinishedAt, SourceWaitMs: tt.SourceWaitMs}
+	if tt.Stages != nil {
+		out.Stages = make(map[PipelineStage]StageTiming, len(tt.Stages))
+		for k, v := range tt.Stages {
+			out.Stages[k] = v
+		}
+	}
+	return out
+}
+
+// ToJSON 序列化为 JSON
+func (tt TaskTimings) ToJSON() (string, error) {
+	data, err := json.Marshal(tt)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// TaskTimingsFromJSON 从 JSON 反序列化，解析失败返回空计时
+func TaskTimingsFromJSON(data string) TaskTimings {
+	var tt TaskTimings
+	if data == "" {
+		return tt
+	}
+	_ = json.Unmarshal([]byte(data), &tt)
+	return tt
+}
@@ -0,0 +1,30 @@
+package vo
+
+import "time"
+
+// ThroughputDateLayout 日统计的日期格式，按 UTC 自然日划分
+const ThroughputDateLayout = "2006-01-02"
+
+// DailyThroughput 一天内的处理量：源文件实际下载字节（缓存命中不计）、产物上传字节与 ffmpeg 编码耗时
+type DailyThroughput struct {
+	Date        string        `json:"date"`
+	InputBytes  int64         `json:"input_bytes"`
+	OutputBytes int64         `json:"output_bytes"`
+	Encode      time.Duration `json:"encode"`
+}
+
+// Add 累加另一份计数，日期不变
+func (d *DailyThroughput) Add(o DailyThroughput) {
+	d.InputBytes += o.InputBytes
+	d.OutputBytes += o.OutputBytes
+	d.Encode += o.Encode
+}
+
+func (d DailyThroughput) IsZero() bool {
+	return d.InputBytes == 0 && d.OutputBytes == 0 && d.Encode == 0
+}
+
+// ThroughputDate t 所在的 UTC 日期
+func ThroughputDate(t time.Time) string {
+	return t.UTC().Format(ThroughputDateLayout)
+}
@@ -0,0 +1,68 @@
+package vo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ImageFormat 封面截帧的图片格式
+type ImageFormat string
+
+const (
+	ImageFormatJPEG ImageFormat = "jpeg"
+	ImageFormatWebP ImageFormat = "webp"
+)
+
+// 截帧来源
+const (
+	ThumbnailSourceRendition = "rendition" // 最近一次完成的正式产物，画面与播放一致
+	ThumbnailSourceOriginal  = "source"    // 原始上传文件，画质最高
+)
+
+// 截帧尺寸限制，宽高为 0 时按另一边等比缩放，均为 0 时保持原尺寸
+const (
+	MaxThumbnailDimension = 3840
+)
+
+// ParseImageFormat 解析图片格式，空串默认 jpeg
+func ParseImageFormat(s string) (ImageFormat, error) {
+	switch f := ImageFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case "", "jpg", ImageFormatJPEG:
+		return ImageFormatJPEG, nil
+	case ImageFormatWebP:
+		return ImageFormatWebP, nil
+	default:
+		return "", fmt.Errorf("不支持的图片格式: %s", s)
+	}
+}
+
+// Extension 文件扩展名（含点）
+func (f ImageFormat) Extension() string {
+	if f == ImageFormatWebP {
+		return ".webp"
+	}
+	return ".jpg"
+}
+
+// ContentType 上传对象存储使用的 MIME 类型
+func (f ImageFormat) ContentType() string {
+	return ContentTypeForExtension(f.Extension())
+}
+
+// ThumbnailSpec 单次截帧请求：从 SourceKey 的 At 时刻取一帧，缩放后以 Format 上传到 ObjectKey
+type ThumbnailSpec struct {
+	UserUUID  string        `json:"user_uuid"`
+	VideoUUID string        `json:"video_uuid"`
+	SourceKey string        `json:"source_key"`
+	At        time.Duration `json:"-"`
+	Width     int           `json:"width"`
+	Height    int           `json:"height"`
+	Format    ImageFormat   `json:"format"`
+	ObjectKey string        `json:"object_key"`
+}
+
+// ThumbnailObjectKey 同一视频、时刻、尺寸与格式的截帧复用同一个 key，重复请求覆盖上传
+func ThumbnailObjectKey(userUUID, videoUUID string, at time.Duration, width, height int, format ImageFormat) string {
+	return fmt.Sprintf("/transcoded/thumbnails/%s/%s/%d_%dx%d%s", userUUID, videoUUID, at.Milliseconds(), width, height, format.Extension())
+}
@@ -6,6 +6,24 @@ import "strings"
 type TranscodeParams struct {
 	Resolution string
 	Bitrate    string
+	Container  Container
+	// PreviewSeconds 大于 0 时为预览任务，只转码前 N 秒
+	PreviewSeconds int
+	// VideoStream/AudioStream 显式指定的输入流序号（ffprobe stream index），nil 时按探测结果自动选择
+	VideoStream *int
+	AudioStream *int
+	// Profile profile=auto 时命中的规则与编码覆盖（重放任务的编码器/预设覆盖也记录在此），nil 表示按配置编码
+	Profile *AutoProfile
+	// Audio 随视频一起生成的纯音频产物（m4a/mp3），nil 表示不生成
+	Audio *AudioOutputs
+	// FormatSet 创建时解析出的输出档位集合名（transcode.format_sets），空表示使用全局 output_formats
+	FormatSet string
+	// MaxRenditions HLS 阶梯最多档位数，0 表示不限制（档位集合的上限仍生效）
+	MaxRenditions int
+	// Streams 执行阶段探测到的源文件内容，只用于生成命令；nil 表示按音视频俱全处理
+	Streams *SourceStreams
+	// GOP 创建时按档位解析的 GOP 结构，nil 表示按编码器默认
+	GOP *GOPPolicy
 }
 
 // NewTranscodeParams 创建转码参数
@@ -22,6 +40,44 @@ func NewTranscodeParams(resolution, bitrate string) (*TranscodeParams, error) {
 	}, nil
 }
 
+// WithContainer 设置输出封装格式，空值默认 mp4
+func (tp *TranscodeParams) WithContainer(container string) error {
+	c, err := ParseContainer(container)
+	if err != nil {
+		return err
+	}
+	tp.Container = c
+	return nil
+}
+
+// OutputContainer 输出封装格式，历史任务未记录时为 mp4
+func (tp TranscodeParams) OutputContainer() Container {
+	if tp.Container == "" {
+		return ContainerMP4
+	}
+	return tp.Container
+}
+
+// IsPreview 是否为预览任务
+func (tp TranscodeParams) IsPreview() bool {
+	return tp.PreviewSeconds > 0
+}
+
+// HasStreamSelection 是否显式指定了输入流
+func (tp TranscodeParams) HasStreamSelection() bool {
+	return tp.VideoStream != nil || tp.AudioStream != nil
+}
+
+// HasAudioOutputs 是否请求了纯音频产物
+func (tp TranscodeParams) HasAudioOutputs() bool {
+	return tp.Audio != nil && len(tp.Audio.Renditions) > 0
+}
+
+// IsAutoProfile 是否由 profile=auto 规则选择编码配置
+func (tp TranscodeParams) IsAutoProfile() bool {
+	return tp.Profile != nil
+}
+
 // GetFFmpegArgs 获取FFmpeg参数，允许外部指定视频编码器和预设。
 func (tp *TranscodeParams) GetFFmpegArgs(videoCodec, preset string) []string {
 	if strings.TrimSpace(videoCodec) == "" {
@@ -31,11 +87,14 @@ func (tp *TranscodeParams) GetFFmpegArgs(videoCodec, preset string) []string {
 		preset = "medium"
 	}
 
-	args := []string{
-		"-c:v", videoCodec,
-		"-preset", preset,
-		"-crf", "23",
+	args := []string{"-c:v", videoCodec}
+	if strings.HasPrefix(strings.ToLower(videoCodec), "libvpx") {
+		// libvpx 不支持 -preset，使用 deadline/cpu-used 控制速度
+		args = append(args, "-deadline", "good", "-cpu-used", "4", "-row-mt", "1")
+	} else {
+		args = append(args, "-preset", preset)
 	}
+	args = append(args, "-crf", "23")
 
 	// 设置分辨率
 	switch tp.Resolution {
@@ -0,0 +1,22 @@
+package vo
+
+// VideoProcessingState 视频维度的整体处理状态（由子作业状态推导）
+type VideoProcessingState string
+
+const (
+	VideoStatePending         VideoProcessingState = "pending"          // 尚无子作业开始
+	VideoStateProcessing      VideoProcessingState = "processing"       // 仍有子作业未结束
+	VideoStatePartiallyFailed VideoProcessingState = "partially_failed" // 部分子作业失败，但已有可播放产物
+	VideoStateFailed          VideoProcessingState = "failed"           // 没有可用产物
+	VideoStatePublished       VideoProcessingState = "published"        // 全部子作业成功
+)
+
+// String 返回状态字符串
+func (s VideoProcessingState) String() string {
+	return string(s)
+}
+
+// IsTerminal 是否为终态
+func (s VideoProcessingState) IsTerminal() bool {
+	return s == VideoStatePartiallyFailed || s == VideoStateFailed || s == VideoStatePublished
+}
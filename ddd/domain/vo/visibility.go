@@ -0,0 +1,75 @@
+package vo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Visibility 产物可见性：public 产物走公开 URL，private 产物存放在非公开前缀，只通过签名 URL 访问
+type Visibility string
+
+const (
+	VisibilityPublic  Visibility = "public"
+	VisibilityPrivate Visibility = "private"
+)
+
+// PrivateKeyPrefix 私有产物的对象 key 前缀，存储侧按该前缀路由到非公开桶
+const PrivateKeyPrefix = "private/"
+
+// privateTokenLength 对象 key 中租户令牌的长度（十六进制字符）
+const privateTokenLength = 32
+
+// ParseVisibility 解析可见性，空串返回 fallback
+func ParseVisibility(s string, fallback Visibility) (Visibility, error) {
+	switch Visibility(strings.ToLower(strings.TrimSpace(s))) {
+	case "":
+		return fallback, nil
+	case VisibilityPublic:
+		return VisibilityPublic, nil
+	case VisibilityPrivate:
+		return VisibilityPrivate, nil
+	}
+	return "", fmt.Errorf("unsupported visibility %q", s)
+}
+
+// PrivateKeyToken 由密钥逐级派生用户、视频的令牌，对象 key 中不出现用户与视频 UUID，
+// 同一视频的所有产物共用一个令牌，签名按令牌目录授权
+func PrivateKeyToken(secret, userUUID, videoUUID string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(userUUID))
+	userKey := mac.Sum(nil)
+	mac = hmac.New(sha256.New, userKey)
+	mac.Write([]byte(videoUUID))
+	return hex.EncodeToString(mac.Sum(nil))[:privateTokenLength]
+}
+
+// PrivateObjectKey 将公开产物 key 改写到令牌目录：
+// /transcoded/<user>/<video>_720p_2000k.mp4 -> /private/<token>/720p_2000k.mp4，
+// /transcoded/preview/<user>/<video>_... -> /private/<token>/preview/...；已是私有 key 时原样返回
+func PrivateObjectKey(key, token, userUUID, videoUUID string) string {
+	if _, ok := PrivateScope(key); ok {
+		return key
+	}
+	rest := strings.TrimPrefix(strings.TrimLeft(key, "/"), "transcoded/")
+	dir, base := path.Split(rest)
+	dir = strings.TrimSuffix(strings.TrimSuffix(dir, "/"), userUUID)
+	base = strings.TrimPrefix(base, videoUUID+"_")
+	return "/" + path.Join(PrivateKeyPrefix, token, dir, base)
+}
+
+// PrivateScope 私有 key 的授权目录 private/<token>/，非私有 key 返回 false
+func PrivateScope(key string) (string, bool) {
+	k := strings.TrimLeft(key, "/")
+	if !strings.HasPrefix(k, PrivateKeyPrefix) {
+		return "", false
+	}
+	token, _, ok := strings.Cut(strings.TrimPrefix(k, PrivateKeyPrefix), "/")
+	if !ok || len(token) != privateTokenLength {
+		return "", false
+	}
+	return PrivateKeyPrefix + token + "/", true
+}
@@ -0,0 +1,36 @@
+package vo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 水印位置
+const (
+	WatermarkTopLeft     = "top_left"
+	WatermarkTopRight    = "top_right"
+	WatermarkBottomLeft  = "bottom_left"
+	WatermarkBottomRight = "bottom_right"
+	WatermarkCenter      = "center"
+)
+
+// Watermark 水印配置值对象
+type Watermark struct {
+	ObjectKey string `json:"object_key"` // 水印图片对象键
+	Position  string `json:"position"`   // 水印位置
+}
+
+// Validate 验证水印配置
+func (w *Watermark) Validate() error {
+	if strings.TrimSpace(w.ObjectKey) == "" {
+		return fmt.Errorf("水印图片不能为空")
+	}
+	switch w.Position {
+	case "":
+		w.Position = WatermarkBottomRight
+	case WatermarkTopLeft, WatermarkTopRight, WatermarkBottomLeft, WatermarkBottomRight, WatermarkCenter:
+	default:
+		return fmt.Errorf("不支持的水印位置: %s", w.Position)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package analytics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/infrastructure/database/dao"
+	"transcode-service/ddd/infrastructure/database/po"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/manager"
+	"transcode-service/pkg/metrics"
+)
+
+// settleDelay 只导出该时长之前更新的记录，避免同一秒内后提交的行被游标跳过
+const settleDelay = time.Minute
+
+// Exporter 周期性把已结束的转码/HLS 作业增量导出到分析写入端。
+// 游标存库，多实例同时开启时由条件更新保证至多一个实例推进；并发窗口内可能重复投递，按 record_id 去重
+type Exporter struct {
+	cfg       config.AnalyticsConfig
+	sink      Sink
+	storage   gateway.StorageGateway
+	cursors   *dao.AnalyticsCursorDAO
+	transcode *dao.TranscodeJobDAO
+	hls       *dao.HLSJobDAO
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewExporter 未启用或写入端配置无效时返回 nil
+func NewExporter(cfg *config.Config, storage gateway.StorageGateway) *Exporter {
+	if cfg == nil || !cfg.Analytics.Enabled {
+		return nil
+	}
+	sink, err := NewSink(cfg.Analytics, storage)
+	if err != nil {
+		logger.Errorf("analytics exporter disabled error=%v", err)
+		return nil
+	}
+	return &Exporter{
+		cfg:       cfg.Analytics,
+		sink:      sink,
+		storage:   storage,
+		cursors:   dao.NewAnalyticsCursorDAO(),
+		transcode: dao.NewTranscodeJobDAO(),
+		hls:       dao.NewHLSJobDAO(),
+	}
+}
+
+func (e *Exporter) Name() string { return "analyticsExporter" }
+
+// ShutdownPhase 排空完成的任务也要导出
+func (e *Exporter) ShutdownPhase() manager.Phase { return manager.PhaseFlush }
+
+func (e *Exporter) Start(ctx context.Context) error {
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.exportAll(ctx)
+			}
+		}
+	}()
+	logger.Infof("analytics exporter started sink=%s interval=%s", e.sink.Name(), e.cfg.Interval)
+	return nil
+}
+
+func (e *Exporter) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.wg.Wait()
+	return nil
+}
+
+func (e *Exporter) exportAll(ctx context.Context) {
+	for _, kind := range []string{KindTranscode, KindHLS} {
+		n, err := e.export(ctx, kind)
+		if err != nil {
+			metrics.Add("analytics_export_failures_total", 1)
+			logger.Warnf("analytics export failed kind=%s sink=%s error=%v", kind, e.sink.Name(), err)
+			continue
+		}
+		if n > 0 {
+			logger.Infof("analytics export finished kind=%s sink=%s records=%d", kind, e.sink.Name(), n)
+		}
+	}
+}
+
+// export 逐批导出直到追上 settleDelay 之前的记录，返回导出条数
+func (e *Exporter) export(ctx context.Context, kind string) (int, error) {
+	before := time.Now().Add(-settleDelay)
+	total := 0
+	for ctx.Err() == nil {
+		cur, err := e.cursors.Get(ctx, kind, time.Now().Add(-e.cfg.Backfill))
+		if err != nil {
+			return total, err
+		}
+		records, lastAt, lastID, err := e.load(ctx, kind, cur, before)
+		if err != nil {
+			return total, err
+		}
+		metrics.SetFloat("analytics_export_lag_seconds_"+kind, time.Since(cur.CursorAt).Seconds())
+		if len(records) == 0 {
+			return total, nil
+		}
+		if err := e.sink.Write(ctx, kind, records); err != nil {
+			return total, err
+		}
+		advanced, err := e.cursors.Advance(ctx, kind, cur, lastAt, lastID)
+		if err != nil {
+			return total, err
+		}
+		if !advanced {
+			// 其他实例已推进游标，本批可能重复，交由写入端去重
+			metrics.Add("analytics_export_cursor_conflicts_total", 1)
+			return total, nil
+		}
+		total += len(records)
+		metrics.Add("analytics_export_records_total", int64(len(records)))
+		metrics.Set("analytics_export_last_success_unix", time.Now().Unix())
+		if len(records) < e.cfg.BatchSize {
+			return total, nil
+		}
+	}
+	return total, ctx.Err()
+}
+
+func (e *Exporter) load(ctx context.Context, kind string, cur *po.AnalyticsCursor, before time.Time) ([]*Record, time.Time, uint64, error) {
+	var records []*Record
+	var lastAt time.Time
+	var lastID uint64
+	switch kind {
+	case KindTranscode:
+		jobs, err := e.transcode.QueryFinishedAfter(ctx, cur.CursorAt, cur.CursorID, before, e.cfg.BatchSize)
+		if err != nil {
+			return nil, lastAt, 0, err
+		}
+		for _, j := range jobs {
+			records = append(records, newTranscodeRecord(j))
+			lastAt, lastID = j.UpdatedAt, j.Id
+		}
+	case KindHLS:
+		jobs, err := e.hls.QueryFinishedAfter(ctx, cur.CursorAt, cur.CursorID, before, e.cfg.BatchSize)
+		if err != nil {
+			return nil, lastAt, 0, err
+		}
+		for _, j := range jobs {
+			records = append(records, newHLSRecord(j))
+			lastAt, lastID = j.UpdatedAt, j.Id
+		}
+	}
+	if e.cfg.IncludeObjectSizes && e.storage != nil {
+		for _, r := range records {
+			r.InputBytes = e.objectSize(ctx, r.inputPath)
+			r.OutputBytes = e.objectSize(ctx, r.outputPath)
+		}
+	}
+	return records, lastAt, lastID, nil
+}
+
+// objectSize 对象不存在或已过期清理时返回 0
+func (e *Exporter) objectSize(ctx context.Context, key string) int64 {
+	if key == "" {
+		return 0
+	}
+	info, err := e.storage.StatObject(ctx, key)
+	if err != nil {
+		return 0
+	}
+	return info.Size
+}
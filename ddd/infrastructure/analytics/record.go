@@ -0,0 +1,181 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/po"
+)
+
+// 导出流
+const (
+	KindTranscode = "transcode"
+	KindHLS       = "hls"
+)
+
+// Record 一条已结束作业的分析记录（扁平结构，便于 CSV/仓库表直接映射）
+type Record struct {
+	RecordID          string  `json:"record_id"` // kind:job_uuid:updated_at，重复投递时用于去重
+	Kind              string  `json:"kind"`
+	JobUUID           string  `json:"job_uuid"`
+	UserUUID          string  `json:"user_uuid"`
+	VideoUUID         string  `json:"video_uuid"`
+	Status            string  `json:"status"`
+	Resolution        string  `json:"resolution,omitempty"`
+	Bitrate           string  `json:"bitrate,omitempty"`
+	Container         string  `json:"container,omitempty"`
+	VideoCodec        string  `json:"video_codec,omitempty"`
+	AudioCodec        string  `json:"audio_codec,omitempty"`
+	HWAccel           string  `json:"hwaccel,omitempty"`
+	VariantCount      int     `json:"variant_count,omitempty"`
+	SegmentDuration   int     `json:"segment_duration,omitempty"`
+	Priority          int     `json:"priority,omitempty"`
+	RetryCount        int     `json:"retry_count"`
+	WorkerID          string  `json:"worker_id,omitempty"`
+	ErrorMessage      string  `json:"error_message,omitempty"`
+	Labels            string  `json:"labels,omitempty"` // k1=v1,k2=v2
+	CreatedAt         string  `json:"created_at"`
+	FinishedAt        string  `json:"finished_at"`
+	WallSeconds       float64 `json:"wall_seconds"`                 // 创建到结束
+	ProcessingSeconds float64 `json:"processing_seconds,omitempty"` // 首条 ffmpeg 命令到结束
+	InputBytes        int64   `json:"input_bytes,omitempty"`
+	OutputBytes       int64   `json:"output_bytes,omitempty"`
+	SourceGeneration  int64   `json:"source_generation,omitempty"`
+
+	inputPath  string
+	outputPath string
+}
+
+// csvHeader CSV 列顺序，与 csvRow 一一对应
+var csvHeader = []string{
+	"record_id", "kind", "job_uuid", "user_uuid", "video_uuid", "status",
+	"resolution", "bitrate", "container", "video_codec", "audio_codec", "hwaccel",
+	"variant_count", "segment_duration", "priority", "retry_count", "worker_id", "error_message", "labels",
+	"created_at", "finished_at", "wall_seconds", "processing_seconds", "input_bytes", "output_bytes",
+	"source_generation",
+}
+
+func (r *Record) csvRow() []string {
+	return []string{
+		r.RecordID, r.Kind, r.JobUUID, r.UserUUID, r.VideoUUID, r.Status,
+		r.Resolution, r.Bitrate, r.Container, r.VideoCodec, r.AudioCodec, r.HWAccel,
+		strconv.Itoa(r.VariantCount), strconv.Itoa(r.SegmentDuration), strconv.Itoa(r.Priority), strconv.Itoa(r.RetryCount), r.WorkerID, r.ErrorMessage, r.Labels,
+		r.CreatedAt, r.FinishedAt, formatSeconds(r.WallSeconds), formatSeconds(r.ProcessingSeconds), strconv.FormatInt(r.InputBytes, 10), strconv.FormatInt(r.OutputBytes, 10),
+		strconv.FormatInt(r.SourceGeneration, 10),
+	}
+}
+
+func formatSeconds(s float64) string {
+	return strconv.FormatFloat(s, 'f', 3, 64)
+}
+
+func newTranscodeRecord(job *po.TranscodeJob) *Record {
+	r := &Record{
+		RecordID:         recordID(KindTranscode, job.JobUUID, job.UpdatedAt),
+		Kind:             KindTranscode,
+		JobUUID:          job.JobUUID,
+		UserUUID:         job.UserUUID,
+		VideoUUID:        job.VideoUUID,
+		Status:           job.Status,
+		Resolution:       job.Resolution,
+		Bitrate:          job.Bitrate,
+		Container:        job.Container,
+		Priority:         job.Priority,
+		RetryCount:       job.RetryCount,
+		SourceGeneration: job.SourceGeneration,
+		inputPath:        job.InputPath,
+		outputPath:       job.OutputPath,
+	}
+	if job.Status != vo.TaskStatusCompleted.String() {
+		r.ErrorMessage = job.Message
+	}
+	if job.WorkerID != nil {
+		r.WorkerID = *job.WorkerID
+	}
+	if job.Labels != nil {
+		r.Labels = vo.TaskLabelsFromJSON(string(*job.Labels)).String()
+	}
+	r.fillTimes(job.CreatedAt, job.UpdatedAt)
+	r.fillCommands(job.Commands, job.UpdatedAt)
+	return r
+}
+
+func newHLSRecord(job *po.HLSJob) *Record {
+	r := &Record{
+		RecordID:        recordID(KindHLS, job.JobUUID, job.UpdatedAt),
+		Kind:            KindHLS,
+		JobUUID:         job.JobUUID,
+		UserUUID:        job.UserUUID,
+		VideoUUID:       job.VideoUUID,
+		Status:          job.Status,
+		Container:       job.Format,
+		VariantCount:    job.VariantCount,
+		SegmentDuration: job.SegmentDuration,
+		inputPath:       job.InputPath,
+	}
+	if job.ErrorMessage != nil {
+		r.ErrorMessage = *job.ErrorMessage
+	}
+	if job.WorkerID != nil {
+		r.WorkerID = *job.WorkerID
+	}
+	if job.MasterPlaylist != nil {
+		r.outputPath = *job.MasterPlaylist
+	}
+	r.fillTimes(job.CreatedAt, job.UpdatedAt)
+	r.fillCommands(job.Commands, job.UpdatedAt)
+	return r
+}
+
+func recordID(kind, jobUUID string, updatedAt time.Time) string {
+	return fmt.Sprintf("%s:%s:%d", kind, jobUUID, updatedAt.Unix())
+}
+
+func (r *Record) fillTimes(createdAt, finishedAt time.Time) {
+	r.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	r.FinishedAt = finishedAt.UTC().Format(time.RFC3339)
+	if d := finishedAt.Sub(createdAt); d > 0 {
+		r.WallSeconds = d.Seconds()
+	}
+}
+
+// fillCommands 从已记录的 ffmpeg 命令中提取编码器与开始处理时间
+func (r *Record) fillCommands(raw *string, finishedAt time.Time) {
+	if raw == nil || *raw == "" {
+		return
+	}
+	var cmds vo.FFmpegCommands
+	if err := json.Unmarshal([]byte(*raw), &cmds); err != nil || len(cmds) == 0 {
+		return
+	}
+	first := cmds[0].RecordedAt
+	for _, c := range cmds {
+		if !c.RecordedAt.IsZero() && c.RecordedAt.Before(first) {
+			first = c.RecordedAt
+		}
+		for i := 0; i+1 < len(c.Args); i++ {
+			switch c.Args[i] {
+			case "-c:v", "-vcodec":
+				if r.VideoCodec == "" {
+					r.VideoCodec = c.Args[i+1]
+				}
+			case "-c:a", "-acodec":
+				if r.AudioCodec == "" {
+					r.AudioCodec = c.Args[i+1]
+				}
+			case "-hwaccel":
+				if r.HWAccel == "" {
+					r.HWAccel = c.Args[i+1]
+				}
+			}
+		}
+	}
+	if !first.IsZero() {
+		if d := finishedAt.Sub(first); d > 0 {
+			r.ProcessingSeconds = d.Seconds()
+		}
+	}
+}
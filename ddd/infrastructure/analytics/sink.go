@@ -0,0 +1,178 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/pkg/config"
+	pkgkafka "transcode-service/pkg/kafka"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// Sink 分析数据写入端；Write 失败时游标不推进，下个周期整批重发，写入端需按 record_id 去重
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, kind string, records []*Record) error
+}
+
+// NewSink 按配置创建写入端
+func NewSink(cfg config.AnalyticsConfig, storage gateway.StorageGateway) (Sink, error) {
+	switch strings.ToLower(cfg.Sink) {
+	case "storage":
+		format := strings.ToLower(cfg.Storage.Format)
+		if format != "csv" && format != "jsonl" {
+			// 未引入 parquet 编码依赖，需要列式格式时由仓库侧从 CSV/JSONL 转换
+			return nil, fmt.Errorf("unsupported analytics storage format %q (csv|jsonl)", cfg.Storage.Format)
+		}
+		if storage == nil {
+			return nil, fmt.Errorf("analytics storage sink requires a storage gateway")
+		}
+		return &storageSink{storage: storage, prefix: strings.Trim(cfg.Storage.Prefix, "/"), format: format, tempDir: os.TempDir()}, nil
+	case "http":
+		if cfg.HTTP.URL == "" {
+			return nil, fmt.Errorf("analytics.http.url is required for http sink")
+		}
+		return &httpSink{url: cfg.HTTP.URL, token: cfg.HTTP.Token, client: &http.Client{Timeout: cfg.HTTP.Timeout}}, nil
+	case "kafka":
+		return &kafkaSink{topic: cfg.Kafka.Topic}, nil
+	default:
+		return nil, fmt.Errorf("unknown analytics sink %q (storage|http|kafka)", cfg.Sink)
+	}
+}
+
+// storageSink 每批写一个对象：<prefix>/<kind>/dt=YYYY-MM-DD/<unix_nano>.<format>
+type storageSink struct {
+	storage gateway.StorageGateway
+	prefix  string
+	format  string
+	tempDir string
+}
+
+func (s *storageSink) Name() string { return "storage" }
+
+func (s *storageSink) Write(ctx context.Context, kind string, records []*Record) error {
+	now := time.Now().UTC()
+	f, err := os.CreateTemp(s.tempDir, "analytics-*."+s.format)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := s.encode(f, records); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	key := path.Join(s.prefix, kind, "dt="+now.Format("2006-01-02"), fmt.Sprintf("%d.%s", now.UnixNano(), s.format))
+	contentType := "text/csv"
+	if s.format == "jsonl" {
+		contentType = "application/x-ndjson"
+	}
+	_, err = s.storage.UploadTranscodedFile(ctx, f.Name(), key, contentType)
+	return err
+}
+
+func (s *storageSink) encode(w io.Writer, records []*Record) error {
+	if s.format == "jsonl" {
+		enc := json.NewEncoder(w)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := cw.Write(r.csvRow()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// httpSink BigQuery tabledata.insertAll 兼容格式：{"rows":[{"insertId":..., "json":{...}}]}，
+// insertId 使用 record_id，仓库侧据此去重
+type httpSink struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func (s *httpSink) Name() string { return "http" }
+
+func (s *httpSink) Write(ctx context.Context, kind string, records []*Record) error {
+	type row struct {
+		InsertID string  `json:"insertId"`
+		JSON     *Record `json:"json"`
+	}
+	body := struct {
+		Rows []row `json:"rows"`
+	}{Rows: make([]row, 0, len(records))}
+	for _, r := range records {
+		body.Rows = append(body.Rows, row{InsertID: r.RecordID, JSON: r})
+	}
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Analytics-Kind", kind)
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("analytics http sink status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// kafkaSink 每条记录一条消息，key 为 record_id
+type kafkaSink struct {
+	topic string
+}
+
+func (s *kafkaSink) Name() string { return "kafka" }
+
+func (s *kafkaSink) Write(ctx context.Context, kind string, records []*Record) error {
+	msgs := make([]kafka.Message, 0, len(records))
+	for _, r := range records {
+		raw, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, kafka.Message{
+			Key:     []byte(r.RecordID),
+			Value:   raw,
+			Headers: []kafka.Header{{Key: "analytics-kind", Value: []byte(kind)}},
+			Time:    time.Now(),
+		})
+	}
+	return pkgkafka.DefaultClient().Writer(s.topic).WriteMessages(ctx, msgs...)
+}
@@ -0,0 +1,319 @@
+// Package benchmark 压测（soak）模式：生成 testsrc 合成源文件，按配置速率经完整流水线提交转码任务，
+// 持续 N 分钟后等待任务结束并输出吞吐与耗时报告，用于容量规划与 ffmpeg 升级前的回归
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/workspace"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+const (
+	// VideoUUIDPrefix 压测任务的 video_uuid 前缀，结果回调据此不通知上游
+	VideoUUIDPrefix = "bench-"
+	// sourceObjectPrefix 合成源文件的对象前缀
+	sourceObjectPrefix = "benchmark"
+	// pollInterval 查询已提交任务状态的间隔
+	pollInterval = 5 * time.Second
+	// generateTimeout 单个合成源文件的生成超时
+	generateTimeout = 10 * time.Minute
+)
+
+// Params 单次压测参数，省略的字段取 benchmark 配置
+type Params struct {
+	Resolutions   []string `json:"resolutions"`
+	SourceSeconds int      `json:"source_seconds"`
+	RatePerMinute float64  `json:"rate_per_minute"`
+	Minutes       int      `json:"duration_minutes"`
+}
+
+// SubmitFunc 经应用层创建转码任务，返回 task_uuid；由调用方注入，基础设施层不依赖应用服务
+type SubmitFunc func(ctx context.Context, videoUUID, sourceKey, resolution string) (string, error)
+
+// Runner 同一实例同时只运行一次压测，保留最近一次的报告
+type Runner struct {
+	cfg     *config.Config
+	storage gateway.StorageGateway
+	tasks   repo.TranscodeJobRepository
+	submit  SubmitFunc
+
+	mu      sync.Mutex
+	report  *Report
+	samples []*sample
+	cancel  context.CancelFunc
+}
+
+func NewRunner(cfg *config.Config, storage gateway.StorageGateway, tasks repo.TranscodeJobRepository, submit SubmitFunc) *Runner {
+	if cfg == nil {
+		cfg = config.GetGlobalConfig()
+	}
+	return &Runner{cfg: cfg, storage: storage, tasks: tasks, submit: submit}
+}
+
+// IsBenchmarkVideo video_uuid 是否属于压测任务
+func IsBenchmarkVideo(videoUUID string) bool {
+	return strings.HasPrefix(videoUUID, VideoUUIDPrefix)
+}
+
+// resolve 用配置补齐参数并校验
+func (r *Runner) resolve(p Params) (Params, error) {
+	c := r.cfg.Benchmark
+	if len(p.Resolutions) == 0 {
+		p.Resolutions = c.Resolutions
+	}
+	if p.SourceSeconds == 0 {
+		p.SourceSeconds = c.SourceSeconds
+	}
+	if p.RatePerMinute == 0 {
+		p.RatePerMinute = c.RatePerMinute
+	}
+	if p.Minutes == 0 {
+		p.Minutes = int(c.Duration.Minutes())
+	}
+	if p.SourceSeconds <= 0 || p.SourceSeconds > 600 || p.RatePerMinute <= 0 || p.RatePerMinute > 600 ||
+		p.Minutes <= 0 || time.Duration(p.Minutes)*time.Minute > c.MaxDuration {
+		return p, errno.ErrInvalidBenchmark
+	}
+	for _, res := range p.Resolutions {
+		if vo.ResolutionHeight(res) <= 0 {
+			return p, errno.ErrInvalidBenchmark
+		}
+	}
+	return p, nil
+}
+
+// Start 异步开始压测，返回初始报告
+func (r *Runner) Start(p Params) (*Report, error) {
+	if !r.cfg.Benchmark.Enabled {
+		return nil, errno.ErrBenchmarkDisabled
+	}
+	p, err := r.resolve(p)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return nil, errno.ErrBenchmarkRunning
+	}
+	runID := clock.NewID()
+	if len(runID) > 8 {
+		runID = runID[len(runID)-8:]
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.samples = nil
+	r.report = &Report{RunID: runID, Phase: PhasePreparing, Params: p, StartedAt: time.Now()}
+	metrics.Add("benchmark_runs_total", 1)
+	logger.Infof("benchmark started run_id=%s resolutions=%v source_seconds=%d rate_per_minute=%.2f minutes=%d",
+		runID, p.Resolutions, p.SourceSeconds, p.RatePerMinute, p.Minutes)
+	go r.run(ctx, runID, p)
+	return r.snapshotLocked(), nil
+}
+
+// Stop 停止提交并结束本次压测，已提交的任务照常执行
+func (r *Runner) Stop() (*Report, error) {
+	r.mu.Lock()
+	cancel := r.cancel
+	r.mu.Unlock()
+	if cancel == nil {
+		return nil, errno.ErrBenchmarkNotRunning
+	}
+	cancel()
+	return r.Status()
+}
+
+// Status 当前或最近一次压测的报告
+func (r *Runner) Status() (*Report, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.report == nil {
+		return nil, errno.ErrBenchmarkNotRunning
+	}
+	return r.snapshotLocked(), nil
+}
+
+func (r *Runner) snapshotLocked() *Report {
+	rep := *r.report
+	buildReport(&rep, r.samples, rep.Params.SourceSeconds)
+	return &rep
+}
+
+func (r *Runner) setPhase(phase string) {
+	r.mu.Lock()
+	r.report.Phase = phase
+	r.mu.Unlock()
+}
+
+// finish 记录终态与最终统计
+func (r *Runner) finish(phase string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.report.Phase, r.report.FinishedAt = phase, &now
+	if err != nil {
+		r.report.Error = err.Error()
+	}
+	buildReport(r.report, r.samples, r.report.Params.SourceSeconds)
+	r.cancel = nil
+	rep := r.report
+	logger.Infof("benchmark finished run_id=%s phase=%s submitted=%d completed=%d failed=%d unfinished=%d throughput_per_minute=%.2f realtime_factor=%.2f latency_p95_ms=%d",
+		rep.RunID, rep.Phase, rep.Submitted, rep.Completed, rep.Failed, rep.Unfinished, rep.ThroughputPerMinute, rep.RealtimeFactor, rep.Latency.P95Ms)
+}
+
+func (r *Runner) run(ctx context.Context, runID string, p Params) {
+	sources, err := r.prepareSources(ctx, runID, p)
+	if err != nil {
+		phase := PhaseFailed
+		if ctx.Err() != nil {
+			phase = PhaseStopped
+		}
+		logger.Warnf("benchmark prepare failed run_id=%s error=%v", runID, err)
+		r.finish(phase, err)
+		return
+	}
+
+	r.setPhase(PhaseRunning)
+	interval := time.Duration(float64(time.Minute) / p.RatePerMinute)
+	deadline := time.Now().Add(time.Duration(p.Minutes) * time.Minute)
+	submitTicker := time.NewTicker(interval)
+	defer submitTicker.Stop()
+	pollTicker := time.NewTicker(pollInterval)
+	defer pollTicker.Stop()
+	seq := 0
+	r.submitOne(ctx, runID, seq, p.Resolutions[0], sources)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			r.finish(PhaseStopped, nil)
+			return
+		case <-submitTicker.C:
+			seq++
+			r.submitOne(ctx, runID, seq, p.Resolutions[seq%len(p.Resolutions)], sources)
+		case <-pollTicker.C:
+			r.poll(ctx)
+		}
+	}
+
+	r.setPhase(PhaseDraining)
+	drainDeadline := time.Now().Add(r.cfg.Benchmark.DrainTimeout)
+	for time.Now().Before(drainDeadline) {
+		if r.poll(ctx) == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			r.finish(PhaseStopped, nil)
+			return
+		case <-pollTicker.C:
+		}
+	}
+	r.finish(PhaseFinished, nil)
+}
+
+// submitOne 提交一个任务，失败只计数
+func (r *Runner) submitOne(ctx context.Context, runID string, seq int, resolution string, sources map[string]string) {
+	videoUUID := fmt.Sprintf("%s%s-%06d", VideoUUIDPrefix, runID, seq)
+	submittedAt := time.Now()
+	taskUUID, err := r.submit(ctx, videoUUID, sources[resolution], resolution)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.report.SubmitErrors++
+		metrics.Add("benchmark_submit_errors_total", 1)
+		logger.Warnf("benchmark submit failed run_id=%s video_uuid=%s error=%v", runID, videoUUID, err)
+		return
+	}
+	metrics.Add("benchmark_tasks_submitted_total", 1)
+	r.samples = append(r.samples, &sample{taskUUID: taskUUID, resolution: resolution, submittedAt: submittedAt})
+}
+
+// poll 刷新未结束任务的状态，返回仍未结束的数量
+func (r *Runner) poll(ctx context.Context) int {
+	r.mu.Lock()
+	pending := make([]*sample, 0, len(r.samples))
+	for _, s := range r.samples {
+		if !s.done {
+			pending = append(pending, s)
+		}
+	}
+	r.mu.Unlock()
+
+	remaining := 0
+	for _, s := range pending {
+		task, err := r.tasks.GetTranscodeJob(ctx, s.taskUUID)
+		if err != nil {
+			remaining++
+			continue
+		}
+		r.mu.Lock()
+		finished := s.observe(task, time.Now())
+		r.mu.Unlock()
+		if !finished {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+// prepareSources 每个分辨率生成一个合成源文件并上传，所有任务复用
+func (r *Runner) prepareSources(ctx context.Context, runID string, p Params) (map[string]string, error) {
+	if r.storage == nil {
+		return nil, fmt.Errorf("storage gateway not configured")
+	}
+	ws, err := workspace.DefaultManager().Acquire("benchmark-" + runID)
+	if err != nil {
+		return nil, fmt.Errorf("create workspace: %w", err)
+	}
+	defer ws.Release()
+	sources := make(map[string]string, len(p.Resolutions))
+	for _, res := range p.Resolutions {
+		if _, ok := sources[res]; ok {
+			continue
+		}
+		local := ws.Path(res + ".mp4")
+		if err := r.generate(ctx, res, p.SourceSeconds, local); err != nil {
+			return nil, fmt.Errorf("generate %s source: %w", res, err)
+		}
+		key, err := r.storage.UploadTranscodedFile(ctx, local, fmt.Sprintf("%s/%s/%s.mp4", sourceObjectPrefix, runID, res), "video/mp4")
+		if err != nil {
+			return nil, fmt.Errorf("upload %s source: %w", res, err)
+		}
+		sources[res] = key
+	}
+	return sources, nil
+}
+
+// generate 用 testsrc2 与正弦音生成源文件；testsrc2 画面持续变化，编码负载接近真实内容
+func (r *Runner) generate(ctx context.Context, resolution string, seconds int, output string) error {
+	h := vo.ResolutionHeight(resolution)
+	w := (h*16/9 + 1) &^ 1
+	args := []string{"-hide_banner", "-v", "error",
+		"-f", "lavfi", "-i", fmt.Sprintf("testsrc2=size=%dx%d:rate=30", w, h),
+		"-f", "lavfi", "-i", "sine=frequency=440:sample_rate=48000",
+		"-t", fmt.Sprint(seconds),
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "18", "-pix_fmt", "yuv420p",
+		"-c:a", "aac", "-b:a", "128k",
+		"-y", output,
+	}
+	genCtx, cancel := context.WithTimeout(ctx, generateTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(genCtx, r.cfg.Transcode.FFmpeg.Binary(), args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package benchmark
+
+import (
+	"sort"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+)
+
+// 压测阶段
+const (
+	PhasePreparing = "preparing" // 生成并上传合成源文件
+	PhaseRunning   = "running"   // 按速率提交任务
+	PhaseDraining  = "draining"  // 停止提交，等待已提交任务结束
+	PhaseFinished  = "finished"
+	PhaseStopped   = "stopped" // 人工停止，报告只含已结束的任务
+	PhaseFailed    = "failed"  // 准备阶段失败
+)
+
+// LatencyStats 耗时分位（毫秒），无样本时全为 0
+type LatencyStats struct {
+	Samples int   `json:"samples"`
+	MeanMs  int64 `json:"mean_ms"`
+	P50Ms   int64 `json:"p50_ms"`
+	P95Ms   int64 `json:"p95_ms"`
+	MaxMs   int64 `json:"max_ms"`
+}
+
+func newLatencyStats(ds []time.Duration) LatencyStats {
+	if len(ds) == 0 {
+		return LatencyStats{}
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	var sum time.Duration
+	for _, d := range ds {
+		sum += d
+	}
+	pct := func(p float64) int64 {
+		return ds[int(p*float64(len(ds)-1))].Milliseconds()
+	}
+	return LatencyStats{
+		Samples: len(ds),
+		MeanMs:  (sum / time.Duration(len(ds))).Milliseconds(),
+		P50Ms:   pct(0.50),
+		P95Ms:   pct(0.95),
+		MaxMs:   ds[len(ds)-1].Milliseconds(),
+	}
+}
+
+// ResolutionReport 单个分辨率的结果
+type ResolutionReport struct {
+	Resolution string       `json:"resolution"`
+	Submitted  int          `json:"submitted"`
+	Completed  int          `json:"completed"`
+	Failed     int          `json:"failed"`
+	Latency    LatencyStats `json:"latency"`
+	Encode     LatencyStats `json:"encode"`
+}
+
+// Report 压测报告；运行中查询时为截至当前的中间结果
+type Report struct {
+	RunID      string     `json:"run_id"`
+	Phase      string     `json:"phase"`
+	Error      string     `json:"error,omitempty"`
+	Params     Params     `json:"params"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	Submitted    int `json:"submitted"`
+	SubmitErrors int `json:"submit_errors"`
+	Completed    int `json:"completed"`
+	Failed       int `json:"failed"` // failed/cancelled/expired/rejected
+	Unfinished   int `json:"unfinished"`
+
+	// ThroughputPerMinute 首个任务提交到最后一个任务结束期间每分钟完成的任务数
+	ThroughputPerMinute float64 `json:"throughput_per_minute"`
+	// RealtimeFactor 每秒墙钟时间完成的源视频秒数，>1 表示快于实时
+	RealtimeFactor float64 `json:"realtime_factor"`
+	// Latency 提交到结束；QueueWait 创建到开始执行；Encode 编码阶段
+	Latency      LatencyStats       `json:"latency"`
+	QueueWait    LatencyStats       `json:"queue_wait"`
+	Encode       LatencyStats       `json:"encode"`
+	ByResolution []ResolutionReport `json:"by_resolution"`
+}
+
+// sample 一个已提交的任务
+type sample struct {
+	taskUUID    string
+	resolution  string
+	submittedAt time.Time
+	finishedAt  time.Time
+	done        bool
+	succeeded   bool
+	queueWait   time.Duration
+	encode      time.Duration
+	hasQueue    bool
+	hasEncode   bool
+}
+
+// observe 按任务当前状态更新样本，返回任务是否已结束
+func (s *sample) observe(task *entity.TranscodeTaskEntity, now time.Time) bool {
+	if task == nil || !task.IsTerminal() {
+		return false
+	}
+	s.done, s.succeeded = true, task.IsCompleted()
+	s.finishedAt = now
+	tt := task.Timings()
+	if tt.FinishedAt != nil {
+		s.finishedAt = *tt.FinishedAt
+	}
+	s.queueWait, s.hasQueue = tt.QueueWait(task.CreatedAt())
+	s.encode, s.hasEncode = tt.StageDuration(vo.StageEncode)
+	return true
+}
+
+type resolutionAgg struct {
+	rep              ResolutionReport
+	latency, encodes []time.Duration
+}
+
+// buildReport 由样本汇总报告的统计部分
+func buildReport(r *Report, samples []*sample, sourceSeconds int) {
+	r.Submitted, r.Completed, r.Failed, r.Unfinished = len(samples), 0, 0, 0
+	var latency, queue, encode []time.Duration
+	byRes := map[string]*resolutionAgg{}
+	var first, last time.Time
+	for _, s := range samples {
+		agg, ok := byRes[s.resolution]
+		if !ok {
+			agg = &resolutionAgg{rep: ResolutionReport{Resolution: s.resolution}}
+			byRes[s.resolution] = agg
+		}
+		agg.rep.Submitted++
+		if first.IsZero() || s.submittedAt.Before(first) {
+			first = s.submittedAt
+		}
+		if !s.done {
+			r.Unfinished++
+			continue
+		}
+		if !s.succeeded {
+			r.Failed++
+			agg.rep.Failed++
+			continue
+		}
+		r.Completed++
+		agg.rep.Completed++
+		if s.finishedAt.After(last) {
+			last = s.finishedAt
+		}
+		d := s.finishedAt.Sub(s.submittedAt)
+		latency = append(latency, d)
+		agg.latency = append(agg.latency, d)
+		if s.hasQueue {
+			queue = append(queue, s.queueWait)
+		}
+		if s.hasEncode {
+			encode = append(encode, s.encode)
+			agg.encodes = append(agg.encodes, s.encode)
+		}
+	}
+	r.Latency, r.QueueWait, r.Encode = newLatencyStats(latency), newLatencyStats(queue), newLatencyStats(encode)
+	r.ThroughputPerMinute, r.RealtimeFactor = 0, 0
+	if elapsed := last.Sub(first); r.Completed > 0 && elapsed > 0 {
+		r.ThroughputPerMinute = float64(r.Completed) / elapsed.Minutes()
+		r.RealtimeFactor = float64(r.Completed*sourceSeconds) / elapsed.Seconds()
+	}
+	r.ByResolution = nil
+	for _, res := range r.Params.Resolutions {
+		if agg, ok := byRes[res]; ok {
+			agg.rep.Latency, agg.rep.Encode = newLatencyStats(agg.latency), newLatencyStats(agg.encodes)
+			r.ByResolution = append(r.ByResolution, agg.rep)
+		}
+	}
+}
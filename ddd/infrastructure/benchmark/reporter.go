@@ -0,0 +1,54 @@
+package benchmark
+
+import (
+	"context"
+
+	"transcode-service/ddd/domain/gateway"
+)
+
+// quietReporter 压测任务的结果不通知上游，其余任务原样转发
+type quietReporter struct {
+	inner gateway.TranscodeResultReporter
+}
+
+// QuietReporter 包装结果上报，跳过 video_uuid 带压测前缀的任务
+func QuietReporter(inner gateway.TranscodeResultReporter) gateway.TranscodeResultReporter {
+	return &quietReporter{inner: inner}
+}
+
+func (q *quietReporter) ReportSuccess(ctx context.Context, videoUUID, taskUUID, videoURL string) error {
+	if IsBenchmarkVideo(videoUUID) {
+		return nil
+	}
+	return q.inner.ReportSuccess(ctx, videoUUID, taskUUID, videoURL)
+}
+
+func (q *quietReporter) ReportFailure(ctx context.Context, videoUUID, taskUUID, errorMessage string) error {
+	if IsBenchmarkVideo(videoUUID) {
+		return nil
+	}
+	return q.inner.ReportFailure(ctx, videoUUID, taskUUID, errorMessage)
+}
+
+func (q *quietReporter) ReportExpired(ctx context.Context, videoUUID, taskUUID, reason string) error {
+	if IsBenchmarkVideo(videoUUID) {
+		return nil
+	}
+	return q.inner.ReportExpired(ctx, videoUUID, taskUUID, reason)
+}
+
+func (q *quietReporter) ReportRejected(ctx context.Context, videoUUID, taskUUID, reason string) error {
+	if IsBenchmarkVideo(videoUUID) {
+		return nil
+	}
+	return q.inner.ReportRejected(ctx, videoUUID, taskUUID, reason)
+}
+
+// ReportMilestone 被包装的上报不支持里程碑时忽略
+func (q *quietReporter) ReportMilestone(ctx context.Context, videoUUID, taskUUID, milestone string) error {
+	mr, ok := q.inner.(gateway.TranscodeMilestoneReporter)
+	if !ok || IsBenchmarkVideo(videoUUID) {
+		return nil
+	}
+	return mr.ReportMilestone(ctx, videoUUID, taskUUID, milestone)
+}
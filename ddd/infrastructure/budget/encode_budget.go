@@ -0,0 +1,257 @@
+package budget
+
+import (
+	"context"
+	"sync"
+
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// 作业类型
+const (
+	JobTranscode = "transcode"
+	JobHLS       = "hls"
+	JobThumbnail = "thumbnail"
+)
+
+var (
+	budgetOnce    sync.Once
+	defaultBudget *EncodeBudget
+)
+
+// DefaultEncodeBudget 进程内共享的编码槽位预算，MP4 与 HLS worker 均从中申请
+func DefaultEncodeBudget() *EncodeBudget {
+	budgetOnce.Do(func() {
+		var cfg config.EncodeBudgetConfig
+		if c := config.GetGlobalConfig(); c != nil {
+			cfg = c.Worker.EncodeBudget
+			if cfg.Slots <= 0 {
+				cfg.Slots = c.Worker.MaxConcurrentTasks
+			}
+		}
+		defaultBudget = NewEncodeBudget(cfg)
+	})
+	return defaultBudget
+}
+
+// Stats 预算当前状态
+type Stats struct {
+	Enabled           bool           `json:"enabled"`
+	Slots             int            `json:"slots"`
+	Used              int            `json:"used"`
+	Waiting           int            `json:"waiting"`
+	JobWeights        map[string]int `json:"job_weights"`
+	ResolutionWeights map[string]int `json:"resolution_weights"`
+	// Reservation 转码槽位预留状态，未开启时省略
+	Reservation *ReservationStats `json:"reservation,omitempty"`
+}
+
+// EncodeBudget 带权重的 FIFO 信号量，容量与权重可在运行时调整
+type EncodeBudget struct {
+	mu                sync.Mutex
+	enabled           bool
+	slots             int
+	used              int
+	waiters           []*waiter
+	jobWeights        map[string]int
+	resolutionWeights map[string]int
+}
+
+type waiter struct {
+	weight int
+	ready  chan struct{}
+}
+
+// Lease 已占用的槽位，作业结束后必须 Release
+type Lease struct {
+	budget *EncodeBudget
+	weight int
+	once   sync.Once
+}
+
+// Weight 本次占用的槽位数
+func (l *Lease) Weight() int {
+	if l == nil {
+		return 0
+	}
+	return l.weight
+}
+
+// Release 归还槽位，可重复调用
+func (l *Lease) Release() {
+	if l == nil || l.budget == nil || l.weight == 0 {
+		return
+	}
+	l.once.Do(func() { l.budget.release(l.weight) })
+}
+
+func NewEncodeBudget(cfg config.EncodeBudgetConfig) *EncodeBudget {
+	if cfg.Slots <= 0 {
+		cfg.Slots = 1
+	}
+	b := &EncodeBudget{enabled: cfg.Enabled, slots: cfg.Slots}
+	b.setWeights(cfg.JobWeights, cfg.ResolutionWeights)
+	b.publishLocked()
+	return b
+}
+
+// Acquire 按作业类型与分辨率申请槽位，预算不足时排队等待；多分辨率作业（HLS）按最重的分辨率计算
+func (b *EncodeBudget) Acquire(ctx context.Context, jobType string, resolutions ...string) (*Lease, error) {
+	b.mu.Lock()
+	if !b.enabled {
+		b.mu.Unlock()
+		return &Lease{}, nil
+	}
+	weight := b.weightLocked(jobType, resolutions)
+	if len(b.waiters) == 0 && b.used+weight <= b.slots {
+		b.used += weight
+		b.publishLocked()
+		b.mu.Unlock()
+		return &Lease{budget: b, weight: weight}, nil
+	}
+	w := &waiter{weight: weight, ready: make(chan struct{})}
+	b.waiters = append(b.waiters, w)
+	b.publishLocked()
+	b.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return &Lease{budget: b, weight: weight}, nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		select {
+		case <-w.ready:
+			// 取消与分配同时发生，归还已分配的槽位
+			b.used -= weight
+		default:
+			for i, x := range b.waiters {
+				if x == w {
+					b.waiters = append(b.waiters[:i], b.waiters[i+1:]...)
+					break
+				}
+			}
+		}
+		b.grantLocked()
+		b.publishLocked()
+		return nil, ctx.Err()
+	}
+}
+
+// Reconfigure 运行时调整容量与权重；nil 表示保持不变，缩容不会中断已运行的作业
+func (b *EncodeBudget) Reconfigure(enabled *bool, slots int, jobWeights, resolutionWeights map[string]int) Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if enabled != nil {
+		b.enabled = *enabled
+	}
+	if slots > 0 {
+		b.slots = slots
+	}
+	jw, rw := b.jobWeights, b.resolutionWeights
+	if jobWeights != nil {
+		jw = jobWeights
+	}
+	if resolutionWeights != nil {
+		rw = resolutionWeights
+	}
+	b.setWeights(jw, rw)
+	if !b.enabled {
+		// 关闭时放行全部等待者，已持有的槽位按原权重归还
+		for _, w := range b.waiters {
+			b.used += w.weight
+			close(w.ready)
+		}
+		b.waiters = nil
+	}
+	b.grantLocked()
+	b.publishLocked()
+	logger.Infof("encode budget reconfigured enabled=%t slots=%d used=%d job_weights=%v resolution_weights=%v", b.enabled, b.slots, b.used, b.jobWeights, b.resolutionWeights)
+	return b.statsLocked()
+}
+
+// Stats 返回当前状态
+func (b *EncodeBudget) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.statsLocked()
+}
+
+func (b *EncodeBudget) release(weight int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used -= weight
+	if b.used < 0 {
+		b.used = 0
+	}
+	b.grantLocked()
+	b.publishLocked()
+}
+
+// grantLocked 按 FIFO 唤醒能放下的等待者，队首放不下时不跳过，避免重作业饿死
+func (b *EncodeBudget) grantLocked() {
+	for len(b.waiters) > 0 {
+		w := b.waiters[0]
+		if b.used+w.weight > b.slots && b.used > 0 {
+			return
+		}
+		b.used += w.weight
+		b.waiters = b.waiters[1:]
+		close(w.ready)
+	}
+}
+
+// weightLocked 权重不超过总容量，保证单个重作业在空闲时总能执行
+func (b *EncodeBudget) weightLocked(jobType string, resolutions []string) int {
+	weight := 1
+	if w, ok := b.jobWeights[jobType]; ok && w > 0 {
+		weight = w
+	}
+	resWeight := 1
+	for _, r := range resolutions {
+		if w, ok := b.resolutionWeights[r]; ok && w > resWeight {
+			resWeight = w
+		}
+	}
+	weight *= resWeight
+	if weight > b.slots {
+		weight = b.slots
+	}
+	return weight
+}
+
+func (b *EncodeBudget) setWeights(jobWeights, resolutionWeights map[string]int) {
+	b.jobWeights = make(map[string]int, len(jobWeights))
+	for k, v := range jobWeights {
+		b.jobWeights[k] = v
+	}
+	b.resolutionWeights = make(map[string]int, len(resolutionWeights))
+	for k, v := range resolutionWeights {
+		b.resolutionWeights[k] = v
+	}
+}
+
+func (b *EncodeBudget) statsLocked() Stats {
+	s := Stats{Enabled: b.enabled, Slots: b.slots, Used: b.used, Waiting: len(b.waiters)}
+	s.JobWeights = make(map[string]int, len(b.jobWeights))
+	for k, v := range b.jobWeights {
+		s.JobWeights[k] = v
+	}
+	s.ResolutionWeights = make(map[string]int, len(b.resolutionWeights))
+	for k, v := range b.resolutionWeights {
+		s.ResolutionWeights[k] = v
+	}
+	if r := DefaultReservation(); r != nil {
+		rs := r.Stats()
+		s.Reservation = &rs
+	}
+	return s
+}
+
+func (b *EncodeBudget) publishLocked() {
+	metrics.Set("encode_budget_slots", int64(b.slots))
+	metrics.Set("encode_budget_used", int64(b.used))
+	metrics.Set("encode_budget_waiting", int64(len(b.waiters)))
+}
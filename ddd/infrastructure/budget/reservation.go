@@ -0,0 +1,149 @@
+package budget
+
+import (
+	"sync"
+
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/metrics"
+)
+
+var (
+	reservationOnce    sync.Once
+	defaultReservation *Reservation
+)
+
+// DefaultReservation 转码槽位预留，槽位数为 worker.max_concurrent_tasks；未开启时返回 nil
+func DefaultReservation() *Reservation {
+	reservationOnce.Do(func() {
+		cfg := config.GetGlobalConfig()
+		if cfg == nil {
+			return
+		}
+		defaultReservation = NewReservation(cfg.Worker.Priority.Reservation, cfg.Worker.MaxConcurrentTasks)
+	})
+	return defaultReservation
+}
+
+// ReservationStats 预留状态
+type ReservationStats struct {
+	Slots           int `json:"slots"`
+	Reserved        int `json:"reserved"`
+	MinPriority     int `json:"min_priority"`
+	GeneralRunning  int `json:"general_running"`  // 低于 min_priority 的运行中任务
+	PriorityRunning int `json:"priority_running"` // 不低于 min_priority 的运行中任务
+	ReservedInUse   int `json:"reserved_in_use"`  // 超出普通任务上限、只能由高优先级任务占用的槽位
+}
+
+// Reservation 为高优先级任务预留部分转码槽位：出队时低优先级任务只能占用 slots - reserved 个名额，
+// 高优先级任务可占用全部槽位；名额在任务出队时占用，执行结束后 Release
+type Reservation struct {
+	mu          sync.Mutex
+	slots       int
+	reserved    int
+	minPriority int
+	running     map[string]bool // taskUUID -> 是否高优先级
+	general     int
+	priority    int
+	changed     chan struct{}
+}
+
+func NewReservation(cfg config.ReservationConfig, slots int) *Reservation {
+	if !cfg.Enabled || slots <= 1 {
+		return nil
+	}
+	reserved := int(float64(slots)*cfg.ReservedRatio + 0.5)
+	if reserved >= slots {
+		reserved = slots - 1
+	}
+	if reserved <= 0 {
+		return nil
+	}
+	r := &Reservation{
+		slots:       slots,
+		reserved:    reserved,
+		minPriority: cfg.MinPriority,
+		running:     make(map[string]bool),
+		changed:     make(chan struct{}),
+	}
+	r.publishLocked()
+	return r
+}
+
+// Admit 按任务原始优先级占用名额，低优先级任务超出普通上限时返回 false
+func (r *Reservation) Admit(taskUUID string, priority int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.running[taskUUID]; ok {
+		return true
+	}
+	high := priority >= r.minPriority
+	if !high && r.general >= r.slots-r.reserved {
+		return false
+	}
+	r.running[taskUUID] = high
+	if high {
+		r.priority++
+	} else {
+		r.general++
+	}
+	r.publishLocked()
+	return true
+}
+
+// Release 归还名额并唤醒因预留而等待的消费者，可重复调用
+func (r *Reservation) Release(taskUUID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	high, ok := r.running[taskUUID]
+	if !ok {
+		return
+	}
+	delete(r.running, taskUUID)
+	if high {
+		r.priority--
+	} else {
+		r.general--
+	}
+	close(r.changed)
+	r.changed = make(chan struct{})
+	r.publishLocked()
+}
+
+// Changed 下一次名额归还时关闭的通道
+func (r *Reservation) Changed() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.changed
+}
+
+// Stats 返回当前状态
+func (r *Reservation) Stats() ReservationStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return ReservationStats{
+		Slots: r.slots, Reserved: r.reserved, MinPriority: r.minPriority,
+		GeneralRunning: r.general, PriorityRunning: r.priority,
+		ReservedInUse: r.reservedInUseLocked(),
+	}
+}
+
+// reservedInUseLocked 运行总数超出普通任务上限的部分，只可能是高优先级任务
+func (r *Reservation) reservedInUseLocked() int {
+	n := r.general + r.priority - (r.slots - r.reserved)
+	if n < 0 {
+		return 0
+	}
+	if n > r.reserved {
+		return r.reserved
+	}
+	return n
+}
+
+func (r *Reservation) publishLocked() {
+	inUse := r.reservedInUseLocked()
+	metrics.Set("capacity_reservation_reserved_slots", int64(r.reserved))
+	metrics.Set("capacity_reservation_reserved_in_use", int64(inUse))
+	metrics.SetFloat("capacity_reservation_reserved_utilization", float64(inUse)/float64(r.reserved))
+	metrics.Set("capacity_reservation_general_running", int64(r.general))
+	metrics.Set("capacity_reservation_priority_running", int64(r.priority))
+}
@@ -27,9 +27,41 @@ func (c *HLSJobConvertor) ToEntity(poJob *po.HLSJob) *entity.HLSJobEntity {
 		cfg.SetOutputPath(*poJob.MasterPlaylist)
 	}
 	e := entity.NewHLSJobEntity(poJob.JobUUID, poJob.UserUUID, poJob.VideoUUID, poJob.InputPath, poJob.OutputDir, *cfg)
+	e.SetProgress(poJob.Progress)
+	e.SetSource(poJob.SourceJobUUID, poJob.SourceType)
+	e.SetPrivateToken(poJob.PrivateToken)
+	e.SetOutputDestination(vo.OutputDestination{Bucket: poJob.OutputBucket, Prefix: poJob.OutputPrefix})
+	if poJob.MasterPlaylist != nil {
+		e.SetMasterPlaylist(*poJob.MasterPlaylist)
+	}
+	if poJob.ErrorMessage != nil {
+		e.SetError(*poJob.ErrorMessage)
+	}
+	if poJob.Commands != nil {
+		e.SetCommands(vo.FFmpegCommandsFromJSON(*poJob.Commands))
+	}
+	if poJob.Renditions != nil {
+		e.SetRenditions(vo.HLSRenditionsFromJSON(*poJob.Renditions))
+	}
+	if poJob.Fingerprint != nil {
+		e.SetFingerprint(vo.EncoderFingerprintFromJSON(*poJob.Fingerprint))
+	}
+	e.Restore(poJob.Id, poJob.Status, poJob.CreatedAt, poJob.UpdatedAt)
 	return e
 }
 
+// fingerprintColumns 指纹拆分为 JSON 与可索引的版本、哈希列，未记录时全为零值
+func fingerprintColumns(fp *vo.EncoderFingerprint) (*string, int, string) {
+	if fp == nil {
+		return nil, 0, ""
+	}
+	data, err := fp.ToJSON()
+	if err != nil {
+		return nil, 0, ""
+	}
+	return &data, fp.SettingsVersion, fp.Hash
+}
+
 func (c *HLSJobConvertor) ToPO(e *entity.HLSJobEntity) *po.HLSJob {
 	var profiles *string
 	if e.GetConfig() != nil {
@@ -37,11 +69,18 @@ func (c *HLSJobConvertor) ToPO(e *entity.HLSJobEntity) *po.HLSJob {
 			profiles = &json
 		}
 	}
+	var renditions *string
+	if data, err := e.Renditions().ToJSON(); err == nil {
+		renditions = &data
+	}
+	fingerprint, settingsVersion, fingerprintHash := fingerprintColumns(e.Fingerprint())
 	return &po.HLSJob{
 		BaseModel:       po.BaseModel{Id: e.ID(), CreatedAt: e.CreatedAt(), UpdatedAt: e.UpdatedAt()},
 		JobUUID:         e.JobUUID(),
 		UserUUID:        e.UserUUID(),
 		VideoUUID:       e.VideoUUID(),
+		SourceJobUUID:   e.SourceJobUUID(),
+		SourceType:      e.SourceType(),
 		InputPath:       e.InputPath(),
 		OutputDir:       e.OutputDir(),
 		MasterPlaylist:  e.MasterPlaylist(),
@@ -52,5 +91,12 @@ func (c *HLSJobConvertor) ToPO(e *entity.HLSJobEntity) *po.HLSJob {
 		ListSize:        e.GetConfig().ListSize,
 		Format:          e.GetConfig().Format,
 		VariantCount:    e.GetConfig().GetResolutionCount(),
+		Renditions:      renditions,
+		Fingerprint:     fingerprint,
+		SettingsVersion: settingsVersion,
+		FingerprintHash: fingerprintHash,
+		PrivateToken:    e.PrivateToken(),
+		OutputBucket:    e.OutputDestination().Bucket,
+		OutputPrefix:    e.OutputDestination().Prefix,
 	}
 }
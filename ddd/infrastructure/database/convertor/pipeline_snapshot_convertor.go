@@ -0,0 +1,38 @@
+package convertor
+
+import (
+	"encoding/json"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/po"
+)
+
+type PipelineSnapshotConvertor struct{}
+
+func NewPipelineSnapshotConvertor() *PipelineSnapshotConvertor { return &PipelineSnapshotConvertor{} }
+
+func (c *PipelineSnapshotConvertor) ToVO(p *po.PipelineSnapshot) *vo.PipelineSnapshot {
+	if p == nil {
+		return nil
+	}
+	snap := &vo.PipelineSnapshot{InstanceID: p.InstanceID, TakenAt: p.TakenAt, Released: p.Released}
+	_ = json.Unmarshal([]byte(p.ClaimsJSON), &snap.Claims)
+	return snap
+}
+
+func (c *PipelineSnapshotConvertor) ToPO(s *vo.PipelineSnapshot) (*po.PipelineSnapshot, error) {
+	claims := s.Claims
+	if claims == nil {
+		claims = []vo.TaskClaim{}
+	}
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &po.PipelineSnapshot{
+		InstanceID: s.InstanceID,
+		ClaimsJSON: string(data),
+		Released:   s.Released,
+		TakenAt:    s.TakenAt,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package convertor
+
+import (
+	"time"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/po"
+)
+
+type TaskAssignmentConvertor struct{}
+
+func NewTaskAssignmentConvertor() *TaskAssignmentConvertor { return &TaskAssignmentConvertor{} }
+
+func (c *TaskAssignmentConvertor) ToVO(p *po.TaskAssignment) *vo.TaskAssignment {
+	if p == nil {
+		return nil
+	}
+	return &vo.TaskAssignment{
+		WorkerID:     p.WorkerID,
+		JobType:      p.JobType,
+		TaskUUID:     p.TaskUUID,
+		Attempt:      p.Attempt,
+		StartedAt:    p.StartedAt,
+		FinishedAt:   p.FinishedAt,
+		Outcome:      vo.AssignmentOutcome(p.Outcome),
+		SlotWeight:   p.SlotWeight,
+		SlotCapacity: p.SlotCapacity,
+		Wait:         time.Duration(p.WaitMs) * time.Millisecond,
+	}
+}
+
+func (c *TaskAssignmentConvertor) ToPO(a *vo.TaskAssignment) *po.TaskAssignment {
+	return &po.TaskAssignment{
+		WorkerID:     a.WorkerID,
+		JobType:      a.JobType,
+		TaskUUID:     a.TaskUUID,
+		Attempt:      a.Attempt,
+		StartedAt:    a.StartedAt,
+		FinishedAt:   a.FinishedAt,
+		Outcome:      string(a.Outcome),
+		SlotWeight:   a.SlotWeight,
+		SlotCapacity: a.SlotCapacity,
+		WaitMs:       a.Wait.Milliseconds(),
+	}
+}
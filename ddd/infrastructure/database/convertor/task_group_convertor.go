@@ -0,0 +1,36 @@
+package convertor
+
+import (
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/po"
+)
+
+type TaskGroupConvertor struct{}
+
+func NewTaskGroupConvertor() *TaskGroupConvertor { return &TaskGroupConvertor{} }
+
+func (c *TaskGroupConvertor) ToEntity(p *po.TaskGroup) *entity.TaskGroupEntity {
+	if p == nil {
+		return nil
+	}
+	return entity.RestoreTaskGroup(p.GroupUUID, p.UserUUID, p.Name, p.CallbackURL, p.ExpectedTasks, vo.TaskGroupStatus(p.Status),
+		vo.GroupCallbackState(p.CallbackState), p.CallbackAttempts, p.CallbackError, p.CompletedAt, p.BatchUntil, p.CreatedAt, p.UpdatedAt)
+}
+
+func (c *TaskGroupConvertor) ToPO(g *entity.TaskGroupEntity) *po.TaskGroup {
+	return &po.TaskGroup{
+		BaseModel:        po.BaseModel{CreatedAt: g.CreatedAt(), UpdatedAt: g.UpdatedAt()},
+		GroupUUID:        g.GroupUUID(),
+		UserUUID:         g.UserUUID(),
+		Name:             g.Name(),
+		ExpectedTasks:    g.ExpectedTasks(),
+		CallbackURL:      g.CallbackURL(),
+		Status:           string(g.Status()),
+		CallbackState:    string(g.CallbackState()),
+		CallbackAttempts: g.CallbackAttempts(),
+		CallbackError:    g.CallbackError(),
+		CompletedAt:      g.CompletedAt(),
+		BatchUntil:       g.BatchUntil(),
+	}
+}
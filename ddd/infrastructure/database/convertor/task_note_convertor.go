@@ -0,0 +1,35 @@
+package convertor
+
+import (
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/po"
+)
+
+type TaskNoteConvertor struct{}
+
+func NewTaskNoteConvertor() *TaskNoteConvertor { return &TaskNoteConvertor{} }
+
+func (c *TaskNoteConvertor) ToVO(p *po.TaskNote) *vo.TaskNote {
+	if p == nil {
+		return nil
+	}
+	return &vo.TaskNote{
+		NoteUUID:  p.NoteUUID,
+		TaskUUID:  p.TaskUUID,
+		Author:    p.Author,
+		Body:      string(p.Body),
+		CreatedAt: p.CreatedAt,
+	}
+}
+
+func (c *TaskNoteConvertor) ToPO(n *vo.TaskNote) *po.TaskNote {
+	p := &po.TaskNote{
+		NoteUUID: n.NoteUUID,
+		TaskUUID: n.TaskUUID,
+		Author:   n.Author,
+		Body:     po.EncryptedString(n.Body),
+	}
+	p.CreatedAt = n.CreatedAt
+	p.UpdatedAt = n.CreatedAt
+	return p
+}
@@ -0,0 +1,30 @@
+package convertor
+
+import (
+	"time"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/po"
+)
+
+type ThroughputConvertor struct{}
+
+func NewThroughputConvertor() *ThroughputConvertor { return &ThroughputConvertor{} }
+
+func (c *ThroughputConvertor) ToVO(p *po.ThroughputDaily) vo.DailyThroughput {
+	return vo.DailyThroughput{
+		Date:        p.StatDate,
+		InputBytes:  p.InputBytes,
+		OutputBytes: p.OutputBytes,
+		Encode:      time.Duration(p.EncodeMs) * time.Millisecond,
+	}
+}
+
+func (c *ThroughputConvertor) ToPO(d vo.DailyThroughput) *po.ThroughputDaily {
+	return &po.ThroughputDaily{
+		StatDate:    d.Date,
+		InputBytes:  d.InputBytes,
+		OutputBytes: d.OutputBytes,
+		EncodeMs:    d.Encode.Milliseconds(),
+	}
+}
@@ -22,6 +22,21 @@ func (c *TranscodeTaskConvertor) ToEntity(job *po.TranscodeJob) *entity.Transcod
 	} else {
 		params = vo.TranscodeParams{Resolution: job.Resolution, Bitrate: job.Bitrate}
 	}
+	if c, err := vo.ParseContainer(job.Container); err == nil {
+		params.Container = c
+	}
+	params.PreviewSeconds = job.PreviewSeconds
+	params.FormatSet, params.MaxRenditions = job.FormatSet, job.MaxRenditions
+	params.VideoStream, params.AudioStream = job.VideoStreamIndex, job.AudioStreamIndex
+	if job.AutoProfile != nil {
+		params.Profile = vo.AutoProfileFromJSON(*job.AutoProfile)
+	}
+	if job.GOP != nil {
+		params.GOP = vo.GOPPolicyFromJSON(*job.GOP)
+	}
+	if job.AudioOutputs != nil {
+		params.Audio = vo.AudioOutputsFromJSON(*job.AudioOutputs)
+	}
 	status, err := vo.NewTaskStatusFromString(job.Status)
 	if err != nil {
 		status = vo.TaskStatusPending
@@ -41,23 +56,165 @@ func (c *TranscodeTaskConvertor) ToEntity(job *po.TranscodeJob) *entity.Transcod
 		job.UpdatedAt,
 	)
 	e.SetVideoPushUUID(job.VideoPushUUID)
+	if job.Priority > 0 {
+		e.SetPriority(job.Priority)
+	}
+	e.SetRetryState(job.RetryCount, job.NextRetryAt)
+	if job.StageProgress != nil {
+		e.SetStages(vo.StageProgressFromJSON(*job.StageProgress))
+	}
+	if job.Timings != nil {
+		e.SetTimings(vo.TaskTimingsFromJSON(*job.Timings))
+	}
+	if job.Commands != nil {
+		e.SetCommands(vo.FFmpegCommandsFromJSON(*job.Commands))
+	}
+	if job.Labels != nil {
+		e.SetLabels(vo.TaskLabelsFromJSON(string(*job.Labels)))
+	}
+	if job.BitrateCap != nil {
+		e.SetBitrateAdjustment(vo.BitrateAdjustmentFromJSON(*job.BitrateCap))
+	}
+	if job.Fingerprint != nil {
+		e.SetFingerprint(vo.EncoderFingerprintFromJSON(*job.Fingerprint))
+	}
+	if job.SourceStreams != nil {
+		e.SetSourceStreams(vo.SourceStreamsFromJSON(*job.SourceStreams))
+	}
+	if job.ResourceTrace != nil {
+		e.SetResourceTrace(vo.ResourceTraceFromJSON(*job.ResourceTrace))
+	}
+	var storage vo.OutputStorage
+	if job.OutputStorage != nil {
+		storage = vo.OutputStorageFromJSON(*job.OutputStorage)
+	}
+	storage.Tier, storage.LastAccessedAt = vo.NewStorageTier(job.StorageTier), job.LastAccessedAt
+	e.SetOutputStorage(storage)
+	e.SetSourceGeneration(job.SourceGeneration)
+	if job.ParentTaskUUID != nil {
+		e.SetParentTaskUUID(*job.ParentTaskUUID)
+	}
+	if job.IdempotencyKey != nil {
+		e.SetIdempotencyKey(*job.IdempotencyKey)
+	}
+	e.SetPrivateToken(job.PrivateToken)
+	e.SetOutputDestination(vo.OutputDestination{Bucket: job.OutputBucket, Prefix: job.OutputPrefix})
+	e.SetLane(vo.ParseTaskLane(job.Lane))
+	e.SetGroupUUID(job.GroupUUID)
 	return e
 }
 
 func (c *TranscodeTaskConvertor) ToPO(entity *entity.TranscodeTaskEntity) *po.TranscodeJob {
+	var stages *string
+	if sp := entity.StageProgress(); len(sp) > 0 {
+		if data, err := sp.ToJSON(); err == nil {
+			stages = &data
+		}
+	}
+	var timings *string
+	tt := entity.Timings()
+	if !tt.IsZero() {
+		if data, err := tt.ToJSON(); err == nil {
+			timings = &data
+		}
+	}
+	var commands *string
+	if cs := entity.Commands(); len(cs) > 0 {
+		if data, err := cs.ToJSON(); err == nil {
+			commands = &data
+		}
+	}
+	var labels *string
+	if l := entity.Labels(); len(l) > 0 {
+		if data, err := l.ToJSON(); err == nil {
+			labels = &data
+		}
+	}
+	var profile *string
+	if p := entity.GetParams().Profile; p != nil {
+		if data, err := p.ToJSON(); err == nil {
+			profile = &data
+		}
+	}
+	var audio *string
+	if a := entity.GetParams().Audio; a != nil {
+		if data, err := a.ToJSON(); err == nil {
+			audio = &data
+		}
+	}
+	var gop *string
+	if g := entity.GetParams().GOP; g != nil {
+		if data, err := g.ToJSON(); err == nil {
+			gop = &data
+		}
+	}
+	var bitrateCap *string
+	if adj := entity.BitrateAdjustment(); adj != nil {
+		if data, err := adj.ToJSON(); err == nil {
+			bitrateCap = &data
+		}
+	}
+	fingerprint, settingsVersion, fingerprintHash := fingerprintColumns(entity.Fingerprint())
+	var sourceStreams *string
+	if s := entity.SourceStreams(); s != nil {
+		if data, err := s.ToJSON(); err == nil {
+			sourceStreams = &data
+		}
+	}
+	var parent *string
+	if entity.IsReplay() {
+		uuid := entity.ParentTaskUUID()
+		parent = &uuid
+	}
+	// 未携带幂等键时存 NULL，唯一索引不约束
+	var idemKey *string
+	if key := entity.IdempotencyKey(); key != "" {
+		idemKey = &key
+	}
 	return &po.TranscodeJob{
-		BaseModel:     po.BaseModel{Id: entity.ID(), CreatedAt: entity.CreatedAt(), UpdatedAt: entity.UpdatedAt()},
-		JobUUID:       entity.TaskUUID(),
-		UserUUID:      entity.UserUUID(),
-		VideoUUID:     entity.VideoUUID(),
-		VideoPushUUID: entity.VideoPushUUID(),
-		InputPath:     entity.InputPath(),
-		OutputPath:    entity.OutputPath(),
-		Resolution:    entity.GetParams().Resolution,
-		Bitrate:       entity.GetParams().Bitrate,
-		Status:        entity.Status().String(),
-		Message:       entity.ErrorMessage(),
-		Progress:      entity.Progress(),
+		BaseModel:        po.BaseModel{Id: entity.ID(), CreatedAt: entity.CreatedAt(), UpdatedAt: entity.UpdatedAt()},
+		JobUUID:          entity.TaskUUID(),
+		UserUUID:         entity.UserUUID(),
+		VideoUUID:        entity.VideoUUID(),
+		VideoPushUUID:    entity.VideoPushUUID(),
+		InputPath:        entity.InputPath(),
+		OutputPath:       entity.OutputPath(),
+		Resolution:       entity.GetParams().Resolution,
+		Bitrate:          entity.GetParams().Bitrate,
+		Container:        entity.GetParams().OutputContainer().String(),
+		PreviewSeconds:   entity.GetParams().PreviewSeconds,
+		VideoStreamIndex: entity.GetParams().VideoStream,
+		AudioStreamIndex: entity.GetParams().AudioStream,
+		Status:           entity.Status().String(),
+		Message:          entity.ErrorMessage(),
+		Progress:         entity.Progress(),
+		Priority:         entity.Priority(),
+		RetryCount:       entity.RetryCount(),
+		NextRetryAt:      entity.NextRetryAt(),
+		StageProgress:    stages,
+		Timings:          timings,
+		StartedAt:        tt.StartedAt,
+		CompletedAt:      tt.FinishedAt,
+		Commands:         commands,
+		Labels:           po.NewEncryptedString(labels),
+		SourceGeneration: entity.SourceGeneration(),
+		AutoProfile:      profile,
+		AudioOutputs:     audio,
+		GOP:              gop,
+		BitrateCap:       bitrateCap,
+		Fingerprint:      fingerprint,
+		SettingsVersion:  settingsVersion,
+		FingerprintHash:  fingerprintHash,
+		SourceStreams:    sourceStreams,
+		FormatSet:        entity.GetParams().FormatSet,
+		MaxRenditions:    entity.GetParams().MaxRenditions,
+		ParentTaskUUID:   parent,
+		IdempotencyKey:   idemKey,
+		PrivateToken:     entity.PrivateToken(),
+		OutputBucket:     entity.OutputDestination().Bucket,
+		OutputPrefix:     entity.OutputDestination().Prefix,
+		Lane:             string(entity.Lane()),
+		GroupUUID:        entity.GroupUUID(),
 	}
 }
 
@@ -0,0 +1,55 @@
+package convertor
+
+import (
+	"encoding/json"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/po"
+)
+
+type UserPreferenceConvertor struct{}
+
+func NewUserPreferenceConvertor() *UserPreferenceConvertor { return &UserPreferenceConvertor{} }
+
+func (c *UserPreferenceConvertor) ToEntity(p *po.UserPreference) *entity.UserPreferenceEntity {
+	if p == nil {
+		return nil
+	}
+	var ladder []vo.ResolutionConfig
+	if p.LadderJSON != nil {
+		_ = json.Unmarshal([]byte(*p.LadderJSON), &ladder)
+	}
+	var watermark *vo.Watermark
+	if p.WatermarkJSON != nil {
+		var wm vo.Watermark
+		if json.Unmarshal([]byte(*p.WatermarkJSON), &wm) == nil && wm.ObjectKey != "" {
+			watermark = &wm
+		}
+	}
+	return entity.RestoreUserPreference(p.Id, p.UserUUID, ladder, watermark, p.SubtitleLanguage, p.CreatedAt, p.UpdatedAt)
+}
+
+func (c *UserPreferenceConvertor) ToPO(e *entity.UserPreferenceEntity) *po.UserPreference {
+	if e == nil {
+		return nil
+	}
+	p := &po.UserPreference{
+		BaseModel:        po.BaseModel{Id: e.ID(), CreatedAt: e.CreatedAt(), UpdatedAt: e.UpdatedAt()},
+		UserUUID:         e.UserUUID(),
+		SubtitleLanguage: e.SubtitleLanguage(),
+	}
+	if len(e.Ladder()) > 0 {
+		if data, err := json.Marshal(e.Ladder()); err == nil {
+			s := string(data)
+			p.LadderJSON = &s
+		}
+	}
+	if e.Watermark() != nil {
+		if data, err := json.Marshal(e.Watermark()); err == nil {
+			s := string(data)
+			p.WatermarkJSON = &s
+		}
+	}
+	return p
+}
@@ -0,0 +1,40 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"transcode-service/ddd/infrastructure/database/po"
+	"transcode-service/internal/resource"
+)
+
+type AnalyticsCursorDAO struct{ db *gorm.DB }
+
+func NewAnalyticsCursorDAO() *AnalyticsCursorDAO {
+	return &AnalyticsCursorDAO{db: resource.DefaultMysqlResource().MainDB()}
+}
+
+// Get 读取游标，不存在时以 initial 创建
+func (d *AnalyticsCursorDAO) Get(ctx context.Context, name string, initial time.Time) (*po.AnalyticsCursor, error) {
+	cur := &po.AnalyticsCursor{}
+	err := d.db.WithContext(ctx).
+		Where("name = ?", name).
+		Attrs(po.AnalyticsCursor{CursorAt: initial}).
+		FirstOrCreate(cur).Error
+	if err != nil {
+		return nil, err
+	}
+	return cur, nil
+}
+
+// Advance 仅当游标仍为 from 时推进到 (toAt, toID)，返回是否由本次调用推进（多实例互斥）
+func (d *AnalyticsCursorDAO) Advance(ctx context.Context, name string, from *po.AnalyticsCursor, toAt time.Time, toID uint64) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&po.AnalyticsCursor{}).
+		Where("name = ? AND cursor_at = ? AND cursor_id = ?", name, from.CursorAt, from.CursorID).
+		Updates(map[string]interface{}{"cursor_at": toAt, "cursor_id": toID})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
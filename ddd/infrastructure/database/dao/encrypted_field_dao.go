@@ -0,0 +1,61 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"transcode-service/internal/resource"
+)
+
+// EncryptedColumn 以 po.EncryptedString 存储的列
+type EncryptedColumn struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+}
+
+// EncryptedColumns 全部加密列，新增加密列时同步登记，供密钥轮换遍历
+var EncryptedColumns = []EncryptedColumn{
+	{Table: "transcode_jobs", Column: "labels"},
+	{Table: "transcode_jobs", Column: "metadata"},
+	{Table: "task_notes", Column: "body"},
+}
+
+// EncryptedValue 加密列的原始取值（密文或历史明文），不经 EncryptedString 解密
+type EncryptedValue struct {
+	Id    uint64
+	Value string
+}
+
+type EncryptedFieldDAO struct{ db *gorm.DB }
+
+func NewEncryptedFieldDAO() *EncryptedFieldDAO {
+	return &EncryptedFieldDAO{db: resource.DefaultMysqlResource().MainDB()}
+}
+
+// ScanBatch 按 id 升序读取 id > afterID 的非空原始取值
+func (d *EncryptedFieldDAO) ScanBatch(ctx context.Context, col EncryptedColumn, afterID uint64, limit int) ([]EncryptedValue, error) {
+	var rows []EncryptedValue
+	err := d.db.WithContext(ctx).
+		Table(col.Table).
+		Select("id, "+col.Column+" AS value").
+		Where(col.Column+" IS NOT NULL AND "+col.Column+" <> '' AND id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Replace 取值未被并发修改时写回新值，不更新 updated_at；返回是否写入
+func (d *EncryptedFieldDAO) Replace(ctx context.Context, col EncryptedColumn, id uint64, old, value string) (bool, error) {
+	res := d.db.WithContext(ctx).
+		Table(col.Table).
+		Where("id = ? AND "+col.Column+" = ?", id, old).
+		UpdateColumn(col.Column, value)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
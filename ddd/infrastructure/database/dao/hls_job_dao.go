@@ -2,6 +2,9 @@ package dao
 
 import (
 	"context"
+	"strings"
+	"time"
+
 	"gorm.io/gorm"
 	"transcode-service/ddd/infrastructure/database/po"
 	"transcode-service/internal/resource"
@@ -15,6 +18,15 @@ func (d *HLSJobDAO) Create(ctx context.Context, job *po.HLSJob) error {
 	return d.db.WithContext(ctx).Model(&po.HLSJob{}).Create(job).Error
 }
 
+// CreateIfAbsent 按 source_job_uuid 唯一索引创建作业，已存在时返回 false，由调用方读取已有作业
+func (d *HLSJobDAO) CreateIfAbsent(ctx context.Context, job *po.HLSJob) (bool, error) {
+	err := d.db.WithContext(ctx).Model(&po.HLSJob{}).Create(job).Error
+	if err != nil && strings.Contains(err.Error(), "Duplicate entry") {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 func (d *HLSJobDAO) UpdateProgress(ctx context.Context, jobUUID string, progress int) error {
 	return d.db.WithContext(ctx).Model(&po.HLSJob{}).Where("job_uuid = ?", jobUUID).Update("progress", progress).Error
 }
@@ -39,6 +51,29 @@ func (d *HLSJobDAO) FindByJobUUID(ctx context.Context, jobUUID string) (*po.HLSJ
 	return &job, nil
 }
 
+func (d *HLSJobDAO) FindLatestBySource(ctx context.Context, sourceJobUUID string) (*po.HLSJob, error) {
+	var jobs []*po.HLSJob
+	if err := d.db.WithContext(ctx).Where("source_job_uuid = ?", sourceJobUUID).Order("created_at DESC").Limit(1).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return jobs[0], nil
+}
+
+// FindBySources 查询多个来源任务的全部 HLS 作业，按创建时间升序
+func (d *HLSJobDAO) FindBySources(ctx context.Context, sourceJobUUIDs []string) ([]*po.HLSJob, error) {
+	var jobs []*po.HLSJob
+	if len(sourceJobUUIDs) == 0 {
+		return jobs, nil
+	}
+	if err := d.db.WithContext(ctx).Where("source_job_uuid IN ?", sourceJobUUIDs).Order("created_at ASC").Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
 func (d *HLSJobDAO) QueryByStatus(ctx context.Context, status string, limit int) ([]*po.HLSJob, error) {
 	var jobs []*po.HLSJob
 	q := d.db.WithContext(ctx).Where("status = ?", status).Order("updated_at ASC")
@@ -50,3 +85,107 @@ func (d *HLSJobDAO) QueryByStatus(ctx context.Context, status string, limit int)
 	}
 	return jobs, nil
 }
+
+func (d *HLSJobDAO) QueryByVideoUUID(ctx context.Context, videoUUID string) ([]*po.HLSJob, error) {
+	var jobs []*po.HLSJob
+	if err := d.db.WithContext(ctx).Where("video_uuid = ?", videoUUID).Order("created_at ASC").Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Claim 原子地将 pending 作业切换为 claimed，返回是否认领成功
+func (d *HLSJobDAO) Claim(ctx context.Context, jobUUID, workerID string) (bool, error) {
+	now := time.Now()
+	res := d.db.WithContext(ctx).Model(&po.HLSJob{}).
+		Where("job_uuid = ? AND status = ?", jobUUID, "pending").
+		Updates(map[string]interface{}{"status": "claimed", "worker_id": workerID, "claimed_at": now})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// ReleaseStaleClaims 将认领后长时间未开始处理的作业，以及 updated_at 早于 updatedBefore 的 processing 作业
+// （处理实例已崩溃，不再续期）退回 pending；processing 作业的本地产物已丢失，码流状态清空后按配置重建
+func (d *HLSJobDAO) ReleaseStaleClaims(ctx context.Context, claimedBefore, updatedBefore time.Time) (int64, error) {
+	res := d.db.WithContext(ctx).Model(&po.HLSJob{}).
+		Where("status = ? AND claimed_at < ?", "claimed", claimedBefore).
+		Updates(map[string]interface{}{"status": "pending", "worker_id": nil, "claimed_at": nil})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	released := res.RowsAffected
+	res = d.db.WithContext(ctx).Model(&po.HLSJob{}).
+		Where("status = ? AND updated_at < ?", "processing", updatedBefore).
+		Updates(map[string]interface{}{"status": "pending", "progress": 0, "worker_id": nil, "claimed_at": nil, "renditions": nil})
+	return released + res.RowsAffected, res.Error
+}
+
+// Touch 刷新 processing 作业的 updated_at，表明处理实例仍存活
+func (d *HLSJobDAO) Touch(ctx context.Context, jobUUID string) error {
+	return d.db.WithContext(ctx).Model(&po.HLSJob{}).
+		Where("job_uuid = ? AND status = ?", jobUUID, "processing").
+		Update("updated_at", time.Now()).Error
+}
+
+// QueryFinishedAfter 按 (updated_at, id) 增量查询已结束的作业，before 之后更新的暂不返回
+func (d *HLSJobDAO) QueryFinishedAfter(ctx context.Context, afterAt time.Time, afterID uint64, before time.Time, limit int) ([]*po.HLSJob, error) {
+	var jobs []*po.HLSJob
+	err := d.db.WithContext(ctx).
+		Where("status IN ?", []string{"completed", "failed"}).
+		Where("(updated_at > ? OR (updated_at = ? AND id > ?)) AND updated_at < ?", afterAt, afterAt, afterID, before).
+		Order("updated_at ASC, id ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// UpdateRenditions 更新各路码流状态（JSON）
+func (d *HLSJobDAO) UpdateRenditions(ctx context.Context, jobUUID, renditions string) error {
+	return d.db.WithContext(ctx).Model(&po.HLSJob{}).Where("job_uuid = ?", jobUUID).Update("renditions", renditions).Error
+}
+
+// ResetForRetry 失败作业重新置为 pending 并写入重置后的码流状态，作业已不是 failed 时返回 false
+func (d *HLSJobDAO) ResetForRetry(ctx context.Context, jobUUID, renditions string) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&po.HLSJob{}).
+		Where("job_uuid = ? AND status = ?", jobUUID, "failed").
+		Updates(map[string]interface{}{"status": "pending", "error_message": nil, "progress": 0, "worker_id": nil, "claimed_at": nil, "renditions": renditions})
+	return res.RowsAffected > 0, res.Error
+}
+
+// ReleaseInterrupted 停机中断的 processing 作业置回 pending 并写入重置后的码流状态
+func (d *HLSJobDAO) ReleaseInterrupted(ctx context.Context, jobUUID, renditions string) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&po.HLSJob{}).
+		Where("job_uuid = ? AND status = ?", jobUUID, "processing").
+		Updates(map[string]interface{}{"status": "pending", "progress": 0, "worker_id": nil, "claimed_at": nil, "renditions": renditions})
+	return res.RowsAffected > 0, res.Error
+}
+
+// UpdateFingerprint 更新编码设置指纹（JSON）及其版本、哈希列
+func (d *HLSJobDAO) UpdateFingerprint(ctx context.Context, jobUUID, fingerprint string, settingsVersion int, hash string) error {
+	return d.db.WithContext(ctx).Model(&po.HLSJob{}).Where("job_uuid = ?", jobUUID).
+		Updates(map[string]interface{}{"encoder_fingerprint": fingerprint, "settings_version": settingsVersion, "fingerprint_hash": hash}).Error
+}
+
+// QueryStale 按 id 升序查询设置版本低于 beforeVersion 或指纹哈希不等于 excludeHash 的已完成作业
+func (d *HLSJobDAO) QueryStale(ctx context.Context, beforeVersion int, excludeHash string, afterID uint64, limit int) ([]*po.HLSJob, error) {
+	var jobs []*po.HLSJob
+	err := staleOutputScope(d.db.WithContext(ctx), beforeVersion, excludeHash).
+		Where("status = ? AND id > ?", "completed", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// UpdateCommands 更新已执行的 ffmpeg 命令（JSON）
+func (d *HLSJobDAO) UpdateCommands(ctx context.Context, jobUUID, commands string) error {
+	return d.db.WithContext(ctx).Model(&po.HLSJob{}).Where("job_uuid = ?", jobUUID).Update("ffmpeg_commands", commands).Error
+}
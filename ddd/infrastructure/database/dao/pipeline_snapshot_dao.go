@@ -0,0 +1,50 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"transcode-service/ddd/infrastructure/database/po"
+	"transcode-service/internal/resource"
+)
+
+type PipelineSnapshotDAO struct{ db *gorm.DB }
+
+func NewPipelineSnapshotDAO() *PipelineSnapshotDAO {
+	return &PipelineSnapshotDAO{db: resource.DefaultMysqlResource().MainDB()}
+}
+
+// Upsert 按 instance_id 新建或覆盖快照
+func (d *PipelineSnapshotDAO) Upsert(ctx context.Context, snap *po.PipelineSnapshot) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&po.PipelineSnapshot{}).Where("instance_id = ?", snap.InstanceID).Updates(map[string]interface{}{
+			"claims_json": snap.ClaimsJSON,
+			"released":    snap.Released,
+			"taken_at":    snap.TakenAt,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			return nil
+		}
+		return tx.Create(snap).Error
+	})
+}
+
+func (d *PipelineSnapshotDAO) List(ctx context.Context) ([]*po.PipelineSnapshot, error) {
+	var snaps []*po.PipelineSnapshot
+	if err := d.db.WithContext(ctx).Order("taken_at ASC").Find(&snaps).Error; err != nil {
+		return nil, err
+	}
+	return snaps, nil
+}
+
+// DeleteByInstanceID 删除快照，返回是否由本次调用删除（用于多实例对账互斥）
+func (d *PipelineSnapshotDAO) DeleteByInstanceID(ctx context.Context, instanceID string) (bool, error) {
+	res := d.db.WithContext(ctx).Where("instance_id = ?", instanceID).Delete(&po.PipelineSnapshot{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
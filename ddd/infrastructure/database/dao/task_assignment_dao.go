@@ -0,0 +1,39 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"transcode-service/ddd/infrastructure/database/po"
+	"transcode-service/internal/resource"
+)
+
+type TaskAssignmentDAO struct{ db *gorm.DB }
+
+func NewTaskAssignmentDAO() *TaskAssignmentDAO {
+	return &TaskAssignmentDAO{db: resource.DefaultMysqlResource().MainDB()}
+}
+
+func (d *TaskAssignmentDAO) Create(ctx context.Context, a *po.TaskAssignment) error {
+	return d.db.WithContext(ctx).Create(a).Error
+}
+
+// ListOverlapping 查询执行区间与 [from, to) 有重叠的记录，workerID 为空时查询全部实例
+func (d *TaskAssignmentDAO) ListOverlapping(ctx context.Context, workerID string, from, to time.Time, limit int) ([]*po.TaskAssignment, error) {
+	q := d.db.WithContext(ctx).Where("started_at < ? AND finished_at >= ?", to, from)
+	if workerID != "" {
+		q = q.Where("worker_id = ?", workerID)
+	}
+	var rows []*po.TaskAssignment
+	if err := q.Order("started_at ASC").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// DeleteBefore 删除 before 之前结束的记录，返回删除行数
+func (d *TaskAssignmentDAO) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	res := d.db.WithContext(ctx).Where("finished_at < ?", before).Delete(&po.TaskAssignment{})
+	return res.RowsAffected, res.Error
+}
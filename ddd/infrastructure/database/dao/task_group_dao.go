@@ -0,0 +1,115 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"transcode-service/ddd/infrastructure/database/po"
+	"transcode-service/internal/resource"
+)
+
+type TaskGroupDAO struct{ db *gorm.DB }
+
+func NewTaskGroupDAO() *TaskGroupDAO {
+	return &TaskGroupDAO{db: resource.DefaultMysqlResource().MainDB()}
+}
+
+func (d *TaskGroupDAO) Create(ctx context.Context, g *po.TaskGroup) error {
+	return d.db.WithContext(ctx).Create(g).Error
+}
+
+// FindByGroupUUID 不存在时返回 nil
+func (d *TaskGroupDAO) FindByGroupUUID(ctx context.Context, groupUUID string) (*po.TaskGroup, error) {
+	var g po.TaskGroup
+	err := d.db.WithContext(ctx).Where("group_uuid = ? AND is_deleted = 0", groupUUID).First(&g).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// AttachJobs 只更新同一用户且未加入任务组的作业
+func (d *TaskGroupDAO) AttachJobs(ctx context.Context, groupUUID, userUUID string, jobUUIDs []string) (int64, error) {
+	res := d.db.WithContext(ctx).Model(&po.TranscodeJob{}).
+		Where("job_uuid IN ? AND user_uuid = ? AND group_uuid = ''", jobUUIDs, userUUID).
+		Update("group_uuid", groupUUID)
+	return res.RowsAffected, res.Error
+}
+
+// GroupStatusCount 组内某一状态的作业数与进度之和
+type GroupStatusCount struct {
+	Status      string
+	Count       int
+	ProgressSum int
+}
+
+func (d *TaskGroupDAO) CountJobsByStatus(ctx context.Context, groupUUID string) ([]GroupStatusCount, error) {
+	var rows []GroupStatusCount
+	err := d.db.WithContext(ctx).Model(&po.TranscodeJob{}).
+		Select("status, COUNT(*) AS count, COALESCE(SUM(progress), 0) AS progress_sum").
+		Where("group_uuid = ?", groupUUID).
+		Group("status").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (d *TaskGroupDAO) QueryJobs(ctx context.Context, groupUUID string, limit int) ([]*po.TranscodeJob, error) {
+	var jobs []*po.TranscodeJob
+	err := d.db.WithContext(ctx).Where("group_uuid = ?", groupUUID).Order("created_at ASC, id ASC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+func (d *TaskGroupDAO) QueryByStatus(ctx context.Context, status string, limit int) ([]*po.TaskGroup, error) {
+	var rows []*po.TaskGroup
+	err := d.db.WithContext(ctx).Where("status = ? AND is_deleted = 0", status).Order("id ASC").Limit(limit).Find(&rows).Error
+	return rows, err
+}
+
+// QueryCallbacksDue 回调待投递（pending）或认领后 updated_at 早于 staleBefore 仍未写回结果（delivering）的任务组
+func (d *TaskGroupDAO) QueryCallbacksDue(ctx context.Context, staleBefore time.Time, limit int) ([]*po.TaskGroup, error) {
+	var rows []*po.TaskGroup
+	err := d.db.WithContext(ctx).
+		Where("(callback_state = ? OR (callback_state = ? AND updated_at < ?)) AND is_deleted = 0", "pending", "delivering", staleBefore).
+		Order("completed_at ASC, id ASC").Limit(limit).Find(&rows).Error
+	return rows, err
+}
+
+// ClaimCallback 条件更新 pending（或认领已过期的 delivering）-> delivering，多副本下只有一个实例成功
+func (d *TaskGroupDAO) ClaimCallback(ctx context.Context, groupUUID string, staleBefore time.Time) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&po.TaskGroup{}).
+		Where("group_uuid = ? AND (callback_state = ? OR (callback_state = ? AND updated_at < ?))", groupUUID, "pending", "delivering", staleBefore).
+		Update("callback_state", "delivering")
+	return res.RowsAffected > 0, res.Error
+}
+
+// HoldBatch 批量创建前把完成保护期延长到 until（只延长不缩短）
+func (d *TaskGroupDAO) HoldBatch(ctx context.Context, groupUUID string, until time.Time) error {
+	return d.db.WithContext(ctx).Model(&po.TaskGroup{}).
+		Where("group_uuid = ? AND status = ? AND (batch_until IS NULL OR batch_until < ?)", groupUUID, "open", until).
+		Update("batch_until", until).Error
+}
+
+// ReleaseBatch 批量创建结束后清除保护期；已被并发批次延长时保留，由该批次清除或自然过期
+func (d *TaskGroupDAO) ReleaseBatch(ctx context.Context, groupUUID string, until time.Time) error {
+	return d.db.WithContext(ctx).Model(&po.TaskGroup{}).
+		Where("group_uuid = ? AND batch_until = ?", groupUUID, until).
+		Update("batch_until", nil).Error
+}
+
+// Complete 条件更新 open -> completed，批量创建保护期内不更新
+func (d *TaskGroupDAO) Complete(ctx context.Context, groupUUID, callbackState string, completedAt time.Time) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&po.TaskGroup{}).
+		Where("group_uuid = ? AND status = ? AND (batch_until IS NULL OR batch_until <= ?)", groupUUID, "open", completedAt).
+		Updates(map[string]interface{}{"status": "completed", "callback_state": callbackState, "completed_at": completedAt})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (d *TaskGroupDAO) UpdateCallbackState(ctx context.Context, groupUUID, state string, attempts int, lastError string) error {
+	return d.db.WithContext(ctx).Model(&po.TaskGroup{}).Where("group_uuid = ?", groupUUID).
+		Updates(map[string]interface{}{"callback_state": state, "callback_attempts": attempts, "callback_error": lastError}).Error
+}
@@ -0,0 +1,33 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"transcode-service/ddd/infrastructure/database/po"
+	"transcode-service/internal/resource"
+)
+
+type TaskNoteDAO struct{ db *gorm.DB }
+
+func NewTaskNoteDAO() *TaskNoteDAO {
+	return &TaskNoteDAO{db: resource.DefaultMysqlResource().MainDB()}
+}
+
+func (d *TaskNoteDAO) Create(ctx context.Context, n *po.TaskNote) error {
+	return d.db.WithContext(ctx).Create(n).Error
+}
+
+// ListByTask 按备注时间升序返回任务的备注
+func (d *TaskNoteDAO) ListByTask(ctx context.Context, taskUUID string, limit int) ([]*po.TaskNote, error) {
+	var rows []*po.TaskNote
+	err := d.db.WithContext(ctx).
+		Where("task_uuid = ? AND is_deleted = 0", taskUUID).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,47 @@
+package dao
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+	"transcode-service/ddd/infrastructure/database/po"
+	"transcode-service/internal/resource"
+)
+
+type ThroughputDAO struct{ db *gorm.DB }
+
+func NewThroughputDAO() *ThroughputDAO {
+	return &ThroughputDAO{db: resource.DefaultMysqlResource().MainDB()}
+}
+
+// Increment 累加到 stat_date 对应的行，不存在时新建；并发新建冲突时改为累加
+func (d *ThroughputDAO) Increment(ctx context.Context, delta *po.ThroughputDaily) error {
+	ok, err := d.add(ctx, delta)
+	if err != nil || ok {
+		return err
+	}
+	err = d.db.WithContext(ctx).Create(delta).Error
+	if err == nil || !strings.Contains(err.Error(), "Duplicate entry") {
+		return err
+	}
+	_, err = d.add(ctx, delta)
+	return err
+}
+
+func (d *ThroughputDAO) add(ctx context.Context, delta *po.ThroughputDaily) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&po.ThroughputDaily{}).
+		Where("stat_date = ?", delta.StatDate).
+		Updates(map[string]interface{}{
+			"input_bytes":  gorm.Expr("input_bytes + ?", delta.InputBytes),
+			"output_bytes": gorm.Expr("output_bytes + ?", delta.OutputBytes),
+			"encode_ms":    gorm.Expr("encode_ms + ?", delta.EncodeMs),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (d *ThroughputDAO) ListRange(ctx context.Context, from, to string) ([]*po.ThroughputDaily, error) {
+	var rows []*po.ThroughputDaily
+	err := d.db.WithContext(ctx).Where("stat_date BETWEEN ? AND ? AND is_deleted = 0", from, to).Order("stat_date ASC").Find(&rows).Error
+	return rows, err
+}
@@ -3,6 +3,7 @@ package dao
 import (
 	"context"
 	"log"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -26,6 +27,11 @@ func (d *TranscodeJobDAO) UpdateProgress(ctx context.Context, jobUUID string, pr
 	return d.db.WithContext(ctx).Model(&po.TranscodeJob{}).Where("job_uuid = ?", jobUUID).Update("progress", progress).Error
 }
 
+func (d *TranscodeJobDAO) UpdateStageProgress(ctx context.Context, jobUUID string, progress int, stages, timings string) error {
+	update := map[string]interface{}{"progress": progress, "stage_progress": stages, "timings": timings}
+	return d.db.WithContext(ctx).Model(&po.TranscodeJob{}).Where("job_uuid = ?", jobUUID).Updates(update).Error
+}
+
 func (d *TranscodeJobDAO) UpdateJob(ctx context.Context, job *po.TranscodeJob) error {
 	return d.db.WithContext(ctx).Model(&po.TranscodeJob{}).Where("job_uuid = ?", job.JobUUID).Updates(job).Error
 }
@@ -38,8 +44,20 @@ func (d *TranscodeJobDAO) FindByJobUUID(ctx context.Context, jobUUID string) (*p
 	return &job, nil
 }
 
-func (d *TranscodeJobDAO) UpdateStatus(ctx context.Context, jobUUID, status, message, outputPath string, progress int) error {
+func (d *TranscodeJobDAO) FindByIdempotencyKey(ctx context.Context, key string) (*po.TranscodeJob, error) {
+	var job po.TranscodeJob
+	if err := d.db.WithContext(ctx).Where("idempotency_key = ?", key).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// UpdateStatus timings 为空时不更新计时
+func (d *TranscodeJobDAO) UpdateStatus(ctx context.Context, jobUUID, status, message, outputPath string, progress int, timings string) error {
 	update := map[string]interface{}{"status": status, "message": message, "progress": progress, "output_path": outputPath}
+	if timings != "" {
+		update["timings"] = timings
+	}
 	return d.db.WithContext(ctx).Model(&po.TranscodeJob{}).Where("job_uuid = ?", jobUUID).Updates(update).Error
 }
 
@@ -55,3 +73,245 @@ func (d *TranscodeJobDAO) QueryByStatus(ctx context.Context, status string, limi
 	}
 	return jobs, nil
 }
+
+// CountPendingAhead 统计排在前面的 pending 作业：优先级更高，或优先级相同且创建更早（不计老化）；
+// express 只统计快车道，standard 另计全部排队的快车道作业（普通协程先取快车道）
+func (d *TranscodeJobDAO) CountPendingAhead(ctx context.Context, lane string, priority int, createdAt time.Time) (int64, error) {
+	var count int64
+	q := d.db.WithContext(ctx).Model(&po.TranscodeJob{}).Where("status = ?", "pending")
+	if lane == "express" {
+		q = q.Where("lane = ? AND (priority > ? OR (priority = ? AND created_at < ?))", lane, priority, priority, createdAt)
+	} else {
+		q = q.Where("lane = ? OR priority > ? OR (priority = ? AND created_at < ?)", "express", priority, priority, createdAt)
+	}
+	err := q.Count(&count).Error
+	return count, err
+}
+
+func (d *TranscodeJobDAO) QueryByVideoUUID(ctx context.Context, videoUUID string) ([]*po.TranscodeJob, error) {
+	var jobs []*po.TranscodeJob
+	if err := d.db.WithContext(ctx).Where("video_uuid = ?", videoUUID).Order("created_at ASC").Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// QueryByVideoUUIDs 批量查询多个视频的全部作业，按创建时间倒序
+func (d *TranscodeJobDAO) QueryByVideoUUIDs(ctx context.Context, videoUUIDs []string) ([]*po.TranscodeJob, error) {
+	var jobs []*po.TranscodeJob
+	if err := d.db.WithContext(ctx).Where("video_uuid IN ?", videoUUIDs).Order("created_at DESC").Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// QueryActiveCreatedBefore 查询在指定时间之前创建且仍未结束（pending/processing/retrying）的作业
+func (d *TranscodeJobDAO) QueryActiveCreatedBefore(ctx context.Context, createdBefore time.Time, limit int) ([]*po.TranscodeJob, error) {
+	var jobs []*po.TranscodeJob
+	q := d.db.WithContext(ctx).
+		Where("status IN ? AND created_at < ?", []string{"pending", "processing", "retrying"}, createdBefore).
+		Order("created_at ASC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// UpdatePriorityIfPending 仅当作业仍在排队时修改优先级，返回是否更新成功
+func (d *TranscodeJobDAO) UpdatePriorityIfPending(ctx context.Context, jobUUID string, priority int) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&po.TranscodeJob{}).
+		Where("job_uuid = ? AND status = ?", jobUUID, "pending").
+		Update("priority", priority)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// ExpireIfActive 仅当作业仍未结束时标记为过期，返回是否更新成功
+func (d *TranscodeJobDAO) ExpireIfActive(ctx context.Context, jobUUID, message string) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&po.TranscodeJob{}).
+		Where("job_uuid = ? AND status IN ?", jobUUID, []string{"pending", "processing", "retrying"}).
+		Updates(map[string]interface{}{"status": "expired", "message": message})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// ScheduleRetry 将作业置为 retrying 并记录重试次数与下次重试时间
+func (d *TranscodeJobDAO) ScheduleRetry(ctx context.Context, jobUUID, message string, retryCount int, nextRetryAt time.Time) error {
+	update := map[string]interface{}{"status": "retrying", "message": message, "retry_count": retryCount, "next_retry_at": nextRetryAt}
+	return d.db.WithContext(ctx).Model(&po.TranscodeJob{}).Where("job_uuid = ?", jobUUID).Updates(update).Error
+}
+
+// QueryDueRetries 查询已到重试时间的 retrying 作业
+func (d *TranscodeJobDAO) QueryDueRetries(ctx context.Context, now time.Time, limit int) ([]*po.TranscodeJob, error) {
+	var jobs []*po.TranscodeJob
+	q := d.db.WithContext(ctx).
+		Where("status = ? AND next_retry_at <= ?", "retrying", now).
+		Order("next_retry_at ASC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ResumeRetry 原子地将 retrying 作业恢复为 pending，多副本下只有一个实例会成功
+func (d *TranscodeJobDAO) ResumeRetry(ctx context.Context, jobUUID string) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&po.TranscodeJob{}).
+		Where("job_uuid = ? AND status = ?", jobUUID, "retrying").
+		Updates(map[string]interface{}{"status": "pending", "next_retry_at": nil})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// MarkRedispatch 为仍处于 pending 的作业打上重新派发标记，作业已出队或已结束时返回 false
+func (d *TranscodeJobDAO) MarkRedispatch(ctx context.Context, jobUUID string, at time.Time) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&po.TranscodeJob{}).
+		Where("job_uuid = ? AND status = ?", jobUUID, "pending").
+		Update("redispatch_at", at)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// QueryRedispatch 查询等待重新派发的 pending 作业
+func (d *TranscodeJobDAO) QueryRedispatch(ctx context.Context, limit int) ([]*po.TranscodeJob, error) {
+	var jobs []*po.TranscodeJob
+	q := d.db.WithContext(ctx).
+		Where("status = ? AND redispatch_at IS NOT NULL", "pending").
+		Order("priority DESC, redispatch_at ASC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ClaimRedispatch 原子地清除重新派发标记，多副本下只有一个实例会成功
+func (d *TranscodeJobDAO) ClaimRedispatch(ctx context.Context, jobUUID string) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&po.TranscodeJob{}).
+		Where("job_uuid = ? AND status = ? AND redispatch_at IS NOT NULL", jobUUID, "pending").
+		Update("redispatch_at", nil)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// QueryFinishedAfter 按 (updated_at, id) 增量查询已结束的作业，before 之后更新的暂不返回
+func (d *TranscodeJobDAO) QueryFinishedAfter(ctx context.Context, afterAt time.Time, afterID uint64, before time.Time, limit int) ([]*po.TranscodeJob, error) {
+	var jobs []*po.TranscodeJob
+	err := d.db.WithContext(ctx).
+		Where("status IN ?", []string{"completed", "failed", "cancelled", "expired", "rejected"}).
+		Where("(updated_at > ? OR (updated_at = ? AND id > ?)) AND updated_at < ?", afterAt, afterAt, afterID, before).
+		Order("updated_at ASC, id ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// QueryStale 按 id 升序查询设置版本低于 beforeVersion 或指纹哈希不等于 excludeHash 的已完成任务，条件为零值时不生效
+func (d *TranscodeJobDAO) QueryStale(ctx context.Context, beforeVersion int, excludeHash string, afterID uint64, limit int) ([]*po.TranscodeJob, error) {
+	var jobs []*po.TranscodeJob
+	err := staleOutputScope(d.db.WithContext(ctx), beforeVersion, excludeHash).
+		Where("status = ? AND id > ?", "completed", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// staleOutputScope 回填筛选条件：两个条件都设置时满足其一即可
+func staleOutputScope(db *gorm.DB, beforeVersion int, excludeHash string) *gorm.DB {
+	switch {
+	case beforeVersion > 0 && excludeHash != "":
+		return db.Where("(settings_version < ? OR fingerprint_hash <> ?)", beforeVersion, excludeHash)
+	case beforeVersion > 0:
+		return db.Where("settings_version < ?", beforeVersion)
+	case excludeHash != "":
+		return db.Where("fingerprint_hash <> ?", excludeHash)
+	}
+	return db
+}
+
+// QueryArchiveCandidates 最近访问（无记录时取完成时间）早于 idleBefore 且仍在标准层的已完成作业
+func (d *TranscodeJobDAO) QueryArchiveCandidates(ctx context.Context, idleBefore time.Time, limit int) ([]*po.TranscodeJob, error) {
+	var jobs []*po.TranscodeJob
+	err := d.db.WithContext(ctx).
+		Where("status = ? AND output_path <> '' AND storage_tier IN ?", "completed", []string{"", "standard"}).
+		Where("COALESCE(last_accessed_at, completed_at, updated_at) < ?", idleBefore).
+		Order("id ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// QueryByStorageTier 查询处于指定存储层级的作业
+func (d *TranscodeJobDAO) QueryByStorageTier(ctx context.Context, tier string, limit int) ([]*po.TranscodeJob, error) {
+	var jobs []*po.TranscodeJob
+	if err := d.db.WithContext(ctx).Where("storage_tier = ?", tier).Order("id ASC").Limit(limit).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// SwapStorageTier 仅当当前层级属于 from 时更新层级与归档位置（JSON）
+func (d *TranscodeJobDAO) SwapStorageTier(ctx context.Context, jobUUID string, from []string, to, storage string) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&po.TranscodeJob{}).
+		Where("job_uuid = ? AND storage_tier IN ?", jobUUID, from).
+		Updates(map[string]interface{}{"storage_tier": to, "output_storage": storage})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// TouchAccess 按产物 key 前移最近访问时间，返回被更新的 job_uuid
+func (d *TranscodeJobDAO) TouchAccess(ctx context.Context, outputPaths []string, at time.Time) ([]string, error) {
+	var uuids []string
+	err := d.db.WithContext(ctx).Model(&po.TranscodeJob{}).
+		Where("output_path IN ? AND (last_accessed_at IS NULL OR last_accessed_at < ?)", outputPaths, at).
+		Pluck("job_uuid", &uuids).Error
+	if err != nil || len(uuids) == 0 {
+		return nil, err
+	}
+	err = d.db.WithContext(ctx).Model(&po.TranscodeJob{}).
+		Where("job_uuid IN ? AND (last_accessed_at IS NULL OR last_accessed_at < ?)", uuids, at).
+		Update("last_accessed_at", at).Error
+	if err != nil {
+		return nil, err
+	}
+	return uuids, nil
+}
+
+// UpdateResourceTrace 更新编码资源时间序列（JSON）
+func (d *TranscodeJobDAO) UpdateResourceTrace(ctx context.Context, jobUUID, trace string) error {
+	return d.db.WithContext(ctx).Model(&po.TranscodeJob{}).Where("job_uuid = ?", jobUUID).Update("resource_trace", trace).Error
+}
+
+// UpdateCommands 更新已执行的 ffmpeg 命令（JSON）
+func (d *TranscodeJobDAO) UpdateCommands(ctx context.Context, jobUUID, commands string) error {
+	return d.db.WithContext(ctx).Model(&po.TranscodeJob{}).Where("job_uuid = ?", jobUUID).Update("ffmpeg_commands", commands).Error
+}
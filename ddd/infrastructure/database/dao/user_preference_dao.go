@@ -0,0 +1,56 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"transcode-service/ddd/infrastructure/database/po"
+	"transcode-service/internal/resource"
+)
+
+type UserPreferenceDAO struct{ db *gorm.DB }
+
+func NewUserPreferenceDAO() *UserPreferenceDAO {
+	return &UserPreferenceDAO{db: resource.DefaultMysqlResource().MainDB()}
+}
+
+// FindByUserUUID 不存在时返回 nil
+func (d *UserPreferenceDAO) FindByUserUUID(ctx context.Context, userUUID string) (*po.UserPreference, error) {
+	var pref po.UserPreference
+	err := d.db.WithContext(ctx).Where("user_uuid = ?", userUUID).First(&pref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+// Upsert 按 user_uuid 新建或覆盖偏好
+func (d *UserPreferenceDAO) Upsert(ctx context.Context, pref *po.UserPreference) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&po.UserPreference{}).Where("user_uuid = ?", pref.UserUUID).Updates(map[string]interface{}{
+			"ladder_json":       pref.LadderJSON,
+			"watermark_json":    pref.WatermarkJSON,
+			"subtitle_language": pref.SubtitleLanguage,
+			"updated_at":        pref.UpdatedAt,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			return nil
+		}
+		return tx.Create(pref).Error
+	})
+}
+
+func (d *UserPreferenceDAO) DeleteByUserUUID(ctx context.Context, userUUID string) (bool, error) {
+	res := d.db.WithContext(ctx).Where("user_uuid = ?", userUUID).Delete(&po.UserPreference{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
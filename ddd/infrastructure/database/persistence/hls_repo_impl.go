@@ -2,8 +2,11 @@ package persistence
 
 import (
 	"context"
+	"time"
+
 	"transcode-service/ddd/domain/entity"
 	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
 	"transcode-service/ddd/infrastructure/database/convertor"
 	"transcode-service/ddd/infrastructure/database/dao"
 )
@@ -21,6 +24,23 @@ func (r *hlsRepositoryImpl) CreateHLSJob(ctx context.Context, job *entity.HLSJob
 	return r.dao.Create(ctx, r.cvt.ToPO(job))
 }
 
+func (r *hlsRepositoryImpl) GetOrCreateHLSJob(ctx context.Context, job *entity.HLSJobEntity) (*entity.HLSJobEntity, bool, error) {
+	source := job.SourceJobUUID()
+	if source == nil || *source == "" {
+		return job, true, r.CreateHLSJob(ctx, job)
+	}
+	if existing, err := r.GetHLSJobBySource(ctx, *source); err != nil || existing != nil {
+		return existing, false, err
+	}
+	created, err := r.dao.CreateIfAbsent(ctx, r.cvt.ToPO(job))
+	if err != nil || created {
+		return job, created, err
+	}
+	// 并发创建冲突，返回先创建的作业
+	existing, err := r.GetHLSJobBySource(ctx, *source)
+	return existing, false, err
+}
+
 func (r *hlsRepositoryImpl) UpdateHLSJobProgress(ctx context.Context, jobUUID string, progress int) error {
 	return r.dao.UpdateProgress(ctx, jobUUID, progress)
 }
@@ -56,3 +76,105 @@ func (r *hlsRepositoryImpl) QueryHLSJobsByStatus(ctx context.Context, status str
 	}
 	return entities, nil
 }
+
+func (r *hlsRepositoryImpl) GetHLSJobBySource(ctx context.Context, sourceJobUUID string) (*entity.HLSJobEntity, error) {
+	jobPo, err := r.dao.FindLatestBySource(ctx, sourceJobUUID)
+	if err != nil || jobPo == nil {
+		return nil, err
+	}
+	return r.cvt.ToEntity(jobPo), nil
+}
+
+func (r *hlsRepositoryImpl) GetHLSJobsBySources(ctx context.Context, sourceJobUUIDs []string) (map[string]*entity.HLSJobEntity, error) {
+	pos, err := r.dao.FindBySources(ctx, sourceJobUUIDs)
+	if err != nil {
+		return nil, err
+	}
+	// 按创建时间升序，同一来源后创建的作业覆盖先前的
+	jobs := make(map[string]*entity.HLSJobEntity, len(pos))
+	for _, p := range pos {
+		if p.SourceJobUUID != nil {
+			jobs[*p.SourceJobUUID] = r.cvt.ToEntity(p)
+		}
+	}
+	return jobs, nil
+}
+
+func (r *hlsRepositoryImpl) QueryHLSJobsByVideo(ctx context.Context, videoUUID string) ([]*entity.HLSJobEntity, error) {
+	pos, err := r.dao.QueryByVideoUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.HLSJobEntity, 0, len(pos))
+	for _, p := range pos {
+		entities = append(entities, r.cvt.ToEntity(p))
+	}
+	return entities, nil
+}
+
+func (r *hlsRepositoryImpl) ClaimHLSJob(ctx context.Context, jobUUID, workerID string) (bool, error) {
+	return r.dao.Claim(ctx, jobUUID, workerID)
+}
+
+func (r *hlsRepositoryImpl) ReleaseStaleHLSClaims(ctx context.Context, claimedBefore, updatedBefore time.Time) (int64, error) {
+	return r.dao.ReleaseStaleClaims(ctx, claimedBefore, updatedBefore)
+}
+
+func (r *hlsRepositoryImpl) TouchHLSJob(ctx context.Context, jobUUID string) error {
+	return r.dao.Touch(ctx, jobUUID)
+}
+
+func (r *hlsRepositoryImpl) UpdateHLSJobRenditions(ctx context.Context, jobUUID string, renditions vo.HLSRenditions) error {
+	data, err := renditions.ToJSON()
+	if err != nil {
+		return err
+	}
+	return r.dao.UpdateRenditions(ctx, jobUUID, data)
+}
+
+func (r *hlsRepositoryImpl) ResetHLSJobForRetry(ctx context.Context, jobUUID string, renditions vo.HLSRenditions) (bool, error) {
+	data, err := renditions.ToJSON()
+	if err != nil {
+		return false, err
+	}
+	return r.dao.ResetForRetry(ctx, jobUUID, data)
+}
+
+func (r *hlsRepositoryImpl) ReleaseInterruptedHLSJob(ctx context.Context, jobUUID string, renditions vo.HLSRenditions) (bool, error) {
+	data, err := renditions.ToJSON()
+	if err != nil {
+		return false, err
+	}
+	return r.dao.ReleaseInterrupted(ctx, jobUUID, data)
+}
+
+func (r *hlsRepositoryImpl) UpdateHLSJobFingerprint(ctx context.Context, jobUUID string, fp *vo.EncoderFingerprint) error {
+	if fp == nil {
+		return nil
+	}
+	data, err := fp.ToJSON()
+	if err != nil {
+		return err
+	}
+	return r.dao.UpdateFingerprint(ctx, jobUUID, data, fp.SettingsVersion, fp.Hash)
+}
+
+func (r *hlsRepositoryImpl) QueryStaleHLSJobs(ctx context.Context, filter vo.StaleOutputFilter, afterID uint64, limit int) ([]*entity.HLSJobEntity, error) {
+	pos, err := r.dao.QueryStale(ctx, filter.BeforeVersion, filter.ExcludeHash, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]*entity.HLSJobEntity, 0, len(pos))
+	for _, p := range pos {
+		entities = append(entities, r.cvt.ToEntity(p))
+	}
+	return entities, nil
+}
+
+func (r *hlsRepositoryImpl) UpdateHLSJobCommands(ctx context.Context, jobUUID string, commands vo.FFmpegCommands) error {
+	data, err := commands.ToJSON()
+	if err != nil {
+		return err
+	}
+	return r.dao.UpdateCommands(ctx, jobUUID, data)
+}
@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"context"
+
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/convertor"
+	"transcode-service/ddd/infrastructure/database/dao"
+)
+
+type pipelineSnapshotRepositoryImpl struct {
+	dao *dao.PipelineSnapshotDAO
+	cvt *convertor.PipelineSnapshotConvertor
+}
+
+func NewPipelineSnapshotRepository() repo.PipelineSnapshotRepository {
+	return &pipelineSnapshotRepositoryImpl{dao: dao.NewPipelineSnapshotDAO(), cvt: convertor.NewPipelineSnapshotConvertor()}
+}
+
+func (r *pipelineSnapshotRepositoryImpl) SavePipelineSnapshot(ctx context.Context, snapshot *vo.PipelineSnapshot) error {
+	p, err := r.cvt.ToPO(snapshot)
+	if err != nil {
+		return err
+	}
+	return r.dao.Upsert(ctx, p)
+}
+
+func (r *pipelineSnapshotRepositoryImpl) ListPipelineSnapshots(ctx context.Context) ([]*vo.PipelineSnapshot, error) {
+	snaps, err := r.dao.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*vo.PipelineSnapshot, 0, len(snaps))
+	for _, p := range snaps {
+		out = append(out, r.cvt.ToVO(p))
+	}
+	return out, nil
+}
+
+func (r *pipelineSnapshotRepositoryImpl) DeletePipelineSnapshot(ctx context.Context, instanceID string) (bool, error) {
+	return r.dao.DeleteByInstanceID(ctx, instanceID)
+}
@@ -0,0 +1,40 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/convertor"
+	"transcode-service/ddd/infrastructure/database/dao"
+)
+
+type taskAssignmentRepositoryImpl struct {
+	dao *dao.TaskAssignmentDAO
+	cvt *convertor.TaskAssignmentConvertor
+}
+
+func NewTaskAssignmentRepository() repo.TaskAssignmentRepository {
+	return &taskAssignmentRepositoryImpl{dao: dao.NewTaskAssignmentDAO(), cvt: convertor.NewTaskAssignmentConvertor()}
+}
+
+func (r *taskAssignmentRepositoryImpl) SaveTaskAssignment(ctx context.Context, a *vo.TaskAssignment) error {
+	return r.dao.Create(ctx, r.cvt.ToPO(a))
+}
+
+func (r *taskAssignmentRepositoryImpl) QueryTaskAssignments(ctx context.Context, workerID string, from, to time.Time, limit int) ([]*vo.TaskAssignment, error) {
+	rows, err := r.dao.ListOverlapping(ctx, workerID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*vo.TaskAssignment, 0, len(rows))
+	for _, p := range rows {
+		out = append(out, r.cvt.ToVO(p))
+	}
+	return out, nil
+}
+
+func (r *taskAssignmentRepositoryImpl) PruneTaskAssignments(ctx context.Context, before time.Time) (int64, error) {
+	return r.dao.DeleteBefore(ctx, before)
+}
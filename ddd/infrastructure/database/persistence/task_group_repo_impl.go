@@ -0,0 +1,113 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/convertor"
+	"transcode-service/ddd/infrastructure/database/dao"
+	"transcode-service/ddd/infrastructure/database/po"
+)
+
+type taskGroupRepositoryImpl struct {
+	dao     *dao.TaskGroupDAO
+	cvt     *convertor.TaskGroupConvertor
+	taskCvt *convertor.TranscodeTaskConvertor
+}
+
+func NewTaskGroupRepository() repo.TaskGroupRepository {
+	return &taskGroupRepositoryImpl{dao: dao.NewTaskGroupDAO(), cvt: convertor.NewTaskGroupConvertor(), taskCvt: convertor.NewTranscodeTaskConvertor()}
+}
+
+func (r *taskGroupRepositoryImpl) CreateTaskGroup(ctx context.Context, group *entity.TaskGroupEntity) error {
+	return r.dao.Create(ctx, r.cvt.ToPO(group))
+}
+
+func (r *taskGroupRepositoryImpl) GetTaskGroup(ctx context.Context, groupUUID string) (*entity.TaskGroupEntity, error) {
+	p, err := r.dao.FindByGroupUUID(ctx, groupUUID)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return r.cvt.ToEntity(p), nil
+}
+
+func (r *taskGroupRepositoryImpl) AttachTasks(ctx context.Context, groupUUID, userUUID string, taskUUIDs []string) (int64, error) {
+	n, err := r.dao.AttachJobs(ctx, groupUUID, userUUID, taskUUIDs)
+	if err == nil {
+		// 读缓存中的任务不带新的 group_uuid
+		for _, id := range taskUUIDs {
+			defaultTaskReadCache().Invalidate(id)
+		}
+	}
+	return n, err
+}
+
+func (r *taskGroupRepositoryImpl) CountGroupTasks(ctx context.Context, groupUUID string) (vo.TaskGroupProgress, error) {
+	var p vo.TaskGroupProgress
+	rows, err := r.dao.CountJobsByStatus(ctx, groupUUID)
+	if err != nil {
+		return p, err
+	}
+	for _, row := range rows {
+		p.Add(vo.NewTaskStatus(row.Status), row.Count, row.ProgressSum)
+	}
+	return p, nil
+}
+
+func (r *taskGroupRepositoryImpl) ListGroupTasks(ctx context.Context, groupUUID string, limit int) ([]*entity.TranscodeTaskEntity, error) {
+	rows, err := r.dao.QueryJobs(ctx, groupUUID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.taskCvt.ToEntities(rows), nil
+}
+
+func (r *taskGroupRepositoryImpl) QueryOpenTaskGroups(ctx context.Context, limit int) ([]*entity.TaskGroupEntity, error) {
+	rows, err := r.dao.QueryByStatus(ctx, string(vo.TaskGroupOpen), limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(rows), nil
+}
+
+func (r *taskGroupRepositoryImpl) CompleteTaskGroup(ctx context.Context, group *entity.TaskGroupEntity) (bool, error) {
+	if group.CompletedAt() == nil {
+		return false, nil
+	}
+	return r.dao.Complete(ctx, group.GroupUUID(), string(group.CallbackState()), *group.CompletedAt())
+}
+
+func (r *taskGroupRepositoryImpl) QueryPendingGroupCallbacks(ctx context.Context, staleBefore time.Time, limit int) ([]*entity.TaskGroupEntity, error) {
+	rows, err := r.dao.QueryCallbacksDue(ctx, staleBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toEntities(rows), nil
+}
+
+func (r *taskGroupRepositoryImpl) ClaimGroupCallback(ctx context.Context, group *entity.TaskGroupEntity, staleBefore time.Time) (bool, error) {
+	return r.dao.ClaimCallback(ctx, group.GroupUUID(), staleBefore)
+}
+
+func (r *taskGroupRepositoryImpl) HoldTaskGroup(ctx context.Context, groupUUID string, until time.Time) error {
+	return r.dao.HoldBatch(ctx, groupUUID, until)
+}
+
+func (r *taskGroupRepositoryImpl) ReleaseTaskGroup(ctx context.Context, groupUUID string, until time.Time) error {
+	return r.dao.ReleaseBatch(ctx, groupUUID, until)
+}
+
+func (r *taskGroupRepositoryImpl) SaveGroupCallbackState(ctx context.Context, group *entity.TaskGroupEntity) error {
+	return r.dao.UpdateCallbackState(ctx, group.GroupUUID(), string(group.CallbackState()), group.CallbackAttempts(), group.CallbackError())
+}
+
+func (r *taskGroupRepositoryImpl) toEntities(rows []*po.TaskGroup) []*entity.TaskGroupEntity {
+	out := make([]*entity.TaskGroupEntity, 0, len(rows))
+	for _, p := range rows {
+		out = append(out, r.cvt.ToEntity(p))
+	}
+	return out
+}
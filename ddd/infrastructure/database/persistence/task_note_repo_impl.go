@@ -0,0 +1,35 @@
+package persistence
+
+import (
+	"context"
+
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/convertor"
+	"transcode-service/ddd/infrastructure/database/dao"
+)
+
+type taskNoteRepositoryImpl struct {
+	dao *dao.TaskNoteDAO
+	cvt *convertor.TaskNoteConvertor
+}
+
+func NewTaskNoteRepository() repo.TaskNoteRepository {
+	return &taskNoteRepositoryImpl{dao: dao.NewTaskNoteDAO(), cvt: convertor.NewTaskNoteConvertor()}
+}
+
+func (r *taskNoteRepositoryImpl) CreateTaskNote(ctx context.Context, note *vo.TaskNote) error {
+	return r.dao.Create(ctx, r.cvt.ToPO(note))
+}
+
+func (r *taskNoteRepositoryImpl) ListTaskNotes(ctx context.Context, taskUUID string, limit int) ([]*vo.TaskNote, error) {
+	rows, err := r.dao.ListByTask(ctx, taskUUID, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*vo.TaskNote, 0, len(rows))
+	for _, p := range rows {
+		out = append(out, r.cvt.ToVO(p))
+	}
+	return out, nil
+}
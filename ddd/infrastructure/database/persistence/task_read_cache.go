@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"sync"
+	"time"
+
+	"transcode-service/ddd/infrastructure/database/po"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/metrics"
+)
+
+// taskReadCache 转码任务读缓存，缓存持久化对象，每次命中都转换出新的实体，避免调用方共享可变实体
+type taskReadCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	items      map[string]cachedTaskPO
+}
+
+type cachedTaskPO struct {
+	job       *po.TranscodeJob
+	expiresAt time.Time
+}
+
+var (
+	taskReadCacheOnce      sync.Once
+	singletonTaskReadCache *taskReadCache
+)
+
+// defaultTaskReadCache 进程内共享，所有仓储实例的写入都会使其失效；未启用时返回 nil
+func defaultTaskReadCache() *taskReadCache {
+	taskReadCacheOnce.Do(func() {
+		cfg := config.GetGlobalConfig()
+		if cfg == nil || !cfg.ReadCache.Enabled {
+			return
+		}
+		singletonTaskReadCache = &taskReadCache{
+			ttl:        cfg.ReadCache.TTL,
+			maxEntries: cfg.ReadCache.MaxEntries,
+			items:      make(map[string]cachedTaskPO),
+		}
+	})
+	return singletonTaskReadCache
+}
+
+func (c *taskReadCache) Get(jobUUID string) (*po.TranscodeJob, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, ok := c.items[jobUUID]
+	if !ok || time.Now().After(item.expiresAt) {
+		metrics.Add("task_read_cache_misses_total", 1)
+		return nil, false
+	}
+	metrics.Add("task_read_cache_hits_total", 1)
+	return item.job, true
+}
+
+func (c *taskReadCache) Put(jobUUID string, job *po.TranscodeJob) {
+	if c == nil || job == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if len(c.items) >= c.maxEntries {
+		// 先清理过期项，仍满时放弃写入
+		for k, v := range c.items {
+			if now.After(v.expiresAt) {
+				delete(c.items, k)
+			}
+		}
+		if len(c.items) >= c.maxEntries {
+			return
+		}
+	}
+	c.items[jobUUID] = cachedTaskPO{job: job, expiresAt: now.Add(c.ttl)}
+}
+
+func (c *taskReadCache) Invalidate(jobUUID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, jobUUID)
+}
@@ -0,0 +1,38 @@
+package persistence
+
+import (
+	"context"
+
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/database/convertor"
+	"transcode-service/ddd/infrastructure/database/dao"
+)
+
+type throughputRepositoryImpl struct {
+	dao *dao.ThroughputDAO
+	cvt *convertor.ThroughputConvertor
+}
+
+func NewThroughputRepository() repo.ThroughputRepository {
+	return &throughputRepositoryImpl{dao: dao.NewThroughputDAO(), cvt: convertor.NewThroughputConvertor()}
+}
+
+func (r *throughputRepositoryImpl) AddDailyThroughput(ctx context.Context, delta vo.DailyThroughput) error {
+	if delta.IsZero() {
+		return nil
+	}
+	return r.dao.Increment(ctx, r.cvt.ToPO(delta))
+}
+
+func (r *throughputRepositoryImpl) ListDailyThroughput(ctx context.Context, from, to string) ([]vo.DailyThroughput, error) {
+	rows, err := r.dao.ListRange(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]vo.DailyThroughput, 0, len(rows))
+	for _, p := range rows {
+		out = append(out, r.cvt.ToVO(p))
+	}
+	return out, nil
+}
@@ -2,8 +2,10 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/event"
 	"transcode-service/ddd/domain/repo"
 	"transcode-service/ddd/domain/vo"
 	"transcode-service/ddd/infrastructure/database/convertor"
@@ -13,34 +15,83 @@ import (
 type transcodeRepositoryImpl struct {
 	jobDao    *dao.TranscodeJobDAO
 	convertor *convertor.TranscodeTaskConvertor
+	cache     *taskReadCache // 仅读接口使用的仓储设置；写入始终使失效
 }
 
 func NewTranscodeRepository() repo.TranscodeJobRepository {
 	return &transcodeRepositoryImpl{jobDao: dao.NewTranscodeJobDAO(), convertor: convertor.NewTranscodeTaskConvertor()}
 }
 
+// NewCachedTranscodeRepository GetTranscodeJob 走短 TTL 读缓存，供轮询较多的查询接口使用；
+// worker 等需要最新状态的路径使用 NewTranscodeRepository
+func NewCachedTranscodeRepository() repo.TranscodeJobRepository {
+	r := NewTranscodeRepository().(*transcodeRepositoryImpl)
+	r.cache = defaultTaskReadCache()
+	return r
+}
+
 func (t *transcodeRepositoryImpl) CreateTranscodeJob(ctx context.Context, job *entity.TranscodeTaskEntity) error {
 	return t.jobDao.Create(ctx, t.convertor.ToPO(job))
 }
 
 func (t *transcodeRepositoryImpl) UpdateTranscodeJobProgress(ctx context.Context, jobUUID string, progress int) error {
+	defer t.invalidate(jobUUID)
 	return t.jobDao.UpdateProgress(ctx, jobUUID, progress)
 }
 
+func (t *transcodeRepositoryImpl) UpdateTranscodeJobStageProgress(ctx context.Context, jobUUID string, progress int, stages vo.StageProgress, timings vo.TaskTimings) error {
+	data, err := stages.ToJSON()
+	if err != nil {
+		return err
+	}
+	timingData, err := timings.ToJSON()
+	if err != nil {
+		return err
+	}
+	defer t.invalidate(jobUUID)
+	return t.jobDao.UpdateStageProgress(ctx, jobUUID, progress, data, timingData)
+}
+
 func (t *transcodeRepositoryImpl) UpdateTranscodeJob(ctx context.Context, job *entity.TranscodeTaskEntity) error {
-	return t.jobDao.UpdateJob(ctx, t.convertor.ToPO(job))
+	defer t.invalidate(job.TaskUUID())
+	if err := t.jobDao.UpdateJob(ctx, t.convertor.ToPO(job)); err != nil {
+		return err
+	}
+	t.publish(ctx, job)
+	return nil
 }
 
 func (t *transcodeRepositoryImpl) GetTranscodeJob(ctx context.Context, jobUUID string) (*entity.TranscodeTaskEntity, error) {
+	if jobPo, ok := t.cache.Get(jobUUID); ok {
+		return t.convertor.ToEntity(jobPo), nil
+	}
 	jobPo, err := t.jobDao.FindByJobUUID(ctx, jobUUID)
 	if err != nil {
 		return nil, err
 	}
+	t.cache.Put(jobUUID, jobPo)
 	return t.convertor.ToEntity(jobPo), nil
 }
 
-func (t *transcodeRepositoryImpl) UpdateTranscodeJobStatus(ctx context.Context, jobUUID string, status vo.TaskStatus, message, outputPath string, progress int) error {
-	return t.jobDao.UpdateStatus(ctx, jobUUID, status.String(), message, outputPath, progress)
+func (t *transcodeRepositoryImpl) GetTranscodeJobByIdempotencyKey(ctx context.Context, key string) (*entity.TranscodeTaskEntity, error) {
+	jobPo, err := t.jobDao.FindByIdempotencyKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return t.convertor.ToEntity(jobPo), nil
+}
+
+func (t *transcodeRepositoryImpl) SaveTranscodeJobStatus(ctx context.Context, job *entity.TranscodeTaskEntity) error {
+	defer t.invalidate(job.TaskUUID())
+	var timings string
+	if tt := job.Timings(); !tt.IsZero() {
+		timings, _ = tt.ToJSON()
+	}
+	if err := t.jobDao.UpdateStatus(ctx, job.TaskUUID(), job.Status().String(), job.ErrorMessage(), job.OutputPath(), job.Progress(), timings); err != nil {
+		return err
+	}
+	t.publish(ctx, job)
+	return nil
 }
 
 func (t *transcodeRepositoryImpl) QueryTranscodeJobsByStatus(ctx context.Context, status vo.TaskStatus, limit int) ([]*entity.TranscodeTaskEntity, error) {
@@ -50,3 +101,182 @@ func (t *transcodeRepositoryImpl) QueryTranscodeJobsByStatus(ctx context.Context
 	}
 	return t.convertor.ToEntities(jobs), nil
 }
+
+func (t *transcodeRepositoryImpl) CountPendingTranscodeJobsAhead(ctx context.Context, lane vo.TaskLane, priority int, createdAt time.Time) (int64, error) {
+	return t.jobDao.CountPendingAhead(ctx, string(lane), priority, createdAt)
+}
+
+func (t *transcodeRepositoryImpl) QueryTranscodeJobsByVideo(ctx context.Context, videoUUID string) ([]*entity.TranscodeTaskEntity, error) {
+	jobs, err := t.jobDao.QueryByVideoUUID(ctx, videoUUID)
+	if err != nil {
+		return nil, err
+	}
+	return t.convertor.ToEntities(jobs), nil
+}
+
+func (t *transcodeRepositoryImpl) QueryLatestTranscodeJobsByVideos(ctx context.Context, videoUUIDs []string) (map[string]*entity.TranscodeTaskEntity, error) {
+	out := make(map[string]*entity.TranscodeTaskEntity, len(videoUUIDs))
+	if len(videoUUIDs) == 0 {
+		return out, nil
+	}
+	jobs, err := t.jobDao.QueryByVideoUUIDs(ctx, videoUUIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if _, ok := out[job.VideoUUID]; !ok {
+			out[job.VideoUUID] = t.convertor.ToEntity(job)
+		}
+	}
+	return out, nil
+}
+
+func (t *transcodeRepositoryImpl) QueryActiveTranscodeJobsCreatedBefore(ctx context.Context, createdBefore time.Time, limit int) ([]*entity.TranscodeTaskEntity, error) {
+	jobs, err := t.jobDao.QueryActiveCreatedBefore(ctx, createdBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	return t.convertor.ToEntities(jobs), nil
+}
+
+func (t *transcodeRepositoryImpl) QueryStaleTranscodeJobs(ctx context.Context, filter vo.StaleOutputFilter, afterID uint64, limit int) ([]*entity.TranscodeTaskEntity, error) {
+	jobs, err := t.jobDao.QueryStale(ctx, filter.BeforeVersion, filter.ExcludeHash, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return t.convertor.ToEntities(jobs), nil
+}
+
+func (t *transcodeRepositoryImpl) ExpireTranscodeJob(ctx context.Context, job *entity.TranscodeTaskEntity) (bool, error) {
+	defer t.invalidate(job.TaskUUID())
+	ok, err := t.jobDao.ExpireIfActive(ctx, job.TaskUUID(), job.ErrorMessage())
+	if err != nil || !ok {
+		job.PullEvents()
+		return ok, err
+	}
+	t.publish(ctx, job)
+	return true, nil
+}
+
+func (t *transcodeRepositoryImpl) UpdateTranscodeJobPriority(ctx context.Context, taskUUID string, priority int) (bool, error) {
+	defer t.invalidate(taskUUID)
+	return t.jobDao.UpdatePriorityIfPending(ctx, taskUUID, priority)
+}
+
+func (t *transcodeRepositoryImpl) ScheduleTranscodeJobRetry(ctx context.Context, job *entity.TranscodeTaskEntity) error {
+	defer t.invalidate(job.TaskUUID())
+	next := time.Now()
+	if job.NextRetryAt() != nil {
+		next = *job.NextRetryAt()
+	}
+	if err := t.jobDao.ScheduleRetry(ctx, job.TaskUUID(), job.ErrorMessage(), job.RetryCount(), next); err != nil {
+		return err
+	}
+	t.publish(ctx, job)
+	return nil
+}
+
+func (t *transcodeRepositoryImpl) QueryDueRetryTranscodeJobs(ctx context.Context, now time.Time, limit int) ([]*entity.TranscodeTaskEntity, error) {
+	jobs, err := t.jobDao.QueryDueRetries(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return t.convertor.ToEntities(jobs), nil
+}
+
+func (t *transcodeRepositoryImpl) ResumeRetryTranscodeJob(ctx context.Context, job *entity.TranscodeTaskEntity) (bool, error) {
+	defer t.invalidate(job.TaskUUID())
+	ok, err := t.jobDao.ResumeRetry(ctx, job.TaskUUID())
+	if err != nil || !ok {
+		job.PullEvents()
+		return ok, err
+	}
+	t.publish(ctx, job)
+	return true, nil
+}
+
+func (t *transcodeRepositoryImpl) QueryArchiveCandidates(ctx context.Context, idleBefore time.Time, limit int) ([]*entity.TranscodeTaskEntity, error) {
+	jobs, err := t.jobDao.QueryArchiveCandidates(ctx, idleBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	return t.convertor.ToEntities(jobs), nil
+}
+
+func (t *transcodeRepositoryImpl) QueryTranscodeJobsByStorageTier(ctx context.Context, tier vo.StorageTier, limit int) ([]*entity.TranscodeTaskEntity, error) {
+	jobs, err := t.jobDao.QueryByStorageTier(ctx, string(tier), limit)
+	if err != nil {
+		return nil, err
+	}
+	return t.convertor.ToEntities(jobs), nil
+}
+
+func (t *transcodeRepositoryImpl) SwapTranscodeOutputStorage(ctx context.Context, jobUUID string, from []vo.StorageTier, storage vo.OutputStorage) (bool, error) {
+	data, err := storage.ToJSON()
+	if err != nil {
+		return false, err
+	}
+	tiers := make([]string, 0, len(from)+1)
+	for _, tier := range from {
+		tiers = append(tiers, string(tier))
+		if tier == vo.StorageTierStandard {
+			tiers = append(tiers, "") // 历史任务的层级列为空
+		}
+	}
+	defer t.invalidate(jobUUID)
+	return t.jobDao.SwapStorageTier(ctx, jobUUID, tiers, string(storage.Tier), data)
+}
+
+func (t *transcodeRepositoryImpl) TouchTranscodeOutputAccess(ctx context.Context, outputPaths []string, at time.Time) (int, error) {
+	uuids, err := t.jobDao.TouchAccess(ctx, outputPaths, at)
+	for _, id := range uuids {
+		t.invalidate(id)
+	}
+	return len(uuids), err
+}
+
+func (t *transcodeRepositoryImpl) MarkTranscodeJobForRedispatch(ctx context.Context, taskUUID string) (bool, error) {
+	defer t.invalidate(taskUUID)
+	return t.jobDao.MarkRedispatch(ctx, taskUUID, time.Now())
+}
+
+func (t *transcodeRepositoryImpl) QueryRedispatchTranscodeJobs(ctx context.Context, limit int) ([]*entity.TranscodeTaskEntity, error) {
+	jobs, err := t.jobDao.QueryRedispatch(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	return t.convertor.ToEntities(jobs), nil
+}
+
+func (t *transcodeRepositoryImpl) ClaimRedispatchTranscodeJob(ctx context.Context, taskUUID string) (bool, error) {
+	defer t.invalidate(taskUUID)
+	return t.jobDao.ClaimRedispatch(ctx, taskUUID)
+}
+
+func (t *transcodeRepositoryImpl) UpdateTranscodeJobCommands(ctx context.Context, jobUUID string, commands vo.FFmpegCommands) error {
+	data, err := commands.ToJSON()
+	if err != nil {
+		return err
+	}
+	defer t.invalidate(jobUUID)
+	return t.jobDao.UpdateCommands(ctx, jobUUID, data)
+}
+
+func (t *transcodeRepositoryImpl) UpdateTranscodeJobResourceTrace(ctx context.Context, jobUUID string, trace *vo.ResourceTrace) error {
+	data, err := trace.ToJSON()
+	if err != nil {
+		return err
+	}
+	defer t.invalidate(jobUUID)
+	return t.jobDao.UpdateResourceTrace(ctx, jobUUID, data)
+}
+
+// publish 持久化成功后取出实体上的状态变更事件并发布
+func (t *transcodeRepositoryImpl) publish(ctx context.Context, job *entity.TranscodeTaskEntity) {
+	event.DefaultBus().Publish(ctx, job.PullEvents()...)
+}
+
+// invalidate 写入后使共享读缓存失效（与当前仓储是否启用缓存无关）
+func (t *transcodeRepositoryImpl) invalidate(jobUUID string) {
+	defaultTaskReadCache().Invalidate(jobUUID)
+}
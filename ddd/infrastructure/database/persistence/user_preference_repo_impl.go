@@ -0,0 +1,35 @@
+package persistence
+
+import (
+	"context"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/infrastructure/database/convertor"
+	"transcode-service/ddd/infrastructure/database/dao"
+)
+
+type userPreferenceRepositoryImpl struct {
+	dao *dao.UserPreferenceDAO
+	cvt *convertor.UserPreferenceConvertor
+}
+
+func NewUserPreferenceRepository() repo.UserPreferenceRepository {
+	return &userPreferenceRepositoryImpl{dao: dao.NewUserPreferenceDAO(), cvt: convertor.NewUserPreferenceConvertor()}
+}
+
+func (r *userPreferenceRepositoryImpl) GetUserPreference(ctx context.Context, userUUID string) (*entity.UserPreferenceEntity, error) {
+	p, err := r.dao.FindByUserUUID(ctx, userUUID)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	return r.cvt.ToEntity(p), nil
+}
+
+func (r *userPreferenceRepositoryImpl) SaveUserPreference(ctx context.Context, pref *entity.UserPreferenceEntity) error {
+	return r.dao.Upsert(ctx, r.cvt.ToPO(pref))
+}
+
+func (r *userPreferenceRepositoryImpl) DeleteUserPreference(ctx context.Context, userUUID string) (bool, error) {
+	return r.dao.DeleteByUserUUID(ctx, userUUID)
+}
@@ -0,0 +1,16 @@
+package po
+
+import "time"
+
+// AnalyticsCursor 分析数据导出游标持久化对象
+type AnalyticsCursor struct {
+	BaseModel
+	Name     string    `gorm:"column:name;type:varchar(32);uniqueIndex" json:"name"`
+	CursorAt time.Time `gorm:"column:cursor_at" json:"cursor_at"`
+	CursorID uint64    `gorm:"column:cursor_id" json:"cursor_id"`
+}
+
+// TableName 指定表名
+func (AnalyticsCursor) TableName() string {
+	return "analytics_export_cursors"
+}
@@ -0,0 +1,48 @@
+package po
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"transcode-service/pkg/fieldcrypt"
+)
+
+// EncryptedString 落库时加密、读取时解密的字符串列，对 convertor 与领域实体透明。
+// 未启用加密时按明文写入；历史明文行可直接读取，由轮换接口补加密
+type EncryptedString string
+
+// Value 写入前用 primary 密钥加密
+func (s EncryptedString) Value() (driver.Value, error) {
+	return fieldcrypt.Writer().Encrypt(string(s))
+}
+
+// Scan 读取后按密文中的密钥 ID 解密
+func (s *EncryptedString) Scan(src interface{}) error {
+	var raw string
+	switch v := src.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("EncryptedString: unsupported scan type %T", src)
+	}
+	plain, err := fieldcrypt.Default().Decrypt(raw)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plain)
+	return nil
+}
+
+// NewEncryptedString 由可空字符串创建，nil 写入 NULL
+func NewEncryptedString(v *string) *EncryptedString {
+	if v == nil {
+		return nil
+	}
+	s := EncryptedString(*v)
+	return &s
+}
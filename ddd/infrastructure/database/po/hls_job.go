@@ -8,7 +8,7 @@ type HLSJob struct {
 	JobUUID         string     `gorm:"column:job_uuid;type:varchar(36);uniqueIndex" json:"job_uuid"`
 	UserUUID        string     `gorm:"column:user_uuid;type:varchar(36);index" json:"user_uuid"`
 	VideoUUID       string     `gorm:"column:video_uuid;type:varchar(36);index" json:"video_uuid"`
-	SourceJobUUID   *string    `gorm:"column:source_job_uuid;type:varchar(36);index" json:"source_job_uuid,omitempty"`
+	SourceJobUUID   *string    `gorm:"column:source_job_uuid;type:varchar(36);uniqueIndex:uk_source_job_uuid" json:"source_job_uuid,omitempty"`
 	SourceType      string     `gorm:"column:source_type;type:varchar(20)" json:"source_type"` // original|transcoded
 	InputPath       string     `gorm:"column:input_path;type:varchar(512)" json:"input_path"`
 	OutputDir       string     `gorm:"column:output_dir;type:varchar(512)" json:"output_dir"`
@@ -21,8 +21,18 @@ type HLSJob struct {
 	Format          string     `gorm:"column:format;type:varchar(20);default:'mpegts'" json:"format"`
 	VariantCount    int        `gorm:"column:variant_count;type:int;default:0" json:"variant_count"`
 	ErrorMessage    *string    `gorm:"column:error_message;type:varchar(500)" json:"error_message,omitempty"`
+	WorkerID        *string    `gorm:"column:worker_id;type:varchar(64);index" json:"worker_id,omitempty"`
+	ClaimedAt       *time.Time `gorm:"column:claimed_at;type:timestamp" json:"claimed_at,omitempty"`
 	StartedAt       *time.Time `gorm:"column:started_at;type:timestamp" json:"started_at,omitempty"`
 	CompletedAt     *time.Time `gorm:"column:completed_at;type:timestamp" json:"completed_at,omitempty"`
+	Commands        *string    `gorm:"column:ffmpeg_commands;type:json" json:"ffmpeg_commands,omitempty"`
+	Renditions      *string    `gorm:"column:renditions;type:json" json:"renditions,omitempty"`
+	Fingerprint     *string    `gorm:"column:encoder_fingerprint;type:json" json:"encoder_fingerprint,omitempty"`
+	SettingsVersion int        `gorm:"column:settings_version;type:int;default:0;index" json:"settings_version"`
+	FingerprintHash string     `gorm:"column:fingerprint_hash;type:varchar(16);default:'';index" json:"fingerprint_hash"`
+	PrivateToken    string     `gorm:"column:private_token;type:varchar(32);default:''" json:"private_token"`  // 随源任务，私有产物的令牌目录
+	OutputBucket    string     `gorm:"column:output_bucket;type:varchar(63);default:''" json:"output_bucket"`  // 随源任务的目标桶
+	OutputPrefix    string     `gorm:"column:output_prefix;type:varchar(256);default:''" json:"output_prefix"` // 随源任务的产物前缀
 }
 
 // TableName 指定表名
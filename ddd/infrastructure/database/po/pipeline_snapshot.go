@@ -0,0 +1,17 @@
+package po
+
+import "time"
+
+// PipelineSnapshot 实例流水线状态快照持久化对象
+type PipelineSnapshot struct {
+	BaseModel
+	InstanceID string    `gorm:"column:instance_id;type:varchar(128);uniqueIndex" json:"instance_id"`
+	ClaimsJSON string    `gorm:"column:claims_json;type:json" json:"claims_json"`
+	Released   bool      `gorm:"column:released" json:"released"`
+	TakenAt    time.Time `gorm:"column:taken_at" json:"taken_at"`
+}
+
+// TableName 指定表名
+func (PipelineSnapshot) TableName() string {
+	return "pipeline_snapshots"
+}
@@ -0,0 +1,23 @@
+package po
+
+import "time"
+
+// TaskAssignment 作业分配历史持久化对象
+type TaskAssignment struct {
+	BaseModel
+	WorkerID     string    `gorm:"column:worker_id;type:varchar(128);index" json:"worker_id"`
+	JobType      string    `gorm:"column:job_type;type:varchar(32)" json:"job_type"`
+	TaskUUID     string    `gorm:"column:task_uuid;type:varchar(64);index" json:"task_uuid"`
+	Attempt      int       `gorm:"column:attempt" json:"attempt"`
+	StartedAt    time.Time `gorm:"column:started_at;index" json:"started_at"`
+	FinishedAt   time.Time `gorm:"column:finished_at" json:"finished_at"`
+	Outcome      string    `gorm:"column:outcome;type:varchar(16)" json:"outcome"`
+	SlotWeight   int       `gorm:"column:slot_weight" json:"slot_weight"`
+	SlotCapacity int       `gorm:"column:slot_capacity" json:"slot_capacity"`
+	WaitMs       int64     `gorm:"column:wait_ms" json:"wait_ms"`
+}
+
+// TableName 指定表名
+func (TaskAssignment) TableName() string {
+	return "task_assignments"
+}
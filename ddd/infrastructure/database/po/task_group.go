@@ -0,0 +1,24 @@
+package po
+
+import "time"
+
+// TaskGroup 任务组持久化对象
+type TaskGroup struct {
+	BaseModel
+	GroupUUID        string     `gorm:"column:group_uuid;type:varchar(36);uniqueIndex" json:"group_uuid"`
+	UserUUID         string     `gorm:"column:user_uuid;type:varchar(36);index" json:"user_uuid"`
+	Name             string     `gorm:"column:name;type:varchar(128);default:''" json:"name"`
+	ExpectedTasks    int        `gorm:"column:expected_tasks;type:int;default:0" json:"expected_tasks"`
+	CallbackURL      string     `gorm:"column:callback_url;type:varchar(1024);default:''" json:"callback_url"`
+	Status           string     `gorm:"column:status;type:varchar(16);default:'open';index" json:"status"`                 // open/completed
+	CallbackState    string     `gorm:"column:callback_state;type:varchar(16);default:'none';index" json:"callback_state"` // none/pending/delivering/delivered/failed
+	CallbackAttempts int        `gorm:"column:callback_attempts;type:int;default:0" json:"callback_attempts"`
+	CallbackError    string     `gorm:"column:callback_error;type:varchar(512);default:''" json:"callback_error"`
+	CompletedAt      *time.Time `gorm:"column:completed_at;type:timestamp" json:"completed_at,omitempty"`
+	BatchUntil       *time.Time `gorm:"column:batch_until;type:timestamp" json:"batch_until,omitempty"` // 批量创建进行中的完成保护期
+}
+
+// TableName 指定表名
+func (TaskGroup) TableName() string {
+	return "task_groups"
+}
@@ -0,0 +1,15 @@
+package po
+
+// TaskNote 任务备注持久化对象，CreatedAt 即备注时间
+type TaskNote struct {
+	BaseModel
+	NoteUUID string          `gorm:"column:note_uuid;type:varchar(64);uniqueIndex" json:"note_uuid"`
+	TaskUUID string          `gorm:"column:task_uuid;type:varchar(64);index" json:"task_uuid"`
+	Author   string          `gorm:"column:author;type:varchar(128)" json:"author"`
+	Body     EncryptedString `gorm:"column:body;type:text" json:"body"`
+}
+
+// TableName 指定表名
+func (TaskNote) TableName() string {
+	return "task_notes"
+}
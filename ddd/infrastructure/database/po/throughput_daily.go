@@ -0,0 +1,15 @@
+package po
+
+// ThroughputDaily 按 UTC 日期汇总的处理量持久化对象
+type ThroughputDaily struct {
+	BaseModel
+	StatDate    string `gorm:"column:stat_date;type:char(10);uniqueIndex" json:"stat_date"` // 2006-01-02
+	InputBytes  int64  `gorm:"column:input_bytes" json:"input_bytes"`
+	OutputBytes int64  `gorm:"column:output_bytes" json:"output_bytes"`
+	EncodeMs    int64  `gorm:"column:encode_ms" json:"encode_ms"`
+}
+
+// TableName 指定表名
+func (ThroughputDaily) TableName() string {
+	return "throughput_daily"
+}
@@ -5,26 +5,58 @@ import "time"
 // TranscodeJob 完整视频转码作业持久化对象
 type TranscodeJob struct {
 	BaseModel
-	JobUUID       string     `gorm:"column:job_uuid;type:varchar(36);uniqueIndex" json:"job_uuid"`
-	UserUUID      string     `gorm:"column:user_uuid;type:varchar(36);index" json:"user_uuid"`
-	VideoUUID     string     `gorm:"column:video_uuid;type:varchar(36);index" json:"video_uuid"`
-	VideoPushUUID string     `gorm:"column:video_push_uuid;type:varchar(36);index" json:"video_push_uuid"`
-	InputPath     string     `gorm:"column:input_path;type:varchar(512)" json:"input_path"`
-	OutputPath    string     `gorm:"column:output_path;type:varchar(512)" json:"output_path"`
-	Resolution    string     `gorm:"column:resolution;type:varchar(50)" json:"resolution"`
-	Bitrate       string     `gorm:"column:bitrate;type:varchar(50)" json:"bitrate"`
-	Status        string     `gorm:"column:status;type:varchar(20);index" json:"status"`
-	Progress      int        `gorm:"column:progress;type:int" json:"progress"`
-	Message       string     `gorm:"column:message;type:varchar(255)" json:"message"`
-	WorkerID      *string    `gorm:"column:worker_id;type:varchar(36);index" json:"worker_id,omitempty"`
-	Priority      int        `gorm:"column:priority;type:int;default:5" json:"priority"`
-	RetryCount    int        `gorm:"column:retry_count;type:int;default:0" json:"retry_count"`
-	MaxRetryCount int        `gorm:"column:max_retry_count;type:int;default:3" json:"max_retry_count"`
-	StartedAt     *time.Time `gorm:"column:started_at;type:timestamp" json:"started_at,omitempty"`
-	CompletedAt   *time.Time `gorm:"column:completed_at;type:timestamp" json:"completed_at,omitempty"`
-	EstimatedTime *int64     `gorm:"column:estimated_time;type:bigint" json:"estimated_time,omitempty"`
-	ActualTime    *int64     `gorm:"column:actual_time;type:bigint" json:"actual_time,omitempty"`
-	Metadata      *string    `gorm:"column:metadata;type:json" json:"metadata,omitempty"`
+	JobUUID          string           `gorm:"column:job_uuid;type:varchar(36);uniqueIndex" json:"job_uuid"`
+	UserUUID         string           `gorm:"column:user_uuid;type:varchar(36);index" json:"user_uuid"`
+	VideoUUID        string           `gorm:"column:video_uuid;type:varchar(36);index" json:"video_uuid"`
+	VideoPushUUID    string           `gorm:"column:video_push_uuid;type:varchar(36);index" json:"video_push_uuid"`
+	InputPath        string           `gorm:"column:input_path;type:varchar(512)" json:"input_path"`
+	OutputPath       string           `gorm:"column:output_path;type:varchar(512)" json:"output_path"`
+	Resolution       string           `gorm:"column:resolution;type:varchar(50)" json:"resolution"`
+	Bitrate          string           `gorm:"column:bitrate;type:varchar(50)" json:"bitrate"`
+	Container        string           `gorm:"column:container;type:varchar(10);default:'mp4'" json:"container"`
+	PreviewSeconds   int              `gorm:"column:preview_seconds;type:int;default:0" json:"preview_seconds"`
+	VideoStreamIndex *int             `gorm:"column:video_stream_index;type:int" json:"video_stream_index,omitempty"`
+	AudioStreamIndex *int             `gorm:"column:audio_stream_index;type:int" json:"audio_stream_index,omitempty"`
+	Status           string           `gorm:"column:status;type:varchar(20);index" json:"status"`
+	Progress         int              `gorm:"column:progress;type:int" json:"progress"`
+	Message          string           `gorm:"column:message;type:varchar(255)" json:"message"`
+	WorkerID         *string          `gorm:"column:worker_id;type:varchar(36);index" json:"worker_id,omitempty"`
+	Priority         int              `gorm:"column:priority;type:int;default:5" json:"priority"`
+	RetryCount       int              `gorm:"column:retry_count;type:int;default:0" json:"retry_count"`
+	MaxRetryCount    int              `gorm:"column:max_retry_count;type:int;default:3" json:"max_retry_count"`
+	NextRetryAt      *time.Time       `gorm:"column:next_retry_at;type:timestamp" json:"next_retry_at,omitempty"`
+	RedispatchAt     *time.Time       `gorm:"column:redispatch_at;type:timestamp" json:"redispatch_at,omitempty"`
+	StartedAt        *time.Time       `gorm:"column:started_at;type:timestamp" json:"started_at,omitempty"`
+	CompletedAt      *time.Time       `gorm:"column:completed_at;type:timestamp" json:"completed_at,omitempty"`
+	EstimatedTime    *int64           `gorm:"column:estimated_time;type:bigint" json:"estimated_time,omitempty"`
+	ActualTime       *int64           `gorm:"column:actual_time;type:bigint" json:"actual_time,omitempty"`
+	Metadata         *EncryptedString `gorm:"column:metadata;type:text" json:"metadata,omitempty"`
+	StageProgress    *string          `gorm:"column:stage_progress;type:json" json:"stage_progress,omitempty"`
+	Timings          *string          `gorm:"column:timings;type:json" json:"timings,omitempty"`
+	Commands         *string          `gorm:"column:ffmpeg_commands;type:json" json:"ffmpeg_commands,omitempty"`
+	Labels           *EncryptedString `gorm:"column:labels;type:text" json:"labels,omitempty"`
+	SourceGeneration int64            `gorm:"column:source_generation;type:bigint;default:0" json:"source_generation"`
+	AutoProfile      *string          `gorm:"column:auto_profile;type:json" json:"auto_profile,omitempty"`
+	AudioOutputs     *string          `gorm:"column:audio_outputs;type:json" json:"audio_outputs,omitempty"`
+	GOP              *string          `gorm:"column:gop_policy;type:json" json:"gop_policy,omitempty"`
+	BitrateCap       *string          `gorm:"column:bitrate_cap;type:json" json:"bitrate_cap,omitempty"`
+	Fingerprint      *string          `gorm:"column:encoder_fingerprint;type:json" json:"encoder_fingerprint,omitempty"`
+	SourceStreams    *string          `gorm:"column:source_streams;type:json" json:"source_streams,omitempty"`
+	ResourceTrace    *string          `gorm:"column:resource_trace;type:json" json:"resource_trace,omitempty"`
+	StorageTier      string           `gorm:"column:storage_tier;type:varchar(16);default:'';index" json:"storage_tier"`
+	LastAccessedAt   *time.Time       `gorm:"column:last_accessed_at;type:timestamp;index" json:"last_accessed_at,omitempty"`
+	OutputStorage    *string          `gorm:"column:output_storage;type:json" json:"output_storage,omitempty"`
+	SettingsVersion  int              `gorm:"column:settings_version;type:int;default:0;index" json:"settings_version"`
+	FingerprintHash  string           `gorm:"column:fingerprint_hash;type:varchar(16);default:'';index" json:"fingerprint_hash"`
+	FormatSet        string           `gorm:"column:format_set;type:varchar(64);default:''" json:"format_set"`
+	MaxRenditions    int              `gorm:"column:max_renditions;type:int;default:0" json:"max_renditions"`
+	ParentTaskUUID   *string          `gorm:"column:parent_task_uuid;type:varchar(36);index" json:"parent_task_uuid,omitempty"`
+	IdempotencyKey   *string          `gorm:"column:idempotency_key;type:varchar(191);uniqueIndex" json:"idempotency_key,omitempty"`
+	PrivateToken     string           `gorm:"column:private_token;type:varchar(32);default:''" json:"private_token"`  // 私有产物的令牌目录，公开任务为空
+	OutputBucket     string           `gorm:"column:output_bucket;type:varchar(63);default:''" json:"output_bucket"`  // 请求指定的产物目标桶，默认桶为空
+	OutputPrefix     string           `gorm:"column:output_prefix;type:varchar(256);default:''" json:"output_prefix"` // 目标桶内的产物前缀
+	Lane             string           `gorm:"column:lane;type:varchar(16);default:'standard'" json:"lane"`            // 调度通道 standard/express
+	GroupUUID        string           `gorm:"column:group_uuid;type:varchar(36);default:'';index" json:"group_uuid"`  // 所属任务组，未加入时为空
 }
 
 // TableName 指定表名
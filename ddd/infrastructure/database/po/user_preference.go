@@ -0,0 +1,15 @@
+package po
+
+// UserPreference 用户默认转码偏好持久化对象
+type UserPreference struct {
+	BaseModel
+	UserUUID         string  `gorm:"column:user_uuid;type:varchar(36);uniqueIndex" json:"user_uuid"`
+	LadderJSON       *string `gorm:"column:ladder_json;type:json" json:"ladder_json,omitempty"`
+	WatermarkJSON    *string `gorm:"column:watermark_json;type:json" json:"watermark_json,omitempty"`
+	SubtitleLanguage string  `gorm:"column:subtitle_language;type:varchar(16)" json:"subtitle_language"`
+}
+
+// TableName 指定表名
+func (UserPreference) TableName() string {
+	return "user_transcode_preferences"
+}
@@ -0,0 +1,202 @@
+// Package diagnostics 事故现场的按需剖析：在本实例上采集 execution trace、CPU/heap 等 profile，
+// 写入工作目录后上传到对象存储，供离线用 go tool trace / go tool pprof 分析
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/infrastructure/workspace"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+const (
+	KindTrace     = "trace"
+	KindCPU       = "cpu"
+	KindHeap      = "heap"
+	KindAllocs    = "allocs"
+	KindGoroutine = "goroutine"
+
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+
+	// historySize 保留最近的采集记录数
+	historySize = 20
+	// uploadTimeout 单个剖析文件的上传超时
+	uploadTimeout = 2 * time.Minute
+)
+
+// Capture 一次采集的状态与产物位置
+type Capture struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	Seconds    int        `json:"seconds,omitempty"`
+	Status     string     `json:"status"`
+	ObjectKey  string     `json:"object_key,omitempty"`
+	Bytes      int64      `json:"bytes,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Capturer 同一实例同时只运行一次采集：trace 与 CPU profile 在进程内都是独占的
+type Capturer struct {
+	cfg     *config.Config
+	storage gateway.StorageGateway
+
+	mu      sync.Mutex
+	running bool
+	history []*Capture
+}
+
+func NewCapturer(cfg *config.Config, storage gateway.StorageGateway) *Capturer {
+	if cfg == nil {
+		cfg = config.GetGlobalConfig()
+	}
+	return &Capturer{cfg: cfg, storage: storage}
+}
+
+// Start 异步开始采集并立即返回；trace/cpu 的 seconds 缺省为 max_capture_seconds，heap 等快照类忽略 seconds
+func (c *Capturer) Start(kind string, seconds int) (*Capture, error) {
+	maxSeconds := c.cfg.Diagnostics.MaxCaptureSeconds
+	switch kind {
+	case KindTrace, KindCPU:
+		if seconds == 0 {
+			seconds = maxSeconds
+		}
+		if seconds < 1 || seconds > maxSeconds {
+			return nil, errno.ErrInvalidProfileCapture
+		}
+	case KindHeap, KindAllocs, KindGoroutine:
+		seconds = 0
+	default:
+		return nil, errno.ErrInvalidProfileCapture
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.running {
+		return nil, errno.ErrProfileCaptureRunning
+	}
+	c.running = true
+	capture := &Capture{ID: clock.NewID(), Kind: kind, Seconds: seconds, Status: StatusRunning, StartedAt: time.Now()}
+	c.history = append(c.history, capture)
+	if len(c.history) > historySize {
+		c.history = c.history[len(c.history)-historySize:]
+	}
+	metrics.Add("profile_captures_total", 1)
+	logger.Infof("profile capture started id=%s kind=%s seconds=%d", capture.ID, kind, seconds)
+	go c.run(capture)
+	cp := *capture
+	return &cp, nil
+}
+
+// List 最近的采集记录，新的在前
+func (c *Capturer) List() []Capture {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]Capture, 0, len(c.history))
+	for i := len(c.history) - 1; i >= 0; i-- {
+		out = append(out, *c.history[i])
+	}
+	return out
+}
+
+func (c *Capturer) run(capture *Capture) {
+	key, size, err := c.captureAndUpload(capture)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	capture.FinishedAt = &now
+	c.running = false
+	if err != nil {
+		capture.Status, capture.Error = StatusFailed, err.Error()
+		metrics.Add("profile_capture_failures_total", 1)
+		logger.Warnf("profile capture failed id=%s kind=%s error=%v", capture.ID, capture.Kind, err)
+		return
+	}
+	capture.Status, capture.ObjectKey, capture.Bytes = StatusCompleted, key, size
+	logger.Infof("profile capture finished id=%s kind=%s object_key=%s bytes=%d", capture.ID, capture.Kind, key, size)
+}
+
+func (c *Capturer) captureAndUpload(capture *Capture) (string, int64, error) {
+	if c.storage == nil {
+		return "", 0, fmt.Errorf("storage gateway not configured")
+	}
+	ws, err := workspace.DefaultManager().Acquire("profile-" + capture.ID)
+	if err != nil {
+		return "", 0, fmt.Errorf("create workspace: %w", err)
+	}
+	defer ws.Release()
+
+	name := fmt.Sprintf("%s_%s.%s", capture.StartedAt.UTC().Format("20060102T150405Z"), capture.Kind, fileExt(capture.Kind))
+	local := ws.Path(name)
+	if err := writeProfile(local, capture.Kind, time.Duration(capture.Seconds)*time.Second); err != nil {
+		return "", 0, err
+	}
+	info, err := os.Stat(local)
+	if err != nil {
+		return "", 0, err
+	}
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "unknown"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+	key, err := c.storage.UploadTranscodedFile(ctx, local, fmt.Sprintf("%s/%s/%s", c.cfg.Diagnostics.CapturePrefix, host, name), "application/octet-stream")
+	if err != nil {
+		return "", 0, fmt.Errorf("upload profile: %w", err)
+	}
+	return key, info.Size(), nil
+}
+
+// writeProfile 采集写入本地文件；trace/cpu 与 /debug/pprof 的同类请求互斥，冲突时返回错误
+func writeProfile(path, kind string, d time.Duration) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	switch kind {
+	case KindTrace:
+		if err := trace.Start(f); err != nil {
+			return fmt.Errorf("start trace: %w", err)
+		}
+		time.Sleep(d)
+		trace.Stop()
+	case KindCPU:
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return fmt.Errorf("start cpu profile: %w", err)
+		}
+		time.Sleep(d)
+		pprof.StopCPUProfile()
+	default:
+		if kind == KindHeap {
+			// heap profile 反映最近一次 GC 的结果，先触发一次 GC 取到最新数据
+			runtime.GC()
+		}
+		if err := pprof.Lookup(kind).WriteTo(f, 0); err != nil {
+			return fmt.Errorf("write %s profile: %w", kind, err)
+		}
+	}
+	return f.Sync()
+}
+
+func fileExt(kind string) string {
+	if kind == KindTrace {
+		return "trace"
+	}
+	return "pb.gz"
+}
@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/port"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/workspace"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// produceAudioRenditions 从已下载的源文件生成纯音频产物并上传到各自的 object key。
+// 与视频产物同属一个任务，任一产物失败即任务失败；完整视频跳过上传时音频产物仍然上传
+func (e *FFmpegExecutor) produceAudioRenditions(ctx context.Context, task *entity.TranscodeTaskEntity, inputPath string, ws *workspace.Workspace, opts port.TranscodeOptions) error {
+	params := task.GetParams()
+	if !params.HasAudioOutputs() {
+		return nil
+	}
+	if s := task.SourceStreams(); s != nil && s.Content == vo.SourceVideoOnly {
+		// 源文件没有音频，静音音轨不值得单独发布
+		logger.Infof("audio renditions skipped, source has no audio task_uuid=%s", task.TaskUUID())
+		return nil
+	}
+	if e.storage == nil {
+		return fmt.Errorf("audio rendition: storage gateway not configured")
+	}
+	for _, r := range params.Audio.Renditions {
+		localPath := ws.Path("audio", r.Bitrate+r.Format.Extension())
+		args := audioRenditionArgs(params, r, inputPath, localPath)
+		if opts.CommandCb != nil {
+			opts.CommandCb(vo.FFmpegCommand{Label: "audio-" + string(r.Format), Binary: e.Binary(), Args: args, RecordedAt: time.Now()})
+		}
+		start := time.Now()
+		if out, err := exec.CommandContext(ctx, e.Binary(), args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("audio rendition %s %s: %w: %s", r.Format, r.Bitrate, err, lastLine(out))
+		}
+		key := strings.TrimPrefix(r.ObjectKey, "/")
+		if _, err := e.storage.UploadTranscodedFile(ctx, localPath, key, r.Format.ContentType()); err != nil {
+			return fmt.Errorf("upload audio rendition %s: %w", key, err)
+		}
+		metrics.Add("audio_renditions_total_"+string(r.Format), 1)
+		logger.Infof("audio rendition published task_uuid=%s format=%s bitrate=%s object_key=%s took=%s", task.TaskUUID(), r.Format, r.Bitrate, key, time.Since(start).Round(time.Millisecond))
+	}
+	return nil
+}
+
+// audioRenditionArgs 丢弃视频流，只编码选定（或第一条）音频流并写入元数据标签
+func audioRenditionArgs(params vo.TranscodeParams, r vo.AudioRendition, inputPath, outputPath string) []string {
+	audioMap := "0:a:0"
+	if params.AudioStream != nil {
+		audioMap = "0:" + strconv.Itoa(*params.AudioStream)
+	}
+	args := []string{"-y", "-i", inputPath, "-map", audioMap, "-vn", "-c:a", r.Format.Codec(), "-b:a", r.Bitrate}
+	args = append(args, params.Audio.MetadataArgs()...)
+	return append(args, "-f", r.Format.Muxer(), outputPath)
+}
+
+// lastLine ffmpeg 输出的最后一行，通常是失败原因
+func lastLine(out []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
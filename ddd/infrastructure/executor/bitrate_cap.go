@@ -0,0 +1,40 @@
+package executor
+
+import (
+	"os"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// applyBitrateCap 按文件大小/时长估算源码率（含音频），封顶 MP4 输出码率并记录到任务；
+// HLS 阶梯在转码完成后按同一源码率封顶
+func (e *FFmpegExecutor) applyBitrateCap(task *entity.TranscodeTaskEntity, policy *vo.BitrateCapPolicy, inputPath string, durationSec float64) {
+	if policy == nil || durationSec <= 0 {
+		return
+	}
+	fi, err := os.Stat(inputPath)
+	if err != nil {
+		return
+	}
+	sourceKbps := int(float64(fi.Size()) * 8 / durationSec / 1000)
+	params := task.GetParams()
+	adj := &vo.BitrateAdjustment{SourceKbps: sourceKbps}
+	if out := policy.CapOutput(params.Resolution, params.Bitrate, sourceKbps); out.Adjusted() {
+		adj.Output = &out
+		metrics.Add("bitrate_capped_outputs_total", 1)
+		logger.Infof("output bitrate capped by source task_uuid=%s source_kbps=%d requested=%s capped=%s",
+			task.TaskUUID(), sourceKbps, out.Requested, out.Bitrate)
+	}
+	task.SetBitrateAdjustment(adj)
+}
+
+// outputBitrate 封顶后的 MP4 码率，未封顶时为请求码率
+func outputBitrate(task *entity.TranscodeTaskEntity) string {
+	if adj := task.BitrateAdjustment(); adj != nil && adj.Output != nil && adj.Output.Bitrate != "" {
+		return adj.Output.Bitrate
+	}
+	return task.GetParams().Bitrate
+}
@@ -0,0 +1,125 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+const (
+	warmerRestartMin = 5 * time.Second
+	warmerRestartMax = 2 * time.Minute
+)
+
+// EncoderWarmer 保持一个常驻 ffmpeg 进程持有 CUDA 设备上下文，避免在 GPU 空闲时驱动被卸载，
+// 后续任务无需承担驱动重新初始化的开销。
+//
+// ffmpeg CLI 不支持在同一进程中连续接收多个任务（没有 server 模式），每个任务仍需创建自己的
+// CUDA 上下文与 NVENC 会话；常驻进程只消除驱动冷启动部分，效果以 ffmpeg_setup_seconds_* 指标衡量。
+// 常驻进程不创建编码会话，不占用 NVENC 会话配额。
+type EncoderWarmer struct {
+	binary string
+	device string
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	stdin  io.WriteCloser
+}
+
+// NewEncoderWarmer 仅在启用 keep_warm 且使用 CUDA/NVENC 时返回常驻进程任务，否则返回 nil
+func NewEncoderWarmer(cfg *config.Config) *EncoderWarmer {
+	if cfg == nil || !cfg.Transcode.FFmpeg.KeepWarm || !usesCUDA(cfg) {
+		return nil
+	}
+	return &EncoderWarmer{binary: cfg.Transcode.FFmpeg.Binary(), device: "cuda=warm:0"}
+}
+
+func usesCUDA(cfg *config.Config) bool {
+	return strings.EqualFold(cfg.Transcode.FFmpeg.HardwareAccel, "cuda") ||
+		strings.Contains(strings.ToLower(cfg.Transcode.FFmpeg.VideoCodec), "nvenc")
+}
+
+func (w *EncoderWarmer) Name() string { return "encoderWarmer" }
+
+func (w *EncoderWarmer) Start(ctx context.Context) error {
+	warmCtx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+	w.wg.Add(1)
+	go w.loop(warmCtx)
+	return nil
+}
+
+// Stop 关闭 stdin 让 ffmpeg 读到 EOF 后退出，上下文取消兜底强杀
+func (w *EncoderWarmer) Stop() error {
+	w.mu.Lock()
+	if w.stdin != nil {
+		_ = w.stdin.Close()
+	}
+	w.mu.Unlock()
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.wg.Wait()
+	metrics.Set("encoder_warmer_up", 0)
+	return nil
+}
+
+func (w *EncoderWarmer) loop(ctx context.Context) {
+	defer w.wg.Done()
+	backoff := warmerRestartMin
+	for {
+		started := time.Now()
+		err := w.run(ctx)
+		metrics.Set("encoder_warmer_up", 0)
+		if ctx.Err() != nil {
+			return
+		}
+		metrics.Add("encoder_warmer_restarts_total", 1)
+		if time.Since(started) > warmerRestartMax {
+			backoff = warmerRestartMin
+		}
+		logger.Warnf("encoder warmer exited, restarting in %s error=%v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > warmerRestartMax {
+			backoff = warmerRestartMax
+		}
+	}
+}
+
+// run 初始化 CUDA 设备后阻塞在读取 stdin 上，直到 stdin 关闭或进程被杀
+func (w *EncoderWarmer) run(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, w.binary,
+		"-hide_banner", "-v", "error",
+		"-init_hw_device", w.device,
+		"-f", "rawvideo", "-pix_fmt", "yuv420p", "-s", "16x16", "-i", "pipe:0",
+		"-f", "null", "-",
+	)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.stdin = stdin
+	w.mu.Unlock()
+	metrics.Set("encoder_warmer_up", 1)
+	logger.Infof("encoder warmer started pid=%d device=%s", cmd.Process.Pid, w.device)
+	err = cmd.Wait()
+	w.mu.Lock()
+	w.stdin = nil
+	w.mu.Unlock()
+	return err
+}
@@ -6,24 +6,33 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"transcode-service/ddd/domain/entity"
 	"transcode-service/ddd/domain/gateway"
 	"transcode-service/ddd/domain/port"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/publicurl"
+	"transcode-service/ddd/infrastructure/throughput"
+	"transcode-service/ddd/infrastructure/workspace"
 	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/hwaccel"
 	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+	"transcode-service/pkg/utils"
 )
 
 // FFmpegExecutor implements port.TranscodeExecutor using local ffmpeg and StorageGateway.
 type FFmpegExecutor struct {
 	cfg     *config.Config
 	storage gateway.StorageGateway
+	uploads *UploadPool
 }
 
 func NewFFmpegExecutor(cfg *config.Config, storage gateway.StorageGateway) *FFmpegExecutor {
@@ -33,51 +42,101 @@ func NewFFmpegExecutor(cfg *config.Config, storage gateway.StorageGateway) *FFmp
 	return &FFmpegExecutor{cfg: cfg, storage: storage}
 }
 
+// SetUploadPool 设置产物上传池，nil 表示在编码协程内同步上传
+func (e *FFmpegExecutor) SetUploadPool(pool *UploadPool) {
+	e.uploads = pool
+}
+
 // Execute runs ffmpeg, uploads result unless SkipUpload, and cleans temporary files.
 func (e *FFmpegExecutor) Execute(ctx context.Context, task *entity.TranscodeTaskEntity, opts port.TranscodeOptions) (string, string, error) {
 	if task == nil {
 		return "", "", errors.New("nil task")
 	}
-	cfg := e.cfg
-	if cfg == nil {
-		cfg = config.GetGlobalConfig()
+	// 每个任务独立工作目录，任何返回路径（含取消）都会清理
+	ws, err := workspace.DefaultManager().Acquire("transcode-" + task.TaskUUID())
+	if err != nil {
+		return "", "", fmt.Errorf("create workspace: %w", err)
 	}
-	tempDir := os.TempDir()
-	if cfg != nil && strings.TrimSpace(cfg.Transcode.FFmpeg.TempDir) != "" {
-		tempDir = cfg.Transcode.FFmpeg.TempDir
+	release := func() {
+		reclaimed := ws.Release()
+		logger.Infof("transcode workspace released task_uuid=%s reclaimed_bytes=%d", task.TaskUUID(), reclaimed)
 	}
+	// 产物交给上传池后，工作目录由上传池在上传结束时清理
+	handedOff := false
+	defer func() {
+		if !handedOff {
+			release()
+		}
+	}()
 
 	// Prepare paths
-	localInputPath := filepath.Join(tempDir, "inputs", fmt.Sprintf("input_%s_%s", task.TaskUUID(), filepath.Base(task.OriginalPath())))
-	if err := os.MkdirAll(filepath.Dir(localInputPath), 0o755); err != nil {
-		return "", "", fmt.Errorf("create temp dir: %w", err)
+	// 本地文件名由对象 key 派生，超长或含非 ASCII 字符时改用哈希，避免超出文件系统文件名长度限制
+	localInputPath := ws.Path("input", utils.LocalFileName(task.OriginalPath()))
+	localOutputPath := ws.Path("output", utils.LocalFileName(task.OutputPath()))
+
+	// 源文件可能尚未复制到位，等待时长单独记录，不计入下载阶段
+	waited, err := e.waitForSource(ctx, task.TaskUUID(), task.OriginalPath())
+	if waited > 0 {
+		task.RecordSourceWait(waited)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("wait for source: %w", err)
 	}
-	localOutputPath := filepath.Join(tempDir, strings.TrimPrefix(task.OutputPath(), "/"))
-	if err := os.MkdirAll(filepath.Dir(localOutputPath), 0o755); err != nil {
-		return "", "", fmt.Errorf("create output dir: %w", err)
+
+	if err := e.checkSourceSize(ctx, task); err != nil {
+		return "", "", err
 	}
 
 	// Download input
+	reportStage(opts.StageCb, vo.StageDownload, 0)
 	if e.storage != nil {
 		if err := e.storage.DownloadFile(ctx, task.OriginalPath(), localInputPath); err != nil {
 			return "", "", fmt.Errorf("download input: %w", err)
 		}
 	}
-	defer func() {
-		_ = os.Remove(localInputPath)
-	}()
+	reportStage(opts.StageCb, vo.StageDownload, 100)
+	// 编码阶段从探测输入开始计时
+	reportStage(opts.StageCb, vo.StageEncode, 0)
 
-	durationSec := e.probeDurationSeconds(localInputPath)
-	cmd := e.buildFFmpegCommand(ctx, task, localInputPath, localOutputPath)
+	durationSec, err := e.probeDurationSeconds(ctx, localInputPath)
+	if err != nil {
+		return "", "", err
+	}
+	if err := e.checkProbedLimits(ctx, task, localInputPath, durationSec); err != nil {
+		return "", "", err
+	}
+	e.applyBitrateCap(task, opts.BitrateCap, localInputPath, durationSec)
+	if preview := float64(task.GetParams().PreviewSeconds); preview > 0 && (durationSec <= 0 || durationSec > preview) {
+		// 进度按预览时长计算
+		durationSec = preview
+	}
+	cmd, err := e.buildFFmpegCommand(ctx, task, localInputPath, localOutputPath)
+	if err != nil {
+		return "", "", err
+	}
 	logger.Infof("ffmpeg command task_uuid=%s command=%s", task.TaskUUID(), strings.Join(cmd.Args, " "))
-	if err := e.executeFFmpegCommand(ctx, cmd, durationSec, opts.ProgressCb); err != nil {
+	if opts.CommandCb != nil {
+		opts.CommandCb(vo.FFmpegCommand{Label: "mp4", Binary: e.Binary(), Args: cmd.Args[1:], RecordedAt: time.Now()})
+	}
+	encodeStarted := time.Now()
+	setup, err := e.executeFFmpegCommand(ctx, cmd, durationSec, "mp4", opts)
+	throughput.AddEncode(time.Since(encodeStarted))
+	if setup > 0 {
+		recordSetupTime(e.encoderKind(), setup)
+		logger.Infof("ffmpeg setup finished task_uuid=%s encoder=%s setup_ms=%d", task.TaskUUID(), e.encoderKind(), setup.Milliseconds())
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if err := e.produceAudioRenditions(ctx, task, localInputPath, ws, opts); err != nil {
 		return "", "", err
 	}
+	reportStage(opts.StageCb, vo.StageEncode, 100)
 
 	var objectKey, publicURL string
 	if opts.SkipUpload {
-		// 不上传完整视频，直接清理本地产物
-		_ = os.Remove(localOutputPath)
+		// 不上传完整视频，本地产物随工作目录清理
+		reportStage(opts.StageCb, vo.StageUpload, 100)
 		return "", "", nil
 	}
 
@@ -89,39 +148,77 @@ func (e *FFmpegExecutor) Execute(ctx context.Context, task *entity.TranscodeTask
 		objectKey = filepath.Base(localOutputPath)
 	}
 
-	uploadedKey, err := e.storage.UploadTranscodedFile(ctx, localOutputPath, objectKey, "video/mp4")
+	if e.uploads != nil && opts.OnUploaded != nil {
+		handedOff = e.uploads.submit(&uploadJob{
+			taskUUID:    task.TaskUUID(),
+			localPath:   localOutputPath,
+			objectKey:   objectKey,
+			contentType: task.GetParams().OutputContainer().ContentType(),
+			stageCb:     opts.StageCb,
+			done:        opts.OnUploaded,
+			release:     release,
+		})
+		if handedOff {
+			return "", "", port.ErrUploadDeferred
+		}
+	}
+
+	reportStage(opts.StageCb, vo.StageUpload, 0)
+	uploadedKey, err := e.storage.UploadTranscodedFile(ctx, localOutputPath, objectKey, task.GetParams().OutputContainer().ContentType())
 	if err != nil {
 		return "", "", fmt.Errorf("upload output: %w", err)
 	}
-	_ = os.Remove(localOutputPath)
+	reportStage(opts.StageCb, vo.StageUpload, 100)
 	objectKey = uploadedKey
-	publicURL = e.buildFileURL(uploadedKey)
+	if task.PrivateToken() != "" {
+		// 私有产物不生成公开 URL
+		publicURL, _, _ = publicurl.BuildPrivate(uploadedKey)
+		return objectKey, publicURL, nil
+	}
+	publicURL = publicurl.DefaultBuilder().Build(uploadedKey)
 	return objectKey, publicURL, nil
 }
 
 // --- internal helpers (mostly migrated from old domain service) ---
 
-func (e *FFmpegExecutor) executeFFmpegCommand(ctx context.Context, cmd *exec.Cmd, durationSec float64, progressCb port.ProgressCallback) error {
+func reportStage(cb port.StageProgressCallback, stage vo.PipelineStage, progress int) {
+	if cb != nil {
+		cb(stage, progress)
+	}
+}
+
+// executeFFmpegCommand 执行 ffmpeg 并返回启动耗时（进程启动到输出首帧，含解码/编码器初始化），未出帧时为 0
+func (e *FFmpegExecutor) executeFFmpegCommand(ctx context.Context, cmd *exec.Cmd, durationSec float64, label string, opts port.TranscodeOptions) (time.Duration, error) {
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return fmt.Errorf("创建FFmpeg stderr管道失败: %w", err)
+		return 0, fmt.Errorf("创建FFmpeg stderr管道失败: %w", err)
 	}
 
+	startedAt := time.Now()
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("启动FFmpeg命令失败: %w", err)
+		return 0, fmt.Errorf("启动FFmpeg命令失败: %w", err)
 	}
 
 	progressDone := make(chan struct{})
 	buf := make([]string, 0, 200)
+	var setup time.Duration
+	// 启动到首次出进度同样计入卡死窗口
+	stats := &progressStats{advancedAt: startedAt}
+	sampler := e.startResourceSampler(cmd, label, opts.RequestID, startedAt, stats, opts.TraceCb)
 	go func() {
 		defer close(progressDone)
-		e.scanFFmpegProgress(ctx, stderr, durationSec, &buf, progressCb)
+		e.scanFFmpegProgress(ctx, stderr, durationSec, &buf, stats, opts.ProgressCb, func() { setup = time.Since(startedAt) })
 	}()
 
 	done := make(chan error, 1)
 	go func() {
 		done <- cmd.Wait()
 	}()
+	// 进程存活但长时间没有进度推进（NFS 读挂起、驱动卡死）时终止进程
+	window := e.stallWindow()
+	stopWatch := make(chan struct{})
+	defer close(stopWatch)
+	stalled := watchStall(stopWatch, stats, window, label)
 
 	select {
 	case <-ctx.Done():
@@ -129,9 +226,20 @@ func (e *FFmpegExecutor) executeFFmpegCommand(ctx context.Context, cmd *exec.Cmd
 			_ = cmd.Process.Kill()
 		}
 		<-progressDone
-		return ctx.Err()
+		sampler.finish(nil, setup)
+		return setup, ctx.Err()
+	case <-stalled:
+		if cmd.Process != nil {
+			_ = cmd.Process.Kill()
+		}
+		<-progressDone
+		sampler.finish(nil, setup)
+		at, outTime := stats.lastAdvance()
+		logger.Errorf("ffmpeg stalled, process killed label=%s window=%s last_progress_at=%s out_time_sec=%.1f", label, window, at.Format(time.RFC3339), outTime)
+		return setup, fmt.Errorf("%w: no progress for %s at out_time=%.1fs", errno.ErrEncodeStalled, window, outTime)
 	case err := <-done:
 		<-progressDone
+		sampler.finish(cmd.ProcessState, setup)
 		if err != nil {
 			tail := buf
 			if n := len(tail); n > 50 {
@@ -141,11 +249,12 @@ func (e *FFmpegExecutor) executeFFmpegCommand(ctx context.Context, cmd *exec.Cmd
 				logger.Errorf("ffmpeg failed tail_stderr=%s", strings.Join(tail, "\n"))
 			}
 		}
-		return err
+		return setup, err
 	}
 }
 
-func (e *FFmpegExecutor) scanFFmpegProgress(ctx context.Context, stderr io.ReadCloser, durationSec float64, capture *[]string, progressCb port.ProgressCallback) {
+// scanFFmpegProgress 解析进度输出并把 fps/speed 记入 stats；firstFrame 在首次出现 frame>0 时调用一次
+func (e *FFmpegExecutor) scanFFmpegProgress(ctx context.Context, stderr io.ReadCloser, durationSec float64, capture *[]string, stats *progressStats, progressCb port.ProgressCallback, firstFrame func()) {
 	scanner := bufio.NewScanner(stderr)
 	scanner.Buffer(make([]byte, 0, 1024), 1024*1024)
 	reTime := regexp.MustCompile(`time=(\d+):(\d+):(\d+\.?\d*)`)
@@ -158,6 +267,15 @@ func (e *FFmpegExecutor) scanFFmpegProgress(ctx context.Context, stderr io.ReadC
 		}
 		line := scanner.Text()
 
+		if strings.HasPrefix(line, "frame=") {
+			if n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "frame="))); err == nil && n > 0 && firstFrame != nil {
+				firstFrame()
+				firstFrame = nil
+			}
+			continue
+		}
+
+		stats.observe(line)
 		if strings.HasPrefix(line, "out_time_ms=") {
 			if ms, err := strconv.ParseFloat(strings.TrimPrefix(line, "out_time_ms="), 64); err == nil && durationSec > 0 {
 				sec := ms / 1e6
@@ -200,22 +318,61 @@ func (e *FFmpegExecutor) emitProgress(currentSec, totalSec float64, cb port.Prog
 	cb(pct)
 }
 
-// probeDurationSeconds 调用 ffprobe 获取输入时长（秒），失败则返回 0。
-func (e *FFmpegExecutor) probeDurationSeconds(inputPath string) float64 {
-	cmd := exec.Command("ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", inputPath)
-	out, err := cmd.Output()
+// encoderKind 指标维度：nvenc 或 software
+func (e *FFmpegExecutor) encoderKind() string {
+	if e.cfg != nil && strings.Contains(strings.ToLower(e.cfg.Transcode.FFmpeg.VideoCodec), "nvenc") {
+		return "nvenc"
+	}
+	return "software"
+}
+
+// recordSetupTime 按编码器类型累计启动耗时，平均值 = setup_ms_total / setup_count
+func recordSetupTime(kind string, setup time.Duration) {
+	metrics.SetFloat("ffmpeg_setup_seconds_last_"+kind, setup.Seconds())
+	metrics.Add("ffmpeg_setup_ms_total_"+kind, setup.Milliseconds())
+	metrics.Add("ffmpeg_setup_count_"+kind, 1)
+}
+
+// runProbe 以超时上下文执行 ffprobe，失败统一归类为 ErrProbeFailed
+func (e *FFmpegExecutor) runProbe(ctx context.Context, args ...string) ([]byte, error) {
+	binary := "ffprobe"
+	timeout := 30 * time.Second
+	if e.cfg != nil {
+		binary = e.cfg.Transcode.FFprobe.Binary()
+		if e.cfg.Transcode.FFprobe.Timeout > 0 {
+			timeout = e.cfg.Transcode.FFprobe.Timeout
+		}
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	out, err := exec.CommandContext(probeCtx, binary, args...).Output()
 	if err != nil {
-		return 0
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if probeCtx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("%w: ffprobe timed out after %s", errno.ErrProbeFailed, timeout)
+		}
+		return nil, fmt.Errorf("%w: %v", errno.ErrProbeFailed, err)
+	}
+	return out, nil
+}
+
+// probeDurationSeconds 调用 ffprobe 获取输入时长（秒）；无法解析时长时返回 0。
+func (e *FFmpegExecutor) probeDurationSeconds(ctx context.Context, inputPath string) (float64, error) {
+	out, err := e.runProbe(ctx, "-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", inputPath)
+	if err != nil {
+		return 0, err
 	}
 	val, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
 	if err != nil {
-		return 0
+		return 0, nil
 	}
-	return val
+	return val, nil
 }
 
-func (e *FFmpegExecutor) probeVideoCodec(inputPath string) (codec string, pixFmt string) {
-	cmd := exec.Command("ffprobe",
+func (e *FFmpegExecutor) probeVideoCodec(ctx context.Context, inputPath string) (codec string, pixFmt string) {
+	out, err := e.runProbe(ctx,
 		"-v", "error",
 		"-probesize", "5M",
 		"-analyzeduration", "5M",
@@ -224,8 +381,8 @@ func (e *FFmpegExecutor) probeVideoCodec(inputPath string) (codec string, pixFmt
 		"-of", "csv=p=0",
 		inputPath,
 	)
-	out, err := cmd.Output()
 	if err != nil {
+		logger.Warnf("probe video codec failed input=%s error=%v", inputPath, err)
 		return "", ""
 	}
 	parts := strings.Split(strings.TrimSpace(string(out)), ",")
@@ -238,18 +395,86 @@ func (e *FFmpegExecutor) probeVideoCodec(inputPath string) (codec string, pixFmt
 	return
 }
 
-func (e *FFmpegExecutor) buildFFmpegCommand(ctx context.Context, task *entity.TranscodeTaskEntity, inputPath, outputPath string) *exec.Cmd {
+// buildFFmpegCommand 探测输入流，记录源文件内容并解析 -map 映射；流探测失败时只保留显式指定的映射
+func (e *FFmpegExecutor) buildFFmpegCommand(ctx context.Context, task *entity.TranscodeTaskEntity, inputPath, outputPath string) (*exec.Cmd, error) {
 	params := task.GetParams()
+	params.Bitrate = outputBitrate(task)
+	inputCodec := ""
+	streams, err := e.probeStreams(ctx, inputPath)
+	if err != nil {
+		logger.Warnf("probe streams failed task_uuid=%s input=%s error=%v", task.TaskUUID(), inputPath, err)
+		inputCodec, _ = e.probeVideoCodec(ctx, inputPath)
+	} else {
+		if params.Streams, err = e.resolveSourceStreams(task, streams); err != nil {
+			return nil, err
+		}
+		sel, err := selectStreams(streams, params)
+		if err != nil {
+			return nil, err
+		}
+		if params.Streams.AudioOnly() {
+			if params.AudioStream, err = selectAudioOnly(streams, params); err != nil {
+				return nil, err
+			}
+		} else if sel.video != nil {
+			params.VideoStream, params.AudioStream = sel.video, sel.audio
+			inputCodec = sel.videoCodec
+			if !task.GetParams().HasStreamSelection() && len(streams) > 2 {
+				logger.Infof("input streams auto selected task_uuid=%s video=%d audio=%s streams=%d", task.TaskUUID(), *sel.video, streamIndexString(sel.audio), len(streams))
+			}
+		} else {
+			inputCodec, _ = e.probeVideoCodec(ctx, inputPath)
+		}
+	}
+	return exec.CommandContext(ctx, e.Binary(), e.BuildArgs(params, inputCodec, inputPath, outputPath)...), nil
+}
+
+func streamIndexString(idx *int) string {
+	if idx == nil {
+		return "none"
+	}
+	return strconv.Itoa(*idx)
+}
+
+// mapArgs 显式输入流映射；只指定视频流时音频可选映射第一条音频流
+func mapArgs(params vo.TranscodeParams) []string {
+	if !params.HasStreamSelection() {
+		return nil
+	}
+	var args []string
+	if params.VideoStream != nil {
+		args = append(args, "-map", "0:"+strconv.Itoa(*params.VideoStream))
+	} else {
+		args = append(args, "-map", "0:v:0")
+	}
+	if params.AudioStream != nil {
+		args = append(args, "-map", "0:"+strconv.Itoa(*params.AudioStream))
+	} else {
+		args = append(args, "-map", "0:a:0?")
+	}
+	return args
+}
+
+// Binary 当前架构生效的 ffmpeg 路径
+func (e *FFmpegExecutor) Binary() string {
+	if e.cfg != nil {
+		return e.cfg.Transcode.FFmpeg.Binary()
+	}
+	return "ffmpeg"
+}
+
+// BuildArgs 生成 MP4 转码参数；inputCodec 为空时不指定硬件解码器
+func (e *FFmpegExecutor) BuildArgs(params vo.TranscodeParams, inputCodec, inputPath, outputPath string) []string {
 	cfg := e.cfg
 
 	videoCodec := "libx264"
 	videoPreset := "medium"
 	hardwareAccel := ""
+	hwDevice := ""
 	threads := 0
 	useHwDecode := false
 	decThreads := 0
 	decSurfaces := 0
-	inputCodec, _ := e.probeVideoCodec(inputPath)
 	if cfg != nil {
 		if strings.TrimSpace(cfg.Transcode.FFmpeg.VideoCodec) != "" {
 			videoCodec = cfg.Transcode.FFmpeg.VideoCodec
@@ -260,10 +485,11 @@ func (e *FFmpegExecutor) buildFFmpegCommand(ctx context.Context, task *entity.Tr
 		if strings.TrimSpace(cfg.Transcode.FFmpeg.HardwareAccel) != "" {
 			hardwareAccel = cfg.Transcode.FFmpeg.HardwareAccel
 		}
+		hwDevice = cfg.Transcode.FFmpeg.HardwareDevice
 		if cfg.Transcode.FFmpeg.Threads > 0 {
 			threads = cfg.Transcode.FFmpeg.Threads
 		}
-		useHwDecode = cfg.Transcode.FFmpeg.UseHardwareDecode && strings.EqualFold(hardwareAccel, "cuda")
+		useHwDecode = cfg.Transcode.FFmpeg.UseHardwareDecode
 		if cfg.Transcode.FFmpeg.DecoderThreads > 0 {
 			decThreads = cfg.Transcode.FFmpeg.DecoderThreads
 		}
@@ -271,53 +497,66 @@ func (e *FFmpegExecutor) buildFFmpegCommand(ctx context.Context, task *entity.Tr
 			decSurfaces = cfg.Transcode.FFmpeg.CuvidSurfaces
 		}
 	}
+	if p := params.Profile; p != nil {
+		// profile=auto 规则指定的编码器/预设优先
+		if p.VideoCodec != "" {
+			videoCodec = p.VideoCodec
+		}
+		if p.Preset != "" {
+			videoPreset = p.Preset
+		}
+	}
+	container := params.OutputContainer()
+	audioOnly := params.Streams.AudioOnly()
+	if audioOnly {
+		// 纯音频源不做视频编码，也不需要硬件解码
+		hardwareAccel = ""
+		useHwDecode = false
+	}
+	if !container.SupportsVideoCodec(videoCodec) {
+		// 封装不支持配置的编码器（如 webm + h264_nvenc），改走软件编码链路
+		videoCodec = container.DefaultVideoCodec()
+		hardwareAccel = ""
+		useHwDecode = false
+	}
+	// 已注册的后端只在编码器匹配时启用（软件编码器不能接收设备帧）；未注册的取值按通用 -hwaccel 透传
+	backend := hwaccel.Lookup(hardwareAccel)
+	if backend != nil && !backend.Encodes(videoCodec) {
+		backend = nil
+		hardwareAccel = ""
+	}
+	useHwDecode = useHwDecode && backend != nil && backend.Decodes()
 
 	args := make([]string, 0, 16)
 	if useHwDecode {
-		args = append(args, "-hwaccel", "cuda", "-hwaccel_output_format", "cuda")
-		switch strings.ToLower(inputCodec) {
-		case "h264", "avc1":
-			args = append(args, "-c:v", "h264_cuvid")
-		case "hevc", "hvc1", "hev1":
-			args = append(args, "-c:v", "hevc_cuvid")
-		default:
-		}
-		if decSurfaces > 0 {
-			args = append(args, "-surfaces", strconv.Itoa(decSurfaces))
-		}
-		if decThreads <= 0 {
-			decThreads = 1
-		}
-		args = append(args, "-threads", strconv.Itoa(decThreads))
+		args = append(args, backend.DecodeArgs(inputCodec, hwaccel.DecodeOptions{Device: hwDevice, Surfaces: decSurfaces, Threads: decThreads})...)
 		threads = 0
-	} else if hardwareAccel != "" && !strings.EqualFold(hardwareAccel, "cuda") {
+	} else if backend != nil {
+		args = append(args, backend.UploadArgs(hwDevice)...)
+	} else if hardwareAccel != "" {
 		args = append(args, "-hwaccel", hardwareAccel)
 	}
 	args = append(args,
 		"-probesize", "5M",
 		"-analyzeduration", "5M",
 		"-i", inputPath,
+	)
+	if params.Streams.SilentAudio() {
+		args = append(args, "-f", "lavfi", "-i", silentAudioSource)
+	}
+	args = append(args,
 		"-progress", "pipe:2",
 		"-nostats",
 	)
+	args = append(args, streamMapArgs(params)...)
 	baseArgs := (&params).GetFFmpegArgs(videoCodec, videoPreset)
-	isNvenc := strings.Contains(strings.ToLower(videoCodec), "nvenc")
-	if isNvenc {
-		filtered := make([]string, 0, len(baseArgs))
-		for i := 0; i < len(baseArgs); i++ {
-			if baseArgs[i] == "-crf" && i+1 < len(baseArgs) {
-				i++
-				continue
-			}
-			filtered = append(filtered, baseArgs[i])
-		}
-		baseArgs = filtered
+	if audioOnly {
+		baseArgs = nil
 	}
-	useCuda := strings.EqualFold(hardwareAccel, "cuda")
-	if useCuda {
+	if hwaccel.IsHardwareEncoder(videoCodec) {
 		filtered := make([]string, 0, len(baseArgs))
 		for i := 0; i < len(baseArgs); i++ {
-			if baseArgs[i] == "-s" && i+1 < len(baseArgs) {
+			if baseArgs[i] == "-crf" && i+1 < len(baseArgs) {
 				i++
 				continue
 			}
@@ -325,8 +564,6 @@ func (e *FFmpegExecutor) buildFFmpegCommand(ctx context.Context, task *entity.Tr
 		}
 		baseArgs = filtered
 	}
-	args = append(args, baseArgs...)
-
 	w, h := 0, 0
 	switch strings.TrimSpace(params.Resolution) {
 	case "480p":
@@ -340,55 +577,55 @@ func (e *FFmpegExecutor) buildFFmpegCommand(ctx context.Context, task *entity.Tr
 	case "2160p":
 		w, h = 3840, 2160
 	}
-	if useCuda && w > 0 && h > 0 {
-		if useHwDecode {
-			args = append(args, "-vf", fmt.Sprintf("scale_npp=%d:%d:format=yuv420p", w, h))
+	// 设备上缩放时去掉 -s，由后端滤镜完成缩放
+	scaleFilter := ""
+	if backend != nil && w > 0 && h > 0 {
+		scaleFilter = backend.ScaleFilter(w, h, useHwDecode)
+	}
+	if scaleFilter != "" {
+		filtered := make([]string, 0, len(baseArgs))
+		for i := 0; i < len(baseArgs); i++ {
+			if baseArgs[i] == "-s" && i+1 < len(baseArgs) {
+				i++
+				continue
+			}
+			filtered = append(filtered, baseArgs[i])
+		}
+		baseArgs = filtered
+	}
+	args = append(args, baseArgs...)
+	if !audioOnly && params.GOP != nil {
+		if params.GOP.SupportsCodec(videoCodec) {
+			args = append(args, params.GOP.EncoderArgs(videoCodec)...)
+			args = append(args, params.GOP.ForceKeyFramesArgs()...)
 		} else {
-			args = append(args, "-vf", fmt.Sprintf("hwupload_cuda,scale_npp=%d:%d:format=yuv420p", w, h))
+			logger.Warnf("gop options ignored, unsupported codec codec=%s resolution=%s", videoCodec, params.Resolution)
 		}
 	}
+
+	if scaleFilter != "" {
+		args = append(args, "-vf", scaleFilter)
+	}
 	if threads > 0 {
 		args = append(args, "-threads", strconv.Itoa(threads))
 	}
+	if params.Streams.NoAudio() {
+		args = append(args, "-an")
+	} else {
+		args = append(args,
+			"-c:a", container.AudioCodec(),
+			"-b:a", "128k",
+		)
+	}
+	args = append(args, container.MuxFlags()...)
+	if params.IsPreview() {
+		// 预览只输出前 N 秒
+		args = append(args, "-t", strconv.Itoa(params.PreviewSeconds))
+	}
 	args = append(args,
-		"-c:a", "aac",
-		"-b:a", "128k",
+		"-f", container.Muxer(),
 		"-y",
 		outputPath,
 	)
-
-	binary := "ffmpeg"
-	if cfg != nil && cfg.Transcode.FFmpeg.BinaryPath != "" {
-		binary = cfg.Transcode.FFmpeg.BinaryPath
-	}
-	return exec.CommandContext(ctx, binary, args...)
-}
-
-func (e *FFmpegExecutor) buildFileURL(objectKey string) string {
-	if strings.TrimSpace(objectKey) == "" {
-		return ""
-	}
-	cfg := e.cfg
-	if cfg == nil {
-		cfg = config.GetGlobalConfig()
-	}
-	if cfg == nil {
-		return objectKey
-	}
-
-	key := strings.TrimLeft(objectKey, "/")
-	if strings.HasPrefix(key, "transcode/") {
-		key = strings.TrimPrefix(key, "transcode/")
-	}
-
-	path := fmt.Sprintf("/storage/transcode/%s", key)
-	publicBase := strings.TrimSpace(cfg.Public.StorageBase)
-	if publicBase != "" {
-		if !strings.HasPrefix(publicBase, "http://") && !strings.HasPrefix(publicBase, "https://") {
-			publicBase = "http://" + publicBase
-		}
-		return strings.TrimRight(publicBase, "/") + path
-	}
-
-	return path
+	return args
 }
@@ -0,0 +1,85 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// inputLimits 任务所属租户生效的输入上限
+func (e *FFmpegExecutor) inputLimits(task *entity.TranscodeTaskEntity) config.InputLimitsConfig {
+	if e.cfg == nil {
+		return config.InputLimitsConfig{}
+	}
+	return e.cfg.Transcode.InputLimits.For(task.UserUUID())
+}
+
+// checkSourceSize 下载前按对象大小校验，超大文件不落盘；获取元数据失败时交给下载阶段报错
+func (e *FFmpegExecutor) checkSourceSize(ctx context.Context, task *entity.TranscodeTaskEntity) error {
+	limits := e.inputLimits(task)
+	if limits.MaxFileSize <= 0 || e.storage == nil {
+		return nil
+	}
+	info, err := e.storage.StatObject(ctx, task.OriginalPath())
+	if err != nil {
+		return nil
+	}
+	return rejectIfExceeded(task, "file_size", info.Size, limits.MaxFileSize)
+}
+
+// checkProbedLimits 探测后校验时长、分辨率与本地文件大小（存储未返回大小时兜底）
+func (e *FFmpegExecutor) checkProbedLimits(ctx context.Context, task *entity.TranscodeTaskEntity, inputPath string, durationSec float64) error {
+	limits := e.inputLimits(task)
+	if limits.MaxFileSize > 0 {
+		if fi, err := os.Stat(inputPath); err == nil {
+			if err := rejectIfExceeded(task, "file_size", fi.Size(), limits.MaxFileSize); err != nil {
+				return err
+			}
+		}
+	}
+	if limits.MaxDuration > 0 && durationSec > limits.MaxDuration.Seconds() {
+		return reject(task, "duration", fmt.Sprintf("duration %s exceeds limit %s",
+			time.Duration(durationSec*float64(time.Second)).Round(time.Second), limits.MaxDuration))
+	}
+	if limits.MaxWidth <= 0 && limits.MaxHeight <= 0 {
+		return nil
+	}
+	streams, err := e.probeStreams(ctx, inputPath)
+	if err != nil {
+		// 分辨率未知时不拒绝，后续流选择会再次探测并按原逻辑处理
+		logger.Warnf("probe resolution for input limits failed task_uuid=%s error=%v", task.TaskUUID(), err)
+		return nil
+	}
+	maxLong, maxShort := max(limits.MaxWidth, limits.MaxHeight), min(limits.MaxWidth, limits.MaxHeight)
+	for _, s := range streams {
+		if s.CodecType != "video" || s.Disposition.AttachedPic == 1 {
+			continue
+		}
+		long, short := max(s.Width, s.Height), min(s.Width, s.Height)
+		if (maxLong > 0 && long > maxLong) || (maxShort > 0 && short > maxShort) {
+			return reject(task, "resolution", fmt.Sprintf("resolution %dx%d exceeds limit %dx%d",
+				s.Width, s.Height, limits.MaxWidth, limits.MaxHeight))
+		}
+	}
+	return nil
+}
+
+func rejectIfExceeded(task *entity.TranscodeTaskEntity, kind string, size, limit int64) error {
+	if size <= limit {
+		return nil
+	}
+	return reject(task, kind, fmt.Sprintf("file size %d bytes exceeds limit %d bytes", size, limit))
+}
+
+func reject(task *entity.TranscodeTaskEntity, kind, reason string) error {
+	metrics.Add("input_rejected_"+kind+"_total", 1)
+	logger.Warnf("transcode input rejected task_uuid=%s user_uuid=%s limit=%s reason=%s", task.TaskUUID(), task.UserUUID(), kind, reason)
+	return fmt.Errorf("%w: %s", errno.ErrInputRejected, reason)
+}
@@ -0,0 +1,237 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"transcode-service/ddd/domain/port"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/logger"
+)
+
+const (
+	// clockTicks /proc/<pid>/stat 中 utime/stime 的单位（Linux USER_HZ 固定为 100）
+	clockTicks = 100
+	// gpuQueryTimeout 单次 nvidia-smi 查询超时
+	gpuQueryTimeout = 2 * time.Second
+)
+
+// progressStats ffmpeg -progress 输出的最新 fps/speed/out_time，供采样读取
+type progressStats struct {
+	mu         sync.Mutex
+	fps, speed float64
+	outTimeSec float64
+	advancedAt time.Time // out_time 最近一次推进的时间，卡死检测使用
+}
+
+// observe 解析一行 -progress 输出，返回是否为关注的字段
+func (p *progressStats) observe(line string) bool {
+	if p == nil {
+		return false
+	}
+	key, val, ok := strings.Cut(line, "=")
+	if !ok {
+		return false
+	}
+	val = strings.TrimSpace(val)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch key {
+	case "fps":
+		p.fps, _ = strconv.ParseFloat(val, 64)
+	case "speed":
+		p.speed, _ = strconv.ParseFloat(strings.TrimSuffix(val, "x"), 64)
+	case "out_time_ms":
+		if us, err := strconv.ParseFloat(val, 64); err == nil {
+			if sec := us / 1e6; sec > p.outTimeSec {
+				p.advancedAt = time.Now()
+				p.outTimeSec = sec
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// lastAdvance 最近一次进度推进的时间与当时的 out_time
+func (p *progressStats) lastAdvance() (time.Time, float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.advancedAt, p.outTimeSec
+}
+
+func (p *progressStats) snapshot() (fps, speed, outTimeSec float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.fps, p.speed, p.outTimeSec
+}
+
+// resourceSampler 按间隔采样 ffmpeg 进程，周期性与退出时通过回调交出时间序列
+type resourceSampler struct {
+	pid        int
+	interval   time.Duration
+	maxSamples int
+	flushEvery int
+	gpu        bool
+	progress   *progressStats
+	cb         port.ResourceTraceCallback
+
+	trace     *vo.ResourceTrace
+	lastTicks int64
+	lastAt    time.Time
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// startResourceSampler 采样未启用或没有回调时返回 nil
+func (e *FFmpegExecutor) startResourceSampler(cmd *exec.Cmd, label, requestID string, startedAt time.Time, progress *progressStats, cb port.ResourceTraceCallback) *resourceSampler {
+	if cb == nil || e.cfg == nil || !e.cfg.Transcode.Samples.Enabled || cmd.Process == nil {
+		return nil
+	}
+	c := e.cfg.Transcode.Samples
+	s := &resourceSampler{
+		pid:        cmd.Process.Pid,
+		interval:   c.Interval,
+		maxSamples: c.MaxSamples,
+		flushEvery: c.FlushEvery,
+		gpu:        e.encoderKind() == "nvenc",
+		progress:   progress,
+		cb:         cb,
+		trace: &vo.ResourceTrace{
+			Label:      label,
+			RequestID:  requestID,
+			Encoder:    e.encoderKind(),
+			StartedAt:  startedAt,
+			IntervalMs: c.Interval.Milliseconds(),
+		},
+		lastAt: startedAt,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *resourceSampler) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	taken := 0
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			if !s.sample(now) {
+				continue
+			}
+			taken++
+			if taken%s.flushEvery == 0 {
+				s.cb(s.trace.Clone())
+			}
+		}
+	}
+}
+
+// sample 采一次样；降采样后间隔加倍，未到新间隔时跳过
+func (s *resourceSampler) sample(now time.Time) bool {
+	elapsed := now.Sub(s.trace.StartedAt).Milliseconds()
+	if n := len(s.trace.Samples); n > 0 && elapsed-s.trace.Samples[n-1].ElapsedMs < s.trace.IntervalMs-s.interval.Milliseconds()/2 {
+		return false
+	}
+	smp := vo.ResourceSample{ElapsedMs: elapsed}
+	if ticks, err := readProcCPUTicks(s.pid); err == nil {
+		if wall := now.Sub(s.lastAt).Seconds(); wall > 0 {
+			smp.CPUPercent = float64(ticks-s.lastTicks) / clockTicks / wall * 100
+		}
+		s.lastTicks, s.lastAt = ticks, now
+	}
+	smp.RSSBytes = readProcRSS(s.pid)
+	if s.gpu {
+		smp.GPUUtil = queryGPUUtil()
+	}
+	if s.progress != nil {
+		smp.FPS, smp.Speed, smp.OutTimeSec = s.progress.snapshot()
+	}
+	s.trace.Append(smp, s.maxSamples)
+	return true
+}
+
+// finish 停止采样，记录退出信息并交出最终序列
+func (s *resourceSampler) finish(state *os.ProcessState, firstFrame time.Duration) {
+	if s == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	now := time.Now()
+	s.trace.ExitedAt = &now
+	s.trace.FirstFrameMs = firstFrame.Milliseconds()
+	if state != nil {
+		code := state.ExitCode()
+		s.trace.ExitCode = &code
+		s.trace.UserCPUMs = state.UserTime().Milliseconds()
+		s.trace.SysCPUMs = state.SystemTime().Milliseconds()
+		if ru, ok := state.SysUsage().(*syscall.Rusage); ok {
+			s.trace.MaxRSSBytes = ru.Maxrss * 1024
+		}
+	}
+	s.cb(s.trace.Clone())
+}
+
+// readProcCPUTicks 进程累计 utime+stime（clock ticks）
+func readProcCPUTicks(pid int) (int64, error) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return 0, err
+	}
+	// comm 字段可能含空格，从最后一个 ')' 之后按空格切分；utime/stime 为第 14/15 个字段
+	s := string(data)
+	fields := strings.Fields(s[strings.LastIndexByte(s, ')')+1:])
+	if len(fields) < 13 {
+		return 0, os.ErrInvalid
+	}
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+	return utime + stime, nil
+}
+
+// readProcRSS 进程常驻内存（字节），读取失败返回 0
+func readProcRSS(pid int) int64 {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/status")
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if v, ok := strings.CutPrefix(line, "VmRSS:"); ok {
+			kb, _ := strconv.ParseInt(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(v), "kB")), 10, 64)
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+// queryGPUUtil 首块 GPU 的利用率，nvidia-smi 不可用时返回 nil
+func queryGPUUtil() *float64 {
+	ctx, cancel := context.WithTimeout(context.Background(), gpuQueryTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "nvidia-smi", "--query-gpu=utilization.gpu", "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		logger.Debug(fmt.Sprintf("query gpu utilization failed error=%v", err))
+		return nil
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	v, err := strconv.ParseFloat(strings.TrimSpace(line), 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
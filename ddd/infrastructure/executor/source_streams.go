@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// silentAudioSource 补静音音轨时的第二路输入
+const silentAudioSource = "anullsrc=channel_layout=stereo:sample_rate=48000"
+
+// resolveSourceStreams 按探测到的流确定源文件内容与缺失流的处理方式并记录到任务；
+// 没有任何音视频流，或纯音频源被配置为拒绝时返回 rejected 错误
+func (e *FFmpegExecutor) resolveSourceStreams(task *entity.TranscodeTaskEntity, streams []probeStream) (*vo.SourceStreams, error) {
+	hasVideo, hasAudio := false, false
+	for _, s := range streams {
+		switch {
+		case s.CodecType == "video" && s.Disposition.AttachedPic != 1:
+			hasVideo = true
+		case s.CodecType == "audio":
+			hasAudio = true
+		}
+	}
+	res := &vo.SourceStreams{Content: vo.NewSourceContent(hasVideo, hasAudio)}
+	missingAudio, audioOnly := "silent", "audio"
+	if e.cfg != nil {
+		missingAudio, audioOnly = e.cfg.Transcode.SourceStreams.MissingAudio, e.cfg.Transcode.SourceStreams.AudioOnly
+	}
+	switch res.Content {
+	case vo.SourceNoStreams:
+		return nil, reject(task, "no_streams", "source contains neither video nor audio streams")
+	case vo.SourceAudioOnly:
+		if audioOnly == "reject" {
+			return nil, reject(task, "audio_only", "source contains no video stream")
+		}
+		res.Handling = vo.StreamHandlingAudioOnly
+	case vo.SourceVideoOnly:
+		res.Handling = vo.StreamHandlingSilentAudio
+		if missingAudio == "none" {
+			res.Handling = vo.StreamHandlingNoAudio
+		}
+	}
+	task.SetSourceStreams(res)
+	if res.Handling != "" {
+		metrics.Add("source_"+string(res.Content)+"_total", 1)
+		logger.Infof("source missing streams task_uuid=%s content=%s handling=%s", task.TaskUUID(), res.Content, res.Handling)
+	}
+	return res, nil
+}
+
+// streamMapArgs 按源文件内容生成输入映射：补静音音轨时映射第二路 anullsrc 输入，纯音频时只映射音频流
+func streamMapArgs(params vo.TranscodeParams) []string {
+	switch {
+	case params.Streams.SilentAudio():
+		video := "0:v:0"
+		if params.VideoStream != nil {
+			video = "0:" + streamIndexString(params.VideoStream)
+		}
+		return []string{"-map", video, "-map", "1:a:0", "-shortest"}
+	case params.Streams.AudioOnly():
+		audio := "0:a:0"
+		if params.AudioStream != nil {
+			audio = "0:" + streamIndexString(params.AudioStream)
+		}
+		return []string{"-map", audio, "-vn"}
+	}
+	return mapArgs(params)
+}
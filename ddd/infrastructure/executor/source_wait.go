@@ -0,0 +1,51 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// waitForSource 源文件尚未复制到位时按退避轮询 HEAD，直到对象出现或超过 max_wait。
+// 只等待 404；其他错误交给随后的下载按原有路径处理。返回实际等待时长，首次即存在时为 0
+func (e *FFmpegExecutor) waitForSource(ctx context.Context, taskUUID, objectKey string) (time.Duration, error) {
+	if e.storage == nil || e.cfg == nil || !e.cfg.Worker.WaitForSource.Enabled {
+		return 0, nil
+	}
+	cfg := e.cfg.Worker.WaitForSource
+	start := time.Now()
+	backoff := cfg.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		_, err := e.storage.StatObject(ctx, objectKey)
+		if err == nil || !gateway.IsObjectNotFound(err) {
+			if attempt == 1 {
+				return 0, nil
+			}
+			waited := time.Since(start)
+			metrics.Add("source_wait_total", 1)
+			metrics.ObserveDuration("source_wait_seconds", waited)
+			logger.Infof("source object available after wait task_uuid=%s object_key=%s waited=%s polls=%d", taskUUID, objectKey, waited.Round(time.Millisecond), attempt)
+			return waited, nil
+		}
+		waited := time.Since(start)
+		if waited+backoff > cfg.MaxWait {
+			metrics.Add("source_wait_timeouts_total", 1)
+			return waited, fmt.Errorf("source %s not available after %s: %w", objectKey, waited.Round(time.Second), err)
+		}
+		if attempt == 1 {
+			logger.Warnf("source object not found yet, waiting task_uuid=%s object_key=%s max_wait=%s", taskUUID, objectKey, cfg.MaxWait)
+		}
+		select {
+		case <-ctx.Done():
+			return time.Since(start), ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > cfg.MaxBackoff {
+			backoff = cfg.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package executor
+
+import (
+	"time"
+
+	"transcode-service/pkg/metrics"
+)
+
+// stallWindow 卡死判定窗口，未启用时返回 0
+func (e *FFmpegExecutor) stallWindow() time.Duration {
+	if e.cfg == nil || !e.cfg.Worker.StallWatchdog.Enabled {
+		return 0
+	}
+	return e.cfg.Worker.StallWatchdog.Window
+}
+
+// watchStall 按窗口的 1/10（至少 1s）检查 ffmpeg 进度，超过 window 未推进时关闭返回的通道；
+// window<=0 时返回 nil 通道，不会触发。stop 关闭后退出
+func watchStall(stop <-chan struct{}, stats *progressStats, window time.Duration, label string) <-chan struct{} {
+	if window <= 0 {
+		return nil
+	}
+	interval := max(window/10, time.Second)
+	stalled := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if at, _ := stats.lastAdvance(); time.Since(at) >= window {
+					metrics.Add("ffmpeg_stalls_total", 1)
+					metrics.Add("ffmpeg_stalls_"+label+"_total", 1)
+					close(stalled)
+					return
+				}
+			}
+		}
+	}()
+	return stalled
+}
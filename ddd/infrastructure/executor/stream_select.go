@@ -0,0 +1,186 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/errno"
+)
+
+// probeStream ffprobe 输出的单条流信息
+type probeStream struct {
+	Index       int    `json:"index"`
+	CodecType   string `json:"codec_type"`
+	CodecName   string `json:"codec_name"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Channels    int    `json:"channels"`
+	BitRate     string `json:"bit_rate"`
+	Disposition struct {
+		Default     int `json:"default"`
+		AttachedPic int `json:"attached_pic"`
+	} `json:"disposition"`
+	program int // 所属节目，-1 表示不属于任何节目
+}
+
+func (s probeStream) bitRate() int64 {
+	v, _ := strconv.ParseInt(s.BitRate, 10, 64)
+	return v
+}
+
+// streamSelection 解析后的输入流映射；字段为 nil 表示不映射该类型
+type streamSelection struct {
+	video      *int
+	audio      *int
+	videoCodec string
+}
+
+// probeStreams 探测全部流及其所属节目（多节目 MPEG-TS）
+func (e *FFmpegExecutor) probeStreams(ctx context.Context, inputPath string) ([]probeStream, error) {
+	out, err := e.runProbe(ctx,
+		"-v", "error",
+		"-probesize", "5M",
+		"-analyzeduration", "5M",
+		"-show_entries", "stream=index,codec_type,codec_name,width,height,channels,bit_rate:stream_disposition=default,attached_pic:program=program_id:program_stream=index",
+		"-of", "json",
+		inputPath,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Streams  []probeStream `json:"streams"`
+		Programs []struct {
+			ProgramID int `json:"program_id"`
+			Streams   []struct {
+				Index int `json:"index"`
+			} `json:"streams"`
+		} `json:"programs"`
+	}
+	if err := json.Unmarshal(out, &res); err != nil {
+		return nil, fmt.Errorf("%w: parse ffprobe streams: %v", errno.ErrProbeFailed, err)
+	}
+	program := make(map[int]int)
+	for _, p := range res.Programs {
+		for _, s := range p.Streams {
+			program[s.Index] = p.ProgramID
+		}
+	}
+	for i := range res.Streams {
+		res.Streams[i].program = -1
+		if id, ok := program[res.Streams[i].Index]; ok {
+			res.Streams[i].program = id
+		}
+	}
+	return res.Streams, nil
+}
+
+// selectStreams 显式指定的流须存在且类型匹配；未指定时选分辨率最高的非封面视频流，
+// 再在同一节目内选声道数/码率最高的音频流（默认流优先）。找不到主视频流时不做映射，沿用 ffmpeg 默认选流
+func selectStreams(streams []probeStream, params vo.TranscodeParams) (streamSelection, error) {
+	var sel streamSelection
+	var video *probeStream
+	if params.VideoStream != nil {
+		s := findStream(streams, *params.VideoStream)
+		if s == nil || s.CodecType != "video" {
+			return sel, fmt.Errorf("%w: video_stream_index=%d", errno.ErrInvalidStreamSelection, *params.VideoStream)
+		}
+		video = s
+	} else {
+		for i := range streams {
+			s := &streams[i]
+			if s.CodecType != "video" || s.Disposition.AttachedPic == 1 {
+				continue
+			}
+			if video == nil || betterVideo(s, video) {
+				video = s
+			}
+		}
+	}
+	if video == nil {
+		return sel, nil
+	}
+	sel.video = &video.Index
+	sel.videoCodec = video.CodecName
+
+	if params.AudioStream != nil {
+		s := findStream(streams, *params.AudioStream)
+		if s == nil || s.CodecType != "audio" {
+			return sel, fmt.Errorf("%w: audio_stream_index=%d", errno.ErrInvalidStreamSelection, *params.AudioStream)
+		}
+		sel.audio = &s.Index
+		return sel, nil
+	}
+	var audio *probeStream
+	for i := range streams {
+		s := &streams[i]
+		if s.CodecType != "audio" {
+			continue
+		}
+		if audio == nil || betterAudio(s, audio, video.program) {
+			audio = s
+		}
+	}
+	if audio != nil {
+		sel.audio = &audio.Index
+	}
+	return sel, nil
+}
+
+// selectAudioOnly 纯音频源的音频流：显式指定的须存在且为音频，否则按默认流、声道数、码率选择
+func selectAudioOnly(streams []probeStream, params vo.TranscodeParams) (*int, error) {
+	if params.AudioStream != nil {
+		s := findStream(streams, *params.AudioStream)
+		if s == nil || s.CodecType != "audio" {
+			return nil, fmt.Errorf("%w: audio_stream_index=%d", errno.ErrInvalidStreamSelection, *params.AudioStream)
+		}
+		return &s.Index, nil
+	}
+	var audio *probeStream
+	for i := range streams {
+		s := &streams[i]
+		if s.CodecType == "audio" && (audio == nil || betterAudio(s, audio, -1)) {
+			audio = s
+		}
+	}
+	if audio == nil {
+		return nil, nil
+	}
+	return &audio.Index, nil
+}
+
+func findStream(streams []probeStream, index int) *probeStream {
+	for i := range streams {
+		if streams[i].Index == index {
+			return &streams[i]
+		}
+	}
+	return nil
+}
+
+func betterVideo(a, b *probeStream) bool {
+	if pa, pb := a.Width*a.Height, b.Width*b.Height; pa != pb {
+		return pa > pb
+	}
+	if a.Disposition.Default != b.Disposition.Default {
+		return a.Disposition.Default > b.Disposition.Default
+	}
+	return a.bitRate() > b.bitRate()
+}
+
+// betterAudio 与主视频同节目优先，其次默认流、声道数、码率
+func betterAudio(a, b *probeStream, program int) bool {
+	if sa, sb := a.program == program, b.program == program; sa != sb {
+		return sa
+	}
+	if a.Disposition.Default != b.Disposition.Default {
+		return a.Disposition.Default > b.Disposition.Default
+	}
+	if a.Channels != b.Channels {
+		return a.Channels > b.Channels
+	}
+	return a.bitRate() > b.bitRate()
+}
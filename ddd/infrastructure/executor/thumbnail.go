@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"transcode-service/ddd/domain/vo"
+)
+
+// ExtractFrame 从 inputPath 的 spec.At 时刻精确截取一帧写入 outputPath。
+// -ss 放在 -i 前走关键帧快速定位，ffmpeg 随后解码到目标时间戳，结果仍精确到帧
+func (e *FFmpegExecutor) ExtractFrame(ctx context.Context, inputPath, outputPath string, spec vo.ThumbnailSpec) error {
+	args := thumbnailArgs(inputPath, outputPath, spec)
+	if out, err := exec.CommandContext(ctx, e.Binary(), args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("extract frame at %s: %w: %s", spec.At, err, lastLine(out))
+	}
+	// 时间戳超出时长时 ffmpeg 正常退出但不输出任何帧
+	if st, err := os.Stat(outputPath); err != nil || st.Size() == 0 {
+		return fmt.Errorf("extract frame at %s: no frame decoded, timestamp may exceed duration", spec.At)
+	}
+	return nil
+}
+
+func thumbnailArgs(inputPath, outputPath string, spec vo.ThumbnailSpec) []string {
+	args := []string{"-y", "-ss", strconv.FormatFloat(spec.At.Seconds(), 'f', 3, 64), "-i", inputPath, "-frames:v", "1", "-an", "-sn"}
+	if spec.Width > 0 || spec.Height > 0 {
+		args = append(args, "-vf", fmt.Sprintf("scale=%s:%s", scaleSide(spec.Width), scaleSide(spec.Height)))
+	}
+	if spec.Format == vo.ImageFormatWebP {
+		return append(args, "-c:v", "libwebp", "-quality", "85", "-f", "webp", outputPath)
+	}
+	return append(args, "-q:v", "2", "-f", "image2", outputPath)
+}
+
+// scaleSide 未指定的一边按比例缩放并取偶数
+func scaleSide(n int) string {
+	if n <= 0 {
+		return "-2"
+	}
+	return strconv.Itoa(n)
+}
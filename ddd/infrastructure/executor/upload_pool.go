@@ -0,0 +1,175 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/port"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/storage"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/manager"
+	"transcode-service/pkg/metrics"
+)
+
+// UploadPool 与编码槽位分离的有界上传池：ffmpeg 结束后产物交给上传协程，编码协程立即释放，
+// 存储变慢时只会积压上传队列，不会占住编码槽位
+type UploadPool struct {
+	cfg     config.UploadPoolConfig
+	storage gateway.StorageGateway
+	jobs    chan *uploadJob
+	mu      sync.RWMutex
+	closed  bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// uploadJob 一个待上传产物；release 在上传结束（成功或放弃）后清理工作目录
+type uploadJob struct {
+	taskUUID    string
+	localPath   string
+	objectKey   string
+	contentType string
+	stageCb     port.StageProgressCallback
+	done        port.UploadCallback
+	release     func()
+}
+
+// NewUploadPool 未启用时返回 nil，执行器在编码协程内同步上传
+func NewUploadPool(cfg *config.Config, storage gateway.StorageGateway) *UploadPool {
+	if cfg == nil || !cfg.Worker.UploadPool.Enabled || storage == nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &UploadPool{
+		cfg:     cfg.Worker.UploadPool,
+		storage: storage,
+		jobs:    make(chan *uploadJob, cfg.Worker.UploadPool.QueueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+func (p *UploadPool) Name() string { return "uploadPool" }
+
+// ShutdownPhase worker 排空后不再产生新产物，再上传剩余产物
+func (p *UploadPool) ShutdownPhase() manager.Phase { return manager.PhaseFlush }
+
+// InFlight 排队中的上传数
+func (p *UploadPool) InFlight() []string {
+	if n := len(p.jobs); n > 0 {
+		return []string{fmt.Sprintf("queued_uploads=%d", n)}
+	}
+	return nil
+}
+
+// Start 上传使用池自身的上下文，停机时先排空队列再退出
+func (p *UploadPool) Start(ctx context.Context) error {
+	for i := 0; i < p.cfg.Workers; i++ {
+		p.wg.Add(1)
+		go p.loop()
+	}
+	logger.Infof("upload pool started workers=%d queue_size=%d", p.cfg.Workers, p.cfg.QueueSize)
+	return nil
+}
+
+// Stop 不再接收新产物，等待已排队的上传完成；超过 drain_timeout 后中止剩余上传，
+// 以 port.ErrUploadAborted 回调，对应任务转为 retrying，由任一实例的重试恢复重新编码
+func (p *UploadPool) Stop() error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(p.cfg.DrainTimeout):
+		logger.Warnf("upload pool drain timeout, aborting remaining uploads pending=%d", len(p.jobs))
+		p.cancel()
+		<-done
+	}
+	p.cancel()
+	return nil
+}
+
+// submit 队列满或已停止时返回 false，由调用方同步上传
+func (p *UploadPool) submit(job *uploadJob) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return false
+	}
+	select {
+	case p.jobs <- job:
+		metrics.Set("upload_pool_queue_depth", int64(len(p.jobs)))
+		return true
+	default:
+		metrics.Add("upload_pool_full_total", 1)
+		return false
+	}
+}
+
+func (p *UploadPool) loop() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		metrics.Set("upload_pool_queue_depth", int64(len(p.jobs)))
+		p.run(job)
+	}
+}
+
+func (p *UploadPool) run(job *uploadJob) {
+	defer job.release()
+	if p.ctx.Err() != nil {
+		// 排空超时后仍在队列中的产物不再上传
+		p.abort(job, p.ctx.Err())
+		return
+	}
+	reportStage(job.stageCb, vo.StageUpload, 0)
+	var key string
+	var err error
+	for attempt := 0; ; attempt++ {
+		key, err = p.storage.UploadTranscodedFile(p.ctx, job.localPath, job.objectKey, job.contentType)
+		storage.DefaultHealthGate().Observe(err)
+		if err == nil || attempt >= p.cfg.Retries || p.ctx.Err() != nil {
+			break
+		}
+		metrics.Add("upload_pool_retries_total", 1)
+		logger.Warnf("upload pool retrying task_uuid=%s object_key=%s attempt=%d/%d error=%v", job.taskUUID, job.objectKey, attempt+1, p.cfg.Retries, err)
+		select {
+		case <-p.ctx.Done():
+		case <-time.After(time.Duration(attempt+1) * p.cfg.Backoff):
+		}
+	}
+	if err != nil && p.ctx.Err() != nil {
+		p.abort(job, err)
+		return
+	}
+	if err != nil {
+		metrics.Add("upload_pool_failures_total", 1)
+		logger.Errorf("upload pool gave up task_uuid=%s object_key=%s error=%v", job.taskUUID, job.objectKey, err)
+		job.done("", err)
+		return
+	}
+	metrics.Add("upload_pool_uploads_total", 1)
+	reportStage(job.stageCb, vo.StageUpload, 100)
+	job.done(key, nil)
+}
+
+// abort 停机中止的上传不计为失败，由任务服务安排重新编码
+func (p *UploadPool) abort(job *uploadJob, cause error) {
+	metrics.Add("upload_pool_aborted_total", 1)
+	logger.Warnf("upload pool aborted task_uuid=%s object_key=%s error=%v", job.taskUUID, job.objectKey, cause)
+	job.done("", fmt.Errorf("%w: %v", port.ErrUploadAborted, cause))
+}
@@ -0,0 +1,185 @@
+// Package failstats 按错误类别统计最近 24 小时的作业失败，并判断各类别是否呈上升趋势，
+// 供 /readyz 与指标展示，值班时一眼区分存储、编码器还是上游输入导致的失败
+package failstats
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/service"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/metrics"
+)
+
+// Class 失败类别
+type Class string
+
+const (
+	ClassStorage  Class = "storage"  // 对象存储不可达或 5xx
+	ClassEncoder  Class = "encoder"  // ffmpeg 非零退出、HLS 码流切片失败
+	ClassUpstream Class = "upstream" // 源文件缺失或无法解析、依赖服务返回错误
+	ClassTimeout  Class = "timeout"  // 执行超时
+	ClassStalled  Class = "stalled"  // ffmpeg 长时间无进度被终止
+	ClassInternal Class = "internal" // 其他
+)
+
+var classes = []Class{ClassStorage, ClassEncoder, ClassUpstream, ClassTimeout, ClassStalled, ClassInternal}
+
+const (
+	window = 24 * time.Hour
+	bucket = time.Hour
+	// 最近一小时失败数不少于 risingMinCount 且不低于前 23 小时小时均值的 risingFactor 倍时视为上升
+	risingMinCount = 5
+	risingFactor   = 2.0
+)
+
+// Classify 按错误链判断失败类别；err 为 nil 或取消时返回空串
+func Classify(err error) Class {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return ""
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ClassTimeout
+	}
+	if errors.Is(err, errno.ErrEncodeStalled) {
+		return ClassStalled
+	}
+	if gateway.IsStorageUnavailable(err) {
+		return ClassStorage
+	}
+	if gateway.IsObjectNotFound(err) || errors.Is(err, errno.ErrProbeFailed) || errors.Is(err, errno.ErrSourceDecryptFailed) {
+		return ClassUpstream
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) || errors.Is(err, service.ErrRenditionsFailed) {
+		return ClassEncoder
+	}
+	if st, ok := status.FromError(err); ok && st.Code() != codes.Unknown {
+		return ClassUpstream
+	}
+	return ClassInternal
+}
+
+// ClassCount 单个类别的统计
+type ClassCount struct {
+	Class    Class `json:"class"`
+	Count24h int64 `json:"count_24h"`
+	LastHour int64 `json:"last_hour"`
+	Rising   bool  `json:"rising"`
+}
+
+// Snapshot 最近 24 小时的失败分布（本实例，进程重启后清零）
+type Snapshot struct {
+	Total   int64        `json:"total"`
+	Rising  bool         `json:"rising"` // 任一类别呈上升趋势
+	Classes []ClassCount `json:"classes"`
+}
+
+// Tracker 按小时分桶的滑动窗口计数
+type Tracker struct {
+	mu      sync.Mutex
+	buckets map[Class][]int64 // 下标 0 为当前小时
+	current time.Time         // 当前小时的起点
+}
+
+var (
+	trackerOnce    sync.Once
+	defaultTracker *Tracker
+)
+
+// Default 进程内共享的失败统计
+func Default() *Tracker {
+	trackerOnce.Do(func() {
+		defaultTracker = NewTracker()
+	})
+	return defaultTracker
+}
+
+// NewTracker 创建失败统计
+func NewTracker() *Tracker {
+	t := &Tracker{buckets: map[Class][]int64{}, current: clock.Now().Truncate(bucket)}
+	for _, c := range classes {
+		t.buckets[c] = make([]int64, int(window/bucket))
+	}
+	return t
+}
+
+// Record 记录一次失败，取消不计入
+func (t *Tracker) Record(err error) {
+	class := Classify(err)
+	if class == "" {
+		return
+	}
+	t.mu.Lock()
+	t.advanceLocked(clock.Now())
+	t.buckets[class][0]++
+	t.mu.Unlock()
+	metrics.Add("job_failures_"+string(class)+"_total", 1)
+	t.Snapshot()
+}
+
+// Snapshot 当前的 24 小时分布与趋势，同时刷新 job_failures_24h_* 与 job_failures_rising 指标
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.advanceLocked(clock.Now())
+	snap := Snapshot{Classes: make([]ClassCount, 0, len(classes))}
+	for _, c := range classes {
+		b := t.buckets[c]
+		var total, previous int64
+		for i, n := range b {
+			total += n
+			if i > 0 {
+				previous += n
+			}
+		}
+		avg := float64(previous) / float64(len(b)-1)
+		cc := ClassCount{Class: c, Count24h: total, LastHour: b[0]}
+		cc.Rising = b[0] >= risingMinCount && float64(b[0]) >= risingFactor*avg
+		snap.Total += total
+		snap.Rising = snap.Rising || cc.Rising
+		snap.Classes = append(snap.Classes, cc)
+	}
+	sort.SliceStable(snap.Classes, func(i, j int) bool { return snap.Classes[i].Count24h > snap.Classes[j].Count24h })
+	rising := int64(0)
+	for _, c := range snap.Classes {
+		metrics.Set("job_failures_24h_"+string(c.Class), c.Count24h)
+		if c.Rising {
+			rising = 1
+		}
+	}
+	metrics.Set("job_failures_rising", rising)
+	return snap
+}
+
+// advanceLocked 跨过整点时把桶整体后移，超出 24 小时的计数丢弃
+func (t *Tracker) advanceLocked(now time.Time) {
+	hour := now.Truncate(bucket)
+	shift := int(hour.Sub(t.current) / bucket)
+	if shift <= 0 {
+		return
+	}
+	for _, c := range classes {
+		b := t.buckets[c]
+		if shift >= len(b) {
+			for i := range b {
+				b[i] = 0
+			}
+			continue
+		}
+		copy(b[shift:], b[:len(b)-shift])
+		for i := 0; i < shift; i++ {
+			b[i] = 0
+		}
+	}
+	t.current = hour
+}
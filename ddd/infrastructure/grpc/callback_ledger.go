@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"transcode-service/pkg/clock"
+)
+
+const (
+	// idempotencyMetadataKey 回调幂等键，下游据此识别重复的终态回调
+	idempotencyMetadataKey = "x-idempotency-key"
+	// callbackLedgerTTL 已发送回调的保留时长
+	callbackLedgerTTL = 24 * time.Hour
+	// callbackLedgerMaxEntries 超过后清理过期记录
+	callbackLedgerMaxEntries = 10000
+)
+
+// terminalCallbackStatuses 需要去重的终态状态
+var terminalCallbackStatuses = map[string]struct{}{
+	"published": {},
+	"completed": {},
+	"failed":    {},
+	"expired":   {},
+	"rejected":  {},
+}
+
+// IdempotencyKey 由 task_uuid + 终态状态 + 回调内容摘要组成；非终态返回空串。
+// 同一任务重新发布到新地址或失败原因变化时键不同，不会被当作重复回调
+func IdempotencyKey(taskUUID, status string, payload ...string) string {
+	s := strings.ToLower(strings.TrimSpace(status))
+	if taskUUID == "" {
+		return ""
+	}
+	if _, ok := terminalCallbackStatuses[s]; !ok {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join(payload, "\x00")))
+	return taskUUID + ":" + s + ":" + hex.EncodeToString(sum[:6])
+}
+
+// withIdempotencyKey 将幂等键写入 outgoing metadata
+func withIdempotencyKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, idempotencyMetadataKey, key)
+}
+
+// callbackLedger 本地已发送回调台账，用于抑制重试路径产生的重复终态回调。
+// 每个 (目标服务, 任务) 只保留最近一次成功的幂等键：状态来回变化（failed -> published -> failed）时不会抑制
+type callbackLedger struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]ledgerEntry
+}
+
+type ledgerEntry struct {
+	key string
+	at  time.Time
+}
+
+var (
+	callbackLedgerOnce      sync.Once
+	singletonCallbackLedger *callbackLedger
+)
+
+func defaultCallbackLedger() *callbackLedger {
+	callbackLedgerOnce.Do(func() {
+		singletonCallbackLedger = &callbackLedger{ttl: callbackLedgerTTL, entries: make(map[string]ledgerEntry)}
+	})
+	return singletonCallbackLedger
+}
+
+// Sent 判断目标服务最近一次成功接收的该任务回调是否就是这个幂等键
+func (l *callbackLedger) Sent(target, taskUUID, key string) bool {
+	if key == "" {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[target+"|"+taskUUID]
+	if !ok || e.key != key {
+		return false
+	}
+	if clock.Since(e.at) > l.ttl {
+		delete(l.entries, target+"|"+taskUUID)
+		return false
+	}
+	return true
+}
+
+// Record 记录回调已被目标服务成功接收，覆盖该任务之前的记录
+func (l *callbackLedger) Record(target, taskUUID, key string) {
+	if key == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) >= callbackLedgerMaxEntries {
+		l.evictLocked()
+	}
+	l.entries[target+"|"+taskUUID] = ledgerEntry{key: key, at: clock.Now()}
+}
+
+func (l *callbackLedger) evictLocked() {
+	now := clock.Now()
+	for k, e := range l.entries {
+		if now.Sub(e.at) > l.ttl {
+			delete(l.entries, k)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestLedger() *callbackLedger {
+	return &callbackLedger{ttl: time.Hour, entries: make(map[string]ledgerEntry)}
+}
+
+func TestIdempotencyKeyVersionsByPayload(t *testing.T) {
+	if IdempotencyKey("t1", "Progress50") != "" {
+		t.Fatal("milestones must not carry an idempotency key")
+	}
+	a := IdempotencyKey("t1", "published", "https://cdn/a/master.m3u8", "")
+	if a != IdempotencyKey("t1", "Published", "https://cdn/a/master.m3u8", "") {
+		t.Fatal("same callback should produce the same key")
+	}
+	if a == IdempotencyKey("t1", "published", "https://cdn/b/master.m3u8", "") {
+		t.Fatal("republishing to a new url must produce a new key")
+	}
+}
+
+func TestCallbackLedgerSuppressesOnlyExactRepeat(t *testing.T) {
+	l := newTestLedger()
+	failed := IdempotencyKey("t1", "failed", "", "encode error")
+	published := IdempotencyKey("t1", "published", "https://cdn/a.mp4", "")
+
+	l.Record("upload-service", "t1", failed)
+	if !l.Sent("upload-service", "t1", failed) {
+		t.Fatal("repeated failure callback should be suppressed")
+	}
+	if l.Sent("video-service", "t1", failed) {
+		t.Fatal("ledger must be per target service")
+	}
+	if l.Sent("upload-service", "t1", published) {
+		t.Fatal("published after failed must be sent")
+	}
+	l.Record("upload-service", "t1", published)
+	if l.Sent("upload-service", "t1", failed) {
+		t.Fatal("failed -> published -> failed must send the second failure")
+	}
+}
@@ -40,3 +40,33 @@ func (r *dualResultReporter) ReportFailure(ctx context.Context, videoUUID, taskU
 	logger.WithContext(ctx).Warnf("transcode result failure reported video_uuid=%s task_uuid=%s error=%s", videoUUID, taskUUID, errorMessage)
 	return nil
 }
+
+func (r *dualResultReporter) ReportExpired(ctx context.Context, videoUUID, taskUUID, reason string) error {
+	if r.upload != nil {
+		_, _ = r.upload.UpdateTranscodeStatus(ctx, videoUUID, taskUUID, uploadStatusExpired, "", reason)
+	}
+	if r.video != nil {
+		_, _ = r.video.UpdateTranscodeResult(ctx, videoUUID, taskUUID, "expired", "", reason, 0, 0)
+	}
+	logger.WithContext(ctx).Warnf("transcode result expired reported video_uuid=%s task_uuid=%s reason=%s", videoUUID, taskUUID, reason)
+	return nil
+}
+
+func (r *dualResultReporter) ReportRejected(ctx context.Context, videoUUID, taskUUID, reason string) error {
+	if r.upload != nil {
+		_, _ = r.upload.UpdateTranscodeStatus(ctx, videoUUID, taskUUID, uploadStatusRejected, "", reason)
+	}
+	if r.video != nil {
+		_, _ = r.video.UpdateTranscodeResult(ctx, videoUUID, taskUUID, "rejected", "", reason, 0, 0)
+	}
+	logger.WithContext(ctx).Warnf("transcode result rejected reported video_uuid=%s task_uuid=%s reason=%s", videoUUID, taskUUID, reason)
+	return nil
+}
+
+func (r *dualResultReporter) ReportMilestone(ctx context.Context, videoUUID, taskUUID, milestone string) error {
+	// 里程碑只用于 upload-service 的进度展示，video-service 仅关心终态
+	if r.upload != nil {
+		_, _ = r.upload.UpdateTranscodeStatus(ctx, videoUUID, taskUUID, uploadMilestoneStatus(milestone), "", "")
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"transcode-service/pkg/grpcutil"
+	"transcode-service/pkg/logger"
+)
+
+const (
+	defaultHealthCheckInterval = 15 * time.Second
+	// 连续失败达到阈值后标记为不健康，由健康检查恢复
+	unhealthyThreshold = 2
+)
+
+// endpoint 下游服务的单个实例
+type endpoint struct {
+	address  string
+	conn     *grpc.ClientConn
+	healthy  bool
+	failures int
+}
+
+// endpointPool 维护下游服务的多个实例，按健康状态选择调用目标并在失败时切换
+type endpointPool struct {
+	name      string
+	timeout   time.Duration
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+}
+
+func newEndpointPool(name string, addresses []string, timeout time.Duration) *endpointPool {
+	p := &endpointPool{name: name, timeout: timeout, stopCh: make(chan struct{})}
+	seen := make(map[string]struct{}, len(addresses))
+	for _, addr := range addresses {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		p.endpoints = append(p.endpoints, &endpoint{address: addr, healthy: true})
+	}
+	return p
+}
+
+// Addresses 返回全部实例地址
+func (p *endpointPool) Addresses() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]string, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		out = append(out, ep.address)
+	}
+	return out
+}
+
+// candidates 健康实例轮询排在前面，不健康实例作为兜底排在后面
+func (p *endpointPool) candidates() []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := len(p.endpoints)
+	healthy := make([]*endpoint, 0, n)
+	unhealthy := make([]*endpoint, 0, n)
+	for i := 0; i < n; i++ {
+		ep := p.endpoints[(p.next+i)%n]
+		if ep.healthy {
+			healthy = append(healthy, ep)
+		} else {
+			unhealthy = append(unhealthy, ep)
+		}
+	}
+	if n > 0 {
+		p.next = (p.next + 1) % n
+	}
+	return append(healthy, unhealthy...)
+}
+
+func (p *endpointPool) connFor(ep *endpoint) (*grpc.ClientConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ep.conn != nil {
+		return ep.conn, nil
+	}
+	// 非阻塞拨号，连接状态由健康检查与调用结果反映
+	conn, err := grpc.Dial(
+		ep.address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(grpcutil.UnaryClientRequestIDInterceptor),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", p.name, err)
+	}
+	ep.conn = conn
+	return conn, nil
+}
+
+func (p *endpointPool) markResult(ep *endpoint, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		if !ep.healthy {
+			logger.Infof("%s endpoint recovered address=%s", p.name, ep.address)
+		}
+		ep.healthy = true
+		ep.failures = 0
+		return
+	}
+	ep.failures++
+	if ep.healthy && ep.failures >= unhealthyThreshold {
+		ep.healthy = false
+		logger.Warnf("%s endpoint marked unhealthy address=%s error=%v", p.name, ep.address, err)
+	}
+}
+
+// invoke 依次尝试候选实例直到成功；业务层返回的响应不视为实例故障
+func (p *endpointPool) invoke(ctx context.Context, call func(ctx context.Context, conn *grpc.ClientConn) error) error {
+	cands := p.candidates()
+	if len(cands) == 0 {
+		return fmt.Errorf("%s has no configured address", p.name)
+	}
+	var lastErr error
+	for _, ep := range cands {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		conn, err := p.connFor(ep)
+		if err != nil {
+			lastErr = err
+			p.markResult(ep, err)
+			continue
+		}
+		callCtx, cancel := context.WithTimeout(ctx, p.timeout)
+		err = call(callCtx, conn)
+		cancel()
+		if err == nil || !isEndpointFailure(err) {
+			p.markResult(ep, nil)
+			return err
+		}
+		lastErr = err
+		p.markResult(ep, err)
+		logger.Warnf("%s call failed, trying next endpoint address=%s error=%v", p.name, ep.address, err)
+	}
+	return lastErr
+}
+
+// isEndpointFailure 仅 Unavailable（连接失败、实例下线，请求未被处理）触发切换实例；
+// 超时、取消与 Unknown 时请求可能已在下游执行，切换会让非幂等调用（如回调、发布）重复执行
+func isEndpointFailure(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// startHealthCheck 周期性探测各实例；下游未实现 health 服务时以连接状态判断
+func (p *endpointPool) startHealthCheck(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stopCh:
+				return
+			case <-ticker.C:
+				p.checkAll()
+			}
+		}
+	}()
+}
+
+func (p *endpointPool) checkAll() {
+	p.mu.Lock()
+	eps := append([]*endpoint(nil), p.endpoints...)
+	p.mu.Unlock()
+	for _, ep := range eps {
+		conn, err := p.connFor(ep)
+		if err != nil {
+			p.markResult(ep, err)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		cancel()
+		if err == nil && resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			err = fmt.Errorf("health status %s", resp.GetStatus().String())
+		}
+		if status.Code(err) == codes.Unimplemented {
+			err = nil
+			if conn.GetState() == connectivity.TransientFailure {
+				err = fmt.Errorf("connection in transient failure")
+			}
+		}
+		p.markResult(ep, err)
+	}
+}
+
+// Close 停止健康检查并关闭全部连接
+func (p *endpointPool) Close() error {
+	p.stopOnce.Do(func() { close(p.stopCh) })
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var firstErr error
+	for _, ep := range p.endpoints {
+		if ep.conn != nil {
+			if err := ep.conn.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			ep.conn = nil
+		}
+	}
+	return firstErr
+}
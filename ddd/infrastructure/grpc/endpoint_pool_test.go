@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsEndpointFailureOnlyUnavailable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{status.Error(codes.Unavailable, "connection refused"), true},
+		{status.Error(codes.DeadlineExceeded, "timeout"), false},
+		{status.Error(codes.Canceled, "canceled"), false},
+		{status.Error(codes.Unknown, "handler error"), false},
+		{status.Error(codes.Internal, "internal"), false},
+		{status.Error(codes.InvalidArgument, "bad request"), false},
+		{context.DeadlineExceeded, false},
+		{errors.New("plain"), false},
+	}
+	for _, tc := range cases {
+		if got := isEndpointFailure(tc.err); got != tc.want {
+			t.Errorf("isEndpointFailure(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package grpc
+
+import (
+	"fmt"
+
+	"transcode-service/pkg/manager"
+)
+
+func init() {
+	// 回调客户端为可选依赖：地址缺失时降级启动，结果回调失败由回调台账与对账补偿
+	manager.RegisterResourcePlugin(&callbackClientPlugin{name: "videoServiceClient", open: func() []string {
+		return DefaultVideoServiceClient().pool.Addresses()
+	}, close: func() { _ = DefaultVideoServiceClient().Close() }})
+	manager.RegisterResourcePlugin(&callbackClientPlugin{name: "uploadServiceClient", open: func() []string {
+		return DefaultUploadServiceClient().pool.Addresses()
+	}, close: func() { _ = DefaultUploadServiceClient().Close() }})
+}
+
+// callbackClientPlugin 把上游回调客户端纳入启动依赖图，启动时校验已解析到实例地址
+type callbackClientPlugin struct {
+	name  string
+	open  func() []string
+	close func()
+}
+
+func (p *callbackClientPlugin) Name() string                         { return p.name }
+func (p *callbackClientPlugin) Criticality() manager.Criticality     { return manager.Optional }
+func (p *callbackClientPlugin) MustCreateResource() manager.Resource { return p }
+
+func (p *callbackClientPlugin) MustOpen() {
+	if len(p.open()) == 0 {
+		panic(fmt.Sprintf("%s has no addresses configured", p.name))
+	}
+}
+
+func (p *callbackClientPlugin) Close() { p.close() }
@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	keyproviderpb "transcode-service/api/keyprovider"
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+	"transcode-service/pkg/sourcecrypt"
+)
+
+var (
+	keyProviderClientOnce      sync.Once
+	singletonKeyProviderClient *KeyProviderClient
+)
+
+// KeyProviderClient 源文件数据密钥服务客户端，契约见 api/keyprovider/key_provider.proto
+type KeyProviderClient struct {
+	pool *endpointPool
+}
+
+// DefaultKeyProvider 未开启 dependencies.key_provider 时返回 nil
+func DefaultKeyProvider() gateway.KeyProvider {
+	if c := DefaultKeyProviderClient(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DefaultKeyProviderClient 获取默认的密钥服务客户端（单例模式），未开启时返回 nil
+func DefaultKeyProviderClient() *KeyProviderClient {
+	keyProviderClientOnce.Do(func() {
+		cfg := config.GetGlobalConfig()
+		if cfg == nil || !cfg.Dependencies.KeyProvider.Enabled {
+			return
+		}
+		kp := cfg.Dependencies.KeyProvider
+		addresses := resolveAddresses(kp.Addresses, kp.Address, kp.Host, kp.Port, kp.ServiceName, kp.Port)
+		timeout := kp.Timeout
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		pool := newEndpointPool("key-provider", addresses, timeout)
+		pool.startHealthCheck(cfg.GRPCClient.HealthCheckInterval)
+		logger.Infof("key-provider client initialised addresses=%v", pool.Addresses())
+		singletonKeyProviderClient = &KeyProviderClient{pool: pool}
+	})
+	return singletonKeyProviderClient
+}
+
+// DataKey 解封数据密钥；密钥不落日志
+func (c *KeyProviderClient) DataKey(ctx context.Context, req gateway.DataKeyRequest) ([]byte, error) {
+	in := &keyproviderpb.GetDataKeyRequest{ObjectKey: req.ObjectKey, KeyId: req.KeyID, WrappedKey: req.WrappedKey}
+	var resp *keyproviderpb.GetDataKeyResponse
+	err := c.pool.invoke(ctx, func(callCtx context.Context, conn *grpc.ClientConn) error {
+		r, err := keyproviderpb.NewKeyProviderClient(conn).GetDataKey(callCtx, in)
+		if err != nil {
+			return err
+		}
+		resp = r
+		return nil
+	})
+	if err != nil {
+		metrics.Add("key_provider_failures_total", 1)
+		logger.Errorf("GetDataKey failed object_key=%s key_id=%s error=%v", req.ObjectKey, req.KeyID, err)
+		return nil, err
+	}
+	if len(resp.GetDataKey()) == 0 {
+		metrics.Add("key_provider_failures_total", 1)
+		return nil, fmt.Errorf("GetDataKey returned an empty data_key key_id=%s", req.KeyID)
+	}
+	// 复制到新切片交给调用方清零，响应中的副本随即清零
+	key := append([]byte(nil), resp.GetDataKey()...)
+	sourcecrypt.Zero(resp.DataKey)
+	metrics.Add("key_provider_requests_total", 1)
+	return key, nil
+}
+
+// Close 关闭gRPC连接
+func (c *KeyProviderClient) Close() error {
+	return c.pool.Close()
+}
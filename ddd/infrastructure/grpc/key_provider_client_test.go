@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	keyproviderpb "transcode-service/api/keyprovider"
+	"transcode-service/ddd/domain/gateway"
+)
+
+// fakeKeyProvider 回显请求并返回固定数据密钥
+type fakeKeyProvider struct {
+	keyproviderpb.UnimplementedKeyProviderServer
+	got     *keyproviderpb.GetDataKeyRequest
+	dataKey []byte
+}
+
+func (s *fakeKeyProvider) GetDataKey(_ context.Context, req *keyproviderpb.GetDataKeyRequest) (*keyproviderpb.GetDataKeyResponse, error) {
+	s.got = req
+	return &keyproviderpb.GetDataKeyResponse{DataKey: s.dataKey}, nil
+}
+
+func startKeyProvider(t *testing.T, srv *fakeKeyProvider) *KeyProviderClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	s := grpc.NewServer()
+	keyproviderpb.RegisterKeyProviderServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return &KeyProviderClient{pool: newEndpointPool("key-provider", []string{lis.Addr().String()}, 5*time.Second)}
+}
+
+func TestKeyProviderClientDataKey(t *testing.T) {
+	srv := &fakeKeyProvider{dataKey: bytes.Repeat([]byte{7}, 32)}
+	c := startKeyProvider(t, srv)
+
+	key, err := c.DataKey(context.Background(), gateway.DataKeyRequest{ObjectKey: "videos/a.mp4", KeyID: "k1", WrappedKey: []byte("wrapped")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, srv.dataKey) {
+		t.Fatalf("data key = %x, want %x", key, srv.dataKey)
+	}
+	if srv.got.GetObjectKey() != "videos/a.mp4" || srv.got.GetKeyId() != "k1" || string(srv.got.GetWrappedKey()) != "wrapped" {
+		t.Fatalf("request = %v", srv.got)
+	}
+}
+
+func TestKeyProviderClientRejectsEmptyKey(t *testing.T) {
+	c := startKeyProvider(t, &fakeKeyProvider{})
+	if _, err := c.DataKey(context.Background(), gateway.DataKeyRequest{KeyID: "k1"}); err == nil {
+		t.Fatal("empty data_key should fail")
+	}
+}
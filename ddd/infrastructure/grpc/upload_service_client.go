@@ -8,11 +8,9 @@ import (
 
 	uploadpb "github.com/jiangqiao2/go-video-proto/proto/upload/upload"
 	"transcode-service/pkg/config"
-	"transcode-service/pkg/grpcutil"
 	"transcode-service/pkg/logger"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 var (
@@ -20,12 +18,10 @@ var (
 	singletonUploadServiceClient *UploadServiceClient
 )
 
-// UploadServiceClient gRPC客户端
+// UploadServiceClient gRPC客户端，支持多实例健康检查与故障切换
 type UploadServiceClient struct {
-	client  uploadpb.UploadServiceClient
-	conn    *grpc.ClientConn
+	pool    *endpointPool
 	timeout time.Duration
-	address string
 }
 
 // DefaultUploadServiceClient 获取默认的gRPC客户端（单例模式）
@@ -37,7 +33,8 @@ func DefaultUploadServiceClient() *UploadServiceClient {
 			return
 		}
 
-		address := resolveAddress(
+		addresses := resolveAddresses(
+			cfg.Dependencies.UploadService.Addresses,
 			cfg.Dependencies.UploadService.Address,
 			cfg.Dependencies.UploadService.Host,
 			cfg.Dependencies.UploadService.Port,
@@ -53,52 +50,16 @@ func DefaultUploadServiceClient() *UploadServiceClient {
 			timeout = 30 * time.Second
 		}
 
-		client := &UploadServiceClient{
-			timeout: timeout,
-			address: address,
-		}
-
-		if err := client.connect(); err != nil {
-			logger.Fatal(fmt.Sprintf("failed to connect upload-service: %v", err))
-			return
-		}
-
-		singletonUploadServiceClient = client
+		pool := newEndpointPool("upload-service", addresses, timeout)
+		pool.startHealthCheck(cfg.GRPCClient.HealthCheckInterval)
+		logger.Infof("upload-service client initialised addresses=%v", pool.Addresses())
+		singletonUploadServiceClient = &UploadServiceClient{pool: pool, timeout: timeout}
 	})
 	return singletonUploadServiceClient
 }
 
-// connect 连接到upload-service
-func (c *UploadServiceClient) connect() error {
-	if c.address == "" {
-		return fmt.Errorf("upload-service address is empty")
-	}
-
-	logger.Infof("Connecting to upload-service address=%s", c.address)
-
-	conn, err := grpc.Dial(c.address,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-		grpc.WithTimeout(c.timeout),
-		grpc.WithChainUnaryInterceptor(grpcutil.UnaryClientRequestIDInterceptor),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to dial upload-service: %w", err)
-	}
-
-	c.conn = conn
-	c.client = uploadpb.NewUploadServiceClient(conn)
-
-	logger.Infof("Connected to upload-service address=%s", c.address)
-	return nil
-}
-
 // UpdateTranscodeStatus 调用上传服务更新转码状态
 func (c *UploadServiceClient) UpdateTranscodeStatus(ctx context.Context, videoUUID, transcodeTaskUUID, status, videoURL, errorMessage string) (*uploadpb.UpdateTranscodeStatusResponse, error) {
-	if c.client == nil {
-		return nil, fmt.Errorf("upload service client not initialised")
-	}
-
 	req := &uploadpb.UpdateTranscodeStatusRequest{
 		VideoUuid:         videoUUID,
 		TranscodeTaskUuid: transcodeTaskUUID,
@@ -107,24 +68,50 @@ func (c *UploadServiceClient) UpdateTranscodeStatus(ctx context.Context, videoUU
 		ErrorMessage:      errorMessage,
 	}
 
-	// 创建带超时的上下文
-	grpcCtx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
+	key := IdempotencyKey(transcodeTaskUUID, status, videoURL, errorMessage)
+	ledger := defaultCallbackLedger()
+	if ledger.Sent("upload-service", transcodeTaskUUID, key) {
+		logger.Infof("skip duplicate upload-service callback idempotency_key=%s video_uuid=%s", key, videoUUID)
+		return &uploadpb.UpdateTranscodeStatusResponse{Success: true, Message: "duplicate callback suppressed"}, nil
+	}
+	ctx = withIdempotencyKey(ctx, key)
 
-	resp, err := c.client.UpdateTranscodeStatus(grpcCtx, req)
+	var resp *uploadpb.UpdateTranscodeStatusResponse
+	err := c.pool.invoke(ctx, func(callCtx context.Context, conn *grpc.ClientConn) error {
+		r, err := uploadpb.NewUploadServiceClient(conn).UpdateTranscodeStatus(callCtx, req)
+		if err != nil {
+			return err
+		}
+		resp = r
+		return nil
+	})
 	if err != nil {
 		logger.Errorf("UpdateTranscodeStatus failed video_uuid=%s task_uuid=%s status=%s error=%v", videoUUID, transcodeTaskUUID, status, err)
 		return nil, err
 	}
+	if resp.GetSuccess() {
+		ledger.Record("upload-service", transcodeTaskUUID, key)
+	}
 	return resp, nil
 }
 
 // Close 关闭gRPC连接
 func (c *UploadServiceClient) Close() error {
-	if c.conn != nil {
-		return c.conn.Close()
+	return c.pool.Close()
+}
+
+// resolveAddresses 优先使用 addresses 列表，否则回退到单地址解析
+func resolveAddresses(addresses []string, addr, host string, port int, serviceName string, defaultPort int) []string {
+	out := make([]string, 0, len(addresses)+1)
+	for _, a := range addresses {
+		if a != "" {
+			out = append(out, a)
+		}
+	}
+	if len(out) > 0 {
+		return out
 	}
-	return nil
+	return append(out, resolveAddress(addr, host, port, serviceName, defaultPort))
 }
 
 func resolveAddress(addr, host string, port int, serviceName string, defaultPort int) string {
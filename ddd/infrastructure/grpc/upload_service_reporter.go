@@ -3,6 +3,7 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"transcode-service/pkg/logger"
 
@@ -12,8 +13,21 @@ import (
 const (
 	uploadStatusPublished = "Published"
 	uploadStatusFailed    = "Failed"
+	uploadStatusExpired   = "Expired"
+	uploadStatusRejected  = "Rejected"
 )
 
+// uploadMilestoneStatus 里程碑对应的 upload-service 状态，如 progress_25 -> Progress25、hls_started -> HLSStarted
+func uploadMilestoneStatus(milestone string) string {
+	if milestone == gateway.MilestoneHLSStarted {
+		return "HLSStarted"
+	}
+	if pct, ok := strings.CutPrefix(milestone, "progress_"); ok {
+		return "Progress" + pct
+	}
+	return milestone
+}
+
 type uploadServiceReporter struct {
 	client *UploadServiceClient
 }
@@ -74,3 +88,60 @@ func (r *uploadServiceReporter) ReportFailure(ctx context.Context, videoUUID, ta
 	}
 	return nil
 }
+
+func (r *uploadServiceReporter) ReportExpired(ctx context.Context, videoUUID, taskUUID, reason string) error {
+	if r.client == nil {
+		return fmt.Errorf("upload service client is not initialised")
+	}
+	if reason == "" {
+		reason = "transcode task expired"
+	}
+
+	resp, err := r.client.UpdateTranscodeStatus(ctx, videoUUID, taskUUID, uploadStatusExpired, "", reason)
+	if err != nil {
+		logger.Errorf("ReportExpired failed video_uuid=%s task_uuid=%s error=%v", videoUUID, taskUUID, err)
+		return err
+	}
+	if resp == nil || !resp.GetSuccess() {
+		logger.Errorf("ReportExpired resp.success is false message=%s", resp.GetMessage())
+		return fmt.Errorf("upload-service returned failure: %s", resp.GetMessage())
+	}
+	return nil
+}
+
+func (r *uploadServiceReporter) ReportRejected(ctx context.Context, videoUUID, taskUUID, reason string) error {
+	if r.client == nil {
+		return fmt.Errorf("upload service client is not initialised")
+	}
+	if reason == "" {
+		reason = "transcode input rejected"
+	}
+
+	resp, err := r.client.UpdateTranscodeStatus(ctx, videoUUID, taskUUID, uploadStatusRejected, "", reason)
+	if err != nil {
+		logger.Errorf("ReportRejected failed video_uuid=%s task_uuid=%s error=%v", videoUUID, taskUUID, err)
+		return err
+	}
+	if resp == nil || !resp.GetSuccess() {
+		logger.Errorf("ReportRejected resp.success is false message=%s", resp.GetMessage())
+		return fmt.Errorf("upload-service returned failure: %s", resp.GetMessage())
+	}
+	return nil
+}
+
+func (r *uploadServiceReporter) ReportMilestone(ctx context.Context, videoUUID, taskUUID, milestone string) error {
+	if r.client == nil {
+		return fmt.Errorf("upload service client is not initialised")
+	}
+
+	status := uploadMilestoneStatus(milestone)
+	resp, err := r.client.UpdateTranscodeStatus(ctx, videoUUID, taskUUID, status, "", "")
+	if err != nil {
+		logger.Warnf("ReportMilestone failed video_uuid=%s task_uuid=%s status=%s error=%v", videoUUID, taskUUID, status, err)
+		return err
+	}
+	if resp == nil || !resp.GetSuccess() {
+		return fmt.Errorf("upload-service returned failure: %s", resp.GetMessage())
+	}
+	return nil
+}
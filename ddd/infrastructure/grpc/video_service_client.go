@@ -8,11 +8,9 @@ import (
 
 	videopb "github.com/jiangqiao2/go-video-proto/proto/video/video"
 	"transcode-service/pkg/config"
-	"transcode-service/pkg/grpcutil"
 	"transcode-service/pkg/logger"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 var (
@@ -21,10 +19,8 @@ var (
 )
 
 type VideoServiceClient struct {
-	client  videopb.VideoServiceClient
-	conn    *grpc.ClientConn
+	pool    *endpointPool
 	timeout time.Duration
-	address string
 }
 
 func DefaultVideoServiceClient() *VideoServiceClient {
@@ -34,7 +30,8 @@ func DefaultVideoServiceClient() *VideoServiceClient {
 			logger.Fatal("global config is not initialised")
 			return
 		}
-		address := resolveAddress(
+		addresses := resolveAddresses(
+			cfg.Dependencies.VideoService.Addresses,
 			cfg.Dependencies.VideoService.Address,
 			cfg.Dependencies.VideoService.Host,
 			cfg.Dependencies.VideoService.Port,
@@ -48,51 +45,15 @@ func DefaultVideoServiceClient() *VideoServiceClient {
 		if timeout <= 0 {
 			timeout = 30 * time.Second
 		}
-		client := &VideoServiceClient{timeout: timeout, address: address}
-		if err := client.connect(); err != nil {
-			logger.Warnf("failed to connect video-service address=%s error=%s", address, err.Error())
-		}
-		singletonVideoServiceClient = client
+		pool := newEndpointPool("video-service", addresses, timeout)
+		pool.startHealthCheck(cfg.GRPCClient.HealthCheckInterval)
+		logger.Infof("video-service client initialised addresses=%v", pool.Addresses())
+		singletonVideoServiceClient = &VideoServiceClient{pool: pool, timeout: timeout}
 	})
 	return singletonVideoServiceClient
 }
 
-func (c *VideoServiceClient) connect() error {
-	if c.address == "" {
-		return fmt.Errorf("video-service address is empty")
-	}
-	logger.Infof("Connecting to video-service address=%s", c.address)
-	conn, err := grpc.Dial(
-		c.address,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithBlock(),
-		grpc.WithTimeout(c.timeout),
-		grpc.WithChainUnaryInterceptor(grpcutil.UnaryClientRequestIDInterceptor),
-	)
-	if err != nil {
-		return fmt.Errorf("dial video-service: %w", err)
-	}
-	c.conn = conn
-	c.client = videopb.NewVideoServiceClient(conn)
-	logger.Infof("Connected to video-service address=%s", c.address)
-	return nil
-}
-
-func (c *VideoServiceClient) reconnect() error {
-	if c.conn != nil {
-		_ = c.conn.Close()
-	}
-	logger.Infof("Reconnecting to video-service address=%s", c.address)
-	return c.connect()
-}
-
 func (c *VideoServiceClient) UpdateTranscodeResult(ctx context.Context, videoUUID, taskUUID, status, videoURL, errMsg string, durationSec int32, sizeBytes int64) (*videopb.UpdateTranscodeResultResponse, error) {
-	if c.client == nil {
-		if err := c.connect(); err != nil {
-			logger.Errorf("video-service init failed address=%s video_uuid=%s task_uuid=%s error=%v", c.address, videoUUID, taskUUID, err)
-			return nil, fmt.Errorf("video service unavailable: %w", err)
-		}
-	}
 	req := &videopb.UpdateTranscodeResultRequest{
 		VideoUuid:   videoUUID,
 		TaskUuid:    taskUUID,
@@ -102,26 +63,34 @@ func (c *VideoServiceClient) UpdateTranscodeResult(ctx context.Context, videoUUI
 		DurationSec: durationSec,
 		SizeBytes:   sizeBytes,
 	}
-	grpcCtx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-	logger.Infof("calling video-service UpdateTranscodeResult address=%s status=%s video_uuid=%s task_uuid=%s url=%s", c.address, status, videoUUID, taskUUID, videoURL)
-	resp, err := c.client.UpdateTranscodeResult(grpcCtx, req)
-	if err != nil {
-		logger.Warnf("video-service UpdateTranscodeResult error address=%s video_uuid=%s task_uuid=%s error=%v", c.address, videoUUID, taskUUID, err)
-		if c.reconnect() == nil {
-			logger.Infof("retrying video-service UpdateTranscodeResult address=%s video_uuid=%s task_uuid=%s", c.address, videoUUID, taskUUID)
-			resp, err = c.client.UpdateTranscodeResult(grpcCtx, req)
+	key := IdempotencyKey(taskUUID, status, videoURL, errMsg)
+	ledger := defaultCallbackLedger()
+	if ledger.Sent("video-service", taskUUID, key) {
+		logger.Infof("skip duplicate video-service callback idempotency_key=%s video_uuid=%s", key, videoUUID)
+		return &videopb.UpdateTranscodeResultResponse{Success: true, Message: "duplicate callback suppressed"}, nil
+	}
+	ctx = withIdempotencyKey(ctx, key)
+	logger.Infof("calling video-service UpdateTranscodeResult status=%s video_uuid=%s task_uuid=%s url=%s", status, videoUUID, taskUUID, videoURL)
+	var resp *videopb.UpdateTranscodeResultResponse
+	err := c.pool.invoke(ctx, func(callCtx context.Context, conn *grpc.ClientConn) error {
+		r, err := videopb.NewVideoServiceClient(conn).UpdateTranscodeResult(callCtx, req)
+		if err != nil {
+			return err
 		}
+		resp = r
+		return nil
+	})
+	if err != nil {
+		logger.Warnf("video-service UpdateTranscodeResult error video_uuid=%s task_uuid=%s error=%v", videoUUID, taskUUID, err)
+		return nil, fmt.Errorf("video service unavailable: %w", err)
 	}
-	if err == nil && resp != nil {
-		logger.Infof("video-service UpdateTranscodeResult done success=%v message=%s video_uuid=%s task_uuid=%s", resp.GetSuccess(), resp.GetMessage(), videoUUID, taskUUID)
+	if resp.GetSuccess() {
+		ledger.Record("video-service", taskUUID, key)
 	}
-	return resp, err
+	logger.Infof("video-service UpdateTranscodeResult done success=%v message=%s video_uuid=%s task_uuid=%s", resp.GetSuccess(), resp.GetMessage(), videoUUID, taskUUID)
+	return resp, nil
 }
 
 func (c *VideoServiceClient) Close() error {
-	if c.conn != nil {
-		return c.conn.Close()
-	}
-	return nil
+	return c.pool.Close()
 }
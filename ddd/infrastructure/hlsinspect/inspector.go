@@ -0,0 +1,357 @@
+package hlsinspect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/service"
+	"transcode-service/ddd/infrastructure/workspace"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/utils"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+
+	// DefaultSamples 每路码流抽查关键帧的切片数
+	DefaultSamples = 3
+	MaxSamples     = 10
+
+	masterPlaylistName = "master.m3u8"
+	// durationTolerance 非末尾切片时长与配置切片时长的允许偏差（秒）
+	durationTolerance = 0.5
+	// alignTolerance 不同码流同序号切片的时长/起始 PTS 允许偏差（秒），约一帧
+	alignTolerance = 0.05
+)
+
+// Issue 诊断发现的问题；Segment 为切片序号，-1 表示不针对具体切片
+type Issue struct {
+	Severity  string `json:"severity"`
+	Rendition string `json:"rendition,omitempty"`
+	Segment   int    `json:"segment"`
+	Message   string `json:"message"`
+}
+
+// KeyframeSample 单个切片的关键帧抽查结果
+type KeyframeSample struct {
+	Segment            int     `json:"segment"`
+	StartsWithKeyframe bool    `json:"starts_with_keyframe"`
+	StartPTS           float64 `json:"start_pts"`
+	Error              string  `json:"error,omitempty"`
+}
+
+// Rendition 单路码流的统计
+type Rendition struct {
+	Playlist          string           `json:"playlist"`
+	Bandwidth         int              `json:"bandwidth,omitempty"`
+	Resolution        string           `json:"resolution,omitempty"`
+	TargetDuration    int              `json:"target_duration"`
+	SegmentCount      int              `json:"segment_count"`
+	TotalSeconds      float64          `json:"total_seconds"`
+	MinSegmentSeconds float64          `json:"min_segment_seconds"`
+	MaxSegmentSeconds float64          `json:"max_segment_seconds"`
+	Discontinuities   int              `json:"discontinuities"`
+	EndList           bool             `json:"end_list"`
+	Keyframes         []KeyframeSample `json:"keyframes,omitempty"`
+
+	media mediaPlaylist
+}
+
+// Report HLS 作业诊断报告；Passed 表示没有 error 级别问题
+type Report struct {
+	JobUUID         string       `json:"job_uuid"`
+	MasterPlaylist  string       `json:"master_playlist"`
+	SegmentDuration int          `json:"segment_duration"`
+	Passed          bool         `json:"passed"`
+	CheckedAt       time.Time    `json:"checked_at"`
+	DurationMs      int64        `json:"duration_ms"`
+	Renditions      []*Rendition `json:"renditions"`
+	Issues          []Issue      `json:"issues"`
+}
+
+func (r *Report) add(severity, rendition string, seg int, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Severity: severity, Rendition: rendition, Segment: seg, Message: fmt.Sprintf(format, args...)})
+}
+
+// Inspector 下载已发布的 HLS 播放列表，检查切片时长、不连续标记与各码流关键帧对齐
+type Inspector struct {
+	cfg     *config.Config
+	storage gateway.StorageGateway
+}
+
+func NewInspector(cfg *config.Config, storage gateway.StorageGateway) *Inspector {
+	if cfg == nil {
+		cfg = config.GetGlobalConfig()
+	}
+	return &Inspector{cfg: cfg, storage: storage}
+}
+
+// Inspect 生成诊断报告；master playlist 无法下载时返回错误，单路码流的问题记录在报告中
+func (i *Inspector) Inspect(ctx context.Context, job *entity.HLSJobEntity, samples int) (*Report, error) {
+	if samples <= 0 {
+		samples = DefaultSamples
+	}
+	if samples > MaxSamples {
+		samples = MaxSamples
+	}
+	prefix := service.HLSObjectKeyPrefix(i.cfg, job)
+	report := &Report{
+		JobUUID:         job.JobUUID(),
+		MasterPlaylist:  path.Join(prefix, masterPlaylistName),
+		SegmentDuration: job.GetConfig().SegmentDuration,
+		CheckedAt:       time.Now(),
+		Issues:          []Issue{},
+	}
+	ws, err := workspace.DefaultManager().Acquire(fmt.Sprintf("hlsinspect-%s-%d", job.JobUUID(), time.Now().UnixNano()))
+	if err != nil {
+		return nil, err
+	}
+	defer ws.Release()
+
+	masterData, err := i.fetch(ctx, ws, report.MasterPlaylist)
+	if err != nil {
+		return nil, fmt.Errorf("download master playlist %s: %w", report.MasterPlaylist, err)
+	}
+	variants := parseMaster(masterData)
+	if len(variants) == 0 {
+		report.add(SeverityError, "", -1, "master playlist has no #EXT-X-STREAM-INF variants")
+	}
+	for _, v := range variants {
+		r := &Rendition{Playlist: v.URI, Bandwidth: v.Bandwidth, Resolution: v.Resolution}
+		if strings.Contains(v.URI, "://") {
+			report.add(SeverityWarning, v.URI, -1, "absolute variant URI is not checked")
+			continue
+		}
+		data, err := i.fetch(ctx, ws, path.Join(prefix, v.URI))
+		if err != nil {
+			report.add(SeverityError, v.URI, -1, "download variant playlist: %v", err)
+			continue
+		}
+		r.media = parseMedia(data)
+		i.checkRendition(report, r)
+		report.Renditions = append(report.Renditions, r)
+	}
+	checkAlignment(report)
+	for _, r := range report.Renditions {
+		i.sampleKeyframes(ctx, ws, report, prefix, r, samples)
+	}
+	checkKeyframeAlignment(report)
+
+	report.Passed = true
+	for _, is := range report.Issues {
+		if is.Severity == SeverityError {
+			report.Passed = false
+			break
+		}
+	}
+	report.DurationMs = time.Since(report.CheckedAt).Milliseconds()
+	logger.Infof("hls inspect finished job_uuid=%s renditions=%d issues=%d passed=%v", job.JobUUID(), len(report.Renditions), len(report.Issues), report.Passed)
+	return report, nil
+}
+
+// checkRendition 单路码流：EXTINF 不得超过 TARGETDURATION，非末尾切片应接近配置切片时长
+func (i *Inspector) checkRendition(report *Report, r *Rendition) {
+	pl := r.media
+	r.TargetDuration = pl.TargetDuration
+	r.SegmentCount = len(pl.Segments)
+	r.Discontinuities = pl.Discontinuities
+	r.EndList = pl.EndList
+	if len(pl.Segments) == 0 {
+		report.add(SeverityError, r.Playlist, -1, "playlist has no segments")
+		return
+	}
+	r.MinSegmentSeconds = math.MaxFloat64
+	offTarget, firstOff := 0, -1
+	for idx, s := range pl.Segments {
+		r.TotalSeconds += s.Duration
+		r.MinSegmentSeconds = math.Min(r.MinSegmentSeconds, s.Duration)
+		r.MaxSegmentSeconds = math.Max(r.MaxSegmentSeconds, s.Duration)
+		if pl.TargetDuration > 0 && math.Round(s.Duration) > float64(pl.TargetDuration) {
+			report.add(SeverityError, r.Playlist, idx, "segment duration %.3fs exceeds EXT-X-TARGETDURATION %d", s.Duration, pl.TargetDuration)
+		}
+		if s.Discontinuity {
+			report.add(SeverityWarning, r.Playlist, idx, "EXT-X-DISCONTINUITY before segment")
+		}
+		last := idx == len(pl.Segments)-1
+		if !last && report.SegmentDuration > 0 && math.Abs(s.Duration-float64(report.SegmentDuration)) > durationTolerance {
+			if offTarget++; firstOff < 0 {
+				firstOff = idx
+			}
+		}
+	}
+	if offTarget > 0 {
+		report.add(SeverityWarning, r.Playlist, firstOff, "%d non-final segments deviate more than %.1fs from configured %ds (first at segment %d)",
+			offTarget, durationTolerance, report.SegmentDuration, firstOff)
+	}
+	if pl.TargetDuration <= 0 {
+		report.add(SeverityError, r.Playlist, -1, "missing EXT-X-TARGETDURATION")
+	}
+	if !pl.EndList {
+		report.add(SeverityWarning, r.Playlist, -1, "missing EXT-X-ENDLIST for VOD playlist")
+	}
+}
+
+// checkAlignment 不同码流的切片数与同序号切片时长应一致，否则切换码流时播放器会跳帧或卡顿
+func checkAlignment(report *Report) {
+	if len(report.Renditions) < 2 {
+		return
+	}
+	ref := report.Renditions[0]
+	for _, r := range report.Renditions[1:] {
+		if len(r.media.Segments) != len(ref.media.Segments) {
+			report.add(SeverityError, r.Playlist, -1, "segment count %d differs from %s (%d)", len(r.media.Segments), ref.Playlist, len(ref.media.Segments))
+		}
+		n := len(r.media.Segments)
+		if len(ref.media.Segments) < n {
+			n = len(ref.media.Segments)
+		}
+		for idx := 0; idx < n; idx++ {
+			a, b := ref.media.Segments[idx].Duration, r.media.Segments[idx].Duration
+			if math.Abs(a-b) > alignTolerance {
+				report.add(SeverityError, r.Playlist, idx, "segment duration %.3fs differs from %s (%.3fs); boundaries are misaligned from here", b, ref.Playlist, a)
+				break
+			}
+		}
+	}
+}
+
+// sampleIndexes 均匀抽取首、中、尾等切片序号
+func sampleIndexes(count, samples int) []int {
+	if count <= 0 {
+		return nil
+	}
+	if samples >= count {
+		samples = count
+	}
+	seen := make(map[int]struct{}, samples)
+	var out []int
+	for k := 0; k < samples; k++ {
+		idx := 0
+		if samples > 1 {
+			idx = k * (count - 1) / (samples - 1)
+		}
+		if _, ok := seen[idx]; !ok {
+			seen[idx] = struct{}{}
+			out = append(out, idx)
+		}
+	}
+	return out
+}
+
+// sampleKeyframes 下载抽样切片，检查首帧是否为关键帧并记录起始 PTS
+func (i *Inspector) sampleKeyframes(ctx context.Context, ws *workspace.Workspace, report *Report, prefix string, r *Rendition, samples int) {
+	for _, idx := range sampleIndexes(len(r.media.Segments), samples) {
+		s := KeyframeSample{Segment: idx}
+		uri := r.media.Segments[idx].URI
+		local := ws.Path("segments", utils.LocalFileName(r.Playlist), utils.LocalFileName(uri))
+		if err := i.storage.DownloadFile(ctx, path.Join(prefix, path.Dir(r.Playlist), uri), local); err != nil {
+			s.Error = err.Error()
+			report.add(SeverityError, r.Playlist, idx, "download segment %s: %v", uri, err)
+		} else if key, pts, err := i.probeFirstFrame(ctx, local); err != nil {
+			s.Error = err.Error()
+			report.add(SeverityWarning, r.Playlist, idx, "probe segment %s: %v", uri, err)
+		} else {
+			s.StartsWithKeyframe, s.StartPTS = key, pts
+			if !key {
+				report.add(SeverityError, r.Playlist, idx, "segment %s does not start with a keyframe", uri)
+			}
+		}
+		_ = os.Remove(local)
+		r.Keyframes = append(r.Keyframes, s)
+	}
+}
+
+// checkKeyframeAlignment 同序号切片在各码流的起始 PTS 应一致
+func checkKeyframeAlignment(report *Report) {
+	byIndex := make(map[int][]float64)
+	for _, r := range report.Renditions {
+		for _, k := range r.Keyframes {
+			if k.Error == "" {
+				byIndex[k.Segment] = append(byIndex[k.Segment], k.StartPTS)
+			}
+		}
+	}
+	indexes := make([]int, 0, len(byIndex))
+	for idx := range byIndex {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	for _, idx := range indexes {
+		pts := byIndex[idx]
+		if len(pts) < 2 {
+			continue
+		}
+		lo, hi := pts[0], pts[0]
+		for _, p := range pts[1:] {
+			lo, hi = math.Min(lo, p), math.Max(hi, p)
+		}
+		if hi-lo > alignTolerance {
+			report.add(SeverityError, "", idx, "segment start PTS differs across renditions by %.3fs (%.3f..%.3f)", hi-lo, lo, hi)
+		}
+	}
+}
+
+// probeFirstFrame 读取首个视频帧的关键帧标记与 PTS
+func (i *Inspector) probeFirstFrame(ctx context.Context, localPath string) (bool, float64, error) {
+	binary, timeout := "ffprobe", 30*time.Second
+	if i.cfg != nil {
+		binary = i.cfg.Transcode.FFprobe.Binary()
+		if i.cfg.Transcode.FFprobe.Timeout > 0 {
+			timeout = i.cfg.Transcode.FFprobe.Timeout
+		}
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	out, err := exec.CommandContext(probeCtx, binary,
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-read_intervals", "%+#1",
+		"-show_entries", "frame=key_frame,pts_time,best_effort_timestamp_time",
+		"-of", "json",
+		localPath,
+	).Output()
+	if err != nil {
+		return false, 0, err
+	}
+	var res struct {
+		Frames []struct {
+			KeyFrame int    `json:"key_frame"`
+			PTSTime  string `json:"pts_time"`
+			BestTime string `json:"best_effort_timestamp_time"`
+		} `json:"frames"`
+	}
+	if err := json.Unmarshal(out, &res); err != nil {
+		return false, 0, err
+	}
+	if len(res.Frames) == 0 {
+		return false, 0, fmt.Errorf("no video frame")
+	}
+	f := res.Frames[0]
+	ts := f.PTSTime
+	if ts == "" || ts == "N/A" {
+		ts = f.BestTime
+	}
+	pts, _ := strconv.ParseFloat(ts, 64)
+	return f.KeyFrame == 1, pts, nil
+}
+
+func (i *Inspector) fetch(ctx context.Context, ws *workspace.Workspace, key string) ([]byte, error) {
+	local := ws.Path("playlists", utils.LocalFileName(key))
+	if err := i.storage.DownloadFile(ctx, key, local); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(local)
+}
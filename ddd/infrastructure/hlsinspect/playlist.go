@@ -0,0 +1,117 @@
+package hlsinspect
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// variant master playlist 中的一路码流
+type variant struct {
+	URI        string
+	Bandwidth  int
+	Resolution string
+}
+
+// mediaPlaylist 解析后的媒体播放列表
+type mediaPlaylist struct {
+	TargetDuration  int
+	Segments        []segment
+	Discontinuities int
+	EndList         bool
+}
+
+type segment struct {
+	URI      string
+	Duration float64
+	// Discontinuity 该切片前是否有 #EXT-X-DISCONTINUITY
+	Discontinuity bool
+}
+
+func parseMaster(data []byte) []variant {
+	var out []variant
+	var pending *variant
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF:"):
+			v := variant{}
+			for k, val := range parseAttributes(strings.TrimPrefix(line, "#EXT-X-STREAM-INF:")) {
+				switch k {
+				case "BANDWIDTH":
+					v.Bandwidth, _ = strconv.Atoi(val)
+				case "RESOLUTION":
+					v.Resolution = val
+				}
+			}
+			pending = &v
+		case strings.HasPrefix(line, "#"):
+		default:
+			if pending != nil {
+				pending.URI = line
+				out = append(out, *pending)
+				pending = nil
+			}
+		}
+	}
+	return out
+}
+
+func parseMedia(data []byte) mediaPlaylist {
+	var pl mediaPlaylist
+	var dur float64
+	disc := false
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXT-X-TARGETDURATION:"):
+			pl.TargetDuration, _ = strconv.Atoi(strings.TrimPrefix(line, "#EXT-X-TARGETDURATION:"))
+		case strings.HasPrefix(line, "#EXTINF:"):
+			v := strings.TrimPrefix(line, "#EXTINF:")
+			if i := strings.IndexByte(v, ','); i >= 0 {
+				v = v[:i]
+			}
+			dur, _ = strconv.ParseFloat(strings.TrimSpace(v), 64)
+		case line == "#EXT-X-DISCONTINUITY":
+			pl.Discontinuities++
+			disc = true
+		case line == "#EXT-X-ENDLIST":
+			pl.EndList = true
+		case strings.HasPrefix(line, "#"):
+		default:
+			pl.Segments = append(pl.Segments, segment{URI: line, Duration: dur, Discontinuity: disc})
+			dur, disc = 0, false
+		}
+	}
+	return pl
+}
+
+// parseAttributes 解析 KEY=VALUE,KEY="VALUE" 形式的属性列表，引号内的逗号不分割
+func parseAttributes(s string) map[string]string {
+	attrs := make(map[string]string)
+	inQuote := false
+	start := 0
+	flush := func(part string) {
+		if k, v, ok := strings.Cut(part, "="); ok {
+			attrs[strings.TrimSpace(k)] = strings.Trim(strings.TrimSpace(v), `"`)
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			inQuote = !inQuote
+		case ',':
+			if !inQuote {
+				flush(s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	flush(s[start:])
+	return attrs
+}
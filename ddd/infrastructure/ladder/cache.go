@@ -0,0 +1,132 @@
+// Package ladder 输出档位、档位集合与自动档位规则的进程内缓存。
+// 档位目录由 gateway.LadderSource 提供（当前为配置文件，后续为 profiles API 的数据库表），
+// 按 TTL 重新加载；管理接口失效时经 Redis 频道通知其他实例，每个任务取档位只读内存
+package ladder
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/service"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+var (
+	cacheOnce    sync.Once
+	defaultCache *Cache
+)
+
+// Default 进程内共享的档位缓存，来源为配置文件
+func Default() *Cache {
+	cacheOnce.Do(func() {
+		var cfg config.LadderCacheConfig
+		if c := config.GetGlobalConfig(); c != nil {
+			cfg = c.Transcode.LadderCache
+		}
+		defaultCache = NewCache(configSource{}, cfg.TTL)
+	})
+	return defaultCache
+}
+
+// configSource 从当前全局配置读取档位目录
+type configSource struct{}
+
+func (configSource) LoadLadderCatalog(context.Context) (*gateway.LadderCatalog, error) {
+	return gateway.LadderCatalogFromConfig(config.GetGlobalConfig()), nil
+}
+
+// Stats 缓存状态
+type Stats struct {
+	Version       string    `json:"version"`
+	LoadedAt      time.Time `json:"loaded_at"`
+	TTLSeconds    float64   `json:"ttl_seconds"`
+	Stale         bool      `json:"stale"`
+	Reloads       int64     `json:"reloads"`
+	ReloadErrors  int64     `json:"reload_errors"`
+	Invalidations int64     `json:"invalidations"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+// Cache 实现 gateway.LadderCatalogProvider：过期或被失效后由首个调用方重新加载，
+// 加载失败时继续使用上一份目录，避免来源抖动导致建任务失败
+type Cache struct {
+	source gateway.LadderSource
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	catalog *gateway.LadderCatalog
+	expires time.Time
+	stats   Stats
+}
+
+// NewCache 创建档位缓存，ttl<=0 时为 60s
+func NewCache(source gateway.LadderSource, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = 60 * time.Second
+	}
+	return &Cache{source: source, ttl: ttl}
+}
+
+// LadderCatalog 返回当前档位目录；来源从未加载成功时返回 nil，调用方按未配置处理
+func (c *Cache) LadderCatalog(ctx context.Context) *gateway.LadderCatalog {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.catalog != nil && clock.Now().Before(c.expires) {
+		return c.catalog
+	}
+	c.reloadLocked(ctx)
+	return c.catalog
+}
+
+// Invalidate 立即失效，下一次读取重新加载
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	c.expires = time.Time{}
+	c.stats.Invalidations++
+	c.mu.Unlock()
+	metrics.Add("ladder_cache_invalidations_total", 1)
+}
+
+// Stats 缓存状态
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.TTLSeconds = c.ttl.Seconds()
+	s.Stale = c.catalog == nil || !clock.Now().Before(c.expires)
+	return s
+}
+
+func (c *Cache) reloadLocked(ctx context.Context) {
+	catalog, err := c.source.LoadLadderCatalog(ctx)
+	if err == nil && catalog != nil {
+		// 档位的 GOP 配置互斥或编码器不支持时拒绝整份目录
+		err = service.ValidateCatalogGOP(catalog)
+	}
+	if err != nil || catalog == nil {
+		c.stats.ReloadErrors++
+		metrics.Add("ladder_cache_reload_errors_total", 1)
+		if err != nil {
+			c.stats.LastError = err.Error()
+		}
+		// 失败后短暂退避，避免每个任务都打到来源
+		c.expires = clock.Now().Add(min(c.ttl, 5*time.Second))
+		logger.Warnf("ladder catalog reload failed, keeping previous catalog version=%s error=%v", c.stats.Version, err)
+		return
+	}
+	if catalog.LoadedAt.IsZero() {
+		catalog.LoadedAt = clock.Now()
+	}
+	c.catalog = catalog
+	c.expires = clock.Now().Add(c.ttl)
+	c.stats.Reloads++
+	c.stats.Version = catalog.Version
+	c.stats.LoadedAt = catalog.LoadedAt
+	c.stats.LastError = ""
+	metrics.Add("ladder_cache_reloads_total", 1)
+}
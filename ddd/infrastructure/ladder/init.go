@@ -0,0 +1,29 @@
+package ladder
+
+import (
+	"transcode-service/pkg/manager"
+	"transcode-service/pkg/task"
+)
+
+func init() {
+	manager.RegisterComponentPlugin(&CacheComponentPlugin{})
+}
+
+// CacheComponentPlugin 启动档位缓存的失效通知订阅
+type CacheComponentPlugin struct{}
+
+func (p *CacheComponentPlugin) Name() string { return "ladderCacheComponent" }
+
+func (p *CacheComponentPlugin) MustCreateComponent(deps *manager.Dependencies) manager.Component {
+	return &cacheComponent{}
+}
+
+type cacheComponent struct{}
+
+func (c *cacheComponent) Start() error {
+	task.Register(newListener(Default()))
+	return nil
+}
+
+func (c *cacheComponent) Stop() error     { return nil }
+func (c *cacheComponent) GetName() string { return "ladderCache" }
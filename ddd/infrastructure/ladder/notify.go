@@ -0,0 +1,138 @@
+package ladder
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"transcode-service/internal/resource"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// channel 失效通知的 Redis 频道
+func channel() string {
+	if cfg := config.GetGlobalConfig(); cfg != nil && cfg.Transcode.LadderCache.Channel != "" {
+		return cfg.Transcode.LadderCache.Channel
+	}
+	return "transcode:ladders:invalidate"
+}
+
+// Invalidation 失效结果，Broadcast 为 false 时其他实例仍按 TTL 过期
+type Invalidation struct {
+	Broadcast bool  `json:"broadcast"`
+	Stats     Stats `json:"stats"`
+}
+
+// pendingBroadcast Redis 不可用期间未能发出的失效通知，恢复后补发一次
+var pendingBroadcast atomic.Bool
+
+// Broadcast 失效本实例缓存并通知其他实例；Redis 不可用时只失效本实例并在恢复后补发，返回是否已广播
+func Broadcast(ctx context.Context, reason string) bool {
+	Default().Invalidate()
+	cli := resource.DefaultRedisResource().Client()
+	if cli == nil {
+		pendingBroadcast.Store(true)
+		metrics.Add("ladder_cache_broadcast_deferred_total", 1)
+		logger.Warnf("redis unavailable, ladder cache invalidation deferred until redis recovers reason=%s", reason)
+		return false
+	}
+	if err := cli.Publish(ctx, channel(), reason).Err(); err != nil {
+		pendingBroadcast.Store(true)
+		metrics.Add("ladder_cache_broadcast_errors_total", 1)
+		logger.Warnf("ladder cache invalidation broadcast failed channel=%s error=%v", channel(), err)
+		return false
+	}
+	return true
+}
+
+// resync Redis 恢复后补发积压的失效通知
+func resync(ctx context.Context) {
+	if !pendingBroadcast.Swap(false) {
+		return
+	}
+	if !Broadcast(ctx, "redis-recovered") {
+		return
+	}
+	metrics.Add("ladder_cache_broadcast_resynced_total", 1)
+	logger.Infof("deferred ladder cache invalidation broadcast after redis recovery")
+}
+
+// listener 订阅失效频道，收到通知后失效本实例缓存；连接断开后按退避重新订阅
+type listener struct {
+	cache  *Cache
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newListener(cache *Cache) *listener { return &listener{cache: cache} }
+
+func (l *listener) Name() string { return "ladderCacheListener" }
+
+// Start Redis 不可用时同样启动订阅循环，恢复后重新订阅，期间只靠 TTL 与本实例失效
+func (l *listener) Start(ctx context.Context) error {
+	if !resource.DefaultRedisResource().Available() {
+		logger.Warnf("redis unavailable, ladder cache relies on ttl and local invalidation until redis recovers")
+	}
+	resource.DefaultRedisResource().OnRecover("ladderCache", resync)
+	ctx, l.cancel = context.WithCancel(ctx)
+	l.wg.Add(1)
+	go l.loop(ctx)
+	return nil
+}
+
+func (l *listener) Stop() error {
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.wg.Wait()
+	return nil
+}
+
+func (l *listener) loop(ctx context.Context) {
+	defer l.wg.Done()
+	backoff := time.Second
+	for ctx.Err() == nil {
+		if l.subscribe(ctx) {
+			backoff = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+// subscribe 订阅直到连接断开或 ctx 结束，返回是否订阅成功；订阅成功后失效一次，补上断开期间可能错过的通知
+func (l *listener) subscribe(ctx context.Context) bool {
+	cli := resource.DefaultRedisResource().Client()
+	if cli == nil {
+		return false
+	}
+	sub := cli.Subscribe(ctx, channel())
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		if ctx.Err() == nil {
+			logger.Warnf("ladder cache subscribe failed channel=%s error=%v", channel(), err)
+		}
+		return false
+	}
+	l.cache.Invalidate()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case msg, ok := <-ch:
+			if !ok {
+				return true
+			}
+			logger.Infof("ladder cache invalidated by broadcast reason=%s", msg.Payload)
+			l.cache.Invalidate()
+		}
+	}
+}
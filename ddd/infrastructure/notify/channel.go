@@ -0,0 +1,187 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"transcode-service/pkg/config"
+)
+
+// Channel 通知通道
+type Channel interface {
+	Name() string
+	Type() string
+	Send(ctx context.Context, alert Alert) error
+}
+
+// NewChannel 按配置创建通道
+func NewChannel(cfg config.NotificationChannel) (Channel, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("notification channel name is required")
+	}
+	client := &http.Client{Timeout: cfg.Timeout}
+	switch strings.ToLower(cfg.Type) {
+	case "slack":
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("notification channel %s: webhook_url is required for slack", cfg.Name)
+		}
+		return &slackChannel{name: cfg.Name, url: cfg.WebhookURL, client: client}, nil
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("notification channel %s: webhook_url is required for webhook", cfg.Name)
+		}
+		return &webhookChannel{name: cfg.Name, url: cfg.WebhookURL, client: client}, nil
+	case "pagerduty":
+		if cfg.RoutingKey == "" {
+			return nil, fmt.Errorf("notification channel %s: routing_key is required for pagerduty", cfg.Name)
+		}
+		return &pagerDutyChannel{name: cfg.Name, routingKey: cfg.RoutingKey, url: pagerDutyEventsURL, client: client}, nil
+	case "email":
+		if cfg.SMTPAddr == "" || cfg.From == "" || len(cfg.To) == 0 {
+			return nil, fmt.Errorf("notification channel %s: smtp_addr, from and to are required for email", cfg.Name)
+		}
+		return &emailChannel{name: cfg.Name, cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("notification channel %s: unknown type %q (slack|email|pagerduty|webhook)", cfg.Name, cfg.Type)
+	}
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return postRaw(ctx, client, url, raw, nil)
+}
+
+// postRaw POST 已编码的 JSON，headers 为额外请求头（如签名）
+func postRaw(ctx context.Context, client *http.Client, url string, raw []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status=%d body=%s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// slackChannel Slack incoming webhook
+type slackChannel struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+func (c *slackChannel) Name() string { return c.name }
+func (c *slackChannel) Type() string { return "slack" }
+
+func (c *slackChannel) Send(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, c.client, c.url, map[string]string{"text": fmt.Sprintf("*%s*\n%s", alert.Title, alert.Message)})
+}
+
+// webhookChannel 通用 webhook，POST 告警 JSON
+type webhookChannel struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+func (c *webhookChannel) Name() string { return c.name }
+func (c *webhookChannel) Type() string { return "webhook" }
+
+func (c *webhookChannel) Send(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, c.client, c.url, alert)
+}
+
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutyChannel PagerDuty Events API v2；dedup_key 为 事件名@实例，同一问题在 PagerDuty 侧合并为一个 incident
+type pagerDutyChannel struct {
+	name       string
+	routingKey string
+	url        string
+	client     *http.Client
+}
+
+func (c *pagerDutyChannel) Name() string { return c.name }
+func (c *pagerDutyChannel) Type() string { return "pagerduty" }
+
+func (c *pagerDutyChannel) Send(ctx context.Context, alert Alert) error {
+	severity := alert.Severity
+	if severity != SeverityCritical && severity != SeverityWarning {
+		severity = SeverityInfo
+	}
+	return postJSON(ctx, c.client, c.url, map[string]interface{}{
+		"routing_key":  c.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    alert.Event + "@" + alert.Instance,
+		"payload": map[string]interface{}{
+			"summary":        alert.Title,
+			"source":         alert.Instance,
+			"severity":       severity,
+			"timestamp":      alert.At.UTC().Format(time.RFC3339),
+			"component":      "transcode-service",
+			"class":          alert.Event,
+			"custom_details": map[string]interface{}{"message": alert.Message, "fields": alert.Fields},
+		},
+	})
+}
+
+// emailChannel SMTP 纯文本邮件，配置 username 时使用 PLAIN 认证
+type emailChannel struct {
+	name string
+	cfg  config.NotificationChannel
+}
+
+func (c *emailChannel) Name() string { return c.name }
+func (c *emailChannel) Type() string { return "email" }
+
+func (c *emailChannel) Send(ctx context.Context, alert Alert) error {
+	var auth smtp.Auth
+	if c.cfg.Username != "" {
+		host, _, err := net.SplitHostPort(c.cfg.SMTPAddr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, host)
+	}
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", c.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(c.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", alert.Title)
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(alert.Message)
+	msg.WriteString("\r\n")
+	// net/smtp 不支持 context，放到协程中按超时返回
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(c.cfg.SMTPAddr, auth, c.cfg.From, c.cfg.To, []byte(msg.String())) }()
+	timer := time.NewTimer(c.cfg.Timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("smtp send timeout after %s", c.cfg.Timeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+)
+
+// GroupCallbackSender 任务组完成回调：POST JSON，配置了密钥时带
+// X-Transcode-Timestamp 与 X-Transcode-Signature（hex(HMAC-SHA256(secret, timestamp + "." + body))）
+type GroupCallbackSender struct {
+	client *http.Client
+	secret string
+}
+
+// NewGroupCallbackSender 按 task_groups 配置创建
+func NewGroupCallbackSender(cfg config.TaskGroupsConfig) *GroupCallbackSender {
+	return &GroupCallbackSender{client: &http.Client{Timeout: cfg.CallbackTimeout}, secret: cfg.ResolvedCallbackSecret()}
+}
+
+func (s *GroupCallbackSender) SendGroupCompletion(ctx context.Context, url string, completion vo.TaskGroupCompletion) error {
+	raw, err := json.Marshal(completion)
+	if err != nil {
+		return err
+	}
+	headers := map[string]string{"X-Transcode-Event": completion.Event}
+	if s.secret != "" {
+		ts := strconv.FormatInt(clock.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write([]byte(ts + "."))
+		mac.Write(raw)
+		headers["X-Transcode-Timestamp"] = ts
+		headers["X-Transcode-Signature"] = hex.EncodeToString(mac.Sum(nil))
+	}
+	return postRaw(ctx, s.client, url, raw, headers)
+}
@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/manager"
+	"transcode-service/pkg/metrics"
+)
+
+// eventCounters 告警事件对应的累计计数器
+var eventCounters = map[string]string{
+	config.AlertTaskFailureSpike: "jobs_failed_total",
+	config.AlertDLQGrowth:        "kafka_consumer_dlq_total",
+	config.AlertSLABreach:        "tasks_expired_total",
+}
+
+type counterSample struct {
+	at    time.Time
+	value int64
+}
+
+// Monitor 周期性采样计数器，窗口内增量达到阈值时通过 Notifier 告警
+type Monitor struct {
+	notifier *Notifier
+	interval time.Duration
+	history  map[string][]counterSample
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewMonitor 通知未启用或没有可用通道时返回 nil
+func NewMonitor(cfg *config.Config, notifier *Notifier) *Monitor {
+	if cfg == nil || !notifier.Enabled() {
+		return nil
+	}
+	return &Monitor{notifier: notifier, interval: cfg.Notifications.CheckInterval, history: make(map[string][]counterSample)}
+}
+
+func (m *Monitor) Name() string { return "alertMonitor" }
+
+// ShutdownPhase 排空期间的失败事件仍需告警
+func (m *Monitor) ShutdownPhase() manager.Phase { return manager.PhaseFlush }
+
+func (m *Monitor) Start(ctx context.Context) error {
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.wg.Add(1)
+	go m.loop(ctx)
+	return nil
+}
+
+func (m *Monitor) Stop() error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+	return nil
+}
+
+func (m *Monitor) loop(ctx context.Context) {
+	defer m.wg.Done()
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	m.check(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+// check 记录当前计数，与窗口起点的样本相减得到窗口内增量
+func (m *Monitor) check(ctx context.Context) {
+	now := clock.Now()
+	events := make([]string, 0, len(m.notifier.Events()))
+	for name := range m.notifier.Events() {
+		events = append(events, name)
+	}
+	sort.Strings(events)
+	for _, name := range events {
+		ev := m.notifier.Events()[name]
+		counter, ok := eventCounters[name]
+		if !ok || !ev.Enabled {
+			continue
+		}
+		samples := append(m.history[name], counterSample{at: now, value: metrics.Value(counter)})
+		for len(samples) > 1 && now.Sub(samples[1].at) >= ev.Window {
+			samples = samples[1:]
+		}
+		m.history[name] = samples
+		delta := samples[len(samples)-1].value - samples[0].value
+		metrics.Set("alert_"+name+"_window_count", delta)
+		if delta < ev.Threshold {
+			continue
+		}
+		logger.Warnf("alert threshold reached event=%s count=%d threshold=%d window=%s", name, delta, ev.Threshold, ev.Window)
+		m.notifier.Fire(ctx, name, delta)
+	}
+}
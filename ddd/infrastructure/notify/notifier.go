@@ -0,0 +1,318 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// 严重级别
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// AlertTest 测试发送使用的事件名
+const AlertTest = "test"
+
+// Alert 一条告警
+type Alert struct {
+	Event    string            `json:"event"`
+	Severity string            `json:"severity"`
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Instance string            `json:"instance"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	At       time.Time         `json:"at"`
+}
+
+// SendResult 单个通道的发送结果
+type SendResult struct {
+	Channel string `json:"channel"`
+	Type    string `json:"type"`
+	Sent    bool   `json:"sent"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ChannelInfo 通道路由信息（不含密钥）
+type ChannelInfo struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Severities []string `json:"severities,omitempty"`
+	Events     []string `json:"events,omitempty"`
+}
+
+// EventInfo 告警事件阈值
+type EventInfo struct {
+	Name      string `json:"name"`
+	Enabled   bool   `json:"enabled"`
+	Severity  string `json:"severity"`
+	Threshold int64  `json:"threshold"`
+	Window    string `json:"window"`
+}
+
+// Status 通道路由与事件配置
+type Status struct {
+	Cooldown string        `json:"cooldown"`
+	Channels []ChannelInfo `json:"channels"`
+	Events   []EventInfo   `json:"events"`
+}
+
+// templateData 告警模板可用字段
+type templateData struct {
+	Event     string
+	Severity  string
+	Count     int64
+	Threshold int64
+	Window    time.Duration
+	Instance  string
+}
+
+var (
+	notifierOnce    sync.Once
+	defaultNotifier *Notifier
+)
+
+// DefaultNotifier 按全局配置创建的通知器；未启用或无可用通道时 Notify 为空操作
+func DefaultNotifier() *Notifier {
+	notifierOnce.Do(func() {
+		var cfg config.NotificationsConfig
+		if c := config.GetGlobalConfig(); c != nil {
+			cfg = c.Notifications
+		}
+		defaultNotifier = NewNotifier(cfg, instanceName())
+	})
+	return defaultNotifier
+}
+
+// Notifier 按事件与严重级别把告警路由到各通道，同一通道同一事件在 cooldown 内只发送一次
+type Notifier struct {
+	cfg      config.NotificationsConfig
+	instance string
+	channels []routedChannel
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+}
+
+type routedChannel struct {
+	Channel
+	severities map[string]struct{}
+	events     map[string]struct{}
+	info       ChannelInfo
+}
+
+func NewNotifier(cfg config.NotificationsConfig, instance string) *Notifier {
+	n := &Notifier{cfg: cfg, instance: instance, lastSent: make(map[string]time.Time)}
+	if !cfg.Enabled {
+		return n
+	}
+	for _, cc := range cfg.Channels {
+		ch, err := NewChannel(cc)
+		if err != nil {
+			logger.Warnf("notification channel skipped error=%v", err)
+			continue
+		}
+		n.channels = append(n.channels, routedChannel{
+			Channel:    ch,
+			severities: toSet(cc.Severities),
+			events:     toSet(cc.Events),
+			info:       ChannelInfo{Name: cc.Name, Type: ch.Type(), Severities: cc.Severities, Events: cc.Events},
+		})
+	}
+	logger.Infof("notifier initialized channels=%d cooldown=%s", len(n.channels), cfg.Cooldown)
+	return n
+}
+
+// Enabled 是否配置了可用通道
+func (n *Notifier) Enabled() bool {
+	return n != nil && n.cfg.Enabled && len(n.channels) > 0
+}
+
+// Status 已配置通道的路由信息与事件阈值
+func (n *Notifier) Status() Status {
+	s := Status{Cooldown: n.cfg.Cooldown.String(), Channels: make([]ChannelInfo, 0, len(n.channels))}
+	for _, ch := range n.channels {
+		s.Channels = append(s.Channels, ch.info)
+	}
+	for name, ev := range n.cfg.Events {
+		s.Events = append(s.Events, EventInfo{Name: name, Enabled: ev.Enabled, Severity: ev.Severity, Threshold: ev.Threshold, Window: ev.Window.String()})
+	}
+	sort.Slice(s.Events, func(i, j int) bool { return s.Events[i].Name < s.Events[j].Name })
+	return s
+}
+
+// Events 生效的告警事件配置
+func (n *Notifier) Events() map[string]config.AlertEventConfig {
+	return n.cfg.Events
+}
+
+// Fire 渲染事件模板并通知匹配的通道；冷却期内的通道跳过并计数
+func (n *Notifier) Fire(ctx context.Context, event string, count int64) []SendResult {
+	if !n.Enabled() {
+		return nil
+	}
+	ev := n.cfg.Events[event]
+	alert := n.render(event, ev, count)
+	now := clock.Now()
+	var results []SendResult
+	for _, ch := range n.channels {
+		if !ch.accepts(alert) {
+			continue
+		}
+		if !n.reserve(ch.Name(), event, now) {
+			metrics.Add("notifications_suppressed_total", 1)
+			continue
+		}
+		results = append(results, n.send(ctx, ch, alert))
+	}
+	return results
+}
+
+// TestFire 向指定通道（为空时全部通道）发送测试告警，忽略路由与冷却
+func (n *Notifier) TestFire(ctx context.Context, channel, severity string) []SendResult {
+	if severity == "" {
+		severity = SeverityInfo
+	}
+	alert := Alert{
+		Event:    AlertTest,
+		Severity: severity,
+		Title:    fmt.Sprintf("[%s] transcode-service test notification", strings.ToUpper(severity)),
+		Message:  fmt.Sprintf("Test notification from %s. If you can read this, the channel is configured correctly.", n.instance),
+		Instance: n.instance,
+		At:       clock.Now(),
+	}
+	var results []SendResult
+	for _, ch := range n.channels {
+		if channel != "" && ch.Name() != channel {
+			continue
+		}
+		results = append(results, n.send(ctx, ch, alert))
+	}
+	return results
+}
+
+// HasChannel 是否存在指定名称的通道
+func (n *Notifier) HasChannel(name string) bool {
+	for _, ch := range n.channels {
+		if ch.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Notifier) send(ctx context.Context, ch routedChannel, alert Alert) SendResult {
+	res := SendResult{Channel: ch.Name(), Type: ch.Type()}
+	if err := ch.Send(ctx, alert); err != nil {
+		metrics.Add("notifications_failed_total", 1)
+		logger.Warnf("notification send failed channel=%s type=%s event=%s error=%v", ch.Name(), ch.Type(), alert.Event, err)
+		res.Error = err.Error()
+		return res
+	}
+	metrics.Add("notifications_sent_total", 1)
+	logger.Infof("notification sent channel=%s type=%s event=%s severity=%s", ch.Name(), ch.Type(), alert.Event, alert.Severity)
+	res.Sent = true
+	return res
+}
+
+// reserve 冷却期外时占用发送名额
+func (n *Notifier) reserve(channel, event string, now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	key := channel + "|" + event
+	if last, ok := n.lastSent[key]; ok && now.Sub(last) < n.cfg.Cooldown {
+		return false
+	}
+	n.lastSent[key] = now
+	return true
+}
+
+func (n *Notifier) render(event string, ev config.AlertEventConfig, count int64) Alert {
+	data := templateData{Event: event, Severity: ev.Severity, Count: count, Threshold: ev.Threshold, Window: ev.Window, Instance: n.instance}
+	msg := defaultMessage(data)
+	if ev.Template != "" {
+		if tpl, err := template.New(event).Parse(ev.Template); err != nil {
+			logger.Warnf("notification template invalid event=%s error=%v", event, err)
+		} else {
+			var buf bytes.Buffer
+			if err := tpl.Execute(&buf, data); err == nil {
+				msg = buf.String()
+			}
+		}
+	}
+	return Alert{
+		Event:    event,
+		Severity: ev.Severity,
+		Title:    fmt.Sprintf("[%s] %s on %s", strings.ToUpper(ev.Severity), event, n.instance),
+		Message:  msg,
+		Instance: n.instance,
+		Fields: map[string]string{
+			"count":     fmt.Sprint(count),
+			"threshold": fmt.Sprint(ev.Threshold),
+			"window":    ev.Window.String(),
+		},
+		At: clock.Now(),
+	}
+}
+
+func defaultMessage(d templateData) string {
+	switch d.Event {
+	case config.AlertTaskFailureSpike:
+		return fmt.Sprintf("%d jobs failed in the last %s on %s (threshold %d).", d.Count, d.Window, d.Instance, d.Threshold)
+	case config.AlertDLQGrowth:
+		return fmt.Sprintf("%d Kafka messages were sent to the DLQ in the last %s on %s (threshold %d).", d.Count, d.Window, d.Instance, d.Threshold)
+	case config.AlertSLABreach:
+		return fmt.Sprintf("%d tasks missed their deadline in the last %s on %s (threshold %d).", d.Count, d.Window, d.Instance, d.Threshold)
+	default:
+		return fmt.Sprintf("%s: %d in the last %s on %s (threshold %d).", d.Event, d.Count, d.Window, d.Instance, d.Threshold)
+	}
+}
+
+func (c routedChannel) accepts(alert Alert) bool {
+	if len(c.severities) > 0 {
+		if _, ok := c.severities[alert.Severity]; !ok {
+			return false
+		}
+	}
+	if len(c.events) > 0 {
+		if _, ok := c.events[alert.Event]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func toSet(values []string) map[string]struct{} {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[strings.ToLower(strings.TrimSpace(v))] = struct{}{}
+	}
+	return set
+}
+
+// instanceName worker_id@hostname，与流水线快照的实例标识一致
+func instanceName() string {
+	id := "transcode-worker"
+	if c := config.GetGlobalConfig(); c != nil && c.Worker.WorkerID != "" {
+		id = c.Worker.WorkerID
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return id + "@" + host
+	}
+	return id
+}
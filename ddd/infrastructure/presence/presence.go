@@ -0,0 +1,245 @@
+// Package presence worker 存活状态：各实例按心跳间隔把自身状态写入带 TTL 的 Redis 键，
+// 读取方按键前缀扫描得到存活实例，键过期即视为下线；MySQL 只保留作业分配历史等登记信息，不再承担心跳写入
+package presence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"transcode-service/internal/resource"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+	"transcode-service/pkg/sysinfo"
+)
+
+const (
+	// KeyPrefix 存活键前缀，完整键为 前缀 + claim_id（worker_id@hostname）
+	KeyPrefix = "transcode:worker:presence:"
+	// defaultInterval worker.heartbeat_interval 未配置时的写入间隔
+	defaultInterval = 10 * time.Second
+	// writeTimeout 单次写入超时
+	writeTimeout = 3 * time.Second
+	// scanCount 每次 SCAN 的建议数量
+	scanCount = 200
+)
+
+// Record 单个实例的存活状态
+type Record struct {
+	ClaimID    string    `json:"claim_id"`
+	WorkerID   string    `json:"worker_id"`
+	Hostname   string    `json:"hostname"`
+	StartedAt  time.Time `json:"started_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Slots      int       `json:"slots"`     // 转码并发
+	HLSSlots   int       `json:"hls_slots"` // HLS 并发
+	Running    int       `json:"running"`   // 正在执行的转码任务
+	QueueDepth int       `json:"queue_depth"`
+	// ActiveTasks 正在执行的转码任务 UUID
+	ActiveTasks []string `json:"active_tasks,omitempty"`
+	// Capabilities 能力标签，如 codec:libx264、hwaccel:cuda、arch:amd64、hls、job:<作业类型>
+	Capabilities []string `json:"capabilities,omitempty"`
+	// GPUs 本机 GPU 的 NVML 计数器，未开启采集或无 GPU 时为空
+	GPUs []sysinfo.GPUStat `json:"gpus,omitempty"`
+}
+
+// SnapshotFunc 每次写入前采集实例的当前状态，由 worker 组件注入
+type SnapshotFunc func() Record
+
+// Publisher 后台任务：按间隔刷新本实例的存活键，停止时删除
+type Publisher struct {
+	claimID   string
+	workerID  string
+	hostname  string
+	interval  time.Duration
+	ttl       time.Duration
+	snapshot  SnapshotFunc
+	startedAt time.Time
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// NewPublisher 未启用时返回 nil
+func NewPublisher(cfg *config.Config, workerID, claimID string, snapshot SnapshotFunc) *Publisher {
+	if cfg == nil || !cfg.Worker.Presence.Enabled {
+		return nil
+	}
+	interval := HeartbeatInterval(cfg)
+	ttl := cfg.Worker.Presence.TTL
+	if ttl <= interval {
+		ttl = 3 * interval
+	}
+	host, _ := os.Hostname()
+	return &Publisher{claimID: claimID, workerID: workerID, hostname: host, interval: interval, ttl: ttl, snapshot: snapshot}
+}
+
+// HeartbeatInterval 存活键的写入间隔，读取方据此判断心跳是否滞后
+func HeartbeatInterval(cfg *config.Config) time.Duration {
+	if cfg == nil || cfg.Worker.HeartbeatInterval <= 0 {
+		return defaultInterval
+	}
+	return cfg.Worker.HeartbeatInterval
+}
+
+func (p *Publisher) Name() string { return "workerPresence" }
+
+func (p *Publisher) Start(ctx context.Context) error {
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.startedAt = time.Now()
+	p.publish(ctx)
+	// Redis 恢复后立即重写存活键，不必等下一次心跳；不可用期间键已过期，实例在列表中短暂缺席
+	resource.DefaultRedisResource().OnRecover("workerPresence", func(rctx context.Context) {
+		if ctx.Err() == nil {
+			p.publish(rctx)
+		}
+	})
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.publish(ctx)
+			}
+		}
+	}()
+	logger.Infof("worker presence started claim_id=%s interval=%s ttl=%s", p.claimID, p.interval, p.ttl)
+	return nil
+}
+
+// Stop 删除存活键，调度方立即看到实例下线而不必等待 TTL
+func (p *Publisher) Stop() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+	cli := resource.DefaultRedisResource().Client()
+	if cli == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	if err := cli.Del(ctx, KeyPrefix+p.claimID).Err(); err != nil {
+		logger.Warnf("remove worker presence failed claim_id=%s error=%v", p.claimID, err)
+	}
+	return nil
+}
+
+// publish Redis 不可用时只计数，不影响任务执行
+func (p *Publisher) publish(ctx context.Context) {
+	cli := resource.DefaultRedisResource().Client()
+	if cli == nil {
+		metrics.Add("worker_presence_skipped_total", 1)
+		return
+	}
+	var rec Record
+	if p.snapshot != nil {
+		rec = p.snapshot()
+	}
+	rec.ClaimID, rec.WorkerID, rec.Hostname = p.claimID, p.workerID, p.hostname
+	rec.StartedAt, rec.UpdatedAt = p.startedAt, time.Now()
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	wctx, cancel := context.WithTimeout(ctx, writeTimeout)
+	defer cancel()
+	if err := cli.Set(wctx, KeyPrefix+p.claimID, data, p.ttl).Err(); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		metrics.Add("worker_presence_errors_total", 1)
+		logger.Warnf("write worker presence failed claim_id=%s error=%v", p.claimID, err)
+		return
+	}
+	metrics.Add("worker_presence_writes_total", 1)
+}
+
+// Get 单个实例的存活状态，键不存在（已下线或从未上线）时返回 nil；Redis 不可用时返回错误
+func Get(ctx context.Context, claimID string) (*Record, error) {
+	cli := resource.DefaultRedisResource().Client()
+	if cli == nil {
+		return nil, fmt.Errorf("redis not available")
+	}
+	data, err := cli.Get(ctx, KeyPrefix+claimID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rec Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Remove 删除实例的存活键，键不存在时返回 false；进程仍在运行时下一次心跳会重新写入
+func Remove(ctx context.Context, claimID string) (bool, error) {
+	cli := resource.DefaultRedisResource().Client()
+	if cli == nil {
+		return false, fmt.Errorf("redis not available")
+	}
+	n, err := cli.Del(ctx, KeyPrefix+claimID).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// List 当前存活的实例，按 claim_id 排序；Redis 不可用时返回错误
+func List(ctx context.Context) ([]Record, error) {
+	cli := resource.DefaultRedisResource().Client()
+	if cli == nil {
+		return nil, fmt.Errorf("redis not available")
+	}
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, err := cli.Scan(ctx, cursor, KeyPrefix+"*", scanCount).Result()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, batch...)
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	// SCAN 可能重复返回同一个键，MGET 之后按 claim_id 去重
+	values, err := cli.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(values))
+	out := make([]Record, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue // 扫描与读取之间过期
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(s), &rec); err != nil || seen[rec.ClaimID] {
+			continue
+		}
+		seen[rec.ClaimID] = true
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ClaimID < out[j].ClaimID })
+	return out, nil
+}
@@ -21,5 +21,8 @@ func (s *DBSink) SaveProgress(ctx context.Context, task *entity.TranscodeTaskEnt
 	if s.repo == nil || task == nil {
 		return nil
 	}
+	if stages := task.StageProgress(); len(stages) > 0 {
+		return s.repo.UpdateTranscodeJobStageProgress(ctx, task.TaskUUID(), progress, stages, task.Timings())
+	}
 	return s.repo.UpdateTranscodeJobProgress(ctx, task.TaskUUID(), progress)
 }
@@ -0,0 +1,235 @@
+package publicurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	neturl "net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/storage"
+	"transcode-service/pkg/assert"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+	"transcode-service/pkg/utils"
+)
+
+const (
+	defaultBucket   = "transcode"
+	defaultTemplate = "{base}/storage/{bucket}/{key}"
+	defaultSignTTL  = 24 * time.Hour
+)
+
+var (
+	builderOnce    sync.Once
+	defaultBuilder *Builder
+)
+
+// Builder 由对象 key 生成对外访问 URL，规则可热更新
+type Builder struct {
+	rules atomic.Pointer[ruleSet]
+	// signer storage_base 为空时按 public.fallback 由存储端点生成直连或预签名 URL；nil 时保持相对路径
+	signer storage.ObjectURLSigner
+}
+
+type ruleSet struct {
+	base       string
+	fallback   string
+	presignTTL time.Duration
+	rules      []rule // 按前缀长度降序
+}
+
+type rule struct {
+	prefix     string
+	bucket     string
+	template   string
+	signSecret []byte
+	signTTL    time.Duration
+}
+
+// DefaultBuilder 按全局配置创建；开启 public.watch_config 时配置文件变更后自动重载
+func DefaultBuilder() *Builder {
+	assert.NotCircular()
+	builderOnce.Do(func() {
+		var pc config.PublicConfig
+		cfg := config.GetGlobalConfig()
+		if cfg != nil {
+			pc = cfg.Public
+		}
+		b, err := NewBuilder(pc)
+		if err != nil {
+			logger.Errorf("invalid public url rules, falling back to default error=%v", err)
+			b, _ = NewBuilder(config.PublicConfig{StorageBase: pc.StorageBase, Fallback: pc.Fallback, PresignTTL: pc.PresignTTL})
+		}
+		if signer, ok := storage.DefaultStorageGateway().(storage.ObjectURLSigner); ok {
+			b.signer = signer
+		}
+		if pc.WatchConfig {
+			config.WatchPublic(0, func(next config.PublicConfig) {
+				if err := b.Reload(next); err != nil {
+					metrics.Add("public_url_reload_failures_total", 1)
+					logger.Errorf("public url rules reload rejected error=%v", err)
+				}
+			})
+		}
+		defaultBuilder = b
+	})
+	assert.NotNil(defaultBuilder)
+	return defaultBuilder
+}
+
+// NewBuilder 校验并编译规则
+func NewBuilder(pc config.PublicConfig) (*Builder, error) {
+	b := &Builder{}
+	if err := b.Reload(pc); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Reload 原子替换规则；校验失败时保留旧规则
+func (b *Builder) Reload(pc config.PublicConfig) error {
+	rs, err := compile(pc)
+	if err != nil {
+		return err
+	}
+	old := b.rules.Swap(rs)
+	if old != nil {
+		metrics.Add("public_url_reloads_total", 1)
+		logger.Infof("public url rules reloaded base=%s rules=%d", rs.base, len(rs.rules))
+	}
+	return nil
+}
+
+func compile(pc config.PublicConfig) (*ruleSet, error) {
+	base := strings.TrimSpace(pc.StorageBase)
+	if base != "" && !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+		base = "http://" + base
+	}
+	if !pc.ValidFallback() {
+		return nil, fmt.Errorf("public.fallback %q must be one of endpoint, presign, relative", pc.Fallback)
+	}
+	rs := &ruleSet{base: strings.TrimRight(base, "/"), fallback: pc.Fallback, presignTTL: pc.PresignTTL}
+	if rs.fallback == "" {
+		rs.fallback = config.PublicFallbackEndpoint
+	}
+	if rs.presignTTL <= 0 {
+		rs.presignTTL = defaultSignTTL
+	}
+	for i, r := range pc.URLRules {
+		c := rule{
+			prefix:     strings.TrimLeft(r.Prefix, "/"),
+			bucket:     strings.Trim(r.Bucket, "/"),
+			template:   strings.TrimSpace(r.Template),
+			signSecret: []byte(r.SignSecret),
+			signTTL:    r.SignTTL,
+		}
+		if c.bucket == "" {
+			c.bucket = defaultBucket
+		}
+		if c.template == "" {
+			c.template = defaultTemplate
+		}
+		if !strings.Contains(c.template, "{key}") {
+			return nil, fmt.Errorf("public.url_rules[%d].template must contain {key}", i)
+		}
+		if c.signTTL <= 0 {
+			c.signTTL = defaultSignTTL
+		}
+		rs.rules = append(rs.rules, c)
+	}
+	sort.SliceStable(rs.rules, func(i, j int) bool { return len(rs.rules[i].prefix) > len(rs.rules[j].prefix) })
+	// 兜底规则保持历史 URL 形态：<storage_base>/storage/transcode/<key>
+	rs.rules = append(rs.rules, rule{bucket: defaultBucket, template: defaultTemplate})
+	return rs, nil
+}
+
+// Build 返回对象的对外 URL；storage_base 为空时按 public.fallback 兜底，relative 或兜底失败时为相对路径
+func (b *Builder) Build(objectKey string) string {
+	if strings.TrimSpace(objectKey) == "" {
+		return ""
+	}
+	rs := b.rules.Load()
+	if bucket, key, ok := vo.SplitBucketKey(objectKey); ok {
+		r := targetRule(bucket)
+		if u, ok := b.fallback(rs, r, objectKey); ok {
+			return u
+		}
+		return r.build(rs.base, key, time.Now())
+	}
+	key := strings.TrimLeft(objectKey, "/")
+	for _, r := range rs.rules {
+		if strings.HasPrefix(key, r.prefix) {
+			if u, ok := b.fallback(rs, r, key); ok {
+				return u
+			}
+			return r.build(rs.base, key, time.Now())
+		}
+	}
+	return key
+}
+
+// fallback storage_base 为空且模板引用 {base} 时由存储端点生成 URL，桶按存储网关的定位规则推断
+func (b *Builder) fallback(rs *ruleSet, r rule, objectKey string) (string, bool) {
+	if rs.base != "" || b.signer == nil || !strings.Contains(r.template, "{base}") {
+		return "", false
+	}
+	switch rs.fallback {
+	case config.PublicFallbackEndpoint:
+		metrics.Add("public_url_fallback_total", 1)
+		return b.signer.ObjectURL(objectKey), true
+	case config.PublicFallbackPresign:
+		u, err := b.signer.PresignGetObject(objectKey, rs.presignTTL)
+		if err != nil {
+			metrics.Add("public_url_presign_failures_total", 1)
+			logger.Warnf("presign public url failed key=%s error=%v", objectKey, err)
+			return "", false
+		}
+		metrics.Add("public_url_fallback_total", 1)
+		return u, true
+	}
+	return "", false
+}
+
+// targetRule 请求指定目标桶的产物按 output_targets 中该桶的 url_template 生成 URL
+func targetRule(bucket string) rule {
+	r := rule{bucket: bucket, template: defaultTemplate}
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		if t, ok := cfg.FindOutputTarget(bucket); ok && strings.Contains(t.URLTemplate, "{key}") {
+			r.template = strings.TrimSpace(t.URLTemplate)
+		}
+	}
+	return r
+}
+
+func (r rule) build(base, key string, now time.Time) string {
+	key = strings.TrimPrefix(key, r.bucket+"/")
+	u := strings.NewReplacer(
+		"{base}", base,
+		"{bucket}", r.bucket,
+		"{key}", utils.EscapeObjectKey(key),
+	).Replace(r.template)
+	if len(r.signSecret) == 0 {
+		return u
+	}
+	expires := strconv.FormatInt(now.Add(r.signTTL).Unix(), 10)
+	path := u
+	if parsed, err := neturl.Parse(u); err == nil {
+		path = parsed.EscapedPath()
+	}
+	mac := hmac.New(sha256.New, r.signSecret)
+	mac.Write([]byte(path + expires))
+	sep := "?"
+	if strings.Contains(u, "?") {
+		sep = "&"
+	}
+	return u + sep + "expires=" + expires + "&signature=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,85 @@
+package publicurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/utils"
+)
+
+var (
+	// ErrPrivateNotConfigured 未配置签名密钥
+	ErrPrivateNotConfigured = errors.New("private outputs sign secret not configured")
+	// ErrInvalidSignature 签名无效、已过期或 key 不在授权目录内
+	ErrInvalidSignature = errors.New("invalid or expired signature")
+)
+
+// BuildPrivate 返回私有产物的签名 URL <gateway_base>/<expires>/<signature>/<key> 与过期时间。
+// 签名授权整个 private/<token>/ 目录：HLS 播放列表中的相对 URI 按 URL 解析后仍带同一签名，播放列表无需改写
+func BuildPrivate(objectKey string) (string, time.Time, error) {
+	cfg := config.GetGlobalConfig()
+	if cfg == nil {
+		return "", time.Time{}, ErrPrivateNotConfigured
+	}
+	base := strings.TrimSpace(cfg.PrivateOutputs.GatewayBase)
+	if base == "" {
+		base = strings.TrimSpace(cfg.Public.StorageBase)
+		if base != "" && !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+			base = "http://" + base
+		}
+		base = strings.TrimRight(base, "/") + "/signed"
+	}
+	return signPrivate(cfg.PrivateOutputs.ResolvedSignSecret(), strings.TrimRight(base, "/"), objectKey, time.Now().Add(cfg.PrivateOutputs.SignTTL))
+}
+
+func signPrivate(secret, base, objectKey string, expiresAt time.Time) (string, time.Time, error) {
+	if secret == "" {
+		return "", time.Time{}, ErrPrivateNotConfigured
+	}
+	key := strings.TrimLeft(objectKey, "/")
+	scope, ok := vo.PrivateScope(key)
+	if !ok {
+		return "", time.Time{}, ErrInvalidSignature
+	}
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	u := base + "/" + expires + "/" + privateSignature(secret, scope, expires) + "/" + utils.EscapeObjectKey(key)
+	return u, time.Unix(expiresAt.Unix(), 0), nil
+}
+
+// VerifyPrivate 供签名网关校验请求路径（已解码并去掉 gateway_base 的 /<expires>/<signature>/<key>），返回可读取的对象 key
+func VerifyPrivate(secret, requestPath string, now time.Time) (string, error) {
+	if secret == "" {
+		return "", ErrPrivateNotConfigured
+	}
+	parts := strings.SplitN(strings.TrimLeft(requestPath, "/"), "/", 3)
+	if len(parts) != 3 {
+		return "", ErrInvalidSignature
+	}
+	expires, sig, key := parts[0], parts[1], parts[2]
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > exp {
+		return "", ErrInvalidSignature
+	}
+	// 拒绝 .. 等越出授权目录的路径
+	if strings.Contains("/"+key+"/", "/../") || strings.Contains("/"+key+"/", "/./") {
+		return "", ErrInvalidSignature
+	}
+	scope, ok := vo.PrivateScope(key)
+	if !ok || !hmac.Equal([]byte(sig), []byte(privateSignature(secret, scope, expires))) {
+		return "", ErrInvalidSignature
+	}
+	return key, nil
+}
+
+func privateSignature(secret, scope, expires string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(scope + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,264 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// Reprioritizer 支持修改排队中任务优先级的队列
+type Reprioritizer interface {
+	// Reprioritize 修改本地排队任务的优先级，任务不在队列中时返回 false
+	Reprioritize(taskUUID string, priority int) bool
+}
+
+// Admission 出队准入：按任务原始优先级占用执行名额，名额归还时关闭 Changed 返回的通道
+type Admission interface {
+	Admit(taskUUID string, priority int) bool
+	Changed() <-chan struct{}
+}
+
+// AdmittingDequeuer 按准入出队的队列，用于为高优先级任务预留槽位
+type AdmittingDequeuer interface {
+	// DequeueAdmitted 出队准入通过的任务中有效优先级最高的一个（阻塞），出队即占用名额
+	DequeueAdmitted(ctx context.Context, admission Admission) (*entity.TranscodeTaskEntity, error)
+}
+
+// AgingPriorityQueue 按有效优先级出队的内存队列。
+// 有效优先级 = 任务优先级 + 排队时长 / agingInterval（最多 +maxBoost），相同时按入队顺序，
+// 低优先级任务排队足够久后会排到新来的高优先级任务前面，不会被饿死
+type AgingPriorityQueue struct {
+	mu            sync.Mutex
+	items         []*agingItem
+	capacity      int
+	agingInterval time.Duration
+	maxBoost      int
+	seq           uint64
+	notify        chan struct{}
+	closed        bool
+}
+
+type agingItem struct {
+	task       *entity.TranscodeTaskEntity
+	priority   int
+	enqueuedAt time.Time
+	seq        uint64
+}
+
+// NewAgingPriorityQueue 创建带优先级老化的任务队列
+func NewAgingPriorityQueue(capacity int, agingInterval time.Duration, maxBoost int) *AgingPriorityQueue {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	if agingInterval <= 0 {
+		agingInterval = 5 * time.Minute
+	}
+	if maxBoost < 0 {
+		maxBoost = 0
+	}
+	return &AgingPriorityQueue{
+		capacity:      capacity,
+		agingInterval: agingInterval,
+		maxBoost:      maxBoost,
+		notify:        make(chan struct{}, 1),
+	}
+}
+
+// Enqueue 入队任务
+func (q *AgingPriorityQueue) Enqueue(ctx context.Context, task *entity.TranscodeTaskEntity) error {
+	if task == nil {
+		return fmt.Errorf("task cannot be nil")
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		logger.Warnf("AgingPriorityQueue.Enqueue rejected queue closed task_uuid=%s", task.TaskUUID())
+		return fmt.Errorf("queue is closed")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(q.items) >= q.capacity {
+		logger.Warnf("AgingPriorityQueue.Enqueue failed queue full task_uuid=%s size=%d max=%d", task.TaskUUID(), len(q.items), q.capacity)
+		return fmt.Errorf("queue is full")
+	}
+	q.seq++
+	q.items = append(q.items, &agingItem{task: task, priority: task.Priority(), enqueuedAt: clock.Now(), seq: q.seq})
+	q.signalLocked()
+	logger.Infof("AgingPriorityQueue.Enqueue success task_uuid=%s priority=%d size=%d", task.TaskUUID(), task.Priority(), len(q.items))
+	return nil
+}
+
+// Dequeue 出队有效优先级最高的任务（阻塞）
+func (q *AgingPriorityQueue) Dequeue(ctx context.Context) (*entity.TranscodeTaskEntity, error) {
+	for {
+		task, err := q.TryDequeue(ctx)
+		if err != nil || task != nil {
+			return task, err
+		}
+		select {
+		case <-q.notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// DequeueAdmitted 实现 AdmittingDequeuer；没有可准入的任务时等待入队或名额归还
+func (q *AgingPriorityQueue) DequeueAdmitted(ctx context.Context, admission Admission) (*entity.TranscodeTaskEntity, error) {
+	for {
+		changed := admission.Changed()
+		task, err := q.tryDequeueAdmitted(admission)
+		if err != nil || task != nil {
+			return task, err
+		}
+		select {
+		case <-q.notify:
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// TryDequeue 尝试出队任务（非阻塞），队列为空时返回 nil
+func (q *AgingPriorityQueue) TryDequeue(ctx context.Context) (*entity.TranscodeTaskEntity, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil, fmt.Errorf("queue is closed")
+	}
+	if len(q.items) == 0 {
+		return nil, nil
+	}
+	now := clock.Now()
+	best, bestScore := 0, q.effectivePriority(q.items[0], now)
+	for i := 1; i < len(q.items); i++ {
+		score := q.effectivePriority(q.items[i], now)
+		if score > bestScore || (score == bestScore && q.items[i].seq < q.items[best].seq) {
+			best, bestScore = i, score
+		}
+	}
+	return q.removeLocked(best, bestScore, now), nil
+}
+
+// tryDequeueAdmitted 按有效优先级依次尝试准入，排在最前的任务被拒时计为一次预留让行
+func (q *AgingPriorityQueue) tryDequeueAdmitted(admission Admission) (*entity.TranscodeTaskEntity, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil, fmt.Errorf("queue is closed")
+	}
+	if len(q.items) == 0 {
+		return nil, nil
+	}
+	now := clock.Now()
+	scores := make([]int, len(q.items))
+	order := make([]int, len(q.items))
+	for i, item := range q.items {
+		scores[i], order[i] = q.effectivePriority(item, now), i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if scores[i] != scores[j] {
+			return scores[i] > scores[j]
+		}
+		return q.items[i].seq < q.items[j].seq
+	})
+	for rank, i := range order {
+		item := q.items[i]
+		if admission.Admit(item.task.TaskUUID(), item.priority) {
+			return q.removeLocked(i, scores[i], now), nil
+		}
+		if rank == 0 {
+			metrics.Add("task_queue_reservation_deferred_total", 1)
+		}
+	}
+	return nil, nil
+}
+
+// removeLocked 移除并返回第 idx 个任务，记录老化与等待时长指标
+func (q *AgingPriorityQueue) removeLocked(idx, score int, now time.Time) *entity.TranscodeTaskEntity {
+	item := q.items[idx]
+	q.items = append(q.items[:idx], q.items[idx+1:]...)
+	if len(q.items) > 0 {
+		// 还有剩余任务时继续唤醒其他等待的消费者
+		q.signalLocked()
+	}
+	if score > item.priority {
+		metrics.Add("task_queue_aged_dequeue_total", 1)
+	}
+	metrics.SetFloat("task_queue_last_wait_seconds", now.Sub(item.enqueuedAt).Seconds())
+	return item.task
+}
+
+// effectivePriority 任务优先级加上排队老化带来的提升
+func (q *AgingPriorityQueue) effectivePriority(item *agingItem, now time.Time) int {
+	boost := int(now.Sub(item.enqueuedAt) / q.agingInterval)
+	if boost > q.maxBoost {
+		boost = q.maxBoost
+	}
+	return item.priority + boost
+}
+
+// Reprioritize 修改排队任务的优先级，保留原入队时间以继续累计老化
+func (q *AgingPriorityQueue) Reprioritize(taskUUID string, priority int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, item := range q.items {
+		if item.task.TaskUUID() == taskUUID {
+			item.priority = priority
+			item.task.SetPriority(priority)
+			return true
+		}
+	}
+	return false
+}
+
+// Size 获取队列大小
+func (q *AgingPriorityQueue) Size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return 0
+	}
+	return len(q.items)
+}
+
+// IsEmpty 检查队列是否为空
+func (q *AgingPriorityQueue) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Close 关闭队列并唤醒所有等待的消费者
+func (q *AgingPriorityQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	close(q.notify)
+	return nil
+}
+
+// IsClosed 检查队列是否已关闭
+func (q *AgingPriorityQueue) IsClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+func (q *AgingPriorityQueue) signalLocked() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,46 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/pkg/clock"
+)
+
+func priorityTask(t *testing.T, taskUUID string, priority int) *entity.TranscodeTaskEntity {
+	t.Helper()
+	task := entity.NewTranscodeTaskEntity(taskUUID, "user", "video", "in.mp4", "out.mp4")
+	task.SetPriority(priority)
+	return task
+}
+
+// TestAgingQueuePromotesLongWaitingTask 手动推进时钟：排队足够久的低优先级任务排到新来的高优先级任务前面
+func TestAgingQueuePromotesLongWaitingTask(t *testing.T) {
+	m := clock.NewManual(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	clock.Install(m, nil)
+	t.Cleanup(func() { clock.Install(clock.System(), nil) })
+	ctx := context.Background()
+
+	q := NewAgingPriorityQueue(8, time.Minute, 5)
+	if err := q.Enqueue(ctx, priorityTask(t, "low", 1)); err != nil {
+		t.Fatal(err)
+	}
+	m.Advance(2 * time.Minute)
+	if err := q.Enqueue(ctx, priorityTask(t, "high", 4)); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := q.TryDequeue(ctx); got.TaskUUID() != "high" {
+		t.Fatalf("dequeued %s, want high before low has aged enough", got.TaskUUID())
+	}
+
+	// low 排队 5 分钟，有效优先级 1+5 高于新入队的 high2
+	m.Advance(3 * time.Minute)
+	if err := q.Enqueue(ctx, priorityTask(t, "high2", 4)); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := q.TryDequeue(ctx); got.TaskUUID() != "low" {
+		t.Fatalf("dequeued %s, want low after aging past high2", got.TaskUUID())
+	}
+}
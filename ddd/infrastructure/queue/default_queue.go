@@ -2,6 +2,7 @@ package queue
 
 import (
 	"sync"
+	"time"
 
 	"transcode-service/pkg/config"
 )
@@ -15,12 +16,27 @@ var (
 func DefaultTaskQueue() TaskQueue {
 	queueOnce.Do(func() {
 		capacity := 100
+		aging, maxBoost := 5*time.Minute, 5
+		var express config.ExpressLaneConfig
 		if cfg := config.GetGlobalConfig(); cfg != nil {
 			if cfg.Worker.QueueCapacity > 0 {
 				capacity = cfg.Worker.QueueCapacity
 			}
+			aging, maxBoost = cfg.Worker.Priority.AgingInterval, cfg.Worker.Priority.MaxAgingBoost
+			express = cfg.Worker.ExpressLane
 		}
-		defaultQueue = NewMemoryTaskQueue(capacity)
+		// 按优先级出队并随排队时长老化，避免低优先级任务饿死
+		standard := NewAgingPriorityQueue(capacity, aging, maxBoost)
+		if !express.Enabled {
+			defaultQueue = standard
+			return
+		}
+		expressCapacity := express.QueueCapacity
+		if expressCapacity <= 0 {
+			expressCapacity = capacity
+		}
+		// 短视频走独立的快车道队列
+		defaultQueue = NewLaneQueue(NewAgingPriorityQueue(expressCapacity, aging, maxBoost), standard)
 	})
 	return defaultQueue
 }
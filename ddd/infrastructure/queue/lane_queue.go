@@ -0,0 +1,153 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/metrics"
+)
+
+// LaneDequeuer 按调度通道出队的队列，快车道专用协程只取 express 任务
+type LaneDequeuer interface {
+	DequeueLane(ctx context.Context, lane vo.TaskLane) (*entity.TranscodeTaskEntity, error)
+}
+
+// LaneQueue express 与 standard 两条带优先级老化的队列：按任务的 lane 入队，
+// 普通出队先取 express 再取 standard，短视频不会排在长视频之后
+type LaneQueue struct {
+	express  *AgingPriorityQueue
+	standard *AgingPriorityQueue
+}
+
+// NewLaneQueue 创建分道队列
+func NewLaneQueue(express, standard *AgingPriorityQueue) *LaneQueue {
+	return &LaneQueue{express: express, standard: standard}
+}
+
+func (q *LaneQueue) lane(lane vo.TaskLane) *AgingPriorityQueue {
+	if lane == vo.LaneExpress {
+		return q.express
+	}
+	return q.standard
+}
+
+// Enqueue 按任务的调度通道入队
+func (q *LaneQueue) Enqueue(ctx context.Context, task *entity.TranscodeTaskEntity) error {
+	if task == nil {
+		return fmt.Errorf("task cannot be nil")
+	}
+	lane := task.Lane()
+	if err := q.lane(lane).Enqueue(ctx, task); err != nil {
+		return err
+	}
+	metrics.Add("task_lane_"+string(lane)+"_enqueued_total", 1)
+	q.publish()
+	return nil
+}
+
+// Dequeue 优先出队 express 任务（阻塞）
+func (q *LaneQueue) Dequeue(ctx context.Context) (*entity.TranscodeTaskEntity, error) {
+	for {
+		task, err := q.TryDequeue(ctx)
+		if err != nil || task != nil {
+			return task, err
+		}
+		select {
+		case <-q.express.notify:
+		case <-q.standard.notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// DequeueAdmitted 实现 AdmittingDequeuer，先 express 后 standard
+func (q *LaneQueue) DequeueAdmitted(ctx context.Context, admission Admission) (*entity.TranscodeTaskEntity, error) {
+	for {
+		changed := admission.Changed()
+		for _, lane := range []vo.TaskLane{vo.LaneExpress, vo.LaneStandard} {
+			task, err := q.lane(lane).tryDequeueAdmitted(admission)
+			if err != nil {
+				return nil, err
+			}
+			if task != nil {
+				q.dequeued(lane)
+				return task, nil
+			}
+		}
+		select {
+		case <-q.express.notify:
+		case <-q.standard.notify:
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// DequeueLane 只出队指定通道的任务（阻塞）
+func (q *LaneQueue) DequeueLane(ctx context.Context, lane vo.TaskLane) (*entity.TranscodeTaskEntity, error) {
+	task, err := q.lane(lane).Dequeue(ctx)
+	if task != nil {
+		q.dequeued(lane)
+	}
+	return task, err
+}
+
+// TryDequeue 尝试出队，先 express 后 standard，均为空时返回 nil
+func (q *LaneQueue) TryDequeue(ctx context.Context) (*entity.TranscodeTaskEntity, error) {
+	for _, lane := range []vo.TaskLane{vo.LaneExpress, vo.LaneStandard} {
+		task, err := q.lane(lane).TryDequeue(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if task != nil {
+			q.dequeued(lane)
+			return task, nil
+		}
+	}
+	return nil, nil
+}
+
+func (q *LaneQueue) dequeued(lane vo.TaskLane) {
+	metrics.Add("task_lane_"+string(lane)+"_dequeued_total", 1)
+	q.publish()
+}
+
+func (q *LaneQueue) publish() {
+	metrics.Set("task_lane_express_queue_size", int64(q.express.Size()))
+	metrics.Set("task_lane_standard_queue_size", int64(q.standard.Size()))
+}
+
+// Reprioritize 修改排队任务的优先级，任务可能在任一通道
+func (q *LaneQueue) Reprioritize(taskUUID string, priority int) bool {
+	return q.express.Reprioritize(taskUUID, priority) || q.standard.Reprioritize(taskUUID, priority)
+}
+
+// Size 两条通道的排队总数
+func (q *LaneQueue) Size() int {
+	return q.express.Size() + q.standard.Size()
+}
+
+// LaneSize 指定通道的排队数
+func (q *LaneQueue) LaneSize(lane vo.TaskLane) int {
+	return q.lane(lane).Size()
+}
+
+// IsEmpty 检查队列是否为空
+func (q *LaneQueue) IsEmpty() bool {
+	return q.Size() == 0
+}
+
+// Close 关闭两条通道并唤醒所有等待的消费者
+func (q *LaneQueue) Close() error {
+	_ = q.express.Close()
+	return q.standard.Close()
+}
+
+// IsClosed 检查队列是否已关闭
+func (q *LaneQueue) IsClosed() bool {
+	return q.standard.IsClosed()
+}
@@ -0,0 +1,255 @@
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/infrastructure/workspace"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/ctxutil"
+	"transcode-service/pkg/hwaccel"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+const (
+	ComponentFFmpeg          = "ffmpeg"
+	ComponentEncode          = "encode"
+	ComponentFFprobe         = "ffprobe"
+	ComponentStorageUpload   = "storage_upload"
+	ComponentStorageDownload = "storage_download"
+
+	// selftestObjectPrefix 自检对象前缀，落在 transcode 桶
+	selftestObjectPrefix = "transcoded/selftest"
+	// encodeTimeout 测试图案编码超时
+	encodeTimeout = 60 * time.Second
+	// cleanupTimeout 删除自检对象的时限，请求已取消时仍会执行
+	cleanupTimeout = 10 * time.Second
+)
+
+// CheckResult 单个组件的检查结果
+type CheckResult struct {
+	Component  string `json:"component"`
+	Passed     bool   `json:"passed"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report 自检报告
+type Report struct {
+	Passed     bool          `json:"passed"`
+	StartedAt  time.Time     `json:"started_at"`
+	DurationMs int64         `json:"duration_ms"`
+	Checks     []CheckResult `json:"checks"`
+}
+
+// Runner 端到端自检：生成测试图案 -> 按配置编码 -> 上传 -> 下载回读
+type Runner struct {
+	cfg     *config.Config
+	storage gateway.StorageGateway
+}
+
+func NewRunner(cfg *config.Config, storage gateway.StorageGateway) *Runner {
+	if cfg == nil {
+		cfg = config.GetGlobalConfig()
+	}
+	return &Runner{cfg: cfg, storage: storage}
+}
+
+// Run 依次执行各组件检查，前置检查失败时后续检查标记为跳过
+func (r *Runner) Run(ctx context.Context) *Report {
+	report := &Report{StartedAt: time.Now()}
+	defer func() {
+		report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+		report.Passed = true
+		for _, c := range report.Checks {
+			if !c.Passed {
+				report.Passed = false
+				break
+			}
+		}
+		logger.Infof("selftest finished passed=%v duration_ms=%d", report.Passed, report.DurationMs)
+	}()
+
+	ws, err := workspace.DefaultManager().Acquire(fmt.Sprintf("selftest-%d", time.Now().UnixNano()))
+	if err != nil {
+		report.Checks = append(report.Checks, CheckResult{Component: "workspace", Error: fmt.Sprintf("create workspace: %v", err)})
+		return report
+	}
+	defer ws.Release()
+
+	encoded := ws.Path("selftest.mp4")
+	objectKey := fmt.Sprintf("%s/%s-%d.mp4", selftestObjectPrefix, hostname(), time.Now().Unix())
+	// 无论回读通过与否都删除上传的对象，避免每次自检在桶里留下测试文件
+	uploadAttempted := false
+	defer func() {
+		if uploadAttempted {
+			r.removeObject(ctx, objectKey)
+		}
+	}()
+	steps := []struct {
+		component string
+		run       func() (string, error)
+	}{
+		{ComponentFFmpeg, func() (string, error) { return exec.LookPath(r.ffmpegBinary()) }},
+		{ComponentEncode, func() (string, error) { return r.encodeTestPattern(ctx, encoded) }},
+		{ComponentFFprobe, func() (string, error) { return r.probe(ctx, encoded) }},
+		{ComponentStorageUpload, func() (string, error) {
+			if r.storage == nil {
+				return "", fmt.Errorf("storage gateway not configured")
+			}
+			uploadAttempted = true
+			key, err := r.storage.UploadTranscodedFile(ctx, encoded, objectKey, "video/mp4")
+			return "object_key=" + key, err
+		}},
+		{ComponentStorageDownload, func() (string, error) {
+			return r.downloadAndCompare(ctx, objectKey, encoded, ws.Path("download", "selftest.mp4"))
+		}},
+	}
+
+	failed := ""
+	for _, step := range steps {
+		if failed != "" {
+			report.Checks = append(report.Checks, CheckResult{Component: step.component, Skipped: true, Detail: "skipped after " + failed + " failure"})
+			continue
+		}
+		start := time.Now()
+		detail, err := step.run()
+		res := CheckResult{Component: step.component, Passed: err == nil, DurationMs: time.Since(start).Milliseconds(), Detail: detail}
+		if err != nil {
+			res.Error = err.Error()
+			failed = step.component
+			logger.Warnf("selftest check failed component=%s error=%v", step.component, err)
+		}
+		report.Checks = append(report.Checks, res)
+	}
+	return report
+}
+
+// removeObject 删除自检对象；存储实现不支持删除或删除失败时只记录，不影响自检结果
+func (r *Runner) removeObject(ctx context.Context, objectKey string) {
+	mover, ok := r.storage.(gateway.ObjectMover)
+	if !ok {
+		logger.Warnf("selftest object left in storage, storage does not support delete object_key=%s", objectKey)
+		return
+	}
+	rmCtx, cancel := ctxutil.Detached(ctx, cleanupTimeout)
+	defer cancel()
+	if err := mover.RemoveObject(rmCtx, objectKey); err != nil {
+		metrics.Add("selftest_cleanup_failures_total", 1)
+		logger.Warnf("selftest object cleanup failed object_key=%s error=%v", objectKey, err)
+	}
+}
+
+// downloadAndCompare 回读上传对象并校验大小
+func (r *Runner) downloadAndCompare(ctx context.Context, objectKey, uploaded, local string) (string, error) {
+	if err := r.storage.DownloadFile(ctx, objectKey, local); err != nil {
+		return "", err
+	}
+	want, err := fileSize(uploaded)
+	if err != nil {
+		return "", err
+	}
+	got, err := fileSize(local)
+	if err != nil {
+		return "", err
+	}
+	if want != got {
+		return "", fmt.Errorf("size mismatch uploaded=%d downloaded=%d", want, got)
+	}
+	return fmt.Sprintf("bytes=%d", got), nil
+}
+
+func (r *Runner) ffmpegBinary() string {
+	if r.cfg != nil {
+		return r.cfg.Transcode.FFmpeg.Binary()
+	}
+	return "ffmpeg"
+}
+
+// encodeTestPattern 使用配置的编码器/预设编码 1 秒测试图案，可暴露 NVENC/QSV/VAAPI 等硬件驱动问题
+func (r *Runner) encodeTestPattern(ctx context.Context, output string) (string, error) {
+	codec, preset := "libx264", "medium"
+	if r.cfg != nil {
+		if c := strings.TrimSpace(r.cfg.Transcode.FFmpeg.VideoCodec); c != "" {
+			codec = c
+		}
+		if p := strings.TrimSpace(r.cfg.Transcode.FFmpeg.VideoPreset); p != "" {
+			preset = p
+		}
+	}
+	args := []string{"-hide_banner", "-v", "error"}
+	// 硬件后端按转码链路上传到设备后编码，同时验证设备初始化与缩放滤镜
+	frameArgs := []string{"-pix_fmt", "yuv420p"}
+	if r.cfg != nil {
+		if b := hwaccel.Lookup(r.cfg.Transcode.FFmpeg.HardwareAccel); b != nil && b.Encodes(codec) {
+			args = append(args, b.UploadArgs(r.cfg.Transcode.FFmpeg.HardwareDevice)...)
+			if f := b.ScaleFilter(320, 240, false); f != "" {
+				frameArgs = []string{"-vf", f}
+			}
+		}
+	}
+	args = append(args,
+		"-f", "lavfi", "-i", "testsrc=size=320x240:rate=25",
+		"-f", "lavfi", "-i", "sine=frequency=1000:sample_rate=44100",
+		"-t", "1",
+		"-c:v", codec, "-preset", preset,
+	)
+	args = append(args, frameArgs...)
+	args = append(args,
+		"-c:a", "aac", "-b:a", "64k",
+		"-y", output,
+	)
+	encCtx, cancel := context.WithTimeout(ctx, encodeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(encCtx, r.ffmpegBinary(), args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("codec=%s: %v: %s", codec, err, strings.TrimSpace(string(out)))
+	}
+	return "codec=" + codec, nil
+}
+
+func (r *Runner) probe(ctx context.Context, path string) (string, error) {
+	binary, timeout := "ffprobe", 30*time.Second
+	if r.cfg != nil {
+		binary = r.cfg.Transcode.FFprobe.Binary()
+		if r.cfg.Transcode.FFprobe.Timeout > 0 {
+			timeout = r.cfg.Transcode.FFprobe.Timeout
+		}
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	out, err := exec.CommandContext(probeCtx, binary, "-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", path).Output()
+	if err != nil {
+		return "", err
+	}
+	dur, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil || dur <= 0 {
+		return "", fmt.Errorf("unexpected duration %q", strings.TrimSpace(string(out)))
+	}
+	return fmt.Sprintf("duration=%.2fs", dur), nil
+}
+
+func fileSize(path string) (int64, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return st.Size(), nil
+}
+
+func hostname() string {
+	h, err := os.Hostname()
+	if err != nil || h == "" {
+		return "unknown"
+	}
+	return h
+}
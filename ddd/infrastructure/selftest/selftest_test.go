@@ -0,0 +1,34 @@
+package selftest
+
+import (
+	"context"
+	"testing"
+
+	"transcode-service/ddd/domain/gateway"
+)
+
+// removingStorage 只实现删除，其余方法调用会 panic
+type removingStorage struct {
+	gateway.StorageGateway
+	removed []string
+}
+
+func (s *removingStorage) CopyObject(context.Context, string, string, string) error { return nil }
+
+func (s *removingStorage) RemoveObject(ctx context.Context, objectKey string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.removed = append(s.removed, objectKey)
+	return nil
+}
+
+func TestRemoveObjectDeletesSelftestObject(t *testing.T) {
+	s := &removingStorage{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	NewRunner(nil, s).removeObject(ctx, "transcoded/selftest/host-1.mp4")
+	if len(s.removed) != 1 || s.removed[0] != "transcoded/selftest/host-1.mp4" {
+		t.Fatalf("removed = %v, want the selftest object even after the request was cancelled", s.removed)
+	}
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/pkg/errno"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+	"transcode-service/pkg/sourcecrypt"
+)
+
+// decryptingGateway 源文件下载时按需解密：对象流预读 magic，upload-service 静态加密的源（GVE1 格式）按头部中的密钥 ID
+// 向 KeyProvider 取数据密钥，逐块解密直接写入 localPath，工作目录中不落密文。数据密钥只在内存中使用，用后清零；
+// 源文件缓存中保存的是密文。未加密的源关闭预读的流后走内层下载，保留源文件缓存的硬链接
+type decryptingGateway struct {
+	gateway.StorageGateway
+	keys gateway.KeyProvider
+}
+
+// NewDecryptingGateway 只用于源文件下载；keys 为 nil 时加密源返回 ErrSourceDecryptFailed
+func NewDecryptingGateway(inner gateway.StorageGateway, keys gateway.KeyProvider) gateway.StorageGateway {
+	if inner == nil {
+		return nil
+	}
+	return &decryptingGateway{StorageGateway: inner, keys: keys}
+}
+
+func (g *decryptingGateway) DownloadFile(ctx context.Context, objectKey, localPath string) error {
+	rc, err := g.StorageGateway.OpenObject(ctx, objectKey)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	src := &sourceReader{r: rc}
+	r := bufio.NewReaderSize(src, 1<<20)
+	encrypted, err := sourcecrypt.Sniff(r)
+	if err != nil {
+		return classifyErr(fmt.Errorf("sniff source: %w", err))
+	}
+	if !encrypted {
+		rc.Close()
+		return g.StorageGateway.DownloadFile(ctx, objectKey, localPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return fmt.Errorf("create local directory: %w", err)
+	}
+	if err := g.decrypt(ctx, objectKey, r, localPath); err != nil {
+		_ = os.Remove(localPath)
+		// 读对象中途断开属于存储故障，按存储错误返回以便退避重试，不计为解密失败
+		if src.err != nil {
+			return classifyErr(fmt.Errorf("read object: %w", src.err))
+		}
+		metrics.Add("source_decrypt_failures_total", 1)
+		return err
+	}
+	return nil
+}
+
+func (g *decryptingGateway) decrypt(ctx context.Context, objectKey string, r *bufio.Reader, localPath string) error {
+	start := time.Now()
+	h, err := sourcecrypt.ReadHeader(r)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errno.ErrSourceDecryptFailed, err)
+	}
+	if g.keys == nil {
+		return fmt.Errorf("%w: source is encrypted but dependencies.key_provider is disabled object_key=%s", errno.ErrSourceDecryptFailed, objectKey)
+	}
+	key, err := g.keys.DataKey(ctx, gateway.DataKeyRequest{ObjectKey: objectKey, KeyID: h.KeyID, WrappedKey: h.WrappedKey})
+	if err != nil {
+		return fmt.Errorf("%w: fetch data key key_id=%s: %w", errno.ErrSourceDecryptFailed, h.KeyID, err)
+	}
+	defer sourcecrypt.Zero(key)
+
+	var n int64
+	err = writeLocal(localPath, func(w io.Writer) (err error) {
+		n, err = sourcecrypt.Decrypt(w, r, h, key)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%w: object_key=%s key_id=%s: %w", errno.ErrSourceDecryptFailed, objectKey, h.KeyID, err)
+	}
+	metrics.Add("source_decrypted_total", 1)
+	metrics.Add("source_decrypted_bytes_total", n)
+	logger.Infof("source decrypted object_key=%s key_id=%s bytes=%d cost_ms=%d", objectKey, h.KeyID, n, time.Since(start).Milliseconds())
+	return nil
+}
+
+// writeLocal 以 0600 创建 localPath 并经缓冲写入
+func writeLocal(localPath string, fill func(w io.Writer) error) error {
+	out, err := os.OpenFile(localPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("create local: %w", err)
+	}
+	w := bufio.NewWriterSize(out, 1<<20)
+	err = fill(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// sourceReader 记录读取对象时的错误，用于区分存储故障与解密失败
+type sourceReader struct {
+	r   io.Reader
+	err error
+}
+
+func (s *sourceReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil && err != io.EOF {
+		s.err = err
+	}
+	return n, err
+}
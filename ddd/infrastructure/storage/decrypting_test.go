@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/pkg/errno"
+)
+
+// encryptGVE1 按 sourcecrypt 的分块信封格式加密，模拟 upload-service 写入的源文件
+func encryptGVE1(t *testing.T, plain, key []byte, chunk int) []byte {
+	t.Helper()
+	keyID, wrapped := []byte("kid-1"), []byte("wrapped")
+	noncePrefix := []byte("12345678")
+	raw := []byte{1}
+	raw = binary.BigEndian.AppendUint32(raw, uint32(chunk))
+	raw = append(raw, noncePrefix...)
+	raw = binary.BigEndian.AppendUint16(raw, uint16(len(keyID)))
+	raw = append(raw, keyID...)
+	raw = binary.BigEndian.AppendUint16(raw, uint16(len(wrapped)))
+	raw = append(raw, wrapped...)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := append([]byte("GVE1"), binary.BigEndian.AppendUint32(nil, uint32(len(raw)))...)
+	out = append(out, raw...)
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, noncePrefix)
+	for index := 0; ; index++ {
+		end := min((index+1)*chunk, len(plain))
+		last := end == len(plain)
+		binary.BigEndian.PutUint32(nonce[8:], uint32(index))
+		aad := append(append([]byte(nil), raw...), 0)
+		if last {
+			aad[len(aad)-1] = 1
+		}
+		out = aead.Seal(out, nonce, plain[index*chunk:end], aad)
+		if last {
+			return out
+		}
+	}
+}
+
+// objectStorage 只实现源文件下载用到的方法，其余方法调用会 panic
+type objectStorage struct {
+	gateway.StorageGateway
+	data       []byte
+	failAfter  int // >0 时读到该偏移后返回连接重置
+	downloaded bool
+}
+
+func (s *objectStorage) OpenObject(context.Context, string) (io.ReadCloser, error) {
+	var r io.Reader = bytes.NewReader(s.data)
+	if s.failAfter > 0 {
+		r = io.MultiReader(bytes.NewReader(s.data[:s.failAfter]), errReader{syscall.ECONNRESET})
+	}
+	return io.NopCloser(r), nil
+}
+
+func (s *objectStorage) DownloadFile(_ context.Context, _ string, localPath string) error {
+	s.downloaded = true
+	return os.WriteFile(localPath, s.data, 0o644)
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+type staticKeys struct{ key []byte }
+
+func (k staticKeys) DataKey(context.Context, gateway.DataKeyRequest) ([]byte, error) {
+	return append([]byte(nil), k.key...), nil
+}
+
+func TestDecryptingGatewayStreamsIntoLocalPath(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	plain := bytes.Repeat([]byte("frame-data"), 1000)
+	inner := &objectStorage{data: encryptGVE1(t, plain, key, 4096)}
+	dir := t.TempDir()
+	local := filepath.Join(dir, "source.mp4")
+
+	if err := NewDecryptingGateway(inner, staticKeys{key}).DownloadFile(context.Background(), "videos/a.mp4", local); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(local)
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Fatalf("decrypted %d bytes err=%v, want %d plaintext bytes", len(got), err, len(plain))
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("workspace has %d files, want only the plaintext (no ciphertext copy)", len(entries))
+	}
+	if inner.downloaded {
+		t.Fatal("encrypted source should be streamed, not downloaded first")
+	}
+}
+
+func TestDecryptingGatewayReportsInterruptedReadAsStorageError(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	data := encryptGVE1(t, bytes.Repeat([]byte("x"), 20000), key, 4096)
+	inner := &objectStorage{data: data, failAfter: len(data) / 2}
+	local := filepath.Join(t.TempDir(), "source.mp4")
+
+	err := NewDecryptingGateway(inner, staticKeys{key}).DownloadFile(context.Background(), "videos/a.mp4", local)
+	if !gateway.IsStorageUnavailable(err) || errors.Is(err, errno.ErrSourceDecryptFailed) {
+		t.Fatalf("err = %v, want storage unavailable", err)
+	}
+	if _, statErr := os.Stat(local); !os.IsNotExist(statErr) {
+		t.Fatal("partial plaintext was left behind")
+	}
+}
+
+func TestDecryptingGatewayRejectsWrongKey(t *testing.T) {
+	data := encryptGVE1(t, []byte("secret"), bytes.Repeat([]byte{7}, 32), 4096)
+	local := filepath.Join(t.TempDir(), "source.mp4")
+
+	err := NewDecryptingGateway(&objectStorage{data: data}, staticKeys{bytes.Repeat([]byte{8}, 32)}).DownloadFile(context.Background(), "videos/a.mp4", local)
+	if !errors.Is(err, errno.ErrSourceDecryptFailed) {
+		t.Fatalf("err = %v, want ErrSourceDecryptFailed", err)
+	}
+	if _, statErr := os.Stat(local); !os.IsNotExist(statErr) {
+		t.Fatal("unauthenticated plaintext was left behind")
+	}
+}
+
+func TestDecryptingGatewayPassesPlainSourceThrough(t *testing.T) {
+	inner := &objectStorage{data: []byte("\x00\x00\x00\x18ftypmp42")}
+	local := filepath.Join(t.TempDir(), "source.mp4")
+
+	if err := NewDecryptingGateway(inner, nil).DownloadFile(context.Background(), "videos/a.mp4", local); err != nil {
+		t.Fatal(err)
+	}
+	if !inner.downloaded {
+		t.Fatal("plain source should use the inner download (keeps source cache hard links)")
+	}
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"sync"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/internal/resource"
+	"transcode-service/pkg/config"
+)
+
+var (
+	defaultStorageOnce      sync.Once
+	singletonStorageGateway gateway.StorageGateway
+)
+
+// DefaultStorageGateway 基于 RustFS 资源构建的默认存储网关（需在资源初始化之后调用）
+func DefaultStorageGateway() gateway.StorageGateway {
+	defaultStorageOnce.Do(func() {
+		rustRes := resource.DefaultRustFSResource()
+		singletonStorageGateway = NewRustFSStorage(
+			rustRes.GetEndpoint(),
+			rustRes.GetAccessKey(),
+			rustRes.GetSecretKey(),
+			RustFSOptions{
+				DirectPut:       rustRes.DirectPut(),
+				VerifyChecksum:  rustRes.VerifyChecksum(),
+				ChecksumRetries: rustRes.ChecksumRetries(),
+				Multipart:       multipartConfig(),
+			},
+		)
+	})
+	return singletonStorageGateway
+}
+
+// multipartConfig 全局分段上传配置，配置未加载时不分段
+func multipartConfig() config.MultipartConfig {
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		return cfg.Multipart
+	}
+	return config.MultipartConfig{}
+}
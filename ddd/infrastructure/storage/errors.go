@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/minio/minio-go/v7"
+
+	"transcode-service/ddd/domain/gateway"
+)
+
+// classifyErr 将网络类错误与 5xx 响应归类为 ErrStorageUnavailable，404 归类为 ErrObjectNotFound，其余错误原样返回
+func classifyErr(err error) error {
+	if err == nil || errors.Is(err, gateway.ErrStorageUnavailable) {
+		return err
+	}
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return fmt.Errorf("%w: %v", gateway.ErrStorageUnavailable, err)
+	}
+	var minioErr minio.ErrorResponse
+	if errors.As(err, &minioErr) && minioErr.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %v", gateway.ErrStorageUnavailable, err)
+	}
+	if errors.As(err, &minioErr) && (minioErr.StatusCode == http.StatusNotFound || minioErr.Code == "NoSuchKey") {
+		return fmt.Errorf("%w: %v", gateway.ErrObjectNotFound, err)
+	}
+	return err
+}
+
+// statusErr 根据 HTTP 状态码构造错误，5xx 视为存储不可用，404 视为对象不存在
+func statusErr(op string, status int, body string) error {
+	err := fmt.Errorf("%s failed: status=%d, body=%s", op, status, body)
+	if status >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %v", gateway.ErrStorageUnavailable, err)
+	}
+	if status == http.StatusNotFound {
+		return fmt.Errorf("%w: %v", gateway.ErrObjectNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+const defaultProbeInterval = 10 * time.Second
+
+// HealthGate 存储健康闸门：观察到存储不可达后关闭闸门暂停出队，后台探测恢复后重新打开
+type HealthGate struct {
+	mu            sync.Mutex
+	healthy       bool
+	lastErr       error
+	downSince     time.Time
+	probing       bool
+	reopened      chan struct{}
+	probe         func(ctx context.Context) error
+	probeInterval time.Duration
+}
+
+var (
+	healthGateOnce      sync.Once
+	singletonHealthGate *HealthGate
+)
+
+// DefaultHealthGate 基于默认存储网关 Ping 的健康闸门
+func DefaultHealthGate() *HealthGate {
+	healthGateOnce.Do(func() {
+		interval := defaultProbeInterval
+		if cfg := config.GetGlobalConfig(); cfg != nil && cfg.Worker.StorageRetry.ProbeInterval > 0 {
+			interval = cfg.Worker.StorageRetry.ProbeInterval
+		}
+		singletonHealthGate = NewHealthGate(func(ctx context.Context) error {
+			return DefaultStorageGateway().Ping(ctx)
+		}, interval)
+	})
+	return singletonHealthGate
+}
+
+// NewHealthGate 创建健康闸门
+func NewHealthGate(probe func(ctx context.Context) error, probeInterval time.Duration) *HealthGate {
+	if probeInterval <= 0 {
+		probeInterval = defaultProbeInterval
+	}
+	metrics.Set("storage_healthy", 1)
+	return &HealthGate{healthy: true, reopened: make(chan struct{}), probe: probe, probeInterval: probeInterval}
+}
+
+// Healthy 存储当前是否可用
+func (g *HealthGate) Healthy() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.healthy
+}
+
+// Observe 根据存储调用结果更新闸门，仅存储不可达错误会关闭闸门
+func (g *HealthGate) Observe(err error) {
+	if !gateway.IsStorageUnavailable(err) {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.lastErr = err
+	if !g.healthy {
+		return
+	}
+	g.healthy = false
+	g.downSince = time.Now()
+	metrics.Set("storage_healthy", 0)
+	metrics.Add("storage_outages_total", 1)
+	logger.Warnf("storage unavailable, pausing dequeue error=%v", err)
+	if !g.probing {
+		g.probing = true
+		go g.probeUntilHealthy()
+	}
+}
+
+// Wait 阻塞直到存储可用或 ctx 结束
+func (g *HealthGate) Wait(ctx context.Context) error {
+	g.mu.Lock()
+	if g.healthy {
+		g.mu.Unlock()
+		return nil
+	}
+	ch := g.reopened
+	g.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-ch:
+		return nil
+	}
+}
+
+func (g *HealthGate) probeUntilHealthy() {
+	ticker := time.NewTicker(g.probeInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), g.probeInterval)
+		err := g.probe(ctx)
+		cancel()
+		if err != nil {
+			logger.Warnf("storage still unavailable error=%v", err)
+			continue
+		}
+		g.mu.Lock()
+		downtime := time.Since(g.downSince)
+		g.healthy = true
+		g.probing = false
+		g.lastErr = nil
+		close(g.reopened)
+		g.reopened = make(chan struct{})
+		g.mu.Unlock()
+		metrics.Set("storage_healthy", 1)
+		logger.Infof("storage recovered, resuming dequeue downtime=%s", downtime.Truncate(time.Second))
+		return
+	}
+}
@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// 探测目标状态
+const (
+	ProbeUp       = "up"       // 可达且延迟正常
+	ProbeDegraded = "degraded" // 可达但延迟超过 slow_threshold，或偶发不可达未达 failure_threshold
+	ProbeDown     = "down"     // 连续不可达达到 failure_threshold
+	ProbeUnknown  = "unknown"  // 尚未完成首次探测
+)
+
+// ProbeResult 单个目标最近一次探测结果
+type ProbeResult struct {
+	Name                string    `json:"name"`
+	CanaryKey           string    `json:"canary_key"`
+	Status              string    `json:"status"`
+	LatencyMs           int64     `json:"latency_ms"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	CheckedAt           time.Time `json:"checked_at"`
+	Optional            bool      `json:"optional"`
+}
+
+// HealthProber 存储健康探测器：按目标定时 HEAD 探针对象并计时。
+// 结果汇总到 /readyz 与 storage_probe_* 指标；gate 目标 down 时关闭出队闸门，恢复由闸门自身探测完成
+type HealthProber struct {
+	cfg     config.StorageProbeConfig
+	storage gateway.StorageGateway
+	gate    *HealthGate
+	mu      sync.RWMutex
+	results map[string]*ProbeResult
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+var (
+	healthProberOnce      sync.Once
+	singletonHealthProber *HealthProber
+)
+
+// DefaultHealthProber 未启用探测时返回 nil
+func DefaultHealthProber() *HealthProber {
+	healthProberOnce.Do(func() {
+		cfg := config.GetGlobalConfig()
+		if cfg == nil || !cfg.StorageProbe.Enabled {
+			return
+		}
+		singletonHealthProber = NewHealthProber(cfg.StorageProbe, DefaultStorageGateway(), DefaultHealthGate())
+	})
+	return singletonHealthProber
+}
+
+// NewHealthProber 创建探测器，各目标初始状态为 unknown
+func NewHealthProber(cfg config.StorageProbeConfig, storage gateway.StorageGateway, gate *HealthGate) *HealthProber {
+	p := &HealthProber{cfg: cfg, storage: storage, gate: gate, results: make(map[string]*ProbeResult, len(cfg.Targets))}
+	for _, t := range cfg.Targets {
+		p.results[t.Name] = &ProbeResult{Name: t.Name, CanaryKey: t.CanaryKey, Status: ProbeUnknown, Optional: t.Optional}
+	}
+	return p
+}
+
+func (p *HealthProber) Name() string { return "storageHealthProber" }
+
+// Start 启动时立即探测一轮，之后按 interval 周期探测
+func (p *HealthProber) Start(ctx context.Context) error {
+	probeCtx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			p.probeAll(probeCtx)
+			select {
+			case <-probeCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	logger.Infof("storage health prober started targets=%d interval=%s", len(p.cfg.Targets), p.cfg.Interval)
+	return nil
+}
+
+func (p *HealthProber) Stop() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+	return nil
+}
+
+// Results 按配置顺序返回各目标最近一次结果
+func (p *HealthProber) Results() []ProbeResult {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := make([]ProbeResult, 0, len(p.cfg.Targets))
+	for _, t := range p.cfg.Targets {
+		if r, ok := p.results[t.Name]; ok {
+			out = append(out, *r)
+		}
+	}
+	return out
+}
+
+// Ready 非 optional 目标均未 down 时为 true；首次探测完成前的 unknown 不阻塞就绪
+func (p *HealthProber) Ready() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, r := range p.results {
+		if !r.Optional && r.Status == ProbeDown {
+			return false
+		}
+	}
+	return true
+}
+
+// probeAll 各目标并行探测，互不阻塞
+func (p *HealthProber) probeAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, t := range p.cfg.Targets {
+		wg.Add(1)
+		go func(t config.StorageProbeTarget) {
+			defer wg.Done()
+			p.probe(ctx, t)
+		}(t)
+	}
+	wg.Wait()
+}
+
+func (p *HealthProber) probe(ctx context.Context, t config.StorageProbeTarget) {
+	probeCtx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	start := time.Now()
+	_, err := p.storage.StatObject(probeCtx, t.CanaryKey)
+	latency := time.Since(start)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+	// 只有不可达（网络错误、5xx、超时）算失败；404/403 等说明存储已正常应答
+	unavailable := gateway.IsStorageUnavailable(err) || probeCtx.Err() != nil
+	if unavailable && !gateway.IsStorageUnavailable(err) {
+		err = fmt.Errorf("%w: probe timed out after %s", gateway.ErrStorageUnavailable, p.cfg.Timeout)
+	}
+
+	prefix := "storage_probe_" + t.Name
+	metrics.Set(prefix+"_latency_ms", latency.Milliseconds())
+	p.mu.Lock()
+	r := p.results[t.Name]
+	prev := r.Status
+	r.LatencyMs = latency.Milliseconds()
+	r.CheckedAt = time.Now()
+	r.LastError = ""
+	if unavailable {
+		r.ConsecutiveFailures++
+		r.LastError = err.Error()
+		r.Status = ProbeDegraded
+		if r.ConsecutiveFailures >= p.cfg.FailureThreshold {
+			r.Status = ProbeDown
+		}
+	} else {
+		r.ConsecutiveFailures = 0
+		r.Status = ProbeUp
+		if latency > p.cfg.SlowThreshold {
+			r.Status = ProbeDegraded
+		}
+	}
+	status := r.Status
+	p.mu.Unlock()
+
+	if unavailable {
+		metrics.Add("storage_probe_failures_total", 1)
+		metrics.Add(prefix+"_failures_total", 1)
+	}
+	up := int64(0)
+	if status != ProbeDown {
+		up = 1
+	}
+	metrics.Set(prefix+"_up", up)
+	if status != prev && prev != ProbeUnknown {
+		logger.Warnf("storage probe status changed target=%s canary_key=%s from=%s to=%s latency=%s error=%v", t.Name, t.CanaryKey, prev, status, latency.Round(time.Millisecond), err)
+	}
+	if status == ProbeDown && t.Gate && p.gate != nil {
+		p.gate.Observe(err)
+	}
+}
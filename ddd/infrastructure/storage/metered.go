@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/infrastructure/throughput"
+)
+
+// meteredGateway 把成功下载/上传的字节数计入每日处理量统计
+type meteredGateway struct {
+	gateway.StorageGateway
+}
+
+// NewMeteredGateway 只用于转码/HLS 流水线；自检、压测、分析导出等走未计量的网关
+func NewMeteredGateway(inner gateway.StorageGateway) gateway.StorageGateway {
+	if inner == nil {
+		return nil
+	}
+	return &meteredGateway{StorageGateway: inner}
+}
+
+func (g *meteredGateway) DownloadFile(ctx context.Context, objectKey, localPath string) error {
+	if err := g.StorageGateway.DownloadFile(ctx, objectKey, localPath); err != nil {
+		return err
+	}
+	throughput.AddInputBytes(fileSize(localPath))
+	return nil
+}
+
+// OpenObject 读取的字节在关闭时计入
+func (g *meteredGateway) OpenObject(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	rc, err := g.StorageGateway.OpenObject(ctx, objectKey)
+	if err != nil {
+		return nil, err
+	}
+	return &meteredReader{ReadCloser: rc}, nil
+}
+
+type meteredReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *meteredReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *meteredReader) Close() error {
+	throughput.AddInputBytes(r.n)
+	r.n = 0
+	return r.ReadCloser.Close()
+}
+
+func (g *meteredGateway) UploadTranscodedFile(ctx context.Context, localPath, objectKey, contentType string) (string, error) {
+	key, err := g.StorageGateway.UploadTranscodedFile(ctx, localPath, objectKey, contentType)
+	if err != nil {
+		return "", err
+	}
+	throughput.AddOutputBytes(fileSize(localPath))
+	return key, nil
+}
+
+// UploadObjects 批量上传中途失败时已上传的对象无法区分，只在全部成功时计入
+func (g *meteredGateway) UploadObjects(ctx context.Context, objects []gateway.UploadObject) error {
+	if err := g.StorageGateway.UploadObjects(ctx, objects); err != nil {
+		return err
+	}
+	var total int64
+	for _, o := range objects {
+		total += fileSize(o.LocalPath)
+	}
+	throughput.AddOutputBytes(total)
+	return nil
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
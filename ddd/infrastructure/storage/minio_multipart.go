@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+
+	"transcode-service/ddd/domain/gateway"
+)
+
+func (s *MinioStorage) core() minio.Core {
+	return minio.Core{Client: s.minioResource.GetClient()}
+}
+
+// InitiateMultipart 创建上传会话
+func (s *MinioStorage) InitiateMultipart(ctx context.Context, objectKey, contentType string) (string, error) {
+	if contentType == "" {
+		contentType = getContentTypeFromExtension(objectKey)
+	}
+	bucket, key := s.locateObject(objectKey)
+	uploadID, err := s.core().NewMultipartUpload(ctx, bucket, key, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return "", classifyErr(fmt.Errorf("initiate multipart in minio failed: %w", err))
+	}
+	return uploadID, nil
+}
+
+// UploadPart 上传分段，携带 Content-MD5 由服务端校验
+func (s *MinioStorage) UploadPart(ctx context.Context, objectKey, uploadID string, partNumber int, data io.ReadSeeker, size int64) (gateway.CompletedPart, error) {
+	sum, err := readerMD5(data)
+	if err != nil {
+		return gateway.CompletedPart{}, fmt.Errorf("read part: %w", err)
+	}
+	raw, _ := hex.DecodeString(sum)
+	bucket, key := s.locateObject(objectKey)
+	part, err := s.core().PutObjectPart(ctx, bucket, key, uploadID, partNumber, data, size, minio.PutObjectPartOptions{
+		Md5Base64: base64.StdEncoding.EncodeToString(raw),
+	})
+	if err != nil {
+		return gateway.CompletedPart{}, classifyErr(fmt.Errorf("upload part to minio failed: %w", err))
+	}
+	return gateway.CompletedPart{PartNumber: partNumber, ETag: strings.Trim(part.ETag, `"`), Size: size}, nil
+}
+
+// ListParts 分页列出已上传分段
+func (s *MinioStorage) ListParts(ctx context.Context, objectKey, uploadID string) ([]gateway.CompletedPart, error) {
+	bucket, key := s.locateObject(objectKey)
+	var parts []gateway.CompletedPart
+	marker := 0
+	for {
+		res, err := s.core().ListObjectParts(ctx, bucket, key, uploadID, marker, 1000)
+		if err != nil {
+			return nil, classifyErr(fmt.Errorf("list parts from minio failed: %w", err))
+		}
+		for _, p := range res.ObjectParts {
+			parts = append(parts, gateway.CompletedPart{PartNumber: p.PartNumber, ETag: strings.Trim(p.ETag, `"`), Size: p.Size})
+		}
+		if !res.IsTruncated || res.NextPartNumberMarker <= marker {
+			return parts, nil
+		}
+		marker = res.NextPartNumberMarker
+	}
+}
+
+// CompleteMultipart 按分段号顺序合并分段
+func (s *MinioStorage) CompleteMultipart(ctx context.Context, objectKey, uploadID string, parts []gateway.CompletedPart) error {
+	complete := make([]minio.CompletePart, 0, len(parts))
+	for _, p := range parts {
+		complete = append(complete, minio.CompletePart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	sort.Slice(complete, func(i, j int) bool { return complete[i].PartNumber < complete[j].PartNumber })
+	bucket, key := s.locateObject(objectKey)
+	if _, err := s.core().CompleteMultipartUpload(ctx, bucket, key, uploadID, complete, minio.PutObjectOptions{}); err != nil {
+		return classifyErr(fmt.Errorf("complete multipart in minio failed: %w", err))
+	}
+	return nil
+}
+
+// AbortMultipart 放弃会话，会话不存在时视为成功
+func (s *MinioStorage) AbortMultipart(ctx context.Context, objectKey, uploadID string) error {
+	bucket, key := s.locateObject(objectKey)
+	err := classifyErr(s.core().AbortMultipartUpload(ctx, bucket, key, uploadID))
+	if err != nil && !gateway.IsObjectNotFound(err) {
+		return fmt.Errorf("abort multipart in minio failed: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,26 +11,30 @@ import (
 	"github.com/minio/minio-go/v7"
 
 	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/vo"
 	"transcode-service/internal/resource"
+	"transcode-service/pkg/config"
 	"transcode-service/pkg/logger"
 )
 
 // MinioStorage MinIO存储实现
 type MinioStorage struct {
 	minioResource *resource.MinioResource
+	multipart     config.MultipartConfig
 }
 
 // NewMinioStorage 创建MinIO存储实例
 func NewMinioStorage(minioResource *resource.MinioResource) gateway.StorageGateway {
 	return &MinioStorage{
 		minioResource: minioResource,
+		multipart:     multipartConfig(),
 	}
 }
 
 // UploadTranscodedFile 上传转码后的文件，返回可访问的对象路径
 func (s *MinioStorage) UploadTranscodedFile(ctx context.Context, localPath, objectKey, contentType string) (string, error) {
 	client := s.minioResource.GetClient()
-	bucketName := s.minioResource.GetBucketName()
+	bucketName, key := s.locateObject(objectKey)
 
 	// 打开本地文件
 	file, err := os.Open(localPath)
@@ -56,8 +61,21 @@ func (s *MinioStorage) UploadTranscodedFile(ctx context.Context, localPath, obje
 		contentType = getContentTypeFromExtension(objectKey)
 	}
 
+	// 大文件分段上传，失败重试时续传已上传的分段
+	if useMultipart(s.multipart, fileInfo.Size()) {
+		if err := uploadMultipart(ctx, s, s.multipart, localPath, objectKey, contentType); err != nil {
+			logger.Error("Failed to multipart upload transcoded file to MinIO", map[string]interface{}{
+				"local_path": localPath,
+				"object_key": objectKey,
+				"error":      err.Error(),
+			})
+			return "", err
+		}
+		return objectKey, nil
+	}
+
 	// 上传文件到MinIO
-	_, err = client.PutObject(ctx, bucketName, objectKey, file, fileInfo.Size(), minio.PutObjectOptions{
+	_, err = client.PutObject(ctx, bucketName, key, file, fileInfo.Size(), minio.PutObjectOptions{
 		ContentType: contentType,
 	})
 	if err != nil {
@@ -66,7 +84,7 @@ func (s *MinioStorage) UploadTranscodedFile(ctx context.Context, localPath, obje
 			"object_key": objectKey,
 			"error":      err.Error(),
 		})
-		return "", fmt.Errorf("upload transcoded file to minio failed: %w", err)
+		return "", classifyErr(fmt.Errorf("upload transcoded file to minio failed: %w", err))
 	}
 
 	logger.Info("Transcoded file uploaded successfully", map[string]interface{}{
@@ -86,7 +104,6 @@ func (s *MinioStorage) UploadObjects(ctx context.Context, objects []gateway.Uplo
 	}
 
 	client := s.minioResource.GetClient()
-	bucketName := s.minioResource.GetBucketName()
 
 	for _, obj := range objects {
 		file, err := os.Open(obj.LocalPath)
@@ -113,7 +130,21 @@ func (s *MinioStorage) UploadObjects(ctx context.Context, objects []gateway.Uplo
 			contentType = getContentTypeFromExtension(obj.ObjectKey)
 		}
 
-		_, err = client.PutObject(ctx, bucketName, obj.ObjectKey, file, fileInfo.Size(), minio.PutObjectOptions{
+		if useMultipart(s.multipart, fileInfo.Size()) {
+			file.Close()
+			if err := uploadMultipart(ctx, s, s.multipart, obj.LocalPath, obj.ObjectKey, contentType); err != nil {
+				logger.Error("Failed to multipart upload object during batch upload", map[string]interface{}{
+					"local_path": obj.LocalPath,
+					"object_key": obj.ObjectKey,
+					"error":      err.Error(),
+				})
+				return err
+			}
+			continue
+		}
+
+		bucketName, key := s.locateObject(obj.ObjectKey)
+		_, err = client.PutObject(ctx, bucketName, key, file, fileInfo.Size(), minio.PutObjectOptions{
 			ContentType: contentType,
 		})
 		file.Close()
@@ -123,7 +154,7 @@ func (s *MinioStorage) UploadObjects(ctx context.Context, objects []gateway.Uplo
 				"object_key": obj.ObjectKey,
 				"error":      err.Error(),
 			})
-			return fmt.Errorf("upload object to minio failed: %w", err)
+			return classifyErr(fmt.Errorf("upload object to minio failed: %w", err))
 		}
 
 		logger.Info("Uploaded object", map[string]interface{}{
@@ -139,7 +170,7 @@ func (s *MinioStorage) UploadObjects(ctx context.Context, objects []gateway.Uplo
 // DownloadFile 从MinIO下载文件到本地路径
 func (s *MinioStorage) DownloadFile(ctx context.Context, objectKey, localPath string) error {
 	client := s.minioResource.GetClient()
-	bucketName := s.minioResource.GetBucketName()
+	bucketName, key := s.locateObject(objectKey)
 
 	// 确保本地目录存在
 	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
@@ -151,13 +182,13 @@ func (s *MinioStorage) DownloadFile(ctx context.Context, objectKey, localPath st
 	}
 
 	// 从MinIO获取对象
-	object, err := client.GetObject(ctx, bucketName, objectKey, minio.GetObjectOptions{})
+	object, err := client.GetObject(ctx, bucketName, key, minio.GetObjectOptions{})
 	if err != nil {
 		logger.Error("Failed to get object from MinIO", map[string]interface{}{
 			"object_key": objectKey,
 			"error":      err.Error(),
 		})
-		return fmt.Errorf("get object from minio failed: %w", err)
+		return classifyErr(fmt.Errorf("get object from minio failed: %w", err))
 	}
 	defer object.Close()
 
@@ -180,7 +211,7 @@ func (s *MinioStorage) DownloadFile(ctx context.Context, objectKey, localPath st
 			"local_path": localPath,
 			"error":      err.Error(),
 		})
-		return fmt.Errorf("download file from minio failed: %w", err)
+		return classifyErr(fmt.Errorf("download file from minio failed: %w", err))
 	}
 
 	logger.Info("File downloaded successfully", map[string]interface{}{
@@ -191,29 +222,83 @@ func (s *MinioStorage) DownloadFile(ctx context.Context, objectKey, localPath st
 	return nil
 }
 
+// OpenObject 先 Stat 一次，使对象不存在、存储不可达等错误在打开时返回而不是推迟到首次读取
+func (s *MinioStorage) OpenObject(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	bucketName, key := s.locateObject(objectKey)
+	object, err := s.minioResource.GetClient().GetObject(ctx, bucketName, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, classifyErr(fmt.Errorf("get object from minio failed: %w", err))
+	}
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, classifyErr(fmt.Errorf("get object from minio failed: %w", err))
+	}
+	return object, nil
+}
+
+func (s *MinioStorage) StatObject(ctx context.Context, objectKey string) (gateway.ObjectInfo, error) {
+	bucket, key := s.locateObject(objectKey)
+	info, err := s.minioResource.GetClient().StatObject(ctx, bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return gateway.ObjectInfo{}, classifyErr(fmt.Errorf("stat object from minio failed: %w", err))
+	}
+	return gateway.ObjectInfo{Size: info.Size, ETag: strings.Trim(info.ETag, `"`)}, nil
+}
+
+// CopyObject 服务端复制；设置存储类别时需替换元数据，内容类型按扩展名重新设置
+func (s *MinioStorage) CopyObject(ctx context.Context, srcKey, dstKey, storageClass string) error {
+	dstBucket, dstObject := s.locateObject(dstKey)
+	srcBucket, srcObject := s.locateObject(srcKey)
+	dst := minio.CopyDestOptions{Bucket: dstBucket, Object: dstObject}
+	if storageClass != "" {
+		dst.ReplaceMetadata = true
+		dst.UserMetadata = map[string]string{
+			"X-Amz-Storage-Class": storageClass,
+			"Content-Type":        getContentTypeFromExtension(dstKey),
+		}
+	}
+	if _, err := s.minioResource.GetClient().CopyObject(ctx, dst, minio.CopySrcOptions{Bucket: srcBucket, Object: srcObject}); err != nil {
+		return classifyErr(fmt.Errorf("copy object in minio failed: %w", err))
+	}
+	return nil
+}
+
+// RemoveObject 删除对象，对象不存在时 MinIO 同样返回成功
+func (s *MinioStorage) RemoveObject(ctx context.Context, objectKey string) error {
+	bucket, key := s.locateObject(objectKey)
+	if err := s.minioResource.GetClient().RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return classifyErr(fmt.Errorf("remove object from minio failed: %w", err))
+	}
+	return nil
+}
+
+// Ping 检查 bucket 是否可访问
+func (s *MinioStorage) Ping(ctx context.Context) error {
+	if _, err := s.minioResource.GetClient().BucketExists(ctx, s.minioResource.GetBucketName()); err != nil {
+		return classifyErr(fmt.Errorf("ping minio: %w", err))
+	}
+	return nil
+}
+
+// locateObject 对象所在的桶与桶内 key：/buckets/<bucket>/<key> 路由到请求指定的桶，其余使用默认桶
+func (s *MinioStorage) locateObject(objectKey string) (string, string) {
+	if bucket, key, ok := vo.SplitBucketKey(objectKey); ok {
+		return bucket, key
+	}
+	return s.minioResource.GetBucketName(), objectKey
+}
+
 // getContentTypeFromExtension 根据文件扩展名获取内容类型
 func getContentTypeFromExtension(filename string) string {
 	ext := strings.ToLower(filepath.Ext(filename))
 	switch ext {
-	case ".mp4":
-		return "video/mp4"
-	case ".m3u8":
-		return "application/vnd.apple.mpegurl"
-	case ".ts":
-		return "video/mp2t"
 	case ".avi":
 		return "video/x-msvideo"
-	case ".mov":
-		return "video/quicktime"
 	case ".wmv":
 		return "video/x-ms-wmv"
 	case ".flv":
 		return "video/x-flv"
-	case ".webm":
-		return "video/webm"
-	case ".mkv":
-		return "video/x-matroska"
 	default:
-		return "application/octet-stream"
+		return vo.ContentTypeForExtension(ext)
 	}
 }
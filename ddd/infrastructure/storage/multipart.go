@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// maxMultipartParts S3 单个上传会话的分段数上限
+const maxMultipartParts = 10000
+
+// multipartState 未完成的上传会话，记录在本地文件旁；文件大小、修改时间或分段大小变化时会话作废
+type multipartState struct {
+	ObjectKey string `json:"object_key"`
+	UploadID  string `json:"upload_id"`
+	Size      int64  `json:"size"`
+	ModTime   int64  `json:"mod_time"`
+	PartSize  int64  `json:"part_size"`
+}
+
+// useMultipart 文件是否走分段上传
+func useMultipart(cfg config.MultipartConfig, size int64) bool {
+	return cfg.Enabled && size >= cfg.Threshold && cfg.PartSize > 0
+}
+
+// multipartPartSize 分段数超过上限时按 1MB 对齐放大分段
+func multipartPartSize(cfg config.MultipartConfig, size int64) int64 {
+	partSize := cfg.PartSize
+	if least := (size + maxMultipartParts - 1) / maxMultipartParts; partSize < least {
+		partSize = (least + 1<<20 - 1) &^ (1<<20 - 1)
+	}
+	return partSize
+}
+
+// multipartStatePath 会话记录文件：与本地文件同目录的隐藏文件，随工作目录一起清理
+func multipartStatePath(localPath string) string {
+	return filepath.Join(filepath.Dir(localPath), "."+filepath.Base(localPath)+".multipart")
+}
+
+// uploadMultipart 分段上传本地文件：按分段大小切分、并发上传，单段遇到存储瞬时故障时退避重试；
+// 上一次同一文件同一目标的会话未完成时列出已上传分段，大小与 MD5 一致的分段直接复用。
+// 瞬时故障与取消时保留会话以便续传，其余错误放弃会话
+func uploadMultipart(ctx context.Context, mp gateway.MultipartUploader, cfg config.MultipartConfig, localPath, objectKey, contentType string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("open local file: %w", err)
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := stat.Size()
+	want := multipartState{ObjectKey: objectKey, Size: size, ModTime: stat.ModTime().UnixNano(), PartSize: multipartPartSize(cfg, size)}
+	statePath := multipartStatePath(localPath)
+
+	uploadID, uploaded := resumeMultipart(ctx, mp, statePath, want)
+	if uploadID == "" {
+		if uploadID, err = mp.InitiateMultipart(ctx, objectKey, contentType); err != nil {
+			return err
+		}
+		want.UploadID = uploadID
+		saveMultipartState(statePath, want)
+	}
+	metrics.Add("storage_multipart_uploads_total", 1)
+
+	count := int((size + want.PartSize - 1) / want.PartSize)
+	if count == 0 {
+		count = 1
+	}
+	parts := make([]gateway.CompletedPart, count)
+	pending := make([]int, 0, count)
+	for i := range parts {
+		off, n := int64(i)*want.PartSize, want.PartSize
+		if off+n > size {
+			n = size - off
+		}
+		if p, ok := uploaded[i+1]; ok && p.Size == n && partMatches(f, off, n, p.ETag) {
+			parts[i] = p
+			metrics.Add("storage_multipart_parts_resumed_total", 1)
+			continue
+		}
+		pending = append(pending, i)
+	}
+	if resumed := count - len(pending); resumed > 0 {
+		logger.Infof("multipart upload resumed key=%s upload_id=%s parts=%d resumed=%d", objectKey, uploadID, count, resumed)
+	}
+
+	if err := uploadParts(ctx, mp, cfg, f, size, want, pending, parts); err != nil {
+		metrics.Add("storage_multipart_failed_total", 1)
+		if !gateway.IsStorageUnavailable(err) && ctx.Err() == nil {
+			abortMultipart(mp, objectKey, uploadID, statePath)
+		}
+		return err
+	}
+	if err := mp.CompleteMultipart(ctx, objectKey, uploadID, parts); err != nil {
+		metrics.Add("storage_multipart_failed_total", 1)
+		if gateway.IsObjectNotFound(err) {
+			// 会话已被清理，下次重新上传
+			_ = os.Remove(statePath)
+		}
+		return err
+	}
+	_ = os.Remove(statePath)
+	metrics.Add("storage_multipart_completed_total", 1)
+	return nil
+}
+
+// resumeMultipart 读取会话记录并列出已上传分段；记录与当前文件不符或会话已失效时放弃旧会话，返回空 uploadID
+func resumeMultipart(ctx context.Context, mp gateway.MultipartUploader, statePath string, want multipartState) (string, map[int]gateway.CompletedPart) {
+	b, err := os.ReadFile(statePath)
+	if err != nil {
+		return "", nil
+	}
+	var st multipartState
+	if json.Unmarshal(b, &st) != nil || st.UploadID == "" {
+		_ = os.Remove(statePath)
+		return "", nil
+	}
+	if st.ObjectKey != want.ObjectKey || st.Size != want.Size || st.ModTime != want.ModTime || st.PartSize != want.PartSize {
+		abortMultipart(mp, st.ObjectKey, st.UploadID, statePath)
+		return "", nil
+	}
+	parts, err := mp.ListParts(ctx, st.ObjectKey, st.UploadID)
+	if err != nil {
+		logger.Warnf("multipart session not resumable, starting over key=%s upload_id=%s error=%v", st.ObjectKey, st.UploadID, err)
+		if !gateway.IsObjectNotFound(err) {
+			abortMultipart(mp, st.ObjectKey, st.UploadID, statePath)
+		}
+		_ = os.Remove(statePath)
+		return "", nil
+	}
+	uploaded := make(map[int]gateway.CompletedPart, len(parts))
+	for _, p := range parts {
+		uploaded[p.PartNumber] = p
+	}
+	return st.UploadID, uploaded
+}
+
+// uploadParts 并发上传待传分段，首个失败取消其余分段
+func uploadParts(ctx context.Context, mp gateway.MultipartUploader, cfg config.MultipartConfig, f *os.File, size int64, st multipartState, pending []int, parts []gateway.CompletedPart) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	workers := cfg.Parallelism
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(pending) {
+		workers = len(pending)
+	}
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				off, n := int64(i)*st.PartSize, st.PartSize
+				if off+n > size {
+					n = size - off
+				}
+				p, err := uploadPartWithRetry(ctx, mp, cfg.PartRetries, st, i+1, io.NewSectionReader(f, off, n), n)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				parts[i] = p
+			}
+		}()
+	}
+	for _, i := range pending {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// uploadPartWithRetry 分段遇到存储瞬时故障或校验不一致时按 1s、2s、4s… 退避重试
+func uploadPartWithRetry(ctx context.Context, mp gateway.MultipartUploader, retries int, st multipartState, partNumber int, data io.ReadSeeker, size int64) (gateway.CompletedPart, error) {
+	for attempt := 0; ; attempt++ {
+		if _, err := data.Seek(0, io.SeekStart); err != nil {
+			return gateway.CompletedPart{}, err
+		}
+		p, err := mp.UploadPart(ctx, st.ObjectKey, st.UploadID, partNumber, data, size)
+		if err == nil {
+			metrics.Add("storage_multipart_parts_uploaded_total", 1)
+			return p, nil
+		}
+		if !gateway.IsStorageUnavailable(err) || attempt >= retries || ctx.Err() != nil {
+			return gateway.CompletedPart{}, fmt.Errorf("upload part %d: %w", partNumber, err)
+		}
+		metrics.Add("storage_multipart_part_retries_total", 1)
+		logger.Warnf("multipart part upload failed, retrying key=%s part=%d attempt=%d error=%v", st.ObjectKey, partNumber, attempt+1, err)
+		select {
+		case <-ctx.Done():
+			return gateway.CompletedPart{}, ctx.Err()
+		case <-time.After(time.Second << attempt):
+		}
+	}
+}
+
+// partMatches 已上传分段的 ETag 为 MD5 时与本地内容比对，ETag 不是 MD5（如 SSE-KMS）时只比对大小
+func partMatches(f *os.File, off, size int64, etag string) bool {
+	if !isMD5ETag(etag) {
+		return true
+	}
+	sum, err := readerMD5(io.NewSectionReader(f, off, size))
+	return err == nil && strings.EqualFold(sum, etag)
+}
+
+// readerMD5 计算内容的 MD5 后回到起点
+func readerMD5(r io.ReadSeeker) (string, error) {
+	h := md5.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func saveMultipartState(statePath string, st multipartState) {
+	b, _ := json.Marshal(st)
+	if err := os.WriteFile(statePath, b, 0o644); err != nil {
+		// 只影响续传，不影响本次上传
+		logger.Warnf("save multipart state failed path=%s error=%v", statePath, err)
+	}
+}
+
+// abortMultipart 放弃会话并删除记录，失败只记录（残留由桶的 AbortIncompleteMultipartUpload 规则清理）
+func abortMultipart(mp gateway.MultipartUploader, objectKey, uploadID, statePath string) {
+	_ = os.Remove(statePath)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := mp.AbortMultipart(ctx, objectKey, uploadID); err != nil && !errors.Is(err, gateway.ErrObjectNotFound) {
+		logger.Warnf("abort multipart upload failed key=%s upload_id=%s error=%v", objectKey, uploadID, err)
+		return
+	}
+	metrics.Add("storage_multipart_aborted_total", 1)
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"encoding/hex"
+	"fmt"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPresignTTL SigV4 预签名 URL 的最长有效期
+const maxPresignTTL = 7 * 24 * time.Hour
+
+// ObjectURLSigner 由存储端点直接生成对象 URL，public.storage_base 未配置时用于兜底
+type ObjectURLSigner interface {
+	// ObjectURL 对象的直连地址（path-style），需桶允许匿名读
+	ObjectURL(objectKey string) string
+	// PresignGetObject 生成 SigV4 查询串签名的 GET URL，ttl 超过 7 天时截断
+	PresignGetObject(objectKey string, ttl time.Duration) (string, error)
+}
+
+// ObjectURL 实现 ObjectURLSigner
+func (s *RustFSStorage) ObjectURL(objectKey string) string {
+	return s.objectURL(objectKey)
+}
+
+// PresignGetObject 实现 ObjectURLSigner
+func (s *RustFSStorage) PresignGetObject(objectKey string, ttl time.Duration) (string, error) {
+	return s.presign("GET", objectKey, ttl, time.Now())
+}
+
+func (s *RustFSStorage) presign(method, objectKey string, ttl time.Duration, now time.Time) (string, error) {
+	if s.access == "" || s.secret == "" {
+		return "", fmt.Errorf("presign %s: storage credentials not configured", objectKey)
+	}
+	if ttl <= 0 || ttl > maxPresignTTL {
+		ttl = maxPresignTTL
+	}
+	u, err := neturl.Parse(s.objectURL(objectKey))
+	if err != nil {
+		return "", fmt.Errorf("presign %s: %w", objectKey, err)
+	}
+	t := now.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	scope := strings.Join([]string{date, s.region, "s3", "aws4_request"}, "/")
+
+	q := neturl.Values{}
+	q.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	q.Set("X-Amz-Credential", s.access+"/"+scope)
+	q.Set("X-Amz-Date", amzDate)
+	q.Set("X-Amz-Expires", strconv.FormatInt(int64(ttl/time.Second), 10))
+	q.Set("X-Amz-SignedHeaders", "host")
+	// Values.Encode 按 key 排序，空格编码为 +，SigV4 要求 %20
+	canonicalQuery := strings.ReplaceAll(q.Encode(), "+", "%20")
+
+	cr := strings.Join([]string{method, u.EscapedPath(), canonicalQuery, "host:" + u.Host + "\n", "host", "UNSIGNED-PAYLOAD"}, "\n")
+	sts := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(cr))}, "\n")
+	kDate := hmacSHA256([]byte("AWS4"+s.secret), date)
+	kRegion := hmacSHA256(kDate, s.region)
+	kService := hmacSHA256(kRegion, "s3")
+	kSigning := hmacSHA256(kService, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(kSigning, sts))
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + sig
+	return u.String(), nil
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"transcode-service/ddd/domain/gateway"
+)
+
+type initiateMultipartResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type listPartsResult struct {
+	IsTruncated          bool
+	NextPartNumberMarker int
+	Parts                []struct {
+		PartNumber int
+		ETag       string
+		Size       int64
+	} `xml:"Part"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []completePart
+}
+
+type completePart struct {
+	XMLName    xml.Name `xml:"Part"`
+	PartNumber int
+	ETag       string
+}
+
+// InitiateMultipart POST ?uploads 创建上传会话
+func (s *RustFSStorage) InitiateMultipart(ctx context.Context, objectKey, contentType string) (string, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	body, err := s.multipartRequest(ctx, http.MethodPost, objectKey, neturl.Values{"uploads": {""}}, nil, contentType, "initiate multipart")
+	if err != nil {
+		return "", err
+	}
+	var res initiateMultipartResult
+	if err := xml.Unmarshal(body, &res); err != nil || res.UploadID == "" {
+		return "", fmt.Errorf("initiate multipart: invalid response: %s", string(body))
+	}
+	return res.UploadID, nil
+}
+
+// UploadPart PUT ?partNumber&uploadId 上传分段，携带 Content-MD5 并比对响应 ETag
+func (s *RustFSStorage) UploadPart(ctx context.Context, objectKey, uploadID string, partNumber int, data io.ReadSeeker, size int64) (gateway.CompletedPart, error) {
+	sh, m := sha256.New(), md5.New()
+	if _, err := io.Copy(io.MultiWriter(sh, m), data); err != nil {
+		return gateway.CompletedPart{}, fmt.Errorf("read part: %w", err)
+	}
+	if _, err := data.Seek(0, io.SeekStart); err != nil {
+		return gateway.CompletedPart{}, err
+	}
+	hash, sum := hex.EncodeToString(sh.Sum(nil)), m.Sum(nil)
+
+	q := neturl.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(objectKey)+"?"+q.Encode(), io.LimitReader(data, size))
+	if err != nil {
+		return gateway.CompletedPart{}, fmt.Errorf("create request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("x-amz-content-sha256", hash)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum))
+	s.signS3(req, hash)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return gateway.CompletedPart{}, classifyErr(fmt.Errorf("upload part: %w", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if strings.Contains(string(b), "BadDigest") {
+			return gateway.CompletedPart{}, fmt.Errorf("%w key=%s part=%d: %s", errChecksumMismatch, objectKey, partNumber, statusErr("upload part", resp.StatusCode, string(b)))
+		}
+		return gateway.CompletedPart{}, statusErr("upload part", resp.StatusCode, string(b))
+	}
+	etag := strings.Trim(resp.Header.Get("ETag"), `"`)
+	if isMD5ETag(etag) && !strings.EqualFold(etag, hex.EncodeToString(sum)) {
+		return gateway.CompletedPart{}, fmt.Errorf("%w key=%s part=%d want_md5=%x got_etag=%s", errChecksumMismatch, objectKey, partNumber, sum, etag)
+	}
+	return gateway.CompletedPart{PartNumber: partNumber, ETag: etag, Size: size}, nil
+}
+
+// ListParts GET ?uploadId 分页列出已上传分段
+func (s *RustFSStorage) ListParts(ctx context.Context, objectKey, uploadID string) ([]gateway.CompletedPart, error) {
+	var parts []gateway.CompletedPart
+	marker := 0
+	for {
+		q := neturl.Values{"uploadId": {uploadID}}
+		if marker > 0 {
+			q.Set("part-number-marker", strconv.Itoa(marker))
+		}
+		body, err := s.multipartRequest(ctx, http.MethodGet, objectKey, q, nil, "", "list parts")
+		if err != nil {
+			return nil, err
+		}
+		var res listPartsResult
+		if err := xml.Unmarshal(body, &res); err != nil {
+			return nil, fmt.Errorf("list parts: invalid response: %w", err)
+		}
+		for _, p := range res.Parts {
+			parts = append(parts, gateway.CompletedPart{PartNumber: p.PartNumber, ETag: strings.Trim(p.ETag, `"`), Size: p.Size})
+		}
+		if !res.IsTruncated || res.NextPartNumberMarker <= marker {
+			return parts, nil
+		}
+		marker = res.NextPartNumberMarker
+	}
+}
+
+// CompleteMultipart POST ?uploadId 合并分段；S3 可能返回 200 但响应体是 <Error>，按服务端故障处理
+func (s *RustFSStorage) CompleteMultipart(ctx context.Context, objectKey, uploadID string, parts []gateway.CompletedPart) error {
+	sorted := append([]gateway.CompletedPart(nil), parts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PartNumber < sorted[j].PartNumber })
+	doc := completeMultipartUpload{Parts: make([]completePart, 0, len(sorted))}
+	for _, p := range sorted {
+		doc.Parts = append(doc.Parts, completePart{PartNumber: p.PartNumber, ETag: `"` + p.ETag + `"`})
+	}
+	payload, err := xml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	body, err := s.multipartRequest(ctx, http.MethodPost, objectKey, neturl.Values{"uploadId": {uploadID}}, payload, "application/xml", "complete multipart")
+	if err != nil {
+		return err
+	}
+	if strings.Contains(string(body), "<Error>") {
+		return statusErr("complete multipart", http.StatusInternalServerError, string(body))
+	}
+	return nil
+}
+
+// AbortMultipart DELETE ?uploadId 放弃会话，会话不存在时视为成功
+func (s *RustFSStorage) AbortMultipart(ctx context.Context, objectKey, uploadID string) error {
+	_, err := s.multipartRequest(ctx, http.MethodDelete, objectKey, neturl.Values{"uploadId": {uploadID}}, nil, "", "abort multipart")
+	if gateway.IsObjectNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// multipartRequest 发送分段上传的控制请求并返回响应体；查询参数按 Values.Encode 排序，与签名的 canonical query 一致
+func (s *RustFSStorage) multipartRequest(ctx context.Context, method, objectKey string, query neturl.Values, payload []byte, contentType, op string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(objectKey)+"?"+query.Encode(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.ContentLength = int64(len(payload))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	hash := sha256Hex(payload)
+	req.Header.Set("x-amz-content-sha256", hash)
+	s.signS3(req, hash)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, classifyErr(fmt.Errorf("%s: %w", op, err))
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, classifyErr(fmt.Errorf("%s: %w", op, err))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, statusErr(op, resp.StatusCode, string(b))
+	}
+	return b, nil
+}
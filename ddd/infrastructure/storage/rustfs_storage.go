@@ -3,8 +3,12 @@ package storage
 import (
 	"context"
 	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,58 +20,280 @@ import (
 	"time"
 
 	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
 	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+	"transcode-service/pkg/utils"
 )
 
 type RustFSStorage struct {
-	endpoint string
-	access   string
-	secret   string
-	region   string
+	endpoint  string
+	access    string
+	secret    string
+	region    string
+	directPut bool
+	// verifyChecksum 上传时携带 Content-MD5，并在上传后 HEAD 比对大小与 ETag；不一致时重传 checksumRetries 次
+	verifyChecksum  bool
+	checksumRetries int
+	multipart       config.MultipartConfig
 }
 
-func NewRustFSStorage(endpoint, access, secret string) gateway.StorageGateway {
-	return &RustFSStorage{endpoint: normalizeEndpoint(endpoint), access: access, secret: secret, region: "us-east-1"}
+// RustFSOptions 上传行为选项
+type RustFSOptions struct {
+	DirectPut       bool
+	VerifyChecksum  bool
+	ChecksumRetries int
+	Multipart       config.MultipartConfig
 }
 
+// errChecksumMismatch 上传后对象大小或 MD5 与本地文件不一致，按瞬时故障处理
+var errChecksumMismatch = fmt.Errorf("%w: uploaded object checksum mismatch", gateway.ErrStorageUnavailable)
+
+// stagingDir 临时键所在目录，位于目标键的首级前缀下以保证与目标键同桶，可按该前缀配置生命周期规则清理残留
+const stagingDir = ".staging"
+
+// emptyPayloadHash 空请求体的 SHA256
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// NewRustFSStorage DirectPut 为 true 时直接 PUT 到目标键，否则先上传临时键、校验后服务端复制到目标键
+func NewRustFSStorage(endpoint, access, secret string, opts RustFSOptions) gateway.StorageGateway {
+	return &RustFSStorage{
+		endpoint:        normalizeEndpoint(endpoint),
+		access:          access,
+		secret:          secret,
+		region:          "us-east-1",
+		directPut:       opts.DirectPut,
+		verifyChecksum:  opts.VerifyChecksum,
+		checksumRetries: opts.ChecksumRetries,
+		multipart:       opts.Multipart,
+	}
+}
+
+// UploadTranscodedFile 上传产物。RustFS 的 PutObject 中途失败可能留下不完整对象，
+// 因此先上传到临时键，HEAD 校验大小后服务端复制到目标键再删除临时键：
+// 目标键只会在内容完整时出现，失败重试也不会让读取方看到半截产物。
+// 开启校验时目标键上传后再比对 MD5，不一致时整体重传，重传用尽后按瞬时故障返回
 func (s *RustFSStorage) UploadTranscodedFile(ctx context.Context, localPath, objectKey, contentType string) (string, error) {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	for attempt := 0; ; attempt++ {
+		err := s.uploadOnce(ctx, localPath, objectKey, contentType)
+		if err == nil {
+			return objectKey, nil
+		}
+		if !errors.Is(err, errChecksumMismatch) {
+			return "", err
+		}
+		metrics.Add("storage_checksum_mismatch_total", 1)
+		if !s.verifyChecksum || attempt >= s.checksumRetries || ctx.Err() != nil {
+			return "", err
+		}
+		metrics.Add("storage_checksum_reuploads_total", 1)
+		logger.Warnf("RustFS upload checksum mismatch, re-uploading key=%s attempt=%d error=%v", objectKey, attempt+1, err)
+	}
+}
+
+func (s *RustFSStorage) uploadOnce(ctx context.Context, localPath, objectKey, contentType string) error {
+	if st, err := os.Stat(localPath); err == nil && useMultipart(s.multipart, st.Size()) {
+		// 分段上传的对象合并后才出现，无需临时键；合并后的 ETag 不是 MD5，只比对大小
+		if err := uploadMultipart(ctx, s, s.multipart, localPath, objectKey, contentType); err != nil {
+			return err
+		}
+		return s.verifyObject(ctx, objectKey, st.Size(), "")
+	}
+	if s.directPut {
+		size, sum, err := s.putObject(ctx, localPath, objectKey, contentType)
+		if err != nil {
+			return err
+		}
+		if s.verifyChecksum {
+			return s.verifyObject(ctx, objectKey, size, sum)
+		}
+		return nil
+	}
+
+	tmpKey := stagingKey(objectKey)
+	// 临时键清理不受调用方取消影响
+	defer s.removeStaged(tmpKey)
+	size, sum, err := s.putObject(ctx, localPath, tmpKey, contentType)
+	if err != nil {
+		metrics.Add("storage_staged_upload_failed_total", 1)
+		return err
+	}
+	if !s.verifyChecksum {
+		sum = ""
+	}
+	if err := s.verifyObject(ctx, tmpKey, size, sum); err != nil {
+		metrics.Add("storage_staged_upload_failed_total", 1)
+		return fmt.Errorf("verify staged object: %w", err)
+	}
+	if err := s.copyObject(ctx, tmpKey, objectKey, ""); err != nil {
+		metrics.Add("storage_staged_upload_failed_total", 1)
+		return err
+	}
+	if s.verifyChecksum {
+		// 复制由存储节点完成，目标键同样可能写坏
+		return s.verifyObject(ctx, objectKey, size, sum)
+	}
+	return nil
+}
+
+// verifyObject HEAD 比对大小，md5Hex 非空时再比对 ETag；ETag 不是单段上传的 MD5（分段上传、SSE-KMS）时只比对大小
+func (s *RustFSStorage) verifyObject(ctx context.Context, key string, size int64, md5Hex string) error {
+	info, err := s.StatObject(ctx, key)
+	if err != nil {
+		return err
+	}
+	if info.Size != size {
+		return fmt.Errorf("%w key=%s want_size=%d got_size=%d", errChecksumMismatch, key, size, info.Size)
+	}
+	if md5Hex == "" {
+		return nil
+	}
+	if !isMD5ETag(info.ETag) {
+		metrics.Add("storage_checksum_unverifiable_total", 1)
+		return nil
+	}
+	if !strings.EqualFold(info.ETag, md5Hex) {
+		return fmt.Errorf("%w key=%s want_md5=%s got_etag=%s", errChecksumMismatch, key, md5Hex, info.ETag)
+	}
+	metrics.Add("storage_checksum_verified_total", 1)
+	return nil
+}
+
+// putObject 单次 PUT 上传本地文件，返回上传的字节数与本地文件的 MD5（十六进制）。
+// 开启校验时携带 Content-MD5，传输中损坏的请求体由存储端直接拒绝（BadDigest）
+func (s *RustFSStorage) putObject(ctx context.Context, localPath, objectKey, contentType string) (int64, string, error) {
 	f, err := os.Open(localPath)
 	if err != nil {
-		return "", fmt.Errorf("open local file: %w", err)
+		return 0, "", fmt.Errorf("open local file: %w", err)
 	}
 	defer f.Close()
 	stat, err := f.Stat()
 	if err != nil {
-		return "", err
-	}
-	if contentType == "" {
-		contentType = "application/octet-stream"
+		return 0, "", err
 	}
-	hash, err := sha256FileHex(localPath)
+	hash, sum, err := fileDigests(localPath)
 	if err != nil {
-		return "", err
+		return 0, "", err
 	}
 	// 使用上传服务已有的 uploads 桶，避免独立的 transcode 桶不存在导致 404
-	url := s.s3URL(inferBucketFromKey(objectKey), objectKey)
+	url := s.objectURL(objectKey)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, f)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return 0, "", fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("x-amz-content-sha256", hash)
 	req.Header.Set("Content-Length", fmt.Sprintf("%d", stat.Size()))
+	if s.verifyChecksum {
+		raw, _ := hex.DecodeString(sum)
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(raw))
+	}
 	req.ContentLength = stat.Size()
 	s.signS3(req, hash)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("put object: %w", err)
+		return 0, "", classifyErr(fmt.Errorf("put object: %w", err))
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("put object failed: status=%d, body=%s", resp.StatusCode, string(b))
+		if s.verifyChecksum && strings.Contains(string(b), "BadDigest") {
+			return 0, "", fmt.Errorf("%w key=%s: %s", errChecksumMismatch, objectKey, statusErr("put object", resp.StatusCode, string(b)))
+		}
+		return 0, "", statusErr("put object", resp.StatusCode, string(b))
+	}
+	// 单段 PUT 的 ETag 即内容 MD5，先比对响应，HEAD 再确认落盘的对象
+	if etag := strings.Trim(resp.Header.Get("ETag"), `"`); s.verifyChecksum && isMD5ETag(etag) && !strings.EqualFold(etag, sum) {
+		return 0, "", fmt.Errorf("%w key=%s want_md5=%s got_etag=%s", errChecksumMismatch, objectKey, sum, etag)
+	}
+	return stat.Size(), sum, nil
+}
+
+// copyObject 服务端复制（x-amz-copy-source），内容类型随源对象复制；storageClass 非空时设置目标存储类别
+func (s *RustFSStorage) copyObject(ctx context.Context, srcKey, dstKey, storageClass string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(dstKey), nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	srcBucket, srcObject := locateObject(srcKey)
+	req.Header.Set("x-amz-copy-source", "/"+srcBucket+"/"+utils.EscapeObjectKey(srcObject))
+	if storageClass != "" {
+		req.Header.Set("x-amz-storage-class", storageClass)
+	}
+	req.Header.Set("x-amz-content-sha256", emptyPayloadHash)
+	s.signS3(req, emptyPayloadHash)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return classifyErr(fmt.Errorf("copy object: %w", err))
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return statusErr("copy object", resp.StatusCode, string(b))
+	}
+	// S3 的复制可能返回 200 但响应体是 <Error>，视为服务端故障
+	if strings.Contains(string(b), "<Error>") {
+		return statusErr("copy object", http.StatusInternalServerError, string(b))
+	}
+	return nil
+}
+
+// CopyObject 服务端复制，用于产物分层存储
+func (s *RustFSStorage) CopyObject(ctx context.Context, srcKey, dstKey, storageClass string) error {
+	return s.copyObject(ctx, srcKey, dstKey, storageClass)
+}
+
+// RemoveObject 删除对象，404 视为成功
+func (s *RustFSStorage) RemoveObject(ctx context.Context, objectKey string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(objectKey), nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("x-amz-content-sha256", emptyPayloadHash)
+	s.signS3(req, emptyPayloadHash)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return classifyErr(fmt.Errorf("delete object: %w", err))
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 300 || resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return statusErr("delete object", resp.StatusCode, "")
+}
+
+// removeStaged 删除临时键，失败只记录（残留由生命周期规则清理）
+func (s *RustFSStorage) removeStaged(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := s.RemoveObject(ctx, key)
+	if err == nil {
+		return
 	}
-	return objectKey, nil
+	metrics.Add("storage_staged_cleanup_failed_total", 1)
+	logger.Warnf("RustFS staged object cleanup failed key=%s error=%v", key, err)
+}
+
+// stagingKey 临时键：<首级前缀>/.staging/<随机串>/<其余路径>，与目标键同桶且不会被按目标键读取到；
+// 指定了目标桶的 key 在桶内的部分上生成临时键
+func stagingKey(objectKey string) string {
+	if bucket, inner, ok := vo.SplitBucketKey(objectKey); ok {
+		return vo.BucketKeyPrefix + bucket + "/" + stagingKey(inner)
+	}
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	nonce := hex.EncodeToString(b[:])
+	k := strings.TrimLeft(objectKey, "/")
+	root, rest, ok := strings.Cut(k, "/")
+	if !ok {
+		return stagingDir + "/" + nonce + "/" + k
+	}
+	return root + "/" + stagingDir + "/" + nonce + "/" + rest
 }
 
 func (s *RustFSStorage) UploadObjects(ctx context.Context, objects []gateway.UploadObject) error {
@@ -93,49 +319,114 @@ func (s *RustFSStorage) UploadObjects(ctx context.Context, objects []gateway.Upl
 }
 
 func (s *RustFSStorage) DownloadFile(ctx context.Context, objectKey, localPath string) error {
-	bucket := inferBucketFromKey(objectKey)
-	url := s.s3URL(bucket, objectKey)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	body, err := s.OpenObject(ctx, objectKey)
 	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+		return err
+	}
+	defer body.Close()
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	out, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("create local: %w", err)
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, body); err != nil {
+		return classifyErr(fmt.Errorf("copy: %w", err))
+	}
+	logger.Info("RustFS downloaded file", map[string]interface{}{"object_key": objectKey, "local_path": localPath})
+	return nil
+}
+
+// OpenObject 返回 GET 响应体，非 2xx 时读出错误信息后关闭
+func (s *RustFSStorage) OpenObject(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(objectKey), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("x-amz-content-sha256", "UNSIGNED-PAYLOAD")
 	s.signS3(req, "UNSIGNED-PAYLOAD")
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("get object: %w", err)
+		return nil, classifyErr(fmt.Errorf("get object: %w", err))
 	}
-	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("get object failed: status=%d, body=%s", resp.StatusCode, string(b))
+		resp.Body.Close()
+		return nil, statusErr("get object", resp.StatusCode, string(b))
 	}
-	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
-		return fmt.Errorf("mkdir: %w", err)
+	return resp.Body, nil
+}
+
+func (s *RustFSStorage) StatObject(ctx context.Context, objectKey string) (gateway.ObjectInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.objectURL(objectKey), nil)
+	if err != nil {
+		return gateway.ObjectInfo{}, fmt.Errorf("create request: %w", err)
 	}
-	out, err := os.Create(localPath)
+	req.Header.Set("x-amz-content-sha256", "UNSIGNED-PAYLOAD")
+	s.signS3(req, "UNSIGNED-PAYLOAD")
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("create local: %w", err)
+		return gateway.ObjectInfo{}, classifyErr(fmt.Errorf("head object: %w", err))
 	}
-	defer out.Close()
-	if _, err := io.Copy(out, resp.Body); err != nil {
-		return fmt.Errorf("copy: %w", err)
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return gateway.ObjectInfo{}, statusErr("head object", resp.StatusCode, "")
+	}
+	return gateway.ObjectInfo{Size: resp.ContentLength, ETag: strings.Trim(resp.Header.Get("ETag"), `"`)}, nil
+}
+
+// Ping 以 HEAD 请求探测端点，能收到非 5xx 响应即视为可达
+func (s *RustFSStorage) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.endpoint+"/", nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return classifyErr(fmt.Errorf("ping storage: %w", err))
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return statusErr("ping storage", resp.StatusCode, "")
 	}
-	logger.Info("RustFS downloaded file", map[string]interface{}{"object_key": objectKey, "local_path": localPath})
 	return nil
 }
 
+// objectURL 按 key 定位桶与桶内对象后拼接请求地址
+func (s *RustFSStorage) objectURL(key string) string {
+	return s.s3URL(locateObject(key))
+}
+
 func (s *RustFSStorage) s3URL(bucket, key string) string {
-	k := strings.TrimLeft(key, "/")
+	// key 可能含空格、CJK、emoji 或非 UTF-8 字节，必须编码后再拼接，否则签名与实际请求路径不一致
+	k := utils.EscapeObjectKey(strings.TrimLeft(key, "/"))
 	return fmt.Sprintf("%s/%s/%s", s.endpoint, bucket, k)
 }
 
 func (s *RustFSStorage) signS3(req *http.Request, payloadHash string) {
-	t := time.Now().UTC()
+	t := clock.Now().UTC()
 	amzDate := t.Format("20060102T150405Z")
 	date := t.Format("20060102")
 	req.Header.Set("x-amz-date", amzDate)
 
+	cr, signedHeaders := canonicalRequest(req, payloadHash)
+	crHash := sha256Hex([]byte(cr))
+
+	scope := strings.Join([]string{date, s.region, "s3", "aws4_request"}, "/")
+	sts := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, crHash}, "\n")
+	kDate := hmacSHA256([]byte("AWS4"+s.secret), date)
+	kRegion := hmacSHA256(kDate, s.region)
+	kService := hmacSHA256(kRegion, "s3")
+	kSigning := hmacSHA256(kService, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(kSigning, sts))
+	auth := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.access, scope, signedHeaders, sig)
+	req.Header.Set("Authorization", auth)
+}
+
+// canonicalRequest SigV4 规范请求；host、x-amz-content-sha256、x-amz-date、content-type 与其余 x-amz-* 头参与签名
+func canonicalRequest(req *http.Request, payloadHash string) (string, string) {
 	u, _ := neturl.Parse(req.URL.String())
 	host := u.Host
 	req.Header.Set("host", host)
@@ -144,6 +435,13 @@ func (s *RustFSStorage) signS3(req *http.Request, payloadHash string) {
 	if req.Header.Get("content-type") != "" {
 		signed = append(signed, "content-type")
 	}
+	// 其余 x-amz-* 头（如 x-amz-copy-source）必须参与签名
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") && lower != "x-amz-content-sha256" && lower != "x-amz-date" {
+			signed = append(signed, lower)
+		}
+	}
 	sort.Strings(signed)
 
 	var canonicalHeaders strings.Builder
@@ -157,21 +455,11 @@ func (s *RustFSStorage) signS3(req *http.Request, payloadHash string) {
 		}
 		canonicalHeaders.WriteString("\n")
 	}
-	canonicalURI := u.Path
+	// canonical URI 使用已编码路径，与 s3URL 的编码一致
+	canonicalURI := u.EscapedPath()
 	canonicalQuery := u.RawQuery
 	signedHeaders := strings.Join(signed, ";")
-	cr := strings.Join([]string{req.Method, canonicalURI, canonicalQuery, canonicalHeaders.String(), signedHeaders, payloadHash}, "\n")
-	crHash := sha256Hex([]byte(cr))
-
-	scope := strings.Join([]string{date, s.region, "s3", "aws4_request"}, "/")
-	sts := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, crHash}, "\n")
-	kDate := hmacSHA256([]byte("AWS4"+s.secret), date)
-	kRegion := hmacSHA256(kDate, s.region)
-	kService := hmacSHA256(kRegion, "s3")
-	kSigning := hmacSHA256(kService, "aws4_request")
-	sig := hex.EncodeToString(hmacSHA256(kSigning, sts))
-	auth := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.access, scope, signedHeaders, sig)
-	req.Header.Set("Authorization", auth)
+	return strings.Join([]string{req.Method, canonicalURI, canonicalQuery, canonicalHeaders.String(), signedHeaders, payloadHash}, "\n"), signedHeaders
 }
 
 func normalizeEndpoint(e string) string {
@@ -190,17 +478,27 @@ func sha256Hex(b []byte) string {
 	return hex.EncodeToString(h[:])
 }
 
-func sha256FileHex(path string) (string, error) {
+// fileDigests 一次读取同时计算 SHA256（请求签名）与 MD5（上传校验）
+func fileDigests(path string) (string, string, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	defer f.Close()
-	d := sha256.New()
-	if _, err := io.Copy(d, f); err != nil {
-		return "", err
+	sh, m := sha256.New(), md5.New()
+	if _, err := io.Copy(io.MultiWriter(sh, m), f); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(sh.Sum(nil)), hex.EncodeToString(m.Sum(nil)), nil
+}
+
+// isMD5ETag 单段上传且未使用 SSE-KMS 时 ETag 为 32 位十六进制 MD5
+func isMD5ETag(etag string) bool {
+	if len(etag) != 32 {
+		return false
 	}
-	return hex.EncodeToString(d.Sum(nil)), nil
+	_, err := hex.DecodeString(etag)
+	return err == nil
 }
 
 func hmacSHA256(key []byte, data string) []byte {
@@ -209,6 +507,14 @@ func hmacSHA256(key []byte, data string) []byte {
 	return h.Sum(nil)
 }
 
+// locateObject 返回 key 所在的桶与桶内对象 key：buckets/<bucket>/<key> 为请求指定的目标桶，其余按前缀推断
+func locateObject(key string) (string, string) {
+	if bucket, objectKey, ok := vo.SplitBucketKey(key); ok {
+		return bucket, objectKey
+	}
+	return inferBucketFromKey(key), strings.TrimLeft(key, "/")
+}
+
 func inferBucketFromKey(key string) string {
 	k := strings.TrimLeft(key, "/")
 	if strings.HasPrefix(k, "uploads/") || strings.HasPrefix(k, "chunks/") {
@@ -217,5 +523,9 @@ func inferBucketFromKey(key string) string {
 	if strings.HasPrefix(k, "transcoded/") || strings.HasPrefix(k, "hls/") {
 		return "transcode"
 	}
+	if strings.HasPrefix(k, "private/") {
+		// 私有产物单独存放，桶不开放匿名读，只经签名网关访问
+		return "transcode-private"
+	}
 	return "uploads"
 }
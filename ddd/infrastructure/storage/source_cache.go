@@ -0,0 +1,375 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+	"transcode-service/pkg/utils"
+)
+
+const sourceCacheTmpSuffix = ".tmp"
+
+var (
+	sourceCacheOnce    sync.Once
+	defaultSourceCache *SourceCache
+)
+
+// DefaultSourceCache 按配置创建源文件缓存，未启用时返回 nil
+func DefaultSourceCache() *SourceCache {
+	sourceCacheOnce.Do(func() {
+		cfg := config.GetGlobalConfig()
+		if cfg == nil || !cfg.Transcode.SourceCache.Enabled {
+			return
+		}
+		sc := cfg.Transcode.SourceCache
+		cache, err := NewSourceCache(sc.Dir, sc.MaxBytes, sc.MaxObjectBytes)
+		if err != nil {
+			logger.Warnf("source cache disabled dir=%s error=%v", sc.Dir, err)
+			return
+		}
+		defaultSourceCache = cache
+	})
+	return defaultSourceCache
+}
+
+// SourceCacheStats 缓存占用与命中统计
+type SourceCacheStats struct {
+	Dir      string `json:"dir"`
+	Entries  int    `json:"entries"`
+	Bytes    int64  `json:"bytes"`
+	MaxBytes int64  `json:"max_bytes"`
+	Hits     int64  `json:"hits"`
+	Misses   int64  `json:"misses"`
+	Bypassed int64  `json:"bypassed"`
+}
+
+// SourceCache 工作节点上已下载源文件的 LRU 磁盘缓存。
+// 键为对象 key + ETag，源文件被替换后 ETag 变化自然失效；文件以硬链接交给工作目录，跨文件系统时回退为复制
+type SourceCache struct {
+	dir            string
+	maxBytes       int64
+	maxObjectBytes int64
+
+	mu       sync.Mutex
+	lru      *list.List // 队首为最近使用
+	entries  map[string]*list.Element
+	size     int64
+	inflight map[string]chan struct{}
+	hits     int64
+	misses   int64
+	bypassed int64
+}
+
+type sourceCacheEntry struct {
+	name string
+	size int64
+}
+
+// NewSourceCache 创建缓存并加载目录中已有文件（按修改时间恢复 LRU 顺序）
+func NewSourceCache(dir string, maxBytes, maxObjectBytes int64) (*SourceCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if maxObjectBytes <= 0 || maxObjectBytes > maxBytes {
+		maxObjectBytes = maxBytes
+	}
+	c := &SourceCache{
+		dir:            dir,
+		maxBytes:       maxBytes,
+		maxObjectBytes: maxObjectBytes,
+		lru:            list.New(),
+		entries:        make(map[string]*list.Element),
+		inflight:       make(map[string]chan struct{}),
+	}
+	c.load()
+	return c, nil
+}
+
+func (c *SourceCache) load() {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	type file struct {
+		name  string
+		size  int64
+		mtime time.Time
+	}
+	files := make([]file, 0, len(dirEntries))
+	for _, de := range dirEntries {
+		if de.IsDir() {
+			continue
+		}
+		path := filepath.Join(c.dir, de.Name())
+		if strings.HasSuffix(de.Name(), sourceCacheTmpSuffix) {
+			// 上次进程中断遗留的半成品
+			_ = os.Remove(path)
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{name: de.Name(), size: info.Size(), mtime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].mtime.After(files[j].mtime) })
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, f := range files {
+		c.entries[f.name] = c.lru.PushBack(&sourceCacheEntry{name: f.name, size: f.size})
+		c.size += f.size
+	}
+	c.evictLocked()
+	logger.Infof("source cache loaded dir=%s entries=%d bytes=%d", c.dir, len(c.entries), c.size)
+}
+
+// errCacheBypass 对象不适合缓存或缓存文件不可用，调用方应直接读存储
+var errCacheBypass = errors.New("source cache bypassed")
+
+// Fetch 把源对象放到 localPath：命中时直接链接缓存文件，未命中时下载进缓存再链接。
+// 缓存出错时回退为直接下载，不影响任务
+func (c *SourceCache) Fetch(ctx context.Context, storage gateway.StorageGateway, objectKey, localPath string) error {
+	err := c.acquire(ctx, storage, objectKey, func(name string) error { return c.materialize(name, localPath) })
+	if errors.Is(err, errCacheBypass) {
+		return storage.DownloadFile(ctx, objectKey, localPath)
+	}
+	return err
+}
+
+// Open 与 Fetch 相同的命中/填充逻辑，返回缓存文件的只读句柄；句柄打开后条目被淘汰也能读完
+func (c *SourceCache) Open(ctx context.Context, storage gateway.StorageGateway, objectKey string) (io.ReadCloser, error) {
+	var f *os.File
+	err := c.acquire(ctx, storage, objectKey, func(name string) (err error) {
+		f, err = os.Open(filepath.Join(c.dir, name))
+		return err
+	})
+	if errors.Is(err, errCacheBypass) {
+		return storage.OpenObject(ctx, objectKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// acquire 确保对象在缓存中后以 use 取用缓存文件；不适合缓存或取用失败时返回 errCacheBypass
+func (c *SourceCache) acquire(ctx context.Context, storage gateway.StorageGateway, objectKey string, use func(name string) error) error {
+	info, err := storage.StatObject(ctx, objectKey)
+	if err != nil || info.ETag == "" || info.Size > c.maxObjectBytes {
+		c.record(&c.bypassed, "source_cache_bypass_total")
+		return errCacheBypass
+	}
+	name := cacheEntryName(objectKey, info.ETag)
+	for {
+		c.mu.Lock()
+		if el, ok := c.entries[name]; ok {
+			c.lru.MoveToFront(el)
+			c.mu.Unlock()
+			if err := use(name); err == nil {
+				c.record(&c.hits, "source_cache_hits_total")
+				logger.Infof("source cache hit object_key=%s etag=%s", objectKey, info.ETag)
+				return nil
+			}
+			// 缓存文件在链接前被淘汰，按未命中处理
+			c.remove(name)
+			continue
+		}
+		if ch, ok := c.inflight[name]; ok {
+			c.mu.Unlock()
+			select {
+			case <-ch:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		ch := make(chan struct{})
+		c.inflight[name] = ch
+		c.mu.Unlock()
+
+		c.record(&c.misses, "source_cache_misses_total")
+		err := c.fill(ctx, storage, objectKey, name)
+		c.mu.Lock()
+		delete(c.inflight, name)
+		close(ch)
+		c.mu.Unlock()
+		if err != nil {
+			return err
+		}
+		if err := use(name); err != nil {
+			logger.Warnf("source cache link failed, downloading directly object_key=%s error=%v", objectKey, err)
+			return errCacheBypass
+		}
+		return nil
+	}
+}
+
+// fill 下载到临时文件后原子改名，并清掉同一对象的旧版本
+func (c *SourceCache) fill(ctx context.Context, storage gateway.StorageGateway, objectKey, name string) error {
+	tmp := filepath.Join(c.dir, fmt.Sprintf("%s.%d%s", name, time.Now().UnixNano(), sourceCacheTmpSuffix))
+	if err := storage.DownloadFile(ctx, objectKey, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	st, err := os.Stat(tmp)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(c.dir, name)); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	c.Invalidate(objectKey, name)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[name] = c.lru.PushFront(&sourceCacheEntry{name: name, size: st.Size()})
+	c.size += st.Size()
+	c.evictLocked()
+	return nil
+}
+
+// Invalidate 删除对象的全部缓存版本（源文件替换时调用），keep 指定需保留的条目名；返回删除数量
+func (c *SourceCache) Invalidate(objectKey string, keep ...string) int {
+	prefix := objectKeyHash(objectKey) + "-"
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for name, el := range c.entries {
+		if !strings.HasPrefix(name, prefix) || contains(keep, name) {
+			continue
+		}
+		c.removeLocked(name, el)
+		removed++
+	}
+	if removed > 0 {
+		logger.Infof("source cache invalidated object_key=%s removed=%d", objectKey, removed)
+	}
+	return removed
+}
+
+// Stats 返回缓存统计
+func (c *SourceCache) Stats() SourceCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return SourceCacheStats{Dir: c.dir, Entries: len(c.entries), Bytes: c.size, MaxBytes: c.maxBytes, Hits: c.hits, Misses: c.misses, Bypassed: c.bypassed}
+}
+
+func (c *SourceCache) materialize(name, localPath string) error {
+	src := filepath.Join(c.dir, name)
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return err
+	}
+	_ = os.Remove(localPath)
+	if err := os.Link(src, localPath); err == nil {
+		return nil
+	}
+	return copyFile(src, localPath)
+}
+
+func (c *SourceCache) remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[name]; ok {
+		c.removeLocked(name, el)
+	}
+}
+
+// evictLocked 淘汰最久未使用的条目直到低于容量；已链接到工作目录的文件不受影响
+func (c *SourceCache) evictLocked() {
+	for c.size > c.maxBytes && c.lru.Len() > 0 {
+		el := c.lru.Back()
+		c.removeLocked(el.Value.(*sourceCacheEntry).name, el)
+		metrics.Add("source_cache_evictions_total", 1)
+	}
+	metrics.Set("source_cache_bytes", c.size)
+	metrics.Set("source_cache_entries", int64(len(c.entries)))
+}
+
+func (c *SourceCache) removeLocked(name string, el *list.Element) {
+	c.size -= el.Value.(*sourceCacheEntry).size
+	c.lru.Remove(el)
+	delete(c.entries, name)
+	_ = os.Remove(filepath.Join(c.dir, name))
+}
+
+func (c *SourceCache) record(counter *int64, metric string) {
+	c.mu.Lock()
+	*counter++
+	c.mu.Unlock()
+	metrics.Add(metric, 1)
+}
+
+// cacheEntryName 对象 key 哈希作前缀，便于按对象失效；保留扩展名便于 ffprobe 识别
+func cacheEntryName(objectKey, etag string) string {
+	sum := sha256.Sum256([]byte(etag))
+	return objectKeyHash(objectKey) + "-" + hex.EncodeToString(sum[:8]) + filepath.Ext(utils.LocalFileName(objectKey))
+}
+
+func objectKeyHash(objectKey string) string {
+	sum := sha256.Sum256([]byte(objectKey))
+	return hex.EncodeToString(sum[:16])
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceCachingGateway 下载走源文件缓存，其余操作透传
+type sourceCachingGateway struct {
+	gateway.StorageGateway
+	cache *SourceCache
+}
+
+// NewSourceCachingGateway 为源文件下载加一层磁盘缓存；cache 为 nil 时原样返回
+func NewSourceCachingGateway(inner gateway.StorageGateway, cache *SourceCache) gateway.StorageGateway {
+	if cache == nil || inner == nil {
+		return inner
+	}
+	return &sourceCachingGateway{StorageGateway: inner, cache: cache}
+}
+
+func (g *sourceCachingGateway) DownloadFile(ctx context.Context, objectKey, localPath string) error {
+	return g.cache.Fetch(ctx, g.StorageGateway, objectKey, localPath)
+}
+
+func (g *sourceCachingGateway) OpenObject(ctx context.Context, objectKey string) (io.ReadCloser, error) {
+	return g.cache.Open(ctx, g.StorageGateway, objectKey)
+}
@@ -0,0 +1,145 @@
+package throughput
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/manager"
+	"transcode-service/pkg/metrics"
+)
+
+// flushTimeout 单次写库超时
+const flushTimeout = 10 * time.Second
+
+// active 下载/上传/编码路径向其累加计数；未启用或已停止时为 nil
+var active atomic.Pointer[Recorder]
+
+// AddInputBytes 源文件实际下载的字节数（源缓存命中不经过存储网关，不计入）
+func AddInputBytes(n int64) {
+	metrics.Add("throughput_input_bytes_total", n)
+	record(vo.DailyThroughput{InputBytes: n})
+}
+
+// AddOutputBytes 产物上传的字节数
+func AddOutputBytes(n int64) {
+	metrics.Add("throughput_output_bytes_total", n)
+	record(vo.DailyThroughput{OutputBytes: n})
+}
+
+// AddEncode ffmpeg 编码耗时，并发作业各自累加
+func AddEncode(d time.Duration) {
+	metrics.Add("throughput_encode_ms_total", d.Milliseconds())
+	record(vo.DailyThroughput{Encode: d})
+}
+
+func record(d vo.DailyThroughput) {
+	if r := active.Load(); r != nil && !d.IsZero() {
+		r.add(clock.Now(), d)
+	}
+}
+
+// Recorder 在内存中按 UTC 日期累加处理量，周期性把增量写入 throughput_daily；
+// 写库失败的增量保留到下一轮，停止时写出最后一批
+type Recorder struct {
+	interval time.Duration
+	repo     repo.ThroughputRepository
+	mu       sync.Mutex
+	pending  map[string]vo.DailyThroughput
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewRecorder 未开启时返回 nil
+func NewRecorder(cfg config.ThroughputConfig, repo repo.ThroughputRepository) *Recorder {
+	if !cfg.Enabled || repo == nil {
+		return nil
+	}
+	return &Recorder{interval: cfg.FlushInterval, repo: repo, pending: map[string]vo.DailyThroughput{}}
+}
+
+func (r *Recorder) Name() string { return "throughputRecorder" }
+
+// ShutdownPhase worker 排空后再写出最后一批计数
+func (r *Recorder) ShutdownPhase() manager.Phase { return manager.PhaseFlush }
+
+func (r *Recorder) Start(ctx context.Context) error {
+	ctx, r.cancel = context.WithCancel(ctx)
+	active.Store(r)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.flush(ctx)
+			}
+		}
+	}()
+	logger.Infof("throughput recorder started flush_interval=%s", r.interval)
+	return nil
+}
+
+func (r *Recorder) Stop() error {
+	active.CompareAndSwap(r, nil)
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	r.flush(ctx)
+	return nil
+}
+
+func (r *Recorder) add(now time.Time, d vo.DailyThroughput) {
+	date := vo.ThroughputDate(now)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur := r.pending[date]
+	cur.Date = date
+	cur.Add(d)
+	r.pending[date] = cur
+}
+
+// flush 逐日写入；失败的日期放回待写，已写入的日期不会重复累加
+func (r *Recorder) flush(ctx context.Context) {
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = map[string]vo.DailyThroughput{}
+	r.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+	dates := make([]string, 0, len(batch))
+	for date := range batch {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	for _, date := range dates {
+		wctx, cancel := context.WithTimeout(ctx, flushTimeout)
+		err := r.repo.AddDailyThroughput(wctx, batch[date])
+		cancel()
+		if err != nil {
+			metrics.Add("throughput_flush_failures_total", 1)
+			logger.Warnf("flush daily throughput failed date=%s error=%v", date, err)
+			r.mu.Lock()
+			cur := r.pending[date]
+			cur.Date = date
+			cur.Add(batch[date])
+			r.pending[date] = cur
+			r.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/manager"
+	"transcode-service/pkg/metrics"
+)
+
+const (
+	assignmentWriteTimeout = 5 * time.Second
+	assignmentPruneEvery   = time.Hour
+)
+
+// activeRecorder runJob 结束后向其提交执行记录；未启用或已停止时为 nil
+var activeRecorder atomic.Pointer[assignmentRecorder]
+
+// assignmentRecorder 异步写入作业分配历史，数据库变慢时只丢弃记录，不阻塞作业完成
+type assignmentRecorder struct {
+	workerID string
+	cfg      config.AssignmentsConfig
+	repo     repo.TaskAssignmentRepository
+	records  chan *vo.TaskAssignment
+	mu       sync.RWMutex
+	closed   bool
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func newAssignmentRecorder(workerID string, cfg config.AssignmentsConfig, repo repo.TaskAssignmentRepository) *assignmentRecorder {
+	return &assignmentRecorder{
+		workerID: workerID,
+		cfg:      cfg,
+		repo:     repo,
+		records:  make(chan *vo.TaskAssignment, cfg.BufferSize),
+	}
+}
+
+func (r *assignmentRecorder) Name() string { return "assignmentRecorder" }
+
+// ShutdownPhase worker 排空后再写出最后一批执行记录
+func (r *assignmentRecorder) ShutdownPhase() manager.Phase { return manager.PhaseFlush }
+
+func (r *assignmentRecorder) Start(ctx context.Context) error {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.wg.Add(2)
+	go r.writeLoop()
+	go r.pruneLoop(ctx)
+	activeRecorder.Store(r)
+	logger.Infof("assignment recorder started worker_id=%s retention=%s", r.workerID, r.cfg.Retention)
+	return nil
+}
+
+// Stop 停止接收新记录并写完缓冲中的记录
+func (r *assignmentRecorder) Stop() error {
+	activeRecorder.CompareAndSwap(r, nil)
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.records)
+	}
+	r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	return nil
+}
+
+// record 缓冲满或已停止时丢弃记录
+func (r *assignmentRecorder) record(a *vo.TaskAssignment) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	a.WorkerID = r.workerID
+	select {
+	case r.records <- a:
+	default:
+		metrics.Add("task_assignments_dropped_total", 1)
+	}
+}
+
+func (r *assignmentRecorder) writeLoop() {
+	defer r.wg.Done()
+	for a := range r.records {
+		ctx, cancel := context.WithTimeout(context.Background(), assignmentWriteTimeout)
+		err := r.repo.SaveTaskAssignment(ctx, a)
+		cancel()
+		if err != nil {
+			metrics.Add("task_assignments_write_failures_total", 1)
+			logger.Warnf("save task assignment failed task_uuid=%s job_type=%s error=%v", a.TaskUUID, a.JobType, err)
+			continue
+		}
+		metrics.Add("task_assignments_recorded_total", 1)
+	}
+}
+
+// pruneLoop 每小时删除超过保留时长的记录；多实例同时执行时删除是幂等的
+func (r *assignmentRecorder) pruneLoop(ctx context.Context) {
+	defer r.wg.Done()
+	ticker := time.NewTicker(assignmentPruneEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := r.repo.PruneTaskAssignments(ctx, clock.Now().Add(-r.cfg.Retention))
+			if err != nil {
+				logger.Warnf("prune task assignments failed error=%v", err)
+				continue
+			}
+			if n > 0 {
+				logger.Infof("pruned task assignments count=%d", n)
+			}
+		}
+	}
+}
+
+// recordAssignment 由 runJob 在作业执行结束后调用
+func recordAssignment(job *Job, startedAt time.Time, wait time.Duration, weight, capacity int, ctxErr, err error) {
+	r := activeRecorder.Load()
+	if r == nil {
+		return
+	}
+	outcome := vo.AssignmentSucceeded
+	switch {
+	case ctxErr != nil:
+		outcome = vo.AssignmentCancelled
+	case err != nil:
+		outcome = vo.AssignmentFailed
+	}
+	attempt := job.Attempt
+	if attempt <= 0 {
+		attempt = 1
+	}
+	if weight <= 0 {
+		weight = 1
+	}
+	if capacity < weight {
+		capacity = weight
+	}
+	r.record(&vo.TaskAssignment{
+		JobType:      job.Type,
+		TaskUUID:     job.ID,
+		Attempt:      attempt,
+		StartedAt:    startedAt,
+		FinishedAt:   clock.Now(),
+		Outcome:      outcome,
+		SlotWeight:   weight,
+		SlotCapacity: capacity,
+		Wait:         wait,
+	})
+}
@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/service"
+	"transcode-service/ddd/infrastructure/budget"
+	"transcode-service/ddd/infrastructure/queue"
+	"transcode-service/ddd/infrastructure/storage"
+)
+
+// transcodeJobHandler 内置 MP4 转码作业，排队使用优先级任务队列
+type transcodeJobHandler struct {
+	svc  service.TranscodeService
+	repo repo.TranscodeJobRepository
+}
+
+func newTranscodeJob(task *entity.TranscodeTaskEntity) *Job {
+	return &Job{Type: budget.JobTranscode, ID: task.TaskUUID(), VideoUUID: task.VideoUUID(), Resolutions: []string{task.GetParams().Resolution}, Attempt: task.RetryCount() + 1, Payload: task}
+}
+
+func (h *transcodeJobHandler) Type() string { return budget.JobTranscode }
+
+// Decode 负载为 {"task_uuid": "..."}，任务须已落库
+func (h *transcodeJobHandler) Decode(ctx context.Context, raw []byte) (*Job, error) {
+	var req struct {
+		TaskUUID string `json:"task_uuid"`
+	}
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, err
+	}
+	if req.TaskUUID == "" {
+		return nil, fmt.Errorf("task_uuid is required")
+	}
+	task, err := h.repo.GetTranscodeJob(ctx, req.TaskUUID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, fmt.Errorf("task %s not found", req.TaskUUID)
+	}
+	return newTranscodeJob(task), nil
+}
+
+func (h *transcodeJobHandler) Execute(ctx context.Context, job *Job) error {
+	task, ok := job.Payload.(*entity.TranscodeTaskEntity)
+	if !ok {
+		return fmt.Errorf("unexpected transcode job payload %T", job.Payload)
+	}
+	err := h.svc.ExecuteTranscode(ctx, task, func(err error) {
+		if job.Finish != nil {
+			job.Finish(err)
+		}
+	})
+	storage.DefaultHealthGate().Observe(err)
+	return err
+}
+
+// Report 状态持久化与上报 upload-service 已在 ExecuteTranscode 内完成
+func (h *transcodeJobHandler) Report(ctx context.Context, job *Job, err error) {}
+
+func enqueueTranscodeJob(q queue.TaskQueue) func(ctx context.Context, job *Job) error {
+	return func(ctx context.Context, job *Job) error {
+		task, ok := job.Payload.(*entity.TranscodeTaskEntity)
+		if !ok {
+			return fmt.Errorf("unexpected transcode job payload %T", job.Payload)
+		}
+		return q.Enqueue(ctx, task)
+	}
+}
+
+// hlsJobHandler 内置 HLS 切片作业，排队与认领复用 HLS worker
+type hlsJobHandler struct {
+	w *hlsWorkerImpl
+}
+
+func newHLSJob(job *entity.HLSJobEntity) *Job {
+	return &Job{Type: budget.JobHLS, ID: job.JobUUID(), VideoUUID: job.VideoUUID(), Resolutions: hlsResolutions(job), Payload: job}
+}
+
+func (h *hlsJobHandler) Type() string { return budget.JobHLS }
+
+// Decode 负载为 {"job_uuid": "..."}，作业须已落库
+func (h *hlsJobHandler) Decode(ctx context.Context, raw []byte) (*Job, error) {
+	var req struct {
+		JobUUID string `json:"job_uuid"`
+	}
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, err
+	}
+	if req.JobUUID == "" {
+		return nil, fmt.Errorf("job_uuid is required")
+	}
+	job, err := h.w.hlsRepo.GetHLSJob(ctx, req.JobUUID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("hls job %s not found", req.JobUUID)
+	}
+	return newHLSJob(job), nil
+}
+
+// Execute 认领成功后执行；失败已在 processJob 内落库并上报
+func (h *hlsJobHandler) Execute(ctx context.Context, job *Job) error {
+	hlsJob, ok := job.Payload.(*entity.HLSJobEntity)
+	if !ok {
+		return fmt.Errorf("unexpected hls job payload %T", job.Payload)
+	}
+	h.w.claimAndProcess(ctx, hlsJob)
+	return nil
+}
+
+func (h *hlsJobHandler) Report(ctx context.Context, job *Job, err error) {}
+
+func (h *hlsJobHandler) enqueue(ctx context.Context, job *Job) error {
+	hlsJob, ok := job.Payload.(*entity.HLSJobEntity)
+	if !ok {
+		return fmt.Errorf("unexpected hls job payload %T", job.Payload)
+	}
+	if _, queued := h.w.queued.LoadOrStore(hlsJob.JobUUID(), struct{}{}); queued {
+		return nil
+	}
+	if err := queue.DefaultHLSJobQueue().Enqueue(ctx, hlsJob); err != nil {
+		h.w.queued.Delete(hlsJob.JobUUID())
+		return err
+	}
+	return nil
+}
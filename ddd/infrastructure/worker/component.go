@@ -3,19 +3,27 @@ package worker
 import (
 	"context"
 	"fmt"
+	"runtime"
+	"time"
 
-	"transcode-service/ddd/domain/gateway"
 	"transcode-service/ddd/domain/service"
+	"transcode-service/ddd/infrastructure/analytics"
+	"transcode-service/ddd/infrastructure/benchmark"
 	"transcode-service/ddd/infrastructure/database/persistence"
 	"transcode-service/ddd/infrastructure/executor"
 	grpcClient "transcode-service/ddd/infrastructure/grpc"
+	"transcode-service/ddd/infrastructure/ladder"
+	"transcode-service/ddd/infrastructure/notify"
+	"transcode-service/ddd/infrastructure/presence"
 	"transcode-service/ddd/infrastructure/progress"
 	"transcode-service/ddd/infrastructure/queue"
 	"transcode-service/ddd/infrastructure/storage"
-	"transcode-service/internal/resource"
+	"transcode-service/ddd/infrastructure/throughput"
+	"transcode-service/ddd/infrastructure/workspace"
 	"transcode-service/pkg/config"
 	"transcode-service/pkg/logger"
 	"transcode-service/pkg/manager"
+	"transcode-service/pkg/observability"
 	"transcode-service/pkg/task"
 )
 
@@ -34,20 +42,25 @@ func (p *TranscodeWorkerComponentPlugin) MustCreateComponent(deps *manager.Depen
 	if cfg == nil {
 		cfg = config.GetGlobalConfig()
 	}
-	var storageGateway gateway.StorageGateway
-	rustRes := resource.DefaultRustFSResource()
-	storageGateway = storage.NewRustFSStorage(
-		rustRes.GetEndpoint(),
-		rustRes.GetAccessKey(),
-		rustRes.GetSecretKey(),
-	)
+	rawStorage := storage.DefaultStorageGateway()
+	// 流水线的下载/上传计入每日处理量；分析导出与产物归档不计
+	storageGateway := storage.NewMeteredGateway(rawStorage)
 	// 上报转码结果给上传服务（用于更新视频状态/SSE）
-	resultReporter := grpcClient.DefaultUploadServiceReporter()
+	// 压测任务的结果不通知上游
+	resultReporter := benchmark.QuietReporter(grpcClient.DefaultUploadServiceReporter())
 
-	ffExecutor := executor.NewFFmpegExecutor(cfg, storageGateway)
+	// 源文件下载走磁盘缓存，同一视频的多个作业只下载一次；缓存保存密文，静态加密的源在下载到工作目录时解密
+	sourceStorage := storage.NewDecryptingGateway(storage.NewSourceCachingGateway(storageGateway, storage.DefaultSourceCache()), grpcClient.DefaultKeyProvider())
+	ffExecutor := executor.NewFFmpegExecutor(cfg, sourceStorage)
+	// 产物上传走独立上传池，编码槽位在 ffmpeg 结束后立即释放
+	uploadPool := executor.NewUploadPool(cfg, storageGateway)
+	if uploadPool != nil {
+		ffExecutor.SetUploadPool(uploadPool)
+	}
 	progressSink := progress.NewDBSink(repo)
-	transcodeSvc := service.NewTranscodeService(repo, hlsRepo, storageGateway, cfg, resultReporter, ffExecutor, progressSink)
+	transcodeSvc := service.NewTranscodeService(repo, hlsRepo, persistence.NewUserPreferenceRepository(), storageGateway, cfg, ladder.Default(), resultReporter, ffExecutor, progressSink)
 	hlsSvc := service.DefaultHLSService()
+	videoSvc := service.NewVideoProcessingService(repo, hlsRepo)
 
 	workerCount := 1
 	hlsWorkerCount := 1
@@ -66,22 +79,121 @@ func (p *TranscodeWorkerComponentPlugin) MustCreateComponent(deps *manager.Depen
 		}
 	}
 
+	var expiry *expiryTask
+	if cfg != nil && cfg.Worker.Expiry.Enabled {
+		expiry = newExpiryTask(service.NewTaskExpiryService(repo, resultReporter, cfg.Worker.Expiry), cfg.Worker.Expiry.CheckInterval)
+	}
+
+	var groups *taskGroupTask
+	if cfg != nil {
+		svc := service.NewTaskGroupService(persistence.NewTaskGroupRepository(), notify.NewGroupCallbackSender(cfg.TaskGroups), cfg.TaskGroups.CallbackMaxAttempts)
+		groups = newTaskGroupTask(svc, cfg.TaskGroups.CheckInterval)
+	}
+
+	var lifecycle *lifecycleTask
+	if cfg != nil && cfg.Lifecycle.Enabled {
+		if svc := service.NewOutputLifecycleService(repo, rawStorage, cfg.Lifecycle); svc != nil {
+			lifecycle = newLifecycleTask(svc, cfg.Lifecycle.Interval)
+		}
+	}
+
+	expressSlots := 0
+	if cfg != nil && cfg.Worker.ExpressLane.Enabled {
+		expressSlots = cfg.Worker.ExpressLane.ReservedSlots
+	}
+	transcodeWorker := NewTranscodeWorker(workerID, queueInstance, transcodeSvc, repo, workerCount, expressSlots)
+	// HLS Worker 在完成 HLS 后，通过 reporter 通知 upload-service（Published + HLS URL）
+	hlsWorker := NewHLSWorker(workerID+"-hls", hlsRepo, hlsSvc, sourceStorage, resultReporter, videoSvc, cfg, hlsWorkerCount)
+	registerBuiltinJobHandler(transcodeWorker.JobHandler(), enqueueTranscodeJob(queueInstance))
+	registerBuiltinJobHandler(hlsWorker.JobHandler(), hlsWorker.JobHandler().(*hlsJobHandler).enqueue)
+	// 其余作业类型由插件在 init 中注册，使用通用作业池执行
+	var pools []*jobPool
+	for _, h := range pluginJobHandlers() {
+		n := 1
+		if cfg != nil && cfg.Worker.JobPools[h.Type()] > 0 {
+			n = cfg.Worker.JobPools[h.Type()]
+		}
+		pools = append(pools, newJobPool(h, n))
+	}
+	var snapshot *snapshotTask
+	if cfg != nil && cfg.Worker.Snapshot.Enabled {
+		snapshot = newSnapshotTask(buildClaimID(workerID), cfg.Worker.Snapshot, persistence.NewPipelineSnapshotRepository(), repo, transcodeWorker, queueInstance)
+	}
+
+	var assignments *assignmentRecorder
+	if cfg != nil && cfg.Worker.Assignments.Enabled {
+		assignments = newAssignmentRecorder(buildClaimID(workerID), cfg.Worker.Assignments, persistence.NewTaskAssignmentRepository())
+	}
+
+	if cfg != nil {
+		configureVideoFence(cfg.Worker.VideoFence, buildClaimID(workerID))
+	}
+
+	capabilities := workerCapabilities(cfg, pools)
+	presencePublisher := presence.NewPublisher(cfg, workerID, buildClaimID(workerID), func() presence.Record {
+		claims := transcodeWorker.ActiveClaims()
+		active := make([]string, 0, len(claims))
+		for _, cl := range claims {
+			active = append(active, cl.TaskUUID)
+		}
+		return presence.Record{
+			Slots:        workerCount,
+			HLSSlots:     hlsWorkerCount,
+			Running:      len(claims),
+			QueueDepth:   queueInstance.Size(),
+			ActiveTasks:  active,
+			Capabilities: capabilities,
+			GPUs:         observability.LatestGPUStats(),
+		}
+	})
+
+	var stats *throughput.Recorder
+	if cfg != nil {
+		stats = throughput.NewRecorder(cfg.Worker.Throughput, persistence.NewThroughputRepository())
+	}
+
+	var redispatch *redispatchTask
+	if cfg != nil && cfg.Worker.Redispatch.Enabled {
+		redispatch = newRedispatchTask(cfg.Worker.Redispatch, repo, queueInstance)
+	}
+
 	return &transcodeWorkerComponent{
-		name:   "transcodeWorker",
-		queue:  queueInstance,
-		worker: NewTranscodeWorker(workerID, queueInstance, transcodeSvc, repo, workerCount),
-		// HLS Worker 在完成 HLS 后，通过 reporter 通知 upload-service（Published + HLS URL）
-		hlsWorker: NewHLSWorker(workerID+"-hls", hlsRepo, hlsSvc, storageGateway, resultReporter, cfg, hlsWorkerCount),
+		name:        "transcodeWorker",
+		expiry:      expiry,
+		groups:      groups,
+		lifecycle:   lifecycle,
+		snapshot:    snapshot,
+		redispatch:  redispatch,
+		queue:       queueInstance,
+		worker:      transcodeWorker,
+		hlsWorker:   hlsWorker,
+		pools:       pools,
+		exporter:    analytics.NewExporter(cfg, rawStorage),
+		uploads:     uploadPool,
+		assignments: assignments,
+		throughput:  stats,
+		presence:    presencePublisher,
 	}
 }
 
 type transcodeWorkerComponent struct {
-	name      string
-	queue     queue.TaskQueue
-	worker    TranscodeWorker
-	hlsWorker HLSWorker
-	ctx       context.Context
-	cancel    context.CancelFunc
+	name        string
+	queue       queue.TaskQueue
+	worker      TranscodeWorker
+	hlsWorker   HLSWorker
+	expiry      *expiryTask
+	groups      *taskGroupTask
+	lifecycle   *lifecycleTask
+	snapshot    *snapshotTask
+	redispatch  *redispatchTask
+	pools       []*jobPool
+	exporter    *analytics.Exporter
+	uploads     *executor.UploadPool
+	assignments *assignmentRecorder
+	throughput  *throughput.Recorder
+	presence    *presence.Publisher
+	ctx         context.Context
+	cancel      context.CancelFunc
 }
 
 func (c *transcodeWorkerComponent) Start() error {
@@ -89,15 +201,96 @@ func (c *transcodeWorkerComponent) Start() error {
 		return fmt.Errorf("transcode worker not initialized")
 	}
 
+	// 清理上次进程崩溃遗留的工作目录
+	var extraRoots []string
+	minAge := 6 * time.Hour
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		if cfg.Transcode.HLS.WorkDir != "" {
+			extraRoots = append(extraRoots, cfg.Transcode.HLS.WorkDir)
+		}
+		minAge = cfg.Worker.OrphanWorkspaceAge
+	}
+	workspace.DefaultManager().RecoverOrphans(minAge, extraRoots...)
+	m := workspace.DefaultManager().GetMetrics()
+	logger.Infof("workspace recovery finished root=%s recovered_dirs=%d reclaimed_bytes=%d", workspace.DefaultManager().Root(), m.RecoveredDirs, m.ReclaimedBytes)
+
 	// 注册后台任务，让应用启动时统一管理
-	task.Register(&backgroundTaskAdapter{name: c.name, startFunc: c.worker.Start, stopFunc: c.worker.Stop})
+	// 存活状态最先注册、最后停止：排空期间实例仍显示在线
+	if c.presence != nil {
+		task.Register(c.presence)
+	}
+	// 上传池先于 worker 注册：按注册逆序停止时 worker 先停止不再产生新产物，上传池再排空剩余上传
+	if c.uploads != nil {
+		task.Register(c.uploads)
+	}
+	// 分配历史记录器同样先于 worker 注册，worker 停止时最后一批执行记录仍能写入
+	if c.assignments != nil {
+		task.Register(c.assignments)
+	}
+	if c.throughput != nil {
+		task.Register(c.throughput)
+	}
+	task.Register(&backgroundTaskAdapter{name: c.name, startFunc: c.worker.Start, stopFunc: c.worker.Stop, inFlight: activeTaskUUIDs(c.worker)})
 	if c.hlsWorker != nil {
 		task.Register(&backgroundTaskAdapter{name: c.name + "-hls", startFunc: c.hlsWorker.Start, stopFunc: c.hlsWorker.Stop})
 	}
+	for _, p := range c.pools {
+		task.Register(p)
+	}
+	if c.expiry != nil {
+		task.Register(c.expiry)
+	}
+	if c.groups != nil {
+		task.Register(c.groups)
+	}
+	if c.lifecycle != nil {
+		task.Register(c.lifecycle)
+	}
+	if warmer := executor.NewEncoderWarmer(config.GetGlobalConfig()); warmer != nil {
+		task.Register(warmer)
+	}
+	if c.exporter != nil {
+		task.Register(c.exporter)
+	}
+	if prober := storage.DefaultHealthProber(); prober != nil {
+		task.Register(prober)
+	}
+	if monitor := notify.NewMonitor(config.GetGlobalConfig(), notify.DefaultNotifier()); monitor != nil {
+		task.Register(monitor)
+	}
+	// 在 worker 之后注册：按注册逆序停止时先写最终快照，再停止 worker
+	if c.snapshot != nil {
+		task.Register(c.snapshot)
+	}
+	// 最后注册、最先停止：排队任务先交还数据库由其他实例接手，交还失败的再由快照记录
+	if c.redispatch != nil {
+		task.Register(c.redispatch)
+	}
 	logger.Infof("Transcode worker component registered background tasks name=%s", c.name)
 	return nil
 }
 
+// workerCapabilities 实例的能力标签，随存活状态发布，供排障时确认实例的编码配置与可执行的作业类型
+func workerCapabilities(cfg *config.Config, pools []*jobPool) []string {
+	caps := []string{"arch:" + runtime.GOARCH, "hls"}
+	if cfg != nil {
+		if codec := cfg.Transcode.FFmpeg.VideoCodec; codec != "" {
+			caps = append(caps, "codec:"+codec)
+		}
+		if accel := cfg.Transcode.FFmpeg.HardwareAccel; accel != "" {
+			caps = append(caps, "hwaccel:"+accel)
+		}
+		if cfg.Worker.ExpressLane.Enabled {
+			caps = append(caps, "lane:express")
+		}
+	}
+	for _, p := range pools {
+		caps = append(caps, "job:"+p.handler.Type())
+	}
+	return caps
+}
+
+// Stop 在排空阶段最后执行：worker 后台任务已停止，消费者已在接入阶段离开消费组，此时关闭队列不会再有入队
 func (c *transcodeWorkerComponent) Stop() error {
 	// 背景任务由 task.Manager 控制停止，这里保持幂等
 	if c.cancel != nil {
@@ -117,8 +310,29 @@ type backgroundTaskAdapter struct {
 	name      string
 	startFunc func(ctx context.Context) error
 	stopFunc  func() error
+	inFlight  func() []string
 }
 
 func (b *backgroundTaskAdapter) Name() string                    { return b.name }
 func (b *backgroundTaskAdapter) Start(ctx context.Context) error { return b.startFunc(ctx) }
 func (b *backgroundTaskAdapter) Stop() error                     { return b.stopFunc() }
+
+// InFlight 停机日志中列出仍在执行的任务
+func (b *backgroundTaskAdapter) InFlight() []string {
+	if b.inFlight == nil {
+		return nil
+	}
+	return b.inFlight()
+}
+
+// activeTaskUUIDs 正在执行的转码任务，形如 task_uuid(progress%)
+func activeTaskUUIDs(w TranscodeWorker) func() []string {
+	return func() []string {
+		claims := w.ActiveClaims()
+		out := make([]string, 0, len(claims))
+		for _, c := range claims {
+			out = append(out, fmt.Sprintf("%s(%d%%)", c.TaskUUID, c.Progress))
+		}
+		return out
+	}
+}
@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/service"
+	"transcode-service/pkg/logger"
+)
+
+// expiryTask 周期性执行任务截止时间检查
+type expiryTask struct {
+	svc      service.TaskExpiryService
+	interval time.Duration
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func newExpiryTask(svc service.TaskExpiryService, interval time.Duration) *expiryTask {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	return &expiryTask{svc: svc, interval: interval}
+}
+
+func (t *expiryTask) Name() string {
+	return "transcodeTaskExpiry"
+}
+
+func (t *expiryTask) Start(ctx context.Context) error {
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n, err := t.svc.ExpireOverdue(ctx)
+				if err != nil {
+					logger.Warnf("task expiry check failed error=%v", err)
+				} else if n > 0 {
+					logger.Infof("task expiry check finished expired=%d", n)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (t *expiryTask) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	return nil
+}
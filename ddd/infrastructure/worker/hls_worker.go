@@ -2,10 +2,10 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 
@@ -14,11 +14,19 @@ import (
 	"transcode-service/ddd/domain/port"
 	"transcode-service/ddd/domain/repo"
 	"transcode-service/ddd/domain/service"
+	"transcode-service/ddd/domain/vo"
 	vgrpc "transcode-service/ddd/infrastructure/grpc"
+	"transcode-service/ddd/infrastructure/publicurl"
 	"transcode-service/ddd/infrastructure/queue"
+	"transcode-service/ddd/infrastructure/storage"
+	"transcode-service/ddd/infrastructure/throughput"
+	"transcode-service/ddd/infrastructure/workspace"
+	"transcode-service/pkg/clock"
 	"transcode-service/pkg/config"
 	"transcode-service/pkg/grpcutil"
 	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+	"transcode-service/pkg/utils"
 )
 
 type HLSWorker interface {
@@ -26,6 +34,7 @@ type HLSWorker interface {
 	Stop() error
 	IsRunning() bool
 	GetStats() WorkerStats
+	JobHandler() JobHandler
 }
 
 type hlsWorkerImpl struct {
@@ -35,8 +44,14 @@ type hlsWorkerImpl struct {
 	hlsExecutor port.HLSExecutor
 	storage     gateway.StorageGateway
 	reporter    gateway.TranscodeResultReporter
+	videoSvc    service.VideoProcessingService
+	milestones  *service.MilestoneNotifier
+	master      *service.HLSMasterPublisher
 	cfg         *config.Config
 	workerCount int
+	claimID     string
+	handler     *hlsJobHandler
+	queued      sync.Map // 本副本已入队、尚未出队的作业
 	running     bool
 	cancel      context.CancelFunc
 	stats       WorkerStats
@@ -44,21 +59,32 @@ type hlsWorkerImpl struct {
 	wg          sync.WaitGroup
 }
 
-func NewHLSWorker(id string, hlsRepo repo.HLSJobRepository, hlsService service.HLSService, storage gateway.StorageGateway, reporter gateway.TranscodeResultReporter, cfg *config.Config, workerCount int) HLSWorker {
+func NewHLSWorker(id string, hlsRepo repo.HLSJobRepository, hlsService service.HLSService, storage gateway.StorageGateway, reporter gateway.TranscodeResultReporter, videoSvc service.VideoProcessingService, cfg *config.Config, workerCount int) HLSWorker {
 	if workerCount <= 0 {
 		workerCount = 1
 	}
-	return &hlsWorkerImpl{
+	w := &hlsWorkerImpl{
 		id:          id,
 		hlsRepo:     hlsRepo,
 		hlsService:  hlsService,
 		hlsExecutor: hlsService, // hlsService 实现了 HLSExecutor 接口
 		storage:     storage,
 		reporter:    reporter,
+		videoSvc:    videoSvc,
+		milestones:  service.NewMilestoneNotifier(cfg, reporter),
+		master:      service.NewHLSMasterPublisher(cfg, storage),
 		cfg:         cfg,
 		workerCount: workerCount,
-		stats:       WorkerStats{StartTime: time.Now()},
+		claimID:     buildClaimID(id),
+		stats:       WorkerStats{StartTime: clock.Now()},
 	}
+	w.handler = &hlsJobHandler{w: w}
+	return w
+}
+
+// JobHandler 返回 HLS 作业处理器
+func (w *hlsWorkerImpl) JobHandler() JobHandler {
+	return w.handler
 }
 
 func (w *hlsWorkerImpl) Start(ctx context.Context) error {
@@ -70,15 +96,10 @@ func (w *hlsWorkerImpl) Start(ctx context.Context) error {
 	workerCtx, cancel := context.WithCancel(ctx)
 	w.cancel = cancel
 	w.running = true
-	w.stats.StartTime = time.Now()
-	go func() {
-		jobs, err := w.hlsRepo.QueryHLSJobsByStatus(workerCtx, "pending", 100)
-		if err == nil {
-			for _, j := range jobs {
-				_ = queue.DefaultHLSJobQueue().Enqueue(workerCtx, j)
-			}
-		}
-	}()
+	w.stats.StartTime = clock.Now()
+	// 周期性对账代替启动时的一次性扫描，重复入队由认领保证只处理一次
+	w.wg.Add(1)
+	go w.reconcileLoop(workerCtx)
 
 	w.wg.Add(w.workerCount)
 	for i := 0; i < w.workerCount; i++ {
@@ -87,6 +108,61 @@ func (w *hlsWorkerImpl) Start(ctx context.Context) error {
 	return nil
 }
 
+// reconcileLoop 定期释放超时认领并把 pending 作业放入本地队列
+func (w *hlsWorkerImpl) reconcileLoop(ctx context.Context) {
+	defer w.wg.Done()
+	interval := 30 * time.Second
+	if w.cfg != nil && w.cfg.Worker.HLSReconcileInterval > 0 {
+		interval = w.cfg.Worker.HLSReconcileInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		w.reconcile(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *hlsWorkerImpl) reconcile(ctx context.Context) {
+	claimTTL := 10 * time.Minute
+	if w.cfg != nil && w.cfg.Worker.HLSClaimTTL > 0 {
+		claimTTL = w.cfg.Worker.HLSClaimTTL
+	}
+	now := clock.Now()
+	if n, err := w.hlsRepo.ReleaseStaleHLSClaims(ctx, now.Add(-claimTTL), now.Add(-w.processingTTL())); err != nil {
+		logger.Warnf("release stale hls claims failed worker_id=%s error=%v", w.id, err)
+	} else if n > 0 {
+		logger.Infof("released stale hls claims worker_id=%s count=%d", w.id, n)
+	}
+
+	jobs, err := w.hlsRepo.QueryHLSJobsByStatus(ctx, vo.HLSStatusPending.String(), 100)
+	if err != nil {
+		logger.Warnf("query pending hls jobs failed worker_id=%s error=%v", w.id, err)
+		return
+	}
+	for _, j := range jobs {
+		if _, queued := w.queued.LoadOrStore(j.JobUUID(), struct{}{}); queued {
+			continue
+		}
+		if err := queue.DefaultHLSJobQueue().Enqueue(ctx, j); err != nil {
+			w.queued.Delete(j.JobUUID())
+			logger.Warnf("requeue hls job failed job_uuid=%s error=%v", j.JobUUID(), err)
+		}
+	}
+}
+
+// buildClaimID 附加主机名，避免多副本使用相同 worker_id 配置时无法区分认领者
+func buildClaimID(id string) string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return id + "@" + host
+	}
+	return id
+}
+
 func (w *hlsWorkerImpl) Stop() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -107,6 +183,10 @@ func (w *hlsWorkerImpl) GetStats() WorkerStats { w.mu.RLock(); defer w.mu.RUnloc
 func (w *hlsWorkerImpl) workerLoop(ctx context.Context) {
 	defer w.wg.Done()
 	for {
+		// 存储不可达时暂停出队
+		if err := storage.DefaultHealthGate().Wait(ctx); err != nil {
+			return
+		}
 		job, err := queue.DefaultHLSJobQueue().Dequeue(ctx)
 		if err != nil {
 			if ctx.Err() != nil {
@@ -123,55 +203,132 @@ func (w *hlsWorkerImpl) workerLoop(ctx context.Context) {
 				jobCtx = ctxWithReq
 			}
 		}
-		_ = w.hlsRepo.UpdateHLSJobStatus(jobCtx, job.JobUUID(), "processing")
-		w.processJob(jobCtx, job)
+		w.queued.Delete(job.JobUUID())
+		// 通用作业流程：先占用共享编码槽位再认领，等待期间作业仍为 pending，可被其他副本领取
+		j := newHLSJob(job)
+		started, err := runJob(jobCtx, w.handler, j)
+		if !started {
+			return
+		}
+		reportJob(jobCtx, w.handler, j, err)
+	}
+}
+
+func (w *hlsWorkerImpl) claimAndProcess(ctx context.Context, job *entity.HLSJobEntity) {
+	claimed, err := w.hlsRepo.ClaimHLSJob(ctx, job.JobUUID(), w.claimID)
+	if err != nil {
+		logger.WithContext(ctx).Warnf("claim hls job failed job_uuid=%s error=%v", job.JobUUID(), err)
+		return
+	}
+	if !claimed {
+		// 已被其他副本认领或已不是 pending
+		return
+	}
+	_ = w.hlsRepo.UpdateHLSJobStatus(ctx, job.JobUUID(), "processing")
+	taskUUID := job.JobUUID()
+	if src := job.SourceJobUUID(); src != nil {
+		taskUUID = *src
+	}
+	w.milestones.HLSStarted(job.VideoUUID(), taskUUID)
+	w.processJob(ctx, job)
+}
+
+func hlsResolutions(job *entity.HLSJobEntity) []string {
+	out := make([]string, 0, len(job.GetConfig().Resolutions))
+	for _, r := range job.GetConfig().Resolutions {
+		out = append(out, r.Resolution)
+	}
+	return out
+}
+
+// processingTTL processing 作业的续期超时
+func (w *hlsWorkerImpl) processingTTL() time.Duration {
+	if w.cfg != nil && w.cfg.Worker.HLSProcessingTTL > 0 {
+		return w.cfg.Worker.HLSProcessingTTL
+	}
+	return 15 * time.Minute
+}
+
+// keepAlive 处理期间每 1/3 TTL 刷新作业的 updated_at，返回的函数停止续期
+func (w *hlsWorkerImpl) keepAlive(ctx context.Context, jobUUID string) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(w.processingTTL() / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := w.hlsRepo.TouchHLSJob(ctx, jobUUID); err != nil && ctx.Err() == nil {
+					logger.Warnf("touch hls job failed job_uuid=%s error=%v", jobUUID, err)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
 	}
 }
 
 func (w *hlsWorkerImpl) processJob(ctx context.Context, job *entity.HLSJobEntity) {
 	log := logger.WithContext(ctx)
-	w.updateStats(func(s *WorkerStats) { s.CurrentlyRunning++; s.LastTaskTime = time.Now() })
+	defer w.keepAlive(ctx, job.JobUUID())()
+	w.updateStats(func(s *WorkerStats) { s.CurrentlyRunning++; s.LastTaskTime = clock.Now() })
 	defer w.updateStats(func(s *WorkerStats) { s.CurrentlyRunning--; s.ProcessedTasks++ })
 
-	usedExistingLocal := false
-	localInput := ""
-	candidate := w.deriveLocalCandidate(job.InputPath())
-	if candidate != "" {
-		if fi, err := os.Stat(candidate); err == nil && !fi.IsDir() {
-			localInput = candidate
-			usedExistingLocal = true
-		}
+	// 独立工作目录：输入文件放在其中，HLS 输出目录登记后随作业一并清理（成功/失败/取消）
+	ws, err := workspace.DefaultManager().Acquire("hls-" + job.JobUUID())
+	if err != nil {
+		w.handleFailure(ctx, job, err)
+		return
 	}
-	if !usedExistingLocal {
-		localInput = w.getLocalInputPath(job)
-		if err := os.MkdirAll(filepath.Dir(localInput), 0o755); err != nil {
-			_ = w.hlsRepo.UpdateHLSJobError(ctx, job.JobUUID(), err.Error())
-			_ = w.hlsRepo.UpdateHLSJobStatus(ctx, job.JobUUID(), "failed")
-			w.updateStats(func(s *WorkerStats) { s.FailedTasks++ })
-			return
-		}
-		if err := w.storage.DownloadFile(ctx, job.InputPath(), localInput); err != nil {
-			_ = w.hlsRepo.UpdateHLSJobError(ctx, job.JobUUID(), err.Error())
-			_ = w.hlsRepo.UpdateHLSJobStatus(ctx, job.JobUUID(), "failed")
-			w.updateStats(func(s *WorkerStats) { s.FailedTasks++ })
+	ws.Track(job.OutputDir())
+	defer func() {
+		reclaimed := ws.Release()
+		log.Infof("hls workspace released job_uuid=%s reclaimed_bytes=%d", job.JobUUID(), reclaimed)
+	}()
+
+	localInput := ws.Path("input", utils.LocalFileName(job.InputPath()))
+	if err := w.storage.DownloadFile(ctx, job.InputPath(), localInput); err != nil {
+		if w.releaseIfInterrupted(ctx, job) {
 			return
 		}
-		defer os.Remove(localInput)
+		_ = w.hlsRepo.UpdateHLSJobError(ctx, job.JobUUID(), err.Error())
+		_ = w.hlsRepo.UpdateHLSJobStatus(ctx, job.JobUUID(), "failed")
+		w.updateStats(func(s *WorkerStats) { s.FailedTasks++ })
+		return
 	}
 
 	// 下游切片逻辑依赖 job.InputPath()，确保使用本地已下载的路径。
 	job.SetInputPath(localInput)
 
+	// 码流级重试：已完成码流不在本地，master 在上传后按库中的码流状态重新生成
+	retried := job.Renditions().CountByStatus(vo.RenditionCompleted) > 0
+
+	// 切片耗时计入每日编码时长，失败的尝试同样占用编码资源
+	sliceStarted := time.Now()
 	if w.hlsExecutor != nil {
-		if _, err := w.hlsExecutor.Slice(ctx, job, port.HLSOptions{}); err != nil {
+		_, err := w.hlsExecutor.Slice(ctx, job, port.HLSOptions{})
+		throughput.AddEncode(time.Since(sliceStarted))
+		if err != nil {
+			w.uploadCompletedRenditions(ctx, job, err)
 			w.handleFailure(ctx, job, err)
 			return
 		}
-	} else if err := w.hlsService.GenerateHLSSlices(ctx, job, localInput); err != nil {
-		errMsg := truncateError(err.Error(), 480)
-		// wrap truncated message with a constant format string to avoid vet's non-constant format warning
-		w.handleFailure(ctx, job, fmt.Errorf("%s", errMsg))
-		return
+	} else {
+		err := w.hlsService.GenerateHLSSlices(ctx, job, localInput)
+		throughput.AddEncode(time.Since(sliceStarted))
+		if err != nil {
+			w.uploadCompletedRenditions(ctx, job, err)
+			errMsg := truncateError(err.Error(), 480)
+			// wrap truncated message with a constant format string to avoid vet's non-constant format warning
+			w.handleFailure(ctx, job, fmt.Errorf("%s", errMsg))
+			return
+		}
 	}
 
 	objects := make([]gateway.UploadObject, 0, 32)
@@ -183,14 +340,15 @@ func (w *hlsWorkerImpl) processJob(ctx context.Context, job *entity.HLSJobEntity
 		if d.IsDir() {
 			return nil
 		}
-		rel := path
-		if strings.HasPrefix(rel, "storage"+string(filepath.Separator)) {
-			if r, e := filepath.Rel("storage", rel); e == nil {
-				rel = r
-			}
+		if retried && filepath.Base(path) == service.HLSMasterPlaylistName {
+			return nil
+		}
+		key, kerr := service.HLSObjectKey(w.cfg, job, path)
+		if kerr != nil {
+			return nil
 		}
 		ct := detectHLSContentType(path)
-		obj := gateway.UploadObject{LocalPath: path, ObjectKey: filepath.ToSlash(rel), ContentType: ct}
+		obj := gateway.UploadObject{LocalPath: path, ObjectKey: key, ContentType: ct}
 		objects = append(objects, obj)
 		return nil
 	})
@@ -201,23 +359,37 @@ func (w *hlsWorkerImpl) processJob(ctx context.Context, job *entity.HLSJobEntity
 		return
 	}
 	if err := w.storage.UploadObjects(ctx, objects); err != nil {
+		if w.releaseIfInterrupted(ctx, job) {
+			return
+		}
 		_ = w.hlsRepo.UpdateHLSJobError(ctx, job.JobUUID(), err.Error())
 		_ = w.hlsRepo.UpdateHLSJobStatus(ctx, job.JobUUID(), "failed")
 		w.updateStats(func(s *WorkerStats) { s.FailedTasks++ })
 		return
 	}
 
-	master := job.MasterPlaylist()
-	publicPath := ""
-	if master != nil {
-		m := *master
-		if strings.HasPrefix(m, "storage"+string(filepath.Separator)) {
-			if r, e := filepath.Rel("storage", m); e == nil {
-				m = r
-			}
+	masterKey := ""
+	if retried {
+		key, _, err := w.master.Publish(ctx, job)
+		if err != nil {
+			w.handleFailure(ctx, job, err)
+			return
+		}
+		masterKey = key
+	} else if master := job.MasterPlaylist(); master != nil {
+		// e.g. hls/uid/vid/job/master.m3u8
+		if key, err := service.HLSObjectKey(w.cfg, job, *master); err == nil {
+			masterKey = key
 		}
-		key := filepath.ToSlash(m) // e.g. hls/uid/vid/job/master.m3u8
-		publicPath = w.buildFileURL(strings.TrimLeft(key, "/"))
+	}
+
+	// 产物已全部上传，终态与回调不再随停机取消
+	ctx, cancel := finalWriteContext(ctx)
+	defer cancel()
+
+	publicPath := ""
+	if masterKey != "" {
+		publicPath = w.masterURL(job, masterKey)
 	}
 	if publicPath != "" {
 		_ = w.hlsRepo.UpdateHLSJobOutput(ctx, job.JobUUID(), publicPath)
@@ -225,79 +397,190 @@ func (w *hlsWorkerImpl) processJob(ctx context.Context, job *entity.HLSJobEntity
 	_ = w.hlsRepo.UpdateHLSJobProgress(ctx, job.JobUUID(), 100)
 	_ = w.hlsRepo.UpdateHLSJobStatus(ctx, job.JobUUID(), "completed")
 
-	// HLS 完成后回调视频服务，传递 master playlist 地址
+	// HLS 完成后按视频聚合状态回调上游，传递 master playlist 地址
 	if publicPath != "" {
-		taskUUID := ""
-		if job.SourceJobUUID() != nil {
-			taskUUID = *job.SourceJobUUID()
-		} else {
-			taskUUID = job.JobUUID()
-		}
+		w.notifyUpstream(ctx, job, publicPath, "")
+	}
 
-		// 通知 video-service：视频已发布，video_url 为 HLS master 地址
-		if cli := vgrpc.DefaultVideoServiceClient(); cli != nil {
-			if resp, err := cli.UpdateTranscodeResult(ctx, job.VideoUUID(), taskUUID, "published", publicPath, "", 0, 0); err != nil {
-				log.Warnf("video-service HLS callback failed video_uuid=%s task_uuid=%s error=%s", job.VideoUUID(), taskUUID, err.Error())
-			} else if resp != nil {
-				log.Infof("video-service HLS callback success=%v video_uuid=%s task_uuid=%s url=%s", resp.GetSuccess(), job.VideoUUID(), taskUUID, publicPath)
-			}
-		} else {
-			log.Warnf("video-service client is nil, skip HLS callback video_uuid=%s task_uuid=%s", job.VideoUUID(), taskUUID)
-		}
+	w.updateStats(func(s *WorkerStats) { s.SuccessfulTasks++ })
+}
 
-		// 通知 upload-service：最终 Published 状态 + HLS URL（方案 B）
-		if w.reporter != nil {
-			if err := w.reporter.ReportSuccess(ctx, job.VideoUUID(), taskUUID, publicPath); err != nil {
-				log.Warnf("upload-service HLS callback failed video_uuid=%s task_uuid=%s error=%s", job.VideoUUID(), taskUUID, err.Error())
-			} else {
-				log.Infof("upload-service HLS callback success video_uuid=%s task_uuid=%s url=%s", job.VideoUUID(), taskUUID, publicPath)
+// uploadCompletedRenditions 部分码流失败时先上传本次成功码流的产物（不含 master），之后重试只需处理失败的码流；
+// 上传失败的码流置回 pending，重试时重新切片
+func (w *hlsWorkerImpl) uploadCompletedRenditions(ctx context.Context, job *entity.HLSJobEntity, sliceErr error) {
+	if !errors.Is(sliceErr, service.ErrRenditionsFailed) || ctx.Err() != nil {
+		return
+	}
+	base := filepath.Clean(job.OutputDir())
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return
+	}
+	naming := service.HLSNaming(w.cfg)
+	renditions := job.Renditions()
+	uploaded := make([]*vo.HLSRendition, 0, len(renditions))
+	objects := make([]gateway.UploadObject, 0, 32)
+	for i := range renditions {
+		r := &renditions[i]
+		if r.Status != vo.RenditionCompleted {
+			continue
+		}
+		found := false
+		for _, e := range entries {
+			if e.IsDir() || !naming.IsRenditionFile(e.Name(), r.Resolution, job.JobUUID()) {
+				continue
+			}
+			path := filepath.Join(base, e.Name())
+			key, kerr := service.HLSObjectKey(w.cfg, job, path)
+			if kerr != nil {
+				continue
 			}
+			objects = append(objects, gateway.UploadObject{LocalPath: path, ObjectKey: key, ContentType: detectHLSContentType(path)})
+			found = true
+		}
+		if found {
+			uploaded = append(uploaded, r)
 		}
 	}
-
-	if usedExistingLocal {
-		_ = os.Remove(localInput)
+	if len(objects) == 0 {
+		return
 	}
-	if base != "" && base != "." {
-		if err := os.RemoveAll(base); err != nil {
-			log.Warnf("failed to clean local HLS dir path=%s error=%s", base, err.Error())
+	if err := w.storage.UploadObjects(ctx, objects); err != nil {
+		logger.WithContext(ctx).Warnf("upload completed hls renditions failed job_uuid=%s error=%v", job.JobUUID(), err)
+		for _, r := range uploaded {
+			r.Status = vo.RenditionPending
 		}
 	}
-	w.updateStats(func(s *WorkerStats) { s.SuccessfulTasks++ })
+	_ = w.hlsRepo.UpdateHLSJobRenditions(ctx, job.JobUUID(), renditions)
 }
 
 func (w *hlsWorkerImpl) handleFailure(ctx context.Context, job *entity.HLSJobEntity, err error) {
 	if err == nil || job == nil {
 		return
 	}
+	if w.releaseIfInterrupted(ctx, job) {
+		return
+	}
+	storage.DefaultHealthGate().Observe(err)
+	ctx, cancel := finalWriteContext(ctx)
+	defer cancel()
 	errMsg := truncateError(err.Error(), 480)
 	_ = w.hlsRepo.UpdateHLSJobError(ctx, job.JobUUID(), errMsg)
 	_ = w.hlsRepo.UpdateHLSJobStatus(ctx, job.JobUUID(), "failed")
 
+	w.notifyUpstream(ctx, job, "", errMsg)
+
+	w.updateStats(func(s *WorkerStats) { s.FailedTasks++ })
+}
+
+// releaseIfInterrupted 作业因停机中断时置回 pending，由其他副本或重启后重新处理，不标记失败也不回调上游；
+// 本地产物尚未上传，码流状态全部重置。返回 false 表示并非中断
+func (w *hlsWorkerImpl) releaseIfInterrupted(ctx context.Context, job *entity.HLSJobEntity) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+	writeCtx, cancel := finalWriteContext(ctx)
+	defer cancel()
+	job.ResetRenditions()
+	released, err := w.hlsRepo.ReleaseInterruptedHLSJob(writeCtx, job.JobUUID(), job.Renditions())
+	if err != nil {
+		logger.WithContext(ctx).Warnf("release interrupted hls job failed, left for reclaim job_uuid=%s error=%v", job.JobUUID(), err)
+		return true
+	}
+	if released {
+		metrics.Add("hls_jobs_released_on_shutdown_total", 1)
+		logger.WithContext(ctx).Infof("hls job interrupted by shutdown, released to pending job_uuid=%s", job.JobUUID())
+	}
+	return true
+}
+
+// masterURL 公开作业返回 master 的公开 URL，私有作业返回签名网关 URL（未配置签名密钥时为空，不回调发布）
+func (w *hlsWorkerImpl) masterURL(job *entity.HLSJobEntity, key string) string {
+	if !job.IsPrivate() {
+		return publicurl.DefaultBuilder().Build(key)
+	}
+	u, _, err := publicurl.BuildPrivate(key)
+	if err != nil {
+		logger.Errorf("sign private hls master failed job_uuid=%s error=%v", job.JobUUID(), err)
+		return ""
+	}
+	return u
+}
+
+// notifyUpstream 根据视频聚合状态回调 video-service 与 upload-service。
+// 聚合仍在处理中时不回调，由最后一个结束的子作业负责最终通知。
+func (w *hlsWorkerImpl) notifyUpstream(ctx context.Context, job *entity.HLSJobEntity, publicPath, errMsg string) {
+	log := logger.WithContext(ctx)
 	taskUUID := job.JobUUID()
 	if src := job.SourceJobUUID(); src != nil {
 		taskUUID = *src
 	}
 
-	// 通知 video-service 失败
+	state := vo.VideoStatePublished
+	if errMsg != "" {
+		state = vo.VideoStateFailed
+	}
+	if w.videoSvc != nil {
+		agg, err := w.videoSvc.Load(ctx, job.VideoUUID())
+		if err != nil {
+			log.Warnf("load video processing failed video_uuid=%s error=%v", job.VideoUUID(), err)
+		} else {
+			state = agg.State()
+			if !state.IsTerminal() {
+				log.Infof("video still processing, defer upstream callback video_uuid=%s state=%s", job.VideoUUID(), state)
+				return
+			}
+			if url := agg.PlaybackURL(); url != "" {
+				publicPath = url
+			}
+			if errMsg == "" {
+				errMsg = agg.FirstError()
+			}
+		}
+	}
+	log.Infof("video processing finished video_uuid=%s task_uuid=%s state=%s", job.VideoUUID(), taskUUID, state)
+
+	// 有可播放产物（published / partially_failed）时按发布处理，否则按失败处理
+	if state == vo.VideoStateFailed || publicPath == "" {
+		if errMsg == "" {
+			errMsg = "transcode failed"
+		}
+		if cli := vgrpc.DefaultVideoServiceClient(); cli != nil {
+			if resp, callErr := cli.UpdateTranscodeResult(ctx, job.VideoUUID(), taskUUID, "failed", "", errMsg, 0, 0); callErr != nil {
+				log.Warnf("video-service HLS failure callback failed video_uuid=%s task_uuid=%s error=%s", job.VideoUUID(), taskUUID, callErr.Error())
+			} else if resp != nil {
+				log.Infof("video-service HLS failure callback success=%v video_uuid=%s task_uuid=%s", resp.GetSuccess(), job.VideoUUID(), taskUUID)
+			}
+		} else {
+			log.Warnf("video-service client is nil, skip HLS failure callback video_uuid=%s task_uuid=%s", job.VideoUUID(), taskUUID)
+		}
+		if w.reporter != nil {
+			if repErr := w.reporter.ReportFailure(ctx, job.VideoUUID(), taskUUID, errMsg); repErr != nil {
+				log.Warnf("upload-service HLS failure callback failed video_uuid=%s task_uuid=%s error=%s", job.VideoUUID(), taskUUID, repErr.Error())
+			}
+		}
+		return
+	}
+
+	// 通知 video-service：视频已发布，video_url 为 HLS master 地址
 	if cli := vgrpc.DefaultVideoServiceClient(); cli != nil {
-		if resp, callErr := cli.UpdateTranscodeResult(ctx, job.VideoUUID(), taskUUID, "failed", "", errMsg, 0, 0); callErr != nil {
-			logger.WithContext(ctx).Warnf("video-service HLS failure callback failed video_uuid=%s task_uuid=%s error=%s", job.VideoUUID(), taskUUID, callErr.Error())
+		if resp, err := cli.UpdateTranscodeResult(ctx, job.VideoUUID(), taskUUID, "published", publicPath, "", 0, 0); err != nil {
+			log.Warnf("video-service HLS callback failed video_uuid=%s task_uuid=%s error=%s", job.VideoUUID(), taskUUID, err.Error())
 		} else if resp != nil {
-			logger.WithContext(ctx).Infof("video-service HLS failure callback success=%v video_uuid=%s task_uuid=%s", resp.GetSuccess(), job.VideoUUID(), taskUUID)
+			log.Infof("video-service HLS callback success=%v video_uuid=%s task_uuid=%s url=%s", resp.GetSuccess(), job.VideoUUID(), taskUUID, publicPath)
 		}
 	} else {
-		logger.WithContext(ctx).Warnf("video-service client is nil, skip HLS failure callback video_uuid=%s task_uuid=%s", job.VideoUUID(), taskUUID)
+		log.Warnf("video-service client is nil, skip HLS callback video_uuid=%s task_uuid=%s", job.VideoUUID(), taskUUID)
 	}
 
-	// 通知 upload-service / 结果上报方失败
+	// 通知 upload-service：最终 Published 状态 + HLS URL（方案 B）
 	if w.reporter != nil {
-		if repErr := w.reporter.ReportFailure(ctx, job.VideoUUID(), taskUUID, errMsg); repErr != nil {
-			logger.WithContext(ctx).Warnf("upload-service HLS failure callback failed video_uuid=%s task_uuid=%s error=%s", job.VideoUUID(), taskUUID, repErr.Error())
+		if err := w.reporter.ReportSuccess(ctx, job.VideoUUID(), taskUUID, publicPath); err != nil {
+			log.Warnf("upload-service HLS callback failed video_uuid=%s task_uuid=%s error=%s", job.VideoUUID(), taskUUID, err.Error())
+		} else {
+			log.Infof("upload-service HLS callback success video_uuid=%s task_uuid=%s url=%s", job.VideoUUID(), taskUUID, publicPath)
 		}
 	}
-
-	w.updateStats(func(s *WorkerStats) { s.FailedTasks++ })
 }
 
 func (w *hlsWorkerImpl) updateStats(f func(*WorkerStats)) {
@@ -306,27 +589,6 @@ func (w *hlsWorkerImpl) updateStats(f func(*WorkerStats)) {
 	f(&w.stats)
 }
 
-func (w *hlsWorkerImpl) getLocalInputPath(job *entity.HLSJobEntity) string {
-	tempDir := os.TempDir()
-	if w.cfg != nil && w.cfg.Transcode.FFmpeg.TempDir != "" {
-		tempDir = w.cfg.Transcode.FFmpeg.TempDir
-	}
-	fileName := filepath.Base(job.InputPath())
-	return filepath.Join(tempDir, "inputs", fmt.Sprintf("hls_%s_%s", job.JobUUID(), fileName))
-}
-
-func (w *hlsWorkerImpl) deriveLocalCandidate(remoteKey string) string {
-	key := strings.TrimPrefix(remoteKey, "/")
-	if key == "" {
-		return ""
-	}
-	base := os.TempDir()
-	if w.cfg != nil && w.cfg.Transcode.FFmpeg.TempDir != "" {
-		base = w.cfg.Transcode.FFmpeg.TempDir
-	}
-	return filepath.Join(base, key)
-}
-
 // truncateError ensures error messages won't overflow downstream DB columns (e.g., VARCHAR(500)).
 func truncateError(msg string, max int) string {
 	if max <= 0 {
@@ -339,43 +601,6 @@ func truncateError(msg string, max int) string {
 	return string(runes[:max])
 }
 
-func (w *hlsWorkerImpl) buildFileURL(objectKey string) string {
-	if strings.TrimSpace(objectKey) == "" {
-		return ""
-	}
-	cfg := w.cfg
-	if cfg == nil {
-		cfg = config.GetGlobalConfig()
-	}
-	if cfg == nil {
-		return objectKey
-	}
-
-	key := strings.TrimLeft(objectKey, "/")
-	if strings.HasPrefix(key, "transcode/") {
-		key = strings.TrimPrefix(key, "transcode/")
-	}
-	path := fmt.Sprintf("/storage/transcode/%s", key)
-	publicBase := strings.TrimSpace(cfg.Public.StorageBase)
-	if publicBase != "" {
-		if !strings.HasPrefix(publicBase, "http://") && !strings.HasPrefix(publicBase, "https://") {
-			publicBase = "http://" + publicBase
-		}
-		return strings.TrimRight(publicBase, "/") + path
-	}
-	return path
-}
-
 func detectHLSContentType(path string) string {
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".m3u8":
-		return "application/vnd.apple.mpegurl"
-	case ".ts":
-		return "video/mp2t"
-	case ".mp4":
-		return "video/mp4"
-	default:
-		return "application/octet-stream"
-	}
+	return vo.ContentTypeForExtension(filepath.Ext(path))
 }
@@ -0,0 +1,241 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/port"
+	"transcode-service/ddd/infrastructure/budget"
+	"transcode-service/ddd/infrastructure/failstats"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/ctxutil"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// Job 通用作业，Payload 为具体作业类型解码后的实体
+type Job struct {
+	Type        string
+	ID          string
+	VideoUUID   string   // 关联视频，用于同视频串行栅栏；为空时不受栅栏限制
+	Resolutions []string // 用于计算共享编码槽位权重
+	Attempt     int
+	Payload     interface{}
+	// Reserved 由快车道专用协程执行，并发由 worker.express_lane.reserved_slots 预留，不占共享编码槽位
+	Reserved bool
+	// Finish Execute 返回 port.ErrUploadDeferred 时，后台上传结束后以最终结果调用一次；由调用方在 runJob 前设置
+	Finish func(err error)
+}
+
+// JobHandler 作业类型插件。新增作业类型（缩略图、DASH 等）只需在单独文件中实现该接口并在 init 中
+// RegisterJobHandler；排队、编码槽位、重试、统计与回调由 worker 基础设施统一处理
+type JobHandler interface {
+	// Type 作业类型，全局唯一
+	Type() string
+	// Decode 把提交的原始负载解码为作业
+	Decode(ctx context.Context, raw []byte) (*Job, error)
+	// Execute 执行作业
+	Execute(ctx context.Context, job *Job) error
+	// Report 作业结束（成功或最终失败）后的回调
+	Report(ctx context.Context, job *Job, err error)
+}
+
+// RetryableJobHandler 可选接口：声明作业失败后的最大尝试次数，未实现时不重试
+type RetryableJobHandler interface {
+	MaxAttempts() int
+}
+
+// jobType 已注册的作业类型；插件类型由通用作业池排队执行，池启动前 enqueue 为空
+type jobType struct {
+	handler JobHandler
+	builtin bool
+	enqueue func(ctx context.Context, job *Job) error
+}
+
+var (
+	jobTypesMu sync.RWMutex
+	jobTypes   = make(map[string]*jobType)
+)
+
+// RegisterJobHandler 注册作业类型插件，重复注册同一类型会 panic
+func RegisterJobHandler(h JobHandler) {
+	registerJobType(&jobType{handler: h})
+}
+
+// registerBuiltinJobHandler 注册自带队列与认领逻辑的内置作业类型（transcode、hls）
+func registerBuiltinJobHandler(h JobHandler, enqueue func(ctx context.Context, job *Job) error) {
+	registerJobType(&jobType{handler: h, builtin: true, enqueue: enqueue})
+}
+
+func registerJobType(t *jobType) {
+	jobTypesMu.Lock()
+	defer jobTypesMu.Unlock()
+	if _, ok := jobTypes[t.handler.Type()]; ok {
+		panic(fmt.Sprintf("job handler already registered: %s", t.handler.Type()))
+	}
+	jobTypes[t.handler.Type()] = t
+}
+
+// pluginJobHandlers 需要通用作业池执行的插件作业类型
+func pluginJobHandlers() []JobHandler {
+	jobTypesMu.RLock()
+	defer jobTypesMu.RUnlock()
+	out := make([]JobHandler, 0, len(jobTypes))
+	for _, t := range jobTypes {
+		if !t.builtin {
+			out = append(out, t.handler)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Type() < out[j].Type() })
+	return out
+}
+
+// setJobEnqueue 通用作业池启动后接管对应类型的排队
+func setJobEnqueue(typ string, enqueue func(ctx context.Context, job *Job) error) {
+	jobTypesMu.Lock()
+	defer jobTypesMu.Unlock()
+	if t, ok := jobTypes[typ]; ok {
+		t.enqueue = enqueue
+	}
+}
+
+// LookupJobHandler 按类型查找作业处理器
+func LookupJobHandler(typ string) (JobHandler, bool) {
+	jobTypesMu.RLock()
+	defer jobTypesMu.RUnlock()
+	t, ok := jobTypes[typ]
+	if !ok {
+		return nil, false
+	}
+	return t.handler, true
+}
+
+// RegisteredJobTypes 已注册的作业类型，按名称排序
+func RegisteredJobTypes() []string {
+	jobTypesMu.RLock()
+	defer jobTypesMu.RUnlock()
+	out := make([]string, 0, len(jobTypes))
+	for typ := range jobTypes {
+		out = append(out, typ)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// SubmitJob 解码并提交作业到对应类型的队列
+func SubmitJob(ctx context.Context, typ string, raw []byte) (*Job, error) {
+	h, ok := LookupJobHandler(typ)
+	if !ok {
+		return nil, fmt.Errorf("unknown job type: %s", typ)
+	}
+	job, err := h.Decode(ctx, raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s job: %w", typ, err)
+	}
+	job.Type = typ
+	if err := enqueueJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// enqueueJob 把已构造好的作业提交到 job.Type 对应的队列
+func enqueueJob(ctx context.Context, job *Job) error {
+	jobTypesMu.RLock()
+	t, ok := jobTypes[job.Type]
+	var enqueue func(ctx context.Context, job *Job) error
+	if ok {
+		enqueue = t.enqueue
+	}
+	jobTypesMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown job type: %s", job.Type)
+	}
+	if enqueue == nil {
+		return fmt.Errorf("job type %s is not running on this instance", job.Type)
+	}
+	return enqueue(ctx, job)
+}
+
+// finalWriteContext 作业结束后的终态落库与上报使用：脱离停机取消，最长 worker.final_write_timeout
+func finalWriteContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	var grace time.Duration
+	if cfg := config.GetGlobalConfig(); cfg != nil {
+		grace = cfg.Worker.FinalWriteTimeout
+	}
+	return ctxutil.Detached(ctx, grace)
+}
+
+// reportJob 最终结果上报不随停机取消
+func reportJob(ctx context.Context, h JobHandler, job *Job, err error) {
+	reportCtx, cancel := finalWriteContext(ctx)
+	defer cancel()
+	h.Report(reportCtx, job, err)
+}
+
+// runJob 通用执行流程：同视频栅栏 -> 占用共享编码槽位 -> 执行 -> 按类型统计并写入分配历史，panic 视为失败。
+// 未拿到槽位（停机）时 started 为 false；Report 由调用方在确定不再重试后调用。
+// 上传交给上传池（port.ErrUploadDeferred）时编码槽位随编码结束释放，成功/失败统计推迟到 Finish
+func runJob(ctx context.Context, h JobHandler, job *Job) (started bool, err error) {
+	// 先过视频栅栏再占槽位，等待同视频作业时不占用编码槽位
+	releaseFence, err := acquireVideoFence(ctx, job)
+	if err != nil {
+		return false, err
+	}
+	defer releaseFence()
+
+	requestedAt := clock.Now()
+	encodeBudget := budget.DefaultEncodeBudget()
+	lease := &budget.Lease{}
+	if !job.Reserved {
+		if lease, err = encodeBudget.Acquire(ctx, job.Type, job.Resolutions...); err != nil {
+			return false, err
+		}
+	}
+	defer lease.Release()
+
+	prefix := "job_" + job.Type
+	metrics.Add(prefix+"_started_total", 1)
+	finish := job.Finish
+	job.Finish = func(err error) {
+		countJobResult(prefix, err)
+		if finish != nil {
+			finish(err)
+		}
+	}
+	start := time.Now()
+	startedAt := clock.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job handler panic: %v", r)
+			logger.Errorf("job handler panic type=%s job_id=%s panic=%v", job.Type, job.ID, r)
+		}
+		encodeErr := err
+		if errors.Is(err, port.ErrUploadDeferred) {
+			encodeErr = nil
+			metrics.Add(prefix+"_upload_deferred_total", 1)
+		}
+		recordAssignment(job, startedAt, startedAt.Sub(requestedAt), lease.Weight(), encodeBudget.Stats().Slots, ctx.Err(), encodeErr)
+		metrics.SetFloat(prefix+"_last_duration_seconds", time.Since(start).Seconds())
+		if !errors.Is(err, port.ErrUploadDeferred) {
+			countJobResult(prefix, err)
+		}
+	}()
+	return true, h.Execute(ctx, job)
+}
+
+// countJobResult 按作业类型统计最终结果
+func countJobResult(prefix string, err error) {
+	if err != nil {
+		metrics.Add(prefix+"_failed_total", 1)
+		metrics.Add("jobs_failed_total", 1)
+		failstats.Default().Record(err)
+		return
+	}
+	metrics.Add(prefix+"_succeeded_total", 1)
+}
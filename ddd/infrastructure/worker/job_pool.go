@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"transcode-service/pkg/logger"
+)
+
+const (
+	defaultJobPoolCapacity = 100
+	// jobRetryBaseDelay 第 n 次重试延迟 n 倍
+	jobRetryBaseDelay = 10 * time.Second
+)
+
+// jobPool 插件作业类型的通用执行池：内存排队、固定并发、失败按 MaxAttempts 重试
+type jobPool struct {
+	handler JobHandler
+	workers int
+	queue   chan *Job
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	closed  bool
+}
+
+func newJobPool(h JobHandler, workers int) *jobPool {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &jobPool{handler: h, workers: workers, queue: make(chan *Job, defaultJobPoolCapacity)}
+}
+
+func (p *jobPool) Name() string {
+	return "job-pool-" + p.handler.Type()
+}
+
+func (p *jobPool) Start(ctx context.Context) error {
+	poolCtx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	setJobEnqueue(p.handler.Type(), p.enqueue)
+	p.wg.Add(p.workers)
+	for i := 0; i < p.workers; i++ {
+		go p.loop(poolCtx)
+	}
+	logger.Infof("job pool started type=%s workers=%d", p.handler.Type(), p.workers)
+	return nil
+}
+
+// Stop 停止接收新作业并等待执行中的作业结束，队列中未执行的作业丢弃
+func (p *jobPool) Stop() error {
+	setJobEnqueue(p.handler.Type(), nil)
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+	if n := len(p.queue); n > 0 {
+		logger.Warnf("job pool stopped with queued jobs dropped type=%s count=%d", p.handler.Type(), n)
+	}
+	return nil
+}
+
+func (p *jobPool) enqueue(ctx context.Context, job *Job) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return fmt.Errorf("job pool %s is closed", p.handler.Type())
+	}
+	select {
+	case p.queue <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return fmt.Errorf("job pool %s is full", p.handler.Type())
+	}
+}
+
+func (p *jobPool) loop(ctx context.Context) {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-p.queue:
+			p.process(ctx, job)
+		}
+	}
+}
+
+func (p *jobPool) process(ctx context.Context, job *Job) {
+	job.Attempt++
+	started, err := runJob(ctx, p.handler, job)
+	if !started {
+		return
+	}
+	if err != nil && ctx.Err() == nil && job.Attempt < p.maxAttempts() {
+		delay := time.Duration(job.Attempt) * jobRetryBaseDelay
+		logger.Warnf("job failed, retrying type=%s job_id=%s attempt=%d delay=%s error=%v", job.Type, job.ID, job.Attempt, delay, err)
+		time.AfterFunc(delay, func() {
+			// 等待重试期间已停机时不再入队，直接上报最终结果
+			qerr := ctx.Err()
+			if qerr == nil {
+				qerr = p.enqueue(ctx, job)
+			}
+			if qerr != nil {
+				logger.Warnf("requeue job failed type=%s job_id=%s error=%v", job.Type, job.ID, qerr)
+				reportJob(ctx, p.handler, job, err)
+			}
+		})
+		return
+	}
+	reportJob(ctx, p.handler, job, err)
+}
+
+func (p *jobPool) maxAttempts() int {
+	if r, ok := p.handler.(RetryableJobHandler); ok && r.MaxAttempts() > 0 {
+		return r.MaxAttempts()
+	}
+	return 1
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/service"
+	"transcode-service/pkg/logger"
+)
+
+// restorePollInterval 恢复请求的检查周期，短于归档周期以便尽快完成用户触发的恢复
+const restorePollInterval = time.Minute
+
+// lifecycleTask 周期性归档冷产物并处理恢复请求
+type lifecycleTask struct {
+	svc      service.OutputLifecycleService
+	interval time.Duration
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func newLifecycleTask(svc service.OutputLifecycleService, interval time.Duration) *lifecycleTask {
+	return &lifecycleTask{svc: svc, interval: interval}
+}
+
+func (t *lifecycleTask) Name() string {
+	return "outputLifecycle"
+}
+
+func (t *lifecycleTask) Start(ctx context.Context) error {
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		archiveTicker := time.NewTicker(t.interval)
+		defer archiveTicker.Stop()
+		restoreTicker := time.NewTicker(restorePollInterval)
+		defer restoreTicker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-archiveTicker.C:
+				n, err := t.svc.ArchiveIdle(ctx)
+				if err != nil {
+					logger.Warnf("output archive check failed error=%v", err)
+				} else if n > 0 {
+					logger.Infof("output archive check finished archived=%d", n)
+				}
+			case <-restoreTicker.C:
+				if _, err := t.svc.RestoreRequested(ctx); err != nil {
+					logger.Warnf("output restore check failed error=%v", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (t *lifecycleTask) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	return nil
+}
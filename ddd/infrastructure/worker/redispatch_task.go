@@ -0,0 +1,116 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/infrastructure/queue"
+	"transcode-service/ddd/infrastructure/storage"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// redispatchTask 停机时把本地队列中尚未开始的任务交还数据库（打上 redispatch_at），
+// 运行期间周期性认领其他实例交还的任务并入队
+type redispatchTask struct {
+	cfg      config.RedispatchConfig
+	taskRepo repo.TranscodeJobRepository
+	queue    queue.TaskQueue
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func newRedispatchTask(cfg config.RedispatchConfig, taskRepo repo.TranscodeJobRepository, q queue.TaskQueue) *redispatchTask {
+	return &redispatchTask{cfg: cfg, taskRepo: taskRepo, queue: q}
+}
+
+func (t *redispatchTask) Name() string {
+	return "taskRedispatch"
+}
+
+func (t *redispatchTask) Start(ctx context.Context) error {
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(t.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.claim(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop 先停止认领，再交还队列中的任务；交还失败的任务放回队列，由流水线快照兜底
+func (t *redispatchTask) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	var queued []*entity.TranscodeTaskEntity
+	for {
+		task, err := t.queue.TryDequeue(context.Background())
+		if err != nil || task == nil {
+			break
+		}
+		queued = append(queued, task)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	marked, failed := 0, 0
+	for _, task := range queued {
+		ok, err := t.taskRepo.MarkTranscodeJobForRedispatch(ctx, task.TaskUUID())
+		if err == nil && ok {
+			marked++
+			continue
+		}
+		if err != nil {
+			failed++
+			logger.Warnf("mark task for redispatch failed task_uuid=%s error=%v", task.TaskUUID(), err)
+			if err := t.queue.Enqueue(context.Background(), task); err != nil {
+				logger.Warnf("put back task after redispatch failure failed task_uuid=%s error=%v", task.TaskUUID(), err)
+			}
+		}
+		// ok=false：任务已不是 pending（已取消/过期等），无需交还
+	}
+	metrics.Add("tasks_redispatched_on_shutdown_total", int64(marked))
+	metrics.Add("tasks_redispatch_mark_failures_total", int64(failed))
+	logger.Infof("queued tasks handed back for redispatch queued=%d marked=%d failed=%d", len(queued), marked, failed)
+	return nil
+}
+
+// claim 认领一批等待重新派发的任务并加入本地队列
+func (t *redispatchTask) claim(ctx context.Context) {
+	if !storage.DefaultHealthGate().Healthy() {
+		return
+	}
+	tasks, err := t.taskRepo.QueryRedispatchTranscodeJobs(ctx, t.cfg.BatchSize)
+	if err != nil {
+		logger.Warnf("query redispatch tasks failed error=%v", err)
+		return
+	}
+	for _, task := range tasks {
+		ok, err := t.taskRepo.ClaimRedispatchTranscodeJob(ctx, task.TaskUUID())
+		if err != nil || !ok {
+			continue
+		}
+		if err := t.queue.Enqueue(ctx, task); err != nil {
+			// 入队失败时重新打标，留给下一个周期或其他实例
+			logger.Warnf("enqueue redispatched task failed task_uuid=%s error=%v", task.TaskUUID(), err)
+			_, _ = t.taskRepo.MarkTranscodeJobForRedispatch(ctx, task.TaskUUID())
+			continue
+		}
+		metrics.Add("tasks_redispatch_claimed_total", 1)
+		logger.Infof("redispatched task claimed task_uuid=%s", task.TaskUUID())
+	}
+}
@@ -0,0 +1,148 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/queue"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+// snapshotTask 周期性持久化本实例的运行中/排队中任务，启动时对账已停止实例的快照
+type snapshotTask struct {
+	instanceID string
+	cfg        config.SnapshotConfig
+	repo       repo.PipelineSnapshotRepository
+	taskRepo   repo.TranscodeJobRepository
+	worker     TranscodeWorker
+	queue      queue.TaskQueue
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+func newSnapshotTask(instanceID string, cfg config.SnapshotConfig, snapshotRepo repo.PipelineSnapshotRepository, taskRepo repo.TranscodeJobRepository, worker TranscodeWorker, q queue.TaskQueue) *snapshotTask {
+	return &snapshotTask{instanceID: instanceID, cfg: cfg, repo: snapshotRepo, taskRepo: taskRepo, worker: worker, queue: q}
+}
+
+func (t *snapshotTask) Name() string {
+	return "pipelineSnapshot"
+}
+
+func (t *snapshotTask) Start(ctx context.Context) error {
+	t.reconcile(ctx)
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(t.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.save(ctx, t.worker.ActiveClaims(), false)
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop 先于 worker 停止：取出队列中尚未执行的任务，连同运行中任务写入最终快照
+func (t *snapshotTask) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	claims := t.worker.ActiveClaims()
+	for {
+		task, err := t.queue.TryDequeue(context.Background())
+		if err != nil || task == nil {
+			break
+		}
+		claims = append(claims, vo.TaskClaim{TaskUUID: task.TaskUUID(), VideoUUID: task.VideoUUID(), State: vo.ClaimQueued, Slot: -1})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	t.save(ctx, claims, true)
+	logger.Infof("pipeline snapshot released instance_id=%s claims=%d", t.instanceID, len(claims))
+	return nil
+}
+
+func (t *snapshotTask) save(ctx context.Context, claims []vo.TaskClaim, released bool) {
+	snap := &vo.PipelineSnapshot{InstanceID: t.instanceID, TakenAt: clock.Now(), Released: released, Claims: claims}
+	if err := t.repo.SavePipelineSnapshot(ctx, snap); err != nil {
+		logger.Warnf("save pipeline snapshot failed instance_id=%s error=%v", t.instanceID, err)
+		return
+	}
+	metrics.Set("pipeline_snapshot_claims", int64(len(claims)))
+}
+
+// reconcile 接管已停止或已宕机实例（含本实例上一次运行）的快照：
+// 排队中的 pending 任务原样恢复入队，执行中被打断的 processing 任务重置为 pending 后重新排队
+func (t *snapshotTask) reconcile(ctx context.Context) {
+	snaps, err := t.repo.ListPipelineSnapshots(ctx)
+	if err != nil {
+		logger.Warnf("list pipeline snapshots failed error=%v", err)
+		return
+	}
+	now := clock.Now()
+	for _, snap := range snaps {
+		if snap.InstanceID != t.instanceID && !snap.Orphaned(now, t.cfg.StaleAfter) {
+			continue
+		}
+		owned, err := t.repo.DeletePipelineSnapshot(ctx, snap.InstanceID)
+		if err != nil || !owned {
+			continue
+		}
+		resumed, requeued := 0, 0
+		for _, claim := range snap.Claims {
+			switch t.restoreClaim(ctx, claim) {
+			case vo.TaskStatusPending:
+				resumed++
+			case vo.TaskStatusProcessing:
+				requeued++
+			}
+		}
+		metrics.Add("pipeline_snapshot_resumed_total", int64(resumed))
+		metrics.Add("pipeline_snapshot_requeued_total", int64(requeued))
+		logger.Infof("pipeline snapshot reconciled instance_id=%s released=%v taken_at=%s claims=%d resumed=%d requeued=%d",
+			snap.InstanceID, snap.Released, snap.TakenAt.Format(time.RFC3339), len(snap.Claims), resumed, requeued)
+	}
+}
+
+// restoreClaim 按库中最新状态恢复单个任务，返回恢复前的状态；无需恢复时返回零值
+func (t *snapshotTask) restoreClaim(ctx context.Context, claim vo.TaskClaim) vo.TaskStatus {
+	task, err := t.taskRepo.GetTranscodeJob(ctx, claim.TaskUUID)
+	if err != nil || task == nil {
+		return vo.TaskStatus{}
+	}
+	prev := task.Status()
+	switch prev {
+	case vo.TaskStatusPending:
+	case vo.TaskStatusProcessing:
+		if err := task.TransitionTo(vo.TaskStatusPending); err != nil {
+			return vo.TaskStatus{}
+		}
+		task.SetProgress(0)
+		task.SetErrorMessage("")
+		if err := t.taskRepo.SaveTranscodeJobStatus(ctx, task); err != nil {
+			logger.Warnf("reset interrupted task failed task_uuid=%s error=%v", claim.TaskUUID, err)
+			return vo.TaskStatus{}
+		}
+	default:
+		// 已结束或等待退避重试的任务不由快照恢复
+		return vo.TaskStatus{}
+	}
+	if err := t.queue.Enqueue(ctx, task); err != nil {
+		logger.Warnf("requeue task from snapshot failed task_uuid=%s error=%v", claim.TaskUUID, err)
+		return vo.TaskStatus{}
+	}
+	return prev
+}
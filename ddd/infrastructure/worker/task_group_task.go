@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/service"
+	"transcode-service/pkg/logger"
+)
+
+// taskGroupTask 周期性检查任务组是否完成并投递完成回调
+type taskGroupTask struct {
+	svc      service.TaskGroupService
+	interval time.Duration
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func newTaskGroupTask(svc service.TaskGroupService, interval time.Duration) *taskGroupTask {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &taskGroupTask{svc: svc, interval: interval}
+}
+
+func (t *taskGroupTask) Name() string {
+	return "taskGroupCompletion"
+}
+
+func (t *taskGroupTask) Start(ctx context.Context) error {
+	ctx, t.cancel = context.WithCancel(ctx)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				completed, delivered, err := t.svc.CheckCompletion(ctx)
+				if err != nil {
+					logger.Warnf("task group completion check failed error=%v", err)
+				} else if completed > 0 || delivered > 0 {
+					logger.Infof("task group completion check finished completed=%d callbacks_delivered=%d", completed, delivered)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (t *taskGroupTask) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	return nil
+}
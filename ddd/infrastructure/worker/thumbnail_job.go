@@ -0,0 +1,164 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"transcode-service/ddd/domain/gateway"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/budget"
+	"transcode-service/ddd/infrastructure/executor"
+	grpcClient "transcode-service/ddd/infrastructure/grpc"
+	"transcode-service/ddd/infrastructure/storage"
+	"transcode-service/ddd/infrastructure/workspace"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+	"transcode-service/pkg/utils"
+)
+
+// thumbnailWaitTimeout 同步截帧的最长等待（排队 + 下载 + 截帧 + 上传）
+const thumbnailWaitTimeout = 60 * time.Second
+
+func init() {
+	RegisterJobHandler(&thumbnailJobHandler{})
+}
+
+// thumbnailJob 截帧作业负载；done 非空时由 Report 回传结果给同步等待方
+type thumbnailJob struct {
+	spec vo.ThumbnailSpec
+	done chan error
+}
+
+// thumbnailJobHandler 按时间戳截取单帧并上传，供编辑器自定义封面。
+// 不关联 VideoUUID，不受同视频栅栏限制，避免排在该视频的长转码之后
+type thumbnailJobHandler struct {
+	once    sync.Once
+	ff      *executor.FFmpegExecutor
+	storage gateway.StorageGateway
+}
+
+func (h *thumbnailJobHandler) Type() string { return budget.JobThumbnail }
+
+// Decode 负载为 {"user_uuid","video_uuid","source_key","timestamp_ms","width","height","format"}
+func (h *thumbnailJobHandler) Decode(ctx context.Context, raw []byte) (*Job, error) {
+	var req struct {
+		UserUUID    string `json:"user_uuid"`
+		VideoUUID   string `json:"video_uuid"`
+		SourceKey   string `json:"source_key"`
+		TimestampMs int64  `json:"timestamp_ms"`
+		Width       int    `json:"width"`
+		Height      int    `json:"height"`
+		Format      string `json:"format"`
+	}
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, err
+	}
+	if req.VideoUUID == "" || req.SourceKey == "" {
+		return nil, fmt.Errorf("video_uuid and source_key are required")
+	}
+	if req.TimestampMs < 0 || req.Width < 0 || req.Width > vo.MaxThumbnailDimension || req.Height < 0 || req.Height > vo.MaxThumbnailDimension {
+		return nil, fmt.Errorf("timestamp_ms must be >= 0 and width/height within 0-%d", vo.MaxThumbnailDimension)
+	}
+	format, err := vo.ParseImageFormat(req.Format)
+	if err != nil {
+		return nil, err
+	}
+	at := time.Duration(req.TimestampMs) * time.Millisecond
+	spec := vo.ThumbnailSpec{
+		UserUUID:  req.UserUUID,
+		VideoUUID: req.VideoUUID,
+		SourceKey: req.SourceKey,
+		At:        at,
+		Width:     req.Width,
+		Height:    req.Height,
+		Format:    format,
+		ObjectKey: vo.ThumbnailObjectKey(req.UserUUID, req.VideoUUID, at, req.Width, req.Height, format),
+	}
+	return newThumbnailJob(spec, nil), nil
+}
+
+func newThumbnailJob(spec vo.ThumbnailSpec, done chan error) *Job {
+	return &Job{Type: budget.JobThumbnail, ID: clock.NewID(), Payload: &thumbnailJob{spec: spec, done: done}}
+}
+
+// setup 插件在 init 中注册时配置尚未加载，首次执行时再创建执行器。
+// 源文件与产物下载经源文件缓存，同一视频反复截帧只下载一次；静态加密的源在下载到工作目录时解密
+func (h *thumbnailJobHandler) setup() {
+	h.once.Do(func() {
+		h.storage = storage.NewDecryptingGateway(storage.NewSourceCachingGateway(storage.DefaultStorageGateway(), storage.DefaultSourceCache()), grpcClient.DefaultKeyProvider())
+		h.ff = executor.NewFFmpegExecutor(config.GetGlobalConfig(), h.storage)
+	})
+}
+
+func (h *thumbnailJobHandler) Execute(ctx context.Context, job *Job) error {
+	tj, ok := job.Payload.(*thumbnailJob)
+	if !ok {
+		return fmt.Errorf("unexpected thumbnail job payload %T", job.Payload)
+	}
+	spec := tj.spec
+	ws, err := workspace.DefaultManager().Acquire(job.ID)
+	if err != nil {
+		return err
+	}
+	defer ws.Release()
+
+	h.setup()
+	inputPath := ws.Path("input", utils.LocalFileName(spec.SourceKey))
+	if err := h.storage.DownloadFile(ctx, spec.SourceKey, inputPath); err != nil {
+		return fmt.Errorf("download thumbnail source %s: %w", spec.SourceKey, err)
+	}
+	outputPath := ws.Path("output", "frame"+spec.Format.Extension())
+	if err := h.ff.ExtractFrame(ctx, inputPath, outputPath, spec); err != nil {
+		return err
+	}
+	key := strings.TrimPrefix(spec.ObjectKey, "/")
+	if _, err := h.storage.UploadTranscodedFile(ctx, outputPath, key, spec.Format.ContentType()); err != nil {
+		return fmt.Errorf("upload thumbnail %s: %w", key, err)
+	}
+	return nil
+}
+
+func (h *thumbnailJobHandler) Report(ctx context.Context, job *Job, err error) {
+	tj, ok := job.Payload.(*thumbnailJob)
+	if !ok {
+		return
+	}
+	if tj.done != nil {
+		// done 带缓冲，等待方已超时离开时不阻塞
+		select {
+		case tj.done <- err:
+		default:
+		}
+	}
+	if err != nil {
+		metrics.Add("thumbnails_failed_total", 1)
+		logger.Warnf("thumbnail failed job_id=%s video_uuid=%s source_key=%s at=%s error=%v", job.ID, tj.spec.VideoUUID, tj.spec.SourceKey, tj.spec.At, err)
+		return
+	}
+	metrics.Add("thumbnails_total_"+string(tj.spec.Format), 1)
+	logger.Infof("thumbnail published job_id=%s video_uuid=%s at=%s object_key=%s", job.ID, tj.spec.VideoUUID, tj.spec.At, tj.spec.ObjectKey)
+}
+
+// ExtractThumbnail 提交截帧作业并等待完成，spec.ObjectKey 须已填写。
+// ctx 结束或超过 thumbnailWaitTimeout 时返回错误，已入队的作业仍会执行完
+func ExtractThumbnail(ctx context.Context, spec vo.ThumbnailSpec) error {
+	done := make(chan error, 1)
+	job := newThumbnailJob(spec, done)
+	if err := enqueueJob(ctx, job); err != nil {
+		return err
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, thumbnailWaitTimeout)
+	defer cancel()
+	select {
+	case err := <-done:
+		return err
+	case <-waitCtx.Done():
+		return fmt.Errorf("thumbnail job %s: %w", job.ID, waitCtx.Err())
+	}
+}
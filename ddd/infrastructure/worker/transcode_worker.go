@@ -9,10 +9,16 @@ import (
 	"time"
 
 	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/port"
 	"transcode-service/ddd/domain/repo"
 	"transcode-service/ddd/domain/service"
 	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/budget"
 	"transcode-service/ddd/infrastructure/queue"
+	"transcode-service/ddd/infrastructure/storage"
+	"transcode-service/pkg/clock"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/metrics"
 )
 
 // TranscodeWorker 转码工作器接口
@@ -28,6 +34,12 @@ type TranscodeWorker interface {
 
 	// GetStats 获取工作器统计信息
 	GetStats() WorkerStats
+
+	// ActiveClaims 返回正在执行的任务认领，用于流水线快照
+	ActiveClaims() []vo.TaskClaim
+
+	// JobHandler 返回转码作业处理器，注册到作业类型表
+	JobHandler() JobHandler
 }
 
 // WorkerStats 工作器统计信息
@@ -46,21 +58,35 @@ type transcodeWorkerImpl struct {
 	taskQueue        queue.TaskQueue
 	transcodeService service.TranscodeService
 	taskRepo         repo.TranscodeJobRepository
+	handler          JobHandler
 	workerCount      int
+	expressSlots     int
+	retryInterval    time.Duration       // 检查到期 retrying 任务的周期
+	reservation      *budget.Reservation // 未开启槽位预留时为 nil
 	running          bool
 	cancel           context.CancelFunc
 	stats            WorkerStats
 	mu               sync.RWMutex
 	wg               sync.WaitGroup
+	activeMu         sync.Mutex
+	active           map[string]activeTask
 }
 
-// NewTranscodeWorker 创建转码工作器
+// activeTask 正在执行的任务及其所在协程
+type activeTask struct {
+	task      *entity.TranscodeTaskEntity
+	slot      int
+	startedAt time.Time
+}
+
+// NewTranscodeWorker 创建转码工作器；队列分道时另起 expressSlots 个只取快车道任务的专用协程
 func NewTranscodeWorker(
 	id string,
 	taskQueue queue.TaskQueue,
 	transcodeService service.TranscodeService,
 	taskRepo repo.TranscodeJobRepository,
 	workerCount int,
+	expressSlots int,
 ) TranscodeWorker {
 	if workerCount <= 0 {
 		workerCount = 1
@@ -71,13 +97,26 @@ func NewTranscodeWorker(
 		taskQueue:        taskQueue,
 		transcodeService: transcodeService,
 		taskRepo:         taskRepo,
+		handler:          &transcodeJobHandler{svc: transcodeService, repo: taskRepo},
 		workerCount:      workerCount,
+		expressSlots:     expressSlots,
+		retryInterval:    retryResumeInterval(),
+		reservation:      budget.DefaultReservation(),
+		active:           make(map[string]activeTask),
 		stats: WorkerStats{
-			StartTime: time.Now(),
+			StartTime: clock.Now(),
 		},
 	}
 }
 
+// retryResumeInterval 与存储探测周期一致，存储恢复后最迟一个周期内重试
+func retryResumeInterval() time.Duration {
+	if cfg := config.GetGlobalConfig(); cfg != nil && cfg.Worker.StorageRetry.ProbeInterval > 0 {
+		return cfg.Worker.StorageRetry.ProbeInterval
+	}
+	return 10 * time.Second
+}
+
 // Start 启动工作器
 func (w *transcodeWorkerImpl) Start(ctx context.Context) error {
 	w.mu.Lock()
@@ -91,20 +130,45 @@ func (w *transcodeWorkerImpl) Start(ctx context.Context) error {
 	workerCtx, cancel := context.WithCancel(ctx)
 	w.cancel = cancel
 	w.running = true
-	w.stats.StartTime = time.Now()
+	w.stats.StartTime = clock.Now()
 
 	log.Printf("Starting transcode worker %s with %d goroutines", w.id, w.workerCount)
 
+	// 开启槽位预留时按准入出队：低优先级任务只能占用未预留的槽位
+	dequeue := w.taskQueue.Dequeue
+	if admitting, ok := w.taskQueue.(queue.AdmittingDequeuer); ok && w.reservation != nil {
+		dequeue = func(ctx context.Context) (*entity.TranscodeTaskEntity, error) {
+			return admitting.DequeueAdmitted(ctx, w.reservation)
+		}
+		st := w.reservation.Stats()
+		log.Printf("Transcode worker %s reserves %d of %d slots for priority >= %d", w.id, st.Reserved, st.Slots, st.MinPriority)
+	}
+
 	// 启动多个工作协程
 	for i := 0; i < w.workerCount; i++ {
 		w.wg.Add(1)
-		go w.workerLoop(workerCtx, i)
+		go w.workerLoop(workerCtx, i, dequeue, false)
+	}
+	// 快车道专用协程只取 express 任务，不占共享编码槽位，长视频占满时短视频仍有并发
+	if lanes, ok := w.taskQueue.(queue.LaneDequeuer); ok {
+		for i := 0; i < w.expressSlots; i++ {
+			w.wg.Add(1)
+			dequeue := func(ctx context.Context) (*entity.TranscodeTaskEntity, error) {
+				return lanes.DequeueLane(ctx, vo.LaneExpress)
+			}
+			go w.workerLoop(workerCtx, w.workerCount+i, dequeue, true)
+		}
+		log.Printf("Transcode worker %s reserved %d express lane goroutines", w.id, w.expressSlots)
 	}
 
 	// 启动任务恢复协程 - 已禁用
 	// w.wg.Add(1)
 	// go w.taskRecoveryLoop(workerCtx)
 
+	// 到期的 retrying 任务（存储瞬时故障、ffmpeg 卡死）由独立协程恢复，不依赖上面的卡住任务回收
+	w.wg.Add(1)
+	go w.retryResumeLoop(workerCtx)
+
 	return nil
 }
 
@@ -147,8 +211,43 @@ func (w *transcodeWorkerImpl) GetStats() WorkerStats {
 	return w.stats
 }
 
-// workerLoop 工作器主循环
-func (w *transcodeWorkerImpl) workerLoop(ctx context.Context, workerID int) {
+// JobHandler 返回转码作业处理器
+func (w *transcodeWorkerImpl) JobHandler() JobHandler {
+	return w.handler
+}
+
+// ActiveClaims 返回正在执行的任务认领
+func (w *transcodeWorkerImpl) ActiveClaims() []vo.TaskClaim {
+	w.activeMu.Lock()
+	defer w.activeMu.Unlock()
+	claims := make([]vo.TaskClaim, 0, len(w.active))
+	for _, a := range w.active {
+		claims = append(claims, vo.TaskClaim{
+			TaskUUID:  a.task.TaskUUID(),
+			VideoUUID: a.task.VideoUUID(),
+			State:     vo.ClaimRunning,
+			Slot:      a.slot,
+			Progress:  a.task.Progress(),
+			StartedAt: a.startedAt,
+		})
+	}
+	return claims
+}
+
+func (w *transcodeWorkerImpl) trackActive(task *entity.TranscodeTaskEntity, slot int) {
+	w.activeMu.Lock()
+	defer w.activeMu.Unlock()
+	w.active[task.TaskUUID()] = activeTask{task: task, slot: slot, startedAt: clock.Now()}
+}
+
+func (w *transcodeWorkerImpl) untrackActive(taskUUID string) {
+	w.activeMu.Lock()
+	defer w.activeMu.Unlock()
+	delete(w.active, taskUUID)
+}
+
+// workerLoop 工作器主循环；reserved 为快车道专用协程
+func (w *transcodeWorkerImpl) workerLoop(ctx context.Context, workerID int, dequeue func(ctx context.Context) (*entity.TranscodeTaskEntity, error), reserved bool) {
 	defer w.wg.Done()
 
 	log.Printf("Worker %s-%d started", w.id, workerID)
@@ -159,8 +258,12 @@ func (w *transcodeWorkerImpl) workerLoop(ctx context.Context, workerID int) {
 		case <-ctx.Done():
 			return
 		default:
+			// 存储不可达时暂停出队，避免批量任务因下载失败而失败
+			if err := storage.DefaultHealthGate().Wait(ctx); err != nil {
+				return
+			}
 			// 从队列中获取任务
-			task, err := w.taskQueue.Dequeue(ctx)
+			task, err := dequeue(ctx)
 			if err != nil {
 				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 					return
@@ -174,14 +277,22 @@ func (w *transcodeWorkerImpl) workerLoop(ctx context.Context, workerID int) {
 				continue
 			}
 
-			// 处理任务
-			w.processTask(ctx, task, workerID)
+			// 处理任务；上传交给上传池时槽位预留在上传结束后释放
+			if !w.processTask(ctx, task, workerID, reserved) {
+				w.releaseReservation(task.TaskUUID())
+			}
 		}
 	}
 }
 
-// processTask 处理单个任务
-func (w *transcodeWorkerImpl) processTask(ctx context.Context, task *entity.TranscodeTaskEntity, workerID int) {
+func (w *transcodeWorkerImpl) releaseReservation(taskUUID string) {
+	if w.reservation != nil {
+		w.reservation.Release(taskUUID)
+	}
+}
+
+// processTask 处理单个任务；产物交给上传池时返回 true，结果统计与槽位预留释放在上传结束后进行
+func (w *transcodeWorkerImpl) processTask(ctx context.Context, task *entity.TranscodeTaskEntity, workerID int, reserved bool) (deferred bool) {
 	log.Printf("Worker %s-%d processing task %s", w.id, workerID, task.TaskUUID())
 
 	// Refresh latest state from repository to avoid stale entity after restart.
@@ -190,33 +301,52 @@ func (w *transcodeWorkerImpl) processTask(ctx context.Context, task *entity.Tran
 			task = fresh
 		}
 	}
-	if task.IsCompleted() || task.IsFailed() || task.IsCancelled() {
+	if task.IsTerminal() {
 		log.Printf("Worker %s-%d skip terminal task %s status=%s", w.id, workerID, task.TaskUUID(), task.Status().String())
-		return
+		return false
 	}
 
+	w.trackActive(task, workerID)
+	defer w.untrackActive(task.TaskUUID())
+
 	// 更新统计信息
 	w.updateStats(func(stats *WorkerStats) {
 		stats.CurrentlyRunning++
-		stats.LastTaskTime = time.Now()
+		stats.LastTaskTime = clock.Now()
 	})
+	defer w.updateStats(func(stats *WorkerStats) { stats.CurrentlyRunning-- })
+
+	// 通用作业流程执行转码；与 HLS 共享编码槽位，停机时放弃等待，任务仍为 pending 由快照恢复
+	job := newTranscodeJob(task)
+	job.Reserved = reserved
+	job.Finish = func(err error) {
+		w.finishTask(ctx, job, workerID, err)
+		w.releaseReservation(task.TaskUUID())
+	}
+	metrics.SetFloat("task_lane_"+string(task.Lane())+"_last_wait_seconds", clock.Now().Sub(task.CreatedAt()).Seconds())
+	started, err := runJob(ctx, w.handler, job)
+	if !started {
+		log.Printf("Worker %s-%d gave up waiting for encode slot task %s: %v", w.id, workerID, task.TaskUUID(), err)
+		return false
+	}
+	if errors.Is(err, port.ErrUploadDeferred) {
+		return true
+	}
+	w.finishTask(ctx, job, workerID, err)
+	return false
+}
 
-	defer func() {
-		w.updateStats(func(stats *WorkerStats) {
-			stats.CurrentlyRunning--
-			stats.ProcessedTasks++
-		})
-	}()
-
-	// 执行转码
-	err := w.transcodeService.ExecuteTranscode(ctx, task)
+// finishTask 上报并统计任务的最终结果
+func (w *transcodeWorkerImpl) finishTask(ctx context.Context, job *Job, workerID int, err error) {
+	reportJob(ctx, w.handler, job, err)
+	w.updateStats(func(stats *WorkerStats) { stats.ProcessedTasks++ })
 	if err != nil {
-		log.Printf("Worker %s-%d failed to process task %s: %v", w.id, workerID, task.TaskUUID(), err)
+		log.Printf("Worker %s-%d failed to process task %s: %v", w.id, workerID, job.ID, err)
 		w.updateStats(func(stats *WorkerStats) {
 			stats.FailedTasks++
 		})
 	} else {
-		log.Printf("Worker %s-%d successfully processed task %s", w.id, workerID, task.TaskUUID())
+		log.Printf("Worker %s-%d successfully processed task %s", w.id, workerID, job.ID)
 		w.updateStats(func(stats *WorkerStats) {
 			stats.SuccessfulTasks++
 		})
@@ -236,6 +366,24 @@ func (w *transcodeWorkerImpl) taskRecoveryLoop(ctx context.Context) {
 			return
 		case <-ticker.C:
 			w.recoverStuckTasks(ctx)
+			w.resumeDueRetries(ctx)
+		}
+	}
+}
+
+// retryResumeLoop 按 worker.storage_retry.probe_interval 恢复到期的 retrying 任务
+func (w *transcodeWorkerImpl) retryResumeLoop(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.resumeDueRetries(ctx)
 		}
 	}
 }
@@ -253,7 +401,7 @@ func (w *transcodeWorkerImpl) recoverStuckTasks(ctx context.Context) {
 	}
 
 	// 过滤出真正卡住的任务（更新时间超过1小时）
-	stuckThreshold := time.Now().Add(-time.Hour)
+	stuckThreshold := clock.Now().Add(-time.Hour)
 	for _, task := range stuckTasks {
 		if task.UpdatedAt().After(stuckThreshold) {
 			continue // 任务还在正常处理中
@@ -262,7 +410,13 @@ func (w *transcodeWorkerImpl) recoverStuckTasks(ctx context.Context) {
 		log.Printf("Worker %s recovering stuck task %s", w.id, task.TaskUUID())
 
 		// 将任务重新设置为pending状态
-		if err := w.taskRepo.UpdateTranscodeJobStatus(ctx, task.TaskUUID(), vo.TaskStatusPending, "", task.OutputPath(), 0); err != nil {
+		if err := task.TransitionTo(vo.TaskStatusPending); err != nil {
+			log.Printf("Worker %s cannot reset stuck task %s: %v", w.id, task.TaskUUID(), err)
+			continue
+		}
+		task.SetProgress(0)
+		task.SetErrorMessage("")
+		if err := w.taskRepo.SaveTranscodeJobStatus(ctx, task); err != nil {
 			log.Printf("Worker %s failed to reset stuck task %s: %v", w.id, task.TaskUUID(), err)
 			continue
 		}
@@ -277,6 +431,34 @@ func (w *transcodeWorkerImpl) recoverStuckTasks(ctx context.Context) {
 	}
 }
 
+// resumeDueRetries 存储可用时将到期的 retrying 任务恢复为 pending 并重新入队
+func (w *transcodeWorkerImpl) resumeDueRetries(ctx context.Context) {
+	if !storage.DefaultHealthGate().Healthy() {
+		return
+	}
+	due, err := w.taskRepo.QueryDueRetryTranscodeJobs(ctx, clock.Now(), 100)
+	if err != nil {
+		log.Printf("Worker %s failed to query due retries: %v", w.id, err)
+		return
+	}
+	for _, task := range due {
+		if err := task.TransitionTo(vo.TaskStatusPending); err != nil {
+			continue
+		}
+		resumed, err := w.taskRepo.ResumeRetryTranscodeJob(ctx, task)
+		if err != nil || !resumed {
+			continue
+		}
+		if err := w.taskQueue.Enqueue(ctx, task); err != nil {
+			// 已恢复为 pending，入队失败时由流水线快照在重启后恢复
+			log.Printf("Worker %s failed to re-enqueue retry task %s: %v", w.id, task.TaskUUID(), err)
+			continue
+		}
+		metrics.Add("transcode_retries_resumed_total", 1)
+		log.Printf("Worker %s resumed retry task %s attempt=%d", w.id, task.TaskUUID(), task.RetryCount())
+	}
+}
+
 // updateStats 更新统计信息
 func (w *transcodeWorkerImpl) updateStats(updateFunc func(*WorkerStats)) {
 	w.mu.Lock()
@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"transcode-service/ddd/domain/entity"
+	"transcode-service/ddd/domain/port"
+	"transcode-service/ddd/domain/repo"
+	"transcode-service/ddd/domain/vo"
+	"transcode-service/ddd/infrastructure/queue"
+	"transcode-service/pkg/clock"
+)
+
+// retryRepo 只实现恢复重试用到的方法，其余方法调用会 panic
+type retryRepo struct {
+	repo.TranscodeJobRepository
+	tasks   []*entity.TranscodeTaskEntity
+	resumed []string
+}
+
+func (r *retryRepo) QueryDueRetryTranscodeJobs(_ context.Context, now time.Time, _ int) ([]*entity.TranscodeTaskEntity, error) {
+	var due []*entity.TranscodeTaskEntity
+	for _, t := range r.tasks {
+		if t.IsRetrying() && t.NextRetryAt() != nil && !t.NextRetryAt().After(now) {
+			due = append(due, t)
+		}
+	}
+	return due, nil
+}
+
+func (r *retryRepo) ResumeRetryTranscodeJob(_ context.Context, job *entity.TranscodeTaskEntity) (bool, error) {
+	r.resumed = append(r.resumed, job.TaskUUID())
+	return true, nil
+}
+
+func retryingTask(t *testing.T, taskUUID string, nextRetryAt time.Time) *entity.TranscodeTaskEntity {
+	t.Helper()
+	task := entity.NewTranscodeTaskEntity(taskUUID, "user", "video", "in.mp4", "out.mp4")
+	if err := task.TransitionTo(vo.TaskStatusProcessing); err != nil {
+		t.Fatal(err)
+	}
+	if err := task.ScheduleRetry(nextRetryAt, "storage unavailable"); err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func TestResumeDueRetriesRequeuesDueTasks(t *testing.T) {
+	now := clock.Now()
+	due := retryingTask(t, "due", now.Add(-time.Second))
+	later := retryingTask(t, "later", now.Add(time.Hour))
+	r := &retryRepo{tasks: []*entity.TranscodeTaskEntity{due, later}}
+	q := queue.NewMemoryTaskQueue(4)
+	w := NewTranscodeWorker("test", q, nil, r, 1, 0).(*transcodeWorkerImpl)
+
+	w.resumeDueRetries(context.Background())
+
+	if len(r.resumed) != 1 || r.resumed[0] != "due" {
+		t.Fatalf("resumed = %v, want [due]", r.resumed)
+	}
+	if due.Status() != vo.TaskStatusPending {
+		t.Fatalf("due task status = %s, want pending", due.Status())
+	}
+	if later.Status() != vo.TaskStatusRetrying {
+		t.Fatalf("later task status = %s, want retrying", later.Status())
+	}
+	if q.Size() != 1 {
+		t.Fatalf("queue size = %d, want 1", q.Size())
+	}
+	got, err := q.TryDequeue(context.Background())
+	if err != nil || got.TaskUUID() != "due" {
+		t.Fatalf("dequeued %v err=%v, want due", got, err)
+	}
+}
+
+func TestRetryResumeLoopRuns(t *testing.T) {
+	due := retryingTask(t, "due", clock.Now().Add(-time.Second))
+	r := &retryRepo{tasks: []*entity.TranscodeTaskEntity{due}}
+	q := queue.NewMemoryTaskQueue(4)
+	w := NewTranscodeWorker("test", q, nil, r, 1, 0).(*transcodeWorkerImpl)
+	w.retryInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.wg.Add(1)
+	go w.retryResumeLoop(ctx)
+	deadline := time.After(2 * time.Second)
+	for q.Size() == 0 {
+		select {
+		case <-deadline:
+			cancel()
+			t.Fatal("retrying task was not re-enqueued by the resume loop")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	cancel()
+	w.wg.Wait()
+	if due.Status() != vo.TaskStatusPending {
+		t.Fatalf("status = %s, want pending", due.Status())
+	}
+}
+
+// deferredService 模拟产物交给上传池：ExecuteTranscode 立即返回，上传结果由测试回调
+type deferredService struct {
+	onUploaded func(err error)
+}
+
+func (s *deferredService) ExecuteTranscode(_ context.Context, _ *entity.TranscodeTaskEntity, onUploaded func(err error)) error {
+	s.onUploaded = onUploaded
+	return port.ErrUploadDeferred
+}
+
+func TestProcessTaskCountsDeferredUploadOnFinish(t *testing.T) {
+	svc := &deferredService{}
+	w := NewTranscodeWorker("test", queue.NewMemoryTaskQueue(1), svc, nil, 1, 0).(*transcodeWorkerImpl)
+	task := entity.NewTranscodeTaskEntity("task", "user", "video", "in.mp4", "out.mp4")
+
+	if !w.processTask(context.Background(), task, 0, true) {
+		t.Fatal("processTask should report the deferred upload")
+	}
+	if st := w.GetStats(); st.ProcessedTasks != 0 || st.SuccessfulTasks != 0 {
+		t.Fatalf("stats before upload = %+v, want nothing counted", st)
+	}
+	if svc.onUploaded == nil {
+		t.Fatal("upload callback was not passed to the service")
+	}
+	svc.onUploaded(nil)
+	if st := w.GetStats(); st.ProcessedTasks != 1 || st.SuccessfulTasks != 1 || st.FailedTasks != 0 {
+		t.Fatalf("stats after upload = %+v, want one success", st)
+	}
+}
@@ -0,0 +1,192 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"transcode-service/internal/resource"
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+const videoFenceKeyPrefix = "transcode:video_fence:"
+
+var (
+	// renewFenceScript 仅持有者可续期
+	renewFenceScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("PEXPIRE", KEYS[1], ARGV[2]) end return 0`)
+	// releaseFenceScript 仅持有者可释放，锁已过期被他人取得时不误删
+	releaseFenceScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+)
+
+// activeVideoFence runJob 执行前按视频加栅栏；mode=off 时为 nil
+var activeVideoFence atomic.Pointer[videoFence]
+
+// videoFence 同一视频的作业串行执行：local 模式只在本实例内串行，fleet 模式再通过 Redis 锁在全部实例间串行。
+// 软栅栏：等待超时或 Redis 出错时照常执行，只记录指标
+type videoFence struct {
+	cfg      config.VideoFenceConfig
+	holderID string
+	mu       sync.Mutex
+	slots    map[string]*fenceSlot
+}
+
+// fenceSlot 单个视频的本地互斥，refs 为持有与等待者数量，归零时回收
+type fenceSlot struct {
+	ch   chan struct{}
+	refs int
+}
+
+func configureVideoFence(cfg config.VideoFenceConfig, holderID string) {
+	if cfg.Mode != config.VideoFenceLocal && cfg.Mode != config.VideoFenceFleet {
+		activeVideoFence.Store(nil)
+		return
+	}
+	activeVideoFence.Store(&videoFence{cfg: cfg, holderID: holderID, slots: make(map[string]*fenceSlot)})
+	logger.Infof("video fence enabled mode=%s max_wait=%s", cfg.Mode, cfg.MaxWait)
+}
+
+// acquireVideoFence 返回的 release 必须调用；作业未关联视频或未启用时直接放行。
+// 仅在 ctx 结束（停机）时返回错误
+func acquireVideoFence(ctx context.Context, job *Job) (func(), error) {
+	f := activeVideoFence.Load()
+	if f == nil || job.VideoUUID == "" {
+		return func() {}, nil
+	}
+	return f.acquire(ctx, job)
+}
+
+func (f *videoFence) acquire(ctx context.Context, job *Job) (func(), error) {
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, f.cfg.MaxWait)
+	defer cancel()
+
+	slot := f.ref(job.VideoUUID)
+	select {
+	case slot.ch <- struct{}{}:
+	case <-waitCtx.Done():
+		f.unref(job.VideoUUID)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		f.timedOut(job, start)
+		return func() {}, nil
+	}
+	releaseLocal := func() {
+		<-slot.ch
+		f.unref(job.VideoUUID)
+	}
+
+	releaseRemote := func() {}
+	if f.cfg.Mode == config.VideoFenceFleet {
+		var err error
+		releaseRemote, err = f.lockRemote(waitCtx, job)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				releaseLocal()
+				return nil, ctxErr
+			}
+			f.timedOut(job, start)
+			releaseRemote = func() {}
+		}
+	}
+	if waited := time.Since(start); waited > time.Second {
+		metrics.Add("video_fence_waits_total", 1)
+		metrics.SetFloat("video_fence_last_wait_seconds", waited.Seconds())
+		logger.Infof("video fence acquired after wait type=%s job_id=%s video_uuid=%s waited=%s", job.Type, job.ID, job.VideoUUID, waited.Round(time.Second))
+	}
+	return func() {
+		releaseRemote()
+		releaseLocal()
+	}, nil
+}
+
+func (f *videoFence) timedOut(job *Job, start time.Time) {
+	metrics.Add("video_fence_wait_timeouts_total", 1)
+	logger.Warnf("video fence wait timed out, running anyway type=%s job_id=%s video_uuid=%s waited=%s", job.Type, job.ID, job.VideoUUID, time.Since(start).Round(time.Second))
+}
+
+func (f *videoFence) ref(videoUUID string) *fenceSlot {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.slots[videoUUID]
+	if !ok {
+		s = &fenceSlot{ch: make(chan struct{}, 1)}
+		f.slots[videoUUID] = s
+	}
+	s.refs++
+	return s
+}
+
+func (f *videoFence) unref(videoUUID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.slots[videoUUID]; ok {
+		if s.refs--; s.refs <= 0 {
+			delete(f.slots, videoUUID)
+		}
+	}
+}
+
+// lockRemote 抢占 Redis 锁直到成功或 ctx 结束；Redis 不可用时退化为本实例内串行（本地栅栏已持有）。持有期间后台续期
+func (f *videoFence) lockRemote(ctx context.Context, job *Job) (func(), error) {
+	cli := resource.DefaultRedisResource().Client()
+	if cli == nil {
+		metrics.Add("video_fence_degraded_total", 1)
+		return func() {}, nil
+	}
+	key := videoFenceKeyPrefix + job.VideoUUID
+	holder := f.holderID + "/" + job.ID
+	for {
+		ok, err := cli.SetNX(ctx, key, holder, f.cfg.LockTTL).Result()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			metrics.Add("video_fence_redis_errors_total", 1)
+			logger.Warnf("video fence redis lock failed, running without fleet fence video_uuid=%s error=%v", job.VideoUUID, err)
+			return func() {}, nil
+		}
+		if ok {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(f.cfg.RetryInterval):
+		}
+	}
+
+	renewCtx, stopRenew := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(f.cfg.LockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+				if err := renewFenceScript.Run(renewCtx, cli, []string{key}, holder, f.cfg.LockTTL.Milliseconds()).Err(); err != nil && renewCtx.Err() == nil {
+					metrics.Add("video_fence_redis_errors_total", 1)
+					logger.Warnf("video fence renew failed video_uuid=%s error=%v", job.VideoUUID, err)
+				}
+			}
+		}
+	}()
+	return func() {
+		stopRenew()
+		<-done
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := releaseFenceScript.Run(releaseCtx, cli, []string{key}, holder).Err(); err != nil {
+			// 释放失败时锁在 lock_ttl 后自动过期
+			logger.Warnf("video fence release failed video_uuid=%s error=%v", job.VideoUUID, err)
+		}
+	}, nil
+}
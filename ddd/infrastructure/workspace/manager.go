@@ -0,0 +1,220 @@
+package workspace
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+	"transcode-service/pkg/utils"
+)
+
+var (
+	managerOnce    sync.Once
+	defaultManager *Manager
+)
+
+// Metrics 工作目录清理指标
+type Metrics struct {
+	Active         int
+	Released       uint64
+	RecoveredDirs  uint64
+	ReclaimedBytes uint64
+}
+
+// Manager 管理每个作业独立的临时工作目录，保证成功/失败/取消/重启后都会被清理
+type Manager struct {
+	root           string
+	mu             sync.Mutex
+	active         map[string]*Workspace
+	released       uint64
+	recoveredDirs  uint64
+	reclaimedBytes uint64
+}
+
+// Workspace 单个作业的工作目录
+type Workspace struct {
+	id      string
+	dir     string
+	tracked []string
+	manager *Manager
+	once    sync.Once
+}
+
+// DefaultManager 基于 transcode.ffmpeg.temp_dir 的默认管理器
+func DefaultManager() *Manager {
+	managerOnce.Do(func() {
+		root := filepath.Join(os.TempDir(), "transcode")
+		if cfg := config.GetGlobalConfig(); cfg != nil && strings.TrimSpace(cfg.Transcode.FFmpeg.TempDir) != "" {
+			root = cfg.Transcode.FFmpeg.TempDir
+		}
+		defaultManager = NewManager(filepath.Join(root, "workspaces"))
+	})
+	return defaultManager
+}
+
+// NewManager 创建管理器
+func NewManager(root string) *Manager {
+	return &Manager{root: root, active: make(map[string]*Workspace)}
+}
+
+// Root 返回工作目录根路径
+func (m *Manager) Root() string {
+	return m.root
+}
+
+// Acquire 为作业创建独立工作目录，同一作业重复获取会复用已存在的目录
+func (m *Manager) Acquire(jobID string) (*Workspace, error) {
+	if strings.TrimSpace(jobID) == "" || strings.ContainsAny(jobID, `/\`) || jobID == "." || jobID == ".." {
+		return nil, fmt.Errorf("invalid workspace id: %q", jobID)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ws, ok := m.active[jobID]; ok {
+		return ws, nil
+	}
+	dir := filepath.Join(m.root, jobID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create workspace: %w", err)
+	}
+	ws := &Workspace{id: jobID, dir: dir, manager: m}
+	m.active[jobID] = ws
+	return ws, nil
+}
+
+// RecoverOrphans 启动时清理上次进程遗留的工作目录；extraRoots 为额外需要清理子目录的根（如 HLS 工作目录）。
+// 临时目录可能被多个实例共享，目录内最近一次修改距今不足 minAge 的视为其他实例仍在使用，跳过
+func (m *Manager) RecoverOrphans(minAge time.Duration, extraRoots ...string) {
+	cutoff := time.Now().Add(-minAge)
+	roots := append([]string{m.root}, extraRoots...)
+	for _, root := range roots {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			m.mu.Lock()
+			_, busy := m.active[name]
+			m.mu.Unlock()
+			if busy {
+				continue
+			}
+			path := filepath.Join(root, name)
+			size, modified := dirUsage(path)
+			if modified.After(cutoff) {
+				metrics.Add("workspace_orphans_skipped_recent_total", 1)
+				logger.Infof("skip recent workspace path=%s modified=%s", path, modified.Format(time.RFC3339))
+				continue
+			}
+			if err := os.RemoveAll(path); err != nil {
+				logger.Warnf("recover workspace failed path=%s error=%v", path, err)
+				continue
+			}
+			atomic.AddUint64(&m.recoveredDirs, 1)
+			atomic.AddUint64(&m.reclaimedBytes, uint64(size))
+			metrics.Add("workspace_orphans_recovered_total", 1)
+			metrics.Add("workspace_orphan_reclaimed_bytes_total", size)
+			logger.Infof("recovered orphan workspace path=%s bytes=%d", path, size)
+		}
+	}
+}
+
+// GetMetrics 返回清理指标
+func (m *Manager) GetMetrics() Metrics {
+	m.mu.Lock()
+	active := len(m.active)
+	m.mu.Unlock()
+	return Metrics{
+		Active:         active,
+		Released:       atomic.LoadUint64(&m.released),
+		RecoveredDirs:  atomic.LoadUint64(&m.recoveredDirs),
+		ReclaimedBytes: atomic.LoadUint64(&m.reclaimedBytes),
+	}
+}
+
+// Dir 返回工作目录
+func (w *Workspace) Dir() string {
+	return w.dir
+}
+
+// Path 返回工作目录下的路径，并确保父目录存在；拼接结果越出工作目录时（如 elem 含 ".."）
+// 改用整个相对路径的哈希文件名，保证不会写到工作目录之外
+func (w *Workspace) Path(elem ...string) string {
+	p, err := utils.SafeJoin(w.dir, elem...)
+	if err != nil {
+		metrics.Add("workspace_unsafe_paths_total", 1)
+		logger.Warnf("workspace path rejected workspace=%s error=%v", w.id, err)
+		p = filepath.Join(w.dir, utils.LocalFileName(strings.Join(elem, "/")))
+	}
+	_ = os.MkdirAll(filepath.Dir(p), 0o755)
+	return p
+}
+
+// Track 登记工作目录之外、同样需要随作业清理的路径
+func (w *Workspace) Track(path string) {
+	if strings.TrimSpace(path) == "" {
+		return
+	}
+	w.manager.mu.Lock()
+	w.tracked = append(w.tracked, path)
+	w.manager.mu.Unlock()
+}
+
+// Release 删除工作目录及登记的路径，返回回收的字节数；可重复调用
+func (w *Workspace) Release() uint64 {
+	var reclaimed uint64
+	w.once.Do(func() {
+		m := w.manager
+		m.mu.Lock()
+		paths := append([]string{w.dir}, w.tracked...)
+		delete(m.active, w.id)
+		m.mu.Unlock()
+		for _, p := range paths {
+			size := dirSize(p)
+			if err := os.RemoveAll(p); err != nil {
+				logger.Warnf("release workspace failed id=%s path=%s error=%v", w.id, p, err)
+				continue
+			}
+			reclaimed += uint64(size)
+		}
+		atomic.AddUint64(&m.released, 1)
+		atomic.AddUint64(&m.reclaimedBytes, reclaimed)
+	})
+	return reclaimed
+}
+
+func dirSize(path string) int64 {
+	size, _ := dirUsage(path)
+	return size
+}
+
+// dirUsage 目录下文件总字节数与最近一次修改时间（含目录自身）
+func dirUsage(path string) (int64, time.Time) {
+	var total int64
+	var modified time.Time
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, e := d.Info()
+		if e != nil {
+			return nil
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+		if !d.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, modified
+}
@@ -0,0 +1,55 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecoverOrphansSkipsRecentWorkspaces(t *testing.T) {
+	root := t.TempDir()
+	m := NewManager(root)
+
+	stale := filepath.Join(root, "transcode-stale")
+	recent := filepath.Join(root, "transcode-recent")
+	for _, dir := range []string{stale, recent} {
+		if err := os.MkdirAll(filepath.Join(dir, "hls"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "hls", "seg.ts"), make([]byte, 100), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	for _, p := range []string{filepath.Join(stale, "hls", "seg.ts"), filepath.Join(stale, "hls"), stale} {
+		if err := os.Chtimes(p, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 顶层目录很旧但子目录仍在写入，视为使用中
+	if err := os.Chtimes(recent, old, old); err != nil {
+		t.Fatal(err)
+	}
+	active, err := m.Acquire("transcode-active")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(active.Dir(), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	m.RecoverOrphans(time.Hour)
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("stale workspace not removed: %v", err)
+	}
+	for _, dir := range []string{recent, active.Dir()} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Fatalf("workspace %s removed: %v", dir, err)
+		}
+	}
+	if got := m.GetMetrics(); got.RecoveredDirs != 1 || got.ReclaimedBytes != 100 {
+		t.Fatalf("metrics = %+v, want 1 dir and 100 bytes", got)
+	}
+}
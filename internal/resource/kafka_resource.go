@@ -11,6 +11,9 @@ type KafkaResourcePlugin struct{}
 
 func (p *KafkaResourcePlugin) Name() string { return "kafka" }
 
+// Criticality Kafka 不可用时降级启动，仍可通过 gRPC/HTTP 创建任务
+func (p *KafkaResourcePlugin) Criticality() manager.Criticality { return manager.Optional }
+
 func (p *KafkaResourcePlugin) MustCreateResource() manager.Resource { return &KafkaResource{} }
 
 func (r *KafkaResource) MustOpen() {
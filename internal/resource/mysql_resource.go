@@ -4,6 +4,7 @@ import (
 	"sync"
 	"transcode-service/pkg/assert"
 	"transcode-service/pkg/config"
+	"transcode-service/pkg/fieldcrypt"
 	"transcode-service/pkg/manager"
 	"transcode-service/pkg/repository"
 
@@ -39,6 +40,10 @@ func (r *MySqlResource) MustOpen() {
 			panic("global config not initialized")
 		}
 
+		// 敏感字段加解密依赖密钥，配置有误时在启动阶段失败而不是首次读写时
+		if err := fieldcrypt.Configure(cfg.Encryption); err != nil {
+			panic("failed to configure field encryption: " + err.Error())
+		}
 		db, err := repository.NewDatabase(&cfg.Database)
 		if err != nil {
 			panic("failed to create database: " + err.Error())
@@ -1,13 +1,18 @@
 package resource
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 
 	"transcode-service/pkg/assert"
 	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
 	"transcode-service/pkg/manager"
+	"transcode-service/pkg/metrics"
 	"transcode-service/pkg/redisclient"
 )
 
@@ -17,8 +22,23 @@ var (
 )
 
 // RedisResource manages the lifecycle of the shared Redis client.
+// 后台按 health_check_interval 探测可用性：不可用期间 Client 返回 nil，依赖方走各自的降级路径
+// （存活上报跳过、栅栏只在本实例内生效、档位缓存按 TTL 过期）；恢复后依次执行 OnRecover 注册的重新同步
 type RedisResource struct {
-	client *redisclient.Client
+	client    *redisclient.Client
+	available atomic.Bool
+	since     atomic.Int64 // 最近一次状态切换的时间（UnixNano）
+
+	mu       sync.Mutex
+	recovers []redisRecoverHook
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type redisRecoverHook struct {
+	name string
+	fn   func(ctx context.Context)
 }
 
 // DefaultRedisResource returns the global Redis resource instance.
@@ -32,6 +52,7 @@ func DefaultRedisResource() *RedisResource {
 }
 
 // MustOpen establishes the Redis connection using global configuration.
+// 启动时连不上不再阻止启动：以降级状态运行，由健康检查在 Redis 可用后自动恢复
 func (r *RedisResource) MustOpen() {
 	if r.client != nil {
 		return
@@ -42,29 +63,118 @@ func (r *RedisResource) MustOpen() {
 		panic("global config not initialized")
 	}
 
-	client, err := redisclient.New(cfg.Redis)
+	r.client = redisclient.NewLazy(cfg.Redis)
+	r.since.Store(time.Now().UnixNano())
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Redis.DialTimeout+time.Second)
+	err := r.client.Ping(ctx)
+	cancel()
 	if err != nil {
-		panic("failed to connect redis: " + err.Error())
+		r.degrade(err)
+	} else {
+		r.available.Store(true)
+		metrics.Set("redis_available", 1)
 	}
 
-	r.client = client
+	ctx, r.cancel = context.WithCancel(context.Background())
+	r.wg.Add(1)
+	go r.monitor(ctx, cfg.Redis.HealthCheckInterval)
 }
 
 // Close tidy ups the underlying Redis client.
 func (r *RedisResource) Close() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
 	if r.client != nil {
 		_ = r.client.Close()
 	}
 }
 
-// Client exposes the raw go-redis client.
+// Client exposes the raw go-redis client; Redis 未配置或当前不可用时返回 nil
 func (r *RedisResource) Client() *redis.Client {
-	if r.client == nil {
+	if r.client == nil || !r.available.Load() {
 		return nil
 	}
 	return r.client.Raw()
 }
 
+// Available Redis 当前是否可用
+func (r *RedisResource) Available() bool {
+	return r.client != nil && r.available.Load()
+}
+
+// Since 最近一次可用/不可用状态切换的时间，未打开时为零值
+func (r *RedisResource) Since() time.Time {
+	if ns := r.since.Load(); ns > 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
+
+// OnRecover 注册 Redis 从不可用恢复后的重新同步，按注册顺序在健康检查协程中执行，不应长时间阻塞
+func (r *RedisResource) OnRecover(name string, fn func(ctx context.Context)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recovers = append(r.recovers, redisRecoverHook{name: name, fn: fn})
+}
+
+// monitor 定期 PING；降级期间 go-redis 按需重连，PING 成功即视为恢复
+func (r *RedisResource) monitor(ctx context.Context, interval time.Duration) {
+	defer r.wg.Done()
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := r.client.Ping(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case err != nil && r.available.Load():
+			r.degrade(err)
+		case err == nil && !r.available.Load():
+			r.recover(ctx)
+		}
+	}
+}
+
+func (r *RedisResource) degrade(err error) {
+	r.available.Store(false)
+	r.since.Store(time.Now().UnixNano())
+	metrics.Set("redis_available", 0)
+	metrics.Add("redis_degraded_total", 1)
+	logger.Warnf("redis unavailable, running degraded (presence skipped, video fence local only, ladder cache ttl only) error=%v", err)
+}
+
+func (r *RedisResource) recover(ctx context.Context) {
+	down := time.Since(r.Since()).Round(time.Second)
+	r.available.Store(true)
+	r.since.Store(time.Now().UnixNano())
+	metrics.Set("redis_available", 1)
+	metrics.Add("redis_recovered_total", 1)
+	logger.Infof("redis available again after %s, resyncing state", down)
+
+	r.mu.Lock()
+	hooks := append([]redisRecoverHook(nil), r.recovers...)
+	r.mu.Unlock()
+	for _, h := range hooks {
+		start := time.Now()
+		h.fn(ctx)
+		metrics.Add("redis_resyncs_total", 1)
+		logger.Infof("redis resync done name=%s took=%s", h.name, time.Since(start).Round(time.Millisecond))
+	}
+}
+
 // RedisResourcePlugin wires the resource into the manager.
 type RedisResourcePlugin struct{}
 
@@ -73,6 +183,11 @@ func (p *RedisResourcePlugin) Name() string {
 	return "redis"
 }
 
+// Criticality Redis 仅用于跨实例栅栏等增强能力，不可用时降级启动
+func (p *RedisResourcePlugin) Criticality() manager.Criticality {
+	return manager.Optional
+}
+
 // MustCreateResource returns the singleton Redis resource for registration.
 func (p *RedisResourcePlugin) MustCreateResource() manager.Resource {
 	return DefaultRedisResource()
@@ -16,9 +16,13 @@ var (
 )
 
 type RustFSResource struct {
-	endpoint string
-	access   string
-	secret   string
+	endpoint  string
+	access    string
+	secret    string
+	directPut bool
+	// 上传后校验对象大小与 MD5，不一致时重传
+	verifyChecksum  bool
+	checksumRetries int
 }
 
 func DefaultRustFSResource() *RustFSResource {
@@ -59,8 +63,11 @@ func (r *RustFSResource) MustOpen() {
 	r.endpoint = endpoint
 	r.access = access
 	r.secret = secret
+	r.directPut = cfg.RustFS.DirectPut
+	r.verifyChecksum = cfg.RustFS.VerifyChecksum
+	r.checksumRetries = cfg.RustFS.ChecksumRetries
 
-	logger.Infof("RustFS resource initialized endpoint=%s", endpoint)
+	logger.Infof("RustFS resource initialized endpoint=%s direct_put=%t verify_checksum=%t", endpoint, r.directPut, r.verifyChecksum)
 }
 
 func (r *RustFSResource) Close() {}
@@ -69,6 +76,15 @@ func (r *RustFSResource) GetEndpoint() string  { return r.endpoint }
 func (r *RustFSResource) GetAccessKey() string { return r.access }
 func (r *RustFSResource) GetSecretKey() string { return r.secret }
 
+// DirectPut 是否跳过临时键直接上传到目标键
+func (r *RustFSResource) DirectPut() bool { return r.directPut }
+
+// VerifyChecksum 上传后是否比对对象大小与 MD5
+func (r *RustFSResource) VerifyChecksum() bool { return r.verifyChecksum }
+
+// ChecksumRetries 校验不一致时的重传次数
+func (r *RustFSResource) ChecksumRetries() int { return r.checksumRetries }
+
 type RustFSResourcePlugin struct{}
 
 func (p *RustFSResourcePlugin) Name() string                         { return "rustfsResource" }
@@ -0,0 +1,102 @@
+package clock
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Clock 时间来源。实体与服务统一经 Now 取时间，测试时可替换为可控时钟验证过期、租约、退避等逻辑
+type Clock interface {
+	Now() time.Time
+}
+
+// IDGenerator 唯一 ID 生成器，默认 UUIDv4
+type IDGenerator interface {
+	NewID() string
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+type uuidGenerator struct{}
+
+func (uuidGenerator) NewID() string { return uuid.New().String() }
+
+// System 返回真实时钟
+func System() Clock { return systemClock{} }
+
+// UUIDs 返回 UUIDv4 生成器
+func UUIDs() IDGenerator { return uuidGenerator{} }
+
+type holder struct {
+	clock Clock
+	ids   IDGenerator
+}
+
+var current atomic.Pointer[holder]
+
+func init() {
+	current.Store(&holder{clock: System(), ids: UUIDs()})
+}
+
+// Install 替换进程级时钟与 ID 生成器，nil 表示保持原值；由依赖容器在启动时调用
+func Install(c Clock, ids IDGenerator) {
+	prev := current.Load()
+	next := *prev
+	if c != nil {
+		next.clock = c
+	}
+	if ids != nil {
+		next.ids = ids
+	}
+	current.Store(&next)
+}
+
+// Now 当前时间
+func Now() time.Time { return current.Load().clock.Now() }
+
+// Since 自 t 起经过的时长
+func Since(t time.Time) time.Duration { return Now().Sub(t) }
+
+// NewID 生成新的唯一 ID
+func NewID() string { return current.Load().ids.NewID() }
+
+// Manual 手动推进的时钟
+type Manual struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManual 创建停在 t 的时钟
+func NewManual(t time.Time) *Manual { return &Manual{now: t} }
+
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// Advance 向前推进 d
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	m.now = m.now.Add(d)
+	m.mu.Unlock()
+}
+
+// Sequence 按前缀与递增序号生成 ID，如 task-1、task-2
+type Sequence struct {
+	prefix string
+	n      atomic.Int64
+}
+
+// NewSequence 创建序号生成器
+func NewSequence(prefix string) *Sequence { return &Sequence{prefix: prefix} }
+
+func (s *Sequence) NewID() string {
+	return s.prefix + "-" + strconv.FormatInt(s.n.Add(1), 10)
+}
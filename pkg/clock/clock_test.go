@@ -0,0 +1,33 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInstallManualClockAndSequence(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManual(start)
+	Install(m, NewSequence("task"))
+	t.Cleanup(func() { Install(System(), UUIDs()) })
+
+	if got := Now(); !got.Equal(start) {
+		t.Fatalf("Now = %s, want %s", got, start)
+	}
+	m.Advance(90 * time.Second)
+	if got := Since(start); got != 90*time.Second {
+		t.Fatalf("Since = %s, want 90s", got)
+	}
+	if a, b := NewID(), NewID(); a != "task-1" || b != "task-2" {
+		t.Fatalf("ids = %s, %s, want task-1, task-2", a, b)
+	}
+
+	// nil 保持原值
+	Install(nil, NewSequence("job"))
+	if got := Now(); !got.Equal(start.Add(90 * time.Second)) {
+		t.Fatalf("Now after Install(nil, ids) = %s, want manual time kept", got)
+	}
+	if got := NewID(); got != "job-1" {
+		t.Fatalf("id = %s, want job-1", got)
+	}
+}
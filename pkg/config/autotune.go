@@ -0,0 +1,73 @@
+package config
+
+import (
+	"strings"
+
+	"transcode-service/pkg/sysinfo"
+)
+
+// TuningResult 根据容器资源推导出的运行参数
+type TuningResult struct {
+	Resources             sysinfo.Resources
+	Threads               int
+	MaxConcurrentTasks    int
+	HLSMaxConcurrentTasks int
+	Adjusted              bool
+}
+
+// AutoTune 按 cgroup CPU/内存限制收敛 ffmpeg 线程数与 worker 并发，避免容器内 ffmpeg 按宿主机核数超订。
+// 只会下调显式配置的并发；threads 仅在未配置（0）时推导。
+func (c *Config) AutoTune(res sysinfo.Resources) TuningResult {
+	result := TuningResult{
+		Resources:             res,
+		Threads:               c.Transcode.FFmpeg.Threads,
+		MaxConcurrentTasks:    c.Worker.MaxConcurrentTasks,
+		HLSMaxConcurrentTasks: c.Worker.HLSMaxConcurrentTasks,
+	}
+	if !c.Worker.AutoTune {
+		return result
+	}
+
+	// GPU 编码受 NVENC 会话数约束，不按 CPU 收敛并发
+	gpu := strings.Contains(strings.ToLower(c.Transcode.FFmpeg.VideoCodec), "nvenc")
+	limit := 0
+	if !gpu {
+		limit = res.EffectiveCPUs() / 2
+		if limit < 1 {
+			limit = 1
+		}
+	}
+	if res.MemoryLimit > 0 && c.Worker.TaskMemoryMB > 0 {
+		memLimit := int(res.MemoryLimit / (int64(c.Worker.TaskMemoryMB) << 20))
+		if memLimit < 1 {
+			memLimit = 1
+		}
+		if limit == 0 || memLimit < limit {
+			limit = memLimit
+		}
+	}
+	if limit > 0 {
+		if c.Worker.MaxConcurrentTasks > limit {
+			c.Worker.MaxConcurrentTasks = limit
+			result.Adjusted = true
+		}
+		if c.Worker.HLSMaxConcurrentTasks > limit {
+			c.Worker.HLSMaxConcurrentTasks = limit
+			result.Adjusted = true
+		}
+	}
+
+	if !gpu && c.Transcode.FFmpeg.Threads == 0 && res.Limited() {
+		threads := res.EffectiveCPUs() / c.Worker.MaxConcurrentTasks
+		if threads < 1 {
+			threads = 1
+		}
+		c.Transcode.FFmpeg.Threads = threads
+		result.Adjusted = true
+	}
+
+	result.Threads = c.Transcode.FFmpeg.Threads
+	result.MaxConcurrentTasks = c.Worker.MaxConcurrentTasks
+	result.HLSMaxConcurrentTasks = c.Worker.HLSMaxConcurrentTasks
+	return result
+}
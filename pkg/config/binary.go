@@ -0,0 +1,68 @@
+package config
+
+import (
+	"runtime"
+	"strings"
+
+	"transcode-service/pkg/hwaccel"
+)
+
+// Binary 返回当前架构实际使用的 ffmpeg 路径：arch_binary_paths > binary_path > "ffmpeg"
+func (f FFmpegConfig) Binary() string {
+	return resolveBinary(f.ArchBinaryPaths, f.BinaryPath, "ffmpeg")
+}
+
+// Binary 返回当前架构实际使用的 ffprobe 路径
+func (f FFprobeConfig) Binary() string {
+	return resolveBinary(f.ArchBinaryPaths, f.BinaryPath, "ffprobe")
+}
+
+func resolveBinary(byArch map[string]string, path, fallback string) string {
+	if p := strings.TrimSpace(byArch[runtime.GOARCH]); p != "" {
+		return p
+	}
+	if p := strings.TrimSpace(path); p != "" {
+		return p
+	}
+	return fallback
+}
+
+// CodecSupport 本机 ffmpeg 的编码器/滤镜/硬件加速能力
+type CodecSupport interface {
+	HasEncoder(name string) bool
+	HasFilter(name string) bool
+	HasHWAccel(name string) bool
+}
+
+// ExcludeUnsupported 按本机 ffmpeg 能力剔除无法执行的编码配置（编码器回退、关闭硬件加速链路），
+// 返回被剔除项说明；无可用视频编码器时返回 ok=false。
+func (c *Config) ExcludeUnsupported(caps CodecSupport) (excluded []string, ok bool) {
+	ff := &c.Transcode.FFmpeg
+	codec := strings.TrimSpace(ff.VideoCodec)
+	if codec == "" {
+		codec = "libx264"
+	}
+	if !caps.HasEncoder(codec) {
+		fallback := strings.TrimSpace(ff.FallbackVideoCodec)
+		if fallback == "" || !caps.HasEncoder(fallback) {
+			return append(excluded, "video_codec="+codec), false
+		}
+		excluded = append(excluded, "video_codec="+codec+" -> "+fallback)
+		ff.VideoCodec = fallback
+		codec = fallback
+	}
+
+	if b := hwaccel.Lookup(ff.HardwareAccel); b != nil {
+		// 硬件链路需要对应后端的编码器、hwaccel 与设备缩放/上传滤镜，缺失时整体回退软件解码与缩放
+		if missing := b.Missing(caps, codec, ff.UseHardwareDecode && b.Decodes()); missing != "" {
+			excluded = append(excluded, "hardware_accel="+b.Name()+" (missing "+missing+")")
+			ff.HardwareAccel = ""
+			ff.UseHardwareDecode = false
+		}
+	}
+	if !caps.HasEncoder("aac") {
+		excluded = append(excluded, "audio_codec=aac")
+		return excluded, false
+	}
+	return excluded, true
+}
@@ -2,10 +2,16 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+
+	"transcode-service/pkg/hlsname"
 )
 
 // Config 应用配置
@@ -18,6 +24,9 @@ type Config struct {
 	Log             LogConfig             `mapstructure:"log"`
 	Minio           MinioConfig           `mapstructure:"minio"`
 	RustFS          RustFSConfig          `mapstructure:"rustfs"`
+	StorageProbe    StorageProbeConfig    `mapstructure:"storage_probe"`
+	Multipart       MultipartConfig       `mapstructure:"storage_multipart"`
+	Lifecycle       LifecycleConfig       `mapstructure:"storage_lifecycle"`
 	Transcode       TranscodeConfig       `mapstructure:"transcode"`
 	Worker          WorkerConfig          `mapstructure:"worker"`
 	Scheduler       SchedulerConfig       `mapstructure:"scheduler"`
@@ -26,6 +35,230 @@ type Config struct {
 	GRPCClient      GRPCClientConfig      `mapstructure:"grpc_client"`
 	Dependencies    DependenciesConfig    `mapstructure:"dependencies"`
 	Public          PublicConfig          `mapstructure:"public"`
+	PrivateOutputs  PrivateOutputsConfig  `mapstructure:"private_outputs"`
+	OutputTargets   []OutputTarget        `mapstructure:"output_targets"`
+	TaskGroups      TaskGroupsConfig      `mapstructure:"task_groups"`
+	ReadCache       ReadCacheConfig       `mapstructure:"read_cache"`
+	Analytics       AnalyticsConfig       `mapstructure:"analytics"`
+	Notifications   NotificationsConfig   `mapstructure:"notifications"`
+	Encryption      EncryptionConfig      `mapstructure:"encryption"`
+	Shutdown        ShutdownConfig        `mapstructure:"shutdown"`
+	Benchmark       BenchmarkConfig       `mapstructure:"benchmark"`
+	Diagnostics     DiagnosticsConfig     `mapstructure:"diagnostics"`
+}
+
+// DiagnosticsConfig 长驻进程诊断：/debug/pprof 按需剖析、周期导出 GC/goroutine 等运行时指标、
+// POST /ops/v1/admin/diagnostics/captures 抓取 trace/heap 等剖析文件上传到对象存储，以及 GC 调优
+type DiagnosticsConfig struct {
+	PprofEnabled           bool             `mapstructure:"pprof_enabled"`            // 开启 /debug/pprof，默认关闭
+	Token                  string           `mapstructure:"token"`                    // /debug/pprof 访问令牌，为空时只允许本机访问
+	TokenEnv               string           `mapstructure:"token_env"`                // 优先从该环境变量读取令牌
+	RuntimeMetricsInterval time.Duration    `mapstructure:"runtime_metrics_interval"` // 运行时指标导出间隔，默认 15s，负数关闭
+	GCPercent              int              `mapstructure:"gc_percent"`               // 非 0 时覆盖 GOGC，-1 只按内存上限触发 GC
+	MemoryLimitRatio       float64          `mapstructure:"memory_limit_ratio"`       // 按容器内存上限的比例设置 Go 软内存上限，0 不设置；GOMEMLIMIT 优先
+	CapturePrefix          string           `mapstructure:"capture_prefix"`           // 剖析文件对象前缀，默认 debug/profiles
+	MaxCaptureSeconds      int              `mapstructure:"max_capture_seconds"`      // trace/cpu 单次采集上限，默认 30
+	GPU                    GPUMetricsConfig `mapstructure:"gpu"`
+}
+
+// GPUMetricsConfig GPU 指标采集：通过 nvidia-smi 读取 NVML 计数器，写入心跳与 /metrics；
+// 本机没有 nvidia-smi 时自动跳过
+type GPUMetricsConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"` // 采集间隔，默认 15s
+	Binary   string        `mapstructure:"binary"`   // 默认 nvidia-smi
+}
+
+// ResolvedToken 优先取环境变量中的令牌
+func (c DiagnosticsConfig) ResolvedToken() string {
+	if c.TokenEnv != "" {
+		if v := os.Getenv(c.TokenEnv); v != "" {
+			return v
+		}
+	}
+	return c.Token
+}
+
+// BenchmarkConfig 压测模式：POST /ops/v1/admin/benchmark 触发，按速率经完整流水线提交合成源文件任务，
+// 请求中省略的参数取此处的值；压测任务的结果不通知上游
+type BenchmarkConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Resolutions   []string      `mapstructure:"resolutions"`     // 合成源文件分辨率，轮流提交，默认 480p/720p/1080p
+	SourceSeconds int           `mapstructure:"source_seconds"`  // 合成源文件时长，默认 30
+	RatePerMinute float64       `mapstructure:"rate_per_minute"` // 每分钟提交任务数，默认 6
+	Duration      time.Duration `mapstructure:"duration"`        // 提交持续时长，默认 10m
+	MaxDuration   time.Duration `mapstructure:"max_duration"`    // 请求可指定的最长持续时长，默认 2h
+	DrainTimeout  time.Duration `mapstructure:"drain_timeout"`   // 停止提交后等待任务结束的上限，默认 15m
+	UserUUID      string        `mapstructure:"user_uuid"`       // 压测任务的 user_uuid，默认 benchmark
+}
+
+// ShutdownConfig 停机各阶段超时：停止接入 → 排空编码 → 写出缓冲 → 关闭资源，
+// 阶段超时后记录仍在进行的工作并进入下一阶段
+type ShutdownConfig struct {
+	IngestTimeout    time.Duration `mapstructure:"ingest_timeout"`    // HTTP/gRPC 停止接受请求、Kafka 消费者离开消费组，默认 15s
+	DrainTimeout     time.Duration `mapstructure:"drain_timeout"`     // worker 完成手上任务、关闭本地队列，默认 60s
+	FlushTimeout     time.Duration `mapstructure:"flush_timeout"`     // 上传池、分配记录、告警与分析导出写出，默认 30s
+	ResourcesTimeout time.Duration `mapstructure:"resources_timeout"` // 关闭数据库、Redis、Kafka 等资源，默认 10s
+}
+
+// EncryptionConfig 敏感字段落库加密（AES-256-GCM）：任务标签、任务元数据与任务备注。
+// 轮换密钥时新增密钥并改 primary_key_id，旧密钥保留到 /ops/v1/admin/encryption/rotate 重加密完成
+type EncryptionConfig struct {
+	Enabled         bool                  `mapstructure:"enabled"`
+	PrimaryKeyID    string                `mapstructure:"primary_key_id"`    // 新写入使用的密钥，为空时取 keys 的第一个
+	Keys            []EncryptionKeyConfig `mapstructure:"keys"`              // 全部可用于解密的密钥
+	RotateBatchSize int                   `mapstructure:"rotate_batch_size"` // 重加密每批行数，默认 200
+}
+
+// EncryptionKeyConfig 单个数据密钥，secret 为 base64 编码的 32 字节密钥。
+// 由 KMS 注入时配置 secret_env，从该环境变量读取，优先于 secret
+type EncryptionKeyConfig struct {
+	ID        string `mapstructure:"id"`
+	Secret    string `mapstructure:"secret"`
+	SecretEnv string `mapstructure:"secret_env"`
+}
+
+// TaskLabelsConfig 任务标签的指标分组配置
+type TaskLabelsConfig struct {
+	MetricKeys      []string `mapstructure:"metric_keys"`       // 参与指标分组的标签 key，未列出的 key 只用于筛选
+	MaxMetricValues int      `mapstructure:"max_metric_values"` // 每个 key 最多统计的取值数，超出归入 other，默认 20
+}
+
+// PreviewConfig 预览模式：只转码前 N 秒并发布到 preview 前缀，用于快速确认画质参数
+type PreviewConfig struct {
+	DefaultSeconds int `mapstructure:"default_seconds"` // 请求未指定时长时使用，默认 10
+	MaxSeconds     int `mapstructure:"max_seconds"`     // 允许的最大预览时长，默认 60
+}
+
+// ReplayConfig 任务重放：按覆盖参数克隆已结束任务，产物发布到 replay 前缀，用于排查画质问题
+type ReplayConfig struct {
+	MaxPerMinute int `mapstructure:"max_per_minute"` // 每个用户（原任务所属）每分钟最多重放次数，默认 5
+}
+
+// 告警事件
+const (
+	AlertTaskFailureSpike = "task_failure_spike" // 窗口内失败作业数超过阈值
+	AlertDLQGrowth        = "dlq_growth"         // 窗口内写入死信主题的消息数超过阈值
+	AlertSLABreach        = "sla_breach"         // 窗口内超过截止时间的任务数超过阈值
+)
+
+// NotificationsConfig 告警通知：周期性检查失败激增、死信增长与 SLA 超时，按事件与严重级别路由到各通道
+type NotificationsConfig struct {
+	Enabled       bool                        `mapstructure:"enabled"`
+	CheckInterval time.Duration               `mapstructure:"check_interval"` // 检查周期，默认 30s
+	Cooldown      time.Duration               `mapstructure:"cooldown"`       // 同一通道同一事件的最小通知间隔，默认 10m
+	Events        map[string]AlertEventConfig `mapstructure:"events"`         // 事件名 -> 阈值配置
+	Channels      []NotificationChannel       `mapstructure:"channels"`
+}
+
+// AlertEventConfig 单个告警事件：window 内计数增量达到 threshold 时以 severity 级别通知
+type AlertEventConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Severity  string        `mapstructure:"severity"` // info | warning | critical
+	Threshold int64         `mapstructure:"threshold"`
+	Window    time.Duration `mapstructure:"window"`
+	// Template 消息正文（text/template），可用 .Event .Severity .Count .Threshold .Window .Instance
+	Template string `mapstructure:"template"`
+}
+
+// NotificationChannel 通知通道；severities/events 为空表示接收全部
+type NotificationChannel struct {
+	Name       string        `mapstructure:"name"`
+	Type       string        `mapstructure:"type"` // slack | email | pagerduty | webhook
+	Severities []string      `mapstructure:"severities"`
+	Events     []string      `mapstructure:"events"`
+	Timeout    time.Duration `mapstructure:"timeout"` // 单次发送超时，默认 10s
+	// slack / webhook
+	WebhookURL string `mapstructure:"webhook_url"`
+	// pagerduty Events API v2
+	RoutingKey string `mapstructure:"routing_key"`
+	// email
+	SMTPAddr string   `mapstructure:"smtp_addr"` // host:port
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+}
+
+// defaultAlertEvents 未配置的告警事件按此启用；已配置事件的零值字段同样按此补齐
+var defaultAlertEvents = map[string]AlertEventConfig{
+	AlertTaskFailureSpike: {Enabled: true, Severity: "critical", Threshold: 20, Window: 5 * time.Minute},
+	AlertDLQGrowth:        {Enabled: true, Severity: "warning", Threshold: 10, Window: 10 * time.Minute},
+	AlertSLABreach:        {Enabled: true, Severity: "critical", Threshold: 1, Window: 5 * time.Minute},
+}
+
+func (c *Config) normalizeNotifications() {
+	n := &c.Notifications
+	if n.CheckInterval <= 0 {
+		n.CheckInterval = 30 * time.Second
+	}
+	if n.Cooldown <= 0 {
+		n.Cooldown = 10 * time.Minute
+	}
+	if n.Events == nil {
+		n.Events = make(map[string]AlertEventConfig, len(defaultAlertEvents))
+	}
+	for name, def := range defaultAlertEvents {
+		ev, ok := n.Events[name]
+		if !ok {
+			n.Events[name] = def
+			continue
+		}
+		if ev.Severity == "" {
+			ev.Severity = def.Severity
+		}
+		if ev.Threshold <= 0 {
+			ev.Threshold = def.Threshold
+		}
+		if ev.Window <= 0 {
+			ev.Window = def.Window
+		}
+		n.Events[name] = ev
+	}
+	for i := range n.Channels {
+		if n.Channels[i].Timeout <= 0 {
+			n.Channels[i].Timeout = 10 * time.Second
+		}
+	}
+}
+
+// AnalyticsConfig 已结束任务周期性导出到分析仓库，数据团队无需访问生产库
+type AnalyticsConfig struct {
+	Enabled            bool                       `mapstructure:"enabled"`
+	Interval           time.Duration              `mapstructure:"interval"`             // 导出周期，默认 5m
+	BatchSize          int                        `mapstructure:"batch_size"`           // 单批最多记录数，默认 500
+	Backfill           time.Duration              `mapstructure:"backfill"`             // 首次导出回溯时长，默认 24h
+	IncludeObjectSizes bool                       `mapstructure:"include_object_sizes"` // 是否查询源/产物对象大小（每条记录两次 HEAD）
+	Sink               string                     `mapstructure:"sink"`                 // storage | http | kafka
+	Storage            AnalyticsStorageSinkConfig `mapstructure:"storage"`
+	HTTP               AnalyticsHTTPSinkConfig    `mapstructure:"http"`
+	Kafka              AnalyticsKafkaSinkConfig   `mapstructure:"kafka"`
+}
+
+// AnalyticsStorageSinkConfig 写入对象存储（S3 兼容），按日期分区
+type AnalyticsStorageSinkConfig struct {
+	Prefix string `mapstructure:"prefix"` // 默认 analytics
+	Format string `mapstructure:"format"` // csv | jsonl
+}
+
+// AnalyticsHTTPSinkConfig 以 BigQuery insertAll 兼容格式 POST 到 HTTP 端点
+type AnalyticsHTTPSinkConfig struct {
+	URL     string        `mapstructure:"url"`
+	Token   string        `mapstructure:"token"` // 非空时作为 Bearer Token
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// AnalyticsKafkaSinkConfig 写入 Kafka 分析主题，每条记录一条消息
+type AnalyticsKafkaSinkConfig struct {
+	Topic string `mapstructure:"topic"`
+}
+
+// ReadCacheConfig 热点读接口（任务详情/进度）的进程内短 TTL 缓存；本实例写入时立即失效，
+// 其他实例的写入最多延迟 TTL 可见
+type ReadCacheConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	TTL        time.Duration `mapstructure:"ttl"`
+	MaxEntries int           `mapstructure:"max_entries"`
 }
 
 // ServerConfig 服务器配置
@@ -35,6 +268,8 @@ type ServerConfig struct {
 	Mode         string        `mapstructure:"mode"`
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// LegacyTimeFormat API 时间戳沿用旧格式（服务器本地时区、纳秒精度），默认输出 RFC3339 UTC
+	LegacyTimeFormat bool `mapstructure:"legacy_time_format"`
 }
 
 // DatabaseConfig 数据库配置
@@ -64,6 +299,8 @@ type RedisConfig struct {
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	EnableTLS    bool          `mapstructure:"enable_tls"`
+	// HealthCheckInterval 健康检查间隔，Redis 不可用期间依赖方降级运行，恢复后自动重新同步，默认 5s
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"`
 }
 
 // ServiceRegistryConfig registration configuration.
@@ -80,6 +317,29 @@ type ServiceRegistryConfig struct {
 type GRPCServerConfig struct {
 	Host string `mapstructure:"host"`
 	Port int    `mapstructure:"port"`
+	// AuthTokens 调用方需在 metadata authorization: Bearer <token> 中携带其中之一，为空时不校验
+	AuthTokens []string `mapstructure:"auth_tokens"`
+	// AuthTokensEnv 从该环境变量读取逗号分隔的令牌，与 AuthTokens 合并
+	AuthTokensEnv string `mapstructure:"auth_tokens_env"`
+	// RequireAuth 为 true 时没有任何令牌则拒绝启动
+	RequireAuth    bool `mapstructure:"require_auth"`
+	MaxRecvMsgSize int  `mapstructure:"max_recv_msg_size"`
+	MaxSendMsgSize int  `mapstructure:"max_send_msg_size"`
+}
+
+// ResolvedAuthTokens 合并配置与环境变量中的令牌，去掉空白项
+func (c GRPCServerConfig) ResolvedAuthTokens() []string {
+	raw := append([]string(nil), c.AuthTokens...)
+	if c.AuthTokensEnv != "" {
+		raw = append(raw, strings.Split(os.Getenv(c.AuthTokensEnv), ",")...)
+	}
+	tokens := make([]string, 0, len(raw))
+	for _, t := range raw {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
 }
 
 // GRPCClientConfig defines outbound gRPC client behaviour.
@@ -88,26 +348,53 @@ type GRPCClientConfig struct {
 	MaxRecvMsgSize int           `mapstructure:"max_recv_msg_size"`
 	MaxSendMsgSize int           `mapstructure:"max_send_msg_size"`
 	RetryTimes     int           `mapstructure:"retry_times"`
+	// HealthCheckInterval 多实例下游的健康检查周期
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"`
 }
 
 // DependenciesConfig enumerates downstream services used by transcode-service.
 type DependenciesConfig struct {
 	UploadService UploadServiceConfig `mapstructure:"upload_service"`
 	VideoService  VideoServiceConfig  `mapstructure:"video_service"`
+	KeyProvider   KeyProviderConfig   `mapstructure:"key_provider"`
+}
+
+// KeyProviderConfig 源文件数据密钥服务：upload-service 静态加密的源文件下载后按头部中的密钥 ID 取数据密钥解密。
+// 未开启时加密源直接失败，不会把密文交给 ffmpeg
+type KeyProviderConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	ServiceName string        `mapstructure:"service_name"`
+	Address     string        `mapstructure:"address"`
+	Addresses   []string      `mapstructure:"addresses"`
+	Host        string        `mapstructure:"host"`
+	Port        int           `mapstructure:"port"`
+	Timeout     time.Duration `mapstructure:"timeout"`
 }
 
 // UploadServiceConfig describes upload-service discovery metadata.
 type UploadServiceConfig struct {
 	ServiceName string        `mapstructure:"service_name"`
 	Address     string        `mapstructure:"address"`
+	Addresses   []string      `mapstructure:"addresses"`
 	Host        string        `mapstructure:"host"`
 	Port        int           `mapstructure:"port"`
 	Timeout     time.Duration `mapstructure:"timeout"`
+	// Milestones 处理中里程碑推送，供 upload-service SSE 展示进度
+	Milestones MilestoneConfig `mapstructure:"milestones"`
+}
+
+// MilestoneConfig 里程碑推送：转码进度越过 progress 中的百分比、HLS 开始切片时各通知一次（非终态，不做幂等去重）
+type MilestoneConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Progress   []int         `mapstructure:"progress"`    // 进度里程碑（1-99），默认 25/50/75
+	HLSStarted bool          `mapstructure:"hls_started"` // 是否推送 HLS 开始切片，默认 true
+	Timeout    time.Duration `mapstructure:"timeout"`     // 单次推送超时，默认 5s
 }
 
 type VideoServiceConfig struct {
 	ServiceName string        `mapstructure:"service_name"`
 	Address     string        `mapstructure:"address"`
+	Addresses   []string      `mapstructure:"addresses"`
 	Host        string        `mapstructure:"host"`
 	Port        int           `mapstructure:"port"`
 	Timeout     time.Duration `mapstructure:"timeout"`
@@ -130,27 +417,420 @@ type RustFSConfig struct {
 	AccessKey string `mapstructure:"access_key"`
 	SecretKey string `mapstructure:"secret_key"`
 	UseSSL    bool   `mapstructure:"use_ssl"`
+	// DirectPut 直接 PUT 到目标键；默认先上传临时键、校验后服务端复制，产物只在完整时可见
+	DirectPut bool `mapstructure:"direct_put"`
+	// VerifyChecksum 上传携带 Content-MD5，上传后 HEAD 比对大小与 ETag(MD5)，不一致时重传
+	VerifyChecksum  bool `mapstructure:"verify_checksum"`
+	ChecksumRetries int  `mapstructure:"checksum_retries"` // 校验不一致时的重传次数，默认 2，0 为不重传
+}
+
+// MultipartConfig 大文件分段上传（RustFS 与 MinIO 共用）：分段并发上传、单段失败重试，
+// 未完成的上传会话记录在本地文件旁，重试上传同一文件时跳过已上传的分段
+type MultipartConfig struct {
+	Enabled     bool  `mapstructure:"enabled"`
+	Threshold   int64 `mapstructure:"threshold"`    // 不小于该字节数的文件分段上传，默认 128MB
+	PartSize    int64 `mapstructure:"part_size"`    // 分段字节数，默认 16MB，最小 5MB（S3 限制）
+	Parallelism int   `mapstructure:"parallelism"`  // 并发上传的分段数，默认 4
+	PartRetries int   `mapstructure:"part_retries"` // 单个分段遇到存储瞬时故障时的重试次数，默认 3
+}
+
+// StorageProbeConfig 存储健康探测：定时 HEAD 各目标的探针对象并计时，结果用于 /readyz、指标与出队闸门
+type StorageProbeConfig struct {
+	Enabled          bool                 `mapstructure:"enabled"`
+	Interval         time.Duration        `mapstructure:"interval"`          // 探测周期，默认 30s
+	Timeout          time.Duration        `mapstructure:"timeout"`           // 单次探测超时，默认 5s
+	SlowThreshold    time.Duration        `mapstructure:"slow_threshold"`    // 延迟超过时记为 degraded，默认 2s
+	FailureThreshold int                  `mapstructure:"failure_threshold"` // 连续不可达次数达到后记为 down，默认 2
+	Targets          []StorageProbeTarget `mapstructure:"targets"`           // 为空时探测 uploads 与 transcode 两个桶
+}
+
+// LifecycleConfig 产物分层存储：长期未访问的 MP4 产物迁移到归档前缀（可指定存储类别），按需恢复
+type LifecycleConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	ColdAfter    time.Duration `mapstructure:"cold_after"`    // 最近访问（无 CDN 访问记录时取完成时间）早于该时长的产物归档，默认 90 天
+	Interval     time.Duration `mapstructure:"interval"`      // 归档与恢复的检查周期，默认 1h
+	BatchSize    int           `mapstructure:"batch_size"`    // 每轮最多归档的产物数，默认 100
+	ArchiveDir   string        `mapstructure:"archive_dir"`   // 归档前缀，插入对象 key 的首级目录之后，默认 .archive
+	StorageClass string        `mapstructure:"storage_class"` // 复制到归档前缀时设置的存储类别（如 GLACIER_IR），为空时由桶生命周期规则按前缀转换
+}
+
+// StorageProbeTarget 单个探测目标（存储后端/发布目的地）
+type StorageProbeTarget struct {
+	Name      string `mapstructure:"name"`
+	CanaryKey string `mapstructure:"canary_key"` // 探针对象 key，按前缀路由到对应桶；对象不存在时视为可达
+	Gate      bool   `mapstructure:"gate"`       // down 时关闭出队闸门
+	Optional  bool   `mapstructure:"optional"`   // 为 true 时不影响 /readyz
+}
+
+// PrivateOutputsConfig 私有产物：visibility=private 的任务上传到 private/<token>/ 前缀（RustFS 为 transcode-private 桶），
+// 回调与查询只返回经网关鉴权的签名 URL <gateway_base>/<expires>/<signature>/<key>
+type PrivateOutputsConfig struct {
+	// DefaultVisibility 请求未指定 visibility 时使用，默认 public
+	DefaultVisibility string `mapstructure:"default_visibility"`
+	// KeySecret 派生对象 key 中租户令牌的密钥，轮换后只影响新任务；未配置时拒绝 private 任务
+	KeySecret    string `mapstructure:"key_secret"`
+	KeySecretEnv string `mapstructure:"key_secret_env"`
+	// GatewayBase 签名网关地址，默认 {public.storage_base}/signed
+	GatewayBase string `mapstructure:"gateway_base"`
+	// SignSecret 签名密钥，网关用同一密钥校验；未配置时拒绝 private 任务
+	SignSecret    string        `mapstructure:"sign_secret"`
+	SignSecretEnv string        `mapstructure:"sign_secret_env"`
+	SignTTL       time.Duration `mapstructure:"sign_ttl"` // 签名有效期，默认 1h
+}
+
+// OutputTarget 请求可通过 output_bucket/output_prefix 指定的产物目标桶（白名单）
+type OutputTarget struct {
+	Bucket      string   `mapstructure:"bucket"`
+	Prefixes    []string `mapstructure:"prefixes"`     // 允许的前缀（含其子目录），为空时只允许不带前缀
+	URLTemplate string   `mapstructure:"url_template"` // 对外 URL 模板，支持 {base}/{bucket}/{key}，默认 {base}/storage/{bucket}/{key}
+}
+
+// AllowsPrefix 前缀等于某个允许的前缀或位于其子目录
+func (t OutputTarget) AllowsPrefix(prefix string) bool {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return len(t.Prefixes) == 0
+	}
+	for _, p := range t.Prefixes {
+		p = strings.Trim(p, "/")
+		if p == "" || prefix == p || strings.HasPrefix(prefix, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskGroupsConfig 任务组：组内任务全部结束后向创建时指定的 callback_url POST 一次完成回调
+type TaskGroupsConfig struct {
+	MaxTasks            int           `mapstructure:"max_tasks"`             // 单组最多任务数，默认 500
+	CheckInterval       time.Duration `mapstructure:"check_interval"`        // 完成检查与回调重试间隔，默认 30s
+	CallbackTimeout     time.Duration `mapstructure:"callback_timeout"`      // 单次回调超时，默认 10s
+	CallbackMaxAttempts int           `mapstructure:"callback_max_attempts"` // 回调最多尝试次数，默认 5
+	CallbackSecret      string        `mapstructure:"callback_secret"`       // 非空时以 HMAC-SHA256 签名请求体
+	CallbackSecretEnv   string        `mapstructure:"callback_secret_env"`
+	AllowedHosts        []string      `mapstructure:"allowed_hosts"` // 非空时回调地址的主机须在列表内
+}
+
+// ResolvedCallbackSecret 优先取环境变量中的密钥
+func (c TaskGroupsConfig) ResolvedCallbackSecret() string {
+	if c.CallbackSecretEnv != "" {
+		if v := os.Getenv(c.CallbackSecretEnv); v != "" {
+			return v
+		}
+	}
+	return c.CallbackSecret
+}
+
+// AllowsHost 未配置 allowed_hosts 时允许任意主机
+func (c TaskGroupsConfig) AllowsHost(host string) bool {
+	if len(c.AllowedHosts) == 0 {
+		return true
+	}
+	for _, h := range c.AllowedHosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindOutputTarget 按桶名查找白名单
+func (c *Config) FindOutputTarget(bucket string) (OutputTarget, bool) {
+	for _, t := range c.OutputTargets {
+		if t.Bucket == bucket {
+			return t, true
+		}
+	}
+	return OutputTarget{}, false
+}
+
+// ResolvedKeySecret 优先取环境变量中的密钥
+func (c PrivateOutputsConfig) ResolvedKeySecret() string {
+	if c.KeySecretEnv != "" {
+		if v := os.Getenv(c.KeySecretEnv); v != "" {
+			return v
+		}
+	}
+	return c.KeySecret
+}
+
+// ResolvedSignSecret 优先取环境变量中的密钥
+func (c PrivateOutputsConfig) ResolvedSignSecret() string {
+	if c.SignSecretEnv != "" {
+		if v := os.Getenv(c.SignSecretEnv); v != "" {
+			return v
+		}
+	}
+	return c.SignSecret
 }
 
 // PublicConfig 对外访问配置
 type PublicConfig struct {
 	StorageBase string `mapstructure:"storage_base"`
+	// Fallback storage_base 为空时引用 {base} 的 URL 如何生成：endpoint 由 rustfs.endpoint 推导直连地址（默认），
+	// presign 生成存储端 SigV4 预签名 URL，relative 保持历史的相对路径
+	Fallback   string        `mapstructure:"fallback"`
+	PresignTTL time.Duration `mapstructure:"presign_ttl"` // presign 有效期，默认 24h，最长 7 天
+	// URLRules 按对象 key 前缀选择 URL 模板（最长前缀优先），均不匹配时为 {base}/storage/transcode/{key}
+	URLRules []PublicURLRule `mapstructure:"url_rules"`
+	// WatchConfig 配置文件变更时热更新 public 段，无需重启
+	WatchConfig bool `mapstructure:"watch_config"`
+}
+
+// 对外 URL 兜底方式
+const (
+	PublicFallbackEndpoint = "endpoint"
+	PublicFallbackPresign  = "presign"
+	PublicFallbackRelative = "relative"
+)
+
+// ValidFallback 空值视为 endpoint
+func (c PublicConfig) ValidFallback() bool {
+	switch c.Fallback {
+	case "", PublicFallbackEndpoint, PublicFallbackPresign, PublicFallbackRelative:
+		return true
+	}
+	return false
+}
+
+// PublicURLWarnings 启动时检查对外 URL 配置：storage_base 与签名都未配置时下游拿到的地址可能无法解析。
+// release 环境下由存储端点推导的直连地址通常只在集群内可达，同样提示
+func (c *Config) PublicURLWarnings() []string {
+	var warnings []string
+	if strings.TrimSpace(c.Public.StorageBase) == "" {
+		switch c.Public.Fallback {
+		case PublicFallbackRelative:
+			warnings = append(warnings, "public.storage_base is empty and public.fallback=relative, output urls are relative paths")
+		case PublicFallbackEndpoint:
+			if c.Server.Mode == "release" {
+				warnings = append(warnings, fmt.Sprintf("public.storage_base is empty, output urls are derived from storage endpoint %s and may be unreachable outside the cluster", c.RustFS.Endpoint))
+			}
+		}
+	}
+	if c.PrivateOutputs.ResolvedKeySecret() != "" && strings.TrimSpace(c.PrivateOutputs.GatewayBase) == "" && strings.TrimSpace(c.Public.StorageBase) == "" {
+		warnings = append(warnings, "private_outputs.gateway_base and public.storage_base are both empty, private output urls are relative paths")
+	}
+	return warnings
+}
+
+// PublicURLRule 产物对外 URL 模板。模板占位符：{base} storage_base，{bucket} 桶名，{key} 去掉桶名前缀并转义后的对象 key
+type PublicURLRule struct {
+	Prefix   string `mapstructure:"prefix"`   // 对象 key 前缀，如 hls/、transcoded/；空串匹配全部
+	Bucket   string `mapstructure:"bucket"`   // 缺省 transcode
+	Template string `mapstructure:"template"` // 如 https://cdn.example.com/{key}，缺省 {base}/storage/{bucket}/{key}
+	// SignSecret 非空时追加 expires/signature 查询参数（HMAC-SHA256(secret, path + expires)），用于 CDN 鉴权
+	SignSecret string        `mapstructure:"sign_secret"`
+	SignTTL    time.Duration `mapstructure:"sign_ttl"` // 签名有效期，默认 24h
 }
 
 // TranscodeConfig 转码配置
 type TranscodeConfig struct {
-	FFmpeg         FFmpegConfig   `mapstructure:"ffmpeg"`
-	OutputFormats  []OutputFormat `mapstructure:"output_formats"`
-	SkipFullUpload bool           `mapstructure:"skip_full_upload"`
+	FFmpeg        FFmpegConfig   `mapstructure:"ffmpeg"`
+	FFprobe       FFprobeConfig  `mapstructure:"ffprobe"`
+	OutputFormats []OutputFormat `mapstructure:"output_formats"`
+	// FormatSet 当前环境生效的档位集合名（可用 GO_VIDEO_TRANSCODE_FORMAT_SET 覆盖），为空时使用 output_formats
+	FormatSet      string                     `mapstructure:"format_set"`
+	FormatSets     map[string]FormatSetConfig `mapstructure:"format_sets"`
+	SkipFullUpload bool                       `mapstructure:"skip_full_upload"`
+	HLS            HLSPathConfig              `mapstructure:"hls"`
+	SourceCache    SourceCacheConfig          `mapstructure:"source_cache"`
+	Labels         TaskLabelsConfig           `mapstructure:"labels"`
+	Preview        PreviewConfig              `mapstructure:"preview"`
+	AutoProfile    AutoProfileConfig          `mapstructure:"auto_profile"`
+	Replay         ReplayConfig               `mapstructure:"replay"`
+	LadderCache    LadderCacheConfig          `mapstructure:"ladder_cache"`
+	InputLimits    InputLimitsConfig          `mapstructure:"input_limits"`
+	BitrateCap     BitrateCapConfig           `mapstructure:"bitrate_cap"`
+	SourceStreams  SourceStreamsConfig        `mapstructure:"source_streams"`
+	Samples        ResourceSamplesConfig      `mapstructure:"resource_samples"`
+	// SettingsVersion 编码设置版本，随产物指纹记录；修改编码参数或升级 ffmpeg 后递增，回填按版本筛选旧产物，缺省 1
+	SettingsVersion int `mapstructure:"settings_version"`
+	// CancelCheckInterval 处理中任务轮询取消状态的间隔（源文件被新代数取代或人工取消时中止 ffmpeg），缺省 10s
+	CancelCheckInterval time.Duration `mapstructure:"cancel_check_interval"`
+}
+
+// LadderCacheConfig 输出档位、档位集合与自动档位规则的进程内缓存；
+// 通过 /ops/v1/admin/ladders/invalidate 或 Redis 频道通知各实例立即失效
+type LadderCacheConfig struct {
+	TTL     time.Duration `mapstructure:"ttl"`     // 缓存有效期，默认 60s
+	Channel string        `mapstructure:"channel"` // 失效通知的 Redis 频道，默认 transcode:ladders:invalidate
+}
+
+// ResourceSamplesConfig 编码期间按间隔采样 ffmpeg 进程的 CPU/RSS/GPU 与 fps/speed，记录为任务的时间序列
+type ResourceSamplesConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"` // 采样间隔，默认 5s
+	// MaxSamples 单个任务保留的样本上限，超过后合并相邻样本，默认 720
+	MaxSamples int `mapstructure:"max_samples"`
+	// FlushEvery 每采样 N 次持久化一次，运行中即可查询，默认 6
+	FlushEvery int `mapstructure:"flush_every"`
+}
+
+// SourceStreamsConfig 缺失音频或视频的源文件的处理方式
+type SourceStreamsConfig struct {
+	// MissingAudio 无音频源：silent 补静音音轨（默认，播放器与 HLS 各档音轨一致），none 输出无音轨视频
+	MissingAudio string `mapstructure:"missing_audio"`
+	// AudioOnly 纯音频源：audio 输出只含音频的产物且不生成 HLS（默认），reject 以 rejected 结束
+	AudioOnly string `mapstructure:"audio_only"`
+}
+
+// InputLimitsConfig 输入文件上限，探测阶段校验，超限任务以 rejected 结束并通知上游；0 表示不限制。
+// 分辨率按长边/短边比较，竖屏输入不会因宽高互换被误拒
+type InputLimitsConfig struct {
+	MaxDuration time.Duration `mapstructure:"max_duration"`
+	MaxFileSize int64         `mapstructure:"max_file_size"` // 字节
+	MaxWidth    int           `mapstructure:"max_width"`
+	MaxHeight   int           `mapstructure:"max_height"`
+	// Tenants 按 user_uuid 覆盖，非 0 字段生效，负数表示该租户不限制
+	Tenants map[string]InputLimitsConfig `mapstructure:"tenants"`
+}
+
+// For 返回租户生效的上限，负数已归一为 0（不限制）
+func (l InputLimitsConfig) For(userUUID string) InputLimitsConfig {
+	eff := InputLimitsConfig{MaxDuration: l.MaxDuration, MaxFileSize: l.MaxFileSize, MaxWidth: l.MaxWidth, MaxHeight: l.MaxHeight}
+	if o, ok := l.Tenants[strings.ToLower(userUUID)]; ok {
+		if o.MaxDuration != 0 {
+			eff.MaxDuration = o.MaxDuration
+		}
+		if o.MaxFileSize != 0 {
+			eff.MaxFileSize = o.MaxFileSize
+		}
+		if o.MaxWidth != 0 {
+			eff.MaxWidth = o.MaxWidth
+		}
+		if o.MaxHeight != 0 {
+			eff.MaxHeight = o.MaxHeight
+		}
+	}
+	eff.MaxDuration = max(eff.MaxDuration, 0)
+	eff.MaxFileSize = max(eff.MaxFileSize, 0)
+	eff.MaxWidth = max(eff.MaxWidth, 0)
+	eff.MaxHeight = max(eff.MaxHeight, 0)
+	return eff
+}
+
+// BitrateCapConfig 按源码率封顶输出码率：各档不超过 源码率×factor（factors 按分辨率覆盖），
+// 封顶后低于 floor 的 HLS 档位被丢弃，至少保留最低一档；源码率由文件大小/时长估算（含音频）
+type BitrateCapConfig struct {
+	Enabled bool               `mapstructure:"enabled"`
+	Factor  float64            `mapstructure:"factor"`  // 默认 1.0
+	Factors map[string]float64 `mapstructure:"factors"` // 分辨率 -> 系数
+	Floor   string             `mapstructure:"floor"`   // 默认 200k
+}
+
+// AutoProfileConfig profile=auto 的规则引擎：按顺序匹配请求携带的源文件特征，第一条命中的规则生效
+type AutoProfileConfig struct {
+	Rules []AutoProfileRule `mapstructure:"rules"`
+}
+
+// AutoProfileRule 单条选择规则；条件为 0/空表示不限制，源文件特征未知时带该条件的规则不命中
+type AutoProfileRule struct {
+	Name        string        `mapstructure:"name"`
+	MinHeight   int           `mapstructure:"min_height"`
+	MaxHeight   int           `mapstructure:"max_height"`
+	MinFPS      float64       `mapstructure:"min_fps"`
+	MaxFPS      float64       `mapstructure:"max_fps"`
+	MinDuration time.Duration `mapstructure:"min_duration"`
+	MaxDuration time.Duration `mapstructure:"max_duration"`
+	Popularity  []string      `mapstructure:"popularity"` // 命中任一预测热度
+	// 命中后的编码决策：resolution/bitrate 必填，video_codec/preset 覆盖 ffmpeg 配置，renditions 裁剪 HLS 阶梯
+	Resolution string   `mapstructure:"resolution"`
+	Bitrate    string   `mapstructure:"bitrate"`
+	VideoCodec string   `mapstructure:"video_codec"`
+	Preset     string   `mapstructure:"preset"`
+	Renditions []string `mapstructure:"renditions"`
+}
+
+// SourceCacheConfig 工作节点源文件 LRU 磁盘缓存，同一视频的多个转码/HLS 作业复用已下载的源
+type SourceCacheConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	Dir            string `mapstructure:"dir"`              // 缺省 <ffmpeg.temp_dir>/source-cache
+	MaxBytes       int64  `mapstructure:"max_bytes"`        // 缺省 20GB
+	MaxObjectBytes int64  `mapstructure:"max_object_bytes"` // 超过该大小的源不缓存，缺省 max_bytes/2
+}
+
+// HLSPathConfig HLS 本地工作目录与对象存储前缀，两者相互独立。
+// PlaylistName/SegmentName 为码流播放列表与切片的文件名模板，可用 {rendition}、{index}、{job}，为空时沿用
+// playlist_{rendition}.m3u8 与 segment_{rendition}_{index}.ts
+type HLSPathConfig struct {
+	WorkDir      string `mapstructure:"work_dir"`
+	ObjectPrefix string `mapstructure:"object_prefix"`
+	PlaylistName string `mapstructure:"playlist_name"`
+	SegmentName  string `mapstructure:"segment_name"`
+}
+
+// Naming 解析文件名模板
+func (h HLSPathConfig) Naming() (*hlsname.Naming, error) {
+	return hlsname.Parse(h.PlaylistName, h.SegmentName)
+}
+
+// validateHLSNaming 模板合法，且全局与各档位集合中的码流生成的文件名互不冲突
+func (t TranscodeConfig) validateHLSNaming() error {
+	naming, err := t.HLS.Naming()
+	if err != nil {
+		return fmt.Errorf("transcode.hls: %w", err)
+	}
+	seen := map[string]bool{"1080p": true, "720p": true, "480p": true}
+	collect := func(formats []OutputFormat) {
+		for _, f := range formats {
+			if f.Resolution != "" {
+				seen[f.Resolution] = true
+			}
+		}
+	}
+	collect(t.OutputFormats)
+	for _, set := range t.FormatSets {
+		collect(set.OutputFormats)
+	}
+	renditions := make([]string, 0, len(seen))
+	for r := range seen {
+		renditions = append(renditions, r)
+	}
+	sort.Strings(renditions)
+	if err := naming.CheckUnique(renditions); err != nil {
+		return fmt.Errorf("transcode.hls: %w", err)
+	}
+	return nil
 }
 
 // OutputFormat 输出格式配置
 type OutputFormat struct {
-	Name       string `mapstructure:"name"`
-	Resolution string `mapstructure:"resolution"`
-	Bitrate    string `mapstructure:"bitrate"`
-	Codec      string `mapstructure:"codec"`
-	Preset     string `mapstructure:"preset"`
+	Name       string    `mapstructure:"name"`
+	Resolution string    `mapstructure:"resolution"`
+	Bitrate    string    `mapstructure:"bitrate"`
+	Codec      string    `mapstructure:"codec"`
+	Preset     string    `mapstructure:"preset"`
+	Container  string    `mapstructure:"container"` // mp4(默认) | mkv | webm | mov
+	GOP        GOPConfig `mapstructure:"gop"`
+}
+
+// GOPConfig 档位的 GOP 结构（闭合 GOP、强制 IDR、场景切换），供要求 IDR 对齐切片的下游打包器使用；
+// forced_idr 不能与 closed_gop: false 或大于 0 的 scenecut 同时配置
+type GOPConfig struct {
+	ClosedGOP   *bool `mapstructure:"closed_gop"`
+	ForcedIDR   bool  `mapstructure:"forced_idr"`
+	IDRInterval int   `mapstructure:"idr_interval"` // forced_idr 时 MP4 的关键帧间隔（秒），默认 2；HLS 按切片时长
+	Scenecut    *int  `mapstructure:"scenecut"`     // 场景切换阈值 0-100，0 关闭；未配置时用编码器默认
+}
+
+// FormatSetConfig 按环境/用途命名的输出档位集合，如 staging 只保留 480p 以节省成本。
+// 选中后替代全局 output_formats，且 HLS 阶梯不再补齐默认档位
+type FormatSetConfig struct {
+	OutputFormats []OutputFormat `mapstructure:"output_formats"`
+	MaxRenditions int            `mapstructure:"max_renditions"` // HLS 阶梯最多档位数，0 表示不限制
+}
+
+// ResolveFormatSet 按名称取档位集合，name 为空时取 format_set；两者都为空时返回全局 output_formats，resolved 为空串。
+// 指定的集合未配置时 ok 为 false
+func (t TranscodeConfig) ResolveFormatSet(name string) (set FormatSetConfig, resolved string, ok bool) {
+	if name == "" {
+		name = t.FormatSet
+	}
+	if name == "" {
+		return FormatSetConfig{OutputFormats: t.OutputFormats}, "", true
+	}
+	set, ok = t.FormatSets[name]
+	return set, name, ok
 }
 
 // FFmpegConfig FFmpeg相关配置
@@ -166,6 +846,21 @@ type FFmpegConfig struct {
 	UseHardwareDecode  bool          `mapstructure:"use_hardware_decode"`
 	DecoderThreads     int           `mapstructure:"decoder_threads"`
 	CuvidSurfaces      int           `mapstructure:"cuvid_surfaces"`
+	// HardwareDevice 硬件加速设备，如 vaapi 的 /dev/dri/renderD129、qsv 的 /dev/dri/renderD128；为空时使用后端默认设备
+	HardwareDevice string `mapstructure:"hardware_device"`
+	// ArchBinaryPaths 按 GOARCH（amd64/arm64）覆盖 binary_path
+	ArchBinaryPaths map[string]string `mapstructure:"arch_binary_paths"`
+	// FallbackVideoCodec 本机 ffmpeg 不支持 video_codec 时改用的编码器
+	FallbackVideoCodec string `mapstructure:"fallback_video_codec"`
+	// KeepWarm 使用 CUDA/NVENC 时保持常驻 ffmpeg 进程持有设备上下文，降低任务启动耗时
+	KeepWarm bool `mapstructure:"keep_warm"`
+}
+
+// FFprobeConfig ffprobe 探测配置
+type FFprobeConfig struct {
+	BinaryPath      string            `mapstructure:"binary_path"`
+	Timeout         time.Duration     `mapstructure:"timeout"`
+	ArchBinaryPaths map[string]string `mapstructure:"arch_binary_paths"`
 }
 
 // WorkerConfig Worker相关配置
@@ -178,6 +873,198 @@ type WorkerConfig struct {
 	HLSMaxConcurrentTasks int           `mapstructure:"hls_max_concurrent_tasks"`
 	QueueCapacity         int           `mapstructure:"queue_capacity"`
 	ShutdownGracePeriod   time.Duration `mapstructure:"shutdown_grace_period"`
+	FinalWriteTimeout     time.Duration `mapstructure:"final_write_timeout"` // 停机/取消后终态落库、回调、位点提交的宽限时长
+	AvgTaskDuration       time.Duration `mapstructure:"avg_task_duration"`
+	HLSReconcileInterval  time.Duration `mapstructure:"hls_reconcile_interval"`
+	HLSClaimTTL           time.Duration `mapstructure:"hls_claim_ttl"`
+	// HLSProcessingTTL processing 作业超过该时长未续期（处理实例已崩溃）时退回 pending；处理期间每 1/3 TTL 续期一次
+	HLSProcessingTTL time.Duration `mapstructure:"hls_processing_ttl"`
+	// OrphanWorkspaceAge 启动时只清理最近修改早于该时长的遗留工作目录，避免误删共享临时目录的其他实例正在使用的目录；默认 6h
+	OrphanWorkspaceAge time.Duration       `mapstructure:"orphan_workspace_age"`
+	AutoTune           bool                `mapstructure:"auto_tune"`
+	TaskMemoryMB       int                 `mapstructure:"task_memory_mb"`
+	Expiry             ExpiryConfig        `mapstructure:"expiry"`
+	StorageRetry       RetryConfig         `mapstructure:"storage_retry"`
+	Snapshot           SnapshotConfig      `mapstructure:"snapshot"`
+	Redispatch         RedispatchConfig    `mapstructure:"redispatch"`
+	UploadPool         UploadPoolConfig    `mapstructure:"upload_pool"`
+	Assignments        AssignmentsConfig   `mapstructure:"assignments"`
+	VideoFence         VideoFenceConfig    `mapstructure:"video_fence"`
+	Priority           PriorityConfig      `mapstructure:"priority"`
+	EncodeBudget       EncodeBudgetConfig  `mapstructure:"encode_budget"`
+	JobPools           map[string]int      `mapstructure:"job_pools"` // 插件作业类型 -> 并发数，缺省 1
+	WaitForSource      WaitForSourceConfig `mapstructure:"wait_for_source"`
+	Presence           PresenceConfig      `mapstructure:"presence"`
+	ExpressLane        ExpressLaneConfig   `mapstructure:"express_lane"`
+	Throughput         ThroughputConfig    `mapstructure:"throughput"`
+	StallWatchdog      StallWatchdogConfig `mapstructure:"stall_watchdog"`
+}
+
+// StallWatchdogConfig ffmpeg 进程存活但超过 window 没有进度推进（如 NFS 读挂起、驱动卡死）时终止进程，
+// 任务按 backoff 进入 retrying 重试，任务重试次数达到 max_retries 后置为失败。
+// 覆盖 MP4 编码与 HLS 切片（带 -progress 的调用）；HLS 码流卡死时按该码流失败处理，纯音频档位不受监控
+type StallWatchdogConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Window     time.Duration `mapstructure:"window"`      // 无进度推进的最长时长，默认 5m
+	MaxRetries int           `mapstructure:"max_retries"` // 默认 2
+	Backoff    time.Duration `mapstructure:"backoff"`     // 重试前等待，默认 30s
+}
+
+// ExpressLaneConfig 短视频快车道：源时长短于 max_duration 的任务进入独立队列，
+// 由 reserved_slots 个专用协程执行（不占共享编码槽位），普通协程空闲时也优先取快车道任务
+type ExpressLaneConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	MaxDuration   time.Duration `mapstructure:"max_duration"`   // 默认 60s
+	ReservedSlots int           `mapstructure:"reserved_slots"` // 默认 1
+	QueueCapacity int           `mapstructure:"queue_capacity"` // 默认同 worker.queue_capacity
+}
+
+// WaitForSourceConfig 源文件尚未复制到位（HEAD 返回 404）时先按退避轮询等待，超过 max_wait 才失败。
+// 等待期间作业已占用编码槽位，max_wait 不宜过长
+type WaitForSourceConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	MaxWait        time.Duration `mapstructure:"max_wait"`        // 最长等待时长，默认 5m
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"` // 首次轮询间隔，之后翻倍，默认 1s
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`     // 轮询间隔上限，默认 30s
+}
+
+// EncodeBudgetConfig MP4 与 HLS 作业共享的编码槽位预算。作业按 job_weights[类型] × resolution_weights[分辨率]
+// 占用槽位；max_concurrent_tasks / hls_max_concurrent_tasks 仍是各自的并发上限
+type EncodeBudgetConfig struct {
+	Enabled           bool           `mapstructure:"enabled"`
+	Slots             int            `mapstructure:"slots"` // 缺省等于 max_concurrent_tasks
+	JobWeights        map[string]int `mapstructure:"job_weights"`
+	ResolutionWeights map[string]int `mapstructure:"resolution_weights"`
+}
+
+// PriorityConfig 队列优先级老化配置：排队每满 aging_interval 有效优先级 +1，最多 +max_aging_boost，
+// 避免低优先级任务被高优先级流量长期饿死
+type PriorityConfig struct {
+	AgingInterval time.Duration     `mapstructure:"aging_interval"`
+	MaxAgingBoost int               `mapstructure:"max_aging_boost"`
+	Reservation   ReservationConfig `mapstructure:"reservation"`
+}
+
+// ReservationConfig 为交互请求预留转码槽位：优先级低于 min_priority 的任务（回填等）
+// 最多占用 (1 - reserved_ratio) 的槽位，出队时按任务原始优先级判断，不计老化提升
+type ReservationConfig struct {
+	Enabled       bool    `mapstructure:"enabled"`
+	ReservedRatio float64 `mapstructure:"reserved_ratio"` // 默认 0.3，至少保留 1 个槽位给普通任务
+	MinPriority   int     `mapstructure:"min_priority"`   // 默认 7
+}
+
+// SnapshotConfig 流水线状态快照配置，用于滚动重启/蓝绿发布后恢复运行中任务
+type SnapshotConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Interval   time.Duration `mapstructure:"interval"`
+	StaleAfter time.Duration `mapstructure:"stale_after"` // 超过该时长未刷新的快照视为实例已宕机
+}
+
+// RedispatchConfig 停机交还任务的重新派发配置：停止的实例把排队未执行的任务打上标记，存活实例周期性认领入队
+type RedispatchConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Interval  time.Duration `mapstructure:"interval"`
+	BatchSize int           `mapstructure:"batch_size"`
+}
+
+// UploadPoolConfig 产物上传池：ffmpeg 结束后产物交给独立的有界上传协程，编码槽位立即释放；
+// 队列满或上传池已停止时回退为在编码协程内同步上传
+type UploadPoolConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Workers      int           `mapstructure:"workers"`       // 并发上传数，默认 2
+	QueueSize    int           `mapstructure:"queue_size"`    // 等待上传的产物数上限，默认 16
+	Retries      int           `mapstructure:"retries"`       // 单个产物上传失败后的重试次数，默认 3
+	Backoff      time.Duration `mapstructure:"backoff"`       // 重试间隔基数，按次数线性增长，默认 5s
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"` // 停机时等待剩余上传完成的时长，默认 2m
+}
+
+// AssignmentsConfig 作业分配历史：每次执行写入 task_assignments，供按 worker 统计利用率
+type AssignmentsConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	BufferSize int           `mapstructure:"buffer_size"` // 待写入记录的缓冲上限，满时丢弃并计数，默认 256
+	Retention  time.Duration `mapstructure:"retention"`   // 记录保留时长，默认 90 天
+}
+
+// ThroughputConfig 每日处理量统计：下载/上传字节与编码耗时按 UTC 日期累加写入 throughput_daily
+type ThroughputConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // 内存计数写库间隔，默认 1m
+}
+
+// PresenceConfig worker 存活状态写入 Redis，间隔取 heartbeat_interval
+type PresenceConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	TTL     time.Duration `mapstructure:"ttl"`     // 存活键过期时间，须大于心跳间隔，默认 3 个心跳间隔
+	History time.Duration `mapstructure:"history"` // 列出 worker 时合并该时长内有执行记录但已下线的实例，默认 24h
+}
+
+// 同视频作业串行化范围
+const (
+	VideoFenceOff   = "off"
+	VideoFenceLocal = "local" // 同一实例内串行
+	VideoFenceFleet = "fleet" // 通过 Redis 锁在全部实例间串行
+)
+
+// VideoFenceConfig 同一视频的作业（不同档位的 MP4/HLS）串行执行，避免争抢磁盘缓存与输出路径。
+// 软栅栏：等待超过 max_wait 或 Redis 不可用时照常执行
+type VideoFenceConfig struct {
+	Mode          string        `mapstructure:"mode"`           // off | local | fleet，默认 off
+	MaxWait       time.Duration `mapstructure:"max_wait"`       // 最长等待时长，默认 10m
+	LockTTL       time.Duration `mapstructure:"lock_ttl"`       // fleet 模式锁过期时间，持有期间按 1/3 周期续期，默认 1m
+	RetryInterval time.Duration `mapstructure:"retry_interval"` // fleet 模式抢锁失败后的重试间隔，默认 2s
+}
+
+// RetryConfig 存储瞬时故障的退避重试配置
+type RetryConfig struct {
+	MaxAttempts   int           `mapstructure:"max_attempts"`
+	BaseBackoff   time.Duration `mapstructure:"base_backoff"`
+	MaxBackoff    time.Duration `mapstructure:"max_backoff"`
+	ProbeInterval time.Duration `mapstructure:"probe_interval"` // 存储不可用期间的探测周期
+}
+
+// Backoff 返回第 attempt 次重试（从 1 开始）的指数退避时长
+func (r RetryConfig) Backoff(attempt int) time.Duration {
+	d := r.BaseBackoff
+	for i := 1; i < attempt && d < r.MaxBackoff; i++ {
+		d *= 2
+	}
+	if r.MaxBackoff > 0 && d > r.MaxBackoff {
+		d = r.MaxBackoff
+	}
+	return d
+}
+
+// ExpiryConfig 任务截止时间配置；by_resolution 优先于 by_priority，均未命中时使用 default_ttl
+type ExpiryConfig struct {
+	Enabled       bool                     `mapstructure:"enabled"`
+	CheckInterval time.Duration            `mapstructure:"check_interval"`
+	DefaultTTL    time.Duration            `mapstructure:"default_ttl"`
+	ByPriority    map[string]time.Duration `mapstructure:"by_priority"`
+	ByResolution  map[string]time.Duration `mapstructure:"by_resolution"`
+}
+
+// TTLFor 返回指定优先级/分辨率的任务截止时长
+func (e ExpiryConfig) TTLFor(priority int, resolution string) time.Duration {
+	if ttl, ok := e.ByResolution[strings.ToLower(resolution)]; ok && ttl > 0 {
+		return ttl
+	}
+	if ttl, ok := e.ByPriority[strconv.Itoa(priority)]; ok && ttl > 0 {
+		return ttl
+	}
+	return e.DefaultTTL
+}
+
+// MinTTL 返回所有配置中最短的截止时长
+func (e ExpiryConfig) MinTTL() time.Duration {
+	min := e.DefaultTTL
+	for _, m := range []map[string]time.Duration{e.ByPriority, e.ByResolution} {
+		for _, ttl := range m {
+			if ttl > 0 && ttl < min {
+				min = ttl
+			}
+		}
+	}
+	return min
 }
 
 // SchedulerConfig 调度器相关配置
@@ -220,13 +1107,28 @@ func Load(configPath string) (*Config, error) {
 	// 保持向后兼容：默认开启服务注册，可配置关闭
 	viper.SetDefault("service_registry.enabled", true)
 	viper.SetDefault("dependencies.upload_service.service_name", "upload-service")
+	viper.SetDefault("dependencies.upload_service.milestones.hls_started", true)
 	viper.SetDefault("kafka.enabled", true)
 	viper.SetDefault("kafka.client_id", "transcode-service")
 	viper.SetDefault("kafka.group_id", "transcode-service-group")
 	viper.SetDefault("kafka.bootstrap_servers", []string{"localhost:29092"})
 	viper.SetDefault("kafka.topics.transcode_tasks", "transcode.tasks")
-	viper.SetDefault("kafka.commit_on_decode_error", true)
-	viper.SetDefault("kafka.commit_on_process_error", false)
+	viper.SetDefault("kafka.error_policy.dlq_topic", "transcode.tasks.dlq")
+	viper.SetDefault("worker.auto_tune", true)
+	viper.SetDefault("rustfs.checksum_retries", 2)
+	viper.SetDefault("worker.expiry.enabled", true)
+	viper.SetDefault("worker.snapshot.enabled", true)
+	viper.SetDefault("worker.redispatch.enabled", true)
+	viper.SetDefault("worker.upload_pool.enabled", true)
+	viper.SetDefault("worker.assignments.enabled", true)
+	viper.SetDefault("worker.throughput.enabled", true)
+	viper.SetDefault("worker.encode_budget.enabled", true)
+	viper.SetDefault("transcode.source_cache.enabled", true)
+	viper.SetDefault("read_cache.enabled", true)
+	viper.SetDefault("analytics.sink", "storage")
+	viper.SetDefault("notifications.events."+AlertTaskFailureSpike+".enabled", true)
+	viper.SetDefault("notifications.events."+AlertDLQGrowth+".enabled", true)
+	viper.SetDefault("notifications.events."+AlertSLABreach+".enabled", true)
 
 	// 设置环境变量前缀
 	viper.SetEnvPrefix("GO_VIDEO")
@@ -245,6 +1147,18 @@ func Load(configPath string) (*Config, error) {
 	}
 
 	config.normalize()
+	if _, _, ok := config.Transcode.ResolveFormatSet(""); !ok {
+		return nil, fmt.Errorf("transcode.format_set %q is not defined in transcode.format_sets", config.Transcode.FormatSet)
+	}
+	if err := config.Transcode.validateHLSNaming(); err != nil {
+		return nil, err
+	}
+	if !config.Public.ValidFallback() {
+		return nil, fmt.Errorf("public.fallback %q must be one of endpoint, presign, relative", config.Public.Fallback)
+	}
+	if config.GRPCServer.RequireAuth && len(config.GRPCServer.ResolvedAuthTokens()) == 0 {
+		return nil, fmt.Errorf("grpc_server.require_auth is set but neither grpc_server.auth_tokens nor $%s provides a token", config.GRPCServer.AuthTokensEnv)
+	}
 
 	return &config, nil
 }
@@ -263,6 +1177,13 @@ func (c *Config) normalize() {
 	if c.RustFS.Endpoint == "" {
 		c.RustFS.Endpoint = c.Minio.Endpoint
 	}
+	if c.RustFS.ChecksumRetries < 0 {
+		c.RustFS.ChecksumRetries = 0
+	}
+
+	if c.Redis.HealthCheckInterval <= 0 {
+		c.Redis.HealthCheckInterval = 5 * time.Second
+	}
 
 	// Worker相关默认值
 	if c.Worker.MaxConcurrentTasks <= 0 {
@@ -284,14 +1205,163 @@ func (c *Config) normalize() {
 	if c.Worker.ShutdownGracePeriod == 0 {
 		c.Worker.ShutdownGracePeriod = 10 * time.Second
 	}
+	if c.Worker.OrphanWorkspaceAge <= 0 {
+		c.Worker.OrphanWorkspaceAge = 6 * time.Hour
+	}
+	if c.Worker.FinalWriteTimeout <= 0 {
+		c.Worker.FinalWriteTimeout = 10 * time.Second
+	}
+	if c.Worker.AvgTaskDuration <= 0 {
+		c.Worker.AvgTaskDuration = 5 * time.Minute
+	}
+	if c.Worker.HLSReconcileInterval <= 0 {
+		c.Worker.HLSReconcileInterval = 30 * time.Second
+	}
+	if c.Worker.HLSClaimTTL <= 0 {
+		c.Worker.HLSClaimTTL = 10 * time.Minute
+	}
+	if c.Worker.HLSProcessingTTL <= 0 {
+		c.Worker.HLSProcessingTTL = 15 * time.Minute
+	}
+	if c.Worker.TaskMemoryMB <= 0 {
+		c.Worker.TaskMemoryMB = 1024
+	}
+	if c.Worker.StorageRetry.MaxAttempts <= 0 {
+		c.Worker.StorageRetry.MaxAttempts = 10
+	}
+	if c.Worker.StorageRetry.BaseBackoff <= 0 {
+		c.Worker.StorageRetry.BaseBackoff = 30 * time.Second
+	}
+	if c.Worker.StorageRetry.MaxBackoff <= 0 {
+		c.Worker.StorageRetry.MaxBackoff = 10 * time.Minute
+	}
+	if c.Worker.StorageRetry.ProbeInterval <= 0 {
+		c.Worker.StorageRetry.ProbeInterval = 10 * time.Second
+	}
+	if c.Worker.Expiry.CheckInterval <= 0 {
+		c.Worker.Expiry.CheckInterval = 5 * time.Minute
+	}
+	if c.Worker.Expiry.DefaultTTL <= 0 {
+		c.Worker.Expiry.DefaultTTL = 24 * time.Hour
+	}
+	if c.Worker.Snapshot.Interval <= 0 {
+		c.Worker.Snapshot.Interval = 15 * time.Second
+	}
+	if c.Worker.Snapshot.StaleAfter <= 0 {
+		c.Worker.Snapshot.StaleAfter = 4 * c.Worker.Snapshot.Interval
+	}
+	if c.Worker.Redispatch.Interval <= 0 {
+		c.Worker.Redispatch.Interval = 10 * time.Second
+	}
+	if c.Worker.Redispatch.BatchSize <= 0 {
+		c.Worker.Redispatch.BatchSize = 50
+	}
+	if c.Worker.UploadPool.Workers <= 0 {
+		c.Worker.UploadPool.Workers = 2
+	}
+	if c.Worker.UploadPool.QueueSize <= 0 {
+		c.Worker.UploadPool.QueueSize = 16
+	}
+	if c.Worker.UploadPool.Retries < 0 {
+		c.Worker.UploadPool.Retries = 0
+	}
+	if c.Worker.UploadPool.Backoff <= 0 {
+		c.Worker.UploadPool.Backoff = 5 * time.Second
+	}
+	if c.Worker.UploadPool.DrainTimeout <= 0 {
+		c.Worker.UploadPool.DrainTimeout = 2 * time.Minute
+	}
+	switch c.Worker.VideoFence.Mode {
+	case VideoFenceLocal, VideoFenceFleet:
+	default:
+		c.Worker.VideoFence.Mode = VideoFenceOff
+	}
+	if c.Worker.WaitForSource.MaxWait <= 0 {
+		c.Worker.WaitForSource.MaxWait = 5 * time.Minute
+	}
+	if c.Worker.StallWatchdog.Window <= 0 {
+		c.Worker.StallWatchdog.Window = 5 * time.Minute
+	}
+	if c.Worker.StallWatchdog.MaxRetries <= 0 {
+		c.Worker.StallWatchdog.MaxRetries = 2
+	}
+	if c.Worker.StallWatchdog.Backoff <= 0 {
+		c.Worker.StallWatchdog.Backoff = 30 * time.Second
+	}
+	if c.Worker.WaitForSource.InitialBackoff <= 0 {
+		c.Worker.WaitForSource.InitialBackoff = time.Second
+	}
+	if c.Worker.WaitForSource.MaxBackoff <= 0 {
+		c.Worker.WaitForSource.MaxBackoff = 30 * time.Second
+	}
+	if c.Worker.VideoFence.MaxWait <= 0 {
+		c.Worker.VideoFence.MaxWait = 10 * time.Minute
+	}
+	if c.Worker.VideoFence.LockTTL <= 0 {
+		c.Worker.VideoFence.LockTTL = time.Minute
+	}
+	if c.Worker.VideoFence.RetryInterval <= 0 {
+		c.Worker.VideoFence.RetryInterval = 2 * time.Second
+	}
+	if c.Worker.Assignments.BufferSize <= 0 {
+		c.Worker.Assignments.BufferSize = 256
+	}
+	if c.Worker.Assignments.Retention <= 0 {
+		c.Worker.Assignments.Retention = 90 * 24 * time.Hour
+	}
+	if c.Worker.Throughput.FlushInterval <= 0 {
+		c.Worker.Throughput.FlushInterval = time.Minute
+	}
+	if c.Worker.Presence.History <= 0 {
+		c.Worker.Presence.History = 24 * time.Hour
+	}
+	if c.Worker.Priority.AgingInterval <= 0 {
+		c.Worker.Priority.AgingInterval = 5 * time.Minute
+	}
+	if c.Worker.Priority.MaxAgingBoost <= 0 {
+		c.Worker.Priority.MaxAgingBoost = 5
+	}
+	if c.Worker.Priority.Reservation.ReservedRatio <= 0 || c.Worker.Priority.Reservation.ReservedRatio >= 1 {
+		c.Worker.Priority.Reservation.ReservedRatio = 0.3
+	}
+	if c.Worker.Priority.Reservation.MinPriority <= 0 {
+		c.Worker.Priority.Reservation.MinPriority = 7
+	}
+	if c.Worker.ExpressLane.MaxDuration <= 0 {
+		c.Worker.ExpressLane.MaxDuration = 60 * time.Second
+	}
+	if c.Worker.ExpressLane.ReservedSlots <= 0 {
+		c.Worker.ExpressLane.ReservedSlots = 1
+	}
 
 	// FFmpeg临时目录默认值
 	if c.Transcode.FFmpeg.TempDir == "" {
 		c.Transcode.FFmpeg.TempDir = "/tmp/transcode"
 	}
+	if c.Transcode.HLS.WorkDir == "" {
+		c.Transcode.HLS.WorkDir = "storage/hls"
+	}
+	if c.Transcode.SourceCache.Dir == "" {
+		c.Transcode.SourceCache.Dir = filepath.Join(c.Transcode.FFmpeg.TempDir, "source-cache")
+	}
+	if c.Transcode.SourceCache.MaxBytes <= 0 {
+		c.Transcode.SourceCache.MaxBytes = 20 << 30
+	}
+	if c.Transcode.SourceCache.MaxObjectBytes <= 0 {
+		c.Transcode.SourceCache.MaxObjectBytes = c.Transcode.SourceCache.MaxBytes / 2
+	}
+	if c.Transcode.HLS.ObjectPrefix == "" {
+		c.Transcode.HLS.ObjectPrefix = "hls"
+	}
 	if c.Transcode.FFmpeg.BinaryPath == "" {
 		c.Transcode.FFmpeg.BinaryPath = "ffmpeg"
 	}
+	if c.Transcode.FFprobe.BinaryPath == "" {
+		c.Transcode.FFprobe.BinaryPath = "ffprobe"
+	}
+	if c.Transcode.FFprobe.Timeout <= 0 {
+		c.Transcode.FFprobe.Timeout = 30 * time.Second
+	}
 	if c.Transcode.FFmpeg.VideoCodec == "" {
 		c.Transcode.FFmpeg.VideoCodec = "libx264"
 	}
@@ -310,6 +1380,211 @@ func (c *Config) normalize() {
 	if c.GRPCServer.Port == 0 {
 		c.GRPCServer.Port = 9092
 	}
+	if c.ReadCache.TTL <= 0 {
+		c.ReadCache.TTL = 2 * time.Second
+	}
+	if c.ReadCache.MaxEntries <= 0 {
+		c.ReadCache.MaxEntries = 10000
+	}
+	if c.Transcode.Labels.MaxMetricValues <= 0 {
+		c.Transcode.Labels.MaxMetricValues = 20
+	}
+	if c.Transcode.Preview.MaxSeconds <= 0 {
+		c.Transcode.Preview.MaxSeconds = 60
+	}
+	if m := strings.ToLower(c.Transcode.SourceStreams.MissingAudio); m != "none" {
+		c.Transcode.SourceStreams.MissingAudio = "silent"
+	} else {
+		c.Transcode.SourceStreams.MissingAudio = m
+	}
+	if a := strings.ToLower(c.Transcode.SourceStreams.AudioOnly); a != "reject" {
+		c.Transcode.SourceStreams.AudioOnly = "audio"
+	} else {
+		c.Transcode.SourceStreams.AudioOnly = a
+	}
+	if c.Transcode.Samples.Interval <= 0 {
+		c.Transcode.Samples.Interval = 5 * time.Second
+	}
+	if c.Transcode.Samples.MaxSamples <= 0 {
+		c.Transcode.Samples.MaxSamples = 720
+	}
+	if c.Transcode.Samples.FlushEvery <= 0 {
+		c.Transcode.Samples.FlushEvery = 6
+	}
+	if c.Transcode.SettingsVersion <= 0 {
+		c.Transcode.SettingsVersion = 1
+	}
+	if c.Transcode.BitrateCap.Factor <= 0 {
+		c.Transcode.BitrateCap.Factor = 1.0
+	}
+	if c.Transcode.BitrateCap.Floor == "" {
+		c.Transcode.BitrateCap.Floor = "200k"
+	}
+	if c.Transcode.LadderCache.TTL <= 0 {
+		c.Transcode.LadderCache.TTL = 60 * time.Second
+	}
+	if c.Transcode.LadderCache.Channel == "" {
+		c.Transcode.LadderCache.Channel = "transcode:ladders:invalidate"
+	}
+	if c.Transcode.Preview.DefaultSeconds <= 0 || c.Transcode.Preview.DefaultSeconds > c.Transcode.Preview.MaxSeconds {
+		c.Transcode.Preview.DefaultSeconds = min(10, c.Transcode.Preview.MaxSeconds)
+	}
+	if c.Transcode.Replay.MaxPerMinute <= 0 {
+		c.Transcode.Replay.MaxPerMinute = 5
+	}
+	if c.Transcode.CancelCheckInterval <= 0 {
+		c.Transcode.CancelCheckInterval = 10 * time.Second
+	}
+	if c.Analytics.Interval <= 0 {
+		c.Analytics.Interval = 5 * time.Minute
+	}
+	if c.Analytics.BatchSize <= 0 {
+		c.Analytics.BatchSize = 500
+	}
+	if c.Analytics.Backfill <= 0 {
+		c.Analytics.Backfill = 24 * time.Hour
+	}
+	if c.Analytics.Sink == "" {
+		c.Analytics.Sink = "storage"
+	}
+	if c.Analytics.Storage.Prefix == "" {
+		c.Analytics.Storage.Prefix = "analytics"
+	}
+	if c.Analytics.Storage.Format == "" {
+		c.Analytics.Storage.Format = "csv"
+	}
+	if c.Analytics.HTTP.Timeout <= 0 {
+		c.Analytics.HTTP.Timeout = 30 * time.Second
+	}
+	if c.Analytics.Kafka.Topic == "" {
+		c.Analytics.Kafka.Topic = "transcode.analytics"
+	}
+	c.normalizeNotifications()
+	if c.Encryption.RotateBatchSize <= 0 {
+		c.Encryption.RotateBatchSize = 200
+	}
+	if len(c.Benchmark.Resolutions) == 0 {
+		c.Benchmark.Resolutions = []string{"480p", "720p", "1080p"}
+	}
+	if c.Benchmark.SourceSeconds <= 0 {
+		c.Benchmark.SourceSeconds = 30
+	}
+	if c.Benchmark.RatePerMinute <= 0 {
+		c.Benchmark.RatePerMinute = 6
+	}
+	if c.Benchmark.Duration <= 0 {
+		c.Benchmark.Duration = 10 * time.Minute
+	}
+	if c.Benchmark.MaxDuration <= 0 {
+		c.Benchmark.MaxDuration = 2 * time.Hour
+	}
+	if c.Benchmark.DrainTimeout <= 0 {
+		c.Benchmark.DrainTimeout = 15 * time.Minute
+	}
+	if c.Benchmark.UserUUID == "" {
+		c.Benchmark.UserUUID = "benchmark"
+	}
+	if c.Diagnostics.RuntimeMetricsInterval == 0 {
+		c.Diagnostics.RuntimeMetricsInterval = 15 * time.Second
+	}
+	if c.Diagnostics.CapturePrefix == "" {
+		c.Diagnostics.CapturePrefix = "debug/profiles"
+	}
+	if c.Diagnostics.MaxCaptureSeconds <= 0 {
+		c.Diagnostics.MaxCaptureSeconds = 30
+	}
+	if c.TaskGroups.MaxTasks <= 0 {
+		c.TaskGroups.MaxTasks = 500
+	}
+	if c.TaskGroups.CheckInterval <= 0 {
+		c.TaskGroups.CheckInterval = 30 * time.Second
+	}
+	if c.TaskGroups.CallbackTimeout <= 0 {
+		c.TaskGroups.CallbackTimeout = 10 * time.Second
+	}
+	if c.TaskGroups.CallbackMaxAttempts <= 0 {
+		c.TaskGroups.CallbackMaxAttempts = 5
+	}
+	if c.Diagnostics.GPU.Interval <= 0 {
+		c.Diagnostics.GPU.Interval = 15 * time.Second
+	}
+	if c.Diagnostics.GPU.Binary == "" {
+		c.Diagnostics.GPU.Binary = "nvidia-smi"
+	}
+	if c.Shutdown.IngestTimeout <= 0 {
+		c.Shutdown.IngestTimeout = 15 * time.Second
+	}
+	if c.Shutdown.DrainTimeout <= 0 {
+		c.Shutdown.DrainTimeout = 60 * time.Second
+	}
+	if c.Shutdown.FlushTimeout <= 0 {
+		c.Shutdown.FlushTimeout = 30 * time.Second
+	}
+	if c.Shutdown.ResourcesTimeout <= 0 {
+		c.Shutdown.ResourcesTimeout = 10 * time.Second
+	}
+	if c.Public.Fallback == "" {
+		c.Public.Fallback = PublicFallbackEndpoint
+	}
+	if c.Public.PresignTTL <= 0 {
+		c.Public.PresignTTL = 24 * time.Hour
+	}
+	if c.PrivateOutputs.DefaultVisibility == "" {
+		c.PrivateOutputs.DefaultVisibility = "public"
+	}
+	if c.PrivateOutputs.SignTTL <= 0 {
+		c.PrivateOutputs.SignTTL = time.Hour
+	}
+	if c.Multipart.Threshold <= 0 {
+		c.Multipart.Threshold = 128 << 20
+	}
+	if c.Multipart.PartSize <= 0 {
+		c.Multipart.PartSize = 16 << 20
+	} else if c.Multipart.PartSize < 5<<20 {
+		c.Multipart.PartSize = 5 << 20
+	}
+	if c.Multipart.Parallelism <= 0 {
+		c.Multipart.Parallelism = 4
+	}
+	if c.Multipart.PartRetries < 0 {
+		c.Multipart.PartRetries = 0
+	}
+	if c.Lifecycle.ColdAfter <= 0 {
+		c.Lifecycle.ColdAfter = 90 * 24 * time.Hour
+	}
+	if c.Lifecycle.Interval <= 0 {
+		c.Lifecycle.Interval = time.Hour
+	}
+	if c.Lifecycle.BatchSize <= 0 {
+		c.Lifecycle.BatchSize = 100
+	}
+	if c.Lifecycle.ArchiveDir == "" {
+		c.Lifecycle.ArchiveDir = ".archive"
+	}
+	if c.StorageProbe.Interval <= 0 {
+		c.StorageProbe.Interval = 30 * time.Second
+	}
+	if c.StorageProbe.Timeout <= 0 {
+		c.StorageProbe.Timeout = 5 * time.Second
+	}
+	if c.StorageProbe.SlowThreshold <= 0 {
+		c.StorageProbe.SlowThreshold = 2 * time.Second
+	}
+	if c.StorageProbe.FailureThreshold <= 0 {
+		c.StorageProbe.FailureThreshold = 2
+	}
+	if len(c.StorageProbe.Targets) == 0 {
+		c.StorageProbe.Targets = []StorageProbeTarget{
+			{Name: "source", CanaryKey: "uploads/.healthcheck", Gate: true},
+			{Name: "output", CanaryKey: "transcoded/.healthcheck", Gate: true},
+		}
+	}
+	if c.GRPCServer.MaxRecvMsgSize <= 0 {
+		c.GRPCServer.MaxRecvMsgSize = 4 << 20
+	}
+	if c.GRPCServer.MaxSendMsgSize <= 0 {
+		c.GRPCServer.MaxSendMsgSize = 4 << 20
+	}
 	if c.ServiceRegistry.TTL == 0 {
 		c.ServiceRegistry.TTL = 30 * time.Second
 	}
@@ -319,6 +1594,9 @@ func (c *Config) normalize() {
 	if c.GRPCClient.Timeout <= 0 {
 		c.GRPCClient.Timeout = 30 * time.Second
 	}
+	if c.GRPCClient.HealthCheckInterval <= 0 {
+		c.GRPCClient.HealthCheckInterval = 15 * time.Second
+	}
 	if c.GRPCClient.RetryTimes < 0 {
 		c.GRPCClient.RetryTimes = 0
 	}
@@ -331,6 +1609,34 @@ func (c *Config) normalize() {
 	if c.Dependencies.UploadService.Timeout <= 0 {
 		c.Dependencies.UploadService.Timeout = c.GRPCClient.Timeout
 	}
+	if kp := &c.Dependencies.KeyProvider; kp.Enabled {
+		// 默认由 upload-service 提供
+		if kp.ServiceName == "" {
+			kp.ServiceName = c.Dependencies.UploadService.ServiceName
+		}
+		if kp.Port <= 0 {
+			kp.Port = c.Dependencies.UploadService.Port
+		}
+		if kp.Timeout <= 0 {
+			kp.Timeout = 10 * time.Second
+		}
+	}
+	if ms := &c.Dependencies.UploadService.Milestones; ms.Enabled {
+		valid := ms.Progress[:0]
+		for _, p := range ms.Progress {
+			if p > 0 && p < 100 {
+				valid = append(valid, p)
+			}
+		}
+		if len(ms.Progress) == 0 {
+			valid = []int{25, 50, 75}
+		}
+		sort.Ints(valid)
+		ms.Progress = valid
+		if ms.Timeout <= 0 {
+			ms.Timeout = 5 * time.Second
+		}
+	}
 	if c.Dependencies.VideoService.ServiceName == "" {
 		c.Dependencies.VideoService.ServiceName = "video-service"
 	}
@@ -346,6 +1652,41 @@ func (c *Config) normalize() {
 	if c.Kafka.ClientID == "" {
 		c.Kafka.ClientID = "transcode-service"
 	}
+	if c.Kafka.Lag.CheckInterval <= 0 {
+		c.Kafka.Lag.CheckInterval = 30 * time.Second
+	}
+	if c.Kafka.RebalanceDrainTimeout <= 0 {
+		c.Kafka.RebalanceDrainTimeout = 20 * time.Second
+	}
+	if c.Kafka.ErrorPolicy.Classes == nil {
+		c.Kafka.ErrorPolicy.Classes = make(map[string]KafkaErrorActionConfig)
+	}
+	for class, def := range defaultKafkaErrorActions() {
+		a, ok := c.Kafka.ErrorPolicy.Classes[class]
+		if !ok || a.Action == "" {
+			c.Kafka.ErrorPolicy.Classes[class] = def
+			continue
+		}
+		a.Action = strings.ToLower(a.Action)
+		a.OnExhausted = strings.ToLower(a.OnExhausted)
+		switch a.Action {
+		case KafkaActionCommit, KafkaActionDLQ, KafkaActionRetry:
+		default:
+			a = def
+		}
+		if a.Action == KafkaActionRetry {
+			if a.Retries <= 0 {
+				a.Retries = 1
+			}
+			if a.Backoff <= 0 {
+				a.Backoff = time.Second
+			}
+			if a.OnExhausted != KafkaActionCommit {
+				a.OnExhausted = KafkaActionDLQ
+			}
+		}
+		c.Kafka.ErrorPolicy.Classes[class] = a
+	}
 }
 
 // GetDSN 获取数据库连接字符串
@@ -366,13 +1707,71 @@ func (c *MinioConfig) GetMinioEndpoint() string {
 
 // KafkaConfig Kafka配置
 type KafkaConfig struct {
-	BootstrapServers     []string          `mapstructure:"bootstrap_servers"`
-	ClientID             string            `mapstructure:"client_id"`
-	GroupID              string            `mapstructure:"group_id"`
-	Enabled              bool              `mapstructure:"enabled"`
-	Topics               KafkaTopicsConfig `mapstructure:"topics"`
-	CommitOnDecodeError  bool              `mapstructure:"commit_on_decode_error"`
-	CommitOnProcessError bool              `mapstructure:"commit_on_process_error"`
+	BootstrapServers []string               `mapstructure:"bootstrap_servers"`
+	ClientID         string                 `mapstructure:"client_id"`
+	GroupID          string                 `mapstructure:"group_id"`
+	Enabled          bool                   `mapstructure:"enabled"`
+	Topics           KafkaTopicsConfig      `mapstructure:"topics"`
+	ErrorPolicy      KafkaErrorPolicyConfig `mapstructure:"error_policy"`
+	Lag              KafkaLagConfig         `mapstructure:"lag"`
+	// RebalanceDrainTimeout 再均衡时等待已派发消息处理完成的上限，超时后中止未完成的创建且不提交位点
+	RebalanceDrainTimeout time.Duration `mapstructure:"rebalance_drain_timeout"`
+}
+
+// 消费失败的错误类别
+const (
+	KafkaErrorDecode     = "decode"
+	KafkaErrorValidation = "validation"
+	KafkaErrorDuplicate  = "duplicate"
+	KafkaErrorStorage    = "storage"
+	KafkaErrorInternal   = "internal"
+)
+
+// 消费失败的处理动作
+const (
+	KafkaActionCommit = "commit" // 提交位点，丢弃消息
+	KafkaActionRetry  = "retry"  // 原地重试 retries 次，仍失败按 on_exhausted 处理
+	KafkaActionDLQ    = "dlq"    // 写入死信主题后提交
+)
+
+// KafkaErrorPolicyConfig 按错误类别配置消费失败后的提交/重试/死信策略
+type KafkaErrorPolicyConfig struct {
+	DLQTopic string                            `mapstructure:"dlq_topic"`
+	Classes  map[string]KafkaErrorActionConfig `mapstructure:"classes"`
+}
+
+// KafkaErrorActionConfig 单个错误类别的处理动作
+type KafkaErrorActionConfig struct {
+	Action      string        `mapstructure:"action"`       // commit | retry | dlq
+	Retries     int           `mapstructure:"retries"`      // action=retry 时的原地重试次数
+	Backoff     time.Duration `mapstructure:"backoff"`      // 第 n 次重试等待 n*backoff
+	OnExhausted string        `mapstructure:"on_exhausted"` // 重试耗尽后的动作 commit | dlq
+}
+
+// defaultKafkaErrorActions 未配置的类别使用的默认策略：数据问题直接进死信，依赖故障先重试
+func defaultKafkaErrorActions() map[string]KafkaErrorActionConfig {
+	return map[string]KafkaErrorActionConfig{
+		KafkaErrorDecode:     {Action: KafkaActionDLQ},
+		KafkaErrorValidation: {Action: KafkaActionDLQ},
+		KafkaErrorDuplicate:  {Action: KafkaActionCommit},
+		KafkaErrorStorage:    {Action: KafkaActionRetry, Retries: 3, Backoff: 2 * time.Second, OnExhausted: KafkaActionDLQ},
+		KafkaErrorInternal:   {Action: KafkaActionRetry, Retries: 1, Backoff: time.Second, OnExhausted: KafkaActionDLQ},
+	}
+}
+
+// Action 返回错误类别对应的处理动作，未知类别按 internal 处理
+func (p KafkaErrorPolicyConfig) Action(class string) KafkaErrorActionConfig {
+	if a, ok := p.Classes[class]; ok {
+		return a
+	}
+	return p.Classes[KafkaErrorInternal]
+}
+
+// KafkaLagConfig 消费积压监控配置
+type KafkaLagConfig struct {
+	CheckInterval     time.Duration `mapstructure:"check_interval"`
+	WarnThreshold     int64         `mapstructure:"warn_threshold"`
+	CriticalThreshold int64         `mapstructure:"critical_threshold"`
 }
 
 type KafkaTopicsConfig struct {
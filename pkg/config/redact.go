@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redactedValue 脱敏后的占位值
+const redactedValue = "******"
+
+// sensitiveKeys 字段名包含以下片段时视为密钥
+var sensitiveKeys = []string{"password", "secret", "access_key", "token", "private_key", "routing_key", "webhook_url"}
+
+// Redacted 返回按 mapstructure 键名展开的生效配置（含 normalize 补齐的默认值），敏感字段已脱敏
+func (c *Config) Redacted() map[string]interface{} {
+	out, _ := redactValue(reflect.ValueOf(*c), "").(map[string]interface{})
+	return out
+}
+
+func redactValue(v reflect.Value, key string) interface{} {
+	if isSensitiveKey(key) {
+		if v.IsZero() {
+			return ""
+		}
+		return redactedValue
+	}
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		out := make(map[string]interface{}, v.NumField())
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+			if name == "" || name == "-" {
+				name = field.Name
+			}
+			out[name] = redactValue(v.Field(i), name)
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k := iter.Key().String()
+			out[k] = redactValue(iter.Value(), k)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		out := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out = append(out, redactValue(v.Index(i), ""))
+		}
+		return out
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem(), key)
+	default:
+		return v.Interface()
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
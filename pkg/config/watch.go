@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// WatchPublic 按 interval 检查配置文件修改时间，变化时重新读取并回调 public 段；
+// 只供调用方更新自身派生状态，全局配置其余部分保持启动时的值
+func WatchPublic(interval time.Duration, fn func(PublicConfig)) {
+	path := viper.ConfigFileUsed()
+	if path == "" || fn == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	st, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	last := st.ModTime()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			st, err := os.Stat(path)
+			if err != nil || !st.ModTime().After(last) {
+				continue
+			}
+			last = st.ModTime()
+			if err := viper.ReadInConfig(); err != nil {
+				continue
+			}
+			var pc PublicConfig
+			if err := viper.UnmarshalKey("public", &pc); err != nil {
+				continue
+			}
+			fn(pc)
+		}
+	}()
+}
@@ -0,0 +1,18 @@
+package ctxutil
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultFinalWriteTimeout 未配置 worker.final_write_timeout 时的收尾写入时限
+const DefaultFinalWriteTimeout = 10 * time.Second
+
+// Detached 派生不随 parent 取消的上下文，保留 parent 携带的值（request id 等），最长存活 grace。
+// 用于停机或作业被取消后仍须完成的收尾写入：终态落库、结果回调、提交消费位点
+func Detached(parent context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	if grace <= 0 {
+		grace = DefaultFinalWriteTimeout
+	}
+	return context.WithTimeout(context.WithoutCancel(parent), grace)
+}
@@ -55,4 +55,116 @@ var (
 	ErrInvalidHLSResolution   = &Errno{Code: 20021, Message: "Invalid HLS resolution configuration"}
 	ErrHLSBitrateRequired     = &Errno{Code: 20022, Message: "HLS bitrate is required"}
 	ErrHLSGenerationFailed    = &Errno{Code: 20023, Message: "HLS slice generation failed"}
+
+	// 视频聚合相关错误码
+	ErrVideoProcessingNotFound = &Errno{Code: 20024, Message: "No processing jobs found for video"}
+
+	// 媒体探测相关错误码
+	ErrProbeFailed = &Errno{Code: 20025, Message: "Media probe failed"}
+
+	// 运维相关错误码
+	ErrKafkaLagUnavailable = &Errno{Code: 20026, Message: "Kafka lag has not been collected yet"}
+
+	// 用户偏好相关错误码
+	ErrPreferenceNotFound = &Errno{Code: 20027, Message: "User transcode preference not found"}
+	ErrPreferenceEmpty    = &Errno{Code: 20028, Message: "At least one of ladder, watermark or subtitle_language is required"}
+
+	// 批量查询相关错误码
+	ErrBatchTooLarge = &Errno{Code: 20029, Message: "Too many items in batch request"}
+
+	// 优先级相关错误码
+	ErrInvalidPriority = &Errno{Code: 20030, Message: "Priority must be between 1 and 10"}
+	ErrTaskNotPending  = &Errno{Code: 20031, Message: "Only pending tasks can be reprioritized"}
+
+	// 源文件缓存相关错误码
+	ErrSourceCacheDisabled = &Errno{Code: 20032, Message: "Source cache is disabled"}
+
+	// 任务标签相关错误码
+	ErrInvalidLabels = &Errno{Code: 20033, Message: "Invalid task labels"}
+
+	// HLS 诊断相关错误码
+	ErrHLSJobNotFound = &Errno{Code: 20034, Message: "HLS job not found"}
+	ErrHLSJobNotReady = &Errno{Code: 20035, Message: "HLS job has not completed"}
+
+	// 预览任务相关错误码
+	ErrInvalidPreview = &Errno{Code: 20036, Message: "preview_seconds must be positive, within transcode.preview.max_seconds and requires preview=true"}
+
+	// 输入流选择相关错误码
+	ErrInvalidStreamSelection = &Errno{Code: 20037, Message: "Selected input stream does not exist or has the wrong type"}
+
+	// 自动编码配置相关错误码
+	ErrInvalidProfile = &Errno{Code: 20038, Message: "profile must be empty or auto"}
+
+	// 告警通知相关错误码
+	ErrNotificationsDisabled       = &Errno{Code: 20039, Message: "Notifications are disabled or no channel is configured"}
+	ErrNotificationChannelNotFound = &Errno{Code: 20040, Message: "Notification channel not found"}
+
+	// 任务备注相关错误码
+	ErrInvalidTaskNote = &Errno{Code: 20041, Message: "Task note requires author (<=128 chars) and body (<=2000 chars)"}
+
+	// 任务重放相关错误码
+	ErrTaskNotReplayable = &Errno{Code: 20042, Message: "Only completed or failed tasks can be replayed"}
+	ErrReplayRateLimited = &Errno{Code: 20043, Message: "Too many replays, try again later"}
+
+	// HLS 码流重试相关错误码
+	ErrHLSJobNotRetryable = &Errno{Code: 20044, Message: "Only failed HLS jobs can be retried"}
+
+	// 纯音频产物相关错误码
+	ErrInvalidAudioOutput = &Errno{Code: 20045, Message: "Invalid audio_outputs: format must be m4a|mp3 (at most 4) and audio_tags limited to known keys"}
+
+	// 截帧封面相关错误码
+	ErrInvalidThumbnail = &Errno{Code: 20046, Message: "Invalid thumbnail: timestamp_ms must be >= 0, width/height within 0-3840, format jpeg|webp, source rendition|source"}
+	ErrThumbnailFailed  = &Errno{Code: 20047, Message: "Thumbnail extraction failed"}
+
+	// 输出档位集合相关错误码
+	ErrInvalidFormatSet   = &Errno{Code: 20048, Message: "Invalid format_set or max_renditions: format_set must be defined in transcode.format_sets and max_renditions within 0-16"}
+	ErrEncryptionDisabled = &Errno{Code: 20049, Message: "Field encryption is disabled"}
+
+	// 输入上限相关错误码
+	ErrInputRejected = &Errno{Code: 20050, Message: "Input exceeds configured limits"}
+
+	// 压测相关错误码
+	ErrBenchmarkDisabled   = &Errno{Code: 20051, Message: "Benchmark mode is disabled"}
+	ErrBenchmarkRunning    = &Errno{Code: 20052, Message: "A benchmark is already running on this instance"}
+	ErrBenchmarkNotRunning = &Errno{Code: 20053, Message: "No benchmark is running or has been run on this instance"}
+	ErrInvalidBenchmark    = &Errno{Code: 20054, Message: "Invalid benchmark: resolutions must be known, source_seconds within 1-600, rate_per_minute within 0-600 and duration within benchmark.max_duration"}
+
+	// 资源采样相关错误码
+	ErrResourceTraceNotFound = &Errno{Code: 20055, Message: "No resource samples recorded for this task"}
+
+	// 分层存储相关错误码
+	ErrOutputNotArchived = &Errno{Code: 20056, Message: "Task output is not archived"}
+
+	// 私有产物相关错误码
+	ErrInvalidVisibility      = &Errno{Code: 20057, Message: "Invalid visibility: must be public or private"}
+	ErrPrivateOutputsDisabled = &Errno{Code: 20058, Message: "Private outputs require private_outputs.key_secret and sign_secret"}
+
+	// 剖析采集相关错误码
+	ErrProfileCaptureRunning = &Errno{Code: 20059, Message: "A profile capture is already running on this instance"}
+	ErrInvalidProfileCapture = &Errno{Code: 20060, Message: "Invalid capture: kind must be trace, cpu, heap, allocs or goroutine and seconds within diagnostics.max_capture_seconds"}
+
+	// worker 相关错误码
+	ErrWorkerNotFound      = &Errno{Code: 20061, Message: "Worker not found: not live and no assignments within worker.presence.history"}
+	ErrWorkerStillLive     = &Errno{Code: 20062, Message: "Worker is still live: stop the instance first, or pass force=true to cancel its active tasks"}
+	ErrPresenceUnavailable = &Errno{Code: 20063, Message: "Worker presence store is unavailable, cannot verify the worker has stopped"}
+
+	// 产物目标桶相关错误码
+	ErrInvalidOutputTarget = &Errno{Code: 20064, Message: "Invalid output_bucket/output_prefix: must be listed in output_targets and cannot be combined with private visibility"}
+
+	// 输入路径校验相关错误码
+	ErrUnsafeInputPath = &Errno{Code: 20065, Message: "Invalid original_path/user_uuid/video_uuid: absolute paths, '..' segments, backslashes and control characters are not allowed"}
+
+	// 任务组相关错误码
+	ErrTaskGroupNotFound = &Errno{Code: 20066, Message: "Task group not found"}
+	ErrInvalidTaskGroup  = &Errno{Code: 20067, Message: "Invalid task group: name up to 128 characters, callback_url must be http(s) on an allowed host, task count within task_groups.max_tasks"}
+	ErrTaskGroupClosed   = &Errno{Code: 20068, Message: "Task group is completed or belongs to another user, tasks can no longer be added"}
+
+	// HLS master 重新生成相关错误码
+	ErrHLSMasterNotRegenerable = &Errno{Code: 20069, Message: "Master playlist can only be regenerated for completed HLS jobs"}
+
+	// 编码卡死相关错误码
+	ErrEncodeStalled = &Errno{Code: 20070, Message: "STALLED: ffmpeg made no progress within the stall window"}
+
+	// 源文件解密相关错误码
+	ErrSourceDecryptFailed = &Errno{Code: 20071, Message: "DECRYPT: encrypted source could not be decrypted"}
 )
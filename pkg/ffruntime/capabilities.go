@@ -0,0 +1,147 @@
+package ffruntime
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// detectTimeout 单次 ffmpeg 能力查询超时
+const detectTimeout = 10 * time.Second
+
+// Capabilities 某个 ffmpeg 可执行文件支持的编码器、滤镜与硬件加速方式
+type Capabilities struct {
+	Binary     string    `json:"binary"`
+	Arch       string    `json:"arch"`
+	Version    string    `json:"version"`
+	Encoders   []string  `json:"encoders"`
+	Filters    []string  `json:"filters"`
+	HWAccels   []string  `json:"hwaccels"`
+	DetectedAt time.Time `json:"detected_at"`
+
+	encoders map[string]struct{}
+	filters  map[string]struct{}
+	hwaccels map[string]struct{}
+}
+
+func (c *Capabilities) HasEncoder(name string) bool {
+	_, ok := c.encoders[strings.ToLower(name)]
+	return ok
+}
+
+func (c *Capabilities) HasFilter(name string) bool {
+	_, ok := c.filters[strings.ToLower(name)]
+	return ok
+}
+
+func (c *Capabilities) HasHWAccel(name string) bool {
+	_, ok := c.hwaccels[strings.ToLower(name)]
+	return ok
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = make(map[string]*Capabilities)
+)
+
+// Detect 查询 ffmpeg 能力，结果按 binary 缓存，进程内只执行一次
+func Detect(ctx context.Context, binary string) (*Capabilities, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if caps, ok := cache[binary]; ok {
+		return caps, nil
+	}
+
+	caps := &Capabilities{Binary: binary, Arch: runtime.GOARCH, DetectedAt: time.Now()}
+	out, err := run(ctx, binary, "-version")
+	if err != nil {
+		return nil, err
+	}
+	if line, _, _ := strings.Cut(string(out), "\n"); line != "" {
+		caps.Version = strings.TrimSpace(line)
+	}
+	if out, err = run(ctx, binary, "-encoders"); err != nil {
+		return nil, err
+	}
+	caps.Encoders, caps.encoders = parseListing(out)
+	if out, err = run(ctx, binary, "-filters"); err != nil {
+		return nil, err
+	}
+	caps.Filters, caps.filters = parseListing(out)
+	if out, err = run(ctx, binary, "-hwaccels"); err != nil {
+		return nil, err
+	}
+	caps.HWAccels, caps.hwaccels = parseHWAccels(out)
+
+	cache[binary] = caps
+	return caps, nil
+}
+
+func run(ctx context.Context, binary string, arg string) ([]byte, error) {
+	runCtx, cancel := context.WithTimeout(ctx, detectTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(runCtx, binary, "-hide_banner", arg).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", binary, arg, err)
+	}
+	return out, nil
+}
+
+// parseListing 解析 -encoders/-filters 输出：每行 "<flags> <name> ..."，
+// 跳过标题行、" = " 图例说明与 "------" 分隔线
+func parseListing(out []byte) ([]string, map[string]struct{}) {
+	names := []string{}
+	set := make(map[string]struct{})
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := sc.Text()
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.Contains(line, " = ") || strings.HasPrefix(fields[0], "---") {
+			continue
+		}
+		name := strings.ToLower(fields[1])
+		if _, dup := set[name]; !dup {
+			set[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	return names, set
+}
+
+// parseHWAccels 解析 -hwaccels 输出：首行标题，之后每行一个方式
+func parseHWAccels(out []byte) ([]string, map[string]struct{}) {
+	names := []string{}
+	set := make(map[string]struct{})
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		name := strings.ToLower(strings.TrimSpace(sc.Text()))
+		if name == "" || strings.HasSuffix(name, ":") {
+			continue
+		}
+		set[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names, set
+}
+
+// Version ffmpeg 版本号（如 6.1.1），探测失败时返回空串；结果复用 Detect 的缓存
+func Version(ctx context.Context, binary string) string {
+	caps, err := Detect(ctx, binary)
+	if err != nil {
+		return ""
+	}
+	// 首行形如 "ffmpeg version 6.1.1-static https://... Copyright ..."
+	fields := strings.Fields(caps.Version)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "version" {
+			return fields[i+1]
+		}
+	}
+	return caps.Version
+}
@@ -0,0 +1,189 @@
+// Package fieldcrypt 数据库敏感字段加密（AES-256-GCM），密文自带密钥 ID 以支持密钥轮换
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"transcode-service/pkg/config"
+)
+
+// prefix 密文格式 enc:v1:<key_id>:<base64(nonce|ciphertext)>，不带前缀的取值视为历史明文
+const prefix = "enc:v1:"
+
+// keySize AES-256 密钥长度
+const keySize = 32
+
+// ErrNoKeyring 读到密文但未配置密钥
+var ErrNoKeyring = errors.New("fieldcrypt: encrypted value found but no keyring is configured")
+
+// Keyring 一组可解密的密钥，新写入使用 primary
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring 按 keyID -> 32 字节密钥创建，primary 必须在 keys 中
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("fieldcrypt: primary key %q is not defined", primary)
+	}
+	k := &Keyring{primary: primary, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("fieldcrypt: invalid key id %q", id)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("fieldcrypt: key %q must be %d bytes, got %d", id, keySize, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.aeads[id] = aead
+	}
+	return k, nil
+}
+
+// PrimaryKeyID 新写入使用的密钥 ID
+func (k *Keyring) PrimaryKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.primary
+}
+
+// Encrypt 用 primary 密钥加密；未配置密钥时原样返回明文
+func (k *Keyring) Encrypt(plain string) (string, error) {
+	if k == nil || plain == "" {
+		return plain, nil
+	}
+	aead := k.aeads[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("fieldcrypt: read nonce: %w", err)
+	}
+	// 密钥 ID 作为附加数据参与认证，防止密文被改挂到其他密钥下
+	sealed := aead.Seal(nonce, nonce, []byte(plain), []byte(k.primary))
+	return prefix + k.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密密文，不带前缀的历史明文原样返回
+func (k *Keyring) Decrypt(value string) (string, error) {
+	id, payload, ok := split(value)
+	if !ok {
+		return value, nil
+	}
+	if k == nil {
+		return "", ErrNoKeyring
+	}
+	aead, found := k.aeads[id]
+	if !found {
+		return "", fmt.Errorf("fieldcrypt: unknown key id %q", id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("fieldcrypt: malformed ciphertext for key %q", id)
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt: decrypt with key %q: %w", id, err)
+	}
+	return string(plain), nil
+}
+
+// NeedsRotation 取值是明文或未使用 primary 密钥加密时返回 true；未配置密钥时不轮换
+func (k *Keyring) NeedsRotation(value string) bool {
+	if k == nil || value == "" {
+		return false
+	}
+	id, _, ok := split(value)
+	return !ok || id != k.primary
+}
+
+// KeyID 密文使用的密钥 ID，明文返回空串
+func KeyID(value string) string {
+	id, _, _ := split(value)
+	return id
+}
+
+func split(value string) (id, payload string, ok bool) {
+	if !strings.HasPrefix(value, prefix) {
+		return "", "", false
+	}
+	id, payload, ok = strings.Cut(value[len(prefix):], ":")
+	return id, payload, ok
+}
+
+var (
+	mu           sync.RWMutex
+	current      *Keyring
+	writeEnabled bool // 新写入是否加密
+)
+
+// Configure 按配置创建全局密钥；enabled=false 且未配置密钥时不加密也不解密。
+// enabled=false 但保留了 keys 时新写入为明文，历史密文仍可解密
+func Configure(cfg config.EncryptionConfig) error {
+	keys := make(map[string][]byte, len(cfg.Keys))
+	for _, kc := range cfg.Keys {
+		secret := kc.Secret
+		if kc.SecretEnv != "" {
+			secret = os.Getenv(kc.SecretEnv)
+		}
+		if secret == "" {
+			return fmt.Errorf("fieldcrypt: key %q has no secret", kc.ID)
+		}
+		raw, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil {
+			return fmt.Errorf("fieldcrypt: key %q is not valid base64: %w", kc.ID, err)
+		}
+		if _, dup := keys[kc.ID]; dup {
+			return fmt.Errorf("fieldcrypt: duplicate key id %q", kc.ID)
+		}
+		keys[kc.ID] = raw
+	}
+	var k *Keyring
+	if cfg.Enabled || len(keys) > 0 {
+		primary := cfg.PrimaryKeyID
+		if primary == "" && len(cfg.Keys) > 0 {
+			primary = cfg.Keys[0].ID
+		}
+		var err error
+		if k, err = NewKeyring(primary, keys); err != nil {
+			return err
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	current = k
+	writeEnabled = cfg.Enabled
+	return nil
+}
+
+// Default 全局密钥，未配置时为 nil（Keyring 的方法对 nil 安全）
+func Default() *Keyring {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Writer 新写入使用的密钥，未启用加密时为 nil（写明文）
+func Writer() *Keyring {
+	mu.RLock()
+	defer mu.RUnlock()
+	if !writeEnabled {
+		return nil
+	}
+	return current
+}
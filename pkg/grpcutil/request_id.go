@@ -8,6 +8,8 @@ import (
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 
 	"transcode-service/pkg/logger"
 )
@@ -76,17 +78,43 @@ func UnaryServerRequestIDInterceptor(ctx context.Context, req interface{}, info
 	ctx, reqID := ContextWithRequestID(ctx, RequestIDFromContext(ctx))
 	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, reqID))
 	resp, err := handler(ctx, req)
+	logServerCall(ctx, reqID, info.FullMethod, start, err)
+	return resp, err
+}
+
+// StreamServerRequestIDInterceptor 流式调用的 request_id 注入与访问日志，与一元调用一致
+func StreamServerRequestIDInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, reqID := ContextWithRequestID(ss.Context(), RequestIDFromContext(ss.Context()))
+	_ = ss.SetHeader(metadata.Pairs(requestIDMetadataKey, reqID))
+	err := handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	logServerCall(ctx, reqID, info.FullMethod, start, err)
+	return err
+}
+
+// contextServerStream 替换流的上下文，使 handler 能取到 request_id
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context { return s.ctx }
+
+func logServerCall(ctx context.Context, reqID, method string, start time.Time, err error) {
 	fields := map[string]interface{}{
 		"request_id":  reqID,
-		"method":      info.FullMethod,
+		"method":      method,
 		"kind":        "grpc_server",
+		"code":        status.Code(err).String(),
 		"duration_ms": time.Since(start).Milliseconds(),
 	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields["peer"] = p.Addr.String()
+	}
 	if err != nil {
 		fields["error"] = err.Error()
 		logger.WithFields(fields).Warn("grpc server call failed")
 	} else {
 		logger.WithFields(fields).Info("grpc server call")
 	}
-	return resp, err
 }
@@ -0,0 +1,208 @@
+package grpcutil
+
+import (
+	"context"
+	"crypto/subtle"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+)
+
+const authorizationMetadataKey = "authorization"
+
+// healthMethodPrefix 健康检查不要求认证
+const healthMethodPrefix = "/grpc.health.v1.Health/"
+
+// ServerOptions 标准服务端选项：消息大小限制 + 拦截器链，一元与流式调用相同：
+// request-id/访问日志 -> 指标 -> panic 恢复 -> 认证
+func ServerOptions(cfg config.GRPCServerConfig) []grpc.ServerOption {
+	auth := newTokenAuth(cfg.ResolvedAuthTokens())
+	if len(auth.tokens) == 0 {
+		logger.Warnf("grpc server authentication DISABLED: grpc_server.auth_tokens and auth_tokens_env are empty, any caller can submit and cancel tasks")
+		metrics.Set("grpc_server_auth_enabled", 0)
+	} else {
+		metrics.Set("grpc_server_auth_enabled", 1)
+	}
+	metrics.RegisterCollector("grpc_server", defaultServerStats.samples)
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			UnaryServerRequestIDInterceptor,
+			unaryServerMetricsInterceptor,
+			unaryServerRecoveryInterceptor,
+			auth.unary,
+		),
+		grpc.ChainStreamInterceptor(
+			StreamServerRequestIDInterceptor,
+			streamServerMetricsInterceptor,
+			streamServerRecoveryInterceptor,
+			auth.stream,
+		),
+	}
+	if cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	return opts
+}
+
+// unaryServerMetricsInterceptor 按方法与状态码统计调用次数与耗时（/metrics）
+func unaryServerMetricsInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	defaultServerStats.observe(info.FullMethod, status.Code(err), time.Since(start))
+	return resp, err
+}
+
+func streamServerMetricsInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	defaultServerStats.observe(info.FullMethod, status.Code(err), time.Since(start))
+	return err
+}
+
+// serverStats 按 {method, code} 聚合的调用次数与耗时，以带标签样本导出到 /metrics
+type serverStats struct {
+	mu    sync.Mutex
+	calls map[serverCallKey]*serverCallStat
+}
+
+type serverCallKey struct {
+	method string
+	code   codes.Code
+}
+
+type serverCallStat struct {
+	count   int64
+	seconds float64
+}
+
+var defaultServerStats = &serverStats{calls: map[serverCallKey]*serverCallStat{}}
+
+func (s *serverStats) observe(method string, code codes.Code, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := serverCallKey{method: method, code: code}
+	st, ok := s.calls[key]
+	if !ok {
+		st = &serverCallStat{}
+		s.calls[key] = st
+	}
+	st.count++
+	st.seconds += d.Seconds()
+}
+
+// samples grpc_server_handled_total 与 grpc_server_handling_seconds_sum，标签 method（完整方法名）与 code
+func (s *serverStats) samples() []metrics.Sample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]serverCallKey, 0, len(s.calls))
+	for k := range s.calls {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].code < keys[j].code
+	})
+	out := make([]metrics.Sample, 0, 2*len(keys))
+	for _, k := range keys {
+		labels := map[string]string{"method": k.method, "code": k.code.String()}
+		out = append(out, metrics.Sample{Name: "grpc_server_handled_total", Labels: labels, Value: float64(s.calls[k].count)})
+	}
+	for _, k := range keys {
+		labels := map[string]string{"method": k.method, "code": k.code.String()}
+		out = append(out, metrics.Sample{Name: "grpc_server_handling_seconds_sum", Labels: labels, Value: s.calls[k].seconds})
+	}
+	return out
+}
+
+// unaryServerRecoveryInterceptor 将 handler panic 转为 Internal 错误，记录堆栈并计数供告警
+func unaryServerRecoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(ctx, info.FullMethod, r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func streamServerRecoveryInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(ss.Context(), info.FullMethod, r)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func recovered(ctx context.Context, method string, r interface{}) error {
+	metrics.Add("grpc_server_panics_total", 1)
+	logger.WithFields(map[string]interface{}{
+		"request_id": RequestIDFromContext(ctx),
+		"method":     method,
+		"kind":       "grpc_server",
+		"panic":      r,
+		"stack":      string(debug.Stack()),
+	}).Error("grpc server handler panic")
+	return status.Error(codes.Internal, "internal error")
+}
+
+// tokenAuth 校验调用方在 metadata 中携带的服务令牌
+type tokenAuth struct {
+	tokens [][]byte
+}
+
+func newTokenAuth(tokens []string) *tokenAuth {
+	a := &tokenAuth{}
+	for _, t := range tokens {
+		if t = strings.TrimSpace(t); t != "" {
+			a.tokens = append(a.tokens, []byte(t))
+		}
+	}
+	return a
+}
+
+func (a *tokenAuth) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *tokenAuth) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.check(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func (a *tokenAuth) check(ctx context.Context, method string) error {
+	if len(a.tokens) == 0 || strings.HasPrefix(method, healthMethodPrefix) {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get(authorizationMetadataKey) {
+		token := []byte(strings.TrimSpace(strings.TrimPrefix(v, "Bearer ")))
+		for _, want := range a.tokens {
+			if subtle.ConstantTimeCompare(token, want) == 1 {
+				return nil
+			}
+		}
+	}
+	metrics.Add("grpc_server_unauthenticated_total", 1)
+	return status.Error(codes.Unauthenticated, "missing or invalid service token")
+}
@@ -0,0 +1,164 @@
+// Package hlsname HLS 码流播放列表与切片的文件名模板。
+// 模板可用 {rendition}（码流分辨率，如 720p）、{index}（切片序号，三位补零）与 {job}（作业 UUID）
+package hlsname
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	TokenRendition = "{rendition}"
+	TokenIndex     = "{index}"
+	TokenJob       = "{job}"
+
+	// DefaultPlaylist 默认码流播放列表名，与历史作业的固定命名一致
+	DefaultPlaylist = "playlist_{rendition}.m3u8"
+	// DefaultSegment 默认切片名
+	DefaultSegment = "segment_{rendition}_{index}.ts"
+
+	masterPlaylist = "master.m3u8"
+	indexFormat    = "%03d"
+)
+
+// Naming 已校验的命名模板
+type Naming struct {
+	playlist string
+	segment  string
+}
+
+var (
+	defaultNaming = &Naming{playlist: DefaultPlaylist, segment: DefaultSegment}
+	parsed        sync.Map // playlist + "\x00" + segment -> *Naming
+)
+
+// Default 默认命名
+func Default() *Naming { return defaultNaming }
+
+// Parse 校验并返回命名模板，空串使用默认模板；校验通过的结果按模板缓存
+func Parse(playlist, segment string) (*Naming, error) {
+	if playlist == "" {
+		playlist = DefaultPlaylist
+	}
+	if segment == "" {
+		segment = DefaultSegment
+	}
+	key := playlist + "\x00" + segment
+	if n, ok := parsed.Load(key); ok {
+		return n.(*Naming), nil
+	}
+	if err := validate("playlist_name", playlist, ".m3u8", false); err != nil {
+		return nil, err
+	}
+	if err := validate("segment_name", segment, ".ts", true); err != nil {
+		return nil, err
+	}
+	n := &Naming{playlist: playlist, segment: segment}
+	parsed.Store(key, n)
+	return n, nil
+}
+
+// validate 模板须为单层文件名、只含已知占位符且各占位符至多一次；{rendition} 必填，
+// 切片模板必须含 {index} 且不能与 {rendition} 相邻，保证不同码流的文件名互不重叠
+func validate(field, tmpl, ext string, segment bool) error {
+	switch {
+	case strings.TrimSpace(tmpl) != tmpl:
+		return fmt.Errorf("%s %q must not have leading or trailing spaces", field, tmpl)
+	case strings.ContainsAny(tmpl, `/\%`) || strings.Contains(tmpl, ".."):
+		return fmt.Errorf("%s %q must be a plain file name without '/', '\\', '%%' or '..'", field, tmpl)
+	case strings.HasPrefix(tmpl, "."):
+		return fmt.Errorf("%s %q must not start with '.'", field, tmpl)
+	case strings.IndexFunc(tmpl, unicode.IsControl) >= 0:
+		return fmt.Errorf("%s %q must not contain control characters", field, tmpl)
+	case !strings.HasSuffix(tmpl, ext):
+		return fmt.Errorf("%s %q must end with %s", field, tmpl, ext)
+	}
+	rest := tmpl
+	for _, token := range []string{TokenRendition, TokenIndex, TokenJob} {
+		if strings.Count(tmpl, token) > 1 {
+			return fmt.Errorf("%s %q uses %s more than once", field, tmpl, token)
+		}
+		rest = strings.ReplaceAll(rest, token, "")
+	}
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("%s %q has an unknown placeholder, allowed: %s %s %s", field, tmpl, TokenRendition, TokenIndex, TokenJob)
+	}
+	if !strings.Contains(tmpl, TokenRendition) {
+		return fmt.Errorf("%s %q must contain %s", field, tmpl, TokenRendition)
+	}
+	if !segment {
+		if strings.Contains(tmpl, TokenIndex) {
+			return fmt.Errorf("%s %q must not contain %s", field, tmpl, TokenIndex)
+		}
+		if tmpl == masterPlaylist {
+			return fmt.Errorf("%s must differ from %s", field, masterPlaylist)
+		}
+		return nil
+	}
+	if !strings.Contains(tmpl, TokenIndex) {
+		return fmt.Errorf("%s %q must contain %s", field, tmpl, TokenIndex)
+	}
+	if strings.Contains(tmpl, TokenRendition+TokenIndex) || strings.Contains(tmpl, TokenIndex+TokenRendition) {
+		return fmt.Errorf("%s %q must separate %s and %s", field, tmpl, TokenRendition, TokenIndex)
+	}
+	return nil
+}
+
+// Playlist 码流播放列表文件名
+func (n *Naming) Playlist(rendition, job string) string {
+	return render(n.playlist, rendition, job)
+}
+
+// SegmentPattern 供 ffmpeg -hls_segment_filename 使用的切片名，{index} 替换为 %03d
+func (n *Naming) SegmentPattern(rendition, job string) string {
+	return strings.Replace(render(n.segment, rendition, job), TokenIndex, indexFormat, 1)
+}
+
+// Segment 第 index 个切片的文件名
+func (n *Naming) Segment(rendition, job string, index int) string {
+	return fmt.Sprintf(n.SegmentPattern(rendition, job), index)
+}
+
+// IsSegment 文件名是否为该码流的切片
+func (n *Naming) IsSegment(name, rendition, job string) bool {
+	before, after, _ := strings.Cut(render(n.segment, rendition, job), TokenIndex)
+	if len(name) <= len(before)+len(after) || !strings.HasPrefix(name, before) || !strings.HasSuffix(name, after) {
+		return false
+	}
+	for _, c := range name[len(before) : len(name)-len(after)] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsRenditionFile 文件名是否属于该码流（播放列表或切片）
+func (n *Naming) IsRenditionFile(name, rendition, job string) bool {
+	return name == n.Playlist(rendition, job) || n.IsSegment(name, rendition, job)
+}
+
+// CheckUnique 校验给定码流生成的文件名互不归属：任一码流的播放列表与切片不会被识别为另一码流的文件
+func (n *Naming) CheckUnique(renditions []string) error {
+	const job = "job"
+	for _, a := range renditions {
+		names := []string{n.Playlist(a, job), n.Segment(a, job, 0), n.Segment(a, job, 1000)}
+		for _, b := range renditions {
+			if a == b {
+				continue
+			}
+			for _, name := range names {
+				if n.IsRenditionFile(name, b, job) {
+					return fmt.Errorf("renditions %s and %s produce conflicting file name %s", a, b, name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func render(tmpl, rendition, job string) string {
+	return strings.NewReplacer(TokenRendition, rendition, TokenJob, job).Replace(tmpl)
+}
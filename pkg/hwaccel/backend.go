@@ -0,0 +1,219 @@
+package hwaccel
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Capabilities 本机 ffmpeg 的编码器/滤镜/硬件加速能力，由 ffruntime.Detect 提供
+type Capabilities interface {
+	HasEncoder(name string) bool
+	HasFilter(name string) bool
+	HasHWAccel(name string) bool
+}
+
+// DecodeOptions 硬件解码参数
+type DecodeOptions struct {
+	Device   string // 设备路径或序号，为空时使用后端默认设备
+	Surfaces int    // 解码缓冲帧数，仅 cuvid 解码器支持
+	Threads  int    // 解码线程数
+}
+
+// Backend 一种硬件加速方式：解码参数、缩放滤镜与所需的 ffmpeg 能力
+type Backend interface {
+	// Name transcode.ffmpeg.hardware_accel 的取值
+	Name() string
+	// Encodes 编码器是否属于该后端，如 cuda 对应 h264_nvenc/hevc_nvenc
+	Encodes(codec string) bool
+	// Decodes 是否支持硬件解码
+	Decodes() bool
+	// DecodeArgs 硬件解码的输入参数，解码后帧留在设备上；inputCodec 为空时由 -hwaccel 自动选择解码器
+	DecodeArgs(inputCodec string, opts DecodeOptions) []string
+	// UploadArgs 软件解码时把帧上传到设备所需的输入参数（初始化滤镜设备）
+	UploadArgs(device string) []string
+	// ScaleFilter 设备上的缩放滤镜，width 可为 -2 保持宽高比；onDevice=false 时先上传帧；
+	// 返回空串表示该后端在内存中缩放（编码器直接接收内存帧）
+	ScaleFilter(width, height int, onDevice bool) string
+	// Missing 使用该后端缺少的 ffmpeg 能力，空串表示可用
+	Missing(caps Capabilities, codec string, hwDecode bool) string
+}
+
+// backend 各后端共用实现，差异由字段描述
+type backend struct {
+	name          string
+	hwaccel       string            // -hwaccel 取值，为空表示不支持硬件解码
+	outputFormat  string            // -hwaccel_output_format，帧留在设备上
+	deviceFlag    string            // 指定解码设备的参数，如 -hwaccel_device
+	defaultDevice string            // 未配置 hardware_device 时使用的设备
+	decoders      map[string]string // 输入编码 -> 专用解码器
+	surfaces      bool              // 解码器支持 -surfaces
+	encoderSuffix string            // 编码器名后缀，如 _nvenc
+	scale         string            // 设备缩放滤镜模板（宽、高），为空表示在内存中缩放
+	upload        string            // 内存帧上传到设备的滤镜
+	initDevice    string            // 上传前初始化设备的 -init_hw_device 类型
+	filters       []string          // 设备缩放链路需要的滤镜
+}
+
+func (b *backend) Name() string { return b.name }
+
+func (b *backend) Encodes(codec string) bool {
+	return strings.HasSuffix(strings.ToLower(strings.TrimSpace(codec)), b.encoderSuffix)
+}
+
+func (b *backend) Decodes() bool { return b.hwaccel != "" }
+
+func (b *backend) DecodeArgs(inputCodec string, opts DecodeOptions) []string {
+	if b.hwaccel == "" {
+		return nil
+	}
+	args := []string{"-hwaccel", b.hwaccel}
+	if device := b.device(opts.Device); device != "" && b.deviceFlag != "" {
+		args = append(args, b.deviceFlag, device)
+	}
+	if b.outputFormat != "" {
+		args = append(args, "-hwaccel_output_format", b.outputFormat)
+	}
+	if dec, ok := b.decoders[normalizeCodec(inputCodec)]; ok {
+		args = append(args, "-c:v", dec)
+		if b.surfaces && opts.Surfaces > 0 {
+			args = append(args, "-surfaces", strconv.Itoa(opts.Surfaces))
+		}
+	}
+	threads := opts.Threads
+	if threads <= 0 {
+		threads = 1
+	}
+	return append(args, "-threads", strconv.Itoa(threads))
+}
+
+func (b *backend) UploadArgs(device string) []string {
+	if b.scale == "" || b.initDevice == "" {
+		return nil
+	}
+	spec := b.initDevice + "=hw"
+	if device = b.device(device); device != "" {
+		spec += ":" + device
+	}
+	return []string{"-init_hw_device", spec, "-filter_hw_device", "hw"}
+}
+
+func (b *backend) ScaleFilter(width, height int, onDevice bool) string {
+	if b.scale == "" || height <= 0 {
+		return ""
+	}
+	filter := fmt.Sprintf(b.scale, width, height)
+	if !onDevice {
+		filter = b.upload + "," + filter
+	}
+	return filter
+}
+
+func (b *backend) Missing(caps Capabilities, codec string, hwDecode bool) string {
+	switch {
+	case !b.Encodes(codec):
+		return strings.TrimPrefix(b.encoderSuffix, "_") + " encoder"
+	case b.hwaccel != "" && (hwDecode || b.scale != "") && !caps.HasHWAccel(b.hwaccel):
+		return b.hwaccel + " hwaccel"
+	}
+	for _, f := range b.filters {
+		if !caps.HasFilter(f) {
+			return f + " filter"
+		}
+	}
+	if b.scale != "" && !hwDecode && !caps.HasFilter(uploadFilter(b.upload)) {
+		return uploadFilter(b.upload) + " filter"
+	}
+	return ""
+}
+
+func (b *backend) device(device string) string {
+	if device = strings.TrimSpace(device); device != "" {
+		return device
+	}
+	return b.defaultDevice
+}
+
+// uploadFilter 上传滤镜链中真正执行上传的滤镜名，如 "format=nv12,hwupload" 中的 hwupload
+func uploadFilter(chain string) string {
+	parts := strings.Split(chain, ",")
+	name, _, _ := strings.Cut(parts[len(parts)-1], "=")
+	return name
+}
+
+func normalizeCodec(codec string) string {
+	switch strings.ToLower(strings.TrimSpace(codec)) {
+	case "h264", "avc1":
+		return "h264"
+	case "hevc", "hvc1", "hev1":
+		return "hevc"
+	default:
+		return ""
+	}
+}
+
+var backends = map[string]Backend{
+	"cuda": &backend{
+		name: "cuda", hwaccel: "cuda", outputFormat: "cuda",
+		decoders:      map[string]string{"h264": "h264_cuvid", "hevc": "hevc_cuvid"},
+		surfaces:      true,
+		encoderSuffix: "_nvenc",
+		scale:         "scale_npp=%d:%d:format=yuv420p",
+		upload:        "hwupload_cuda",
+		initDevice:    "cuda",
+		filters:       []string{"scale_npp"},
+	},
+	"qsv": &backend{
+		name: "qsv", hwaccel: "qsv", outputFormat: "qsv",
+		decoders:      map[string]string{"h264": "h264_qsv", "hevc": "hevc_qsv"},
+		encoderSuffix: "_qsv",
+		scale:         "scale_qsv=w=%d:h=%d:format=nv12",
+		upload:        "format=nv12,hwupload=extra_hw_frames=64",
+		initDevice:    "qsv",
+		filters:       []string{"scale_qsv"},
+	},
+	"vaapi": &backend{
+		name: "vaapi", hwaccel: "vaapi", outputFormat: "vaapi",
+		deviceFlag: "-hwaccel_device", defaultDevice: "/dev/dri/renderD128",
+		encoderSuffix: "_vaapi",
+		scale:         "scale_vaapi=w=%d:h=%d:format=nv12",
+		upload:        "format=nv12,hwupload",
+		initDevice:    "vaapi",
+		filters:       []string{"scale_vaapi"},
+	},
+	// AMF 编码器直接接收内存帧，解码与缩放在 CPU 上完成
+	"amf": &backend{name: "amf", encoderSuffix: "_amf"},
+	// VideoToolbox 解码后帧回到内存，缩放在 CPU 上完成
+	"videotoolbox": &backend{name: "videotoolbox", hwaccel: "videotoolbox", encoderSuffix: "_videotoolbox"},
+}
+
+// Lookup 按 hardware_accel 取后端，未注册的取值返回 nil（按通用 -hwaccel 处理）
+func Lookup(name string) Backend {
+	return backends[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// ForEncoder 编码器所属的后端，软件编码器返回 nil
+func ForEncoder(codec string) Backend {
+	for _, b := range backends {
+		if b.Encodes(codec) {
+			return b
+		}
+	}
+	return nil
+}
+
+// IsHardwareEncoder 是否为硬件编码器（不支持 -crf 等软件编码参数）
+func IsHardwareEncoder(codec string) bool {
+	return ForEncoder(codec) != nil
+}
+
+// Names 已注册的后端名，按字母序
+func Names() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -108,6 +108,29 @@ func (c *Client) Reader(topic, groupID string) *kafka.Reader {
 	})
 }
 
+// ConsumerGroup 显式管理再均衡代次的消费组：每代分配的分区由调用方逐个读取并通过 Generation 提交位点
+func (c *Client) ConsumerGroup(topic, groupID string) (*kafka.ConsumerGroup, error) {
+	logger.Infof("Kafka consumer group created topic=%s group=%s brokers=%v", topic, groupID, c.brokers)
+	return kafka.NewConsumerGroup(kafka.ConsumerGroupConfig{
+		ID:      groupID,
+		Brokers: c.brokers,
+		Topics:  []string{topic},
+		Dialer:  c.dialer,
+	})
+}
+
+// PartitionReader 读取单个分区，不加入消费组；位点由消费组代次提交
+func (c *Client) PartitionReader(topic string, partition int) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   c.brokers,
+		Topic:     topic,
+		Partition: partition,
+		Dialer:    c.dialer,
+		MinBytes:  1,
+		MaxBytes:  10 << 20,
+	})
+}
+
 // EnsureTopic creates the topic if it does not exist.
 func (c *Client) EnsureTopic(topic string, numPartitions, replicationFactor int) error {
 	if len(c.brokers) == 0 {
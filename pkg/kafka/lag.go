@@ -0,0 +1,220 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// 消费积压级别
+const (
+	LagLevelOK       = "ok"
+	LagLevelWarn     = "warn"
+	LagLevelCritical = "critical"
+)
+
+// PartitionLag 单个分区的消费积压
+type PartitionLag struct {
+	Partition       int   `json:"partition"`
+	CommittedOffset int64 `json:"committed_offset"`
+	LatestOffset    int64 `json:"latest_offset"`
+	Lag             int64 `json:"lag"`
+}
+
+// GroupLag 消费组在指定 topic 上的积压快照
+type GroupLag struct {
+	Topic      string         `json:"topic"`
+	GroupID    string         `json:"group_id"`
+	Partitions []PartitionLag `json:"partitions"`
+	TotalLag   int64          `json:"total_lag"`
+	Level      string         `json:"level"`
+	CheckedAt  time.Time      `json:"checked_at"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// GroupLag 通过 OffsetFetch + ListOffsets 计算消费组各分区积压
+func (c *Client) GroupLag(ctx context.Context, topic, groupID string) (*GroupLag, error) {
+	if len(c.brokers) == 0 {
+		return nil, fmt.Errorf("kafka client not opened")
+	}
+	admin := &kafka.Client{
+		Addr:      kafka.TCP(c.brokers...),
+		Timeout:   10 * time.Second,
+		Transport: &kafka.Transport{ClientID: c.clientID, Dial: c.dialer.DialFunc},
+	}
+	meta, err := admin.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, fmt.Errorf("fetch metadata: %w", err)
+	}
+	var partitions []int
+	for _, t := range meta.Topics {
+		if t.Name != topic {
+			continue
+		}
+		if t.Error != nil {
+			return nil, fmt.Errorf("topic metadata: %w", t.Error)
+		}
+		for _, p := range t.Partitions {
+			partitions = append(partitions, p.ID)
+		}
+	}
+	sort.Ints(partitions)
+
+	committed, err := admin.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: groupID, Topics: map[string][]int{topic: partitions}})
+	if err != nil {
+		return nil, fmt.Errorf("fetch committed offsets: %w", err)
+	}
+	if committed.Error != nil {
+		return nil, fmt.Errorf("fetch committed offsets: %w", committed.Error)
+	}
+	reqs := make([]kafka.OffsetRequest, 0, len(partitions))
+	for _, p := range partitions {
+		reqs = append(reqs, kafka.FirstOffsetOf(p), kafka.LastOffsetOf(p))
+	}
+	offsets, err := admin.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{topic: reqs}})
+	if err != nil {
+		return nil, fmt.Errorf("list offsets: %w", err)
+	}
+
+	first := make(map[int]int64, len(partitions))
+	latest := make(map[int]int64, len(partitions))
+	for _, po := range offsets.Topics[topic] {
+		first[po.Partition] = po.FirstOffset
+		latest[po.Partition] = po.LastOffset
+	}
+	result := &GroupLag{Topic: topic, GroupID: groupID, CheckedAt: time.Now()}
+	for _, cp := range committed.Topics[topic] {
+		off := cp.CommittedOffset
+		if off < 0 {
+			// 尚未提交过 offset，按最早可读位置计算积压
+			off = first[cp.Partition]
+		}
+		lag := latest[cp.Partition] - off
+		if lag < 0 {
+			lag = 0
+		}
+		result.Partitions = append(result.Partitions, PartitionLag{
+			Partition:       cp.Partition,
+			CommittedOffset: cp.CommittedOffset,
+			LatestOffset:    latest[cp.Partition],
+			Lag:             lag,
+		})
+		result.TotalLag += lag
+	}
+	sort.Slice(result.Partitions, func(i, j int) bool { return result.Partitions[i].Partition < result.Partitions[j].Partition })
+	return result, nil
+}
+
+// LagMonitor 周期性采集消费组积压，输出指标并按阈值告警
+type LagMonitor struct {
+	mu     sync.RWMutex
+	last   *GroupLag
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var (
+	lagMonitorOnce      sync.Once
+	singletonLagMonitor *LagMonitor
+)
+
+func DefaultLagMonitor() *LagMonitor {
+	lagMonitorOnce.Do(func() {
+		singletonLagMonitor = &LagMonitor{}
+	})
+	return singletonLagMonitor
+}
+
+// Snapshot 返回最近一次采集结果，尚未采集时返回 nil
+func (m *LagMonitor) Snapshot() *GroupLag {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.last
+}
+
+func (m *LagMonitor) Name() string {
+	return "kafka-lag-monitor"
+}
+
+func (m *LagMonitor) Start(ctx context.Context) error {
+	cfg := config.GetGlobalConfig()
+	if cfg == nil || !cfg.Kafka.Enabled {
+		return nil
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(cfg.Kafka.Lag.CheckInterval)
+		defer ticker.Stop()
+		for {
+			m.collect(ctx, cfg)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *LagMonitor) Stop() error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+	return nil
+}
+
+func (m *LagMonitor) collect(ctx context.Context, cfg *config.Config) {
+	topic, group := cfg.Kafka.Topics.TranscodeTasks, cfg.Kafka.GroupID
+	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	lag, err := DefaultClient().GroupLag(reqCtx, topic, group)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warnf("collect kafka lag failed topic=%s group=%s error=%v", topic, group, err)
+		lag = &GroupLag{Topic: topic, GroupID: group, CheckedAt: time.Now(), Error: err.Error()}
+		if prev := m.Snapshot(); prev != nil {
+			lag.Partitions, lag.TotalLag = prev.Partitions, prev.TotalLag
+		}
+	}
+	lag.Level = lagLevel(lag.TotalLag, cfg.Kafka.Lag)
+	m.mu.Lock()
+	m.last = lag
+	m.mu.Unlock()
+
+	metrics.Set("kafka_lag_total", lag.TotalLag)
+	for _, p := range lag.Partitions {
+		metrics.Set("kafka_lag_partition_"+strconv.Itoa(p.Partition), p.Lag)
+	}
+	switch lag.Level {
+	case LagLevelCritical:
+		logger.Errorf("kafka consumer lag critical topic=%s group=%s total_lag=%d threshold=%d", topic, group, lag.TotalLag, cfg.Kafka.Lag.CriticalThreshold)
+	case LagLevelWarn:
+		logger.Warnf("kafka consumer lag high topic=%s group=%s total_lag=%d threshold=%d", topic, group, lag.TotalLag, cfg.Kafka.Lag.WarnThreshold)
+	}
+}
+
+func lagLevel(total int64, cfg config.KafkaLagConfig) string {
+	switch {
+	case cfg.CriticalThreshold > 0 && total >= cfg.CriticalThreshold:
+		return LagLevelCritical
+	case cfg.WarnThreshold > 0 && total >= cfg.WarnThreshold:
+		return LagLevelWarn
+	default:
+		return LagLevelOK
+	}
+}
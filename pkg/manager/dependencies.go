@@ -2,6 +2,7 @@ package manager
 
 import (
 	"github.com/gin-gonic/gin"
+	"transcode-service/pkg/clock"
 	"transcode-service/pkg/config"
 	"transcode-service/pkg/kafka"
 
@@ -14,6 +15,8 @@ type Dependencies struct {
 	Config              *config.Config
 	TranscodeAppService interface{} // 转码应用服务
 	Kafka               *kafka.Client
+	Clock               clock.Clock       // 时间来源，缺省为系统时钟
+	IDs                 clock.IDGenerator // 任务/作业 ID 生成器，缺省为 UUIDv4
 }
 
 // ComponentPlugin 组件插件接口
@@ -101,6 +104,8 @@ func MustInitComponents(deps *Dependencies) {
 
 // MustInitServices 初始化所有服务
 func MustInitServices(deps *Dependencies) {
+	// 时钟与 ID 生成器先于各服务/组件安装，实体与服务统一经 pkg/clock 取时间和 ID
+	clock.Install(deps.Clock, deps.IDs)
 	for _, plugin := range servicePlugins {
 		service := plugin.MustCreateService(deps)
 		services = append(services, service)
@@ -123,16 +128,3 @@ func RegisterAllRoutes(router *gin.Engine) {
 		service.RegisterRoutes(router)
 	}
 }
-
-// Shutdown 关闭所有组件
-func Shutdown() {
-	// 逆序关闭组件
-	for i := len(components) - 1; i >= 0; i-- {
-		if err := components[i].Stop(); err != nil {
-			// 记录错误但继续关闭其他组件
-			// logger.Error("failed to stop component", map[string]interface{}{"error": err})
-		}
-	}
-	components = nil
-	services = nil
-}
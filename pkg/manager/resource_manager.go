@@ -2,6 +2,10 @@ package manager
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -22,11 +26,46 @@ type (
 		// Close 关闭资源
 		Close()
 	}
+
+	// DependentResourcePlugin 可选接口：声明依赖的其他资源插件名，依赖全部打开后才初始化
+	DependentResourcePlugin interface {
+		DependsOn() []string
+	}
+
+	// CriticalityResourcePlugin 可选接口：未实现时视为 Critical
+	CriticalityResourcePlugin interface {
+		Criticality() Criticality
+	}
+)
+
+// Criticality 资源的重要程度
+type Criticality int
+
+const (
+	// Critical 打开失败时终止启动
+	Critical Criticality = iota
+	// Optional 打开失败时降级启动，依赖它的资源一并跳过
+	Optional
 )
 
+func (c Criticality) String() string {
+	if c == Optional {
+		return "optional"
+	}
+	return "critical"
+}
+
+// DegradedResource 降级启动时未能打开的可选资源
+type DegradedResource struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
 var (
 	resourcePlugins = map[string]ResourcePlugin{}
 	resources       []Resource
+	resourcesMu     sync.Mutex
+	degraded        []DegradedResource
 )
 
 // RegisterResourcePlugin registers resource plugin
@@ -43,25 +82,185 @@ func RegisterResourcePlugin(p ResourcePlugin) {
 	resourcePlugins[p.Name()] = p
 }
 
-// MustInitResources 初始化已注册的 Resource，如果失败则 panic
+func criticalityOf(p ResourcePlugin) Criticality {
+	if c, ok := p.(CriticalityResourcePlugin); ok {
+		return c.Criticality()
+	}
+	return Critical
+}
+
+func dependsOn(p ResourcePlugin) []string {
+	if d, ok := p.(DependentResourcePlugin); ok {
+		return d.DependsOn()
+	}
+	return nil
+}
+
+// resourceLevels 按依赖关系分层：同一层内的资源互不依赖，可并行打开。依赖不存在或成环时返回错误
+func resourceLevels() ([][]string, error) {
+	pending := make(map[string][]string, len(resourcePlugins))
+	for name, p := range resourcePlugins {
+		deps := dependsOn(p)
+		for _, d := range deps {
+			if _, ok := resourcePlugins[d]; !ok {
+				return nil, fmt.Errorf("resource %s depends on unregistered resource %s", name, d)
+			}
+		}
+		pending[name] = deps
+	}
+	done := make(map[string]bool, len(pending))
+	var levels [][]string
+	for len(pending) > 0 {
+		var level []string
+		for name, deps := range pending {
+			ready := true
+			for _, d := range deps {
+				if !done[d] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, name)
+			}
+		}
+		if len(level) == 0 {
+			names := make([]string, 0, len(pending))
+			for name := range pending {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("circular resource dependency among: %s", strings.Join(names, ", "))
+		}
+		sort.Strings(level)
+		for _, name := range level {
+			done[name] = true
+			delete(pending, name)
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// openResource 创建并打开资源，把 panic 转为错误
+func openResource(p ResourcePlugin) (res Resource, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	res = p.MustCreateResource()
+	res.MustOpen()
+	return res, nil
+}
+
+// MustInitResources 按依赖分层初始化已注册的 Resource，同层并行打开。
+// Critical 资源失败（或其依赖未打开）时 panic；Optional 资源失败时记录降级并继续启动
 func MustInitResources() {
 	log.Infof("开始初始化资源插件，共有 %d 个插件", len(resourcePlugins))
-	for n, p := range resourcePlugins {
-		log.Infof("正在初始化资源插件: %s", n)
-		resource := p.MustCreateResource()
-		log.Infof("资源插件 %s 创建成功，正在打开...", n)
-		resource.MustOpen()
-		log.Infof("资源插件 %s 打开成功", n)
-		resources = append(resources, resource)
-		log.Infof("Init resource, plugin=%s, resource=%+v", n, resource)
+	levels, err := resourceLevels()
+	if err != nil {
+		panic(err)
+	}
+	failed := map[string]error{}
+	for i, level := range levels {
+		log.Infof("初始化第 %d 层资源插件: %s", i+1, strings.Join(level, ", "))
+		// 依赖未打开的资源直接跳过，在并行打开前判定，避免与本层写入并发
+		var open []string
+		for _, name := range level {
+			if blocked := blockedBy(resourcePlugins[name], failed); blocked != "" {
+				failed[name] = fmt.Errorf("dependency %s unavailable", blocked)
+				continue
+			}
+			open = append(open, name)
+		}
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		for _, name := range open {
+			p := resourcePlugins[name]
+			wg.Add(1)
+			go func(name string, p ResourcePlugin) {
+				defer wg.Done()
+				start := time.Now()
+				res, err := openResource(p)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					failed[name] = err
+					return
+				}
+				resourcesMu.Lock()
+				resources = append(resources, res)
+				resourcesMu.Unlock()
+				log.Infof("资源插件 %s 打开成功 criticality=%s took=%s", name, criticalityOf(p), time.Since(start).Round(time.Millisecond))
+			}(name, p)
+		}
+		wg.Wait()
+	}
+
+	var fatal []string
+	names := make([]string, 0, len(failed))
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		err := failed[name]
+		if criticalityOf(resourcePlugins[name]) == Critical {
+			fatal = append(fatal, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		resourcesMu.Lock()
+		degraded = append(degraded, DegradedResource{Name: name, Error: err.Error()})
+		resourcesMu.Unlock()
+		log.Warnf("可选资源插件 %s 初始化失败，降级启动 error=%v", name, err)
+	}
+	if len(fatal) > 0 {
+		panic("failed to init critical resources: " + strings.Join(fatal, "; "))
+	}
+	if len(degraded) > 0 {
+		log.Warnf("资源插件初始化完成（降级模式），不可用: %d 个", len(degraded))
+		return
 	}
 	log.Infof("所有资源插件初始化完成")
 }
 
-// CloseResources 关闭所有注册的资源
+// blockedBy 返回第一个未能打开的依赖名，依赖均已打开时返回空串
+func blockedBy(p ResourcePlugin, failed map[string]error) string {
+	for _, d := range dependsOn(p) {
+		if _, ok := failed[d]; ok {
+			return d
+		}
+	}
+	return ""
+}
+
+// DegradedResources 启动时未能打开的可选资源，为空表示全部正常
+func DegradedResources() []DegradedResource {
+	resourcesMu.Lock()
+	defer resourcesMu.Unlock()
+	return append([]DegradedResource(nil), degraded...)
+}
+
+// IsDegraded 指定资源是否因初始化失败而不可用
+func IsDegraded(name string) bool {
+	resourcesMu.Lock()
+	defer resourcesMu.Unlock()
+	for _, d := range degraded {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CloseResources 按打开的逆序关闭资源，依赖方先于被依赖方关闭
 func CloseResources() {
-	for _, resource := range resources {
-		resource.Close()
-		log.Infof("Close resource, resource=%+v", resource)
+	resourcesMu.Lock()
+	defer resourcesMu.Unlock()
+	for i := len(resources) - 1; i >= 0; i-- {
+		resources[i].Close()
+		log.Infof("Close resource, resource=%+v", resources[i])
 	}
+	resources = nil
 }
@@ -0,0 +1,217 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/metrics"
+	"transcode-service/pkg/task"
+)
+
+// Phase 停机阶段，按 PhaseIngest → PhaseDrain → PhaseFlush → PhaseResources 顺序执行
+type Phase string
+
+const (
+	PhaseIngest    Phase = "ingest"    // 停止接入：HTTP/gRPC 不再接受请求，Kafka 消费者离开消费组
+	PhaseDrain     Phase = "drain"     // 排空编码：worker 完成手上任务，随后关闭本地队列
+	PhaseFlush     Phase = "flush"     // 写出缓冲：上传池、分配记录、告警与分析导出
+	PhaseResources Phase = "resources" // 关闭资源：数据库、Redis、Kafka 等
+)
+
+var shutdownPhases = []Phase{PhaseIngest, PhaseDrain, PhaseFlush, PhaseResources}
+
+type (
+	// Phased 可选接口：组件或后台任务声明所属的停机阶段，未实现时属于 PhaseDrain
+	Phased interface {
+		ShutdownPhase() Phase
+	}
+
+	// InFlightReporter 可选接口：报告仍在处理的工作（如任务 UUID、待上传数），阶段开始与超时时写入日志
+	InFlightReporter interface {
+		InFlight() []string
+	}
+)
+
+// shutdownStep 阶段内的一个停止动作
+type shutdownStep struct {
+	name     string
+	stop     func(ctx context.Context) error
+	inFlight func() []string
+}
+
+var (
+	shutdownMu    sync.Mutex
+	shutdownHooks = map[Phase][]shutdownStep{}
+)
+
+// RegisterShutdownStep 注册停机步骤（如 HTTP/gRPC 服务停止），在该阶段的后台任务与组件之前按注册顺序执行
+func RegisterShutdownStep(phase Phase, name string, stop func(ctx context.Context) error) {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+	shutdownHooks[phase] = append(shutdownHooks[phase], shutdownStep{name: name, stop: stop})
+}
+
+// Shutdown 按阶段有序停机。阶段内依次执行：注册的步骤 → 该阶段的后台任务（注册逆序）→ 该阶段的组件（启动逆序），
+// 超过阶段超时后记录卡住的步骤与在途工作并进入下一阶段，避免入口未停时先关闭队列、排空未完成时先关闭资源
+func Shutdown(cfg config.ShutdownConfig) {
+	start := time.Now()
+	for _, phase := range shutdownPhases {
+		runPhase(phase, phaseTimeout(cfg, phase), phaseSteps(phase))
+	}
+	components = nil
+	services = nil
+	log.Infof("Shutdown finished elapsed=%s", time.Since(start).Round(time.Millisecond))
+}
+
+func phaseTimeout(cfg config.ShutdownConfig, phase Phase) time.Duration {
+	var d time.Duration
+	switch phase {
+	case PhaseIngest:
+		d = cfg.IngestTimeout
+	case PhaseDrain:
+		d = cfg.DrainTimeout
+	case PhaseFlush:
+		d = cfg.FlushTimeout
+	case PhaseResources:
+		d = cfg.ResourcesTimeout
+	}
+	if d <= 0 {
+		d = 30 * time.Second
+	}
+	return d
+}
+
+// phaseOf 组件或后台任务所属阶段
+func phaseOf(v interface{}) Phase {
+	if p, ok := v.(Phased); ok && p.ShutdownPhase() != "" {
+		return p.ShutdownPhase()
+	}
+	return PhaseDrain
+}
+
+func inFlightOf(v interface{}) func() []string {
+	if r, ok := v.(InFlightReporter); ok {
+		return r.InFlight
+	}
+	return nil
+}
+
+// phaseSteps 组装阶段内的步骤；写出阶段最后停止其余后台任务，资源阶段关闭全部资源
+func phaseSteps(phase Phase) []shutdownStep {
+	shutdownMu.Lock()
+	steps := append([]shutdownStep(nil), shutdownHooks[phase]...)
+	shutdownMu.Unlock()
+	hooks := len(steps)
+
+	for _, t := range task.Tasks() {
+		if phase == PhaseResources || phaseOf(t) != phase {
+			continue
+		}
+		t := t
+		steps = append(steps, shutdownStep{
+			name: "task:" + t.Name(),
+			stop: func(context.Context) error {
+				task.StopWhere(func(x task.BackgroundTask) bool { return x == t })
+				return nil
+			},
+			inFlight: inFlightOf(t),
+		})
+	}
+	// 后台任务按注册逆序停止（后注册的依赖先注册的，如快照依赖 worker）
+	reverseTaskSteps(steps[hooks:])
+
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		if phaseOf(c) != phase {
+			continue
+		}
+		steps = append(steps, shutdownStep{
+			name:     "component:" + c.GetName(),
+			stop:     func(context.Context) error { return c.Stop() },
+			inFlight: inFlightOf(c),
+		})
+	}
+
+	switch phase {
+	case PhaseFlush:
+		steps = append(steps, shutdownStep{name: "task:remaining", stop: func(context.Context) error { task.StopAll(); return nil }})
+	case PhaseResources:
+		steps = append(steps, shutdownStep{name: "resources", stop: func(context.Context) error { CloseResources(); return nil }})
+	}
+	return steps
+}
+
+func reverseTaskSteps(s []shutdownStep) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// runPhase 依次执行阶段内的步骤；超时后不再等待（卡住的步骤在后台继续），记录当前步骤与在途工作
+func runPhase(phase Phase, timeout time.Duration, steps []shutdownStep) {
+	if len(steps) == 0 {
+		return
+	}
+	start := time.Now()
+	if pending := collectInFlight(steps); len(pending) > 0 {
+		log.Infof("Shutdown phase started phase=%s steps=%d timeout=%s in_flight=%v", phase, len(steps), timeout, pending)
+	} else {
+		log.Infof("Shutdown phase started phase=%s steps=%d timeout=%s", phase, len(steps), timeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var (
+		mu      sync.Mutex
+		current int
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i, s := range steps {
+			mu.Lock()
+			current = i
+			mu.Unlock()
+			stepStart := time.Now()
+			if err := s.stop(ctx); err != nil {
+				log.Warnf("Shutdown step failed phase=%s step=%s elapsed=%s error=%v", phase, s.name, time.Since(stepStart).Round(time.Millisecond), err)
+				continue
+			}
+			log.Infof("Shutdown step done phase=%s step=%s elapsed=%s", phase, s.name, time.Since(stepStart).Round(time.Millisecond))
+		}
+	}()
+
+	select {
+	case <-done:
+		metrics.ObserveDuration(fmt.Sprintf("shutdown_phase_%s", phase), time.Since(start))
+		log.Infof("Shutdown phase done phase=%s elapsed=%s", phase, time.Since(start).Round(time.Millisecond))
+	case <-ctx.Done():
+		mu.Lock()
+		remaining := steps[current:]
+		mu.Unlock()
+		names := make([]string, 0, len(remaining))
+		for _, s := range remaining {
+			names = append(names, s.name)
+		}
+		metrics.Add("shutdown_phase_timeout_total", 1)
+		log.Warnf("Shutdown phase timed out, continuing phase=%s timeout=%s waiting_on=%v in_flight=%v", phase, timeout, names, collectInFlight(remaining))
+	}
+}
+
+func collectInFlight(steps []shutdownStep) []string {
+	var out []string
+	for _, s := range steps {
+		if s.inFlight == nil {
+			continue
+		}
+		for _, item := range s.inFlight() {
+			out = append(out, s.name+":"+item)
+		}
+	}
+	return out
+}
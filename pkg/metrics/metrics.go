@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"expvar"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// registry 服务级指标，通过 /debug/vars 以 JSON 暴露
+var registry = expvar.NewMap("transcode_service")
+
+// Add 计数器累加
+func Add(name string, delta int64) {
+	registry.Add(name, delta)
+}
+
+// Value 读取整型计数器/仪表的当前值，不存在时返回 0
+func Value(name string) int64 {
+	if v, ok := registry.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// Set 设置整型仪表值
+func Set(name string, value int64) {
+	v := new(expvar.Int)
+	v.Set(value)
+	registry.Set(name, v)
+}
+
+// SetFloat 设置浮点仪表值
+func SetFloat(name string, value float64) {
+	v := new(expvar.Float)
+	v.Set(value)
+	registry.Set(name, v)
+}
+
+// SetString 设置字符串信息
+func SetString(name, value string) {
+	v := new(expvar.String)
+	v.Set(value)
+	registry.Set(name, v)
+}
+
+// DurationBuckets 耗时直方图的桶上界（秒）
+var DurationBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600}
+
+// ObserveDuration 以累积桶记录耗时直方图：<name>_bucket_le_<上界秒>、<name>_bucket_le_inf、<name>_sum_ms、<name>_count
+func ObserveDuration(name string, d time.Duration) {
+	seconds := d.Seconds()
+	for _, le := range DurationBuckets {
+		if seconds <= le {
+			registry.Add(name+"_bucket_le_"+strconv.FormatFloat(le, 'f', -1, 64), 1)
+		}
+	}
+	registry.Add(name+"_bucket_le_inf", 1)
+	registry.Add(name+"_sum_ms", d.Milliseconds())
+	registry.Add(name+"_count", 1)
+}
+
+// Handler 返回 expvar 的 HTTP 处理器
+func Handler() http.Handler {
+	return expvar.Handler()
+}
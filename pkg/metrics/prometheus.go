@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// prometheusPrefix 导出到 /metrics 的指标名前缀
+const prometheusPrefix = "transcode_service_"
+
+// Sample 带标签的仪表样本，用于 expvar 无法表达的维度（如按 GPU 区分）
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+var (
+	collectorsMu sync.RWMutex
+	collectors   = map[string]func() []Sample{}
+)
+
+// RegisterCollector 注册带标签样本的采集函数，同名覆盖；每次抓取 /metrics 时调用
+func RegisterCollector(name string, fn func() []Sample) {
+	collectorsMu.Lock()
+	defer collectorsMu.Unlock()
+	collectors[name] = fn
+}
+
+// PrometheusHandler 以 Prometheus 文本格式暴露 expvar 中的整型/浮点指标与已注册的带标签样本
+func PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		var b strings.Builder
+		registry.Do(func(kv expvar.KeyValue) {
+			switch v := kv.Value.(type) {
+			case *expvar.Int:
+				fmt.Fprintf(&b, "%s%s %d\n", prometheusPrefix, metricName(kv.Key), v.Value())
+			case *expvar.Float:
+				fmt.Fprintf(&b, "%s%s %s\n", prometheusPrefix, metricName(kv.Key), strconv.FormatFloat(v.Value(), 'g', -1, 64))
+			}
+		})
+		writeSamples(&b)
+		_, _ = w.Write([]byte(b.String()))
+	})
+}
+
+func writeSamples(b *strings.Builder) {
+	collectorsMu.RLock()
+	names := make([]string, 0, len(collectors))
+	for name := range collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fns := make([]func() []Sample, 0, len(names))
+	for _, name := range names {
+		fns = append(fns, collectors[name])
+	}
+	collectorsMu.RUnlock()
+
+	typed := map[string]bool{}
+	for _, fn := range fns {
+		for _, s := range fn() {
+			name := prometheusPrefix + metricName(s.Name)
+			if !typed[name] {
+				fmt.Fprintf(b, "# TYPE %s gauge\n", name)
+				typed[name] = true
+			}
+			b.WriteString(name)
+			b.WriteString(labelString(s.Labels))
+			b.WriteString(" ")
+			b.WriteString(strconv.FormatFloat(s.Value, 'g', -1, 64))
+			b.WriteString("\n")
+		}
+	}
+}
+
+func labelString(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, metricName(k)+`="`+labelEscaper.Replace(labels[k])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricName 非 [a-zA-Z0-9_] 字符替换为下划线
+func metricName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DebugGuard 保护 /debug/pprof 等调试端点：未开启时返回 404；
+// 配置了令牌时要求 Authorization: Bearer <token> 或 X-Debug-Token，否则只允许本机（回环地址）访问
+func DebugGuard(enabled bool, token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if token == "" {
+			if ip := net.ParseIP(c.RemoteIP()); ip == nil || !ip.IsLoopback() {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+		got := c.GetHeader("X-Debug-Token")
+		if got == "" {
+			got = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
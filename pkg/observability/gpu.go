@@ -0,0 +1,123 @@
+package observability
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"transcode-service/pkg/config"
+	"transcode-service/pkg/logger"
+	"transcode-service/pkg/metrics"
+	"transcode-service/pkg/sysinfo"
+)
+
+// gpuQueryTimeout 单次 nvidia-smi 查询超时，驱动卡住时不阻塞下一轮
+const gpuQueryTimeout = 5 * time.Second
+
+var latestGPUs atomic.Pointer[[]sysinfo.GPUStat]
+
+// LatestGPUStats 最近一次采集到的 GPU 计数器；未开启采集或本机无 GPU 时为 nil
+func LatestGPUStats() []sysinfo.GPUStat {
+	if p := latestGPUs.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// GPUCollector 周期性读取 NVML 计数器（利用率、显存、编解码器占用、温度），
+// 写入 expvar 并注册带 gpu 标签的 /metrics 样本，同时供心跳携带
+type GPUCollector struct {
+	binary   string
+	interval time.Duration
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewGPUCollector 未开启或本机找不到 nvidia-smi 时返回 nil，不采集
+func NewGPUCollector(cfg config.GPUMetricsConfig) *GPUCollector {
+	if !cfg.Enabled {
+		return nil
+	}
+	binary, err := exec.LookPath(cfg.Binary)
+	if err != nil {
+		logger.Infof("GPU metrics disabled, %s not found error=%v", cfg.Binary, err)
+		return nil
+	}
+	return &GPUCollector{binary: binary, interval: cfg.Interval}
+}
+
+func (c *GPUCollector) Name() string {
+	return "gpuMetricsCollector"
+}
+
+func (c *GPUCollector) Start(ctx context.Context) error {
+	metrics.RegisterCollector("gpu", gpuSamples)
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		c.collect(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.collect(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *GPUCollector) Stop() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+	return nil
+}
+
+func (c *GPUCollector) collect(ctx context.Context) {
+	qctx, cancel := context.WithTimeout(ctx, gpuQueryTimeout)
+	defer cancel()
+	stats, err := sysinfo.QueryGPUs(qctx, c.binary)
+	if err != nil {
+		if ctx.Err() == nil {
+			metrics.Add("gpu_query_failures_total", 1)
+			logger.Warnf("query GPU metrics failed error=%v", err)
+		}
+		return
+	}
+	latestGPUs.Store(&stats)
+	metrics.Set("gpu_count", int64(len(stats)))
+}
+
+// gpuSamples 按 GPU 展开的 /metrics 样本
+func gpuSamples() []metrics.Sample {
+	stats := LatestGPUStats()
+	samples := make([]metrics.Sample, 0, len(stats)*7)
+	for _, g := range stats {
+		labels := map[string]string{"gpu": strconv.Itoa(g.Index), "uuid": g.UUID, "name": g.Name}
+		add := func(name string, v float64) {
+			samples = append(samples, metrics.Sample{Name: name, Labels: labels, Value: v})
+		}
+		add("gpu_utilization_percent", g.UtilizationPercent)
+		add("gpu_memory_used_bytes", float64(g.MemoryUsedBytes))
+		add("gpu_memory_total_bytes", float64(g.MemoryTotalBytes))
+		if g.EncoderPercent != nil {
+			add("gpu_encoder_utilization_percent", *g.EncoderPercent)
+		}
+		if g.DecoderPercent != nil {
+			add("gpu_decoder_utilization_percent", *g.DecoderPercent)
+		}
+		if g.TemperatureC != nil {
+			add("gpu_temperature_celsius", *g.TemperatureC)
+		}
+	}
+	return samples
+}
@@ -0,0 +1,124 @@
+package observability
+
+import (
+	"context"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"transcode-service/pkg/metrics"
+)
+
+// RuntimeTuning 启动时生效的 GC 参数，-1 表示未修改
+type RuntimeTuning struct {
+	GCPercent   int   `json:"gc_percent"`
+	MemoryLimit int64 `json:"memory_limit_bytes"`
+}
+
+// ApplyRuntimeTuning 按配置覆盖 GOGC，并按容器内存上限的比例设置 Go 软内存上限，
+// 让堆在接近容器上限前更积极地回收，降低与 ffmpeg 子进程争抢内存导致 OOM 的概率；
+// 显式设置了 GOGC/GOMEMLIMIT 环境变量时以环境变量为准
+func ApplyRuntimeTuning(gcPercent int, memoryLimitRatio float64, containerMemory int64) RuntimeTuning {
+	t := RuntimeTuning{GCPercent: -1, MemoryLimit: -1}
+	if gcPercent != 0 && os.Getenv("GOGC") == "" {
+		debug.SetGCPercent(gcPercent)
+		t.GCPercent = gcPercent
+	}
+	if memoryLimitRatio > 0 && memoryLimitRatio <= 1 && containerMemory > 0 && os.Getenv("GOMEMLIMIT") == "" {
+		t.MemoryLimit = int64(float64(containerMemory) * memoryLimitRatio)
+		debug.SetMemoryLimit(t.MemoryLimit)
+	}
+	return t
+}
+
+// RuntimeExporter 周期性采集 goroutine、堆与 GC 统计，以 Prometheus 样本经 /metrics 导出，不写入 expvar
+type RuntimeExporter struct {
+	interval time.Duration
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewRuntimeExporter interval 为负数时返回 nil，不导出
+func NewRuntimeExporter(interval time.Duration) *RuntimeExporter {
+	if interval < 0 {
+		return nil
+	}
+	if interval == 0 {
+		interval = 15 * time.Second
+	}
+	return &RuntimeExporter{interval: interval}
+}
+
+func (e *RuntimeExporter) Name() string {
+	return "runtimeMetricsExporter"
+}
+
+func (e *RuntimeExporter) Start(ctx context.Context) error {
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		ExportRuntimeMetrics()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ExportRuntimeMetrics()
+			}
+		}
+	}()
+	return nil
+}
+
+func (e *RuntimeExporter) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.wg.Wait()
+	return nil
+}
+
+// runtimeSamples 最近一次采集的运行时样本
+var (
+	runtimeSamples      atomic.Pointer[[]metrics.Sample]
+	runtimeCollectorReg sync.Once
+)
+
+// ExportRuntimeMetrics 采集一次运行时统计；ReadMemStats 会短暂 STW，间隔不宜过短
+func ExportRuntimeMetrics() {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	samples := []metrics.Sample{
+		{Name: "runtime_goroutines", Value: float64(runtime.NumGoroutine())},
+		{Name: "runtime_gomaxprocs", Value: float64(runtime.GOMAXPROCS(0))},
+		{Name: "runtime_heap_alloc_bytes", Value: float64(m.HeapAlloc)},
+		{Name: "runtime_heap_inuse_bytes", Value: float64(m.HeapInuse)},
+		{Name: "runtime_heap_objects", Value: float64(m.HeapObjects)},
+		{Name: "runtime_sys_bytes", Value: float64(m.Sys)},
+		{Name: "runtime_next_gc_bytes", Value: float64(m.NextGC)},
+		{Name: "runtime_gc_count", Value: float64(m.NumGC)},
+		{Name: "runtime_gc_forced_count", Value: float64(m.NumForcedGC)},
+		{Name: "runtime_gc_pause_total_ns", Value: float64(m.PauseTotalNs)},
+		{Name: "runtime_gc_last_pause_ns", Value: float64(m.PauseNs[(m.NumGC+255)%256])},
+		{Name: "runtime_gc_cpu_fraction", Value: m.GCCPUFraction},
+	}
+	if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+		samples = append(samples, metrics.Sample{Name: "runtime_memory_limit_bytes", Value: float64(limit)})
+	}
+	runtimeSamples.Store(&samples)
+	runtimeCollectorReg.Do(func() {
+		metrics.RegisterCollector("runtime", func() []metrics.Sample {
+			if p := runtimeSamples.Load(); p != nil {
+				return *p
+			}
+			return nil
+		})
+	})
+}
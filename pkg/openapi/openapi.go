@@ -0,0 +1,245 @@
+// Package openapi 由已注册的 Gin 路由与 handler 注解生成 OpenAPI 3 文档，
+// 供 upload-service 与控制台生成客户端 SDK
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"transcode-service/pkg/restapi"
+)
+
+// Operation handler 注解；Request/Response/Query 传对应类型的零值，只用于反射
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Query       interface{} // form 标签的查询参数结构体
+	Request     interface{} // JSON 请求体
+	Response    interface{} // 响应信封中的 data
+	Paged       bool        // data 为分页结构，Response 为单行类型
+	Auth        bool        // 需要 Bearer Token
+}
+
+var (
+	annotationsMu sync.RWMutex
+	annotations   = map[string]Operation{}
+)
+
+// Annotate 为 handler 登记文档。handler 可传方法表达式，如 (*fooController).Get，
+// 同一 handler 挂在多个分组下时共用一份注解
+func Annotate(handler interface{}, op Operation) {
+	annotationsMu.Lock()
+	defer annotationsMu.Unlock()
+	annotations[handlerName(handler)] = op
+}
+
+// handlerName 与 gin.RouteInfo.Handler 一致的函数名，方法值的 -fm 后缀去掉后与方法表达式相同
+func handlerName(handler interface{}) string {
+	return strings.TrimSuffix(runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name(), "-fm")
+}
+
+func lookup(handler string) (Operation, bool) {
+	annotationsMu.RLock()
+	defer annotationsMu.RUnlock()
+	op, ok := annotations[strings.TrimSuffix(handler, "-fm")]
+	return op, ok
+}
+
+// Info 文档标题与版本
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Document OpenAPI 3 文档
+type Document struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       Info                                   `json:"info"`
+	Paths      map[string]map[string]*OperationObject `json:"paths"`
+	Components Components                             `json:"components"`
+}
+
+// Components 共享的 schema 与鉴权方式
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme 鉴权方式
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// OperationObject 单个接口
+type OperationObject struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter 路径或查询参数
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody JSON 请求体
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response 响应
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType 内容类型对应的 schema
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Build 按路由生成文档；未注解的路由也会列出，只是没有请求体与 data 的结构
+func Build(routes gin.RoutesInfo, info Info) *Document {
+	b := newSchemaBuilder()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   map[string]map[string]*OperationObject{},
+		Components: Components{
+			Schemas:         b.schemas,
+			SecuritySchemes: map[string]SecurityScheme{"bearerAuth": {Type: "http", Scheme: "bearer"}},
+		},
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	for _, r := range routes {
+		p, params := convertPath(r.Path)
+		op, annotated := lookup(r.Handler)
+		obj := &OperationObject{
+			OperationID: operationID(r.Method, r.Path),
+			Summary:     op.Summary,
+			Description: op.Description,
+			Tags:        op.Tags,
+			Parameters:  append(params, b.queryParameters(op.Query)...),
+			Responses:   map[string]Response{},
+		}
+		if !annotated {
+			obj.Summary = shortHandlerName(r.Handler)
+		}
+		if len(obj.Tags) == 0 {
+			obj.Tags = []string{defaultTag(r.Path)}
+		}
+		if body := b.of(op.Request); body != nil {
+			obj.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {Schema: body}}}
+		}
+		if op.Auth {
+			obj.Security = []map[string][]string{{"bearerAuth": {}}}
+		}
+		data := b.of(op.Response)
+		if op.Paged {
+			data = pageSchema(b, data)
+		}
+		obj.Responses["200"] = Response{Description: "OK", Content: map[string]MediaType{"application/json": {Schema: envelope(data)}}}
+		obj.Responses["default"] = Response{Description: "业务错误，code 为 errno 错误码", Content: map[string]MediaType{"application/json": {Schema: envelope(nil)}}}
+		if doc.Paths[p] == nil {
+			doc.Paths[p] = map[string]*OperationObject{}
+		}
+		doc.Paths[p][strings.ToLower(r.Method)] = obj
+	}
+	return doc
+}
+
+// envelope restapi.Response 统一响应信封，data 为 nil 时不约束结构
+func envelope(data *Schema) *Schema {
+	if data == nil {
+		data = &Schema{}
+	}
+	return &Schema{Type: "object", Properties: map[string]*Schema{
+		"code":         {Type: "integer", Format: "int32"},
+		"message":      {Type: "string"},
+		"data":         data,
+		"data_version": {Type: "string"},
+		"request_id":   {Type: "string"},
+	}}
+}
+
+// pageSchema restapi.PageResult，rows 为 row 的数组
+func pageSchema(b *schemaBuilder, row *Schema) *Schema {
+	if row == nil {
+		row = &Schema{}
+	}
+	return &Schema{Type: "object", Properties: map[string]*Schema{
+		"page_info": b.of(restapi.PageInfo{}),
+		"rows":      {Type: "array", Items: row},
+	}}
+}
+
+// convertPath 把 /tasks/:task_uuid、/files/*path 转为 /tasks/{task_uuid}、/files/{path} 并生成路径参数
+func convertPath(ginPath string) (string, []Parameter) {
+	segs := strings.Split(ginPath, "/")
+	var params []Parameter
+	for i, s := range segs {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			name := s[1:]
+			segs[i] = "{" + name + "}"
+			params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	return strings.Join(segs, "/"), params
+}
+
+func operationID(method, ginPath string) string {
+	r := strings.NewReplacer("/", "_", ":", "", "*", "", "-", "_", ".", "_")
+	return strings.ToLower(method) + strings.TrimRight(r.Replace(ginPath), "_")
+}
+
+// defaultTag 未注解时以路由分组（api/inner/ops/debug）作为标签
+func defaultTag(ginPath string) string {
+	first, _, _ := strings.Cut(strings.TrimPrefix(ginPath, "/"), "/")
+	if first == "" {
+		return "default"
+	}
+	return first
+}
+
+func shortHandlerName(name string) string {
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// Handler 提供 JSON 文档；首次请求时按当时的路由生成并缓存，须在全部路由注册完成后对外服务
+func Handler(engine *gin.Engine, info Info) gin.HandlerFunc {
+	var (
+		once sync.Once
+		doc  *Document
+	)
+	return func(c *gin.Context) {
+		once.Do(func() { doc = Build(engine.Routes(), info) })
+		c.JSON(http.StatusOK, doc)
+	}
+}
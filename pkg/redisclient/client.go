@@ -17,6 +17,17 @@ type Client struct {
 
 // New builds a redis client using service configuration and validates the connection.
 func New(cfg config.RedisConfig) (*Client, error) {
+	c := NewLazy(cfg)
+	if err := c.Ping(context.Background()); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewLazy builds a redis client without validating the connection; go-redis dials on demand,
+// so the client starts working once the server becomes reachable.
+func NewLazy(cfg config.RedisConfig) *Client {
 	opts := &redis.Options{
 		Addr: cfg.GetRedisAddr(),
 	}
@@ -42,13 +53,12 @@ func New(cfg config.RedisConfig) (*Client, error) {
 		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
 	}
 
-	cli := redis.NewClient(opts)
-	if err := cli.Ping(context.Background()).Err(); err != nil {
-		_ = cli.Close()
-		return nil, err
-	}
+	return &Client{native: redis.NewClient(opts)}
+}
 
-	return &Client{native: cli}, nil
+// Ping checks that the server is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.native.Ping(ctx).Err()
 }
 
 // Raw exposes the underlying go-redis client for advanced use cases.
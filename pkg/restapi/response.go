@@ -7,6 +7,7 @@ import (
 
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -34,6 +35,29 @@ func Success(c *gin.Context, data interface{}) {
 	sendResponse(c, http.StatusOK, data, nil)
 }
 
+// SuccessWithETag 以响应数据的哈希作为 ETag，If-None-Match 命中时返回 304 且不带响应体
+func SuccessWithETag(c *gin.Context, data interface{}) {
+	b, _ := json.Marshal(data)
+	etag := `"` + encode.Crc32HashCode(b) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "no-cache")
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	Success(c, data)
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
 func Failed(c *gin.Context, err error) {
 	sendResponse(c, http.StatusInternalServerError, nil, err)
 }
@@ -0,0 +1,195 @@
+// Package sourcecrypt upload-service 静态加密源文件的流式解密（AES-256-GCM 分块信封格式）。
+//
+// 文件格式：
+//
+//	magic "GVE1" | uint32 头部长度 | 头部 | 分块...
+//	头部：version(1) | uint32 分块明文长度 | nonce 前缀(8) | uint16 密钥 ID 长度 | 密钥 ID | uint16 封装密钥长度 | 封装密钥
+//	分块：每块至多「分块明文长度」字节明文，GCM 加密后附 16 字节 tag；
+//	nonce = nonce 前缀 | uint32 块序号，AAD = 头部 | 末块标志(1 字节)，截断、重排与跨对象拼接都会认证失败
+//
+// 数据密钥由 KeyProvider 按密钥 ID 与封装密钥解封，只在内存中使用
+package sourcecrypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Magic 加密源文件的前 4 字节
+var Magic = []byte("GVE1")
+
+const (
+	version         = 1
+	noncePrefixSize = 8
+	tagSize         = 16
+	keySize         = 32
+	// maxHeaderSize 头部长度上限，防止损坏文件导致大块分配
+	maxHeaderSize = 64 << 10
+	// maxChunkSize 分块明文长度上限
+	maxChunkSize = 16 << 20
+)
+
+var (
+	// ErrMalformed 头部或分块结构不合法
+	ErrMalformed = errors.New("sourcecrypt: malformed encrypted source")
+	// ErrAuth 分块认证失败：密钥不对、内容被篡改或文件被截断
+	ErrAuth = errors.New("sourcecrypt: authentication failed")
+)
+
+// Header 加密源文件头部
+type Header struct {
+	ChunkSize   int
+	NoncePrefix []byte
+	KeyID       string
+	WrappedKey  []byte
+	raw         []byte
+}
+
+// Sniff 流是否以 Magic 开头，只预读不消费
+func Sniff(r *bufio.Reader) (bool, error) {
+	buf, err := r.Peek(len(Magic))
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return bytes.Equal(buf, Magic), nil
+}
+
+// ReadHeader 读取 magic 与头部，r 停在第一个分块起点
+func ReadHeader(r io.Reader) (*Header, error) {
+	var pre [8]byte
+	if _, err := io.ReadFull(r, pre[:]); err != nil {
+		return nil, fmt.Errorf("%w: read magic: %v", ErrMalformed, err)
+	}
+	if !bytes.Equal(pre[:4], Magic) {
+		return nil, fmt.Errorf("%w: bad magic", ErrMalformed)
+	}
+	n := binary.BigEndian.Uint32(pre[4:])
+	if n == 0 || n > maxHeaderSize {
+		return nil, fmt.Errorf("%w: header length %d", ErrMalformed, n)
+	}
+	raw := make([]byte, n)
+	if _, err := io.ReadFull(r, raw); err != nil {
+		return nil, fmt.Errorf("%w: read header: %v", ErrMalformed, err)
+	}
+	h, err := parseHeader(raw)
+	if err != nil {
+		return nil, err
+	}
+	h.raw = raw
+	return h, nil
+}
+
+func parseHeader(raw []byte) (*Header, error) {
+	b := bytes.NewReader(raw)
+	v, err := b.ReadByte()
+	if err != nil || v != version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrMalformed, v)
+	}
+	var chunk uint32
+	if err := binary.Read(b, binary.BigEndian, &chunk); err != nil || chunk == 0 || chunk > maxChunkSize {
+		return nil, fmt.Errorf("%w: chunk size %d", ErrMalformed, chunk)
+	}
+	h := &Header{ChunkSize: int(chunk), NoncePrefix: make([]byte, noncePrefixSize)}
+	if _, err := io.ReadFull(b, h.NoncePrefix); err != nil {
+		return nil, fmt.Errorf("%w: nonce prefix", ErrMalformed)
+	}
+	keyID, err := readField(b)
+	if err != nil || len(keyID) == 0 {
+		return nil, fmt.Errorf("%w: key id", ErrMalformed)
+	}
+	h.KeyID = string(keyID)
+	if h.WrappedKey, err = readField(b); err != nil {
+		return nil, fmt.Errorf("%w: wrapped key", ErrMalformed)
+	}
+	return h, nil
+}
+
+// readField uint16 长度前缀的字段
+func readField(b *bytes.Reader) ([]byte, error) {
+	var n uint16
+	if err := binary.Read(b, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	out := make([]byte, n)
+	if _, err := io.ReadFull(b, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Decrypt 逐块解密 r 中头部之后的内容并写入 w，返回明文字节数。
+// 只有认证通过的分块才会写出；返回错误时 w 中可能已有部分明文，调用方应丢弃
+func Decrypt(w io.Writer, r io.Reader, h *Header, key []byte) (int64, error) {
+	if len(key) != keySize {
+		return 0, fmt.Errorf("%w: data key must be %d bytes, got %d", ErrAuth, keySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return 0, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return 0, err
+	}
+	br := bufio.NewReaderSize(r, h.ChunkSize+tagSize)
+	buf := make([]byte, h.ChunkSize+tagSize)
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, h.NoncePrefix)
+	aad := append(append([]byte(nil), h.raw...), 0)
+	var total int64
+	for index := uint32(0); ; index++ {
+		n, err := io.ReadFull(br, buf)
+		last := false
+		switch {
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			last = true
+		case errors.Is(err, io.EOF):
+			// 末块至少包含 tag，读不到任何字节说明文件被截断在块边界
+			return total, fmt.Errorf("%w: missing final chunk", ErrAuth)
+		case err != nil:
+			return total, err
+		default:
+			if _, perr := br.Peek(1); errors.Is(perr, io.EOF) {
+				last = true
+			}
+		}
+		if n < tagSize {
+			return total, fmt.Errorf("%w: chunk %d too short", ErrMalformed, index)
+		}
+		binary.BigEndian.PutUint32(nonce[noncePrefixSize:], index)
+		aad[len(aad)-1] = 0
+		if last {
+			aad[len(aad)-1] = 1
+		}
+		plain, err := aead.Open(buf[:0], nonce, buf[:n], aad)
+		if err != nil {
+			return total, fmt.Errorf("%w: chunk %d", ErrAuth, index)
+		}
+		if _, err := w.Write(plain); err != nil {
+			return total, err
+		}
+		total += int64(len(plain))
+		if last {
+			return total, nil
+		}
+		if index == ^uint32(0) {
+			return total, fmt.Errorf("%w: too many chunks", ErrMalformed)
+		}
+	}
+}
+
+// Zero 使用后清空数据密钥
+func Zero(key []byte) {
+	for i := range key {
+		key[i] = 0
+	}
+}
@@ -0,0 +1,115 @@
+package sysinfo
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Resources 进程可用的 CPU/内存资源（已考虑 cgroup 限制）
+type Resources struct {
+	HostCPUs    int     // 宿主机可见核数
+	CPULimit    float64 // cgroup CPU 配额折算的核数，无限制时等于 HostCPUs
+	MemoryLimit int64   // cgroup 内存上限（字节），0 表示无限制
+	Source      string  // cgroup-v2 / cgroup-v1 / host
+}
+
+// Limited 是否受 cgroup 限制
+func (r Resources) Limited() bool {
+	return r.CPULimit < float64(r.HostCPUs) || r.MemoryLimit > 0
+}
+
+// EffectiveCPUs 向下取整的可用核数，至少为 1
+func (r Resources) EffectiveCPUs() int {
+	n := int(r.CPULimit)
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// unlimitedMemoryThreshold cgroup v1 无限制时会返回接近 int64 最大值的数
+const unlimitedMemoryThreshold = int64(1) << 60
+
+// Detect 读取 cgroup v2/v1 的 CPU 配额与内存上限，读取失败时回退到宿主机信息
+func Detect() Resources {
+	res := Resources{HostCPUs: runtime.NumCPU(), Source: "host"}
+	res.CPULimit = float64(res.HostCPUs)
+
+	if quota, period, ok := readCgroupV2CPU(); ok {
+		res.Source = "cgroup-v2"
+		applyCPUQuota(&res, quota, period)
+		res.MemoryLimit = readMemoryLimit("/sys/fs/cgroup/memory.max")
+		return res
+	}
+	if quota, ok := readInt("/sys/fs/cgroup/cpu/cpu.cfs_quota_us"); ok {
+		res.Source = "cgroup-v1"
+		if period, ok := readInt("/sys/fs/cgroup/cpu/cpu.cfs_period_us"); ok {
+			applyCPUQuota(&res, quota, period)
+		}
+		res.MemoryLimit = readMemoryLimit("/sys/fs/cgroup/memory/memory.limit_in_bytes")
+	}
+	return res
+}
+
+func applyCPUQuota(res *Resources, quota, period int64) {
+	if quota <= 0 || period <= 0 {
+		return
+	}
+	if limit := float64(quota) / float64(period); limit < res.CPULimit {
+		res.CPULimit = limit
+	}
+}
+
+// readCgroupV2CPU 解析 cpu.max，格式为 "<quota|max> <period>"
+func readCgroupV2CPU() (quota, period int64, ok bool) {
+	data, err := os.ReadFile("/sys/fs/cgroup/cpu.max")
+	if err != nil {
+		return 0, 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return 0, 0, false
+	}
+	period, err = strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if fields[0] == "max" {
+		return -1, period, true
+	}
+	quota, err = strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return quota, period, true
+}
+
+func readMemoryLimit(path string) int64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	v := strings.TrimSpace(string(data))
+	if v == "max" {
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 || n >= unlimitedMemoryThreshold {
+		return 0
+	}
+	return n
+}
+
+func readInt(path string) (int64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
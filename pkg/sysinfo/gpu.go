@@ -0,0 +1,87 @@
+package sysinfo
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GPUStat 单块 GPU 的 NVML 计数器快照；驱动不支持的项（如部分型号的编解码器利用率）为 nil
+type GPUStat struct {
+	Index              int      `json:"index"`
+	UUID               string   `json:"uuid"`
+	Name               string   `json:"name"`
+	UtilizationPercent float64  `json:"utilization_percent"`
+	MemoryUsedBytes    int64    `json:"memory_used_bytes"`
+	MemoryTotalBytes   int64    `json:"memory_total_bytes"`
+	EncoderPercent     *float64 `json:"encoder_percent,omitempty"`
+	DecoderPercent     *float64 `json:"decoder_percent,omitempty"`
+	TemperatureC       *float64 `json:"temperature_c,omitempty"`
+}
+
+// gpuQueryFields nvidia-smi（NVML 的命令行前端）查询字段，顺序与 parseGPUStat 一致
+const gpuQueryFields = "index,uuid,name,utilization.gpu,memory.used,memory.total,utilization.encoder,utilization.decoder,temperature.gpu"
+
+// QueryGPUs 通过 nvidia-smi 读取全部 GPU 的 NVML 计数器；binary 为空时使用 PATH 中的 nvidia-smi
+func QueryGPUs(ctx context.Context, binary string) ([]GPUStat, error) {
+	if binary == "" {
+		binary = "nvidia-smi"
+	}
+	out, err := exec.CommandContext(ctx, binary, "--query-gpu="+gpuQueryFields, "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return nil, fmt.Errorf("nvidia-smi query: %w", err)
+	}
+	r := csv.NewReader(strings.NewReader(string(out)))
+	r.TrimLeadingSpace = true
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse nvidia-smi output: %w", err)
+	}
+	stats := make([]GPUStat, 0, len(rows))
+	for _, row := range rows {
+		st, err := parseGPUStat(row)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, st)
+	}
+	return stats, nil
+}
+
+func parseGPUStat(row []string) (GPUStat, error) {
+	if len(row) < 9 {
+		return GPUStat{}, fmt.Errorf("unexpected nvidia-smi row %q", strings.Join(row, ","))
+	}
+	for i := range row {
+		row[i] = strings.TrimSpace(row[i])
+	}
+	index, err := strconv.Atoi(row[0])
+	if err != nil {
+		return GPUStat{}, fmt.Errorf("invalid gpu index %q", row[0])
+	}
+	st := GPUStat{Index: index, UUID: row[1], Name: row[2]}
+	if v := gpuValue(row[3]); v != nil {
+		st.UtilizationPercent = *v
+	}
+	// memory.* 单位为 MiB
+	if v := gpuValue(row[4]); v != nil {
+		st.MemoryUsedBytes = int64(*v) << 20
+	}
+	if v := gpuValue(row[5]); v != nil {
+		st.MemoryTotalBytes = int64(*v) << 20
+	}
+	st.EncoderPercent, st.DecoderPercent, st.TemperatureC = gpuValue(row[6]), gpuValue(row[7]), gpuValue(row[8])
+	return st, nil
+}
+
+// gpuValue 不支持的字段输出为 [N/A] / [Not Supported]，返回 nil
+func gpuValue(s string) *float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
@@ -13,14 +13,15 @@ type BackgroundTask interface {
 }
 
 type manager struct {
-	tasks  []BackgroundTask
-	mu     sync.RWMutex
-	ctx    context.Context
-	cancel context.CancelFunc
+	tasks   []BackgroundTask
+	stopped map[int]bool
+	mu      sync.RWMutex
+	ctx     context.Context
+	cancel  context.CancelFunc
 }
 
 var (
-	defaultManager = &manager{tasks: make([]BackgroundTask, 0)}
+	defaultManager = &manager{tasks: make([]BackgroundTask, 0), stopped: map[int]bool{}}
 )
 
 // Register adds a background task; should be called during init/assembly before StartAll.
@@ -52,6 +53,34 @@ func StartAll(ctx context.Context) error {
 	return nil
 }
 
+// Tasks returns a snapshot of registered tasks that have not been stopped, in registration order.
+func Tasks() []BackgroundTask {
+	defaultManager.mu.RLock()
+	defer defaultManager.mu.RUnlock()
+	out := make([]BackgroundTask, 0, len(defaultManager.tasks))
+	for i, t := range defaultManager.tasks {
+		if t != nil && !defaultManager.stopped[i] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// StopWhere stops matching tasks in reverse registration order without cancelling the shared context,
+// so tasks outside the current shutdown phase keep running. Each task is stopped at most once.
+func StopWhere(match func(BackgroundTask) bool) {
+	defaultManager.mu.Lock()
+	defer defaultManager.mu.Unlock()
+	for i := len(defaultManager.tasks) - 1; i >= 0; i-- {
+		t := defaultManager.tasks[i]
+		if t == nil || defaultManager.stopped[i] || !match(t) {
+			continue
+		}
+		_ = t.Stop()
+		defaultManager.stopped[i] = true
+	}
+}
+
 // StopAll stops all running tasks.
 func StopAll() {
 	defaultManager.mu.Lock()
@@ -60,8 +89,9 @@ func StopAll() {
 		defaultManager.cancel()
 	}
 	for i := len(defaultManager.tasks) - 1; i >= 0; i-- {
-		if t := defaultManager.tasks[i]; t != nil {
+		if t := defaultManager.tasks[i]; t != nil && !defaultManager.stopped[i] {
 			_ = t.Stop()
+			defaultManager.stopped[i] = true
 		}
 	}
 	defaultManager.cancel = nil
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// maxLocalNameLen 本地临时文件名上限，远低于常见文件系统 255 字节的限制，给前缀/后缀留余量
+const maxLocalNameLen = 100
+
+// maxObjectKeyLen S3 对象 key 的长度上限
+const maxObjectKeyLen = 1024
+
+// ErrUnsafePath 路径或对象 key 含目录穿越、绝对路径等可疑成分
+var ErrUnsafePath = errors.New("unsafe path")
+
+// NormalizeObjectKey 校验并规范化外部传入的对象 key：拒绝空值、超长、控制字符、反斜杠、
+// 绝对路径与 ".." 段；去掉多余的 "/" 与 "." 段后返回规范形式
+func NormalizeObjectKey(key string) (string, error) {
+	key = strings.TrimSpace(key)
+	if key == "" || len(key) > maxObjectKeyLen {
+		return "", fmt.Errorf("%w: object key length %d", ErrUnsafePath, len(key))
+	}
+	if hasControlOrBackslash(key) {
+		return "", fmt.Errorf("%w: object key %q contains control characters or backslash", ErrUnsafePath, key)
+	}
+	if strings.HasPrefix(key, "/") {
+		return "", fmt.Errorf("%w: object key %q is absolute", ErrUnsafePath, key)
+	}
+	segments := make([]string, 0, strings.Count(key, "/")+1)
+	for _, seg := range strings.Split(key, "/") {
+		switch seg {
+		case "", ".":
+			continue
+		case "..":
+			return "", fmt.Errorf("%w: object key %q contains '..'", ErrUnsafePath, key)
+		}
+		segments = append(segments, seg)
+	}
+	if len(segments) == 0 {
+		return "", fmt.Errorf("%w: object key %q is empty after normalization", ErrUnsafePath, key)
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// ValidatePathSegment 校验会作为单级目录名使用的标识（如 user_uuid、video_uuid）
+func ValidatePathSegment(s string) error {
+	if strings.TrimSpace(s) == "" || s == "." || s == ".." || len(s) > maxLocalNameLen ||
+		strings.Contains(s, "/") || hasControlOrBackslash(s) {
+		return fmt.Errorf("%w: path segment %q", ErrUnsafePath, s)
+	}
+	return nil
+}
+
+// SafeJoin 拼接 base 与 elem，结果必须仍位于 base 之内
+func SafeJoin(base string, elem ...string) (string, error) {
+	root := filepath.Clean(base)
+	p := filepath.Join(append([]string{root}, elem...)...)
+	rel, err := filepath.Rel(root, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("%w: %q escapes %s", ErrUnsafePath, filepath.Join(elem...), root)
+	}
+	return p, nil
+}
+
+func hasControlOrBackslash(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c == 0x7f || c == '\\' {
+			return true
+		}
+	}
+	return false
+}
+
+// EscapeObjectKey 按 S3 SigV4 规则对对象 key 逐字节百分号编码：保留 RFC 3986 非保留字符与路径分隔符 '/'，
+// 其余（含非 UTF-8 字节、空格、emoji、CJK）编码为 %XX。签名中的 canonical URI 与实际请求路径必须一致
+func EscapeObjectKey(key string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	b.Grow(len(key))
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if isUnreserved(c) || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&0x0F])
+	}
+	return b.String()
+}
+
+// LocalFileName 由对象 key 生成本地临时文件名：仅含安全 ASCII 字符且不超长时保留原文件名，
+// 否则使用 key 的哈希，并在扩展名安全时保留扩展名，便于 ffmpeg/ffprobe 识别格式
+func LocalFileName(objectKey string) string {
+	base := filepath.Base(strings.TrimRight(objectKey, "/"))
+	if base != "." && base != ".." && base != "/" && len(base) <= maxLocalNameLen && isSafeName(base) {
+		return base
+	}
+	sum := sha256.Sum256([]byte(objectKey))
+	name := "obj-" + hex.EncodeToString(sum[:12])
+	if ext := filepath.Ext(base); len(ext) > 1 && len(ext) <= 10 && isSafeName(ext[1:]) {
+		name += strings.ToLower(ext)
+	}
+	return name
+}
+
+func isUnreserved(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isSafeName(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isUnreserved(c) || c == '~' {
+			return false
+		}
+	}
+	return s != ""
+}
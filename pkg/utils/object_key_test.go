@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeObjectKey(t *testing.T) {
+	cases := []struct {
+		name string
+		key  string
+		want string
+		bad  bool
+	}{
+		{name: "plain", key: "videos/u1/v1/source.mp4", want: "videos/u1/v1/source.mp4"},
+		{name: "trim spaces", key: "  videos/a.mp4 ", want: "videos/a.mp4"},
+		{name: "empty segments", key: "videos//u1///a.mp4", want: "videos/u1/a.mp4"},
+		{name: "trailing slash", key: "videos/u1/", want: "videos/u1"},
+		{name: "dot segments", key: "./videos/./a.mp4", want: "videos/a.mp4"},
+		{name: "encoded dots stay literal", key: "videos/%2e%2e/a.mp4", want: "videos/%2e%2e/a.mp4"},
+		{name: "dots inside name", key: "videos/a..b.mp4", want: "videos/a..b.mp4"},
+		{name: "parent segment", key: "../etc/passwd", bad: true},
+		{name: "nested parent segment", key: "videos/../../etc/passwd", bad: true},
+		{name: "trailing parent segment", key: "videos/..", bad: true},
+		{name: "absolute", key: "/etc/passwd", bad: true},
+		{name: "backslash", key: `videos\..\a.mp4`, bad: true},
+		{name: "windows absolute", key: `C:\videos\a.mp4`, bad: true},
+		{name: "nul byte", key: "videos/a.mp4\x00.txt", bad: true},
+		{name: "control character", key: "videos/a\nb.mp4", bad: true},
+		{name: "delete character", key: "videos/a\x7fb.mp4", bad: true},
+		{name: "empty", key: "", bad: true},
+		{name: "only spaces", key: "   ", bad: true},
+		{name: "only slashes and dots", key: "./././", bad: true},
+		{name: "too long", key: strings.Repeat("a", maxObjectKeyLen+1), bad: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NormalizeObjectKey(tc.key)
+			if tc.bad {
+				if !errors.Is(err, ErrUnsafePath) {
+					t.Fatalf("NormalizeObjectKey(%q) = %q, %v; want ErrUnsafePath", tc.key, got, err)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Fatalf("NormalizeObjectKey(%q) = %q, %v; want %q", tc.key, got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestSafeJoin(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "work")
+	cases := []struct {
+		name string
+		elem []string
+		want string
+		bad  bool
+	}{
+		{name: "file", elem: []string{"a.mp4"}, want: filepath.Join(base, "a.mp4")},
+		{name: "nested", elem: []string{"hls", "720p", "seg_001.ts"}, want: filepath.Join(base, "hls", "720p", "seg_001.ts")},
+		{name: "empty segments", elem: []string{"", "a", "", "b.ts"}, want: filepath.Join(base, "a", "b.ts")},
+		{name: "parent inside base", elem: []string{"a", "..", "b.ts"}, want: filepath.Join(base, "b.ts")},
+		{name: "encoded dots stay literal", elem: []string{"%2e%2e", "a.ts"}, want: filepath.Join(base, "%2e%2e", "a.ts")},
+		{name: "absolute element stays under base", elem: []string{"/etc/passwd"}, want: filepath.Join(base, "etc", "passwd")},
+		{name: "no element", elem: nil, want: base},
+		{name: "parent", elem: []string{".."}, bad: true},
+		{name: "parent escape", elem: []string{"../escape.ts"}, bad: true},
+		{name: "nested escape", elem: []string{"a", "..", "..", "b.ts"}, bad: true},
+		{name: "sibling prefix", elem: []string{"../work2/a.ts"}, bad: true},
+		{name: "absolute escape", elem: []string{"/../../etc/passwd"}, bad: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SafeJoin(base, tc.elem...)
+			if tc.bad {
+				if !errors.Is(err, ErrUnsafePath) {
+					t.Fatalf("SafeJoin(%q) = %q, %v; want ErrUnsafePath", tc.elem, got, err)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Fatalf("SafeJoin(%q) = %q, %v; want %q", tc.elem, got, err, tc.want)
+			}
+		})
+	}
+}
+
+// TestSafeJoinKeepsNormalizedKeysInside 规范化后的对象 key 拼到工作目录下不会逃出工作目录
+func TestSafeJoinKeepsNormalizedKeysInside(t *testing.T) {
+	base := t.TempDir()
+	for _, key := range []string{"a/b/c.mp4", "a//b/./c.mp4", "%2e%2e/%2e%2e/c.mp4"} {
+		norm, err := NormalizeObjectKey(key)
+		if err != nil {
+			t.Fatalf("NormalizeObjectKey(%q): %v", key, err)
+		}
+		p, err := SafeJoin(base, norm)
+		if err != nil {
+			t.Fatalf("SafeJoin(%q): %v", norm, err)
+		}
+		if rel, err := filepath.Rel(base, p); err != nil || strings.HasPrefix(rel, "..") {
+			t.Fatalf("SafeJoin(%q) = %q escapes %s", norm, p, base)
+		}
+	}
+}
+
+func TestEscapeObjectKey(t *testing.T) {
+	cases := []struct {
+		key  string
+		want string
+	}{
+		{key: "videos/a.mp4", want: "videos/a.mp4"},
+		{key: "videos/a b.mp4", want: "videos/a%20b.mp4"},
+		{key: "videos/a+b.mp4", want: "videos/a%2Bb.mp4"},
+		{key: "videos/视频.mp4", want: "videos/%E8%A7%86%E9%A2%91.mp4"},
+		{key: "videos/a?b.mp4", want: "videos/a%3Fb.mp4"},
+		{key: "videos/a#b.mp4", want: "videos/a%23b.mp4"},
+		{key: "videos/100%.mp4", want: "videos/100%25.mp4"},
+		{key: "videos/%2e%2e/a.mp4", want: "videos/%252e%252e/a.mp4"},
+		{key: "videos/a~b-c_d.mp4", want: "videos/a~b-c_d.mp4"},
+		{key: "videos/a\xffb.mp4", want: "videos/a%FFb.mp4"},
+	}
+	for _, tc := range cases {
+		if got := EscapeObjectKey(tc.key); got != tc.want {
+			t.Errorf("EscapeObjectKey(%q) = %q, want %q", tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestLocalFileName(t *testing.T) {
+	kept := map[string]string{
+		"videos/u1/source.mp4":  "source.mp4",
+		"videos/u1/a-b_c.1.mkv": "a-b_c.1.mkv",
+		"videos/u1/dir/":        "dir",
+	}
+	for key, want := range kept {
+		if got := LocalFileName(key); got != want {
+			t.Errorf("LocalFileName(%q) = %q, want %q", key, got, want)
+		}
+	}
+	hashed := map[string]string{
+		"videos/u1/a b.mp4":     ".mp4",
+		"videos/u1/a+b.mp4":     ".mp4",
+		"videos/u1/视频.MP4":      ".mp4",
+		"videos/u1/a?b.mov":     ".mov",
+		"videos/u1/a#b.webm":    ".webm",
+		"videos/u1/100%.mp4":    ".mp4",
+		"videos/u1/~backup.mp4": ".mp4",
+		"videos/u1/a.m p4":      "",
+		"videos/u1/..":          "",
+		"videos/" + strings.Repeat("a", maxLocalNameLen) + ".mp4": ".mp4",
+	}
+	for key, ext := range hashed {
+		got := LocalFileName(key)
+		if !strings.HasPrefix(got, "obj-") || !strings.HasSuffix(got, ext) || !isSafeName(strings.TrimSuffix(got, ext)) {
+			t.Errorf("LocalFileName(%q) = %q, want obj-<hash>%s", key, got, ext)
+		}
+		if ext == "" && strings.Contains(got, ".") {
+			t.Errorf("LocalFileName(%q) = %q, want no extension", key, got)
+		}
+		if again := LocalFileName(key); again != got {
+			t.Errorf("LocalFileName(%q) not stable: %q then %q", key, got, again)
+		}
+	}
+	if a, b := LocalFileName("videos/u1/a b.mp4"), LocalFileName("videos/u2/a b.mp4"); a == b {
+		t.Errorf("different keys share local name %q", a)
+	}
+}
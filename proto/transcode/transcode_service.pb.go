@@ -206,14 +206,16 @@ func (x *GetTranscodeTaskRequest) GetTaskUuid() string {
 
 // Response containing task status.
 type GetTranscodeTaskResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	TaskUuid      string                 `protobuf:"bytes,2,opt,name=task_uuid,json=taskUuid,proto3" json:"task_uuid,omitempty"`
-	VideoUuid     string                 `protobuf:"bytes,3,opt,name=video_uuid,json=videoUuid,proto3" json:"video_uuid,omitempty"`
-	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
-	Progress      int32                  `protobuf:"varint,5,opt,name=progress,proto3" json:"progress,omitempty"`
-	OutputPath    string                 `protobuf:"bytes,6,opt,name=output_path,json=outputPath,proto3" json:"output_path,omitempty"`
-	ErrorMessage  string                 `protobuf:"bytes,7,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Success      bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TaskUuid     string                 `protobuf:"bytes,2,opt,name=task_uuid,json=taskUuid,proto3" json:"task_uuid,omitempty"`
+	VideoUuid    string                 `protobuf:"bytes,3,opt,name=video_uuid,json=videoUuid,proto3" json:"video_uuid,omitempty"`
+	Status       string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Progress     int32                  `protobuf:"varint,5,opt,name=progress,proto3" json:"progress,omitempty"`
+	OutputPath   string                 `protobuf:"bytes,6,opt,name=output_path,json=outputPath,proto3" json:"output_path,omitempty"`
+	ErrorMessage string                 `protobuf:"bytes,7,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	// Every output produced for the task: MP4 rendition, audio-only outputs and HLS playlists.
+	Outputs       []*TranscodeOutput `protobuf:"bytes,8,rep,name=outputs,proto3" json:"outputs,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -297,6 +299,85 @@ func (x *GetTranscodeTaskResponse) GetErrorMessage() string {
 	return ""
 }
 
+func (x *GetTranscodeTaskResponse) GetOutputs() []*TranscodeOutput {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+// A single output produced for a transcode task.
+type TranscodeOutput struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// video | audio | hls_master | hls_rendition
+	Type       string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Resolution string `protobuf:"bytes,2,opt,name=resolution,proto3" json:"resolution,omitempty"`
+	// Public URL, or a signed URL for private outputs.
+	Url string `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	// Size in bytes, 0 when unknown.
+	Size          int64 `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TranscodeOutput) Reset() {
+	*x = TranscodeOutput{}
+	mi := &file_transcode_transcode_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TranscodeOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TranscodeOutput) ProtoMessage() {}
+
+func (x *TranscodeOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_transcode_transcode_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TranscodeOutput.ProtoReflect.Descriptor instead.
+func (*TranscodeOutput) Descriptor() ([]byte, []int) {
+	return file_transcode_transcode_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TranscodeOutput) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TranscodeOutput) GetResolution() string {
+	if x != nil {
+		return x.Resolution
+	}
+	return ""
+}
+
+func (x *TranscodeOutput) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *TranscodeOutput) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
 var File_transcode_transcode_service_proto protoreflect.FileDescriptor
 
 const file_transcode_transcode_service_proto_rawDesc = "" +
@@ -315,7 +396,7 @@ const file_transcode_transcode_service_proto_rawDesc = "" +
 	"\ttask_uuid\x18\x02 \x01(\tR\btaskUuid\x12\x18\n" +
 	"\amessage\x18\x03 \x01(\tR\amessage\"6\n" +
 	"\x17GetTranscodeTaskRequest\x12\x1b\n" +
-	"\ttask_uuid\x18\x01 \x01(\tR\btaskUuid\"\xea\x01\n" +
+	"\ttask_uuid\x18\x01 \x01(\tR\btaskUuid\"\xa0\x02\n" +
 	"\x18GetTranscodeTaskResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1b\n" +
 	"\ttask_uuid\x18\x02 \x01(\tR\btaskUuid\x12\x1d\n" +
@@ -325,7 +406,15 @@ const file_transcode_transcode_service_proto_rawDesc = "" +
 	"\bprogress\x18\x05 \x01(\x05R\bprogress\x12\x1f\n" +
 	"\voutput_path\x18\x06 \x01(\tR\n" +
 	"outputPath\x12#\n" +
-	"\rerror_message\x18\a \x01(\tR\ferrorMessage2\xd5\x01\n" +
+	"\rerror_message\x18\a \x01(\tR\ferrorMessage\x124\n" +
+	"\aoutputs\x18\b \x03(\v2\x1a.transcode.TranscodeOutputR\aoutputs\"k\n" +
+	"\x0fTranscodeOutput\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1e\n" +
+	"\n" +
+	"resolution\x18\x02 \x01(\tR\n" +
+	"resolution\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size2\xd5\x01\n" +
 	"\x10TranscodeService\x12d\n" +
 	"\x13CreateTranscodeTask\x12%.transcode.CreateTranscodeTaskRequest\x1a&.transcode.CreateTranscodeTaskResponse\x12[\n" +
 	"\x10GetTranscodeTask\x12\".transcode.GetTranscodeTaskRequest\x1a#.transcode.GetTranscodeTaskResponseB#Z!transcode-service/proto/transcodeb\x06proto3"
@@ -342,23 +431,25 @@ func file_transcode_transcode_service_proto_rawDescGZIP() []byte {
 	return file_transcode_transcode_service_proto_rawDescData
 }
 
-var file_transcode_transcode_service_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_transcode_transcode_service_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_transcode_transcode_service_proto_goTypes = []any{
 	(*CreateTranscodeTaskRequest)(nil),  // 0: transcode.CreateTranscodeTaskRequest
 	(*CreateTranscodeTaskResponse)(nil), // 1: transcode.CreateTranscodeTaskResponse
 	(*GetTranscodeTaskRequest)(nil),     // 2: transcode.GetTranscodeTaskRequest
 	(*GetTranscodeTaskResponse)(nil),    // 3: transcode.GetTranscodeTaskResponse
+	(*TranscodeOutput)(nil),             // 4: transcode.TranscodeOutput
 }
 var file_transcode_transcode_service_proto_depIdxs = []int32{
-	0, // 0: transcode.TranscodeService.CreateTranscodeTask:input_type -> transcode.CreateTranscodeTaskRequest
-	2, // 1: transcode.TranscodeService.GetTranscodeTask:input_type -> transcode.GetTranscodeTaskRequest
-	1, // 2: transcode.TranscodeService.CreateTranscodeTask:output_type -> transcode.CreateTranscodeTaskResponse
-	3, // 3: transcode.TranscodeService.GetTranscodeTask:output_type -> transcode.GetTranscodeTaskResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	4, // 0: transcode.GetTranscodeTaskResponse.outputs:type_name -> transcode.TranscodeOutput
+	0, // 1: transcode.TranscodeService.CreateTranscodeTask:input_type -> transcode.CreateTranscodeTaskRequest
+	2, // 2: transcode.TranscodeService.GetTranscodeTask:input_type -> transcode.GetTranscodeTaskRequest
+	1, // 3: transcode.TranscodeService.CreateTranscodeTask:output_type -> transcode.CreateTranscodeTaskResponse
+	3, // 4: transcode.TranscodeService.GetTranscodeTask:output_type -> transcode.GetTranscodeTaskResponse
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_transcode_transcode_service_proto_init() }
@@ -372,7 +463,7 @@ func file_transcode_transcode_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_transcode_transcode_service_proto_rawDesc), len(file_transcode_transcode_service_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -0,0 +1,20 @@
+-- 分析数据导出游标
+-- 按 (updated_at, id) 记录每类作业已导出的位置，多实例通过条件更新推进，至多一个实例推进成功
+
+USE transcode_service;
+
+CREATE TABLE IF NOT EXISTS analytics_export_cursors (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    name VARCHAR(32) NOT NULL COMMENT '导出流（transcode/hls）',
+    cursor_at DATETIME NOT NULL COMMENT '已导出记录的最大 updated_at',
+    cursor_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '同一 updated_at 内已导出的最大 id',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    is_deleted BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '是否删除',
+
+    UNIQUE KEY uk_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='分析数据导出游标';
+
+-- 按 updated_at 增量扫描已结束作业
+ALTER TABLE transcode_jobs ADD INDEX idx_updated_at_id (updated_at, id);
+ALTER TABLE hls_jobs ADD INDEX idx_updated_at_id (updated_at, id);
@@ -0,0 +1,7 @@
+-- 纯音频产物（播客重发布）
+-- 记录请求的 m4a/mp3 产物、码率、object key 与元数据标签
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN audio_outputs JSON DEFAULT NULL COMMENT '纯音频产物(JSON: renditions[{format,bitrate,object_key}], tags)';
@@ -0,0 +1,6 @@
+-- profile=auto 命中的规则及编码覆盖（规则名、编码器、预设、HLS 阶梯），NULL 表示按配置编码
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN auto_profile JSON NULL COMMENT 'profile=auto 命中的规则与编码覆盖';
@@ -0,0 +1,18 @@
+-- 产物的编码设置指纹（编码器、preset、码控、滤镜链哈希、ffmpeg 版本）与设置版本
+-- 回填通过 GET /ops/v1/admin/outputs/stale 按 settings_version / fingerprint_hash 选出旧设置编码的产物
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN encoder_fingerprint JSON DEFAULT NULL COMMENT '编码设置指纹(JSON: {settings_version,ffmpeg_version,codec,preset,rate_control,filter_hash,hash})',
+ADD COLUMN settings_version INT NOT NULL DEFAULT 0 COMMENT '编码设置版本，0 表示未记录',
+ADD COLUMN fingerprint_hash VARCHAR(16) NOT NULL DEFAULT '' COMMENT '编码设置指纹哈希',
+ADD INDEX idx_transcode_jobs_settings_version (settings_version),
+ADD INDEX idx_transcode_jobs_fingerprint_hash (fingerprint_hash);
+
+ALTER TABLE hls_jobs
+ADD COLUMN encoder_fingerprint JSON DEFAULT NULL COMMENT '编码设置指纹(JSON，各码流命令合并计算)',
+ADD COLUMN settings_version INT NOT NULL DEFAULT 0 COMMENT '编码设置版本，0 表示未记录',
+ADD COLUMN fingerprint_hash VARCHAR(16) NOT NULL DEFAULT '' COMMENT '编码设置指纹哈希',
+ADD INDEX idx_hls_jobs_settings_version (settings_version),
+ADD INDEX idx_hls_jobs_fingerprint_hash (fingerprint_hash);
@@ -0,0 +1,7 @@
+-- 快车道：创建时按源时长分道，短于 worker.express_lane.max_duration 的任务记为 express，
+-- 进入独立队列并由预留的专用协程执行
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN lane VARCHAR(16) NOT NULL DEFAULT 'standard' COMMENT '调度通道 standard/express';
@@ -0,0 +1,10 @@
+-- 记录实际执行的 ffmpeg 命令
+-- 通过 GET /api/v1/tasks/:task_uuid?include=commands 返回，便于本地复现编码
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN ffmpeg_commands JSON DEFAULT NULL COMMENT '已执行的 ffmpeg 命令(JSON: [{label,binary,args,recorded_at}])';
+
+ALTER TABLE hls_jobs
+ADD COLUMN ffmpeg_commands JSON DEFAULT NULL COMMENT '已执行的 ffmpeg 命令(JSON: [{label,binary,args,recorded_at}])';
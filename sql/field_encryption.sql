@@ -0,0 +1,8 @@
+-- 敏感字段落库加密：标签、元数据与备注改存 enc:v1:<key_id>:<密文>，JSON 列改为 TEXT
+-- 历史明文行保持可读，启用加密后调用 POST /ops/v1/admin/encryption/rotate 补加密
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+MODIFY COLUMN labels TEXT NULL COMMENT '任务标签JSON,启用加密时为密文',
+MODIFY COLUMN metadata TEXT NULL COMMENT '任务元数据JSON,启用加密时为密文';
@@ -0,0 +1,8 @@
+-- 按环境/用途的输出档位集合与单任务 HLS 档位数上限
+-- format_set 为空表示使用创建时环境默认的档位集合
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN format_set VARCHAR(64) NOT NULL DEFAULT '' COMMENT '输出档位集合(transcode.format_sets)',
+ADD COLUMN max_renditions INT NOT NULL DEFAULT 0 COMMENT 'HLS阶梯最多档位数,0不限制';
@@ -0,0 +1,7 @@
+-- 档位 GOP 结构：建任务时按同名档位解析的 closed_gop/forced_idr/scenecut，执行时映射为编码器参数
+-- 通过 GET /api/v2/tasks/:task_uuid 的 output.gop 返回；HLS 各档随 hls_jobs.profiles_json 保存
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN gop_policy JSON DEFAULT NULL COMMENT 'GOP 结构(JSON: {closed_gop,forced_idr,idr_interval_seconds,scenecut})';
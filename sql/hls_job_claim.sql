@@ -0,0 +1,9 @@
+-- HLS 作业认领字段
+-- 多副本部署时通过 pending -> claimed 的原子状态切换避免重复处理
+
+USE transcode_service;
+
+ALTER TABLE hls_jobs
+ADD COLUMN worker_id VARCHAR(64) DEFAULT NULL COMMENT '认领该作业的 worker',
+ADD COLUMN claimed_at TIMESTAMP NULL COMMENT '认领时间',
+ADD INDEX idx_worker_id (worker_id);
@@ -0,0 +1,19 @@
+-- 每个转码任务只保留一个 HLS 作业：source_job_uuid 唯一
+-- 任务重试后再次完成时复用已有作业，不再重复创建与上传
+
+USE transcode_service;
+
+-- 历史重复作业只保留最新一条的来源关联，旧作业的 source_job_uuid 置空（唯一索引允许多个 NULL）
+UPDATE hls_jobs h
+JOIN (
+    SELECT source_job_uuid, MAX(id) AS keep_id
+    FROM hls_jobs
+    WHERE source_job_uuid IS NOT NULL
+    GROUP BY source_job_uuid
+    HAVING COUNT(*) > 1
+) d ON h.source_job_uuid = d.source_job_uuid AND h.id <> d.keep_id
+SET h.source_job_uuid = NULL;
+
+ALTER TABLE hls_jobs
+DROP INDEX idx_hls_jobs_source_job_uuid,
+ADD UNIQUE INDEX uk_source_job_uuid (source_job_uuid);
@@ -0,0 +1,7 @@
+-- HLS processing 作业回收
+-- 处理实例崩溃后，updated_at 超过 worker.hls_processing_ttl 未续期的 processing 作业退回 pending
+
+USE transcode_service;
+
+ALTER TABLE hls_jobs
+ADD INDEX idx_status_updated_at (status, updated_at);
@@ -0,0 +1,6 @@
+-- HLS 码流级状态：记录每路码流的状态、错误与产物统计，支持只重试失败的码流
+
+USE transcode_service;
+
+ALTER TABLE hls_jobs
+ADD COLUMN renditions JSON NULL COMMENT '各路码流状态、错误与统计';
@@ -0,0 +1,12 @@
+-- 产物目标桶：请求携带 output_bucket/output_prefix（须在 output_targets 白名单内）时产物写到 buckets/<bucket>/<prefix>/ 定位的 key，
+-- 存储侧据此路由到该桶；HLS 作业随源任务继承
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN output_bucket VARCHAR(63) NOT NULL DEFAULT '' COMMENT '请求指定的产物目标桶，默认桶为空',
+ADD COLUMN output_prefix VARCHAR(256) NOT NULL DEFAULT '' COMMENT '目标桶内的产物前缀';
+
+ALTER TABLE hls_jobs
+ADD COLUMN output_bucket VARCHAR(63) NOT NULL DEFAULT '' COMMENT '随源任务的目标桶',
+ADD COLUMN output_prefix VARCHAR(256) NOT NULL DEFAULT '' COMMENT '随源任务的产物前缀';
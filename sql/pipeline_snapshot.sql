@@ -0,0 +1,17 @@
+-- 流水线状态快照
+-- 每个实例周期性写入运行中/排队中的任务认领，重启或蓝绿发布后据此精确恢复或重新排队
+
+USE transcode_service;
+
+CREATE TABLE IF NOT EXISTS pipeline_snapshots (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    instance_id VARCHAR(128) NOT NULL COMMENT '实例标识（worker_id@hostname）',
+    claims_json JSON NOT NULL COMMENT '任务认领 [{task_uuid,video_uuid,state,slot,progress,started_at}]',
+    released TINYINT(1) NOT NULL DEFAULT 0 COMMENT '实例正常停止时写入的最终快照',
+    taken_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '快照时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    is_deleted BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '是否删除',
+
+    UNIQUE KEY uk_instance_id (instance_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='流水线状态快照';
@@ -0,0 +1,10 @@
+-- 私有产物：visibility=private 的任务产物写到 private/<token>/ 前缀，只经签名网关访问
+-- token 由 private_outputs.key_secret 按用户、视频派生，HLS 作业随源任务继承
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN private_token VARCHAR(32) NOT NULL DEFAULT '' COMMENT '私有产物的令牌目录，公开任务为空';
+
+ALTER TABLE hls_jobs
+ADD COLUMN private_token VARCHAR(32) NOT NULL DEFAULT '' COMMENT '私有产物的令牌目录，随源任务';
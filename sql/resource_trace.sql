@@ -0,0 +1,7 @@
+-- 编码期间 ffmpeg 进程的生命周期与资源采样（CPU/RSS/GPU/fps/speed 时间序列）
+-- 通过 GET /api/v2/tasks/:task_uuid/samples 返回
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN resource_trace JSON DEFAULT NULL COMMENT '编码资源采样(JSON: {started_at,exit_code,samples[]})';
@@ -0,0 +1,6 @@
+-- 源文件代数：同一视频重新上传源文件时递增，新代数的任务会取消旧代数未完成的任务
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN source_generation BIGINT NOT NULL DEFAULT 0 COMMENT '源文件代数';
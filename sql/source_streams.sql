@@ -0,0 +1,7 @@
+-- 执行阶段探测到的源文件内容（音视频俱全/仅视频/仅音频）及缺失流的处理方式
+-- 通过 GET /api/v2/tasks/:task_uuid 的 source.streams 返回
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN source_streams JSON DEFAULT NULL COMMENT '源文件内容(JSON: {content,handling})';
@@ -0,0 +1,12 @@
+-- 产物分层存储：存储层级、CDN 回写的最近访问时间与归档位置
+-- 通过 GET /api/v2/tasks/:task_uuid 的 output.storage 返回，POST /api/v2/tasks/:task_uuid/restore 触发恢复
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN storage_tier VARCHAR(16) NOT NULL DEFAULT '' COMMENT '存储层级: 空/standard/archiving/archived/restoring',
+ADD COLUMN last_accessed_at TIMESTAMP NULL DEFAULT NULL COMMENT 'CDN 访问日志回写的最近访问时间',
+ADD COLUMN output_storage JSON DEFAULT NULL COMMENT '归档位置(JSON: {archive_path,storage_class,archived_at,restore_requested_at,restored_at})',
+ADD INDEX idx_transcode_jobs_storage_tier (storage_tier),
+ADD INDEX idx_transcode_jobs_last_accessed_at (last_accessed_at),
+ADD INDEX idx_transcode_jobs_output_path (output_path(191));
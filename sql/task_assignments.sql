@@ -0,0 +1,25 @@
+-- 作业分配历史
+-- 每个作业在某个 worker 上的一次执行记录一行，用于按 worker 统计利用率、评估机器规模
+
+USE transcode_service;
+
+CREATE TABLE IF NOT EXISTS task_assignments (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    worker_id VARCHAR(128) NOT NULL COMMENT '执行实例（worker_id@hostname）',
+    job_type VARCHAR(32) NOT NULL COMMENT '作业类型 transcode/hls/插件类型',
+    task_uuid VARCHAR(64) NOT NULL COMMENT '任务/作业UUID',
+    attempt INT NOT NULL DEFAULT 1 COMMENT '第几次执行',
+    started_at TIMESTAMP(3) NOT NULL COMMENT '拿到编码槽位开始执行的时间',
+    finished_at TIMESTAMP(3) NOT NULL COMMENT '执行结束时间',
+    outcome VARCHAR(16) NOT NULL COMMENT 'succeeded/failed/cancelled',
+    slot_weight INT NOT NULL DEFAULT 1 COMMENT '占用的编码槽位数',
+    slot_capacity INT NOT NULL DEFAULT 1 COMMENT '实例编码槽位总数',
+    wait_ms BIGINT NOT NULL DEFAULT 0 COMMENT '等待编码槽位的时长（毫秒）',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    is_deleted BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '是否删除',
+
+    INDEX idx_worker_started (worker_id, started_at),
+    INDEX idx_started_at (started_at),
+    INDEX idx_task_uuid (task_uuid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='作业分配历史';
@@ -0,0 +1,10 @@
+-- 任务组回调认领与批量创建保护期
+-- 多副本下投递回调前先条件认领（pending -> delivering），认领超过 5 分钟未写回结果时可被重新认领；
+-- 批量创建任务期间设置 batch_until，保护期内任务组不会因先创建的任务已结束而提前完成
+
+USE transcode_service;
+
+ALTER TABLE task_groups
+MODIFY COLUMN callback_state VARCHAR(16) NOT NULL DEFAULT 'none' COMMENT 'none/pending/delivering/delivered/failed',
+ADD COLUMN batch_until TIMESTAMP NULL COMMENT '批量创建保护期，该时间前不会完成',
+ADD INDEX idx_callback_state_updated_at (callback_state, updated_at);
@@ -0,0 +1,30 @@
+-- 任务组
+-- 一季多集等批量上传共用一个句柄：聚合查询组内任务进度，全部结束后向 callback_url 发送一次完成回调
+
+USE transcode_service;
+
+CREATE TABLE IF NOT EXISTS task_groups (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    group_uuid VARCHAR(36) NOT NULL COMMENT '任务组UUID',
+    user_uuid VARCHAR(36) NOT NULL COMMENT '用户UUID',
+    name VARCHAR(128) NOT NULL DEFAULT '' COMMENT '任务组名称',
+    expected_tasks INT NOT NULL DEFAULT 0 COMMENT '预期任务数，0 表示不限',
+    callback_url VARCHAR(1024) NOT NULL DEFAULT '' COMMENT '完成回调地址',
+    status VARCHAR(16) NOT NULL DEFAULT 'open' COMMENT 'open/completed',
+    callback_state VARCHAR(16) NOT NULL DEFAULT 'none' COMMENT 'none/pending/delivered/failed',
+    callback_attempts INT NOT NULL DEFAULT 0 COMMENT '回调尝试次数',
+    callback_error VARCHAR(512) NOT NULL DEFAULT '' COMMENT '最近一次回调错误',
+    completed_at TIMESTAMP NULL COMMENT '完成时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    is_deleted BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '是否删除',
+
+    UNIQUE KEY uk_group_uuid (group_uuid),
+    INDEX idx_status (status),
+    INDEX idx_callback_state (callback_state),
+    INDEX idx_user_created (user_uuid, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='任务组';
+
+ALTER TABLE transcode_jobs
+ADD COLUMN group_uuid VARCHAR(36) NOT NULL DEFAULT '' COMMENT '所属任务组',
+ADD INDEX idx_group_uuid (group_uuid);
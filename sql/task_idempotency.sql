@@ -0,0 +1,7 @@
+-- 创建请求幂等键：Kafka 再均衡后重复投递的消息按 idempotency_key 返回已有任务
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN idempotency_key VARCHAR(191) NULL COMMENT '创建请求的幂等键',
+ADD UNIQUE INDEX uk_idempotency_key (idempotency_key);
@@ -0,0 +1,7 @@
+-- 任务标签（如 campaign=summer、source=mobile-app），创建时设置
+-- 用于 GET /api/v2/tasks?labels=k=v 筛选及按标签分组的指标
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN labels JSON DEFAULT NULL COMMENT '任务标签(JSON: {key: value})';
@@ -0,0 +1,18 @@
+-- 任务备注
+-- 运维/客服在任务上记录的事件上下文（如 "客户升级，改用 x264 重试"），随任务详情返回
+
+USE transcode_service;
+
+CREATE TABLE IF NOT EXISTS task_notes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    note_uuid VARCHAR(64) NOT NULL COMMENT '备注UUID',
+    task_uuid VARCHAR(64) NOT NULL COMMENT '任务UUID',
+    author VARCHAR(128) NOT NULL COMMENT '备注作者',
+    body TEXT NOT NULL COMMENT '备注内容',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    is_deleted BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '是否删除',
+
+    UNIQUE KEY uk_note_uuid (note_uuid),
+    INDEX idx_task_created (task_uuid, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='任务备注';
@@ -0,0 +1,8 @@
+-- 停机时排队未执行任务的重新派发标记
+-- 实例停止时把本地队列中尚未开始的 pending 任务打上 redispatch_at，存活实例周期性认领后入队
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN redispatch_at TIMESTAMP NULL COMMENT '等待其他实例重新派发的时间',
+ADD INDEX idx_status_redispatch (status, redispatch_at);
@@ -0,0 +1,7 @@
+-- 任务重放：按覆盖参数克隆已结束任务，parent_task_uuid 指向原任务
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN parent_task_uuid VARCHAR(36) NULL COMMENT '重放任务对应的原任务UUID',
+ADD INDEX idx_parent_task_uuid (parent_task_uuid);
@@ -0,0 +1,17 @@
+-- 每日处理量统计
+-- 各 worker 周期性把源文件下载字节、产物上传字节与编码耗时的增量累加到当天（UTC）的行，供容量看板与成本核算查询
+
+USE transcode_service;
+
+CREATE TABLE IF NOT EXISTS throughput_daily (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    stat_date CHAR(10) NOT NULL COMMENT '统计日期（UTC，2006-01-02）',
+    input_bytes BIGINT NOT NULL DEFAULT 0 COMMENT '源文件下载字节数（源缓存命中不计）',
+    output_bytes BIGINT NOT NULL DEFAULT 0 COMMENT '产物上传字节数（MP4、音频、HLS 切片与播放列表）',
+    encode_ms BIGINT NOT NULL DEFAULT 0 COMMENT 'ffmpeg 编码耗时（毫秒，并发作业累加）',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    is_deleted BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '是否删除',
+
+    UNIQUE KEY uk_stat_date (stat_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='每日处理量统计';
@@ -0,0 +1,7 @@
+-- 按源码率封顶后的输出阶梯
+-- 通过 GET /api/v2/tasks/:task_uuid 的 output.bitrate_cap 返回
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN bitrate_cap JSON DEFAULT NULL COMMENT '码率封顶记录(JSON: {source_kbps,output,renditions:[{resolution,requested_bitrate,bitrate,dropped}]})';
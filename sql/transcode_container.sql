@@ -0,0 +1,7 @@
+-- 输出封装格式
+-- 每个任务记录 mp4/mkv/webm/mov，决定输出扩展名、封装参数与上传 Content-Type
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN container VARCHAR(10) NOT NULL DEFAULT 'mp4' COMMENT '输出封装格式(mp4/mkv/webm/mov)';
@@ -0,0 +1,6 @@
+-- 预览任务：只转码前 preview_seconds 秒，0 表示完整转码
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN preview_seconds INT NOT NULL DEFAULT 0 COMMENT '预览时长(秒)，0 为完整转码';
@@ -0,0 +1,8 @@
+-- 转码任务退避重试字段
+-- 存储等瞬时故障导致的失败进入 retrying 状态，到达 next_retry_at 后重新派发
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN next_retry_at TIMESTAMP NULL COMMENT '下次重试时间',
+ADD INDEX idx_status_next_retry (status, next_retry_at);
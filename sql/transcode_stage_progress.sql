@@ -0,0 +1,7 @@
+-- 转码流水线阶段进度
+-- 记录 download/encode/upload/hls 各阶段进度，用于计算视频整体进度
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN stage_progress JSON DEFAULT NULL COMMENT '各阶段进度(JSON: stage -> 0-100)';
@@ -0,0 +1,7 @@
+-- 显式指定的输入流序号（ffprobe stream index），NULL 表示自动选择主视频流与最佳音频流
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN video_stream_index INT NULL COMMENT '视频输入流序号',
+ADD COLUMN audio_stream_index INT NULL COMMENT '音频输入流序号';
@@ -0,0 +1,7 @@
+-- 任务耗时拆分
+-- 记录最近一次执行的开始/结束时间与 download/encode/upload 各阶段起止，用于区分排队、编码与存储耗时
+
+USE transcode_service;
+
+ALTER TABLE transcode_jobs
+ADD COLUMN timings JSON DEFAULT NULL COMMENT '执行计时(JSON: started_at, finished_at, stages -> {started_at, finished_at})';
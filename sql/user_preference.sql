@@ -0,0 +1,17 @@
+-- 用户默认转码偏好
+-- 任务未显式指定分辨率/码率时按偏好的码率阶梯补齐，HLS 阶梯优先使用偏好
+
+USE transcode_service;
+
+CREATE TABLE IF NOT EXISTS user_transcode_preferences (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    user_uuid VARCHAR(36) NOT NULL COMMENT '用户UUID',
+    ladder_json JSON DEFAULT NULL COMMENT '偏好码率阶梯 [{resolution,bitrate}]，首档为默认转码目标',
+    watermark_json JSON DEFAULT NULL COMMENT '水印配置 {object_key,position}',
+    subtitle_language VARCHAR(16) NOT NULL DEFAULT '' COMMENT '字幕语言',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    is_deleted BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '是否删除',
+
+    UNIQUE KEY uk_user_uuid (user_uuid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户默认转码偏好';